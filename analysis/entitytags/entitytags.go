@@ -0,0 +1,471 @@
+// Package entitytags checks the db and jet tags of entity structs before a
+// repository is created for them. It reports the mistakes
+// core.NewBaseRepository and the schema generator only surface at runtime,
+// or silently accept:
+//
+//   - jet options JetORM does not know, usually misspelled
+//   - auto_increment on a field that is not an integer
+//   - uuid on a field that is not a string or [16]byte, or with an unknown strategy
+//   - auto_now, auto_now_add and soft_delete on a field that is not a time.Time
+//   - enum on a field that is not a string, or without a type and values
+//   - default values that do not fit the field's type
+//   - options that contradict each other, such as uuid with auto_increment
+//   - several primary keys, or none on a struct with column options
+//   - two fields mapped to the same column, including through embedded structs
+//
+// A struct is checked when one of its fields has a db or jet tag. Like
+// sqlinject, the checker depends only on the standard library; "jetorm doctor
+// entities" runs it over package directories.
+package entitytags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Diagnostic is a problem found in the tags of an entity
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+// options are the jet tag options JetORM reads, on columns and on embedded
+// structs
+var options = map[string]bool{
+	"primary_key": true, "auto_increment": true, "unique": true, "not_null": true,
+	"index": true, "unique_index": true, "composite_index": true,
+	"size": true, "type": true, "default": true, "check": true,
+	"foreign_key": true, "on_delete": true, "on_update": true,
+	"auto_now_add": true, "auto_now": true, "soft_delete": true,
+	"uuid": true, "enum": true,
+	"discriminator": true, "alias": true,
+}
+
+// uuidStrategies are the values of the uuid option
+var uuidStrategies = map[string]bool{"": true, "v4": true, "v7": true, "db": true}
+
+// Check type-checks the files of one package and returns the problems in
+// the tags of its structs sorted by position. Type errors are ignored, so
+// fields of unresolved types are only checked for what their tags say.
+func Check(fset *token.FileSet, files []*ast.File) ([]Diagnostic, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	conf.Check(files[0].Name.Name, fset, files, info)
+
+	c := &checker{}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				obj, _ := info.Defs[spec.Name].(*types.TypeName)
+				c.checkStruct(spec, st, obj)
+			}
+			return true
+		})
+	}
+
+	sort.SliceStable(c.diags, func(i, j int) bool { return c.diags[i].Pos < c.diags[j].Pos })
+	return c.diags, nil
+}
+
+type checker struct {
+	diags []Diagnostic
+}
+
+func (c *checker) reportf(pos token.Pos, format string, args ...interface{}) {
+	c.diags = append(c.diags, Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkStruct checks the fields of a struct declaration, and its columns as
+// a whole when it resolved
+func (c *checker) checkStruct(spec *ast.TypeSpec, st *ast.StructType, obj *types.TypeName) {
+	tagged, columnOptions := false, false
+	primaryKeys := 0
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		if tag.Get("db") == "" && tag.Get("jet") == "" {
+			continue
+		}
+		tagged = true
+		var typ types.Type
+		if obj != nil {
+			typ = fieldType(obj, field)
+		}
+
+		opts := parseTag(tag.Get("jet"))
+		for _, opt := range opts {
+			if opt.key != "alias" && opt.key != "discriminator" && opt.key != "-" {
+				columnOptions = true
+			}
+			if opt.key == "primary_key" {
+				primaryKeys++
+			}
+		}
+		if tag.Get("db") != "-" && tag.Get("jet") != "-" {
+			c.checkField(field, fieldName(field), opts, typ)
+		}
+	}
+	if !tagged {
+		return
+	}
+
+	if primaryKeys > 1 {
+		c.reportf(spec.Name.Pos(), "%s has %d primary_key fields; only one is used", spec.Name.Name, primaryKeys)
+	}
+	if obj == nil {
+		return
+	}
+	structType, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	if isProjection(structType) {
+		return // Each embedded entity's columns are selected under its own prefix
+	}
+	cols := columns(structType, "")
+	if primaryKeys == 0 && columnOptions && !hasPrimaryKey(cols) {
+		c.reportf(spec.Name.Pos(), "%s has column options but no primary_key field", spec.Name.Name)
+	}
+
+	seen := make(map[string]column)
+	for _, col := range cols {
+		if first, dup := seen[col.name]; dup {
+			c.reportf(spec.Name.Pos(), "%s maps both %s and %s to column %s", spec.Name.Name, first.path, col.path, col.name)
+			continue
+		}
+		seen[col.name] = col
+	}
+}
+
+// checkField checks the jet options of one field against each other and
+// against its type, which is nil when it did not resolve
+func (c *checker) checkField(field *ast.Field, name string, opts []option, typ types.Type) {
+	pos := field.Pos()
+	if field.Tag != nil {
+		pos = field.Tag.Pos()
+	}
+
+	set := make(map[string]option, len(opts))
+	for _, opt := range opts {
+		if !options[opt.key] {
+			c.reportf(pos, "%s: unknown jet option %q", name, opt.key)
+			continue
+		}
+		set[opt.key] = opt
+	}
+	for _, conflict := range [][2]string{
+		{"uuid", "auto_increment"},
+		{"auto_now", "auto_now_add"},
+		{"primary_key", "soft_delete"},
+	} {
+		_, a := set[conflict[0]]
+		_, b := set[conflict[1]]
+		if a && b {
+			c.reportf(pos, "%s: %s and %s cannot be combined", name, conflict[0], conflict[1])
+		}
+	}
+	if opt, ok := set["uuid"]; ok && !uuidStrategies[opt.value] {
+		c.reportf(pos, "%s: unknown uuid strategy %q, want v4, v7 or db", name, opt.value)
+	}
+	if opt, ok := set["enum"]; ok && !validEnum(opt.value) {
+		c.reportf(pos, "%s: enum must be enum:type_name(value1,value2,...)", name)
+	}
+
+	if typ == nil || typ == types.Typ[types.Invalid] {
+		return
+	}
+	if _, ok := set["auto_increment"]; ok && !isInteger(typ) {
+		c.reportf(pos, "%s: auto_increment needs an integer field, not %s", name, typ)
+	}
+	if _, ok := set["uuid"]; ok && !isUUID(typ) {
+		c.reportf(pos, "%s: uuid needs a string or [16]byte field, not %s", name, typ)
+	}
+	for _, key := range []string{"auto_now", "auto_now_add", "soft_delete"} {
+		if _, ok := set[key]; ok && !isTime(typ) {
+			c.reportf(pos, "%s: %s needs a time.Time field, not %s", name, key, typ)
+		}
+	}
+	if _, ok := set["enum"]; ok && !isString(typ) {
+		c.reportf(pos, "%s: enum needs a string field, not %s", name, typ)
+	}
+	if opt, ok := set["default"]; ok && !defaultFits(opt.value, typ) {
+		c.reportf(pos, "%s: default %q does not fit %s", name, opt.value, typ)
+	}
+}
+
+// column is a column of a struct and the field path declaring it
+type column struct {
+	name       string
+	path       string
+	primaryKey bool
+}
+
+// columns lists the columns of st the way core.EntityMetadata maps them,
+// flattening embedded structs
+func columns(st *types.Struct, prefix string) []column {
+	var cols []column
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		dbTag, jetTag := tag.Get("db"), tag.Get("jet")
+		if !field.Exported() || dbTag == "-" || jetTag == "-" {
+			continue
+		}
+		if embedded, ok := flattened(field, dbTag); ok {
+			cols = append(cols, columns(embedded, prefix+field.Name()+".")...)
+			continue
+		}
+
+		name := dbTag
+		if name == "" {
+			name = toSnakeCase(field.Name())
+		}
+		col := column{name: name, path: prefix + field.Name()}
+		for _, opt := range parseTag(jetTag) {
+			if opt.key == "primary_key" {
+				col.primaryKey = true
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// isProjection reports whether st embeds several entities, as projections
+// of joins do
+func isProjection(st *types.Struct) bool {
+	entities := 0
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		if embedded, ok := deref(field.Type()).Underlying().(*types.Struct); ok && hasPrimaryKey(columns(embedded, "")) {
+			entities++
+		}
+	}
+	return entities > 1
+}
+
+// flattened returns the struct an embedded field contributes columns from.
+// Embedded pointers, structs with a db tag and structs without exported
+// fields, such as time.Time, are a single column.
+func flattened(field *types.Var, dbTag string) (*types.Struct, bool) {
+	if !field.Embedded() || dbTag != "" {
+		return nil, false
+	}
+	st, ok := field.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Exported() {
+			return st, true
+		}
+	}
+	return nil, false
+}
+
+func hasPrimaryKey(cols []column) bool {
+	for _, col := range cols {
+		if col.primaryKey {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldType returns the type of a field declared in the struct named by obj
+func fieldType(obj *types.TypeName, field *ast.Field) types.Type {
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	name := fieldName(field)
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Name() == name {
+			return st.Field(i).Type()
+		}
+	}
+	return nil
+}
+
+// fieldName returns the name of a field, or of the type it embeds
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	expr := field.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+// deref strips a pointer, as nullable columns are pointers
+func deref(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func basicInfo(t types.Type) (types.BasicInfo, bool) {
+	basic, ok := deref(t).Underlying().(*types.Basic)
+	if !ok {
+		return 0, false
+	}
+	return basic.Info(), true
+}
+
+func isInteger(t types.Type) bool {
+	info, ok := basicInfo(t)
+	return ok && info&types.IsInteger != 0
+}
+
+func isString(t types.Type) bool {
+	info, ok := basicInfo(t)
+	return ok && info&types.IsString != 0
+}
+
+func isUUID(t types.Type) bool {
+	if isString(t) {
+		return true
+	}
+	array, ok := deref(t).Underlying().(*types.Array)
+	if !ok || array.Len() != 16 {
+		return false
+	}
+	info, ok := basicInfo(array.Elem())
+	return ok && info&types.IsInteger != 0 && info&types.IsUnsigned != 0
+}
+
+func isTime(t types.Type) bool {
+	named, ok := deref(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// defaultFits reports whether a default value fits a field's type. SQL
+// expressions such as now() or nextval('seq') fit any type.
+func defaultFits(value string, t types.Type) bool {
+	if strings.Contains(value, "(") || strings.EqualFold(value, "null") {
+		return true
+	}
+	info, ok := basicInfo(t)
+	if !ok {
+		return true
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = value[1 : len(value)-1] // PostgreSQL casts quoted literals
+	}
+	switch {
+	case info&types.IsBoolean != 0:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case info&types.IsInteger != 0:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case info&types.IsFloat != 0:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}
+	return true
+}
+
+// validEnum reports whether an enum option names a type and its values
+func validEnum(value string) bool {
+	open := strings.Index(value, "(")
+	if open <= 0 || !strings.HasSuffix(value, ")") {
+		return false
+	}
+	return strings.TrimSpace(value[open+1:len(value)-1]) != ""
+}
+
+// option is a key or key:value of a jet tag
+type option struct {
+	key   string
+	value string
+}
+
+// parseTag splits a jet tag on the commas outside quotes and parentheses,
+// like core does
+func parseTag(tag string) []option {
+	var opts []option
+	var current strings.Builder
+	inQuote, depth := false, 0
+	flush := func() {
+		part := strings.TrimSpace(current.String())
+		current.Reset()
+		if part == "" {
+			return
+		}
+		key, value, _ := strings.Cut(part, ":")
+		opts = append(opts, option{key: key, value: value})
+	}
+	for _, r := range tag {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '(' && !inQuote:
+			depth++
+		case r == ')' && !inQuote:
+			depth--
+		case r == ',' && !inQuote && depth == 0:
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return opts
+}
+
+// toSnakeCase converts a field name to its default column name, like core
+func toSnakeCase(s string) string {
+	var result strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(r))
+		} else {
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,101 @@
+package entitytags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	diags, err := Check(fset, []*ast.File{file})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	lines := make([]string, len(diags))
+	for i, d := range diags {
+		lines[i] = fmt.Sprintf("%d: %s", fset.Position(d.Pos).Line, d.Message)
+	}
+	return lines
+}
+
+func TestCheck_Fields(t *testing.T) {
+	src := "package example\n\nimport \"time\"\n\n" +
+		"type Invoice struct {\n" +
+		"\tID        string    `db:\"id\" jet:\"primary_key,auto_increment\"`\n" +
+		"\tNumber    int       `db:\"number\" jet:\"unique,defualt:1\"`\n" +
+		"\tTotal     int       `db:\"total\" jet:\"default:zero\"`\n" +
+		"\tPaid      bool      `db:\"paid\" jet:\"default:false\"`\n" +
+		"\tRate      float64   `db:\"rate\" jet:\"default:'1.5'\"`\n" +
+		"\tSerial    int       `db:\"serial\" jet:\"default:nextval('serial_seq')\"`\n" +
+		"\tStatus    int       `db:\"status\" jet:\"enum:invoice_status(draft,sent)\"`\n" +
+		"\tKind      string    `db:\"kind\" jet:\"enum:invoice_kind\"`\n" +
+		"\tToken     int64     `db:\"token\" jet:\"uuid:v9\"`\n" +
+		"\tCreatedAt string    `db:\"created_at\" jet:\"auto_now_add\"`\n" +
+		"\tUpdatedAt time.Time `db:\"updated_at\" jet:\"auto_now,auto_now_add\"`\n" +
+		"\tDeletedAt *time.Time `db:\"deleted_at\" jet:\"soft_delete\"`\n" +
+		"\tInternal  string    `db:\"-\" jet:\"bogus\"`\n" +
+		"}\n"
+
+	want := []string{
+		"6: ID: auto_increment needs an integer field, not string",
+		`7: Number: unknown jet option "defualt"`,
+		`8: Total: default "zero" does not fit int`,
+		"12: Status: enum needs a string field, not int",
+		"13: Kind: enum must be enum:type_name(value1,value2,...)",
+		`14: Token: unknown uuid strategy "v9", want v4, v7 or db`,
+		"14: Token: uuid needs a string or [16]byte field, not int64",
+		"15: CreatedAt: auto_now_add needs a time.Time field, not string",
+		"16: UpdatedAt: auto_now and auto_now_add cannot be combined",
+	}
+	if got := check(t, src); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestCheck_Columns(t *testing.T) {
+	src := "package example\n\n" +
+		"type Base struct {\n" +
+		"\tID   int64  `db:\"id\" jet:\"primary_key\"`\n" +
+		"\tName string `db:\"name\"`\n" +
+		"}\n\n" +
+		"type Account struct {\n" +
+		"\tBase\n" +
+		"\tTitle string `db:\"name\"`\n" +
+		"}\n\n" +
+		"type Pair struct {\n" +
+		"\tLeft  int64 `db:\"left\" jet:\"primary_key\"`\n" +
+		"\tRight int64 `db:\"right\" jet:\"primary_key\"`\n" +
+		"}\n\n" +
+		"type Note struct {\n" +
+		"\tBody string `db:\"body\" jet:\"not_null\"`\n" +
+		"}\n\n" +
+		"type Summary struct {\n" +
+		"\tName  string `db:\"name\"`\n" +
+		"\tCount int    `db:\"count\"`\n" +
+		"}\n\n" +
+		"type Customer struct {\n" +
+		"\tID int64 `db:\"id\" jet:\"primary_key\"`\n" +
+		"}\n\n" +
+		"type AccountView struct {\n" +
+		"\tAccount `jet:\"alias:a\"`\n" +
+		"\tCustomer\n" +
+		"}\n"
+
+	want := []string{
+		"8: Account maps both Base.Name and Title to column name",
+		"13: Pair has 2 primary_key fields; only one is used",
+		"18: Note has column options but no primary_key field",
+	}
+	if got := check(t, src); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected\n%q\ngot\n%q", want, got)
+	}
+}
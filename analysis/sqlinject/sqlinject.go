@@ -0,0 +1,320 @@
+// Package sqlinject is a vet analyzer for SQL injection risks at JetORM call
+// sites. It flags SQL text built with fmt.Sprintf or string concatenation of
+// non-constant values that is passed to Query, QueryOne, QueryRow or Exec,
+// and sort fields of core.Order values that are not constants. Both usually
+// mean user input reaches the SQL text instead of a bind parameter; use
+// placeholders and specifications for values, and a filter.Binder or a fixed
+// set of columns for sorting.
+//
+// The analyzer is syntactic and depends only on the standard library. Its
+// Analysis and Pass types mirror golang.org/x/tools/go/analysis, so it can be
+// wrapped in an analysis.Analyzer when that module is available; "jetorm vet"
+// runs it over package directories.
+//
+// A finding is suppressed by a //nolint:sqlinject comment on the same line or
+// the line above.
+package sqlinject
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a finding reported by the analyzer
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+// Pass provides the files of one package to the analyzer
+type Pass struct {
+	Fset   *token.FileSet
+	Files  []*ast.File
+	Report func(Diagnostic)
+}
+
+// Reportf reports a diagnostic at pos
+func (p *Pass) Reportf(pos token.Pos, format string, args ...interface{}) {
+	p.Report(Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// An Analysis describes an analysis function and its documentation, like
+// analysis.Analyzer
+type Analysis struct {
+	Name string
+	Doc  string
+	Run  func(*Pass) (interface{}, error)
+}
+
+// Analyzer flags SQL built from non-constant strings and non-constant sort fields
+var Analyzer = &Analysis{
+	Name: "sqlinject",
+	Doc:  "report SQL built with fmt.Sprintf or concatenation passed to Query/Exec and non-constant sort fields",
+	Run:  run,
+}
+
+// queryMethods are the methods whose SQL argument is checked
+var queryMethods = map[string]bool{
+	"Query":    true,
+	"QueryOne": true,
+	"QueryRow": true,
+	"Exec":     true,
+}
+
+const (
+	corePath  = "github.com/satishbabariya/jetorm/core"
+	maxFollow = 8 // Declarations followed when resolving an identifier
+)
+
+// Check runs the analyzer over the files of one package and returns its
+// diagnostics sorted by position
+func Check(fset *token.FileSet, files []*ast.File) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	pass := &Pass{Fset: fset, Files: files, Report: func(d Diagnostic) { diags = append(diags, d) }}
+	if _, err := Analyzer.Run(pass); err != nil {
+		return nil, err
+	}
+	sort.Slice(diags, func(i, j int) bool { return diags[i].Pos < diags[j].Pos })
+	return diags, nil
+}
+
+func run(pass *Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		c := &checker{
+			pass:       pass,
+			fmtName:    importName(file, "fmt"),
+			coreName:   importName(file, corePath),
+			suppressed: suppressedLines(pass.Fset, file),
+		}
+		ast.Inspect(file, c.visit)
+	}
+	return nil, nil
+}
+
+type checker struct {
+	pass       *Pass
+	fmtName    string // Local name of fmt, empty when not imported
+	coreName   string // Local name of the core package, empty when not imported
+	suppressed map[int]bool
+}
+
+func (c *checker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.CallExpr:
+		c.checkQuery(n)
+	case *ast.CompositeLit:
+		c.checkOrder(n)
+	}
+	return true
+}
+
+func (c *checker) report(pos token.Pos, format string, args ...interface{}) {
+	if c.suppressed[c.pass.Fset.Position(pos).Line] {
+		return
+	}
+	c.pass.Reportf(pos, format, args...)
+}
+
+// checkQuery flags Query/Exec calls whose SQL argument is built at run time.
+// The SQL is the first argument, or the second when the first is not a
+// string, as with a leading context.
+func (c *checker) checkQuery(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !queryMethods[sel.Sel.Name] || len(call.Args) == 0 {
+		return
+	}
+	sqlArg := call.Args[0]
+	if c.builtBy(sqlArg, 0) == "" && !c.isConstant(sqlArg, 0) && len(call.Args) > 1 {
+		sqlArg = call.Args[1]
+	}
+	if how := c.builtBy(sqlArg, 0); how != "" {
+		c.report(sqlArg.Pos(), "SQL passed to %s is built with %s; pass values as $n parameters instead", sel.Sel.Name, how)
+	}
+}
+
+// checkOrder flags core.Order literals whose Field is not a constant
+func (c *checker) checkOrder(lit *ast.CompositeLit) {
+	if c.coreName == "" || !isSelector(lit.Type, c.coreName, "Order") {
+		return
+	}
+	for i, elt := range lit.Elts {
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok || key.Name != "Field" {
+				continue
+			}
+			value = kv.Value
+		} else if i != 0 {
+			continue
+		}
+		if !c.isConstant(value, 0) {
+			c.report(value.Pos(), "sort field of %s.Order is not a constant; validate it against a fixed set of columns (see filter.Sortable)", c.coreName)
+		}
+	}
+}
+
+// builtBy reports how a string expression is assembled from non-constant
+// parts: "fmt.Sprintf" or "string concatenation", or "" when it is not
+func (c *checker) builtBy(expr ast.Expr, depth int) string {
+	if depth > maxFollow {
+		return ""
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return c.builtBy(e.X, depth+1)
+	case *ast.CallExpr:
+		if c.fmtName == "" || !isSelector(e.Fun, c.fmtName, "Sprintf") || len(e.Args) == 0 {
+			return ""
+		}
+		for _, arg := range e.Args[1:] {
+			if !c.isConstant(arg, depth+1) {
+				return "fmt.Sprintf"
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return ""
+		}
+		if how := c.builtBy(e.X, depth+1); how != "" {
+			return how
+		}
+		if how := c.builtBy(e.Y, depth+1); how != "" {
+			return how
+		}
+		if !c.isConstant(e.X, depth+1) || !c.isConstant(e.Y, depth+1) {
+			return "string concatenation"
+		}
+	case *ast.Ident:
+		if value := declaredValue(e); value != nil {
+			return c.builtBy(value, depth+1)
+		}
+	}
+	return ""
+}
+
+// isConstant reports whether expr is known not to carry run-time input:
+// literals, constants, qualified identifiers of other packages, Name and
+// String of generated columns, and variables initialized from those
+func (c *checker) isConstant(expr ast.Expr, depth int) bool {
+	if depth > maxFollow {
+		return false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return c.isConstant(e.X, depth+1)
+	case *ast.BinaryExpr:
+		return c.isConstant(e.X, depth+1) && c.isConstant(e.Y, depth+1)
+	case *ast.SelectorExpr:
+		// pkg.Name or a package-level value declared in another file, such
+		// as generated <Entity>Fields; locals and parameters are resolved
+		x, ok := e.X.(*ast.Ident)
+		return ok && x.Obj == nil
+	case *ast.CallExpr:
+		// UserFields.Email.Name() and friends
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if ok && len(e.Args) == 0 && (sel.Sel.Name == "Name" || sel.Sel.Name == "String") {
+			_, ok := sel.X.(*ast.SelectorExpr)
+			return ok
+		}
+	case *ast.Ident:
+		if e.Obj == nil {
+			return e.Name == "true" || e.Name == "false" || e.Name == "nil"
+		}
+		if e.Obj.Kind == ast.Con {
+			return true
+		}
+		if value := declaredValue(e); value != nil {
+			return c.isConstant(value, depth+1)
+		}
+	}
+	return false
+}
+
+// declaredValue returns the initial value of a variable declared with :=, =
+// or var, or nil when it is a parameter or its value is unknown
+func declaredValue(id *ast.Ident) ast.Expr {
+	if id.Obj == nil || id.Obj.Kind != ast.Var {
+		return nil
+	}
+	switch decl := id.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		if len(decl.Lhs) != len(decl.Rhs) {
+			return nil
+		}
+		for i, lhs := range decl.Lhs {
+			if name, ok := lhs.(*ast.Ident); ok && name.Name == id.Name {
+				return decl.Rhs[i]
+			}
+		}
+	case *ast.ValueSpec:
+		if len(decl.Names) != len(decl.Values) {
+			return nil
+		}
+		for i, name := range decl.Names {
+			if name.Name == id.Name {
+				return decl.Values[i]
+			}
+		}
+	}
+	return nil
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg
+}
+
+func isStringLiteral(expr ast.Expr) bool {
+	lit, ok := expr.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING
+}
+
+// importName returns the local name of an imported package, or ""
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != path {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == "_" || spec.Name.Name == "." {
+				return ""
+			}
+			return spec.Name.Name
+		}
+		return p[strings.LastIndex(p, "/")+1:]
+	}
+	return ""
+}
+
+// suppressedLines collects the lines covered by //nolint:sqlinject comments
+func suppressedLines(fset *token.FileSet, file *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+			if !strings.HasPrefix(text, "nolint") {
+				continue
+			}
+			linters := strings.TrimPrefix(text, "nolint")
+			if linters != "" && !strings.Contains(linters, "sqlinject") {
+				continue
+			}
+			line := fset.Position(comment.Pos()).Line
+			lines[line] = true
+			lines[line+1] = true
+		}
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+package sqlinject
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	diags, err := Check(fset, []*ast.File{file})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	lines := make([]string, len(diags))
+	for i, d := range diags {
+		lines[i] = fmt.Sprintf("%d: %s", fset.Position(d.Pos).Line, d.Message)
+	}
+	return lines
+}
+
+func TestAnalyzer_Queries(t *testing.T) {
+	src := `package example
+
+import (
+	"context"
+	"fmt"
+)
+
+const table = "users"
+
+func queries(ctx context.Context, repo Repo, name string, limit int) {
+	repo.Query(ctx, fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name))
+	repo.Exec(ctx, "DELETE FROM users WHERE name = '"+name+"'")
+	repo.QueryOne(ctx, "SELECT * FROM users WHERE name = $1", name)
+	repo.Query(ctx, fmt.Sprintf("SELECT * FROM %s WHERE name = $1", table), name)
+	repo.Query(ctx, "SELECT * FROM " + table + " LIMIT 10")
+
+	query := fmt.Sprintf("SELECT * FROM users LIMIT %d", limit)
+	repo.Query(ctx, query)
+
+	fixed := "SELECT * FROM users WHERE name = $1"
+	repo.Query(ctx, fixed, fmt.Sprintf("%s%%", name))
+
+	db.Exec(fmt.Sprintf("DROP TABLE %s", name))
+	repo.Exec(ctx, fmt.Sprintf("DROP TABLE %s", name)) //nolint:sqlinject
+}
+`
+	got := check(t, src)
+	want := []string{
+		"11: SQL passed to Query is built with fmt.Sprintf; pass values as $n parameters instead",
+		"12: SQL passed to Exec is built with string concatenation; pass values as $n parameters instead",
+		"18: SQL passed to Query is built with fmt.Sprintf; pass values as $n parameters instead",
+		"23: SQL passed to Exec is built with fmt.Sprintf; pass values as $n parameters instead",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAnalyzer_SortFields(t *testing.T) {
+	t.Run("non-constant fields", func(t *testing.T) {
+		src := `package example
+
+import (
+	"net/http"
+
+	orm "github.com/satishbabariya/jetorm/core"
+)
+
+const byName = "name"
+
+func sorts(r *http.Request, field string) {
+	orm.PageRequest(0, 20, orm.Order{Field: r.URL.Query().Get("sort")})
+	orm.PageRequest(0, 20, orm.Order{field, orm.Desc})
+	orm.PageRequest(0, 20, orm.Order{Field: byName, Direction: orm.Desc})
+	orm.PageRequest(0, 20, orm.Order{Field: "created_at"})
+	orm.PageRequest(0, 20, orm.Order{Field: UserFields.Email.Name()})
+}
+`
+		got := check(t, src)
+		want := []string{
+			"12: sort field of orm.Order is not a constant; validate it against a fixed set of columns (see filter.Sortable)",
+			"13: sort field of orm.Order is not a constant; validate it against a fixed set of columns (see filter.Sortable)",
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	})
+
+	t.Run("other Order types", func(t *testing.T) {
+		src := `package example
+
+func sorts(field string) {
+	_ = Order{Field: field}
+}
+`
+		if got := check(t, src); len(got) != 0 {
+			t.Errorf("unexpected diagnostics: %v", got)
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/satishbabariya/jetorm/generator"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are the shared config files discovered from the working
+// directory upwards, in order of preference within a directory
+var configFileNames = []string{"jetorm.yaml", "jetorm.yml", "jetorm.json"}
+
+// Environment variables overriding the config file
+const (
+	envConfig        = "JETORM_CONFIG"
+	envEnvironment   = "JETORM_ENV"
+	envDatabaseURL   = "JETORM_DATABASE_URL"
+	envMigrationsDir = "JETORM_MIGRATIONS_DIR"
+	envSeedsDir      = "JETORM_SEEDS_DIR"
+)
+
+// Profile holds the settings that an environment can override
+type Profile struct {
+	DatabaseURL   string            `json:"database_url,omitempty" yaml:"database_url,omitempty"`
+	MigrationsDir string            `json:"migrations_dir,omitempty" yaml:"migrations_dir,omitempty"`
+	SeedsDir      string            `json:"seeds_dir,omitempty" yaml:"seeds_dir,omitempty"`
+	Generator     *generator.Config `json:"generator,omitempty" yaml:"generator,omitempty"`
+}
+
+// Config is the configuration shared by all jetorm subcommands. The top-level
+// profile applies to every environment; the selected environment's non-empty
+// settings replace it.
+type Config struct {
+	Profile      `yaml:",inline"`
+	Environments map[string]Profile `json:"environments,omitempty" yaml:"environments,omitempty"`
+
+	Environment string `json:"-" yaml:"-"` // Selected environment, empty when none
+	path        string // File the config was loaded from, empty when none was found
+}
+
+// options holds the flags every subcommand accepts
+type options struct {
+	configPath    string
+	environment   string
+	databaseURL   string
+	migrationsDir string
+	seedsDir      string
+}
+
+// addFlags registers the shared flags as persistent flags of the root command
+func (o *options) addFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.configPath, "config", "", "Config file (default: nearest jetorm.yaml or jetorm.json)")
+	flags.StringVarP(&o.environment, "env", "e", "", "Environment profile from the config file (env "+envEnvironment+")")
+	flags.StringVar(&o.databaseURL, "db", "", "Database connection string (env "+envDatabaseURL+")")
+	flags.StringVar(&o.migrationsDir, "dir", "", "Migrations directory (env "+envMigrationsDir+", default ./migrations)")
+	flags.StringVar(&o.seedsDir, "seeds", "", "Seed files directory (env "+envSeedsDir+", default ./seeds)")
+
+	cmd.MarkPersistentFlagFilename("config", "yaml", "yml", "json")
+	cmd.MarkPersistentFlagDirname("dir")
+	cmd.MarkPersistentFlagDirname("seeds")
+	cmd.RegisterFlagCompletionFunc("env", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		cfg, err := o.loadFile()
+		if err != nil || cfg == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cfg.environmentNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// load resolves the configuration. Flags take precedence over environment
+// variables, which take precedence over the selected environment profile and
+// then the top-level settings of the config file.
+func (o *options) load() (*Config, error) {
+	cfg, err := o.loadFile()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	if name := firstNonEmpty(o.environment, os.Getenv(envEnvironment)); name != "" {
+		if err := cfg.selectEnvironment(name); err != nil {
+			return nil, err
+		}
+	}
+
+	base := ""
+	if cfg.path != "" {
+		base = filepath.Dir(cfg.path)
+	}
+
+	// File values are interpolated only when not overridden, so that an
+	// unset variable in an unused setting is not an error
+	setting := func(flagValue, envName string, value *string, isPath bool) error {
+		if override := firstNonEmpty(flagValue, os.Getenv(envName)); override != "" {
+			*value = override
+			return nil
+		}
+		expanded, err := expandEnv(*value)
+		if err != nil {
+			return err
+		}
+		if isPath && base != "" {
+			expanded = resolvePath(base, expanded)
+		}
+		*value = expanded
+		return nil
+	}
+	if err := setting(o.databaseURL, envDatabaseURL, &cfg.DatabaseURL, false); err != nil {
+		return nil, err
+	}
+	if err := setting(o.migrationsDir, envMigrationsDir, &cfg.MigrationsDir, true); err != nil {
+		return nil, err
+	}
+	if err := setting(o.seedsDir, envSeedsDir, &cfg.SeedsDir, true); err != nil {
+		return nil, err
+	}
+	cfg.MigrationsDir = firstNonEmpty(cfg.MigrationsDir, "./migrations")
+	cfg.SeedsDir = firstNonEmpty(cfg.SeedsDir, "./seeds")
+
+	return cfg, nil
+}
+
+// loadFile reads the config file named by flag or environment, or the nearest
+// discovered one. It returns nil when there is none.
+func (o *options) loadFile() (*Config, error) {
+	path := firstNonEmpty(o.configPath, os.Getenv(envConfig))
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if path = findConfigFile(wd); path == "" {
+			return nil, nil
+		}
+	}
+	return loadConfig(path)
+}
+
+// findConfigFile returns the nearest config file in dir or its parents, or ""
+func findConfigFile(dir string) string {
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads a YAML or JSON config file, chosen by extension. Unknown
+// keys are rejected so that typos do not silently fall back to defaults.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if filepath.Ext(path) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&cfg)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&cfg); err != nil && len(bytes.TrimSpace(data)) == 0 {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	cfg.path = path
+
+	return &cfg, nil
+}
+
+// selectEnvironment applies the named environment profile over the top-level settings
+func (c *Config) selectEnvironment(name string) error {
+	profile, ok := c.Environments[name]
+	if !ok {
+		if c.path == "" {
+			return fmt.Errorf("environment %q selected but no config file was found", name)
+		}
+		return fmt.Errorf("unknown environment %q in %s (available: %s)",
+			name, c.path, strings.Join(c.environmentNames(), ", "))
+	}
+
+	c.DatabaseURL = firstNonEmpty(profile.DatabaseURL, c.DatabaseURL)
+	c.MigrationsDir = firstNonEmpty(profile.MigrationsDir, c.MigrationsDir)
+	c.SeedsDir = firstNonEmpty(profile.SeedsDir, c.SeedsDir)
+	if profile.Generator != nil {
+		c.Generator = profile.Generator
+	}
+	c.Environment = name
+	return nil
+}
+
+func (c *Config) environmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandEnv replaces $VAR and ${VAR} with the value of the environment
+// variable. ${VAR:-default} falls back to default when VAR is unset or empty;
+// any other reference to an unset variable is an error.
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := os.Expand(s, func(ref string) string {
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if hasDefault {
+			return def
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+func resolvePath(base, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// openDB opens and pings the configured database
+func (c *Config) openDB(ctx context.Context) (*sql.DB, error) {
+	if c.DatabaseURL == "" {
+		return nil, fmt.Errorf("database URL is required: use --db, %s or database_url in the config file", envDatabaseURL)
+	}
+
+	db, err := sql.Open("pgx", c.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestFindConfigFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if path := findConfigFile(nested); path != "" {
+		t.Errorf("Expected no config file, got '%s'", path)
+	}
+
+	expected := writeConfig(t, root, "jetorm.json", `{}`)
+	if path := findConfigFile(nested); path != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, path)
+	}
+}
+
+func TestOptionsLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "jetorm.json", `{
+		"database_url": "postgres://file",
+		"migrations_dir": "db/migrations",
+		"generator": {"entity_type": "User"}
+	}`)
+
+	t.Run("file values", func(t *testing.T) {
+		t.Setenv(envDatabaseURL, "")
+		t.Setenv(envMigrationsDir, "")
+		t.Setenv(envSeedsDir, "")
+
+		cfg, err := (&options{configPath: path}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://file" {
+			t.Errorf("Expected 'postgres://file', got '%s'", cfg.DatabaseURL)
+		}
+		if want := filepath.Join(dir, "db", "migrations"); cfg.MigrationsDir != want {
+			t.Errorf("Expected '%s', got '%s'", want, cfg.MigrationsDir)
+		}
+		if cfg.SeedsDir != "./seeds" {
+			t.Errorf("Expected './seeds', got '%s'", cfg.SeedsDir)
+		}
+		if cfg.Generator == nil || cfg.Generator.EntityType != "User" {
+			t.Errorf("Expected generator config to be loaded, got %+v", cfg.Generator)
+		}
+	})
+
+	t.Run("env overrides file", func(t *testing.T) {
+		t.Setenv(envDatabaseURL, "postgres://env")
+		cfg, err := (&options{configPath: path}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://env" {
+			t.Errorf("Expected 'postgres://env', got '%s'", cfg.DatabaseURL)
+		}
+	})
+
+	t.Run("flag overrides env", func(t *testing.T) {
+		t.Setenv(envDatabaseURL, "postgres://env")
+		cfg, err := (&options{configPath: path, databaseURL: "postgres://flag"}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://flag" {
+			t.Errorf("Expected 'postgres://flag', got '%s'", cfg.DatabaseURL)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		bad := writeConfig(t, t.TempDir(), "jetorm.json", `{`)
+		if _, err := (&options{configPath: bad}).load(); err == nil {
+			t.Error("Expected error for invalid config file")
+		}
+	})
+}
+
+func TestFindConfigFile_PrefersYAML(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "jetorm.json", `{}`)
+	expected := writeConfig(t, dir, "jetorm.yaml", ``)
+	if path := findConfigFile(dir); path != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, path)
+	}
+}
+
+func TestOptionsLoad_Environments(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "jetorm.yaml", `
+database_url: postgres://localhost/dev
+migrations_dir: migrations
+generator:
+  entity_type: User
+environments:
+  staging:
+    database_url: postgres://${STAGING_DB_USER}@staging/app
+  prod:
+    database_url: ${PROD_DATABASE_URL}
+    migrations_dir: ${PROD_MIGRATIONS:-prod/migrations}
+    generator:
+      entity_type: Account
+`)
+	t.Setenv(envDatabaseURL, "")
+	t.Setenv(envMigrationsDir, "")
+	t.Setenv(envEnvironment, "")
+	t.Setenv("STAGING_DB_USER", "deploy")
+	t.Setenv("PROD_MIGRATIONS", "")
+
+	t.Run("top-level settings without env", func(t *testing.T) {
+		cfg, err := (&options{configPath: path}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://localhost/dev" {
+			t.Errorf("Expected dev URL, got '%s'", cfg.DatabaseURL)
+		}
+		if want := filepath.Join(dir, "migrations"); cfg.MigrationsDir != want {
+			t.Errorf("Expected '%s', got '%s'", want, cfg.MigrationsDir)
+		}
+	})
+
+	t.Run("profile overrides and interpolates", func(t *testing.T) {
+		cfg, err := (&options{configPath: path, environment: "staging"}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://deploy@staging/app" {
+			t.Errorf("Expected interpolated URL, got '%s'", cfg.DatabaseURL)
+		}
+		if want := filepath.Join(dir, "migrations"); cfg.MigrationsDir != want {
+			t.Errorf("Expected inherited '%s', got '%s'", want, cfg.MigrationsDir)
+		}
+		if cfg.Environment != "staging" {
+			t.Errorf("Expected 'staging', got '%s'", cfg.Environment)
+		}
+	})
+
+	t.Run("defaults and generator from profile", func(t *testing.T) {
+		t.Setenv("PROD_DATABASE_URL", "postgres://prod/app")
+		t.Setenv(envEnvironment, "prod")
+		cfg, err := (&options{configPath: path}).load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if want := filepath.Join(dir, "prod", "migrations"); cfg.MigrationsDir != want {
+			t.Errorf("Expected '%s', got '%s'", want, cfg.MigrationsDir)
+		}
+		if cfg.Generator == nil || cfg.Generator.EntityType != "Account" {
+			t.Errorf("Expected profile generator config, got %+v", cfg.Generator)
+		}
+	})
+
+	t.Run("unset variable", func(t *testing.T) {
+		_, err := (&options{configPath: path, environment: "prod"}).load()
+		if err == nil || !strings.Contains(err.Error(), "PROD_DATABASE_URL") {
+			t.Errorf("Expected unset variable error, got %v", err)
+		}
+
+		// An overridden setting is not interpolated
+		if _, err := (&options{configPath: path, environment: "prod", databaseURL: "postgres://flag"}).load(); err != nil {
+			t.Errorf("Expected no error when overridden, got %v", err)
+		}
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		_, err := (&options{configPath: path, environment: "qa"}).load()
+		if err == nil || !strings.Contains(err.Error(), "available: prod, staging") {
+			t.Errorf("Expected unknown environment error, got %v", err)
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		bad := writeConfig(t, t.TempDir(), "jetorm.yaml", "databse_url: x\n")
+		if _, err := (&options{configPath: bad}).load(); err == nil {
+			t.Error("Expected error for unknown key")
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/satishbabariya/jetorm/analysis/entitytags"
+	"github.com/satishbabariya/jetorm/migration"
+	"github.com/spf13/cobra"
+)
+
+// newDoctorCmd checks the configuration, database connectivity and migrations,
+// printing one line per check. It fails when any check fails.
+func newDoctorCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check configuration, connectivity and migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			report := func(ok bool, check, detail string) {
+				mark := "ok"
+				if !ok {
+					mark = "FAIL"
+					failed++
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "[%-4s] %-12s %s\n", mark, check, detail)
+			}
+
+			switch {
+			case cfg.path == "":
+				report(true, "config", "no config file found, using flags and environment")
+			case cfg.Environment != "":
+				report(true, "config", fmt.Sprintf("%s (environment %s)", cfg.path, cfg.Environment))
+			default:
+				report(true, "config", cfg.path)
+			}
+
+			runner := migration.NewRunner(nil, cfg.MigrationsDir)
+			if _, err := os.Stat(cfg.MigrationsDir); err != nil {
+				report(false, "migrations", err.Error())
+			} else if migrations, err := runner.LoadMigrations(cmd.Context()); err != nil {
+				report(false, "migrations", err.Error())
+			} else {
+				report(true, "migrations", fmt.Sprintf("%d in %s", len(migrations), cfg.MigrationsDir))
+			}
+
+			db, err := cfg.openDB(cmd.Context())
+			if err != nil {
+				report(false, "database", err.Error())
+			} else {
+				defer db.Close()
+
+				var version string
+				if err := db.QueryRowContext(cmd.Context(), "SHOW server_version").Scan(&version); err != nil {
+					report(false, "database", err.Error())
+				} else {
+					report(true, "database", "PostgreSQL "+version)
+				}
+
+				statuses, err := migration.NewRunner(db, cfg.MigrationsDir).Status(cmd.Context())
+				if err != nil {
+					report(false, "schema", err.Error())
+				} else {
+					pending := 0
+					for _, status := range statuses {
+						if status.Status == "pending" {
+							pending++
+						}
+					}
+					report(pending == 0, "schema", fmt.Sprintf("%d pending migration(s)", pending))
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(newDoctorEntitiesCmd())
+	return cmd
+}
+
+// newDoctorEntitiesCmd checks the db and jet tags of the structs in package
+// directories, without a database. Patterns ending in /... include every
+// package below the directory; test files are skipped.
+func newDoctorEntitiesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "entities [packages]",
+		Short: "Report invalid or conflicting entity tags",
+		Long: `Report entity tags that fail or misbehave at runtime: unknown jet options,
+auto_increment on non-integer fields, uuid, enum, auto_now and soft_delete on
+fields of the wrong type, default values that do not fit the field, several
+primary keys and fields mapped to the same column.`,
+		Example: "  jetorm doctor entities ./...",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			dirs, err := vetDirs(args)
+			if err != nil {
+				return err
+			}
+
+			problems := 0
+			for _, dir := range dirs {
+				fset := token.NewFileSet()
+				pkgs, err := parseDir(fset, dir)
+				if err != nil {
+					return err
+				}
+				for _, files := range pkgs {
+					var sources []*ast.File
+					for _, file := range files {
+						if !strings.HasSuffix(fset.Position(file.Pos()).Filename, "_test.go") {
+							sources = append(sources, file)
+						}
+					}
+					diags, err := entitytags.Check(fset, sources)
+					if err != nil {
+						return err
+					}
+					for _, d := range diags {
+						fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", fset.Position(d.Pos), d.Message)
+					}
+					problems += len(diags)
+				}
+			}
+			if problems > 0 {
+				return fmt.Errorf("%d entity problem(s)", problems)
+			}
+			return nil
+		},
+	}
+}
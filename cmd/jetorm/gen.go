@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/satishbabariya/jetorm/generator"
+	"github.com/spf13/cobra"
+)
+
+// newGenCmd generates repository code from the generator section of the
+// config file, overridden by flags
+func newGenCmd(opts *options) *cobra.Command {
+	var (
+		typeName, interfaceName, inputFile, output, packageName, saveMode, jetTables string
+		comments, tests, mocks                                                       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gen [package-dir...]",
+		Short: "Generate repository code",
+		Long: "Generate repository code for the interfaces given by flags or the config file.\n" +
+			"Given package directories, or without either, generate every interface of the\n" +
+			"packages (default .) marked " + generator.RepositoryMarker + ".",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			genCfg := generator.DefaultConfig()
+			if cfg.Generator != nil {
+				copied := *cfg.Generator
+				genCfg = &copied
+			}
+
+			for target, value := range map[*string]string{
+				&genCfg.EntityType:    typeName,
+				&genCfg.InterfaceName: interfaceName,
+				&genCfg.InputFile:     inputFile,
+				&genCfg.OutputFile:    output,
+				&genCfg.OutputPackage: packageName,
+				&genCfg.SaveMode:      saveMode,
+				&genCfg.JetTablesDir:  jetTables,
+			} {
+				if value != "" {
+					*target = value
+				}
+			}
+			if cmd.Flags().Changed("comments") {
+				genCfg.GenerateComments = comments
+			}
+			if cmd.Flags().Changed("tests") {
+				genCfg.GenerateTests = tests
+			}
+			if cmd.Flags().Changed("mocks") {
+				genCfg.GenerateMocks = mocks
+			}
+			// Stamp the schema version from the project's migrations when they exist
+			if genCfg.MigrationsDir == "" {
+				if info, err := os.Stat(cfg.MigrationsDir); err == nil && info.IsDir() {
+					genCfg.MigrationsDir = cfg.MigrationsDir
+				}
+			}
+
+			// Package directories, or nothing configured: generate the marked interfaces
+			var files []string
+			if len(args) > 0 || (genCfg.InterfaceName == "" && genCfg.InputFile == "" && len(genCfg.Repositories) == 0) {
+				if len(args) == 0 {
+					args = []string{"."}
+				}
+				files, err = generator.GeneratePackage(args...)
+			} else {
+				if err := genCfg.Validate(); err != nil {
+					return fmt.Errorf("invalid generator configuration: %w", err)
+				}
+				files, err = generator.Generate(genCfg)
+			}
+			for _, file := range files {
+				fmt.Fprintf(cmd.OutOrStdout(), "Generated %s\n", file)
+			}
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&typeName, "type", "", "Entity type name")
+	flags.StringVar(&interfaceName, "interface", "", "Repository interface name")
+	flags.StringVar(&inputFile, "input", "", "Input Go source file")
+	flags.StringVar(&output, "output", "", "Output file path")
+	flags.StringVar(&packageName, "package", "", "Package name for generated code")
+	flags.BoolVar(&comments, "comments", true, "Generate documentation comments")
+	flags.BoolVar(&tests, "tests", false, "Generate test files")
+	flags.BoolVar(&mocks, "mocks", false, "Generate a mock of the repository interface")
+	flags.StringVar(&saveMode, "save-mode", "", "Save mode: auto, always_insert or always_update")
+	flags.StringVar(&jetTables, "jet-tables", "", "Directory of a Jet table package to write the entity's table to")
+
+	cmd.ValidArgsFunction = func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	cmd.MarkFlagFilename("input", "go")
+	cmd.MarkFlagFilename("output", "go")
+	cmd.MarkFlagDirname("jet-tables")
+	cmd.RegisterFlagCompletionFunc("save-mode", cobra.FixedCompletions(
+		[]string{"auto", "always_insert", "always_update"}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.AddCommand(newGenMapperCmd())
+	return cmd
+}
+
+// newGenMapperCmd generates mapping functions between an entity and an API
+// model declared in the package directory argument (default .)
+func newGenMapperCmd() *cobra.Command {
+	var cfg generator.MapperConfig
+
+	cmd := &cobra.Command{
+		Use:   "mapper [package-dir]",
+		Short: "Generate entity/model mapping functions",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				cfg.Dir = args[0]
+			}
+			file, err := generator.GenerateMappers(cfg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Generated %s\n", file)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.Entity, "entity", "", "Entity struct name")
+	flags.StringVar(&cfg.Model, "model", "", "API model struct name")
+	flags.StringVar(&cfg.OutputFile, "output", "", "Output file path")
+
+	cmd.MarkFlagRequired("entity")
+	cmd.MarkFlagRequired("model")
+	cmd.MarkFlagFilename("output", "go")
+
+	return cmd
+}
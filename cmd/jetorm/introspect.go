@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/satishbabariya/jetorm/generator"
+	"github.com/spf13/cobra"
+)
+
+// newIntrospectCmd prints the tables and columns of a schema, or generates
+// entity structs for them
+func newIntrospectCmd(opts *options) *cobra.Command {
+	var schema, table, entities, pkg string
+
+	cmd := &cobra.Command{
+		Use:   "introspect",
+		Short: "Show tables and columns of the database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			db, err := cfg.openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			if entities != "" {
+				cfg := generator.IntrospectConfig{Schema: schema, Package: pkg, OutputFile: entities}
+				if table != "" {
+					cfg.Tables = []string{table}
+				}
+				file, err := generator.GenerateEntities(cmd.Context(), db, cfg)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully generated: %s\n", file)
+				return nil
+			}
+
+			rows, err := db.QueryContext(cmd.Context(), `
+				SELECT table_name, column_name, data_type, is_nullable, COALESCE(column_default, '')
+				FROM information_schema.columns
+				WHERE table_schema = $1 AND ($2 = '' OR table_name = $2)
+				ORDER BY table_name, ordinal_position`, schema, table)
+			if err != nil {
+				return fmt.Errorf("failed to read columns: %w", err)
+			}
+			defer rows.Close()
+
+			current := ""
+			for rows.Next() {
+				var tableName, column, dataType, nullable, defaultValue string
+				if err := rows.Scan(&tableName, &column, &dataType, &nullable, &defaultValue); err != nil {
+					return err
+				}
+
+				if tableName != current {
+					if current != "" {
+						fmt.Fprintln(cmd.OutOrStdout())
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), tableName)
+					current = tableName
+				}
+
+				line := fmt.Sprintf("  %-30s %s", column, dataType)
+				if nullable == "NO" {
+					line += " NOT NULL"
+				}
+				if defaultValue != "" {
+					line += " DEFAULT " + defaultValue
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			if current == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "No tables found in schema %s\n", schema)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&schema, "schema", "public", "Schema to inspect")
+	cmd.Flags().StringVar(&table, "table", "", "Only show this table")
+	cmd.Flags().StringVar(&entities, "entities", "", "Write entity structs for the tables to this Go file")
+	cmd.Flags().StringVar(&pkg, "package", "", "Package of the entities file (default the file's directory name)")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+// Command jetorm is the JetORM command line tool: code generation, migrations,
+// schema introspection, environment checks, seeding, query plan checks and
+// SQL linting in a single binary.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// newRootCmd builds the jetorm command tree. Shell completion scripts are
+// provided by the generated "completion" subcommand.
+func newRootCmd() *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:           "jetorm",
+		Short:         "JetORM command line tool",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	opts.addFlags(root)
+
+	root.AddCommand(
+		newGenCmd(opts),
+		newMigrateCmd(opts),
+		newIntrospectCmd(opts),
+		newDoctorCmd(opts),
+		newSeedCmd(opts),
+		newPlansCmd(opts),
+		newVetCmd(),
+	)
+	return root
+}
+
+func main() {
+	if err := newRootCmd().ExecuteContext(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/satishbabariya/jetorm/migration"
+	"github.com/spf13/cobra"
+)
+
+// envAppVersion is the application version recorded with applied migrations
+// when --app-version is not given
+const envAppVersion = "JETORM_APP_VERSION"
+
+// newMigrateCmd groups the migration subcommands
+func newMigrateCmd(opts *options) *cobra.Command {
+	var appVersion string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database migrations",
+	}
+	cmd.PersistentFlags().StringVar(&appVersion, "app-version", "", "Application version recorded with applied migrations (env "+envAppVersion+")")
+
+	// withRunner opens the database and runs fn with a runner reporting to stdout
+	withRunner := func(cmd *cobra.Command, fn func(*migration.Runner) error) error {
+		cfg, err := opts.load()
+		if err != nil {
+			return err
+		}
+		db, err := cfg.openDB(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		runner := migration.NewRunner(db, cfg.MigrationsDir)
+		runner.SetEventSink(migration.NewWriterEventSink(cmd.OutOrStdout()))
+		runner.SetAppVersion(firstNonEmpty(appVersion, os.Getenv(envAppVersion)))
+		return fn(runner)
+	}
+
+	// Rollbacks that drop tables or columns need --force or --backup-dir
+	var (
+		force     bool
+		backupDir string
+	)
+	withRollback := func(cmd *cobra.Command, fn func(*migration.Runner) error) error {
+		return withRunner(cmd, func(r *migration.Runner) error {
+			r.SetForce(force)
+			r.SetBackupDir(backupDir)
+			return fn(r)
+		})
+	}
+
+	downCmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last applied migration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRollback(cmd, func(r *migration.Runner) error { return r.Down(cmd.Context()) })
+		},
+	}
+	downToCmd := &cobra.Command{
+		Use:   "down-to <version>",
+		Short: "Roll back migrations newer than version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid version: %w", err)
+			}
+			return withRollback(cmd, func(r *migration.Runner) error { return r.DownTo(cmd.Context(), version) })
+		},
+	}
+	for _, c := range []*cobra.Command{downCmd, downToCmd} {
+		c.Flags().BoolVar(&force, "force", false, "Roll back migrations that drop tables or columns without a backup")
+		c.Flags().StringVar(&backupDir, "backup-dir", "", "Copy tables that the rollback destroys data of to CSV files in this directory first")
+		c.MarkFlagDirname("backup-dir")
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withRunner(cmd, func(r *migration.Runner) error { return r.Up(cmd.Context()) })
+			},
+		},
+		downCmd,
+		downToCmd,
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show applied and pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withRunner(cmd, func(r *migration.Runner) error {
+					statuses, err := r.Status(cmd.Context())
+					if err != nil {
+						return err
+					}
+					writeMigrationStatus(cmd.OutOrStdout(), statuses, useColor(cmd.OutOrStdout()))
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "validate",
+			Short: "Check that every migration has up and down files",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withRunner(cmd, func(r *migration.Runner) error {
+					if err := r.ValidateMigrations(cmd.Context()); err != nil {
+						return err
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), "Migrations validated successfully")
+					return nil
+				})
+			},
+		},
+		newMigrateCreateCmd(opts),
+		newMigrateBackfillCmd(opts),
+	)
+	return cmd
+}
+
+// newMigrateCreateCmd creates a migration file pair. Missing answers are
+// prompted for when stdin is a terminal.
+func newMigrateCreateCmd(opts *options) *cobra.Command {
+	var templateName string
+
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a new migration file pair",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			interactive := isTerminal(os.Stdin)
+			if interactive && (name == "" || !cmd.Flags().Changed("template")) {
+				p := newPrompter(cmd.InOrStdin(), cmd.OutOrStdout())
+				if name == "" {
+					if name, err = p.ask("Migration name", ""); err != nil {
+						return err
+					}
+				}
+				if !cmd.Flags().Changed("template") {
+					choices := append([]string{"blank"}, templateNames()...)
+					if templateName, err = p.choose("Template", choices); err != nil {
+						return err
+					}
+				}
+			}
+			if name == "" {
+				return fmt.Errorf("migration name is required")
+			}
+
+			template, err := migration.ParseTemplate(templateName)
+			if err != nil {
+				return err
+			}
+			if err := migration.NewRunner(nil, cfg.MigrationsDir).CreateMigrationFromTemplate(name, template); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created migration %s in %s\n", name, cfg.MigrationsDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&templateName, "template", "", "Migration template: table, index, data, column, concurrent_index or rename")
+	cmd.RegisterFlagCompletionFunc("template", cobra.FixedCompletions(
+		templateNames(), cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// templateNames lists the names of the migration templates
+func templateNames() []string {
+	names := make([]string, len(migration.Templates))
+	for i, t := range migration.Templates {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// newMigrateBackfillCmd writes the migrations adding a NOT NULL column to a
+// populated table: add, backfill in batches, then promote to NOT NULL
+func newMigrateBackfillCmd(opts *options) *cobra.Command {
+	var backfill migration.ColumnBackfill
+
+	cmd := &cobra.Command{
+		Use:   "backfill <table> <column> <type>",
+		Short: "Create migrations adding a NOT NULL column to a populated table",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+			backfill.Table, backfill.Column, backfill.Type = args[0], args[1], args[2]
+
+			files, err := migration.NewGenerator().GenerateBackfillMigrations(backfill, cfg.MigrationsDir)
+			for _, file := range files {
+				fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", file)
+			}
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&backfill.Default, "default", "", "SQL default for new rows, e.g. \"'active'\"")
+	flags.StringVar(&backfill.Value, "value", "", "SQL expression filling existing rows (default: the default)")
+	flags.StringVar(&backfill.Key, "key", "", "Unique column batches are taken in order of (default id)")
+	flags.IntVar(&backfill.BatchSize, "batch-size", 0, "Rows updated per transaction (default 10000)")
+
+	return cmd
+}
+
+// ANSI colors used for status output
+const (
+	colorReset  = "\033[0m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// useColor reports whether w is a terminal that accepts colors
+func useColor(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f) && os.Getenv("NO_COLOR") == ""
+}
+
+// writeMigrationStatus writes the migrations as an aligned table. The status
+// is the last column so that color codes do not disturb the alignment.
+func writeMigrationStatus(w io.Writer, statuses []migration.MigrationStatus, color bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT\tDURATION\tAPPLIED BY\tAPP VERSION\tSTATUS")
+
+	applied := 0
+	for _, status := range statuses {
+		appliedAt, duration, appliedBy, appVersion := "-", "-", "-", "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		// Migrations applied before the audit columns existed have no details
+		if status.AppliedBy != "" {
+			duration = status.Duration.String()
+			appliedBy = status.AppliedBy
+			if status.Hostname != "" {
+				appliedBy += "@" + status.Hostname
+			}
+		}
+		if status.AppVersion != "" {
+			appVersion = status.AppVersion
+		}
+
+		label := status.Status
+		if status.Status == "applied" {
+			applied++
+		}
+		if color {
+			c := colorYellow
+			if status.Status == "applied" {
+				c = colorGreen
+			}
+			label = c + label + colorReset
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			status.Version, status.Name, appliedAt, duration, appliedBy, appVersion, label)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d applied, %d pending\n", applied, len(statuses)-applied)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satishbabariya/jetorm/migration"
+)
+
+func TestPrompter(t *testing.T) {
+	t.Run("ask uses default on empty answer", func(t *testing.T) {
+		var out bytes.Buffer
+		p := newPrompter(strings.NewReader("\n"), &out)
+		answer, err := p.ask("Name", "users")
+		if err != nil {
+			t.Fatalf("ask failed: %v", err)
+		}
+		if answer != "users" {
+			t.Errorf("Expected 'users', got '%s'", answer)
+		}
+	})
+
+	t.Run("ask repeats until answered", func(t *testing.T) {
+		var out bytes.Buffer
+		p := newPrompter(strings.NewReader("\n  add_users  \n"), &out)
+		answer, err := p.ask("Name", "")
+		if err != nil {
+			t.Fatalf("ask failed: %v", err)
+		}
+		if answer != "add_users" {
+			t.Errorf("Expected 'add_users', got '%s'", answer)
+		}
+	})
+
+	t.Run("ask fails at end of input", func(t *testing.T) {
+		p := newPrompter(strings.NewReader(""), &bytes.Buffer{})
+		if _, err := p.ask("Name", ""); err == nil {
+			t.Error("Expected error at end of input")
+		}
+	})
+
+	t.Run("choose by number and name", func(t *testing.T) {
+		choices := []string{"blank", "table", "index"}
+
+		p := newPrompter(strings.NewReader("3\n"), &bytes.Buffer{})
+		if choice, _ := p.choose("Template", choices); choice != "index" {
+			t.Errorf("Expected 'index', got '%s'", choice)
+		}
+
+		var out bytes.Buffer
+		p = newPrompter(strings.NewReader("view\nTABLE\n"), &out)
+		if choice, _ := p.choose("Template", choices); choice != "table" {
+			t.Errorf("Expected 'table', got '%s'", choice)
+		}
+		if !strings.Contains(out.String(), "Please choose one of") {
+			t.Error("Expected invalid choice to be reported")
+		}
+	})
+}
+
+func TestWriteMigrationStatus(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	statuses := []migration.MigrationStatus{
+		{Version: 1, Name: "create_users", Status: "applied", AppliedAt: &appliedAt,
+			Duration: 1500 * time.Millisecond, AppliedBy: "deploy", Hostname: "ci-1", AppVersion: "v1.2.0"},
+		{Version: 2, Name: "legacy", Status: "applied", AppliedAt: &appliedAt},
+		{Version: 20240102030405, Name: "add_index", Status: "pending"},
+	}
+
+	var plain bytes.Buffer
+	writeMigrationStatus(&plain, statuses, false)
+	lines := strings.Split(plain.String(), "\n")
+
+	// Columns are aligned: NAME starts at the same offset on every row
+	offset := strings.Index(lines[0], "NAME")
+	if strings.Index(lines[1], "create_users") != offset || strings.Index(lines[3], "add_index") != offset {
+		t.Errorf("Expected aligned columns, got:\n%s", plain.String())
+	}
+	if !strings.Contains(lines[1], "2024-01-02 03:04:05") {
+		t.Errorf("Expected applied time, got '%s'", lines[1])
+	}
+	for _, want := range []string{"1.5s", "deploy@ci-1", "v1.2.0"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("Expected '%s' in '%s'", want, lines[1])
+		}
+	}
+	if strings.Contains(lines[2], "0s") {
+		t.Errorf("Expected no duration without audit details, got '%s'", lines[2])
+	}
+	if !strings.Contains(plain.String(), "2 applied, 1 pending") {
+		t.Errorf("Expected summary, got:\n%s", plain.String())
+	}
+	if strings.Contains(plain.String(), "\033[") {
+		t.Error("Expected no color codes")
+	}
+
+	var colored bytes.Buffer
+	writeMigrationStatus(&colored, statuses, true)
+	if !strings.Contains(colored.String(), colorGreen+"applied"+colorReset) {
+		t.Error("Expected applied status in green")
+	}
+	if !strings.Contains(colored.String(), colorYellow+"pending"+colorReset) {
+		t.Error("Expected pending status in yellow")
+	}
+}
+
+func TestMigrateCreateCmd_TemplateCompletion(t *testing.T) {
+	cmd := newRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"__complete", "migrate", "create", "--template", ""})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+	for _, template := range migration.Templates {
+		if !strings.Contains(out.String(), string(template)+"\n") {
+			t.Errorf("Expected %s to be completed, got:\n%s", template, out.String())
+		}
+	}
+}
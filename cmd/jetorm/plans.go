@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/satishbabariya/jetorm/migration"
+	"github.com/spf13/cobra"
+)
+
+// planFlags are the flags shared by the plans subcommands
+type planFlags struct {
+	manifest string
+	golden   string
+	opts     migration.PlanOptions
+}
+
+func (f *planFlags) add(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.manifest, "manifest", "queries.sql", "SQL manifest of the queries, each after a -- name: line")
+	cmd.Flags().StringVar(&f.golden, "golden", "testdata/plans", "Directory of the golden plan files")
+	cmd.Flags().BoolVar(&f.opts.DisableSeqScan, "no-seqscan", false, "Plan with enable_seqscan off, for sparse CI databases")
+}
+
+// newPlansCmd records and checks the plans of critical queries
+func newPlansCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plans",
+		Short: "Guard the query plans of critical queries against regressions",
+	}
+	cmd.AddCommand(newPlansRecordCmd(opts), newPlansCheckCmd(opts))
+	return cmd
+}
+
+// newPlansRecordCmd writes the golden plan of each manifest query
+func newPlansRecordCmd(opts *options) *cobra.Command {
+	flags := &planFlags{}
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Write the plan shape of each manifest query to a golden file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+			queries, err := migration.LoadQueryManifest(flags.manifest)
+			if err != nil {
+				return err
+			}
+
+			db, err := cfg.openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			files, err := migration.RecordPlans(cmd.Context(), db, queries, flags.golden, flags.opts)
+			for _, file := range files {
+				fmt.Fprintf(cmd.OutOrStdout(), "Recorded %s\n", file)
+			}
+			return err
+		},
+	}
+	flags.add(cmd)
+	return cmd
+}
+
+// newPlansCheckCmd fails when a manifest query scans sequentially a
+// relation its golden plan read through an index
+func newPlansCheckCmd(opts *options) *cobra.Command {
+	flags := &planFlags{}
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Compare the plan of each manifest query with its golden file",
+		Long: `Compare the plan of each manifest query with its golden file. The check fails
+when a relation the golden plan read through an index is now only scanned
+sequentially, typically after a migration dropped or changed an index. Other
+plan changes are listed; record the plans again once they are reviewed.`,
+		Example: "  jetorm plans check --manifest queries.sql --golden testdata/plans",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+			queries, err := migration.LoadQueryManifest(flags.manifest)
+			if err != nil {
+				return err
+			}
+
+			db, err := cfg.openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			report, err := migration.CheckPlans(cmd.Context(), db, queries, flags.golden, flags.opts)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			for _, name := range report.Missing {
+				fmt.Fprintf(out, "%s: no golden plan, run jetorm plans record\n", name)
+			}
+			for _, name := range report.Changed {
+				fmt.Fprintf(out, "%s: plan changed\n", name)
+			}
+			for _, regression := range report.Regressions {
+				fmt.Fprintln(out, regression)
+			}
+			if len(report.Regressions) > 0 {
+				return fmt.Errorf("%d plan regression(s)", len(report.Regressions))
+			}
+			return nil
+		},
+	}
+	flags.add(cmd)
+	return cmd
+}
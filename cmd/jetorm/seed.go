@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// newSeedCmd executes the .sql files of the seeds directory in name order,
+// in a single transaction
+func newSeedCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Run SQL seed files in a transaction",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.load()
+			if err != nil {
+				return err
+			}
+
+			files, err := filepath.Glob(filepath.Join(cfg.SeedsDir, "*.sql"))
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				return fmt.Errorf("no seed files found in %s", cfg.SeedsDir)
+			}
+			sort.Strings(files)
+
+			db, err := cfg.openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			tx, err := db.BeginTx(cmd.Context(), nil)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+
+			for _, file := range files {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("failed to read seed file %s: %w", file, err)
+				}
+				if _, err := tx.ExecContext(cmd.Context(), string(content)); err != nil {
+					return fmt.Errorf("failed to run seed file %s: %w", file, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Seeded %s\n", filepath.Base(file))
+			}
+
+			return tx.Commit()
+		},
+	}
+}
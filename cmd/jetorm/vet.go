@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/satishbabariya/jetorm/analysis/sqlinject"
+	"github.com/spf13/cobra"
+)
+
+// newVetCmd runs the sqlinject analyzer over package directories. Patterns
+// ending in /... include every package below the directory.
+func newVetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "vet [packages]",
+		Short: "Report SQL built from strings and unchecked sort fields",
+		Long: `Report SQL text built with fmt.Sprintf or concatenation that is passed to
+Query, QueryOne, QueryRow or Exec, and core.Order sort fields that are not
+constants. Add //nolint:sqlinject to silence a reviewed finding.`,
+		Example: "  jetorm vet ./...",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			dirs, err := vetDirs(args)
+			if err != nil {
+				return err
+			}
+
+			findings := 0
+			for _, dir := range dirs {
+				fset := token.NewFileSet()
+				pkgs, err := parseDir(fset, dir)
+				if err != nil {
+					return err
+				}
+				for _, files := range pkgs {
+					diags, err := sqlinject.Check(fset, files)
+					if err != nil {
+						return err
+					}
+					for _, d := range diags {
+						fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", fset.Position(d.Pos), d.Message)
+					}
+					findings += len(diags)
+				}
+			}
+			if findings > 0 {
+				return fmt.Errorf("%d finding(s)", findings)
+			}
+			return nil
+		},
+	}
+}
+
+// parseDir parses the Go files of a directory, grouping them by package
+// name in name order so external test packages are checked on their own
+func parseDir(fset *token.FileSet, dir string) ([][]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	byName := make(map[string][]*ast.File)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg := file.Name.Name
+		if _, ok := byName[pkg]; !ok {
+			names = append(names, pkg)
+		}
+		byName[pkg] = append(byName[pkg], file)
+	}
+	sort.Strings(names)
+	pkgs := make([][]*ast.File, len(names))
+	for i, name := range names {
+		pkgs[i] = byName[name]
+	}
+	return pkgs, nil
+}
+
+// vetDirs expands package patterns into directories containing Go files,
+// skipping vendor, testdata and hidden directories below /... patterns
+func vetDirs(patterns []string) ([]string, error) {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "/...")
+		if pattern == "..." {
+			root, recursive = ".", true
+		}
+		if !recursive {
+			if _, err := os.Stat(root); err != nil {
+				return nil, err
+			}
+			add(root)
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".go") {
+				add(filepath.Dir(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SumInt64 returns the sum of an integer column over the rows matching spec
+// (all rows for nil), or 0 when none match
+func (r *BaseRepository[T, ID]) SumInt64(ctx context.Context, column string, spec Specification[T]) (int64, error) {
+	sum, err := aggregate[T, ID, int64](ctx, r, "COALESCE(SUM(%s), 0)::bigint", column, spec)
+	if err != nil {
+		return 0, err
+	}
+	return *sum, nil
+}
+
+// SumFloat64 returns the sum of a numeric column over the rows matching spec
+// (all rows for nil), or 0 when none match
+func (r *BaseRepository[T, ID]) SumFloat64(ctx context.Context, column string, spec Specification[T]) (float64, error) {
+	sum, err := aggregate[T, ID, float64](ctx, r, "COALESCE(SUM(%s), 0)::double precision", column, spec)
+	if err != nil {
+		return 0, err
+	}
+	return *sum, nil
+}
+
+// AvgFloat64 returns the average of a numeric column over the rows matching
+// spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) AvgFloat64(ctx context.Context, column string, spec Specification[T]) (float64, error) {
+	return aggregateValue[T, ID, float64](ctx, r, "AVG(%s)::double precision", column, spec)
+}
+
+// MinInt64 returns the smallest value of an integer column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MinInt64(ctx context.Context, column string, spec Specification[T]) (int64, error) {
+	return aggregateValue[T, ID, int64](ctx, r, "MIN(%s)::bigint", column, spec)
+}
+
+// MaxInt64 returns the largest value of an integer column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MaxInt64(ctx context.Context, column string, spec Specification[T]) (int64, error) {
+	return aggregateValue[T, ID, int64](ctx, r, "MAX(%s)::bigint", column, spec)
+}
+
+// MinFloat64 returns the smallest value of a numeric column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MinFloat64(ctx context.Context, column string, spec Specification[T]) (float64, error) {
+	return aggregateValue[T, ID, float64](ctx, r, "MIN(%s)::double precision", column, spec)
+}
+
+// MaxFloat64 returns the largest value of a numeric column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MaxFloat64(ctx context.Context, column string, spec Specification[T]) (float64, error) {
+	return aggregateValue[T, ID, float64](ctx, r, "MAX(%s)::double precision", column, spec)
+}
+
+// MinTime returns the earliest value of a timestamp column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MinTime(ctx context.Context, column string, spec Specification[T]) (time.Time, error) {
+	return aggregateValue[T, ID, time.Time](ctx, r, "MIN(%s)", column, spec)
+}
+
+// MaxTime returns the latest value of a timestamp column over the rows
+// matching spec. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) MaxTime(ctx context.Context, column string, spec Specification[T]) (time.Time, error) {
+	return aggregateValue[T, ID, time.Time](ctx, r, "MAX(%s)", column, spec)
+}
+
+// PercentileCont returns the continuous percentile (0 to 1, e.g. 0.95 for
+// p95) of a numeric column over the rows matching spec, interpolating
+// between values. It returns ErrNotFound when no row has a value.
+func (r *BaseRepository[T, ID]) PercentileCont(ctx context.Context, column string, fraction float64, spec Specification[T]) (float64, error) {
+	if fraction < 0 || fraction > 1 {
+		return 0, fmt.Errorf("%w: percentile %v is outside 0 to 1", ErrInvalidInput, fraction)
+	}
+	return aggregateValue[T, ID, float64](ctx, r, "percentile_cont($1) WITHIN GROUP (ORDER BY %s)", column, spec, fraction)
+}
+
+// PercentileDisc returns the discrete percentile (0 to 1) of a numeric
+// column over the rows matching spec: the first value whose position in
+// the ordering reaches the fraction. It returns ErrNotFound when no row has
+// a value.
+func (r *BaseRepository[T, ID]) PercentileDisc(ctx context.Context, column string, fraction float64, spec Specification[T]) (float64, error) {
+	if fraction < 0 || fraction > 1 {
+		return 0, fmt.Errorf("%w: percentile %v is outside 0 to 1", ErrInvalidInput, fraction)
+	}
+	return aggregateValue[T, ID, float64](ctx, r, "(percentile_disc($1) WITHIN GROUP (ORDER BY %s))::double precision", column, spec, fraction)
+}
+
+// Stddev returns the sample standard deviation of a numeric column over the
+// rows matching spec. It returns ErrNotFound with fewer than two values.
+func (r *BaseRepository[T, ID]) Stddev(ctx context.Context, column string, spec Specification[T]) (float64, error) {
+	return aggregateValue[T, ID, float64](ctx, r, "stddev_samp(%s)::double precision", column, spec)
+}
+
+// Mode returns the most frequent value of a column over the rows matching
+// spec, the smallest one on ties. It returns ErrNotFound when no row has a
+// value.
+func (r *BaseRepository[T, ID]) Mode(ctx context.Context, column string, spec Specification[T]) (interface{}, error) {
+	return aggregateValue[T, ID, interface{}](ctx, r, "mode() WITHIN GROUP (ORDER BY %s)", column, spec)
+}
+
+// ValueCount is a column value with the number of rows holding it
+type ValueCount struct {
+	Value interface{} // nil for NULL
+	Count int64
+}
+
+// ValueCounts returns the values of a column over the rows matching spec
+// with their row counts, most frequent first (ties by value). topN limits
+// the result to the most frequent values; 0 returns all of them.
+func (r *BaseRepository[T, ID]) ValueCounts(ctx context.Context, column string, spec Specification[T], topN int) ([]ValueCount, error) {
+	if err := r.validateColumns([]string{column}); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM %s", column, r.tableName)
+	var args []interface{}
+	var whereClause string
+	if spec != nil {
+		whereClause, args = spec.ToSQL()
+	}
+	query += r.whereClause(whereClause)
+	query += fmt.Sprintf(" GROUP BY %s ORDER BY COUNT(*) DESC, %s", column, column)
+	if topN > 0 {
+		query += fmt.Sprintf(" LIMIT %d", topN)
+	}
+
+	r.logQuery(query, args)
+
+	var rows pgx.Rows
+	var err error
+	if r.tx != nil {
+		rows, err = r.txConn(ctx).Query(ctx, query, args...)
+	} else {
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []ValueCount{}
+	for rows.Next() {
+		var count ValueCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// DistinctValues returns the distinct values of a column over the rows
+// matching spec, in ascending order with NULL (nil) last
+func (r *BaseRepository[T, ID]) DistinctValues(ctx context.Context, column string, spec Specification[T]) ([]interface{}, error) {
+	if err := r.validateColumns([]string{column}); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT DISTINCT %s FROM %s", column, r.tableName)
+	var args []interface{}
+	var whereClause string
+	if spec != nil {
+		whereClause, args = spec.ToSQL()
+	}
+	query += r.whereClause(whereClause)
+	query += " ORDER BY " + column
+
+	r.logQuery(query, args)
+
+	var rows pgx.Rows
+	var err error
+	if r.tx != nil {
+		rows, err = r.txConn(ctx).Query(ctx, query, args...)
+	} else {
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []interface{}{}
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// aggregateValue runs aggregate and turns NULL into ErrNotFound
+func aggregateValue[T any, ID comparable, V any](ctx context.Context, r *BaseRepository[T, ID], expr, column string, spec Specification[T], exprArgs ...interface{}) (V, error) {
+	var zero V
+	value, err := aggregate[T, ID, V](ctx, r, expr, column, spec, exprArgs...)
+	if err != nil {
+		return zero, err
+	}
+	if value == nil {
+		return zero, fmt.Errorf("%w: no %s values", ErrNotFound, column)
+	}
+	return *value, nil
+}
+
+// aggregate selects expr, a format with one %s for the column, over the
+// rows matching spec. It returns nil for NULL. The $1-based placeholders of
+// expr bind exprArgs, numbered before the specification's.
+func aggregate[T any, ID comparable, V any](ctx context.Context, r *BaseRepository[T, ID], expr, column string, spec Specification[T], exprArgs ...interface{}) (*V, error) {
+	if err := r.validateColumns([]string{column}); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT "+expr+" FROM %s", column, r.tableName)
+	var specArgs []interface{}
+	var whereClause string
+	if spec != nil {
+		whereClause, specArgs = spec.ToSQL()
+	}
+	query += RenumberPlaceholders(r.whereClause(whereClause), len(exprArgs)+1)
+	args := append(append([]interface{}{}, exprArgs...), specArgs...)
+
+	r.logQuery(query, args)
+
+	var value *V
+	var err error
+	if r.tx != nil {
+		err = r.txConn(ctx).QueryRow(ctx, query, args...).Scan(&value)
+	} else {
+		err = r.poolConn(ctx).QueryRow(ctx, query, args...).Scan(&value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBaseRepository_Aggregates(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should select one aggregate over the specification", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		spec := GreaterThan[TestUser]("age", 18)
+
+		calls := []func() error{
+			func() error { _, err := repo.SumInt64(ctx, "age", spec); return err },
+			func() error { _, err := repo.AvgFloat64(ctx, "age", nil); return err },
+			func() error { _, err := repo.MaxTime(ctx, "created_at", spec); return err },
+		}
+		for i, call := range calls {
+			if err := call(); !errors.Is(err, ErrDryRun) {
+				t.Errorf("Call %d: expected ErrDryRun, got %v", i, err)
+			}
+		}
+
+		expected := []string{
+			"SELECT COALESCE(SUM(age), 0)::bigint FROM test_user WHERE age > $1",
+			"SELECT AVG(age)::double precision FROM test_user",
+			"SELECT MAX(created_at) FROM test_user WHERE age > $1",
+		}
+		statements := capture.Statements()
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d statements, got %d", len(expected), len(statements))
+		}
+		for i, statement := range statements {
+			if statement.SQL != expected[i] {
+				t.Errorf("Statement %d: expected %q, got %q", i, expected[i], statement.SQL)
+			}
+		}
+	})
+
+	t.Run("should bind percentiles before the specification", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		spec := GreaterThan[TestUser]("age", 18)
+		if _, err := repo.PercentileCont(ctx, "age", 0.95, spec); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.Mode(ctx, "username", nil); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.PercentileDisc(ctx, "age", 1.5, nil); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 2 {
+			t.Fatalf("Expected 2 statements, got %d", len(statements))
+		}
+		expected := "SELECT percentile_cont($1) WITHIN GROUP (ORDER BY age) FROM test_user WHERE age > $2"
+		if statements[0].SQL != expected {
+			t.Errorf("Expected %q, got %q", expected, statements[0].SQL)
+		}
+		if len(statements[0].Args) != 2 || statements[0].Args[0] != 0.95 || statements[0].Args[1] != 18 {
+			t.Errorf("Expected args [0.95 18], got %v", statements[0].Args)
+		}
+		if statements[1].SQL != "SELECT mode() WITHIN GROUP (ORDER BY username) FROM test_user" {
+			t.Errorf("Unexpected SQL %q", statements[1].SQL)
+		}
+	})
+
+	t.Run("should group and count values", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		if _, err := repo.ValueCounts(ctx, "age", GreaterThan[TestUser]("age", 18), 5); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.ValueCounts(ctx, "username", nil, 0); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.DistinctValues(ctx, "age", IsNotNull[TestUser]("email")); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+
+		expected := []string{
+			"SELECT age, COUNT(*) FROM test_user WHERE age > $1 GROUP BY age ORDER BY COUNT(*) DESC, age LIMIT 5",
+			"SELECT username, COUNT(*) FROM test_user GROUP BY username ORDER BY COUNT(*) DESC, username",
+			"SELECT DISTINCT age FROM test_user WHERE email IS NOT NULL ORDER BY age",
+		}
+		statements := capture.Statements()
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d statements, got %d", len(expected), len(statements))
+		}
+		for i, statement := range statements {
+			if statement.SQL != expected[i] {
+				t.Errorf("Statement %d: expected %q, got %q", i, expected[i], statement.SQL)
+			}
+		}
+	})
+
+	t.Run("should reject unknown columns", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		if _, err := repo.MinInt64(ctx, "age); DROP TABLE test_user; --", nil); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+		if _, err := repo.ValueCounts(ctx, "1; --", nil, 0); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+		if len(capture.Statements()) != 0 {
+			t.Error("Expected no statements for an unknown column")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// queryLists holds the fingerprints registered with AllowQueries and DenyQueries
+var queryLists = struct {
+	sync.RWMutex
+	allowed map[string]bool
+	denied  map[string]bool
+}{allowed: make(map[string]bool), denied: make(map[string]bool)}
+
+// rawSQLKey marks a context allowed to run unregistered raw SQL
+type rawSQLKey struct{}
+
+// QueryFingerprint identifies a SQL statement independently of its
+// whitespace and trailing semicolon. jetorm-gen computes it for the queries
+// it generates; the fingerprint of a rejected query is part of the error so
+// it can be added to an allowlist.
+func QueryFingerprint(sql string) string {
+	normalized := strings.TrimSuffix(strings.Join(strings.Fields(sql), " "), ";")
+	sum := sha256.Sum256([]byte(strings.TrimSpace(normalized)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// AllowQueries registers query fingerprints that raw Query, QueryOne and Exec
+// may run when Config.RestrictRawSQL is set. Generated repositories call it
+// from init with the fingerprints of their queries.
+func AllowQueries(fingerprints ...string) {
+	queryLists.Lock()
+	defer queryLists.Unlock()
+	for _, fingerprint := range fingerprints {
+		queryLists.allowed[fingerprint] = true
+	}
+}
+
+// DenyQueries registers query fingerprints that raw Query, QueryOne and Exec
+// always reject, even when allowlisted or run with WithRawSQL
+func DenyQueries(fingerprints ...string) {
+	queryLists.Lock()
+	defer queryLists.Unlock()
+	for _, fingerprint := range fingerprints {
+		queryLists.denied[fingerprint] = true
+	}
+}
+
+// WithRawSQL grants ctx the capability to run raw SQL that is not on the
+// allowlist, for the few code paths that need it under Config.RestrictRawSQL
+func WithRawSQL(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rawSQLKey{}, true)
+}
+
+// checkRawSQL rejects denied queries, and unregistered queries without the
+// raw SQL capability when the database restricts raw SQL
+func (r *BaseRepository[T, ID]) checkRawSQL(ctx context.Context, query string) error {
+	return checkRawSQL(ctx, r.db, query)
+}
+
+// checkRawSQL is BaseRepository.checkRawSQL for raw SQL run on db directly
+func checkRawSQL(ctx context.Context, db *Database, query string) error {
+	fingerprint := QueryFingerprint(query)
+
+	queryLists.RLock()
+	allowed, denied := queryLists.allowed[fingerprint], queryLists.denied[fingerprint]
+	queryLists.RUnlock()
+
+	if denied {
+		return fmt.Errorf("%w: query %s is denied", ErrQueryNotAllowed, fingerprint)
+	}
+	if db == nil || !db.config.RestrictRawSQL || allowed {
+		return nil
+	}
+	if capable, _ := ctx.Value(rawSQLKey{}).(bool); capable {
+		return nil
+	}
+	return fmt.Errorf("%w: query %s is not allowlisted", ErrQueryNotAllowed, fingerprint)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+	a := QueryFingerprint("SELECT * FROM test_user WHERE age > $1")
+	b := QueryFingerprint("SELECT *\n\tFROM test_user\n\tWHERE age > $1;")
+	if a != b {
+		t.Errorf("Expected whitespace-insensitive fingerprints, got %s and %s", a, b)
+	}
+	if a == QueryFingerprint("SELECT * FROM test_user WHERE age < $1") {
+		t.Error("Expected different queries to have different fingerprints")
+	}
+	if len(a) != 32 {
+		t.Errorf("Expected a 32 character fingerprint, got '%s'", a)
+	}
+}
+
+func TestBaseRepository_RestrictRawSQL(t *testing.T) {
+	db := &Database{config: Config{RestrictRawSQL: true}}
+	repo, err := NewBaseRepository[TestUser, int64](db)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := db.DryRun(context.Background())
+
+	t.Run("should reject unknown raw SQL", func(t *testing.T) {
+		_, err := repo.Exec(ctx, "DELETE FROM test_user WHERE age < $1", 18)
+		if !errors.Is(err, ErrQueryNotAllowed) {
+			t.Fatalf("Expected ErrQueryNotAllowed, got %v", err)
+		}
+		if !strings.Contains(err.Error(), QueryFingerprint("DELETE FROM test_user WHERE age < $1")) {
+			t.Errorf("Expected the fingerprint in the error, got %v", err)
+		}
+		if _, err := repo.Query(ctx, "SELECT * FROM test_user"); !errors.Is(err, ErrQueryNotAllowed) {
+			t.Errorf("Expected ErrQueryNotAllowed from Query, got %v", err)
+		}
+		if len(capture.Statements()) != 0 {
+			t.Errorf("Expected nothing to run, got %v", capture.Statements())
+		}
+	})
+
+	t.Run("should run allowlisted SQL and SQL with the capability", func(t *testing.T) {
+		AllowQueries(QueryFingerprint("UPDATE test_user SET age = age + 1"))
+		if _, err := repo.Exec(ctx, "UPDATE test_user\n SET age = age + 1"); err != nil {
+			t.Errorf("Expected allowlisted SQL to run, got %v", err)
+		}
+		if _, err := repo.Exec(WithRawSQL(ctx), "UPDATE test_user SET age = 0"); err != nil {
+			t.Errorf("Expected the raw SQL capability to allow the query, got %v", err)
+		}
+		if len(capture.Statements()) != 2 {
+			t.Errorf("Expected 2 statements, got %d", len(capture.Statements()))
+		}
+	})
+
+	t.Run("should not check statements the repository builds", func(t *testing.T) {
+		db.serverVersion.Store(150000)
+		ctx, capture := db.DryRun(context.Background())
+
+		if _, err := repo.FindAllKeyset(ctx, Cursor{}, 10); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected the keyset query to run, got %v", err)
+		}
+		err := repo.ForEachKeyset(ctx, nil, Cursor{}, 10, func(*TestUser) error { return nil })
+		if !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected the keyset batch to run, got %v", err)
+		}
+		m, err := repo.MergeFrom([]*TestUser{{Email: "a@example.com"}}, "email")
+		if err != nil {
+			t.Fatalf("Failed to build merge: %v", err)
+		}
+		if _, err := repo.Merge(ctx, m.WhenMatchedUpdate("")); err != nil {
+			t.Errorf("Expected the merge to run, got %v", err)
+		}
+		if len(capture.Statements()) != 3 {
+			t.Errorf("Expected 3 statements, got %v", capture.Statements())
+		}
+	})
+
+	t.Run("should reject denied SQL regardless of mode", func(t *testing.T) {
+		DenyQueries(QueryFingerprint("TRUNCATE test_user"))
+		unrestricted, _ := NewBaseRepository[TestUser, int64](nil)
+		if _, err := unrestricted.Exec(WithRawSQL(ctx), "TRUNCATE test_user"); !errors.Is(err, ErrQueryNotAllowed) {
+			t.Errorf("Expected ErrQueryNotAllowed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ObjectStore is the subset of an S3-compatible client the Archiver needs.
+// Adapt the AWS SDK, MinIO or GCS clients by implementing PutObject.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+}
+
+// ObjectExister is implemented by object stores that can tell whether a key
+// is taken. The Archiver checks it before each upload and fails with
+// ErrObjectExists rather than overwrite an archived batch.
+type ObjectExister interface {
+	ObjectExists(ctx context.Context, key string) (bool, error)
+}
+
+// ArchiveOptions configures an Archiver
+type ArchiveOptions struct {
+	BatchSize int         // Rows moved per transaction (default 1000)
+	Table     string      // Archive table (default <table>_archive), used when Store is nil
+	Store     ObjectStore // Object storage receiving JSONL files instead of an archive table
+	Prefix    string      // Object key prefix (default <table>/)
+}
+
+// ArchiveResult reports what an archival run moved
+type ArchiveResult struct {
+	Rows    int64    // Rows removed from the live table
+	Batches int      // Committed batches
+	Objects []string // Keys of the objects written, when archiving to object storage
+}
+
+// Archiver moves rows matching a specification out of an entity's table,
+// either into an archive table with the same columns plus archived_at, or
+// into JSONL objects in S3-compatible storage. Each batch is copied and
+// deleted in its own transaction, so an interrupted run leaves every row
+// either archived or live, never both or neither; an object written for a
+// batch whose commit then fails is left behind as a duplicate.
+//
+// Archival removes rows permanently and ignores soft delete scoping, so
+// soft-deleted rows match too; add the condition to the specification to
+// archive only those.
+type Archiver[T any, ID comparable] struct {
+	repo *BaseRepository[T, ID]
+	opts ArchiveOptions
+	now  func() time.Time
+}
+
+// NewArchiver creates an archiver for the repository's table
+func NewArchiver[T any, ID comparable](repo *BaseRepository[T, ID], opts ArchiveOptions) *Archiver[T, ID] {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.Table == "" {
+		opts.Table = repo.tableName + "_archive"
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = repo.tableName + "/"
+	}
+	clock := SystemClock
+	if c := repo.clock(); c != nil {
+		clock = c
+	}
+	return &Archiver[T, ID]{repo: repo, opts: opts, now: clock.Now}
+}
+
+// CreateArchiveTable creates the archive table with the live table's columns
+// and an archived_at timestamp, if it does not exist yet. Constraints,
+// defaults and indexes are not copied.
+func (a *Archiver[T, ID]) CreateArchiveTable(ctx context.Context) error {
+	for _, query := range a.archiveTableStatements() {
+		a.repo.logQuery(query, nil)
+		if _, err := a.repo.poolConn(ctx).Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to create archive table %s: %w", a.opts.Table, err)
+		}
+	}
+	return nil
+}
+
+func (a *Archiver[T, ID]) archiveTableStatements() []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (LIKE %s)", a.opts.Table, a.repo.tableName),
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()", a.opts.Table),
+	}
+}
+
+// Archive moves every row matching the specification in batches until none
+// are left. The result counts the batches committed before any error. Rows
+// locked by other transactions are skipped rather than waited for.
+func (a *Archiver[T, ID]) Archive(ctx context.Context, spec Specification[T]) (*ArchiveResult, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("%w: specification cannot be nil for archive", ErrInvalidInput)
+	}
+	where, args := spec.ToSQL()
+	if where == "" {
+		return nil, fmt.Errorf("%w: specification must have a WHERE clause for archive", ErrInvalidInput)
+	}
+
+	result := &ArchiveResult{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var moved int64
+		var key string
+		err := a.inTx(ctx, func(q querier) error {
+			var err error
+			if a.opts.Store != nil {
+				moved, key, err = a.archiveToStore(ctx, q, where, args, result.Batches)
+			} else {
+				moved, err = a.archiveToTable(ctx, q, where, args)
+			}
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+		if moved == 0 {
+			return result, nil
+		}
+
+		result.Rows += moved
+		result.Batches++
+		if key != "" {
+			result.Objects = append(result.Objects, key)
+		}
+		if moved < int64(a.opts.BatchSize) {
+			return result, nil
+		}
+	}
+}
+
+// inTx runs fn in the repository's transaction, or in a new one per batch
+func (a *Archiver[T, ID]) inTx(ctx context.Context, fn func(q querier) error) error {
+	r := a.repo
+	if dryRunCapture(ctx) != nil {
+		return fn(r.poolConn(ctx))
+	}
+	if r.tx != nil {
+		return fn(r.txConn(ctx))
+	}
+	return r.db.Transaction(ctx, func(tx *Tx) error {
+		return fn(r.guard(tx.tx))
+	})
+}
+
+// batchCondition selects the primary keys of the next batch, locking them.
+// A shared table only yields the rows of the entity's type.
+func (a *Archiver[T, ID]) batchCondition(where string) string {
+	r := a.repo
+	return fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT %d FOR UPDATE SKIP LOCKED)",
+		r.pkField, r.pkField, r.tableName, r.discriminated(where), r.pkField, a.opts.BatchSize)
+}
+
+// moveStatement deletes a batch and inserts it into the archive table
+func (a *Archiver[T, ID]) moveStatement(where string) string {
+	columns := strings.Join(a.columns(), ", ")
+	return fmt.Sprintf(
+		"WITH moved AS (DELETE FROM %s WHERE %s RETURNING %s) INSERT INTO %s (%s, archived_at) SELECT %s, %s FROM moved",
+		a.repo.tableName, a.batchCondition(where), columns, a.opts.Table, columns, columns, a.repo.nowSQL(),
+	)
+}
+
+func (a *Archiver[T, ID]) archiveToTable(ctx context.Context, q querier, where string, args []interface{}) (int64, error) {
+	query := a.moveStatement(where)
+	a.repo.logQuery(query, args)
+	tag, err := q.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive into %s: %w", a.opts.Table, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (a *Archiver[T, ID]) archiveToStore(ctx context.Context, q querier, where string, args []interface{}, batch int) (int64, string, error) {
+	r := a.repo
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s", r.selectList(), r.tableName, a.batchCondition(where), r.pkField)
+	r.logQuery(query, args)
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	entities, err := r.scanRows(rows)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(entities) == 0 {
+		return 0, "", nil
+	}
+
+	body, err := a.encodeJSONL(entities)
+	if err != nil {
+		return 0, "", err
+	}
+	key := a.objectKey(batch, r.getPKValue(entities[0]), r.getPKValue(entities[len(entities)-1]))
+	if exister, ok := a.opts.Store.(ObjectExister); ok {
+		exists, err := exister.ObjectExists(ctx, key)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to check archive object %s: %w", key, err)
+		}
+		if exists {
+			return 0, "", fmt.Errorf("%w: %s", ErrObjectExists, key)
+		}
+	}
+	if err := a.opts.Store.PutObject(ctx, key, bytes.NewReader(body), int64(len(body)), "application/x-ndjson"); err != nil {
+		return 0, "", fmt.Errorf("failed to upload archive object %s: %w", key, err)
+	}
+
+	ids := make([]interface{}, len(entities))
+	placeholders := make([]string, len(entities))
+	for i, entity := range entities {
+		ids[i] = r.getPKValue(entity)
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	del := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", r.tableName, r.pkField, strings.Join(placeholders, ", "))
+	r.logQuery(del, ids)
+	if _, err := q.Exec(ctx, del, ids...); err != nil {
+		return 0, "", err
+	}
+	return int64(len(entities)), key, nil
+}
+
+// objectKey names a batch's object so that keys sort in archival order. The
+// batch's first and last primary keys keep the keys of runs in the same
+// second, or under a frozen clock, apart: archived rows are gone from the
+// table, so no two batches hold the same keys.
+func (a *Archiver[T, ID]) objectKey(batch int, first, last interface{}) string {
+	return fmt.Sprintf("%s%s-%06d-%s-%s.jsonl", a.opts.Prefix, a.now().UTC().Format("20060102T150405Z"), batch,
+		url.PathEscape(fmt.Sprint(first)), url.PathEscape(fmt.Sprint(last)))
+}
+
+// encodeJSONL writes one JSON object per entity keyed by column name, with
+// the archived_at timestamp an archive table would add
+func (a *Archiver[T, ID]) encodeJSONL(entities []*T) ([]byte, error) {
+	archivedAt := a.now().UTC()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entity := range entities {
+		v := reflect.ValueOf(entity).Elem()
+		record := make(map[string]interface{}, len(a.repo.entity.Fields)+1)
+		for i := range a.repo.entity.Fields {
+			f := &a.repo.entity.Fields[i]
+			if f.Ignored {
+				continue
+			}
+			value := f.columnValue(v)
+			if valuer, ok := value.(driver.Valuer); ok && !f.JSON {
+				converted, err := valuer.Value()
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode archived %s: %w", f.DBName, err)
+				}
+				value = converted
+			}
+			record[f.DBName] = value
+		}
+		record["archived_at"] = archivedAt
+		if err := enc.Encode(record); err != nil {
+			return nil, fmt.Errorf("failed to encode archived row: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// columns lists the entity's stored columns
+func (a *Archiver[T, ID]) columns() []string {
+	return a.repo.entity.columns()
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type memoryStore struct {
+	objects map[string]string
+}
+
+func (s *memoryStore) PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = string(data)
+	return nil
+}
+
+func (s *memoryStore) ObjectExists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+// archiveTx is a transaction whose queries return the next batch of users
+// and whose statements are recorded
+type archiveTx struct {
+	pgx.Tx
+	batches    [][]*TestUser
+	statements []string
+}
+
+func (tx *archiveTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.statements = append(tx.statements, sql)
+	var users []*TestUser
+	if len(tx.batches) > 0 {
+		users, tx.batches = tx.batches[0], tx.batches[1:]
+	}
+	return &userRows{users: users}, nil
+}
+
+func (tx *archiveTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.statements = append(tx.statements, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+// userRows is an in-memory pgx.Rows of TestUser rows, scanned in column order
+type userRows struct {
+	fakeRows
+	users []*TestUser
+}
+
+func (r *userRows) Next() bool {
+	if r.pos >= len(r.users) {
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *userRows) Scan(dest ...any) error {
+	u := r.users[r.pos-1]
+	for i, value := range []any{u.ID, u.Email, u.Username, u.Age, u.CreatedAt, u.UpdatedAt} {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if target.Kind() == reflect.Ptr { // Nullable columns scan through a pointer
+			target.Set(reflect.New(target.Type().Elem()))
+			target = target.Elem()
+		}
+		target.Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func TestArchiver(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	db := &Database{}
+	archivedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should default the archive table, prefix and batch size", func(t *testing.T) {
+		archiver := NewArchiver(repo, ArchiveOptions{})
+		if archiver.opts.Table != "test_user_archive" || archiver.opts.Prefix != "test_user/" || archiver.opts.BatchSize != 1000 {
+			t.Errorf("Unexpected defaults: %+v", archiver.opts)
+		}
+
+		statements := archiver.archiveTableStatements()
+		if statements[0] != "CREATE TABLE IF NOT EXISTS test_user_archive (LIKE test_user)" {
+			t.Errorf("Unexpected SQL '%s'", statements[0])
+		}
+		if !strings.Contains(statements[1], "ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ") {
+			t.Errorf("Unexpected SQL '%s'", statements[1])
+		}
+	})
+
+	t.Run("should move a batch into the archive table", func(t *testing.T) {
+		archiver := NewArchiver(repo, ArchiveOptions{BatchSize: 500})
+		ctx, capture := db.DryRun(context.Background())
+
+		result, err := archiver.Archive(ctx, LessThan[TestUser]("created_at", archivedAt))
+		if err != nil {
+			t.Fatalf("Expected archive to succeed, got %v", err)
+		}
+		if result.Rows != 0 || result.Batches != 0 {
+			t.Errorf("Expected nothing moved in a dry run, got %+v", result)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 1 {
+			t.Fatalf("Expected 1 statement, got %d", len(statements))
+		}
+		expected := "WITH moved AS (DELETE FROM test_user WHERE id IN (SELECT id FROM test_user WHERE created_at < $1 ORDER BY id LIMIT 500 FOR UPDATE SKIP LOCKED) " +
+			"RETURNING id, email, username, age, created_at, updated_at) " +
+			"INSERT INTO test_user_archive (id, email, username, age, created_at, updated_at, archived_at) " +
+			"SELECT id, email, username, age, created_at, updated_at, NOW() FROM moved"
+		if statements[0].SQL != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, statements[0].SQL)
+		}
+	})
+
+	t.Run("should select a locked batch for object storage", func(t *testing.T) {
+		archiver := NewArchiver(repo, ArchiveOptions{Store: &memoryStore{objects: map[string]string{}}, BatchSize: 10})
+		ctx, capture := db.DryRun(context.Background())
+
+		if _, err := archiver.Archive(ctx, Equal[TestUser]("age", 0)); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		expected := "SELECT * FROM test_user WHERE id IN (SELECT id FROM test_user WHERE age = $1 ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED) ORDER BY id"
+		if statements := capture.Statements(); len(statements) != 1 || statements[0].SQL != expected {
+			t.Errorf("Expected '%s', got %v", expected, statements)
+		}
+	})
+
+	t.Run("should encode rows as JSONL keyed by column", func(t *testing.T) {
+		archiver := NewArchiver(repo, ArchiveOptions{Prefix: "archive/users/"})
+		archiver.now = func() time.Time { return archivedAt }
+
+		body, err := archiver.encodeJSONL([]*TestUser{
+			{ID: 1, Email: "a@example.com", Username: "a", Age: 30},
+			{ID: 2, Email: "b@example.com", Username: "b", Age: 40},
+		})
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(lines))
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+			t.Fatalf("Invalid JSON line: %v", err)
+		}
+		if record["email"] != "b@example.com" || record["id"] != float64(2) || record["archived_at"] != "2024-03-01T12:00:00Z" {
+			t.Errorf("Unexpected record: %v", record)
+		}
+
+		if key := archiver.objectKey(3, int64(7), int64(9)); key != "archive/users/20240301T120000Z-000003-7-9.jsonl" {
+			t.Errorf("Unexpected object key '%s'", key)
+		}
+	})
+
+	t.Run("should keep the objects of runs under a frozen clock apart", func(t *testing.T) {
+		store := &memoryStore{objects: map[string]string{}}
+		tx := &archiveTx{batches: [][]*TestUser{
+			{{ID: 1, Email: "a@example.com"}, {ID: 2, Email: "b@example.com"}},
+			{{ID: 3, Email: "c@example.com"}},
+		}}
+		bound := repo.WithTx(&Tx{tx: tx}).(*BaseRepository[TestUser, int64]).WithTestMode()
+		archiver := NewArchiver(bound, ArchiveOptions{Store: store, BatchSize: 10})
+
+		var keys []string
+		for run := 0; run < 2; run++ {
+			result, err := archiver.Archive(context.Background(), Equal[TestUser]("age", 0))
+			if err != nil {
+				t.Fatalf("Run %d: expected archive to succeed, got %v", run, err)
+			}
+			keys = append(keys, result.Objects...)
+		}
+		if len(keys) != 2 || keys[0] == keys[1] || len(store.objects) != 2 {
+			t.Fatalf("Expected 2 distinct objects, got %v", keys)
+		}
+		if keys[0] != "test_user/20000101T000000Z-000000-1-2.jsonl" || keys[1] != "test_user/20000101T000000Z-000000-3-3.jsonl" {
+			t.Errorf("Unexpected object keys %v", keys)
+		}
+	})
+
+	t.Run("should not overwrite an existing object", func(t *testing.T) {
+		store := &memoryStore{objects: map[string]string{"test_user/20000101T000000Z-000000-1-1.jsonl": "kept"}}
+		tx := &archiveTx{batches: [][]*TestUser{{{ID: 1, Email: "a@example.com"}}}}
+		bound := repo.WithTx(&Tx{tx: tx}).(*BaseRepository[TestUser, int64]).WithTestMode()
+
+		_, err := NewArchiver(bound, ArchiveOptions{Store: store}).Archive(context.Background(), Equal[TestUser]("age", 0))
+		if !errors.Is(err, ErrObjectExists) {
+			t.Fatalf("Expected ErrObjectExists, got %v", err)
+		}
+		if store.objects["test_user/20000101T000000Z-000000-1-1.jsonl"] != "kept" {
+			t.Error("Expected the existing object to be kept")
+		}
+		for _, statement := range tx.statements {
+			if strings.HasPrefix(statement, "DELETE") {
+				t.Errorf("Expected no rows deleted, got '%s'", statement)
+			}
+		}
+	})
+
+	t.Run("should require a condition", func(t *testing.T) {
+		archiver := NewArchiver(repo, ArchiveOptions{})
+		if _, err := archiver.Archive(context.Background(), nil); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
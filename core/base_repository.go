@@ -2,13 +2,14 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/satishbabariya/jetorm/internal/schema"
 )
 
 // BaseRepository provides the base implementation for Repository interface
@@ -18,25 +19,28 @@ type BaseRepository[T any, ID comparable] struct {
 	entity   *Entity
 	tableName string
 	pkField  string
+	softDelete *Field   // soft delete column, nil if hard deletes are used
+	unscoped   bool     // include soft-deleted rows and delete permanently
+	lockMode   LockMode
+	returning  []string // RETURNING columns for saves, nil for all columns
+	saveMode   SaveMode
+	testClock  Clock       // clock of WithTestMode, overriding the configured one
+	testIDs    IDGenerator // ID generator of WithTestMode, overriding the configured one
 }
 
 // NewBaseRepository creates a new base repository
 func NewBaseRepository[T any, ID comparable](db *Database) (*BaseRepository[T, ID], error) {
-	var zero T
-	entity, err := EntityMetadata(zero)
+	entity, err := RegisterEntity[T]()
 	if err != nil {
 		return nil, err
 	}
 
-	if entity.PrimaryKey == nil {
-		return nil, ErrNoPrimaryKey
-	}
-
 	return &BaseRepository[T, ID]{
-		db:        db,
-		entity:    entity,
-		tableName: entity.TableName,
-		pkField:   entity.PrimaryKey.DBName,
+		db:         db,
+		entity:     entity,
+		tableName:  entity.TableName,
+		pkField:    entity.PrimaryKey.DBName,
+		softDelete: softDeleteField(db, entity),
 	}, nil
 }
 
@@ -49,27 +53,21 @@ func (r *BaseRepository[T, ID]) Save(ctx context.Context, entity *T) (*T, error)
 }
 
 func (r *BaseRepository[T, ID]) saveWithPool(ctx context.Context, entity *T) (*T, error) {
-	// Get primary key value
-	pkValue := r.getPKValue(entity)
-	
-	// Check if entity exists (has non-zero primary key)
-	if r.isZeroValue(pkValue) {
+	// Insert or update according to the save mode
+	if r.isInsert(entity) {
 		// Insert
-		return r.insert(ctx, entity, r.db.pool)
+		return r.insert(ctx, entity, r.poolConn(ctx))
 	}
 	
 	// Update
-	return r.update(ctx, entity, r.db.pool)
+	return r.update(ctx, entity, r.poolConn(ctx))
 }
 
 func (r *BaseRepository[T, ID]) saveWithTx(ctx context.Context, entity *T) (*T, error) {
-	tx := r.tx.tx
+	tx := r.txConn(ctx)
 	
-	// Get primary key value
-	pkValue := r.getPKValue(entity)
-	
-	// Check if entity exists (has non-zero primary key)
-	if r.isZeroValue(pkValue) {
+	// Insert or update according to the save mode
+	if r.isInsert(entity) {
 		// Insert
 		return r.insertTx(ctx, entity, tx)
 	}
@@ -78,121 +76,209 @@ func (r *BaseRepository[T, ID]) saveWithTx(ctx context.Context, entity *T) (*T,
 	return r.updateTx(ctx, entity, tx)
 }
 
-func (r *BaseRepository[T, ID]) insert(ctx context.Context, entity *T, pool *pgxpool.Pool) (*T, error) {
-	fields, values, placeholders := r.buildInsertQuery(entity)
+func (r *BaseRepository[T, ID]) insert(ctx context.Context, entity *T, pool querier) (*T, error) {
+	if err := r.validateEnums(entity); err != nil {
+		return nil, err
+	}
 	
-	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s) RETURNING *",
-		r.tableName,
-		strings.Join(fields, ", "),
-		strings.Join(placeholders, ", "),
-	)
+	query, values := r.insertStatement(entity)
 	
 	r.logQuery(query, values)
 	
 	row := pool.QueryRow(ctx, query, values...)
-	result := new(T)
-	if err := r.scanRow(row, result); err != nil {
+	return r.scanReturning(row, entity)
+}
+
+func (r *BaseRepository[T, ID]) insertTx(ctx context.Context, entity *T, tx querier) (*T, error) {
+	if err := r.validateEnums(entity); err != nil {
 		return nil, err
 	}
 	
-	return result, nil
-}
-
-func (r *BaseRepository[T, ID]) insertTx(ctx context.Context, entity *T, tx pgx.Tx) (*T, error) {
-	fields, values, placeholders := r.buildInsertQuery(entity)
-	
-	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s) RETURNING *",
-		r.tableName,
-		strings.Join(fields, ", "),
-		strings.Join(placeholders, ", "),
-	)
+	query, values := r.insertStatement(entity)
 	
 	r.logQuery(query, values)
 	
 	row := tx.QueryRow(ctx, query, values...)
-	result := new(T)
-	if err := r.scanRow(row, result); err != nil {
+	return r.scanReturning(row, entity)
+}
+
+func (r *BaseRepository[T, ID]) update(ctx context.Context, entity *T, pool querier) (*T, error) {
+	if err := r.validateEnums(entity); err != nil {
 		return nil, err
 	}
 	
-	return result, nil
-}
-
-func (r *BaseRepository[T, ID]) update(ctx context.Context, entity *T, pool *pgxpool.Pool) (*T, error) {
-	fields, values := r.buildUpdateQuery(entity)
-	pkValue := r.getPKValue(entity)
-	values = append(values, pkValue)
-	
-	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s = $%d RETURNING *",
-		r.tableName,
-		strings.Join(fields, ", "),
-		r.pkField,
-		len(values),
-	)
+	query, values := r.updateStatement(entity)
 	
 	r.logQuery(query, values)
 	
 	row := pool.QueryRow(ctx, query, values...)
-	result := new(T)
-	if err := r.scanRow(row, result); err != nil {
-		return nil, err
-	}
-	
-	return result, nil
+	return r.scanReturning(row, entity)
 }
 
-func (r *BaseRepository[T, ID]) updateTx(ctx context.Context, entity *T, tx pgx.Tx) (*T, error) {
-	fields, values := r.buildUpdateQuery(entity)
-	pkValue := r.getPKValue(entity)
-	values = append(values, pkValue)
+func (r *BaseRepository[T, ID]) updateTx(ctx context.Context, entity *T, tx querier) (*T, error) {
+	if err := r.validateEnums(entity); err != nil {
+		return nil, err
+	}
 	
-	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s = $%d RETURNING *",
-		r.tableName,
-		strings.Join(fields, ", "),
-		r.pkField,
-		len(values),
-	)
+	query, values := r.updateStatement(entity)
 	
 	r.logQuery(query, values)
 	
 	row := tx.QueryRow(ctx, query, values...)
-	result := new(T)
-	if err := r.scanRow(row, result); err != nil {
+	return r.scanReturning(row, entity)
+}
+
+// SaveAll saves multiple entities using a single pgx batch
+func (r *BaseRepository[T, ID]) SaveAll(ctx context.Context, entities []*T) ([]*T, error) {
+	result, err := r.SaveAllWithResult(ctx, entities)
+	if err != nil {
 		return nil, err
 	}
-	
-	return result, nil
+	return result.Entities, nil
 }
 
-// SaveAll saves multiple entities
-func (r *BaseRepository[T, ID]) SaveAll(ctx context.Context, entities []*T) ([]*T, error) {
-	results := make([]*T, 0, len(entities))
-	for _, entity := range entities {
-		saved, err := r.Save(ctx, entity)
+// SaveAllWithResult saves multiple entities using a single pgx batch and
+// reports inserted/updated counts and primary keys in input order
+func (r *BaseRepository[T, ID]) SaveAllWithResult(ctx context.Context, entities []*T) (*BatchResult[T, ID], error) {
+	result := &BatchResult[T, ID]{
+		Entities: make([]*T, 0, len(entities)),
+		IDs:      make([]ID, 0, len(entities)),
+	}
+	if len(entities) == 0 {
+		return result, nil
+	}
+
+	// Queue every INSERT/UPDATE so the whole slice costs a single round trip
+	batch := &pgx.Batch{}
+	inserts := make([]bool, len(entities))
+	for i, entity := range entities {
+		if err := r.validateEnums(entity); err != nil {
+			return nil, fmt.Errorf("save failed at index %d: %w", i, err)
+		}
+		inserts[i] = r.isInsert(entity)
+		query, values := r.saveStatement(entity)
+		r.logQuery(query, values)
+		batch.Queue(query, values...)
+	}
+
+	var br pgx.BatchResults
+	if r.tx != nil {
+		br = r.txConn(ctx).SendBatch(ctx, batch)
+	} else {
+		br = r.poolConn(ctx).SendBatch(ctx, batch)
+	}
+	defer br.Close()
+
+	for i, entity := range entities {
+		saved, err := r.scanReturning(br.QueryRow(), entity)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("save failed at index %d: %w", i, err)
+		}
+		id, _ := r.getPKValue(saved).(ID)
+		result.Entities = append(result.Entities, saved)
+		result.IDs = append(result.IDs, id)
+		if inserts[i] {
+			result.Inserted++
+		} else {
+			result.Updated++
 		}
-		results = append(results, saved)
 	}
-	return results, nil
+
+	if err := br.Close(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// saveItemSavepoint is the savepoint each SaveAllIsolated item runs under
+const saveItemSavepoint = "jetorm_save_item"
+
+// SaveAllIsolated saves entities in one transaction, each under its own
+// savepoint, so an item that fails is rolled back and reported in
+// BatchResult.Failed while the others commit. It runs in the repository's
+// transaction when there is one, otherwise in a new transaction. Errors that
+// are not caused by an item, such as a cancelled context, roll back the
+// whole transaction.
+func (r *BaseRepository[T, ID]) SaveAllIsolated(ctx context.Context, entities []*T) (*BatchResult[T, ID], error) {
+	result := &BatchResult[T, ID]{
+		Entities: make([]*T, 0, len(entities)),
+		IDs:      make([]ID, 0, len(entities)),
+	}
+	if len(entities) == 0 {
+		return result, nil
+	}
+
+	save := func(q querier) error {
+		for i, entity := range entities {
+			if err := r.validateEnums(entity); err != nil {
+				result.Failed = append(result.Failed, BatchFailure{Index: i, Err: err})
+				continue
+			}
+			if _, err := q.Exec(ctx, "SAVEPOINT "+saveItemSavepoint); err != nil {
+				return fmt.Errorf("save failed at index %d: %w", i, err)
+			}
+
+			insert := r.isInsert(entity)
+			query, values := r.saveStatement(entity)
+			r.logQuery(query, values)
+			saved, err := r.scanReturning(q.QueryRow(ctx, query, values...), entity)
+			if err != nil {
+				if errors.Is(err, ErrDryRun) || ctx.Err() != nil {
+					return err
+				}
+				if _, rbErr := q.Exec(ctx, "ROLLBACK TO SAVEPOINT "+saveItemSavepoint); rbErr != nil {
+					return fmt.Errorf("save failed at index %d: %w", i, rbErr)
+				}
+				result.Failed = append(result.Failed, BatchFailure{Index: i, Err: err})
+				continue
+			}
+			if _, err := q.Exec(ctx, "RELEASE SAVEPOINT "+saveItemSavepoint); err != nil {
+				return fmt.Errorf("save failed at index %d: %w", i, err)
+			}
+
+			id, _ := r.getPKValue(saved).(ID)
+			result.Entities = append(result.Entities, saved)
+			result.IDs = append(result.IDs, id)
+			if insert {
+				result.Inserted++
+			} else {
+				result.Updated++
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if dryRunCapture(ctx) != nil {
+		err = save(r.poolConn(ctx))
+	} else if r.tx != nil {
+		err = save(r.txConn(ctx))
+	} else {
+		err = r.db.Transaction(ctx, func(tx *Tx) error {
+			return save(r.guard(tx.tx))
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
-// Update updates an existing entity (must have non-zero primary key)
+// Update updates an existing entity. Under SaveAuto the primary key must be
+// non-zero; other save modes allow zero-valued natural keys.
 func (r *BaseRepository[T, ID]) Update(ctx context.Context, entity *T) (*T, error) {
 	pkValue := r.getPKValue(entity)
-	if r.isZeroValue(pkValue) {
+	if r.saveMode == SaveAuto && r.isZeroValue(pkValue) {
 		return nil, ErrInvalidID
 	}
 
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		return r.updateTx(ctx, entity, tx)
 	}
-	return r.update(ctx, entity, r.db.pool)
+	return r.update(ctx, entity, r.poolConn(ctx))
 }
 
 // UpdateAll updates multiple entities
@@ -208,17 +294,151 @@ func (r *BaseRepository[T, ID]) UpdateAll(ctx context.Context, entities []*T) ([
 	return results, nil
 }
 
+// UpdateFields updates only the given columns of the entity with the given ID.
+// Keys may be column names or struct field names; auto_now columns are set to NOW()
+// unless provided. Returns ErrNotFound if no row matches.
+func (r *BaseRepository[T, ID]) UpdateFields(ctx context.Context, id ID, fields map[string]interface{}) (*T, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
+	}
+
+	query, values, err := r.updateFieldsStatement(id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logQuery(query, values)
+
+	var row pgx.Row
+	if r.tx != nil {
+		row = r.txConn(ctx).QueryRow(ctx, query, values...)
+	} else {
+		row = r.poolConn(ctx).QueryRow(ctx, query, values...)
+	}
+
+	result := new(T)
+	if err := r.scanRow(row, result); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateChanged updates only the columns that differ between original and modified.
+// If nothing changed, modified is returned without querying the database.
+func (r *BaseRepository[T, ID]) UpdateChanged(ctx context.Context, original, modified *T) (*T, error) {
+	id, ok := r.getPKValue(modified).(ID)
+	if !ok || r.isZeroValue(id) {
+		return nil, ErrInvalidID
+	}
+
+	changes := r.ChangedFields(original, modified)
+	if len(changes) == 0 {
+		return modified, nil
+	}
+
+	return r.UpdateFields(ctx, id, changes)
+}
+
+// ChangedFields returns the column values of modified that differ from original,
+// keyed by column name. The primary key and ignored fields are never included.
+func (r *BaseRepository[T, ID]) ChangedFields(original, modified *T) map[string]interface{} {
+	ov := reflect.ValueOf(original).Elem()
+	mv := reflect.ValueOf(modified).Elem()
+
+	changes := make(map[string]interface{})
+	for _, fieldMeta := range r.entity.Fields {
+		if fieldMeta.PrimaryKey || fieldMeta.Ignored || r.isSoftDeleteField(fieldMeta) {
+			continue
+		}
+
+		newValue := fieldMeta.valueOf(mv).Interface()
+		if !reflect.DeepEqual(fieldMeta.valueOf(ov).Interface(), newValue) {
+			changes[fieldMeta.DBName] = newValue
+		}
+	}
+
+	return changes
+}
+
+// updateFieldsStatement builds an UPDATE for a subset of columns in a stable order
+func (r *BaseRepository[T, ID]) updateFieldsStatement(id ID, fields map[string]interface{}) (string, []interface{}, error) {
+	sets, values, err := r.setClause(fields)
+	if err != nil {
+		return "", nil, err
+	}
+	values = append(values, id)
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s RETURNING %s",
+		r.tableName,
+		strings.Join(sets, ", "),
+		r.discriminated(fmt.Sprintf("%s = $%d", r.pkField, len(values))),
+		r.selectList(),
+	)
+
+	return query, values, nil
+}
+
+// setClause builds the SET assignments of a partial update, in field order,
+// binding the values from $1
+func (r *BaseRepository[T, ID]) setClause(fields map[string]interface{}) ([]string, []interface{}, error) {
+	byColumn := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		fieldMeta := r.lookupField(name)
+		if fieldMeta == nil || fieldMeta.Ignored {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnknownField, name)
+		}
+		if fieldMeta.PrimaryKey {
+			return nil, nil, fmt.Errorf("%w: cannot update primary key %s", ErrInvalidInput, name)
+		}
+		if err := fieldMeta.checkEnumValue(reflect.ValueOf(value)); err != nil {
+			return nil, nil, err
+		}
+		byColumn[fieldMeta.DBName] = value
+	}
+
+	sets := make([]string, 0, len(byColumn))
+	values := make([]interface{}, 0, len(byColumn)+1)
+	for _, fieldMeta := range r.entity.Fields {
+		if value, ok := byColumn[fieldMeta.DBName]; ok {
+			if fieldMeta.JSON {
+				value = jsonValue{v: value}
+			}
+			values = append(values, value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", fieldMeta.DBName, len(values)))
+		} else if fieldMeta.AutoNow {
+			sets = append(sets, fmt.Sprintf("%s = %s", fieldMeta.DBName, r.nowSQL()))
+		}
+	}
+
+	return sets, values, nil
+}
+
+// lookupField finds entity field metadata by column name or struct field name
+func (r *BaseRepository[T, ID]) lookupField(name string) *Field {
+	for i := range r.entity.Fields {
+		if r.entity.Fields[i].DBName == name || r.entity.Fields[i].Name == name {
+			return &r.entity.Fields[i]
+		}
+	}
+	return nil
+}
+
 // FindByID finds an entity by ID
 func (r *BaseRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", r.tableName, r.pkField)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s%s", r.selectList(), r.tableName, r.scoped(r.pkField+" = $1"), r.lockClause())
 	r.logQuery(query, []interface{}{id})
 	
 	var row pgx.Row
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		row = tx.QueryRow(ctx, query, id)
 	} else {
-		row = r.db.pool.QueryRow(ctx, query, id)
+		row = r.poolConn(ctx).QueryRow(ctx, query, id)
 	}
 	
 	result := new(T)
@@ -234,16 +454,16 @@ func (r *BaseRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error)
 
 // FindAll finds all entities
 func (r *BaseRepository[T, ID]) FindAll(ctx context.Context) ([]*T, error) {
-	query := fmt.Sprintf("SELECT * FROM %s", r.tableName)
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s", r.selectList(), r.tableName, r.whereClause(""), r.lockClause())
 	r.logQuery(query, nil)
 	
 	var rows pgx.Rows
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		rows, err = tx.Query(ctx, query)
 	} else {
-		rows, err = r.db.pool.Query(ctx, query)
+		rows, err = r.poolConn(ctx).Query(ctx, query)
 	}
 	
 	if err != nil {
@@ -254,12 +474,14 @@ func (r *BaseRepository[T, ID]) FindAll(ctx context.Context) ([]*T, error) {
 	return r.scanRows(rows)
 }
 
-// FindAllByIDs finds entities by IDs
+// FindAllByIDs finds entities by IDs, in the order the database returns
+// them. Repeated IDs are queried once.
 func (r *BaseRepository[T, ID]) FindAllByIDs(ctx context.Context, ids []ID) ([]*T, error) {
 	if len(ids) == 0 {
 		return []*T{}, nil
 	}
 	
+	ids = distinctIDs(ids)
 	placeholders := make([]string, len(ids))
 	args := make([]interface{}, len(ids))
 	for i, id := range ids {
@@ -268,20 +490,21 @@ func (r *BaseRepository[T, ID]) FindAllByIDs(ctx context.Context, ids []ID) ([]*
 	}
 	
 	query := fmt.Sprintf(
-		"SELECT * FROM %s WHERE %s IN (%s)",
+		"SELECT %s FROM %s WHERE %s%s",
+		r.selectList(),
 		r.tableName,
-		r.pkField,
-		strings.Join(placeholders, ", "),
+		r.scoped(fmt.Sprintf("%s IN (%s)", r.pkField, strings.Join(placeholders, ", "))),
+		r.lockClause(),
 	)
 	r.logQuery(query, args)
 	
 	var rows pgx.Rows
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		rows, err = tx.Query(ctx, query, args...)
 	} else {
-		rows, err = r.db.pool.Query(ctx, query, args...)
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
 	}
 	
 	if err != nil {
@@ -292,6 +515,56 @@ func (r *BaseRepository[T, ID]) FindAllByIDs(ctx context.Context, ids []ID) ([]*
 	return r.scanRows(rows)
 }
 
+// FindAllByIDsOrdered finds entities by IDs and returns them in the order of
+// ids. IDs without a row are skipped and a repeated ID yields its entity once,
+// at its first position.
+func (r *BaseRepository[T, ID]) FindAllByIDsOrdered(ctx context.Context, ids []ID) ([]*T, error) {
+	byID, err := r.FindMapByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*T, 0, len(byID))
+	for _, id := range distinctIDs(ids) {
+		if entity, ok := byID[id]; ok {
+			results = append(results, entity)
+		}
+	}
+	return results, nil
+}
+
+// FindMapByIDs finds entities by IDs and returns them keyed by ID. IDs
+// without a row have no entry.
+func (r *BaseRepository[T, ID]) FindMapByIDs(ctx context.Context, ids []ID) (map[ID]*T, error) {
+	entities, err := r.FindAllByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[ID]*T, len(entities))
+	for _, entity := range entities {
+		id, ok := r.getPKValue(entity).(ID)
+		if !ok {
+			return nil, fmt.Errorf("%w: primary key of %s is not a %T", ErrInvalidID, r.entity.Type, id)
+		}
+		byID[id] = entity
+	}
+	return byID, nil
+}
+
+// distinctIDs returns ids without repeats, keeping the first occurrence of each
+func distinctIDs[ID comparable](ids []ID) []ID {
+	seen := make(map[ID]bool, len(ids))
+	distinct := make([]ID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
+
 // Delete deletes an entity
 func (r *BaseRepository[T, ID]) Delete(ctx context.Context, entity *T) error {
 	pkValue := r.getPKValue(entity)
@@ -300,20 +573,45 @@ func (r *BaseRepository[T, ID]) Delete(ctx context.Context, entity *T) error {
 
 // DeleteByID deletes an entity by ID
 func (r *BaseRepository[T, ID]) DeleteByID(ctx context.Context, id ID) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", r.tableName, r.pkField)
+	query := r.deleteStatement(r.pkField + " = $1")
 	r.logQuery(query, []interface{}{id})
 	
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		_, err = tx.Exec(ctx, query, id)
 	} else {
-		_, err = r.db.pool.Exec(ctx, query, id)
+		_, err = r.poolConn(ctx).Exec(ctx, query, id)
 	}
 	
 	return err
 }
 
+// DeleteByIDReturning deletes an entity by ID and returns the deleted row.
+// With soft delete the returned entity carries the new deleted-at value.
+// Returns ErrNotFound if no row matches.
+func (r *BaseRepository[T, ID]) DeleteByIDReturning(ctx context.Context, id ID) (*T, error) {
+	query := r.deleteStatement(r.pkField+" = $1") + " RETURNING " + r.selectList()
+	r.logQuery(query, []interface{}{id})
+
+	var row pgx.Row
+	if r.tx != nil {
+		row = r.txConn(ctx).QueryRow(ctx, query, id)
+	} else {
+		row = r.poolConn(ctx).QueryRow(ctx, query, id)
+	}
+
+	result := new(T)
+	if err := r.scanRow(row, result); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // DeleteAll deletes multiple entities
 func (r *BaseRepository[T, ID]) DeleteAll(ctx context.Context, entities []*T) error {
 	for _, entity := range entities {
@@ -337,20 +635,15 @@ func (r *BaseRepository[T, ID]) DeleteAllByIDs(ctx context.Context, ids []ID) er
 		args[i] = id
 	}
 
-	query := fmt.Sprintf(
-		"DELETE FROM %s WHERE %s IN (%s)",
-		r.tableName,
-		r.pkField,
-		strings.Join(placeholders, ", "),
-	)
+	query := r.deleteStatement(fmt.Sprintf("%s IN (%s)", r.pkField, strings.Join(placeholders, ", ")))
 	r.logQuery(query, args)
 
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		_, err = tx.Exec(ctx, query, args...)
 	} else {
-		_, err = r.db.pool.Exec(ctx, query, args...)
+		_, err = r.poolConn(ctx).Exec(ctx, query, args...)
 	}
 
 	return err
@@ -358,16 +651,16 @@ func (r *BaseRepository[T, ID]) DeleteAllByIDs(ctx context.Context, ids []ID) er
 
 // Count counts all entities
 func (r *BaseRepository[T, ID]) Count(ctx context.Context) (int64, error) {
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", r.tableName)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", r.tableName, r.whereClause(""))
 	r.logQuery(query, nil)
 	
 	var count int64
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		err = tx.QueryRow(ctx, query).Scan(&count)
 	} else {
-		err = r.db.pool.QueryRow(ctx, query).Scan(&count)
+		err = r.poolConn(ctx).QueryRow(ctx, query).Scan(&count)
 	}
 	
 	if err != nil {
@@ -379,16 +672,16 @@ func (r *BaseRepository[T, ID]) Count(ctx context.Context) (int64, error) {
 
 // ExistsById checks if an entity exists by ID
 func (r *BaseRepository[T, ID]) ExistsById(ctx context.Context, id ID) (bool, error) {
-	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE %s = $1)", r.tableName, r.pkField)
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE %s)", r.tableName, r.scoped(r.pkField+" = $1"))
 	r.logQuery(query, []interface{}{id})
 	
 	var exists bool
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		err = tx.QueryRow(ctx, query, id).Scan(&exists)
 	} else {
-		err = r.db.pool.QueryRow(ctx, query, id).Scan(&exists)
+		err = r.poolConn(ctx).QueryRow(ctx, query, id).Scan(&exists)
 	}
 	
 	if err != nil {
@@ -398,39 +691,117 @@ func (r *BaseRepository[T, ID]) ExistsById(ctx context.Context, id ID) (bool, er
 	return exists, nil
 }
 
+// FindMissingIDs returns the ids without a matching row, in input order and
+// without duplicates, using one query. Soft-deleted rows count as missing
+// unless the repository is Unscoped.
+func (r *BaseRepository[T, ID]) FindMissingIDs(ctx context.Context, ids []ID) ([]ID, error) {
+	if len(ids) == 0 {
+		return []ID{}, nil
+	}
+
+	query := fmt.Sprintf(
+		"SELECT v.id FROM unnest($1::%s[]) WITH ORDINALITY AS v(id, ord) WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s) GROUP BY v.id ORDER BY MIN(v.ord)",
+		r.pkArrayElemType(), r.tableName, r.scoped(r.pkField+" = v.id"),
+	)
+	r.logQuery(query, []interface{}{ids})
+
+	var rows pgx.Rows
+	var err error
+	if r.tx != nil {
+		rows, err = r.txConn(ctx).Query(ctx, query, ids)
+	} else {
+		rows, err = r.poolConn(ctx).Query(ctx, query, ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	missing := []ID{}
+	for rows.Next() {
+		var id ID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		missing = append(missing, id)
+	}
+	return missing, rows.Err()
+}
+
+// ExistsAllByIDs reports whether every id has a matching row, using one
+// query. It returns true for no ids.
+func (r *BaseRepository[T, ID]) ExistsAllByIDs(ctx context.Context, ids []ID) (bool, error) {
+	if len(ids) == 0 {
+		return true, nil
+	}
+
+	query := fmt.Sprintf(
+		"SELECT NOT EXISTS (SELECT 1 FROM unnest($1::%s[]) AS v(id) WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s))",
+		r.pkArrayElemType(), r.tableName, r.scoped(r.pkField+" = v.id"),
+	)
+	r.logQuery(query, []interface{}{ids})
+
+	var exists bool
+	var err error
+	if r.tx != nil {
+		err = r.txConn(ctx).QueryRow(ctx, query, ids).Scan(&exists)
+	} else {
+		err = r.poolConn(ctx).QueryRow(ctx, query, ids).Scan(&exists)
+	}
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// pkArrayElemType returns the type the primary key is cast to in id arrays,
+// as the schema generator declares the column. Serial pseudo-types become
+// their integer types, which cannot be cast to.
+func (r *BaseRepository[T, ID]) pkArrayElemType() string {
+	pk := r.entity.PrimaryKey
+	jetTag := ""
+	if field, ok := r.entity.Type.FieldByName(pk.Name); ok {
+		jetTag = field.Tag.Get("jet")
+	}
+	columnType := strings.ToLower(schema.ColumnType(pk.Type, jetTag))
+	switch columnType {
+	case "serial":
+		return "integer"
+	case "bigserial":
+		return "bigint"
+	case "smallserial":
+		return "smallint"
+	}
+	return columnType
+}
+
 // FindAllPaged finds entities with pagination
 func (r *BaseRepository[T, ID]) FindAllPaged(ctx context.Context, pageable Pageable) (*Page[T], error) {
 	// Build query with pagination
-	query := fmt.Sprintf("SELECT * FROM %s", r.tableName)
+	query := fmt.Sprintf("SELECT %s FROM %s%s", r.selectList(), r.tableName, r.whereClause(""))
 	
 	// Add sorting
-	if len(pageable.Sort.Orders) > 0 {
-		orderClauses := make([]string, len(pageable.Sort.Orders))
-		for i, order := range pageable.Sort.Orders {
-			direction := "ASC"
-			if order.Direction == Desc {
-				direction = "DESC"
-			}
-			orderClauses[i] = fmt.Sprintf("%s %s", order.Field, direction)
-		}
-		query += " ORDER BY " + strings.Join(orderClauses, ", ")
+	orderBy, err := r.orderByClause(pageable)
+	if err != nil {
+		return nil, err
 	}
+	query += orderBy
 	
 	// Add pagination
 	if pageable.Size > 0 {
-		query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageable.Size, pageable.Page*pageable.Size)
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageable.pageLimit(), pageable.Page*pageable.Size)
 	}
+	query += r.lockClause()
 	
 	r.logQuery(query, nil)
 	
 	// Execute query
 	var rows pgx.Rows
-	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		rows, err = tx.Query(ctx, query)
 	} else {
-		rows, err = r.db.pool.Query(ctx, query)
+		rows, err = r.poolConn(ctx).Query(ctx, query)
 	}
 	
 	if err != nil {
@@ -443,55 +814,128 @@ func (r *BaseRepository[T, ID]) FindAllPaged(ctx context.Context, pageable Pagea
 		return nil, err
 	}
 	
-	// Get total count
-	totalElements, err := r.Count(ctx)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Calculate page info
-	totalPages := 0
-	if pageable.Size > 0 {
-		totalPages = int((totalElements + int64(pageable.Size) - 1) / int64(pageable.Size))
-	}
-	
-	numberOfElements := len(content)
-	
-	return &Page[T]{
-		Content:          content,
-		Pageable:         pageable,
-		TotalElements:    totalElements,
-		TotalPages:       totalPages,
-		Size:             pageable.Size,
-		Number:           pageable.Page,
-		NumberOfElements: numberOfElements,
-		First:            pageable.Page == 0,
-		Last:             pageable.Page >= totalPages-1 || totalPages == 0,
-		Empty:            numberOfElements == 0,
-		Sort:             pageable.Sort,
-	}, nil
+	return r.newPage(ctx, pageable, nil, content)
 }
 
 // SaveBatch saves entities in batches
 func (r *BaseRepository[T, ID]) SaveBatch(ctx context.Context, entities []*T, batchSize int) error {
+	_, err := r.SaveBatchWithResult(ctx, entities, batchSize)
+	return err
+}
+
+// SaveBatchWithResult saves entities in batches and reports what was written.
+// On failure the result covers the batches saved before the failing one.
+func (r *BaseRepository[T, ID]) SaveBatchWithResult(ctx context.Context, entities []*T, batchSize int) (*BatchResult[T, ID], error) {
 	if batchSize <= 0 {
 		batchSize = 100 // Default batch size
 	}
 
+	result := &BatchResult[T, ID]{}
 	for i := 0; i < len(entities); i += batchSize {
 		end := i + batchSize
 		if end > len(entities) {
 			end = len(entities)
 		}
 
-		batch := entities[i:end]
-		_, err := r.SaveAll(ctx, batch)
+		batch, err := r.SaveAllWithResult(ctx, entities[i:end])
 		if err != nil {
-			return fmt.Errorf("batch save failed at offset %d: %w", i, err)
+			return result, fmt.Errorf("batch save failed at offset %d: %w", i, err)
 		}
+		result.merge(batch)
 	}
 
-	return nil
+	return result, nil
+}
+
+// BulkInsert inserts entities using the PostgreSQL COPY protocol.
+// Auto-increment primary keys and auto-now fields are left to the database,
+// and inserted rows are not read back. Rows leaving different columns to
+// their defaults are copied separately, in one transaction. Returns the
+// number of rows copied.
+func (r *BaseRepository[T, ID]) BulkInsert(ctx context.Context, entities []*T) (int64, error) {
+	if len(entities) == 0 {
+		return 0, nil
+	}
+
+	for i, entity := range entities {
+		if err := r.validateEnums(entity); err != nil {
+			return 0, fmt.Errorf("bulk insert failed at index %d: %w", i, err)
+		}
+	}
+	columns, rows, defaults := r.insertRows(entities)
+	groups := copyGroups(columns, rows, defaults)
+	table := pgx.Identifier(strings.Split(r.tableName, "."))
+
+	copyAll := func(q querier) (int64, error) {
+		var count int64
+		for _, group := range groups {
+			if r.db != nil && r.db.config.LogSQL {
+				r.db.logger.Debug("executing copy", "table", r.tableName, "columns", group.columns, "rows", len(group.rows))
+			}
+			n, err := q.CopyFrom(ctx, table, group.columns, pgx.CopyFromRows(group.rows))
+			if err != nil {
+				return count, err
+			}
+			count += n
+		}
+		return count, nil
+	}
+
+	// Several copies run in one transaction so the batch stays atomic
+	var count int64
+	var err error
+	switch {
+	case r.tx != nil:
+		count, err = copyAll(r.txConn(ctx))
+	case len(groups) == 1 || dryRunCapture(ctx) != nil:
+		count, err = copyAll(r.poolConn(ctx))
+	default:
+		err = r.db.Transaction(ctx, func(tx *Tx) error {
+			var copyErr error
+			count, copyErr = copyAll(r.guard(tx.tx))
+			return copyErr
+		})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bulk insert failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// copyGroup is a COPY of the rows that write the same columns
+type copyGroup struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// copyGroups splits rows by the columns they leave to the column default,
+// as COPY cannot ask for a default, keeping the groups in first-row order
+func copyGroups(columns []string, rows [][]interface{}, defaults [][]bool) []*copyGroup {
+	var groups []*copyGroup
+	index := make(map[string]*copyGroup)
+	for i, row := range rows {
+		key := fmt.Sprint(defaults[i])
+		group, ok := index[key]
+		if !ok {
+			group = &copyGroup{}
+			for j, column := range columns {
+				if !defaults[i][j] {
+					group.columns = append(group.columns, column)
+				}
+			}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		values := make([]interface{}, 0, len(group.columns))
+		for j, value := range row {
+			if !defaults[i][j] {
+				values = append(values, value)
+			}
+		}
+		group.rows = append(group.rows, values)
+	}
+	return groups
 }
 
 // FindOne finds a single entity matching the specification
@@ -505,14 +949,14 @@ func (r *BaseRepository[T, ID]) FindOne(ctx context.Context, spec Specification[
 		return nil, ErrNotFound
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", r.tableName, whereClause)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s LIMIT 1%s", r.selectList(), r.tableName, r.scoped(whereClause), r.lockClause())
 	r.logQuery(query, args)
 
 	var row pgx.Row
 	if r.tx != nil {
-		row = r.tx.tx.QueryRow(ctx, query, args...)
+		row = r.txConn(ctx).QueryRow(ctx, query, args...)
 	} else {
-		row = r.db.pool.QueryRow(ctx, query, args...)
+		row = r.poolConn(ctx).QueryRow(ctx, query, args...)
 	}
 
 	result := new(T)
@@ -528,76 +972,67 @@ func (r *BaseRepository[T, ID]) FindOne(ctx context.Context, spec Specification[
 
 // FindAllWithSpec finds all entities matching the specification
 func (r *BaseRepository[T, ID]) FindAllWithSpec(ctx context.Context, spec Specification[T]) ([]*T, error) {
-	query := fmt.Sprintf("SELECT * FROM %s", r.tableName)
+	rows, err := r.queryWithSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanRows(rows)
+}
+
+// queryWithSpec runs a SELECT for the specification and returns the open rows
+func (r *BaseRepository[T, ID]) queryWithSpec(ctx context.Context, spec Specification[T]) (pgx.Rows, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s", r.selectList(), r.tableName)
 	var args []interface{}
 
+	var whereClause string
 	if spec != nil {
-		whereClause, specArgs := spec.ToSQL()
-		if whereClause != "" {
-			query += " WHERE " + whereClause
-			args = specArgs
-		}
+		whereClause, args = spec.ToSQL()
 	}
+	query += r.whereClause(whereClause) + r.lockClause()
 
 	r.logQuery(query, args)
 
-	var rows pgx.Rows
-	var err error
 	if r.tx != nil {
-		rows, err = r.tx.tx.Query(ctx, query, args...)
-	} else {
-		rows, err = r.db.pool.Query(ctx, query, args...)
+		return r.txConn(ctx).Query(ctx, query, args...)
 	}
-
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	return r.scanRows(rows)
+	return r.poolConn(ctx).Query(ctx, query, args...)
 }
 
 // FindAllPagedWithSpec finds entities with pagination matching the specification
 func (r *BaseRepository[T, ID]) FindAllPagedWithSpec(ctx context.Context, spec Specification[T], pageable Pageable) (*Page[T], error) {
-	query := fmt.Sprintf("SELECT * FROM %s", r.tableName)
+	query := fmt.Sprintf("SELECT %s FROM %s", r.selectList(), r.tableName)
 	var args []interface{}
 
 	// Add WHERE clause if specification provided
+	var whereClause string
 	if spec != nil {
-		whereClause, specArgs := spec.ToSQL()
-		if whereClause != "" {
-			query += " WHERE " + whereClause
-			args = specArgs
-		}
+		whereClause, args = spec.ToSQL()
 	}
+	query += r.whereClause(whereClause)
 
 	// Add sorting
-	if len(pageable.Sort.Orders) > 0 {
-		orderClauses := make([]string, len(pageable.Sort.Orders))
-		for i, order := range pageable.Sort.Orders {
-			direction := "ASC"
-			if order.Direction == Desc {
-				direction = "DESC"
-			}
-			orderClauses[i] = fmt.Sprintf("%s %s", order.Field, direction)
-		}
-		query += " ORDER BY " + strings.Join(orderClauses, ", ")
+	orderBy, err := r.orderByClause(pageable)
+	if err != nil {
+		return nil, err
 	}
+	query += orderBy
 
 	// Add pagination
 	if pageable.Size > 0 {
-		query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageable.Size, pageable.Page*pageable.Size)
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageable.pageLimit(), pageable.Page*pageable.Size)
 	}
+	query += r.lockClause()
 
 	r.logQuery(query, args)
 
 	// Execute query
 	var rows pgx.Rows
-	var err error
 	if r.tx != nil {
-		rows, err = r.tx.tx.Query(ctx, query, args...)
+		rows, err = r.txConn(ctx).Query(ctx, query, args...)
 	} else {
-		rows, err = r.db.pool.Query(ctx, query, args...)
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -610,33 +1045,7 @@ func (r *BaseRepository[T, ID]) FindAllPagedWithSpec(ctx context.Context, spec S
 		return nil, err
 	}
 
-	// Get total count with specification
-	totalElements, err := r.CountWithSpec(ctx, spec)
-	if err != nil {
-		return nil, err
-	}
-
-	// Calculate page info
-	totalPages := 0
-	if pageable.Size > 0 {
-		totalPages = int((totalElements + int64(pageable.Size) - 1) / int64(pageable.Size))
-	}
-
-	numberOfElements := len(content)
-
-	return &Page[T]{
-		Content:          content,
-		Pageable:         pageable,
-		TotalElements:    totalElements,
-		TotalPages:       totalPages,
-		Size:             pageable.Size,
-		Number:           pageable.Page,
-		NumberOfElements: numberOfElements,
-		First:            pageable.Page == 0,
-		Last:             pageable.Page >= totalPages-1 || totalPages == 0,
-		Empty:            numberOfElements == 0,
-		Sort:             pageable.Sort,
-	}, nil
+	return r.newPage(ctx, pageable, spec, content)
 }
 
 // CountWithSpec counts entities matching the specification
@@ -644,22 +1053,20 @@ func (r *BaseRepository[T, ID]) CountWithSpec(ctx context.Context, spec Specific
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", r.tableName)
 	var args []interface{}
 
+	var whereClause string
 	if spec != nil {
-		whereClause, specArgs := spec.ToSQL()
-		if whereClause != "" {
-			query += " WHERE " + whereClause
-			args = specArgs
-		}
+		whereClause, args = spec.ToSQL()
 	}
+	query += r.whereClause(whereClause)
 
 	r.logQuery(query, args)
 
 	var count int64
 	var err error
 	if r.tx != nil {
-		err = r.tx.tx.QueryRow(ctx, query, args...).Scan(&count)
+		err = r.txConn(ctx).QueryRow(ctx, query, args...).Scan(&count)
 	} else {
-		err = r.db.pool.QueryRow(ctx, query, args...).Scan(&count)
+		err = r.poolConn(ctx).QueryRow(ctx, query, args...).Scan(&count)
 	}
 
 	if err != nil {
@@ -674,13 +1081,11 @@ func (r *BaseRepository[T, ID]) ExistsWithSpec(ctx context.Context, spec Specifi
 	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s", r.tableName)
 	var args []interface{}
 
+	var whereClause string
 	if spec != nil {
-		whereClause, specArgs := spec.ToSQL()
-		if whereClause != "" {
-			query += " WHERE " + whereClause
-			args = specArgs
-		}
+		whereClause, args = spec.ToSQL()
 	}
+	query += r.whereClause(whereClause)
 	query += ")"
 
 	r.logQuery(query, args)
@@ -688,9 +1093,9 @@ func (r *BaseRepository[T, ID]) ExistsWithSpec(ctx context.Context, spec Specifi
 	var exists bool
 	var err error
 	if r.tx != nil {
-		err = r.tx.tx.QueryRow(ctx, query, args...).Scan(&exists)
+		err = r.txConn(ctx).QueryRow(ctx, query, args...).Scan(&exists)
 	} else {
-		err = r.db.pool.QueryRow(ctx, query, args...).Scan(&exists)
+		err = r.poolConn(ctx).QueryRow(ctx, query, args...).Scan(&exists)
 	}
 
 	if err != nil {
@@ -711,15 +1116,53 @@ func (r *BaseRepository[T, ID]) DeleteWithSpec(ctx context.Context, spec Specifi
 		return 0, fmt.Errorf("specification must have a WHERE clause for delete")
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s", r.tableName, whereClause)
+	query := r.deleteStatement(whereClause)
 	r.logQuery(query, args)
 
 	var result pgconn.CommandTag
 	var err error
 	if r.tx != nil {
-		result, err = r.tx.tx.Exec(ctx, query, args...)
+		result, err = r.txConn(ctx).Exec(ctx, query, args...)
 	} else {
-		result, err = r.db.pool.Exec(ctx, query, args...)
+		result, err = r.poolConn(ctx).Exec(ctx, query, args...)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// UpdateWithSpec sets the given columns of the entities matching the
+// specification and returns rows affected. Keys may be column names or struct
+// field names; auto_now columns are set to NOW() unless provided. Soft-deleted
+// rows are left alone unless the repository is Unscoped.
+func (r *BaseRepository[T, ID]) UpdateWithSpec(ctx context.Context, fields map[string]interface{}, spec Specification[T]) (int64, error) {
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("%w: no fields to update", ErrInvalidInput)
+	}
+	if spec == nil {
+		return 0, fmt.Errorf("specification cannot be nil for update")
+	}
+
+	sets, args, err := r.setClause(fields)
+	if err != nil {
+		return 0, err
+	}
+	whereClause := buildSpec(spec, &args)
+	if whereClause == "" {
+		return 0, fmt.Errorf("specification must have a WHERE clause for update")
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", r.tableName, strings.Join(sets, ", "), r.scoped(whereClause))
+	r.logQuery(query, args)
+
+	var result pgconn.CommandTag
+	if r.tx != nil {
+		result, err = r.txConn(ctx).Exec(ctx, query, args...)
+	} else {
+		result, err = r.poolConn(ctx).Exec(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -731,26 +1174,73 @@ func (r *BaseRepository[T, ID]) DeleteWithSpec(ctx context.Context, spec Specifi
 
 // WithTx returns a repository bound to a transaction
 func (r *BaseRepository[T, ID]) WithTx(tx *Tx) Repository[T, ID] {
-	return &BaseRepository[T, ID]{
-		db:        r.db,
-		tx:        tx,
-		entity:    r.entity,
-		tableName: r.tableName,
-		pkField:   r.pkField,
+	repo := *r
+	repo.tx = tx
+	return &repo
+}
+
+// Unscoped returns a repository that includes soft-deleted rows in queries
+// and permanently deletes rows instead of marking them deleted
+func (r *BaseRepository[T, ID]) Unscoped() *BaseRepository[T, ID] {
+	repo := *r
+	repo.unscoped = true
+	return &repo
+}
+
+// Restore clears the soft delete marker of the entity with the given ID.
+// Returns ErrNotFound if no soft-deleted row matches.
+func (r *BaseRepository[T, ID]) Restore(ctx context.Context, id ID) error {
+	if r.softDelete == nil {
+		return ErrSoftDeleteUnsupported
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = NULL WHERE %s AND %s IS NOT NULL",
+		r.tableName,
+		r.softDelete.DBName,
+		r.discriminated(r.pkField+" = $1"),
+		r.softDelete.DBName,
+	)
+	r.logQuery(query, []interface{}{id})
+
+	var result pgconn.CommandTag
+	var err error
+	if r.tx != nil {
+		result, err = r.txConn(ctx).Exec(ctx, query, id)
+	} else {
+		result, err = r.poolConn(ctx).Exec(ctx, query, id)
+	}
+
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
 	}
+
+	return nil
 }
 
 // Query executes a raw SQL query and returns results
 func (r *BaseRepository[T, ID]) Query(ctx context.Context, query string, args ...interface{}) ([]*T, error) {
+	if err := r.checkRawSQL(ctx, query); err != nil {
+		return nil, err
+	}
+	return r.queryEntities(ctx, query, args...)
+}
+
+// queryEntities runs a query the repository built itself, which unlike raw
+// SQL passed to Query is not checked against the allowlist
+func (r *BaseRepository[T, ID]) queryEntities(ctx context.Context, query string, args ...interface{}) ([]*T, error) {
 	r.logQuery(query, args)
 
 	var rows pgx.Rows
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		rows, err = tx.Query(ctx, query, args...)
 	} else {
-		rows, err = r.db.pool.Query(ctx, query, args...)
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -763,14 +1253,17 @@ func (r *BaseRepository[T, ID]) Query(ctx context.Context, query string, args ..
 
 // QueryOne executes a raw SQL query and returns a single result
 func (r *BaseRepository[T, ID]) QueryOne(ctx context.Context, query string, args ...interface{}) (*T, error) {
+	if err := r.checkRawSQL(ctx, query); err != nil {
+		return nil, err
+	}
 	r.logQuery(query, args)
 
 	var row pgx.Row
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		row = tx.QueryRow(ctx, query, args...)
 	} else {
-		row = r.db.pool.QueryRow(ctx, query, args...)
+		row = r.poolConn(ctx).QueryRow(ctx, query, args...)
 	}
 
 	result := new(T)
@@ -786,15 +1279,24 @@ func (r *BaseRepository[T, ID]) QueryOne(ctx context.Context, query string, args
 
 // Exec executes a raw SQL statement and returns the number of rows affected
 func (r *BaseRepository[T, ID]) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if err := r.checkRawSQL(ctx, query); err != nil {
+		return 0, err
+	}
+	return r.execStatement(ctx, query, args...)
+}
+
+// execStatement runs a statement the repository built itself, which unlike
+// raw SQL passed to Exec is not checked against the allowlist
+func (r *BaseRepository[T, ID]) execStatement(ctx context.Context, query string, args ...interface{}) (int64, error) {
 	r.logQuery(query, args)
 
 	var result pgconn.CommandTag
 	var err error
 	if r.tx != nil {
-		tx := r.tx.tx
+		tx := r.txConn(ctx)
 		result, err = tx.Exec(ctx, query, args...)
 	} else {
-		result, err = r.db.pool.Exec(ctx, query, args...)
+		result, err = r.poolConn(ctx).Exec(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -806,17 +1308,102 @@ func (r *BaseRepository[T, ID]) Exec(ctx context.Context, query string, args ...
 
 // Helper methods
 
-func (r *BaseRepository[T, ID]) getPKValue(entity *T) interface{} {
-	v := reflect.ValueOf(entity).Elem()
-	for i := 0; i < v.NumField(); i++ {
-		field := r.entity.Type.Field(i)
-		if field.Name == r.entity.PrimaryKey.Name {
-			return v.Field(i).Interface()
+// saveStatement returns the INSERT or UPDATE statement Save would issue for entity
+func (r *BaseRepository[T, ID]) saveStatement(entity *T) (string, []interface{}) {
+	if r.isInsert(entity) {
+		return r.insertStatement(entity)
+	}
+	return r.updateStatement(entity)
+}
+
+// insertStatement builds an INSERT ... RETURNING statement for entity
+func (r *BaseRepository[T, ID]) insertStatement(entity *T) (string, []interface{}) {
+	fields, values, placeholders := r.buildInsertQuery(entity)
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		r.tableName,
+		strings.Join(fields, ", "),
+		strings.Join(placeholders, ", "),
+		r.returningClause(),
+	)
+
+	return query, values
+}
+
+// updateStatement builds an UPDATE ... WHERE pk = $n RETURNING statement for entity
+func (r *BaseRepository[T, ID]) updateStatement(entity *T) (string, []interface{}) {
+	fields, values := r.buildUpdateQuery(entity)
+	values = append(values, r.entity.PrimaryKey.columnValue(reflect.ValueOf(entity).Elem()))
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s RETURNING %s",
+		r.tableName,
+		strings.Join(fields, ", "),
+		r.discriminated(fmt.Sprintf("%s = $%d", r.pkField, len(values))),
+		r.returningClause(),
+	)
+
+	return query, values
+}
+
+// softDeleteField resolves the soft delete column from the soft_delete tag,
+// or from Config.DeletedAtField when Config.SoftDelete is enabled
+func softDeleteField(db *Database, entity *Entity) *Field {
+	if entity.SoftDelete != nil {
+		return entity.SoftDelete
+	}
+	if db == nil || !db.config.SoftDelete {
+		return nil
+	}
+	for i := range entity.Fields {
+		if entity.Fields[i].DBName == db.config.DeletedAtField {
+			return &entity.Fields[i]
 		}
 	}
 	return nil
 }
 
+// isSoftDeleteField reports whether fieldMeta is the soft delete column
+func (r *BaseRepository[T, ID]) isSoftDeleteField(fieldMeta Field) bool {
+	return r.softDelete != nil && fieldMeta.DBName == r.softDelete.DBName
+}
+
+// scoped adds the "not soft-deleted" condition, and the discriminator of a
+// shared table, to a WHERE clause
+func (r *BaseRepository[T, ID]) scoped(where string) string {
+	where = r.discriminated(where)
+	if r.softDelete == nil || r.unscoped {
+		return where
+	}
+	notDeleted := r.softDelete.DBName + " IS NULL"
+	if where == "" {
+		return notDeleted
+	}
+	return fmt.Sprintf("(%s) AND %s", where, notDeleted)
+}
+
+// whereClause returns " WHERE ..." for the scoped condition, or "" if there is none
+func (r *BaseRepository[T, ID]) whereClause(where string) string {
+	if where = r.scoped(where); where == "" {
+		return ""
+	}
+	return " WHERE " + where
+}
+
+// deleteStatement builds a DELETE, or a soft delete UPDATE when enabled
+func (r *BaseRepository[T, ID]) deleteStatement(where string) string {
+	if r.softDelete == nil || r.unscoped {
+		return fmt.Sprintf("DELETE FROM %s WHERE %s", r.tableName, r.discriminated(where))
+	}
+	return fmt.Sprintf("UPDATE %s SET %s = %s%s", r.tableName, r.softDelete.DBName, r.nowSQL(), r.whereClause(where))
+}
+
+func (r *BaseRepository[T, ID]) getPKValue(entity *T) interface{} {
+	v := reflect.ValueOf(entity).Elem()
+	return r.entity.PrimaryKey.valueOf(v).Interface()
+}
+
 func (r *BaseRepository[T, ID]) isZeroValue(v interface{}) bool {
 	return reflect.ValueOf(v).IsZero()
 }
@@ -828,22 +1415,62 @@ func (r *BaseRepository[T, ID]) buildInsertQuery(entity *T) ([]string, []interfa
 	values := make([]interface{}, 0)
 	placeholders := make([]string, 0)
 	
+	ids := r.idGenerator()
 	idx := 1
-	for i := 0; i < v.NumField(); i++ {
+	for i := range r.entity.Fields {
 		fieldMeta := r.entity.Fields[i]
 		
-		// Skip auto-increment primary keys
+		value := fieldMeta.columnValue(v)
+		
+		// Auto-increment primary keys are left to the sequence, unless an
+		// ID generator is configured
 		if fieldMeta.AutoIncrement && fieldMeta.PrimaryKey {
+			if ids == nil {
+				continue
+			}
+			if fieldMeta.valueOf(v).IsZero() {
+				value = r.generatedKey(ids, &r.entity.Fields[i])
+			}
+		}
+		
+		// Unset UUID keys are generated by the client or left to the column default
+		if fieldMeta.UUID != "" && fieldMeta.valueOf(v).IsZero() {
+			switch {
+			case ids != nil:
+				value = r.generatedKey(ids, &r.entity.Fields[i])
+			case fieldMeta.UUID == UUIDDatabase:
+				continue
+			default:
+				value = fieldMeta.newUUID()
+			}
+		}
+		
+		// Unset nullable fields with a default are left to the column default
+		if fieldMeta.Default != "" && isNilPointer(fieldMeta.valueOf(v)) {
 			continue
 		}
 		
-		// Skip auto-now fields (they should be handled by database)
+		// Auto-now fields are set by the database, unless a clock is configured
 		if fieldMeta.AutoNowAdd || fieldMeta.AutoNow {
+			clock := r.clock()
+			if clock == nil {
+				continue
+			}
+			value = clock.Now()
+		}
+		
+		// Soft delete markers are only written by Delete and Restore
+		if r.isSoftDeleteField(fieldMeta) {
 			continue
 		}
 		
+		// Rows of a shared table are tagged with the entity's type
+		if r.isDiscriminatorField(fieldMeta) {
+			value = r.entity.Discriminator.Value
+		}
+		
 		fields = append(fields, fieldMeta.DBName)
-		values = append(values, v.Field(i).Interface())
+		values = append(values, value)
 		placeholders = append(placeholders, fmt.Sprintf("$%d", idx))
 		idx++
 	}
@@ -851,6 +1478,43 @@ func (r *BaseRepository[T, ID]) buildInsertQuery(entity *T) ([]string, []interfa
 	return fields, values, placeholders
 }
 
+// insertRows lays out a multi-row insert: the columns any of the entities
+// writes, in field order, and each entity's values for them. defaults marks
+// the columns an entity leaves to the column default, such as unset uuid:db
+// keys and nil pointers with a default tag; their values are nil.
+func (r *BaseRepository[T, ID]) insertRows(entities []*T) ([]string, [][]interface{}, [][]bool) {
+	written := make([]map[string]interface{}, len(entities))
+	used := make(map[string]bool)
+	for i, entity := range entities {
+		fields, values, _ := r.buildInsertQuery(entity)
+		written[i] = make(map[string]interface{}, len(fields))
+		for j, field := range fields {
+			written[i][field] = values[j]
+			used[field] = true
+		}
+	}
+
+	var columns []string
+	for _, fieldMeta := range r.entity.Fields {
+		if used[fieldMeta.DBName] {
+			columns = append(columns, fieldMeta.DBName)
+			delete(used, fieldMeta.DBName)
+		}
+	}
+
+	rows := make([][]interface{}, len(entities))
+	defaults := make([][]bool, len(entities))
+	for i := range entities {
+		rows[i] = make([]interface{}, len(columns))
+		defaults[i] = make([]bool, len(columns))
+		for j, column := range columns {
+			value, ok := written[i][column]
+			rows[i][j], defaults[i][j] = value, !ok
+		}
+	}
+	return columns, rows, defaults
+}
+
 func (r *BaseRepository[T, ID]) buildUpdateQuery(entity *T) ([]string, []interface{}) {
 	v := reflect.ValueOf(entity).Elem()
 	
@@ -858,7 +1522,7 @@ func (r *BaseRepository[T, ID]) buildUpdateQuery(entity *T) ([]string, []interfa
 	values := make([]interface{}, 0)
 	
 	idx := 1
-	for i := 0; i < v.NumField(); i++ {
+	for i := range r.entity.Fields {
 		fieldMeta := r.entity.Fields[i]
 		
 		// Skip primary key
@@ -871,8 +1535,18 @@ func (r *BaseRepository[T, ID]) buildUpdateQuery(entity *T) ([]string, []interfa
 			continue
 		}
 		
+		// Soft delete markers are only written by Delete and Restore,
+		// and the type of a row never changes
+		if r.isSoftDeleteField(fieldMeta) || r.isDiscriminatorField(fieldMeta) {
+			continue
+		}
+		
+		value := fieldMeta.columnValue(v)
+		if clock := r.clock(); clock != nil && fieldMeta.AutoNow {
+			value = clock.Now()
+		}
 		fields = append(fields, fmt.Sprintf("%s = $%d", fieldMeta.DBName, idx))
-		values = append(values, v.Field(i).Interface())
+		values = append(values, value)
 		idx++
 	}
 	
@@ -882,13 +1556,15 @@ func (r *BaseRepository[T, ID]) buildUpdateQuery(entity *T) ([]string, []interfa
 func (r *BaseRepository[T, ID]) scanRow(row pgx.Row, dest *T) error {
 	v := reflect.ValueOf(dest).Elem()
 	
-	// Create slice of pointers to struct fields
-	fields := make([]interface{}, len(r.entity.Fields))
+	// Collect scan destinations for the struct fields
+	targets := rowTargets{dest: make([]interface{}, 0, len(r.entity.Fields))}
 	for i := range r.entity.Fields {
-		fields[i] = v.Field(i).Addr().Interface()
+		if !r.entity.Fields[i].Ignored {
+			targets.add(&r.entity.Fields[i], v)
+		}
 	}
 	
-	return row.Scan(fields...)
+	return targets.scan(row)
 }
 
 func (r *BaseRepository[T, ID]) scanRows(rows pgx.Rows) ([]*T, error) {
@@ -910,7 +1586,7 @@ func (r *BaseRepository[T, ID]) scanRows(rows pgx.Rows) ([]*T, error) {
 }
 
 func (r *BaseRepository[T, ID]) logQuery(query string, args []interface{}) {
-	if r.db.config.LogSQL {
+	if r.db != nil && r.db.config.LogSQL {
 		r.db.logger.Debug("executing query", "query", query, "args", args)
 	}
 }
@@ -1,8 +1,13 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TestUser is a test entity
@@ -188,6 +193,217 @@ func TestUtils(t *testing.T) {
 	})
 }
 
+func TestBaseRepository_SaveStatement(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should insert entity without primary key", func(t *testing.T) {
+		query, args := repo.saveStatement(&TestUser{Email: "a@example.com", Username: "a", Age: 30})
+
+		expected := "INSERT INTO test_user (email, username, age) VALUES ($1, $2, $3) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 3 {
+			t.Errorf("Expected 3 args, got %d", len(args))
+		}
+	})
+
+	t.Run("should update entity with primary key", func(t *testing.T) {
+		query, args := repo.saveStatement(&TestUser{ID: 7, Email: "a@example.com", Username: "a", Age: 30})
+
+		if !contains(query, "UPDATE test_user SET") || !contains(query, "WHERE id = $5 RETURNING *") {
+			t.Errorf("Unexpected update statement: %s", query)
+		}
+		if len(args) != 5 || args[4] != int64(7) {
+			t.Errorf("Expected primary key as last arg, got %v", args)
+		}
+	})
+}
+
+func TestBaseRepository_UpdateFields(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should only set given columns", func(t *testing.T) {
+		query, args, err := repo.updateFieldsStatement(7, map[string]interface{}{
+			"Age":   31,
+			"email": "new@example.com",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "UPDATE test_user SET email = $1, age = $2, updated_at = NOW() WHERE id = $3 RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 3 || args[2] != int64(7) {
+			t.Errorf("Expected [new@example.com 31 7], got %v", args)
+		}
+	})
+
+	t.Run("should reject unknown and primary key fields", func(t *testing.T) {
+		if _, _, err := repo.updateFieldsStatement(7, map[string]interface{}{"nope": 1}); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+		if _, _, err := repo.updateFieldsStatement(7, map[string]interface{}{"id": 8}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("should detect changed fields", func(t *testing.T) {
+		original := &TestUser{ID: 7, Email: "a@example.com", Username: "a", Age: 30}
+		modified := *original
+		modified.Age = 31
+
+		changes := repo.ChangedFields(original, &modified)
+		if len(changes) != 1 || changes["age"] != 31 {
+			t.Errorf("Expected only age to change, got %v", changes)
+		}
+	})
+}
+
+func TestBaseRepository_UpdateWithSpec(t *testing.T) {
+	repo, err := NewBaseRepository[TestArticle, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+
+	spec := Where[TestArticle]("id = $1 OR title = $2", 7, "old")
+	if _, err := repo.UpdateWithSpec(ctx, map[string]interface{}{"Title": "new"}, spec); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	statements := capture.Statements()
+	expected := "UPDATE test_article SET title = $1 WHERE (id = $2 OR title = $3) AND deleted_at IS NULL"
+	if len(statements) != 1 || statements[0].SQL != expected {
+		t.Fatalf("Expected %q, got %v", expected, statements)
+	}
+	if args := statements[0].Args; len(args) != 3 || args[0] != "new" || args[2] != "old" {
+		t.Errorf("Expected [new 7 old], got %v", args)
+	}
+
+	if _, err := repo.UpdateWithSpec(ctx, nil, spec); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput without fields, got %v", err)
+	}
+	if _, err := repo.UpdateWithSpec(ctx, map[string]interface{}{"title": "new"}, nil); err == nil {
+		t.Error("Expected an error without a specification")
+	}
+}
+
+func TestBaseRepository_FindByIDs(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+
+	if _, err := repo.FindAllByIDsOrdered(ctx, []int64{3, 1, 3, 2}); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+	if _, err := repo.FindMapByIDs(ctx, []int64{5, 5}); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(statements))
+	}
+	if want := "SELECT * FROM test_user WHERE id IN ($1, $2, $3)"; statements[0].SQL != want {
+		t.Errorf("Expected repeated IDs to be queried once, got %q", statements[0].SQL)
+	}
+	if args := statements[0].Args; len(args) != 3 || args[0] != int64(3) || args[1] != int64(1) || args[2] != int64(2) {
+		t.Errorf("Expected [3 1 2], got %v", args)
+	}
+	if len(statements[1].Args) != 1 {
+		t.Errorf("Expected a single argument, got %v", statements[1].Args)
+	}
+
+	if m, err := repo.FindMapByIDs(context.Background(), nil); err != nil || len(m) != 0 {
+		t.Errorf("Expected an empty map without querying, got %v, %v", m, err)
+	}
+}
+
+func TestBatchResult(t *testing.T) {
+	result := &BatchResult[TestUser, int64]{}
+	result.merge(&BatchResult[TestUser, int64]{IDs: []int64{1, 2}, Inserted: 2})
+	result.merge(&BatchResult[TestUser, int64]{IDs: []int64{7}, Updated: 1})
+
+	if result.Total() != 3 {
+		t.Errorf("Expected 3 rows, got %d", result.Total())
+	}
+	if len(result.IDs) != 3 || result.IDs[2] != 7 {
+		t.Errorf("Expected IDs in input order, got %v", result.IDs)
+	}
+}
+
+// TestArticle is a test entity with soft delete
+type TestArticle struct {
+	ID        int64      `db:"id" jet:"primary_key,auto_increment"`
+	Title     string     `db:"title"`
+	DeletedAt *time.Time `db:"deleted_at" jet:"soft_delete"`
+}
+
+func TestBaseRepository_SoftDelete(t *testing.T) {
+	repo, err := NewBaseRepository[TestArticle, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should detect soft delete tag", func(t *testing.T) {
+		if repo.softDelete == nil || repo.softDelete.DBName != "deleted_at" {
+			t.Fatal("Expected deleted_at to be the soft delete field")
+		}
+	})
+
+	t.Run("should filter deleted rows", func(t *testing.T) {
+		if got := repo.whereClause(""); got != " WHERE deleted_at IS NULL" {
+			t.Errorf("Expected ' WHERE deleted_at IS NULL', got '%s'", got)
+		}
+		if got := repo.scoped("title = $1"); got != "(title = $1) AND deleted_at IS NULL" {
+			t.Errorf("Expected scoped condition, got '%s'", got)
+		}
+	})
+
+	t.Run("should mark rows deleted", func(t *testing.T) {
+		expected := "UPDATE test_article SET deleted_at = NOW() WHERE (id = $1) AND deleted_at IS NULL"
+		if got := repo.deleteStatement("id = $1"); got != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, got)
+		}
+	})
+
+	t.Run("should not write soft delete column on insert", func(t *testing.T) {
+		query, _ := repo.insertStatement(&TestArticle{Title: "hello"})
+		expected := "INSERT INTO test_article (title) VALUES ($1) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+	})
+
+	t.Run("unscoped should hard delete", func(t *testing.T) {
+		unscoped := repo.Unscoped()
+		if got := unscoped.whereClause(""); got != "" {
+			t.Errorf("Expected no filter, got '%s'", got)
+		}
+		expected := "DELETE FROM test_article WHERE id = $1"
+		if got := unscoped.deleteStatement("id = $1"); got != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, got)
+		}
+	})
+
+	t.Run("should not filter entities without soft delete", func(t *testing.T) {
+		users, _ := NewBaseRepository[TestUser, int64](nil)
+		if got := users.whereClause(""); got != "" {
+			t.Errorf("Expected no filter, got '%s'", got)
+		}
+	})
+}
+
 // Integration tests would go here
 // They would require a real database connection (using testcontainers)
 // Example structure:
@@ -240,3 +456,337 @@ func TestBaseRepositoryIntegration(t *testing.T) {
 }
 */
 
+
+func TestBaseRepository_SaveMode(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should detect insert from zero primary key by default", func(t *testing.T) {
+		if !repo.isInsert(&TestUser{}) || repo.isInsert(&TestUser{ID: 1}) {
+			t.Errorf("Expected zero ID to insert and non-zero ID to update")
+		}
+	})
+
+	t.Run("should always insert", func(t *testing.T) {
+		query, _ := repo.WithSaveMode(SaveAlwaysInsert).saveStatement(&TestUser{ID: 1})
+		if !contains(query, "INSERT INTO test_user") {
+			t.Errorf("Expected INSERT, got '%s'", query)
+		}
+	})
+
+	t.Run("should always update", func(t *testing.T) {
+		query, args := repo.WithSaveMode(SaveAlwaysUpdate).saveStatement(&TestUser{})
+		if !contains(query, "UPDATE test_user") || args[len(args)-1] != int64(0) {
+			t.Errorf("Expected UPDATE by zero ID, got '%s' %v", query, args)
+		}
+	})
+}
+
+// BaseModel holds fields shared across entities
+type BaseModel struct {
+	ID        int64     `db:"id" jet:"primary_key,auto_increment"`
+	CreatedAt time.Time `db:"created_at" jet:"auto_now_add"`
+}
+
+// Timestamps holds the update timestamp
+type Timestamps struct {
+	UpdatedAt time.Time `db:"updated_at" jet:"auto_now"`
+}
+
+// TestPost embeds shared fields
+type TestPost struct {
+	BaseModel
+	Title string `db:"title"`
+	Timestamps
+	PublishedAt time.Time `db:"published_at"`
+}
+
+func TestEntityMetadata_Embedded(t *testing.T) {
+	repo, err := NewBaseRepository[TestPost, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should flatten embedded structs into columns", func(t *testing.T) {
+		expected := []string{"id", "created_at", "title", "updated_at", "published_at"}
+		if len(repo.entity.Fields) != len(expected) {
+			t.Fatalf("Expected %d fields, got %d", len(expected), len(repo.entity.Fields))
+		}
+		for i, name := range expected {
+			if repo.entity.Fields[i].DBName != name {
+				t.Errorf("Expected field '%s', got '%s'", name, repo.entity.Fields[i].DBName)
+			}
+		}
+		if repo.entity.PrimaryKey == nil || repo.entity.PrimaryKey.Name != "ID" {
+			t.Fatalf("Expected primary key from embedded struct")
+		}
+	})
+
+	t.Run("should read embedded primary key", func(t *testing.T) {
+		post := &TestPost{BaseModel: BaseModel{ID: 9}}
+		if got := repo.getPKValue(post); got != int64(9) {
+			t.Errorf("Expected 9, got %v", got)
+		}
+		if id, err := ExtractID[TestPost, int64](post); err != nil || id != 9 {
+			t.Errorf("Expected 9, got %v (%v)", id, err)
+		}
+		if err := SetID(post, int64(10)); err != nil || post.ID != 10 {
+			t.Errorf("Expected ID 10, got %d (%v)", post.ID, err)
+		}
+	})
+
+	t.Run("should build insert and update statements", func(t *testing.T) {
+		post := &TestPost{BaseModel: BaseModel{ID: 3}, Title: "hello"}
+
+		query, args := repo.insertStatement(post)
+		expected := "INSERT INTO test_post (title, published_at) VALUES ($1, $2) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 2 || args[0] != "hello" {
+			t.Errorf("Expected title argument, got %v", args)
+		}
+
+		query, args = repo.updateStatement(post)
+		expected = "UPDATE test_post SET title = $1, updated_at = $2, published_at = $3 WHERE id = $4 RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if args[len(args)-1] != int64(3) {
+			t.Errorf("Expected ID argument 3, got %v", args[len(args)-1])
+		}
+	})
+
+	t.Run("should not flatten time values", func(t *testing.T) {
+		type Event struct {
+			ID int64 `db:"id" jet:"primary_key"`
+			time.Time
+		}
+		entity, err := EntityMetadata(Event{})
+		if err != nil {
+			t.Fatalf("Failed to extract metadata: %v", err)
+		}
+		if len(entity.Fields) != 2 || entity.Fields[1].Type != reflect.TypeOf(time.Time{}) {
+			t.Errorf("Expected embedded time.Time as a single field, got %d fields", len(entity.Fields))
+		}
+	})
+}
+
+// TestDocument has a client-generated UUID primary key
+type TestDocument struct {
+	ID    string `db:"id" jet:"primary_key,uuid:v7"`
+	Title string `db:"title"`
+}
+
+// TestToken has a database-generated UUID primary key
+type TestToken struct {
+	ID    uuid.UUID `db:"id" jet:"primary_key,uuid:db"`
+	Value string    `db:"value"`
+}
+
+func TestBaseRepository_UUIDPrimaryKey(t *testing.T) {
+	t.Run("should generate client-side UUIDs on insert", func(t *testing.T) {
+		repo, err := NewBaseRepository[TestDocument, string](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+
+		doc := &TestDocument{Title: "hello"}
+		if !repo.isInsert(doc) {
+			t.Fatal("Expected empty UUID to insert")
+		}
+
+		query, args := repo.insertStatement(doc)
+		expected := "INSERT INTO test_document (id, title) VALUES ($1, $2) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		id, err := uuid.Parse(args[0].(string))
+		if err != nil || id.Version() != 7 {
+			t.Errorf("Expected a v7 UUID, got %v (%v)", args[0], err)
+		}
+		if doc.ID != "" {
+			t.Errorf("Expected entity to be unchanged, got ID '%s'", doc.ID)
+		}
+	})
+
+	t.Run("should keep assigned UUIDs", func(t *testing.T) {
+		repo, _ := NewBaseRepository[TestDocument, string](nil)
+		_, args := repo.WithSaveMode(SaveAlwaysInsert).insertStatement(&TestDocument{ID: "a1b2"})
+		if args[0] != "a1b2" {
+			t.Errorf("Expected 'a1b2', got %v", args[0])
+		}
+	})
+
+	t.Run("should leave database UUIDs to the column default", func(t *testing.T) {
+		repo, err := NewBaseRepository[TestToken, uuid.UUID](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+
+		query, _ := repo.insertStatement(&TestToken{Value: "secret"})
+		expected := "INSERT INTO test_token (value) VALUES ($1) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+
+		query, _ = repo.saveStatement(&TestToken{ID: uuid.New(), Value: "secret"})
+		if !contains(query, "UPDATE test_token") {
+			t.Errorf("Expected UPDATE for assigned UUID, got '%s'", query)
+		}
+	})
+
+	t.Run("should reject unsupported UUID fields", func(t *testing.T) {
+		type BadKey struct {
+			ID int64 `db:"id" jet:"primary_key,uuid"`
+		}
+		if _, err := NewBaseRepository[BadKey, int64](nil); !errors.Is(err, ErrUnsupportedUUID) {
+			t.Errorf("Expected ErrUnsupportedUUID, got %v", err)
+		}
+
+		type BadStrategy struct {
+			ID string `db:"id" jet:"primary_key,uuid:v1"`
+		}
+		if _, err := NewBaseRepository[BadStrategy, string](nil); !errors.Is(err, ErrUnsupportedUUID) {
+			t.Errorf("Expected ErrUnsupportedUUID, got %v", err)
+		}
+
+		type BadRef struct {
+			ID  int64      `db:"id" jet:"primary_key,auto_increment"`
+			Ref *uuid.UUID `db:"ref" jet:"uuid"`
+		}
+		if _, err := NewBaseRepository[BadRef, int64](nil); !errors.Is(err, ErrUnsupportedUUID) {
+			t.Errorf("Expected ErrUnsupportedUUID for a non-key field, got %v", err)
+		}
+	})
+
+	t.Run("should generate UUIDs for other uuid fields", func(t *testing.T) {
+		type Ticket struct {
+			ID  int64  `db:"id" jet:"primary_key,auto_increment"`
+			Ref string `db:"ref" jet:"uuid"`
+		}
+		repo, err := NewBaseRepository[Ticket, int64](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		_, args := repo.insertStatement(&Ticket{})
+		if ref, ok := args[0].(string); !ok || uuid.Validate(ref) != nil {
+			t.Errorf("Expected a generated UUID, got %v", args)
+		}
+	})
+}
+
+func TestBaseRepository_SaveAllIsolated(t *testing.T) {
+	repo, err := NewBaseRepository[TestTicket, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should report invalid items and save the rest under a savepoint", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		_, err := repo.SaveAllIsolated(ctx, []*TestTicket{{Status: "pending"}, {Status: "open"}})
+		if !errors.Is(err, ErrDryRun) {
+			t.Fatalf("Expected ErrDryRun, got %v", err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 2 {
+			t.Fatalf("Expected 2 statements, got %v", statements)
+		}
+		if statements[0].SQL != "SAVEPOINT jetorm_save_item" {
+			t.Errorf("Expected a savepoint before the item, got '%s'", statements[0].SQL)
+		}
+		if !contains(statements[1].SQL, "INSERT INTO test_ticket") || statements[1].Args[0] != "open" {
+			t.Errorf("Expected the valid item to be inserted, got '%s' %v", statements[1].SQL, statements[1].Args)
+		}
+	})
+
+	t.Run("should collect failures by input index", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		result, err := repo.SaveAllIsolated(ctx, []*TestTicket{{Status: "pending"}, {Status: "done"}})
+		if err != nil {
+			t.Fatalf("Expected enum failures to be reported per item, got %v", err)
+		}
+		if len(result.Failed) != 2 || result.Failed[0].Index != 0 || result.Failed[1].Index != 1 {
+			t.Fatalf("Expected failures at 0 and 1, got %+v", result.Failed)
+		}
+		if !errors.Is(result.Failed[1].Err, ErrInvalidEnumValue) || result.Total() != 0 {
+			t.Errorf("Expected ErrInvalidEnumValue and nothing saved, got %+v", result)
+		}
+		if len(capture.Statements()) != 0 {
+			t.Errorf("Expected invalid items to skip the database, got %v", capture.Statements())
+		}
+	})
+}
+
+// TestCoupon has columns individual inserts may leave to their defaults
+type TestCoupon struct {
+	ID       uuid.UUID `db:"id" jet:"primary_key,uuid:db"`
+	Code     string    `db:"code"`
+	UsesLeft *int64    `db:"uses_left" jet:"default:10"`
+}
+
+func TestBaseRepository_BulkInsert(t *testing.T) {
+	t.Run("should copy rows against one column list", func(t *testing.T) {
+		repo, err := NewBaseRepository[TestUser, int64](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		count, err := repo.BulkInsert(ctx, []*TestUser{{Email: "a@example.com", Age: 30}, {ID: 9, Email: "b@example.com"}})
+		if err != nil || count != 2 {
+			t.Fatalf("Expected 2 copied rows, got %d (%v)", count, err)
+		}
+		expected := `COPY "test_user" (email, username, age) FROM STDIN ` +
+			"[$1=[a@example.com  30] $2=[b@example.com  0]]\n"
+		if capture.String() != expected {
+			t.Errorf("Expected\n%s\ngot\n%s", expected, capture.String())
+		}
+	})
+
+	t.Run("should copy rows leaving columns to their defaults separately", func(t *testing.T) {
+		repo, err := NewBaseRepository[TestCoupon, uuid.UUID](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		repo.tableName = "billing.test_coupon"
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		id := uuid.MustParse("00000000-0000-4000-8000-000000000007")
+		uses := int64(3)
+		count, err := repo.BulkInsert(ctx, []*TestCoupon{
+			{Code: "A"},
+			{ID: id, Code: "B", UsesLeft: &uses},
+			{Code: "C", UsesLeft: &uses},
+			{Code: "D"},
+		})
+		if err != nil || count != 4 {
+			t.Fatalf("Expected 4 copied rows, got %d (%v)", count, err)
+		}
+		statements := capture.Statements()
+		expected := []string{
+			`COPY "billing"."test_coupon" (code) FROM STDIN`,
+			`COPY "billing"."test_coupon" (id, code, uses_left) FROM STDIN`,
+			`COPY "billing"."test_coupon" (code, uses_left) FROM STDIN`,
+		}
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d copies, got:\n%s", len(expected), capture.String())
+		}
+		for i, statement := range statements {
+			if statement.SQL != expected[i] {
+				t.Errorf("Expected '%s', got '%s'", expected[i], statement.SQL)
+			}
+		}
+		if rows := statements[0].Args; len(rows) != 2 || rows[1].([]interface{})[0] != "D" {
+			t.Errorf("Expected rows A and D in the first copy, got %v", rows)
+		}
+		if row := statements[1].Args[0].([]interface{}); row[0] != id || row[2] != &uses {
+			t.Errorf("Expected the assigned key and uses, got %v", row)
+		}
+	})
+}
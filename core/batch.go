@@ -22,6 +22,38 @@ func DefaultBatchConfig() BatchConfig {
 	}
 }
 
+// BatchResult summarizes a batch save
+type BatchResult[T any, ID comparable] struct {
+	Entities []*T // Saved entities in input order
+	IDs      []ID // Primary keys in input order, including generated ones
+	Inserted int  // Number of rows inserted
+	Updated  int  // Number of rows updated
+
+	// Failed lists the items rolled back by SaveAllIsolated; the saved
+	// entities and IDs skip them
+	Failed []BatchFailure
+}
+
+// BatchFailure is an item that could not be saved
+type BatchFailure struct {
+	Index int   // Position in the input slice
+	Err   error // Why the item was rolled back
+}
+
+// Total returns the number of rows written
+func (br *BatchResult[T, ID]) Total() int {
+	return br.Inserted + br.Updated
+}
+
+// merge appends the results of another batch
+func (br *BatchResult[T, ID]) merge(other *BatchResult[T, ID]) {
+	br.Entities = append(br.Entities, other.Entities...)
+	br.IDs = append(br.IDs, other.IDs...)
+	br.Inserted += other.Inserted
+	br.Updated += other.Updated
+	br.Failed = append(br.Failed, other.Failed...)
+}
+
 // BatchWriter provides optimized batch writing
 type BatchWriter[T any, ID comparable] struct {
 	repo   Repository[T, ID]
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -47,6 +48,21 @@ func (ckg *CacheKeyGenerator[T, ID]) KeyForQuery(query string, args ...interface
 	return key
 }
 
+// KeyForSpec generates a cache key for a specification from its syntax tree,
+// so specifications with the same conditions share a key however they were
+// combined, e.g. And(a, And(b, c)) and And(a, b, c)
+func (ckg *CacheKeyGenerator[T, ID]) KeyForSpec(spec Specification[T]) (string, error) {
+	node, err := encodeSpecNode(SpecAST(spec))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:spec:%s", ckg.entityType, data), nil
+}
+
 // CachedRepository wraps a repository with caching
 type CachedRepository[T any, ID comparable] struct {
 	repo  Repository[T, ID]
@@ -125,7 +141,8 @@ func (cr *CachedRepository[T, ID]) Delete(ctx context.Context, entity *T) error
 
 // InMemoryCache is a simple in-memory cache implementation
 type InMemoryCache struct {
-	data map[string]cacheEntry
+	data  map[string]cacheEntry
+	clock Clock
 }
 
 type cacheEntry struct {
@@ -136,10 +153,16 @@ type cacheEntry struct {
 // NewInMemoryCache creates a new in-memory cache
 func NewInMemoryCache() *InMemoryCache {
 	return &InMemoryCache{
-		data: make(map[string]cacheEntry),
+		data:  make(map[string]cacheEntry),
+		clock: SystemClock,
 	}
 }
 
+// SetClock sets the clock entries expire by, e.g. a FrozenClock in tests
+func (c *InMemoryCache) SetClock(clock Clock) {
+	c.clock = clock
+}
+
 // Get retrieves a value from cache
 func (c *InMemoryCache) Get(ctx context.Context, key string) (interface{}, bool) {
 	entry, ok := c.data[key]
@@ -148,7 +171,7 @@ func (c *InMemoryCache) Get(ctx context.Context, key string) (interface{}, bool)
 	}
 	
 	// Check expiration
-	if time.Now().After(entry.expiresAt) {
+	if c.clock.Now().After(entry.expiresAt) {
 		delete(c.data, key)
 		return nil, false
 	}
@@ -160,7 +183,7 @@ func (c *InMemoryCache) Get(ctx context.Context, key string) (interface{}, bool)
 func (c *InMemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	c.data[key] = cacheEntry{
 		value:     value,
-		expiresAt: time.Now().Add(ttl),
+		expiresAt: c.clock.Now().Add(ttl),
 	}
 	return nil
 }
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time. Config.Clock sets the clock repositories use for
+// auto_now and auto_now_add columns, soft deletes and archival; caches take
+// one for TTLs. Tests freeze time with NewFrozenClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FrozenClock is a Clock that only moves when told to
+type FrozenClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFrozenClock creates a clock stopped at t
+func NewFrozenClock(t time.Time) *FrozenClock {
+	return &FrozenClock{now: t}
+}
+
+// Now returns the frozen time
+func (c *FrozenClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t
+func (c *FrozenClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d
+func (c *FrozenClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// WithClock makes repositories take auto timestamps from clock instead of
+// the database server's NOW()
+func WithClock(clock Clock) ConfigOption {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
+// Clock returns the configured clock, or SystemClock
+func (db *Database) Clock() Clock {
+	if db == nil || db.config.Clock == nil {
+		return SystemClock
+	}
+	return db.config.Clock
+}
+
+// clock returns the repository's configured clock, or nil when timestamps
+// are left to the database server
+func (r *BaseRepository[T, ID]) clock() Clock {
+	if r.testClock != nil {
+		return r.testClock
+	}
+	if r.db == nil {
+		return nil
+	}
+	return r.db.config.Clock
+}
+
+// nowSQL is the SQL for the current time: NOW() on the server, or the
+// configured clock's time as a literal
+func (r *BaseRepository[T, ID]) nowSQL() string {
+	clock := r.clock()
+	if clock == nil {
+		return "NOW()"
+	}
+	return "'" + clock.Now().UTC().Format(time.RFC3339Nano) + "'::timestamptz"
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	frozenAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFrozenClock(frozenAt)
+	db := &Database{config: Config{Clock: clock}}
+
+	users, err := NewBaseRepository[TestUser, int64](db)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should bind auto timestamps from the clock", func(t *testing.T) {
+		query, args := users.insertStatement(&TestUser{Email: "a@example.com", Username: "a"})
+		expected := "INSERT INTO test_user (email, username, age, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if args[3] != frozenAt || args[4] != frozenAt {
+			t.Errorf("Expected frozen timestamps, got %v", args)
+		}
+
+		clock.Advance(time.Hour)
+		query, args = users.updateStatement(&TestUser{ID: 1, CreatedAt: frozenAt})
+		if contains(query, "created_at") || args[3] != frozenAt.Add(time.Hour) {
+			t.Errorf("Expected only updated_at advanced, got '%s' %v", query, args)
+		}
+		clock.Set(frozenAt)
+	})
+
+	t.Run("should use the clock for server-side timestamps", func(t *testing.T) {
+		query, _, err := users.updateFieldsStatement(1, map[string]interface{}{"age": 30})
+		if err != nil {
+			t.Fatalf("Failed to build: %v", err)
+		}
+		if !contains(query, "updated_at = '2024-03-01T12:00:00Z'::timestamptz") {
+			t.Errorf("Expected a clock literal for updated_at, got '%s'", query)
+		}
+
+		articles, _ := NewBaseRepository[TestArticle, int64](db)
+		if got := articles.deleteStatement("id = $1"); got != "UPDATE test_article SET deleted_at = '2024-03-01T12:00:00Z'::timestamptz WHERE (id = $1) AND deleted_at IS NULL" {
+			t.Errorf("Unexpected soft delete '%s'", got)
+		}
+	})
+
+	t.Run("should keep creation time on upsert conflicts", func(t *testing.T) {
+		query, _ := users.upsertStatement([]*TestUser{{Email: "a@example.com", Username: "a"}}, OnConflict("email").DoUpdate())
+		expected := "ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, age = EXCLUDED.age, updated_at = '2024-03-01T12:00:00Z'::timestamptz RETURNING *"
+		if !contains(query, expected) {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+	})
+
+	t.Run("should leave timestamps to the server without a clock", func(t *testing.T) {
+		plain, _ := NewBaseRepository[TestUser, int64](nil)
+		query, _ := plain.insertStatement(&TestUser{Email: "a@example.com", Username: "a"})
+		if contains(query, "created_at") {
+			t.Errorf("Expected auto timestamps to be skipped, got '%s'", query)
+		}
+		if (*Database)(nil).Clock() != SystemClock {
+			t.Error("Expected SystemClock by default")
+		}
+	})
+
+	t.Run("should expire cache entries by the clock", func(t *testing.T) {
+		ctx := context.Background()
+		cache := NewInMemoryCache()
+		cache.SetClock(clock)
+		cache.Set(ctx, "k", "v", time.Minute)
+
+		if _, ok := cache.Get(ctx, "k"); !ok {
+			t.Fatal("Expected a fresh entry")
+		}
+		clock.Advance(2 * time.Minute)
+		if _, ok := cache.Get(ctx, "k"); ok {
+			t.Error("Expected the entry to expire")
+		}
+
+		queries := NewQueryCache(time.Minute, 10)
+		queries.SetClock(clock)
+		queries.Set("q", 1)
+		clock.Advance(2 * time.Minute)
+		if _, ok := queries.Get("q"); ok {
+			t.Error("Expected the query entry to expire")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package core
+
+// Column is a typed reference to a column of entity T holding values of type
+// V. jetorm-gen emits one per entity field, e.g. UserFields.Email, so that
+// specifications built from columns fail to compile when a field is renamed
+// or compared with a value of the wrong type.
+type Column[T any, V any] struct {
+	name string
+}
+
+// NewColumn returns a reference to the named column of T
+func NewColumn[T any, V any](name string) Column[T, V] {
+	return Column[T, V]{name: name}
+}
+
+// Name returns the column name
+func (c Column[T, V]) Name() string {
+	return c.name
+}
+
+// String returns the column name
+func (c Column[T, V]) String() string {
+	return c.name
+}
+
+// Eq creates a specification for column = value
+func (c Column[T, V]) Eq(value V) Specification[T] {
+	return Equal[T](c.name, value)
+}
+
+// NotEq creates a specification for column != value
+func (c Column[T, V]) NotEq(value V) Specification[T] {
+	return NotEqual[T](c.name, value)
+}
+
+// Gt creates a specification for column > value
+func (c Column[T, V]) Gt(value V) Specification[T] {
+	return GreaterThan[T](c.name, value)
+}
+
+// Gte creates a specification for column >= value
+func (c Column[T, V]) Gte(value V) Specification[T] {
+	return GreaterThanEqual[T](c.name, value)
+}
+
+// Lt creates a specification for column < value
+func (c Column[T, V]) Lt(value V) Specification[T] {
+	return LessThan[T](c.name, value)
+}
+
+// Lte creates a specification for column <= value
+func (c Column[T, V]) Lte(value V) Specification[T] {
+	return LessThanEqual[T](c.name, value)
+}
+
+// Between creates a specification for column BETWEEN min AND max
+func (c Column[T, V]) Between(min, max V) Specification[T] {
+	return Between[T](c.name, min, max)
+}
+
+// In creates a specification for column IN (values...)
+func (c Column[T, V]) In(values ...V) Specification[T] {
+	return In[T](c.name, toInterfaces(values)...)
+}
+
+// NotIn creates a specification for column NOT IN (values...)
+func (c Column[T, V]) NotIn(values ...V) Specification[T] {
+	return NotIn[T](c.name, toInterfaces(values)...)
+}
+
+// IsNull creates a specification for column IS NULL
+func (c Column[T, V]) IsNull() Specification[T] {
+	return IsNull[T](c.name)
+}
+
+// IsNotNull creates a specification for column IS NOT NULL
+func (c Column[T, V]) IsNotNull() Specification[T] {
+	return IsNotNull[T](c.name)
+}
+
+// Like creates a specification for column LIKE pattern
+func (c Column[T, V]) Like(pattern string) Specification[T] {
+	return Like[T](c.name, pattern)
+}
+
+// Contains creates a specification for column LIKE '%value%'
+func (c Column[T, V]) Contains(value string) Specification[T] {
+	return Contains[T](c.name, value)
+}
+
+// StartsWith creates a specification for column LIKE 'value%'
+func (c Column[T, V]) StartsWith(value string) Specification[T] {
+	return StartsWith[T](c.name, value)
+}
+
+// EndsWith creates a specification for column LIKE '%value'
+func (c Column[T, V]) EndsWith(value string) Specification[T] {
+	return EndsWith[T](c.name, value)
+}
+
+// EqIgnoreCase creates a specification for LOWER(column) = LOWER(value)
+func (c Column[T, V]) EqIgnoreCase(value string) Specification[T] {
+	return EqualIgnoreCase[T](c.name, value)
+}
+
+// ContainsIgnoreCase creates a specification for column ILIKE '%value%'
+func (c Column[T, V]) ContainsIgnoreCase(value string) Specification[T] {
+	return ContainsIgnoreCase[T](c.name, value)
+}
+
+func toInterfaces[V any](values []V) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
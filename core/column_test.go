@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// testUserFields mirrors the UserFields variable jetorm-gen emits
+var testUserFields = struct {
+	Email     Column[TestUser, string]
+	Age       Column[TestUser, int]
+	CreatedAt Column[TestUser, time.Time]
+}{
+	Email:     NewColumn[TestUser, string]("email"),
+	Age:       NewColumn[TestUser, int]("age"),
+	CreatedAt: NewColumn[TestUser, time.Time]("created_at"),
+}
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     Specification[TestUser]
+		expected string
+		args     int
+	}{
+		{"Eq", testUserFields.Email.Eq("a@example.com"), "email = $1", 1},
+		{"NotEq", testUserFields.Email.NotEq("a@example.com"), "email != $1", 1},
+		{"Gt", testUserFields.Age.Gt(18), "age > $1", 1},
+		{"Gte", testUserFields.Age.Gte(18), "age >= $1", 1},
+		{"Lt", testUserFields.Age.Lt(65), "age < $1", 1},
+		{"Lte", testUserFields.Age.Lte(65), "age <= $1", 1},
+		{"Between", testUserFields.CreatedAt.Between(time.Time{}, time.Now()), "created_at BETWEEN $1 AND $2", 2},
+		{"In", testUserFields.Age.In(1, 2, 3), "age IN ($1, $2, $3)", 3},
+		{"NotIn", testUserFields.Age.NotIn(4), "age NOT IN ($1)", 1},
+		{"IsNull", testUserFields.CreatedAt.IsNull(), "created_at IS NULL", 0},
+		{"IsNotNull", testUserFields.CreatedAt.IsNotNull(), "created_at IS NOT NULL", 0},
+		{"StartsWith", testUserFields.Email.StartsWith("admin"), "email LIKE $1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.spec.ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, sql)
+			}
+			if len(args) != tt.args {
+				t.Errorf("Expected %d args, got %d", tt.args, len(args))
+			}
+		})
+	}
+
+	if testUserFields.Email.Name() != "email" || testUserFields.Email.String() != "email" {
+		t.Errorf("Expected column name 'email', got '%s'", testUserFields.Email.Name())
+	}
+}
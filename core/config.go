@@ -1,6 +1,9 @@
 package core
 
-import "time"
+import (
+	"io/fs"
+	"time"
+)
 
 // Config holds database configuration
 type Config struct {
@@ -13,6 +16,12 @@ type Config struct {
 	Password string // Database password
 	SSLMode  string // SSL mode: disable, require, verify-ca, verify-full
 
+	// Connection labeling
+	ServiceName     string            // Service name, reported as application_name with ServiceVersion
+	ServiceVersion  string            // Service version, e.g. a release tag or commit
+	ApplicationName string            // Explicit application_name (default: "ServiceName/ServiceVersion", then executable name)
+	PoolLabels      map[string]string // Extra labels attached to pool metrics
+
 	// Connection Pool
 	MaxOpenConns    int           // Maximum open connections (default: 25)
 	MaxIdleConns    int           // Maximum idle connections (default: 5)
@@ -20,9 +29,12 @@ type Config struct {
 	ConnMaxIdleTime time.Duration // Connection max idle time (default: 5m)
 
 	// Migrations
-	MigrationsPath string // Path to migration files
-	AutoMigrate    bool   // Auto-run migrations on startup
-	MigrationTable string // Migration version table (default: "schema_migrations")
+	MigrationsPath      string // Path to migration files
+	AutoMigrate         bool   // Auto-run migrations on startup
+	MigrationTable      string // Migration version table (default: "schema_migrations")
+	MigrateOnStart      fs.FS  // Migrations applied by Connect under an advisory lock, e.g. an embed.FS
+	SchemaVersion       int64  // Minimum migration version the application requires, besides RequireSchemaVersion
+	VerifySchemaVersion bool   // Fail Connect with ErrSchemaVersionMismatch when the database is older than required
 
 	// Jet Code Generation
 	JetGenPath    string // Path for generated Jet code
@@ -35,14 +47,24 @@ type Config struct {
 	LogSlowQueries time.Duration // Log queries slower than threshold
 
 	// Performance
-	PreparedStmts bool          // Use prepared statements (default: true)
-	QueryTimeout  time.Duration // Default query timeout (default: 30s)
+	PreparedStmts      bool          // Use prepared statements (default: true)
+	QueryTimeout       time.Duration // Default query timeout (default: 30s)
+	StatementCacheSize int           // Prepared statements cached per connection (default: pgx's 512), or StatementCacheDisabled
 
 	// Behavior
-	SoftDelete     bool   // Enable soft delete globally
-	CreatedAtField string // Custom created_at field name
-	UpdatedAtField string // Custom updated_at field name
-	DeletedAtField string // Custom deleted_at field name
+	SoftDelete     bool        // Enable soft delete globally
+	CreatedAtField string      // Custom created_at field name
+	UpdatedAtField string      // Custom updated_at field name
+	DeletedAtField string      // Custom deleted_at field name
+	ReadOnly       bool        // Reject writes with ErrReadOnly, e.g. for replicas or during freezes
+	RestrictRawSQL bool        // Reject raw Query/Exec of SQL not registered with AllowQueries, unless ctx has WithRawSQL
+	QueryComments  bool        // Append the context's actor and request ID to statements as a SQL comment
+	Clock          Clock       // Source of auto timestamps instead of the server's NOW(), e.g. a FrozenClock in tests
+	IDGenerator    IDGenerator // Source of inserted keys instead of random UUIDs and sequences, e.g. SequentialIDs in tests
+
+	// Development
+	NPlusOneThreshold int              // Warn when a WithNPlusOneDetection context runs the same single-row SELECT this often (0 disables)
+	NPlusOneReporter  NPlusOneReporter // Receives N+1 warnings instead of the logger
 }
 
 // DefaultConfig returns a Config with sensible defaults
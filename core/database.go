@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -16,6 +17,8 @@ type Database struct {
 	pool   *pgxpool.Pool
 	config Config
 	logger Logger
+
+	serverVersion atomic.Int64 // cached server_version_num, 0 until queried
 }
 
 // Connect creates a new database connection
@@ -37,6 +40,11 @@ func Connect(config Config) (*Database, error) {
 		config.QueryTimeout = 30 * time.Second
 	}
 
+	// Migrations write, so they cannot run against a read-only database
+	if config.ReadOnly && config.MigrateOnStart != nil {
+		return nil, fmt.Errorf("%w: MigrateOnStart cannot be used with ReadOnly", ErrReadOnly)
+	}
+
 	// Build connection string
 	connString := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -59,6 +67,14 @@ func Connect(config Config) (*Database, error) {
 	poolConfig.MinConns = int32(config.MaxIdleConns)
 	poolConfig.MaxConnLifetime = config.ConnMaxLifetime
 	poolConfig.MaxConnIdleTime = config.ConnMaxIdleTime
+	if name := config.applicationName(); name != "" {
+		poolConfig.ConnConfig.RuntimeParams["application_name"] = name
+	}
+	config.configureStatementCache(poolConfig.ConnConfig)
+	if config.ReadOnly {
+		// The server rejects writes that bypass the repositories, e.g. through Pool()
+		poolConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
 
 	// Create pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -86,7 +102,22 @@ func Connect(config Config) (*Database, error) {
 		db.logger = &defaultLogger{level: config.LogLevel}
 	}
 
-	db.logger.Info("database connection established", "host", config.Host, "database", config.Database)
+	db.logger.Info("database connection established", "host", config.Host, "database", config.Database,
+		"application_name", config.applicationName())
+
+	if config.MigrateOnStart != nil {
+		if err := db.migrateOnStart(context.Background()); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("%w: %v", ErrMigrationFailed, err)
+		}
+	}
+
+	if config.VerifySchemaVersion {
+		if err := db.VerifySchemaVersion(context.Background()); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
 
 	return db, nil
 }
@@ -125,6 +156,9 @@ func ConnectURL(connString string, opts ...ConfigOption) (*Database, error) {
 	if sslMode := query.Get("sslmode"); sslMode != "" {
 		config.SSLMode = sslMode
 	}
+	if applicationName := query.Get("application_name"); applicationName != "" {
+		config.ApplicationName = applicationName
+	}
 
 	// Apply additional options
 	for _, opt := range opts {
@@ -165,6 +199,55 @@ func WithLogSQL(enabled bool) ConfigOption {
 	}
 }
 
+// WithService sets the service name and version reported as application_name
+func WithService(name, version string) ConfigOption {
+	return func(c *Config) {
+		c.ServiceName = name
+		c.ServiceVersion = version
+	}
+}
+
+// WithPoolLabels adds labels to the pool's metrics
+func WithPoolLabels(labels map[string]string) ConfigOption {
+	return func(c *Config) {
+		if c.PoolLabels == nil {
+			c.PoolLabels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			c.PoolLabels[k] = v
+		}
+	}
+}
+
+// WithReadOnly makes the database reject writes with ErrReadOnly
+func WithReadOnly() ConfigOption {
+	return func(c *Config) {
+		c.ReadOnly = true
+	}
+}
+
+// WithSchemaVersionCheck makes Connect verify the database schema version
+func WithSchemaVersionCheck() ConfigOption {
+	return func(c *Config) {
+		c.VerifySchemaVersion = true
+	}
+}
+
+// WithRestrictedRawSQL only lets repositories run allowlisted raw SQL
+func WithRestrictedRawSQL() ConfigOption {
+	return func(c *Config) {
+		c.RestrictRawSQL = true
+	}
+}
+
+// WithQueryComments tags statements with the actor and request ID of their
+// context, see WithActor and WithRequestID
+func WithQueryComments() ConfigOption {
+	return func(c *Config) {
+		c.QueryComments = true
+	}
+}
+
 // Close closes the database connection
 func (db *Database) Close() {
 	if db.pool != nil {
@@ -183,6 +266,31 @@ func (db *Database) Ping(ctx context.Context) error {
 	return db.pool.Ping(ctx)
 }
 
+// ServerVersion returns the server's version number as reported by
+// server_version_num (e.g. 150004 for 15.4). The result is cached.
+func (db *Database) ServerVersion(ctx context.Context) (int, error) {
+	if v := db.serverVersion.Load(); v != 0 {
+		return int(v), nil
+	}
+
+	var version int
+	if err := db.pool.QueryRow(ctx, "SELECT current_setting('server_version_num')::int").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to detect server version: %w", err)
+	}
+	db.serverVersion.Store(int64(version))
+
+	return version, nil
+}
+
+// SupportsMerge reports whether the server supports MERGE (PostgreSQL 15+)
+func (db *Database) SupportsMerge(ctx context.Context) (bool, error) {
+	version, err := db.ServerVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	return version >= mergeMinVersion, nil
+}
+
 // Transaction executes a function within a transaction
 func (db *Database) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
 	return db.TransactionWithOptions(ctx, TxOptions{}, fn)
@@ -201,7 +309,7 @@ func (db *Database) TransactionWithOptions(ctx context.Context, opts TxOptions,
 	pgxTx, err := db.pool.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.TxIsoLevel(opts.Isolation.ToSQLIsolation().String()),
 		AccessMode: func() pgx.TxAccessMode {
-			if opts.ReadOnly {
+			if opts.ReadOnly || db.config.ReadOnly {
 				return pgx.ReadOnly
 			}
 			return pgx.ReadWrite
@@ -249,7 +357,7 @@ func (db *Database) BeginWithOptions(ctx context.Context, opts TxOptions) (*Tx,
 	pgxTx, err := db.pool.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.TxIsoLevel(opts.Isolation.ToSQLIsolation().String()),
 		AccessMode: func() pgx.TxAccessMode {
-			if opts.ReadOnly {
+			if opts.ReadOnly || db.config.ReadOnly {
 				return pgx.ReadOnly
 			}
 			return pgx.ReadWrite
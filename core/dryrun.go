@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is the subset of pgxpool.Pool and pgx.Tx that repositories execute statements on
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// dryRunKey is the context key for the dry-run capture
+type dryRunKey struct{}
+
+// CapturedStatement is a statement recorded in dry-run mode
+type CapturedStatement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// String formats the statement with its arguments
+func (s CapturedStatement) String() string {
+	if len(s.Args) == 0 {
+		return s.SQL
+	}
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		args[i] = fmt.Sprintf("$%d=%v", i+1, arg)
+	}
+	return s.SQL + " [" + strings.Join(args, " ") + "]"
+}
+
+// DryRunCapture records the statements issued in a dry-run context
+type DryRunCapture struct {
+	mu         sync.Mutex
+	statements []CapturedStatement
+}
+
+// Statements returns the recorded statements in execution order
+func (c *DryRunCapture) Statements() []CapturedStatement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CapturedStatement(nil), c.statements...)
+}
+
+// String returns the recorded statements, one per line, for snapshot tests
+func (c *DryRunCapture) String() string {
+	var sb strings.Builder
+	for _, statement := range c.Statements() {
+		sb.WriteString(statement.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Reset discards the recorded statements
+func (c *DryRunCapture) Reset() {
+	c.mu.Lock()
+	c.statements = nil
+	c.mu.Unlock()
+}
+
+func (c *DryRunCapture) record(sql string, args []interface{}) {
+	c.mu.Lock()
+	c.statements = append(c.statements, CapturedStatement{SQL: sql, Args: args})
+	c.mu.Unlock()
+}
+
+// DryRun returns a context in which repositories record their statements in
+// the returned capture instead of executing them. Statements that only execute,
+// such as deletes and updates without RETURNING, succeed and affect no rows;
+// reading a result, as finders, counts and RETURNING writes do, fails with
+// ErrDryRun.
+func (db *Database) DryRun(ctx context.Context) (context.Context, *DryRunCapture) {
+	capture := &DryRunCapture{}
+	return context.WithValue(ctx, dryRunKey{}, capture), capture
+}
+
+// dryRunCapture returns the capture of a dry-run context, or nil
+func dryRunCapture(ctx context.Context) *DryRunCapture {
+	capture, _ := ctx.Value(dryRunKey{}).(*DryRunCapture)
+	return capture
+}
+
+// poolConn returns the pool, or the dry-run recorder when ctx is in dry-run mode
+func (r *BaseRepository[T, ID]) poolConn(ctx context.Context) querier {
+	if capture := dryRunCapture(ctx); capture != nil {
+		return capture
+	}
+	return r.guard(r.db.pool)
+}
+
+// readConn returns the pool for reads made outside a repository, or the
+// dry-run recorder when ctx is in dry-run mode
+func (db *Database) readConn(ctx context.Context) querier {
+	if capture := dryRunCapture(ctx); capture != nil {
+		return capture
+	}
+	if db.config.QueryComments {
+		return commentQuerier{q: db.pool}
+	}
+	return db.pool
+}
+
+// txConn returns the repository's transaction, or the dry-run recorder when
+// ctx is in dry-run mode
+func (r *BaseRepository[T, ID]) txConn(ctx context.Context) querier {
+	if capture := dryRunCapture(ctx); capture != nil {
+		return capture
+	}
+	return r.guard(r.tx.tx)
+}
+
+// Exec records the statement and reports no affected rows
+func (c *DryRunCapture) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.record(sql, args)
+	return pgconn.CommandTag{}, nil
+}
+
+// Query records the statement; the returned rows fail with ErrDryRun
+func (c *DryRunCapture) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.record(sql, args)
+	return errRows{err: ErrDryRun}, nil
+}
+
+// QueryRow records the statement; scanning the row fails with ErrDryRun
+func (c *DryRunCapture) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c.record(sql, args)
+	return errRows{err: ErrDryRun}
+}
+
+// SendBatch records every queued statement
+func (c *DryRunCapture) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, query := range b.QueuedQueries {
+		c.record(query.SQL, query.Arguments)
+	}
+	return dryRunBatchResults{}
+}
+
+// CopyFrom records a COPY statement with the copied rows as arguments
+func (c *DryRunCapture) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	var rows []interface{}
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, values)
+	}
+	if err := rowSrc.Err(); err != nil {
+		return 0, err
+	}
+
+	c.record(fmt.Sprintf("COPY %s (%s) FROM STDIN", tableName.Sanitize(), strings.Join(columnNames, ", ")), rows)
+	return int64(len(rows)), nil
+}
+
+// errRows is the empty result of a statement that was not executed; reading
+// it fails with err
+type errRows struct {
+	err error
+}
+
+func (errRows) Close()                                       {}
+func (r errRows) Err() error                                 { return r.err }
+func (errRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (errRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (errRows) Next() bool                                   { return false }
+func (r errRows) Scan(dest ...any) error                     { return r.err }
+func (r errRows) Values() ([]any, error)                     { return nil, r.err }
+func (errRows) RawValues() [][]byte                          { return nil }
+func (errRows) Conn() *pgx.Conn                              { return nil }
+
+// dryRunBatchResults are the results of a recorded batch
+type dryRunBatchResults struct{}
+
+func (dryRunBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, nil }
+func (dryRunBatchResults) Query() (pgx.Rows, error)         { return errRows{err: ErrDryRun}, nil }
+func (dryRunBatchResults) QueryRow() pgx.Row                { return errRows{err: ErrDryRun} }
+func (dryRunBatchResults) Close() error                     { return nil }
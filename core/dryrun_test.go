@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDatabase_DryRun(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	db := &Database{}
+
+	t.Run("should record statements without executing them", func(t *testing.T) {
+		ctx, capture := db.DryRun(context.Background())
+
+		if err := repo.DeleteByID(ctx, 42); err != nil {
+			t.Fatalf("Expected delete to succeed, got %v", err)
+		}
+		affected, err := repo.DeleteWithSpec(ctx, GreaterThan[TestUser]("age", 90))
+		if err != nil {
+			t.Fatalf("Expected delete to succeed, got %v", err)
+		}
+		if affected != 0 {
+			t.Errorf("Expected no affected rows, got %d", affected)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 2 {
+			t.Fatalf("Expected 2 statements, got %d", len(statements))
+		}
+		if statements[0].SQL != "DELETE FROM test_user WHERE id = $1" {
+			t.Errorf("Unexpected SQL '%s'", statements[0].SQL)
+		}
+		if len(statements[0].Args) != 1 || statements[0].Args[0] != int64(42) {
+			t.Errorf("Expected args [42], got %v", statements[0].Args)
+		}
+		if !strings.Contains(capture.String(), "DELETE FROM test_user WHERE id = $1 [$1=42]\n") {
+			t.Errorf("Unexpected capture output:\n%s", capture.String())
+		}
+	})
+
+	t.Run("should fail reads with ErrDryRun", func(t *testing.T) {
+		ctx, capture := db.DryRun(context.Background())
+
+		if _, err := repo.FindByID(ctx, 1); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun from FindByID, got %v", err)
+		}
+		if _, err := repo.FindAll(ctx); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun from FindAll, got %v", err)
+		}
+		if _, err := repo.Save(ctx, &TestUser{Email: "a@example.com"}); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun from Save, got %v", err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 3 {
+			t.Fatalf("Expected 3 statements, got %d", len(statements))
+		}
+		if !strings.HasPrefix(statements[2].SQL, "INSERT INTO test_user") {
+			t.Errorf("Expected INSERT to be recorded, got '%s'", statements[2].SQL)
+		}
+	})
+
+	t.Run("should record batches and copies", func(t *testing.T) {
+		ctx, capture := db.DryRun(context.Background())
+
+		count, err := repo.BulkInsert(ctx, []*TestUser{{Email: "a@example.com"}, {Email: "b@example.com"}})
+		if err != nil {
+			t.Fatalf("Expected bulk insert to succeed, got %v", err)
+		}
+		if count != 2 {
+			t.Errorf("Expected 2 copied rows, got %d", count)
+		}
+		if _, err := repo.SaveAll(ctx, []*TestUser{{Email: "c@example.com"}, {Email: "d@example.com"}}); err == nil {
+			t.Error("Expected SaveAll to fail reading RETURNING rows")
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 3 {
+			t.Fatalf("Expected 3 statements, got %d", len(statements))
+		}
+		if !strings.HasPrefix(statements[0].SQL, `COPY "test_user" (`) {
+			t.Errorf("Expected COPY statement, got '%s'", statements[0].SQL)
+		}
+
+		capture.Reset()
+		if len(capture.Statements()) != 0 {
+			t.Error("Expected Reset to discard statements")
+		}
+	})
+}
+
+func TestBaseRepository_FindMissingIDs(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+
+	t.Run("should not query for no ids", func(t *testing.T) {
+		missing, err := repo.FindMissingIDs(ctx, nil)
+		if err != nil || len(missing) != 0 {
+			t.Errorf("Expected no missing ids, got %v (%v)", missing, err)
+		}
+		exists, err := repo.ExistsAllByIDs(ctx, nil)
+		if err != nil || !exists {
+			t.Errorf("Expected true, got %v (%v)", exists, err)
+		}
+		if len(capture.Statements()) != 0 {
+			t.Errorf("Expected no statements, got %v", capture.Statements())
+		}
+	})
+
+	t.Run("should join an id array in one query", func(t *testing.T) {
+		ids := []int64{3, 1, 3}
+		if _, err := repo.FindMissingIDs(ctx, ids); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.ExistsAllByIDs(ctx, ids); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+
+		expected := []string{
+			"SELECT v.id FROM unnest($1::bigint[]) WITH ORDINALITY AS v(id, ord) WHERE NOT EXISTS (SELECT 1 FROM test_user WHERE id = v.id) GROUP BY v.id ORDER BY MIN(v.ord)",
+			"SELECT NOT EXISTS (SELECT 1 FROM unnest($1::bigint[]) AS v(id) WHERE NOT EXISTS (SELECT 1 FROM test_user WHERE id = v.id))",
+		}
+		statements := capture.Statements()
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d statements, got %d", len(expected), len(statements))
+		}
+		for i, statement := range statements {
+			if statement.SQL != expected[i] {
+				t.Errorf("Statement %d: expected %q, got %q", i, expected[i], statement.SQL)
+			}
+			if len(statement.Args) != 1 {
+				t.Errorf("Statement %d: expected the id array as the only argument, got %v", i, statement.Args)
+			}
+		}
+	})
+}
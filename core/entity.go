@@ -1,6 +1,8 @@
 package core
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"strings"
@@ -12,6 +14,8 @@ type Entity struct {
 	TableName  string
 	Fields     []Field
 	PrimaryKey *Field
+	SoftDelete *Field // Field tagged soft_delete, if any
+	Discriminator *Discriminator // Single-table inheritance mapping, if any
 }
 
 // Field represents metadata about an entity field
@@ -36,6 +40,11 @@ type Field struct {
 	AutoNowAdd      bool
 	AutoNow         bool
 	Ignored         bool // Field is ignored (db:"-")
+	SoftDelete      bool // Field holds the soft delete timestamp
+	UUID            string // UUID generation strategy: v4, v7 or db
+	JSON            bool   // Struct, map or slice stored in a json/jsonb column
+	Enum            *Enum  // PostgreSQL enum type of the column, if any
+	StructIndex     []int // Index path in the entity struct, through embedded structs
 }
 
 // CompositeIndex represents a composite index definition
@@ -61,19 +70,69 @@ func EntityMetadata(entity interface{}) (*Entity, error) {
 		Fields:    make([]Field, 0),
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	// Embedded structs are flattened into the entity's columns; visible fields
+	// list each embedded struct before the fields it promotes
+	flattened := make(map[string]bool)
+	for _, field := range reflect.VisibleFields(t) {
+		if len(field.Index) > 1 && !flattened[fmt.Sprint(field.Index[:len(field.Index)-1])] {
+			continue
+		}
+		if isEmbeddedStruct(field) {
+			flattened[fmt.Sprint(field.Index)] = true
+			if d := parseDiscriminator(t, field); d != nil && len(field.Index) == 1 {
+				meta.Discriminator = d
+				meta.TableName = d.Table
+			}
+			continue
+		}
+
 		fieldMeta := parseFieldTags(field)
+		fieldMeta.StructIndex = field.Index
 		meta.Fields = append(meta.Fields, fieldMeta)
 
 		if fieldMeta.PrimaryKey {
 			meta.PrimaryKey = &fieldMeta
 		}
+		if fieldMeta.SoftDelete {
+			meta.SoftDelete = &fieldMeta
+		}
 	}
 
 	return meta, nil
 }
 
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// isEmbeddedStruct reports whether field is an embedded struct whose fields
+// should be flattened into the entity. Embedded structs with a db tag, ignored
+// with jet:"-", implementing driver.Valuer or sql.Scanner, or without exported
+// fields (such as time.Time) map to one column.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	if field.Tag.Get("db") != "" || field.Tag.Get("jet") == "-" {
+		return false
+	}
+	if hasCustomCodec(field.Type) {
+		return false
+	}
+	for i := 0; i < field.Type.NumField(); i++ {
+		if field.Type.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// valueOf returns the field's value within an entity struct value
+func (f *Field) valueOf(entity reflect.Value) reflect.Value {
+	return entity.FieldByIndex(f.StructIndex)
+}
+
 // parseFieldTags parses struct tags for a field
 func parseFieldTags(field reflect.StructField) Field {
 	dbTag := field.Tag.Get("db")
@@ -170,10 +229,22 @@ func parseFieldTags(field reflect.StructField) Field {
 				f.AutoNowAdd = true
 			case "auto_now":
 				f.AutoNow = true
+			case "soft_delete":
+				f.SoftDelete = true
+			case "uuid":
+				f.UUID = tag.Value
+				if f.UUID == "" {
+					f.UUID = UUIDv4
+				}
+			case "enum":
+				// Format: enum:type_name(value1,value2,...)
+				f.Enum = parseEnumTag(tag.Value)
 			}
 		}
 	}
 
+	f.JSON = isJSONColumn(&f)
+
 	return f
 }
 
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Enum is a PostgreSQL enum type declared with jet:"enum:name(value1,value2)"
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// parseEnumTag parses "name(value1,value2)". Values may be single-quoted.
+// A malformed tag yields an Enum without values, reported by validateEnumField.
+func parseEnumTag(value string) *Enum {
+	open := strings.Index(value, "(")
+	if open < 0 || !strings.HasSuffix(value, ")") {
+		return &Enum{Name: strings.TrimSpace(value)}
+	}
+
+	enum := &Enum{Name: strings.TrimSpace(value[:open])}
+	for _, v := range strings.Split(value[open+1:len(value)-1], ",") {
+		v = strings.Trim(strings.TrimSpace(v), "'")
+		if v != "" {
+			enum.Values = append(enum.Values, v)
+		}
+	}
+	return enum
+}
+
+// Contains reports whether value is one of the enum's values
+func (e *Enum) Contains(value string) bool {
+	for _, v := range e.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEnumField checks that an enum field names a type with values and
+// holds a string
+func validateEnumField(f *Field) error {
+	if f.Enum == nil {
+		return nil
+	}
+	if f.Enum.Name == "" || len(f.Enum.Values) == 0 {
+		return fmt.Errorf("%w: %s must be tagged enum:type_name(value1,value2,...)", ErrInvalidEnum, f.Name)
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.String {
+		return fmt.Errorf("%w: %s must be a string type, got %s", ErrInvalidEnum, f.Name, f.Type)
+	}
+	return nil
+}
+
+// checkEnumValue reports ErrInvalidEnumValue when value, a string or
+// pointer to string, is not one of the field's enum values. Nil pointers are
+// NULL and always accepted.
+func (f *Field) checkEnumValue(value reflect.Value) error {
+	if f.Enum == nil || !value.IsValid() {
+		return nil
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("%w: %s expects a string, got %s", ErrInvalidEnumValue, f.Name, value.Type())
+	}
+	if !f.Enum.Contains(value.String()) {
+		return fmt.Errorf("%w: %s must be one of %s, got %q",
+			ErrInvalidEnumValue, f.Name, strings.Join(f.Enum.Values, ", "), value.String())
+	}
+	return nil
+}
+
+// validateEnums checks every enum field of entity before it is written
+func (r *BaseRepository[T, ID]) validateEnums(entity *T) error {
+	v := reflect.ValueOf(entity).Elem()
+	for i := range r.entity.Fields {
+		if err := r.entity.Fields[i].checkEnumValue(r.entity.Fields[i].valueOf(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
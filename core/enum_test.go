@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestTicket has a required and a nullable enum column
+type TestTicket struct {
+	ID       int64   `db:"id" jet:"primary_key,auto_increment"`
+	Status   string  `db:"status" jet:"enum:ticket_status(open,closed),not_null"`
+	Previous *string `db:"previous" jet:"enum:ticket_status(open,closed)"`
+}
+
+func TestBaseRepository_Enums(t *testing.T) {
+	repo, err := NewBaseRepository[TestTicket, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should parse enum tags", func(t *testing.T) {
+		status := repo.lookupField("status")
+		if status.Enum == nil || status.Enum.Name != "ticket_status" {
+			t.Fatalf("Expected enum ticket_status, got %+v", status.Enum)
+		}
+		if len(status.Enum.Values) != 2 || !status.Enum.Contains("closed") {
+			t.Errorf("Expected values [open closed], got %v", status.Enum.Values)
+		}
+		if !status.NotNull {
+			t.Error("Expected not_null after the enum values to be parsed")
+		}
+	})
+
+	t.Run("should reject invalid values before the database", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		_, err := repo.Save(ctx, &TestTicket{Status: "pending"})
+		if !errors.Is(err, ErrInvalidEnumValue) {
+			t.Errorf("Expected ErrInvalidEnumValue, got %v", err)
+		}
+		previous := "reopened"
+		if _, err := repo.Save(ctx, &TestTicket{Status: "open", Previous: &previous}); !errors.Is(err, ErrInvalidEnumValue) {
+			t.Errorf("Expected ErrInvalidEnumValue, got %v", err)
+		}
+		if len(capture.Statements()) != 0 {
+			t.Errorf("Expected no statements, got %v", capture.Statements())
+		}
+	})
+
+	t.Run("should accept valid values and NULL", func(t *testing.T) {
+		if err := repo.validateEnums(&TestTicket{Status: "open"}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		closed := "closed"
+		if err := repo.validateEnums(&TestTicket{Status: "closed", Previous: &closed}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should check partial updates", func(t *testing.T) {
+		if _, _, err := repo.updateFieldsStatement(1, map[string]interface{}{"status": "pending"}); !errors.Is(err, ErrInvalidEnumValue) {
+			t.Errorf("Expected ErrInvalidEnumValue, got %v", err)
+		}
+		if _, _, err := repo.updateFieldsStatement(1, map[string]interface{}{"status": 3}); !errors.Is(err, ErrInvalidEnumValue) {
+			t.Errorf("Expected ErrInvalidEnumValue for a non-string, got %v", err)
+		}
+		if _, _, err := repo.updateFieldsStatement(1, map[string]interface{}{"status": "closed", "previous": nil}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestNewBaseRepository_InvalidEnum(t *testing.T) {
+	type missingValues struct {
+		ID     int64  `db:"id" jet:"primary_key"`
+		Status string `db:"status" jet:"enum:status_enum"`
+	}
+	if _, err := NewBaseRepository[missingValues, int64](nil); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum for missing values, got %v", err)
+	}
+
+	type notString struct {
+		ID     int64 `db:"id" jet:"primary_key"`
+		Status int   `db:"status" jet:"enum:status_enum(active,inactive)"`
+	}
+	if _, err := NewBaseRepository[notString, int64](nil); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum for a non-string field, got %v", err)
+	}
+}
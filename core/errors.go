@@ -20,5 +20,56 @@ var (
 	
 	// ErrTransactionFailed is returned when a transaction fails
 	ErrTransactionFailed = errors.New("jetorm: transaction failed")
+	
+	// ErrUnknownField is returned when a field name does not map to an entity column
+	ErrUnknownField = errors.New("jetorm: unknown field")
+	
+	// ErrSoftDeleteUnsupported is returned when an entity has no soft delete field
+	ErrSoftDeleteUnsupported = errors.New("jetorm: entity does not support soft delete")
+	
+	// ErrTransactionRequired is returned when an operation must run inside a transaction
+	ErrTransactionRequired = errors.New("jetorm: operation requires a transaction")
+	
+	// ErrInvalidCursor is returned when a keyset cursor cannot be decoded or does not match the sort
+	ErrInvalidCursor = errors.New("jetorm: invalid cursor")
+	
+	// ErrMergeUnsupported is returned when the server is too old for the requested MERGE statement
+	ErrMergeUnsupported = errors.New("jetorm: MERGE is not supported by this server")
+	
+	// ErrMigrationFailed is returned when migrations applied during Connect fail
+	ErrMigrationFailed = errors.New("jetorm: migration failed")
+	
+	// ErrUnsupportedUUID is returned when a uuid field has an unknown strategy or type
+	ErrUnsupportedUUID = errors.New("jetorm: unsupported uuid field")
+	
+	// ErrDryRun is returned when reading the result of a statement recorded in dry-run mode
+	ErrDryRun = errors.New("jetorm: statement not executed in dry-run mode")
+	
+	// ErrReadOnly is returned when a write is attempted on a read-only database
+	ErrReadOnly = errors.New("jetorm: database is read-only")
+	
+	// ErrInvalidEnum is returned when an enum tag is malformed or used on a non-string field
+	ErrInvalidEnum = errors.New("jetorm: invalid enum field")
+	
+	// ErrInvalidEnumValue is returned when an enum field holds a value outside its type
+	ErrInvalidEnumValue = errors.New("jetorm: invalid enum value")
+	
+	// ErrSchemaVersionMismatch is returned when the database schema is older than the application expects
+	ErrSchemaVersionMismatch = errors.New("jetorm: database schema version is older than required")
+	
+	// ErrQueryNotAllowed is returned when raw SQL is rejected by the query allowlist or denylist
+	ErrQueryNotAllowed = errors.New("jetorm: query not allowed")
+	
+	// ErrInvalidIdentifier is returned when a table or column name is not a plain SQL identifier
+	ErrInvalidIdentifier = errors.New("jetorm: invalid identifier")
+	
+	// ErrInvalidDiscriminator is returned when a discriminator tag is malformed or its column is missing
+	ErrInvalidDiscriminator = errors.New("jetorm: invalid discriminator")
+	
+	// ErrUnknownDiscriminator is returned when a row's discriminator names no registered entity type
+	ErrUnknownDiscriminator = errors.New("jetorm: unknown discriminator value")
+	
+	// ErrObjectExists is returned when an archive object's key is already taken in the object store
+	ErrObjectExists = errors.New("jetorm: archive object already exists")
 )
 
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// FacetDef defines a facet: the distinct values of Column with their counts,
+// or the counts of labeled Ranges
+type FacetDef[T any] struct {
+	Name   string
+	Column string          // Count rows per value of this column
+	Ranges []FacetRange[T] // Or count rows matching each range
+	Limit  int             // Keep the most frequent values of Column; 0 keeps all
+}
+
+// FacetRange is a labeled bucket of a range facet, such as a price band
+type FacetRange[T any] struct {
+	Label string
+	Spec  Specification[T]
+}
+
+// FacetBucket is a facet value, or range label, with its row count
+type FacetBucket struct {
+	Value interface{} // nil for NULL
+	Count int64
+}
+
+// FacetResult holds the count of rows matching the specification and the
+// buckets of each facet by name
+type FacetResult struct {
+	Total  int64
+	Facets map[string][]FacetBucket
+}
+
+// ValueFacet counts rows per value of a column, keeping the limit most
+// frequent values (0 for all)
+func ValueFacet[T any](name, column string, limit int) FacetDef[T] {
+	return FacetDef[T]{Name: name, Column: column, Limit: limit}
+}
+
+// RangeFacet counts rows matching each range
+func RangeFacet[T any](name string, ranges ...FacetRange[T]) FacetDef[T] {
+	return FacetDef[T]{Name: name, Ranges: ranges}
+}
+
+// Facets counts the rows matching spec and, in the same query, the rows of
+// each facet bucket. Value facets are grouped with GROUPING SETS and range
+// facets are counted with FILTER clauses, so any number of facets costs one
+// round trip. Value buckets are ordered by count, most frequent first (ties
+// by value), and range buckets keep their definition order.
+func (r *BaseRepository[T, ID]) Facets(ctx context.Context, spec Specification[T], facets []FacetDef[T]) (*FacetResult, error) {
+	var columns []string
+	seenNames := make(map[string]bool)
+	seenColumns := make(map[string]bool)
+	for _, facet := range facets {
+		if facet.Name == "" || seenNames[facet.Name] {
+			return nil, fmt.Errorf("%w: facet names must be unique and not empty, got %q", ErrInvalidInput, facet.Name)
+		}
+		seenNames[facet.Name] = true
+		if (facet.Column == "") == (len(facet.Ranges) == 0) {
+			return nil, fmt.Errorf("%w: facet %s needs a column or ranges", ErrInvalidInput, facet.Name)
+		}
+		if facet.Column == "" {
+			continue
+		}
+		if err := r.validateColumns([]string{facet.Column}); err != nil {
+			return nil, err
+		}
+		if seenColumns[facet.Column] {
+			return nil, fmt.Errorf("%w: column %s is faceted twice", ErrInvalidInput, facet.Column)
+		}
+		seenColumns[facet.Column] = true
+		columns = append(columns, facet.Column)
+	}
+
+	var whereClause string
+	var args []interface{}
+	if spec != nil {
+		whereClause, args = spec.ToSQL()
+	}
+
+	// Range buckets are counted on the grand total row
+	var selects []string
+	if len(columns) > 0 {
+		selects = append(selects, "GROUPING("+strings.Join(columns, ", ")+")")
+		selects = append(selects, columns...)
+	}
+	selects = append(selects, "COUNT(*)")
+	for _, facet := range facets {
+		for _, bucket := range facet.Ranges {
+			bucketSQL, bucketArgs := "TRUE", []interface{}(nil)
+			if bucket.Spec != nil {
+				if sql, specArgs := bucket.Spec.ToSQL(); sql != "" {
+					bucketSQL, bucketArgs = RenumberPlaceholders(sql, len(args)+1), specArgs
+				}
+			}
+			selects = append(selects, fmt.Sprintf("COUNT(*) FILTER (WHERE %s)", bucketSQL))
+			args = append(args, bucketArgs...)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(selects, ", "), r.tableName, r.whereClause(whereClause))
+	if len(columns) > 0 {
+		sets := make([]string, 0, len(columns)+1)
+		sets = append(sets, "()")
+		for _, column := range columns {
+			sets = append(sets, "("+column+")")
+		}
+		query += " GROUP BY GROUPING SETS (" + strings.Join(sets, ", ") + ")"
+	}
+
+	r.logQuery(query, args)
+
+	var rows pgx.Rows
+	var err error
+	if r.tx != nil {
+		rows, err = r.txConn(ctx).Query(ctx, query, args...)
+	} else {
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// GROUPING sets the bit of every column grouped out of a row, the first
+	// column being the most significant
+	all := int32(1)<<len(columns) - 1
+	columnIndex := make(map[int32]int, len(columns))
+	for i := range columns {
+		columnIndex[all&^(1<<(len(columns)-1-i))] = i
+	}
+
+	result := &FacetResult{Facets: make(map[string][]FacetBucket, len(facets))}
+	valueBuckets := make([][]FacetBucket, len(columns))
+	var grouping int32
+	values := make([]interface{}, len(columns))
+	var rangeCounts []int64
+	for _, facet := range facets {
+		for range facet.Ranges {
+			rangeCounts = append(rangeCounts, 0)
+		}
+	}
+
+	for rows.Next() {
+		var count int64
+		dest := make([]interface{}, 0, len(selects))
+		if len(columns) > 0 {
+			dest = append(dest, &grouping)
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+		}
+		dest = append(dest, &count)
+		filtered := make([]int64, len(rangeCounts))
+		for i := range filtered {
+			dest = append(dest, &filtered[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		if grouping == all {
+			result.Total = count
+			copy(rangeCounts, filtered)
+			continue
+		}
+		if i, ok := columnIndex[grouping]; ok {
+			valueBuckets[i] = append(valueBuckets[i], FacetBucket{Value: values[i], Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	column, rangeIndex := 0, 0
+	for _, facet := range facets {
+		if facet.Column != "" {
+			buckets := valueBuckets[column]
+			column++
+			sort.SliceStable(buckets, func(i, j int) bool {
+				if buckets[i].Count != buckets[j].Count {
+					return buckets[i].Count > buckets[j].Count
+				}
+				return fmt.Sprint(buckets[i].Value) < fmt.Sprint(buckets[j].Value)
+			})
+			if facet.Limit > 0 && len(buckets) > facet.Limit {
+				buckets = buckets[:facet.Limit]
+			}
+			if buckets == nil {
+				buckets = []FacetBucket{}
+			}
+			result.Facets[facet.Name] = buckets
+			continue
+		}
+		buckets := make([]FacetBucket, len(facet.Ranges))
+		for i, bucket := range facet.Ranges {
+			buckets[i] = FacetBucket{Value: bucket.Label, Count: rangeCounts[rangeIndex]}
+			rangeIndex++
+		}
+		result.Facets[facet.Name] = buckets
+	}
+	return result, nil
+}
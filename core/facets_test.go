@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBaseRepository_Facets(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should count facets in one query", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		_, err := repo.Facets(ctx, Like[TestUser]("email", "%@example.com"), []FacetDef[TestUser]{
+			ValueFacet[TestUser]("ages", "age", 10),
+			RangeFacet("age_bands",
+				FacetRange[TestUser]{Label: "minor", Spec: LessThan[TestUser]("age", 18)},
+				FacetRange[TestUser]{Label: "adult", Spec: Between[TestUser]("age", 18, 64)},
+			),
+			ValueFacet[TestUser]("names", "username", 0),
+		})
+		if !errors.Is(err, ErrDryRun) {
+			t.Fatalf("Expected ErrDryRun, got %v", err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 1 {
+			t.Fatalf("Expected 1 statement, got %d", len(statements))
+		}
+		expected := "SELECT GROUPING(age, username), age, username, COUNT(*), " +
+			"COUNT(*) FILTER (WHERE age < $2), COUNT(*) FILTER (WHERE age BETWEEN $3 AND $4) " +
+			"FROM test_user WHERE email LIKE $1 GROUP BY GROUPING SETS ((), (age), (username))"
+		if statements[0].SQL != expected {
+			t.Errorf("Expected %q, got %q", expected, statements[0].SQL)
+		}
+		if len(statements[0].Args) != 4 {
+			t.Errorf("Expected 4 args, got %v", statements[0].Args)
+		}
+	})
+
+	t.Run("should skip grouping without value facets", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		repo.Facets(ctx, nil, []FacetDef[TestUser]{
+			RangeFacet("recent", FacetRange[TestUser]{Label: "any"}),
+		})
+		expected := "SELECT COUNT(*), COUNT(*) FILTER (WHERE TRUE) FROM test_user"
+		if statements := capture.Statements(); len(statements) != 1 || statements[0].SQL != expected {
+			t.Errorf("Expected %q, got %v", expected, statements)
+		}
+	})
+
+	t.Run("should reject invalid facets", func(t *testing.T) {
+		ctx := context.Background()
+		invalid := [][]FacetDef[TestUser]{
+			{{Name: "", Column: "age"}},
+			{{Name: "a", Column: "age"}, {Name: "a", Column: "username"}},
+			{{Name: "a"}},
+			{{Name: "a", Column: "age"}, {Name: "b", Column: "age"}},
+		}
+		for i, facets := range invalid {
+			if _, err := repo.Facets(ctx, nil, facets); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("Case %d: expected ErrInvalidInput, got %v", i, err)
+			}
+		}
+		if _, err := repo.Facets(ctx, nil, []FacetDef[TestUser]{ValueFacet[TestUser]("x", "nope", 0)}); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+}
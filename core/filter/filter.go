@@ -0,0 +1,397 @@
+// Package filter binds HTTP query strings to repository specifications.
+//
+// A query string such as
+//
+//	?age.gte=18&status.in=active,premium&sort=-created_at&page=2&size=50
+//
+// becomes And(GreaterThanEqual("age", 18), In("status", "active", "premium"))
+// and a Pageable sorted by created_at descending. Only fields whitelisted on
+// the Binder can be filtered or sorted on, and values are converted to the
+// Go type of the entity field, so clients never supply SQL.
+package filter
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+// Reserved query parameters
+const (
+	SortParam = "sort"
+	PageParam = "page"
+	SizeParam = "size"
+)
+
+// Operators accepted after the field name, e.g. age.gte=18. A parameter
+// without an operator compares for equality.
+const (
+	OpEq         = "eq"
+	OpNe         = "ne"
+	OpGt         = "gt"
+	OpGte        = "gte"
+	OpLt         = "lt"
+	OpLte        = "lte"
+	OpIn         = "in"          // comma-separated values
+	OpNotIn      = "not_in"      // comma-separated values
+	OpBetween    = "between"     // two comma-separated values
+	OpIsNull     = "is_null"     // true or false
+	OpLike       = "like"        // raw LIKE pattern
+	OpContains   = "contains"    // LIKE '%value%'
+	OpStartsWith = "starts_with" // LIKE 'value%'
+	OpEndsWith   = "ends_with"   // LIKE '%value'
+)
+
+// Option configures a Binder
+type Option func(*options)
+
+type options struct {
+	filterable    []string
+	sortable      []string
+	defaultSize   int
+	maxSize       int
+	defaultSort   []string
+	ignoreUnknown bool
+}
+
+// Filterable whitelists fields, by column or Go field name, that may be filtered on
+func Filterable(fields ...string) Option {
+	return func(o *options) {
+		o.filterable = append(o.filterable, fields...)
+	}
+}
+
+// Sortable whitelists fields, by column or Go field name, that may be sorted on
+func Sortable(fields ...string) Option {
+	return func(o *options) {
+		o.sortable = append(o.sortable, fields...)
+	}
+}
+
+// PageSize sets the page size used when the size parameter is absent and the
+// largest size a client may request (defaults: 20 and 100)
+func PageSize(defaultSize, maxSize int) Option {
+	return func(o *options) {
+		o.defaultSize = defaultSize
+		o.maxSize = maxSize
+	}
+}
+
+// DefaultSort sets the orders used when the sort parameter is absent, in the
+// same syntax, e.g. DefaultSort("-created_at", "id")
+func DefaultSort(fields ...string) Option {
+	return func(o *options) {
+		o.defaultSort = fields
+	}
+}
+
+// IgnoreUnknown makes Bind skip parameters that do not name a filterable
+// field instead of rejecting them, for endpoints sharing the query string
+// with other parameters
+func IgnoreUnknown() Option {
+	return func(o *options) {
+		o.ignoreUnknown = true
+	}
+}
+
+// Binder parses query parameters into specifications of T
+type Binder[T any] struct {
+	filterable    map[string]*core.Field // column and Go field name -> field
+	sortable      map[string]string      // column and Go field name -> column
+	defaultSize   int
+	maxSize       int
+	defaultSort   core.Sort
+	ignoreUnknown bool
+}
+
+// NewBinder creates a binder for entity T. It returns core.ErrUnknownField
+// when a whitelisted field is not a column of T.
+func NewBinder[T any](opts ...Option) (*Binder[T], error) {
+	entity, err := core.RegisterEntity[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	o := options{defaultSize: 20, maxSize: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.defaultSize <= 0 || o.maxSize < o.defaultSize {
+		return nil, fmt.Errorf("%w: invalid page sizes %d and %d", core.ErrInvalidInput, o.defaultSize, o.maxSize)
+	}
+
+	b := &Binder[T]{
+		filterable:    make(map[string]*core.Field),
+		sortable:      make(map[string]string),
+		defaultSize:   o.defaultSize,
+		maxSize:       o.maxSize,
+		ignoreUnknown: o.ignoreUnknown,
+	}
+	for _, name := range o.filterable {
+		f := lookupField(entity, name)
+		if f == nil {
+			return nil, fmt.Errorf("%w: %s", core.ErrUnknownField, name)
+		}
+		b.filterable[f.DBName] = f
+		b.filterable[f.Name] = f
+	}
+	for _, name := range o.sortable {
+		f := lookupField(entity, name)
+		if f == nil {
+			return nil, fmt.Errorf("%w: %s", core.ErrUnknownField, name)
+		}
+		b.sortable[f.DBName] = f.DBName
+		b.sortable[f.Name] = f.DBName
+	}
+	if len(o.defaultSort) > 0 {
+		if b.defaultSort, err = b.parseSort(strings.Join(o.defaultSort, ",")); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func lookupField(entity *core.Entity, name string) *core.Field {
+	for i := range entity.Fields {
+		f := &entity.Fields[i]
+		if !f.Ignored && (f.DBName == name || f.Name == name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// Bind parses the query parameters into a specification, nil when there are
+// no filters, and a Pageable. Conditions are combined with AND, including
+// repeated parameters. Invalid parameters fail with core.ErrInvalidInput or
+// core.ErrUnknownField, which API layers can report as bad requests.
+func (b *Binder[T]) Bind(values url.Values) (core.Specification[T], core.Pageable, error) {
+	spec, err := b.Spec(values)
+	if err != nil {
+		return nil, core.Pageable{}, err
+	}
+	pageable, err := b.Pageable(values)
+	if err != nil {
+		return nil, core.Pageable{}, err
+	}
+	return spec, pageable, nil
+}
+
+// Spec parses the filter parameters, ignoring sort and paging parameters
+func (b *Binder[T]) Spec(values url.Values) (core.Specification[T], error) {
+	// Sort keys so the generated SQL does not depend on map order
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var specs []core.Specification[T]
+	for _, key := range keys {
+		if key == SortParam || key == PageParam || key == SizeParam {
+			continue
+		}
+		name, op, _ := strings.Cut(key, ".")
+		if op == "" {
+			op = OpEq
+		}
+		f, ok := b.filterable[name]
+		if !ok {
+			if b.ignoreUnknown {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s is not filterable", core.ErrUnknownField, name)
+		}
+		for _, raw := range values[key] {
+			spec, err := b.condition(f, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return core.And(specs...), nil
+}
+
+// condition builds the specification for one parameter value
+func (b *Binder[T]) condition(f *core.Field, op, raw string) (core.Specification[T], error) {
+	column := f.DBName
+	switch op {
+	case OpIsNull:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.%s expects true or false", core.ErrInvalidInput, column, op)
+		}
+		if isNull {
+			return core.IsNull[T](column), nil
+		}
+		return core.IsNotNull[T](column), nil
+	case OpLike:
+		return core.Like[T](column, raw), nil
+	case OpContains:
+		return core.Contains[T](column, raw), nil
+	case OpStartsWith:
+		return core.StartsWith[T](column, raw), nil
+	case OpEndsWith:
+		return core.EndsWith[T](column, raw), nil
+	case OpIn, OpNotIn, OpBetween:
+		parts := strings.Split(raw, ",")
+		values := make([]interface{}, len(parts))
+		for i, part := range parts {
+			value, err := convert(f, part)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		switch op {
+		case OpIn:
+			return core.In[T](column, values...), nil
+		case OpNotIn:
+			return core.NotIn[T](column, values...), nil
+		}
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%w: %s.between expects two comma-separated values", core.ErrInvalidInput, column)
+		}
+		return core.Between[T](column, values[0], values[1]), nil
+	}
+
+	compare, ok := map[string]func(string, interface{}) core.Specification[T]{
+		OpEq:  core.Equal[T],
+		OpNe:  core.NotEqual[T],
+		OpGt:  core.GreaterThan[T],
+		OpGte: core.GreaterThanEqual[T],
+		OpLt:  core.LessThan[T],
+		OpLte: core.LessThanEqual[T],
+	}[op]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown filter operator %q on %s", core.ErrInvalidInput, op, column)
+	}
+	value, err := convert(f, raw)
+	if err != nil {
+		return nil, err
+	}
+	return compare(column, value), nil
+}
+
+// Pageable parses the sort, page and size parameters. Pages are zero-based.
+func (b *Binder[T]) Pageable(values url.Values) (core.Pageable, error) {
+	pageable := core.Pageable{Size: b.defaultSize, Sort: b.defaultSort}
+
+	if raw := values.Get(PageParam); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 0 {
+			return core.Pageable{}, fmt.Errorf("%w: page must be a non-negative integer", core.ErrInvalidInput)
+		}
+		pageable.Page = page
+	}
+	if raw := values.Get(SizeParam); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size <= 0 || size > b.maxSize {
+			return core.Pageable{}, fmt.Errorf("%w: size must be between 1 and %d", core.ErrInvalidInput, b.maxSize)
+		}
+		pageable.Size = size
+	}
+	if raw := values.Get(SortParam); raw != "" {
+		s, err := b.parseSort(raw)
+		if err != nil {
+			return core.Pageable{}, err
+		}
+		pageable.Sort = s
+	}
+	return pageable, nil
+}
+
+// parseSort parses comma-separated fields, each prefixed with - for descending
+func (b *Binder[T]) parseSort(raw string) (core.Sort, error) {
+	var s core.Sort
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		direction := core.Asc
+		if strings.HasPrefix(part, "-") {
+			direction = core.Desc
+			part = part[1:]
+		} else {
+			part = strings.TrimPrefix(part, "+")
+		}
+		column, ok := b.sortable[part]
+		if !ok {
+			return core.Sort{}, fmt.Errorf("%w: %s is not sortable", core.ErrUnknownField, part)
+		}
+		s.Orders = append(s.Orders, core.Order{Field: column, Direction: direction}) //nolint:sqlinject // column comes from the sortable allowlist
+	}
+	return s, nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// convert parses a query parameter value into the Go type of the field
+func convert(f *core.Field, raw string) (interface{}, error) {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	invalid := func(err error) error {
+		return fmt.Errorf("%w: invalid %s value %q: %v", core.ErrInvalidInput, f.DBName, raw, err)
+	}
+
+	value := reflect.New(t)
+	if t == reflect.TypeOf(time.Time{}) {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if parsed, err = time.Parse(time.DateOnly, raw); err != nil {
+				return nil, invalid(err)
+			}
+		}
+		return parsed, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return nil, invalid(err)
+		}
+		return value.Elem().Interface(), nil
+	}
+
+	elem := value.Elem()
+	switch t.Kind() {
+	case reflect.String:
+		if f.Enum != nil && !f.Enum.Contains(raw) {
+			return nil, fmt.Errorf("%w: %s must be one of %s", core.ErrInvalidInput, f.DBName, strings.Join(f.Enum.Values, ", "))
+		}
+		elem.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		elem.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, invalid(err)
+		}
+		elem.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, invalid(err)
+		}
+		elem.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, invalid(err)
+		}
+		elem.SetFloat(parsed)
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be filtered from a query string", core.ErrInvalidInput, f.DBName)
+	}
+	return elem.Interface(), nil
+}
@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type testAccount struct {
+	ID        int64      `db:"id" jet:"primary_key,auto_increment"`
+	Email     string     `db:"email"`
+	Age       int        `db:"age"`
+	Status    string     `db:"status" jet:"enum:account_status(active,premium,closed)"`
+	Score     *float64   `db:"score"`
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	Password  string     `db:"password"`
+}
+
+func newTestBinder(t *testing.T, opts ...Option) *Binder[testAccount] {
+	t.Helper()
+	opts = append([]Option{
+		Filterable("email", "Age", "status", "score", "created_at", "deleted_at"),
+		Sortable("created_at", "age", "id"),
+	}, opts...)
+	binder, err := NewBinder[testAccount](opts...)
+	if err != nil {
+		t.Fatalf("Failed to create binder: %v", err)
+	}
+	return binder
+}
+
+func TestBinder_Bind(t *testing.T) {
+	binder := newTestBinder(t)
+
+	t.Run("should bind filters, sort and paging", func(t *testing.T) {
+		values, _ := url.ParseQuery("age.gte=18&status.in=active,premium&sort=-created_at,id&page=2&size=50")
+		spec, pageable, err := binder.Bind(values)
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+
+		sql, args := spec.ToSQL()
+		expected := "(age >= $1) AND (status IN ($2, $3))"
+		if sql != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, sql)
+		}
+		if args[0] != 18 || args[1] != "active" || args[2] != "premium" {
+			t.Errorf("Expected typed arguments, got %#v", args)
+		}
+
+		if pageable.Page != 2 || pageable.Size != 50 {
+			t.Errorf("Expected page 2 of size 50, got %+v", pageable)
+		}
+		orders := pageable.Sort.Orders
+		if len(orders) != 2 || orders[0] != (core.Order{Field: "created_at", Direction: core.Desc}) || orders[1] != (core.Order{Field: "id", Direction: core.Asc}) {
+			t.Errorf("Unexpected sort %+v", orders)
+		}
+	})
+
+	t.Run("should convert values to field types", func(t *testing.T) {
+		values := url.Values{
+			"created_at.between": {"2024-01-01,2024-02-01T00:00:00Z"},
+			"score.lt":           {"4.5"},
+			"deleted_at.is_null": {"true"},
+			"email.ends_with":    {"@example.com"},
+		}
+		spec, err := binder.Spec(values)
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+		sql, args := spec.ToSQL()
+		expected := "(((created_at BETWEEN $1 AND $2) AND (deleted_at IS NULL)) AND (email LIKE $3)) AND (score < $4)"
+		if sql != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, sql)
+		}
+		if args[0] != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) || args[2] != "%@example.com" || args[3] != 4.5 {
+			t.Errorf("Unexpected arguments %#v", args)
+		}
+	})
+
+	t.Run("should default paging and return no spec without filters", func(t *testing.T) {
+		binder := newTestBinder(t, PageSize(10, 25), DefaultSort("-id"))
+		spec, pageable, err := binder.Bind(url.Values{})
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+		if spec != nil {
+			t.Error("Expected a nil specification without filters")
+		}
+		if pageable.Size != 10 || pageable.Sort.Orders[0] != (core.Order{Field: "id", Direction: core.Desc}) {
+			t.Errorf("Unexpected defaults %+v", pageable)
+		}
+	})
+
+	t.Run("should reject parameters outside the whitelists", func(t *testing.T) {
+		tests := []struct {
+			query string
+			err   error
+		}{
+			{"password=secret", core.ErrUnknownField},
+			{"sort=email", core.ErrUnknownField},
+			{"age.regex=1", core.ErrInvalidInput},
+			{"age=old", core.ErrInvalidInput},
+			{"status=deleted", core.ErrInvalidInput},
+			{"created_at.between=2024-01-01", core.ErrInvalidInput},
+			{"size=1000", core.ErrInvalidInput},
+			{"page=-1", core.ErrInvalidInput},
+		}
+		for _, tt := range tests {
+			values, _ := url.ParseQuery(tt.query)
+			if _, _, err := binder.Bind(values); !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.query, tt.err, err)
+			}
+		}
+
+		lenient := newTestBinder(t, IgnoreUnknown())
+		if spec, _, err := lenient.Bind(url.Values{"q": {"search"}}); err != nil || spec != nil {
+			t.Errorf("Expected unknown parameters to be ignored, got %v, %v", spec, err)
+		}
+	})
+
+	t.Run("should reject unknown whitelisted fields", func(t *testing.T) {
+		if _, err := NewBinder[testAccount](Filterable("nickname")); !errors.Is(err, core.ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+}
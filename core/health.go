@@ -71,6 +71,7 @@ func (hc *HealthChecker) Check(ctx context.Context) HealthCheck {
 	check.Details["idle_connections"] = stats.IdleConns()
 	check.Details["total_connections"] = stats.TotalConns()
 	check.Details["constructing_connections"] = stats.ConstructingConns()
+	check.Details["labels"] = hc.db.Labels()
 
 	return check
 }
@@ -120,6 +121,7 @@ type HealthMetrics struct {
 	AcquireCount      int64
 	CanceledAcquireCount int64
 	EmptyAcquireCount int64
+	Labels            map[string]string // Pool labels, see Database.Labels
 }
 
 // GetMetrics returns current database metrics
@@ -139,6 +141,7 @@ func (hc *HealthChecker) GetMetrics() HealthMetrics {
 		AcquireCount:      stats.AcquireCount(),
 		CanceledAcquireCount: stats.CanceledAcquireCount(),
 		EmptyAcquireCount:    stats.EmptyAcquireCount(),
+		Labels:               hc.db.Labels(),
 	}
 }
 
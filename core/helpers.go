@@ -156,7 +156,9 @@ func FindAllMatching[T any, ID comparable](
 }
 
 // TimestampHelper provides timestamp utilities
-type TimestampHelper struct{}
+type TimestampHelper struct {
+	Clock Clock // Source of timestamps; nil uses SystemClock
+}
 
 // NewTimestampHelper creates a new timestamp helper
 func NewTimestampHelper() *TimestampHelper {
@@ -165,12 +167,20 @@ func NewTimestampHelper() *TimestampHelper {
 
 // SetCreatedAt sets created_at timestamp on entity
 func (th *TimestampHelper) SetCreatedAt(entity interface{}) error {
-	return th.setTimestamp(entity, "created_at", time.Now())
+	return th.setTimestamp(entity, "created_at", th.now())
 }
 
 // SetUpdatedAt sets updated_at timestamp on entity
 func (th *TimestampHelper) SetUpdatedAt(entity interface{}) error {
-	return th.setTimestamp(entity, "updated_at", time.Now())
+	return th.setTimestamp(entity, "updated_at", th.now())
+}
+
+// now returns the current time from the helper's clock
+func (th *TimestampHelper) now() time.Time {
+	if th.Clock == nil {
+		return SystemClock.Now()
+	}
+	return th.Clock.Now()
 }
 
 // setTimestamp sets a timestamp field on entity
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidIdentifier reports whether name is a plain SQL identifier, optionally
+// qualified by one table or schema name ("email", "users.email"). It is the
+// check for column names that come from user input, such as a sort parameter,
+// before they are written into SQL.
+func ValidIdentifier(name string) bool {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" || !isIdentStart(part[0]) {
+			return false
+		}
+		for i := 1; i < len(part); i++ {
+			if !isIdentStart(part[i]) && (part[i] < '0' || part[i] > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// QuoteIdentifier double-quotes each part of a possibly qualified name and
+// joins them with dots, so QuoteIdentifier("users", "email") is
+// "users"."email". Embedded double quotes are doubled.
+func QuoteIdentifier(parts ...string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, ".")
+}
+
+// ValidateSort checks the sort orders against SortableFields, when set, and
+// rejects field names that are not plain identifiers
+func (p Pageable) ValidateSort() error {
+	for _, order := range p.Sort.Orders {
+		if !ValidIdentifier(order.Field) {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, order.Field)
+		}
+		if p.SortableFields != nil && !p.sortable(order.Field) {
+			return fmt.Errorf("%w: %s is not sortable", ErrUnknownField, order.Field)
+		}
+	}
+	return nil
+}
+
+// sortable reports whether field is listed in SortableFields
+func (p Pageable) sortable(field string) bool {
+	for _, allowed := range p.SortableFields {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
+
+// orderByClause renders the pageable's sort as an ORDER BY clause, or "" when
+// unsorted. Each field must name a column of the entity; the column name from
+// the entity metadata is written, never the requested string.
+func (r *BaseRepository[T, ID]) orderByClause(pageable Pageable) (string, error) {
+	if len(pageable.Sort.Orders) == 0 {
+		return "", nil
+	}
+	if err := pageable.ValidateSort(); err != nil {
+		return "", err
+	}
+
+	orderClauses := make([]string, len(pageable.Sort.Orders))
+	for i, order := range pageable.Sort.Orders {
+		fieldMeta := r.lookupField(order.Field)
+		if fieldMeta == nil || fieldMeta.Ignored {
+			return "", fmt.Errorf("%w: %s", ErrUnknownField, order.Field)
+		}
+		direction := "ASC"
+		if order.Direction == Desc {
+			direction = "DESC"
+		}
+		orderClauses[i] = fieldMeta.DBName + " " + direction
+	}
+	return " ORDER BY " + strings.Join(orderClauses, ", "), nil
+}
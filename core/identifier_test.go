@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidIdentifier(t *testing.T) {
+	valid := []string{"email", "_id", "users.email", "CreatedAt", "col1"}
+	for _, name := range valid {
+		if !ValidIdentifier(name) {
+			t.Errorf("Expected %q to be valid", name)
+		}
+	}
+
+	invalid := []string{"", "1col", "a.b.c", "users.", "email; DROP TABLE users", "email DESC", `"email"`, "lower(email)"}
+	for _, name := range invalid {
+		if ValidIdentifier(name) {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+
+	if got := QuoteIdentifier("users", `we"ird`); got != `"users"."we""ird"` {
+		t.Errorf("Unexpected quoting %s", got)
+	}
+}
+
+func TestBaseRepository_OrderByClause(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should write the column names from the entity", func(t *testing.T) {
+		clause, err := repo.orderByClause(PageRequest(0, 10, Order{Field: "CreatedAt", Direction: Desc}, Order{Field: "email"}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if clause != " ORDER BY created_at DESC, email ASC" {
+			t.Errorf("Unexpected clause '%s'", clause)
+		}
+	})
+
+	t.Run("should reject injected and unknown fields", func(t *testing.T) {
+		_, err := repo.orderByClause(PageRequest(0, 10, Order{Field: "email; DROP TABLE test_user"}))
+		if !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Expected ErrInvalidIdentifier, got %v", err)
+		}
+		_, err = repo.orderByClause(PageRequest(0, 10, Order{Field: "password"}))
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+
+	t.Run("should only allow sortable fields", func(t *testing.T) {
+		pageable := PageRequest(0, 10, Order{Field: "age"}).WithSortable("email", "created_at")
+		if _, err := repo.orderByClause(pageable); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+		if next := pageable.Next(); len(next.SortableFields) != 2 {
+			t.Errorf("Expected sortable fields to carry over, got %v", next.SortableFields)
+		}
+
+		pageable.Sort.Orders[0].Field = "email"
+		if _, err := repo.orderByClause(pageable); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Discriminator maps an entity type onto the rows of a table it shares with
+// other types (single-table inheritance). It is declared on an embedded base
+// struct, whose table the entity then uses:
+//
+//	type Vehicle struct {
+//		ID   int64  `db:"id" jet:"primary_key,auto_increment"`
+//		Kind string `db:"kind"`
+//	}
+//
+//	type Car struct {
+//		Vehicle `jet:"discriminator:kind"`     // rows of vehicle with kind = 'car'
+//		Doors   int `db:"doors"`
+//	}
+//
+//	type Truck struct {
+//		Vehicle `jet:"discriminator:kind=lorry"` // kind = 'lorry'
+//		Payload int `db:"payload"`
+//	}
+//
+// The value defaults to the snake_case name of the entity type.
+type Discriminator struct {
+	Table  string // Shared table, named after the base struct
+	Column string // Column holding the type of each row
+	Value  string // Column value of this entity's rows
+}
+
+// parseDiscriminator reads the discriminator tag of an embedded struct of
+// entity type t, or returns nil when it has none
+func parseDiscriminator(t reflect.Type, field reflect.StructField) *Discriminator {
+	for _, tag := range parseTag(field.Tag.Get("jet")) {
+		if tag.Key != "discriminator" {
+			continue
+		}
+		column, value, _ := strings.Cut(tag.Value, "=")
+		if value == "" {
+			value = toSnakeCase(t.Name())
+		}
+		return &Discriminator{Table: toSnakeCase(field.Type.Name()), Column: column, Value: value}
+	}
+	return nil
+}
+
+// validateDiscriminator checks that the discriminator column is a string
+// field of the entity
+func validateDiscriminator(entity *Entity) error {
+	d := entity.Discriminator
+	if d == nil {
+		return nil
+	}
+	if d.Column == "" {
+		return fmt.Errorf("%w: %s must be tagged discriminator:column or discriminator:column=value", ErrInvalidDiscriminator, entity.Type)
+	}
+	f := entity.discriminatorField()
+	if f == nil {
+		return fmt.Errorf("%w: %s has no %s column", ErrInvalidDiscriminator, entity.Type, d.Column)
+	}
+	if f.Type.Kind() != reflect.String {
+		return fmt.Errorf("%w: %s must be a string type, got %s", ErrInvalidDiscriminator, f.Name, f.Type)
+	}
+	return nil
+}
+
+// discriminatorField returns the field of the discriminator column, or nil
+func (e *Entity) discriminatorField() *Field {
+	if e.Discriminator == nil {
+		return nil
+	}
+	for i := range e.Fields {
+		if !e.Fields[i].Ignored && e.Fields[i].DBName == e.Discriminator.Column {
+			return &e.Fields[i]
+		}
+	}
+	return nil
+}
+
+// columns lists the entity's stored columns
+func (e *Entity) columns() []string {
+	columns := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if !f.Ignored {
+			columns = append(columns, f.DBName)
+		}
+	}
+	return columns
+}
+
+// isDiscriminatorField reports whether fieldMeta is the discriminator column
+func (r *BaseRepository[T, ID]) isDiscriminatorField(fieldMeta Field) bool {
+	return r.entity.Discriminator != nil && fieldMeta.DBName == r.entity.Discriminator.Column
+}
+
+// discriminated restricts a WHERE clause to the rows of the entity's type
+// when its table is shared
+func (r *BaseRepository[T, ID]) discriminated(where string) string {
+	d := r.entity.Discriminator
+	if d == nil {
+		return where
+	}
+	own := d.Column + " = " + quoteString(d.Value)
+	if where == "" {
+		return own
+	}
+	return fmt.Sprintf("(%s) AND %s", where, own)
+}
+
+// selectList returns the columns to read. A shared table holds the columns
+// of other types too, so they are listed rather than read with *.
+func (r *BaseRepository[T, ID]) selectList() string {
+	if r.entity.Discriminator == nil {
+		return "*"
+	}
+	return strings.Join(r.entity.columns(), ", ")
+}
+
+// PolymorphicRepository reads the rows of a table shared through
+// single-table inheritance, returning each row as the entity type its
+// discriminator names. T is usually an interface the entity types implement:
+//
+//	vehicles, err := core.NewPolymorphicRepository[Wheeled](db, &Car{}, &Truck{})
+//	all, err := vehicles.FindAll(ctx) // *Car and *Truck values
+type PolymorphicRepository[T any] struct {
+	db       *Database
+	table    string
+	column   string
+	pkField  string
+	columns  []string
+	values   []string // Discriminator values, in registration order
+	subtypes map[string]*Entity
+}
+
+// NewPolymorphicRepository creates a repository over the entity types of
+// subtypes, given as pointers. They must share one table and discriminator
+// column and have distinct discriminator values.
+func NewPolymorphicRepository[T any](db *Database, subtypes ...T) (*PolymorphicRepository[T], error) {
+	if len(subtypes) == 0 {
+		return nil, fmt.Errorf("%w: no entity types given", ErrInvalidDiscriminator)
+	}
+
+	r := &PolymorphicRepository[T]{db: db, subtypes: make(map[string]*Entity, len(subtypes))}
+	seen := make(map[string]bool)
+	for _, subtype := range subtypes {
+		t := reflect.TypeOf(subtype)
+		if t == nil || t.Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("%w: entity types must be given as pointers, got %T", ErrInvalidEntity, subtype)
+		}
+		entity, err := entityMetadataFor(t.Elem())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Elem(), err)
+		}
+
+		d := entity.Discriminator
+		switch {
+		case d == nil:
+			return nil, fmt.Errorf("%w: %s has no discriminator", ErrInvalidDiscriminator, t.Elem())
+		case r.table == "":
+			r.table, r.column, r.pkField = d.Table, d.Column, entity.PrimaryKey.DBName
+		case d.Table != r.table || d.Column != r.column:
+			return nil, fmt.Errorf("%w: %s is discriminated by %s.%s, not %s.%s",
+				ErrInvalidDiscriminator, t.Elem(), d.Table, d.Column, r.table, r.column)
+		}
+		if other, ok := r.subtypes[d.Value]; ok {
+			return nil, fmt.Errorf("%w: %s and %s share the value %q", ErrInvalidDiscriminator, other.Type, t.Elem(), d.Value)
+		}
+		r.subtypes[d.Value] = entity
+		r.values = append(r.values, d.Value)
+
+		for _, column := range entity.columns() {
+			if !seen[column] {
+				seen[column] = true
+				r.columns = append(r.columns, column)
+			}
+		}
+	}
+	return r, nil
+}
+
+// FindByID finds the row with the given primary key
+func (r *PolymorphicRepository[T]) FindByID(ctx context.Context, id interface{}) (T, error) {
+	var zero T
+	results, err := r.query(ctx, r.pkField+" = $1", id)
+	if err != nil {
+		return zero, err
+	}
+	if len(results) == 0 {
+		return zero, ErrNotFound
+	}
+	return results[0], nil
+}
+
+// FindAll finds the rows of every registered entity type
+func (r *PolymorphicRepository[T]) FindAll(ctx context.Context) ([]T, error) {
+	return r.query(ctx, "")
+}
+
+// query selects the rows of the registered types matching where
+func (r *PolymorphicRepository[T]) query(ctx context.Context, where string, args ...interface{}) ([]T, error) {
+	values := make([]string, len(r.values))
+	for i, value := range r.values {
+		values[i] = quoteString(value)
+	}
+	condition := fmt.Sprintf("%s IN (%s)", r.column, strings.Join(values, ", "))
+	if where != "" {
+		condition = fmt.Sprintf("(%s) AND %s", where, condition)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s",
+		strings.Join(r.columns, ", "), r.table, condition, r.pkField)
+	if r.db.config.LogSQL {
+		r.db.logger.Debug("executing query", "query", query, "args", args)
+	}
+
+	rows, err := r.db.readConn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanRows(rows)
+}
+
+// scanRows scans each row into the entity type its discriminator names.
+// Columns are matched by name; fields whose column was not read keep their
+// zero value.
+func (r *PolymorphicRepository[T]) scanRows(rows pgx.Rows) ([]T, error) {
+	index := columnIndex(rows)
+	discriminator, selected := index[r.column]
+
+	results := make([]T, 0)
+	for rows.Next() {
+		if !selected {
+			return nil, fmt.Errorf("%w: column %s was not selected", ErrInvalidDiscriminator, r.column)
+		}
+
+		// Read the discriminator alone first to pick the type to scan into
+		dest := make([]interface{}, len(rows.FieldDescriptions()))
+		var value string
+		dest[discriminator] = &value
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		entity, ok := r.subtypes[value]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s = %q", ErrUnknownDiscriminator, r.column, value)
+		}
+
+		result := reflect.New(entity.Type)
+		if err := scanByName(rows, index, namedFields{fields: entity.Fields, v: result.Elem()}); err != nil {
+			return nil, err
+		}
+		results = append(results, result.Interface().(T))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
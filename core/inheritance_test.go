@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testVehicle struct {
+	ID   int64  `db:"id" jet:"primary_key,auto_increment"`
+	Kind string `db:"kind"`
+	Name string `db:"name"`
+}
+
+type testCar struct {
+	testVehicle `jet:"discriminator:kind"`
+	Doors       int `db:"doors"`
+}
+
+type testTruck struct {
+	testVehicle `jet:"discriminator:kind=lorry"`
+	Payload     int `db:"payload"`
+}
+
+type testBoat struct {
+	testVehicle `jet:"discriminator:hull"`
+}
+
+type wheeled interface{ wheels() int }
+
+func (*testCar) wheels() int   { return 4 }
+func (*testTruck) wheels() int { return 6 }
+
+func TestEntityMetadata_Discriminator(t *testing.T) {
+	car, err := RegisterEntity[testCar]()
+	if err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	if car.TableName != "test_vehicle" {
+		t.Errorf("Expected the base struct's table, got %s", car.TableName)
+	}
+	if want := (Discriminator{Table: "test_vehicle", Column: "kind", Value: "test_car"}); *car.Discriminator != want {
+		t.Errorf("Expected %+v, got %+v", want, *car.Discriminator)
+	}
+
+	truck, err := RegisterEntity[testTruck]()
+	if err != nil {
+		t.Fatalf("Failed to register entity: %v", err)
+	}
+	if truck.Discriminator.Value != "lorry" {
+		t.Errorf("Expected the tagged value, got %s", truck.Discriminator.Value)
+	}
+
+	if _, err := RegisterEntity[testBoat](); !errors.Is(err, ErrInvalidDiscriminator) {
+		t.Errorf("Expected ErrInvalidDiscriminator for a missing column, got %v", err)
+	}
+}
+
+func TestBaseRepository_Discriminator(t *testing.T) {
+	repo, err := NewBaseRepository[testCar, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+
+	car := &testCar{testVehicle: testVehicle{Kind: "ignored", Name: "Beetle"}, Doors: 2}
+	_, _ = repo.Save(ctx, car)
+	_, _ = repo.FindAll(ctx)
+	_ = repo.DeleteByID(ctx, 7)
+	car.ID = 7
+	_, _ = repo.Save(ctx, car)
+
+	statements := capture.Statements()
+	want := []string{
+		"INSERT INTO test_vehicle (kind, name, doors) VALUES ($1, $2, $3) RETURNING id, kind, name, doors",
+		"SELECT id, kind, name, doors FROM test_vehicle WHERE kind = 'test_car'",
+		"DELETE FROM test_vehicle WHERE (id = $1) AND kind = 'test_car'",
+		"UPDATE test_vehicle SET name = $1, doors = $2 WHERE (id = $3) AND kind = 'test_car' RETURNING id, kind, name, doors",
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("Expected %d statements, got %d", len(want), len(statements))
+	}
+	for i := range want {
+		if statements[i].SQL != want[i] {
+			t.Errorf("Expected %q, got %q", want[i], statements[i].SQL)
+		}
+	}
+	if statements[0].Args[0] != "test_car" {
+		t.Errorf("Expected the discriminator value to be inserted, got %v", statements[0].Args[0])
+	}
+}
+
+func TestPolymorphicRepository(t *testing.T) {
+	db := &Database{}
+
+	t.Run("should select the rows of every type", func(t *testing.T) {
+		repo, err := NewPolymorphicRepository[wheeled](db, &testCar{}, &testTruck{})
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		ctx, capture := db.DryRun(context.Background())
+		if _, err := repo.FindByID(ctx, 1); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+
+		want := "SELECT id, kind, name, doors, payload FROM test_vehicle WHERE (id = $1) AND kind IN ('test_car', 'lorry') ORDER BY id"
+		if statements := capture.Statements(); len(statements) != 1 || statements[0].SQL != want {
+			t.Errorf("Expected %q, got %v", want, statements)
+		}
+	})
+
+	t.Run("should reject mismatched types", func(t *testing.T) {
+		if _, err := NewPolymorphicRepository[any](db, &testCar{}, &testCar{}); !errors.Is(err, ErrInvalidDiscriminator) {
+			t.Errorf("Expected ErrInvalidDiscriminator for a repeated value, got %v", err)
+		}
+		if _, err := NewPolymorphicRepository[any](db, &testCar{}, &TestUser{}); !errors.Is(err, ErrInvalidDiscriminator) {
+			t.Errorf("Expected ErrInvalidDiscriminator for an entity without one, got %v", err)
+		}
+		if _, err := NewPolymorphicRepository[any](db, testCar{}); !errors.Is(err, ErrInvalidEntity) {
+			t.Errorf("Expected ErrInvalidEntity for a non-pointer, got %v", err)
+		}
+	})
+}
+
+func TestDiscriminator_ArchiveAndSubqueries(t *testing.T) {
+	t.Run("should archive only the entity's rows", func(t *testing.T) {
+		repo, err := NewBaseRepository[testCar, int64](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		archiver := NewArchiver(repo, ArchiveOptions{BatchSize: 100})
+		expected := "id IN (SELECT id FROM test_vehicle WHERE (doors = $1) AND kind = 'test_car' ORDER BY id LIMIT 100 FOR UPDATE SKIP LOCKED)"
+		if condition := archiver.batchCondition("doors = $1"); condition != expected {
+			t.Errorf("Expected %q, got %q", expected, condition)
+		}
+		if statement := archiver.moveStatement("doors = $1"); !contains(statement, expected) {
+			t.Errorf("Expected the move to select %q, got %q", expected, statement)
+		}
+	})
+
+	t.Run("should restrict subqueries to the inner entity's rows", func(t *testing.T) {
+		where, _ := InSubquery[TestUser]("id", "id", Equal[testCar]("doors", 2)).ToSQL()
+		expected := "id IN (SELECT id FROM test_vehicle WHERE (doors = $1) AND test_vehicle.kind = 'test_car')"
+		if where != expected {
+			t.Errorf("Expected %q, got %q", expected, where)
+		}
+
+		where, _ = ExistsIn[testTruck, testCar]("id", nil).ToSQL()
+		expected = "EXISTS (SELECT 1 FROM test_vehicle AS test_vehicle_sub WHERE test_vehicle_sub.id = test_vehicle.id AND test_vehicle_sub.kind = 'test_car')"
+		if where != expected {
+			t.Errorf("Expected %q, got %q", expected, where)
+		}
+	})
+}
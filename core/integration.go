@@ -10,10 +10,60 @@ import (
 
 // Integration utilities for combining features
 
+// DeleteReturner is implemented by repositories that can return the deleted row
+// (see BaseRepository.DeleteByIDReturning)
+type DeleteReturner[T any, ID comparable] interface {
+	DeleteByIDReturning(ctx context.Context, id ID) (*T, error)
+}
+
+// deleteWithHooks deletes the entity with the given ID and runs the delete hooks
+// with a snapshot of it. The snapshot is the entity passed by the caller or, when
+// prefetch is set, the entity loaded before deletion; without either, before-delete
+// hooks see an entity with only the ID set. After-delete hooks receive the deleted
+// row when the repository implements DeleteReturner, so soft deletes include the
+// deleted-at value. Returns ErrNotFound if no row was deleted.
+func deleteWithHooks[T any, ID comparable](ctx context.Context, repo Repository[T, ID], h *hooks.Hooks[T], id ID, entity *T, prefetch bool) error {
+	if h == nil {
+		return repo.DeleteByID(ctx, id)
+	}
+
+	if entity == nil {
+		if prefetch {
+			found, err := repo.FindByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			entity = found
+		} else {
+			entity = new(T)
+			if err := SetID(entity, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := h.ExecuteBeforeDelete(ctx, entity); err != nil {
+		return err
+	}
+
+	if returner, ok := repo.(DeleteReturner[T, ID]); ok {
+		deleted, err := returner.DeleteByIDReturning(ctx, id)
+		if err != nil {
+			return err
+		}
+		entity = deleted
+	} else if err := repo.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+
+	return h.ExecuteAfterDelete(ctx, entity)
+}
+
 // CachedRepositoryWithHooks combines caching and hooks
 type CachedRepositoryWithHooks[T any, ID comparable] struct {
 	*CachedRepository[T, ID]
-	hooks *hooks.Hooks[T]
+	hooks              *hooks.Hooks[T]
+	skipDeletePrefetch bool
 }
 
 // NewCachedRepositoryWithHooks creates a repository with caching and hooks
@@ -70,6 +120,39 @@ func (cr *CachedRepositoryWithHooks[T, ID]) Save(ctx context.Context, entity *T)
 	return saved, nil
 }
 
+// WithoutDeletePrefetch returns a repository that skips loading the entity
+// before DeleteByID. Before-delete hooks then only see the ID; after-delete
+// hooks still receive the deleted row when the repository can return it.
+func (cr *CachedRepositoryWithHooks[T, ID]) WithoutDeletePrefetch() *CachedRepositoryWithHooks[T, ID] {
+	repo := *cr
+	repo.skipDeletePrefetch = true
+	return &repo
+}
+
+// Delete implements Repository.Delete with caching and hooks
+func (cr *CachedRepositoryWithHooks[T, ID]) Delete(ctx context.Context, entity *T) error {
+	id, err := ExtractID[T, ID](entity)
+	if err != nil {
+		return err
+	}
+	if err := deleteWithHooks(ctx, cr.repo, cr.hooks, id, entity, false); err != nil {
+		return err
+	}
+
+	cr.cache.Clear(ctx)
+	return nil
+}
+
+// DeleteByID implements Repository.DeleteByID with caching and hooks
+func (cr *CachedRepositoryWithHooks[T, ID]) DeleteByID(ctx context.Context, id ID) error {
+	if err := deleteWithHooks(ctx, cr.repo, cr.hooks, id, nil, !cr.skipDeletePrefetch); err != nil {
+		return err
+	}
+
+	cr.cache.Clear(ctx)
+	return nil
+}
+
 // RepositoryWithValidation wraps a repository with validation
 type RepositoryWithValidation[T any, ID comparable] struct {
 	repo      Repository[T, ID]
@@ -145,6 +228,8 @@ type FullFeaturedRepository[T any, ID comparable] struct {
 	keyGen        *CacheKeyGenerator[T, ID]
 	ttl           time.Duration
 	entityType    string
+
+	skipDeletePrefetch bool
 }
 
 // NewFullFeaturedRepository creates a repository with all features
@@ -265,9 +350,31 @@ func (fr *FullFeaturedRepository[T, ID]) Update(ctx context.Context, entity *T)
 	return fr.Save(ctx, entity) // Save handles update
 }
 
+// WithoutDeletePrefetch returns a repository that skips loading the entity
+// before DeleteByID. Before-delete hooks then only see the ID; after-delete
+// hooks still receive the deleted row when the repository can return it.
+func (fr *FullFeaturedRepository[T, ID]) WithoutDeletePrefetch() *FullFeaturedRepository[T, ID] {
+	repo := *fr
+	repo.skipDeletePrefetch = true
+	return &repo
+}
+
+// Delete implements Repository.Delete, passing the entity through to delete hooks
+func (fr *FullFeaturedRepository[T, ID]) Delete(ctx context.Context, entity *T) error {
+	id, err := ExtractID[T, ID](entity)
+	if err != nil {
+		return err
+	}
+	return fr.deleteByID(ctx, id, entity)
+}
+
 // DeleteByID implements Repository.DeleteByID
 func (fr *FullFeaturedRepository[T, ID]) DeleteByID(ctx context.Context, id ID) error {
-	err := fr.repo.DeleteByID(ctx, id)
+	return fr.deleteByID(ctx, id, nil)
+}
+
+func (fr *FullFeaturedRepository[T, ID]) deleteByID(ctx context.Context, id ID, entity *T) error {
+	err := deleteWithHooks(ctx, fr.repo, fr.hooks, id, entity, !fr.skipDeletePrefetch)
 	if err != nil {
 		return err
 	}
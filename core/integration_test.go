@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/satishbabariya/jetorm/hooks"
 )
 
 // Integration tests for feature combinations
@@ -274,3 +276,119 @@ func TestGroupEntities(t *testing.T) {
 	}
 }
 
+
+// deleteRepo is a Repository stub recording deletes; unimplemented methods panic
+type deleteRepo struct {
+	Repository[TestUser, int64]
+	rows    map[int64]*TestUser
+	fetches int
+}
+
+func (d *deleteRepo) FindByID(ctx context.Context, id int64) (*TestUser, error) {
+	d.fetches++
+	if user, ok := d.rows[id]; ok {
+		return user, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (d *deleteRepo) DeleteByID(ctx context.Context, id int64) error {
+	delete(d.rows, id)
+	return nil
+}
+
+// returningDeleteRepo also returns the deleted row, like BaseRepository
+type returningDeleteRepo struct {
+	*deleteRepo
+}
+
+func (d returningDeleteRepo) DeleteByIDReturning(ctx context.Context, id int64) (*TestUser, error) {
+	user, ok := d.rows[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(d.rows, id)
+	deleted := *user
+	deleted.UpdatedAt = time.Unix(1, 0)
+	return &deleted, nil
+}
+
+func TestDeleteWithHooks(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func() *deleteRepo {
+		return &deleteRepo{rows: map[int64]*TestUser{7: {ID: 7, Email: "a@example.com"}}}
+	}
+
+	var before, after []*TestUser
+	h := hooks.NewHooks[TestUser]()
+	h.RegisterBeforeDelete(func(ctx context.Context, user *TestUser) error {
+		before = append(before, user)
+		return nil
+	})
+	h.RegisterAfterDelete(func(ctx context.Context, user *TestUser) error {
+		after = append(after, user)
+		return nil
+	})
+	reset := func() { before, after = nil, nil }
+
+	t.Run("should prefetch entity for hooks", func(t *testing.T) {
+		reset()
+		repo := newRepo()
+		if err := deleteWithHooks[TestUser, int64](ctx, repo, h, 7, nil, true); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if repo.fetches != 1 || after[0].Email != "a@example.com" || before[0] != after[0] {
+			t.Errorf("Expected prefetched snapshot in both hooks, got %+v", after[0])
+		}
+	})
+
+	t.Run("should pass through entity without fetching", func(t *testing.T) {
+		reset()
+		repo := newRepo()
+		entity := &TestUser{ID: 7, Email: "passed@example.com"}
+		if err := deleteWithHooks[TestUser, int64](ctx, repo, h, 7, entity, true); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if repo.fetches != 0 || after[0] != entity {
+			t.Errorf("Expected passed entity without fetch, got %d fetches", repo.fetches)
+		}
+	})
+
+	t.Run("should skip prefetch and use returned row", func(t *testing.T) {
+		reset()
+		repo := newRepo()
+		if err := deleteWithHooks[TestUser, int64](ctx, returningDeleteRepo{repo}, h, 7, nil, false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if repo.fetches != 0 {
+			t.Errorf("Expected no prefetch, got %d", repo.fetches)
+		}
+		if before[0].ID != 7 || before[0].Email != "" {
+			t.Errorf("Expected ID-only entity before delete, got %+v", before[0])
+		}
+		if after[0].Email != "a@example.com" || !after[0].UpdatedAt.Equal(time.Unix(1, 0)) {
+			t.Errorf("Expected deleted row after delete, got %+v", after[0])
+		}
+	})
+
+	t.Run("should not run hooks for missing entity", func(t *testing.T) {
+		reset()
+		if err := deleteWithHooks[TestUser, int64](ctx, newRepo(), h, 8, nil, true); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if len(before)+len(after) != 0 {
+			t.Errorf("Expected no hooks to run")
+		}
+	})
+
+	t.Run("should leave the shared repository prefetching", func(t *testing.T) {
+		cached := &CachedRepositoryWithHooks[TestUser, int64]{hooks: h}
+		if skipping := cached.WithoutDeletePrefetch(); !skipping.skipDeletePrefetch || cached.skipDeletePrefetch {
+			t.Error("Expected only the returned repository to skip the prefetch")
+		}
+		full := &FullFeaturedRepository[TestUser, int64]{hooks: h}
+		if skipping := full.WithoutDeletePrefetch(); !skipping.skipDeletePrefetch || full.skipDeletePrefetch {
+			t.Error("Expected only the returned repository to skip the prefetch")
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// SchemaInfo is the structure of a database schema as read by Introspect
+type SchemaInfo struct {
+	Name   string
+	Tables []TableInfo // Sorted by name
+}
+
+// TableInfo is a base table of a SchemaInfo
+type TableInfo struct {
+	Name        string
+	Columns     []ColumnInfo // In ordinal position order
+	PrimaryKey  []string     // Primary key columns in key order, nil if none
+	Indexes     []IndexInfo  // Sorted by name, including constraint indexes
+	ForeignKeys []ForeignKeyInfo
+}
+
+// ColumnInfo is a column of a TableInfo
+type ColumnInfo struct {
+	Name     string
+	Type     string // As format_type prints it, e.g. "character varying(255)"
+	Nullable bool
+	Default  string // Default expression, "" if none
+}
+
+// IndexInfo is an index of a TableInfo
+type IndexInfo struct {
+	Name       string
+	Columns    []string // Key columns; expression keys are empty strings
+	Unique     bool
+	Primary    bool
+	Valid      bool   // False for a failed or in-progress concurrent build
+	Method     string // Access method, e.g. btree or gin
+	Predicate  string // WHERE clause of a partial index, "" if none
+	Definition string // CREATE INDEX statement
+}
+
+// ForeignKeyInfo is a foreign key constraint of a TableInfo
+type ForeignKeyInfo struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string // cascade, set_null, set_default, restrict or no_action
+	OnUpdate   string
+}
+
+// Table returns the named table, or nil
+func (s *SchemaInfo) Table(name string) *TableInfo {
+	i := sort.Search(len(s.Tables), func(i int) bool { return s.Tables[i].Name >= name })
+	if i < len(s.Tables) && s.Tables[i].Name == name {
+		return &s.Tables[i]
+	}
+	return nil
+}
+
+// Column returns the named column, or nil
+func (t *TableInfo) Column(name string) *ColumnInfo {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Index returns the named index, or nil
+func (t *TableInfo) Index(name string) *IndexInfo {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			return &t.Indexes[i]
+		}
+	}
+	return nil
+}
+
+// Introspect reads the tables of the connection's current schema with their
+// columns, indexes and foreign keys. The migration diff, doctor and drift
+// checks build on it; other tools can use it to share jetorm's view of the
+// database.
+func (db *Database) Introspect(ctx context.Context) (*SchemaInfo, error) {
+	var schema string
+	if err := db.pool.QueryRow(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return nil, fmt.Errorf("failed to read current schema: %w", err)
+	}
+	return db.IntrospectSchema(ctx, schema)
+}
+
+// IntrospectSchema is Introspect for a named schema
+func (db *Database) IntrospectSchema(ctx context.Context, schema string) (*SchemaInfo, error) {
+	info := &SchemaInfo{Name: schema}
+	tables := make(map[string]int) // Table name -> index in info.Tables
+	table := func(name string) *TableInfo {
+		if i, ok := tables[name]; ok {
+			return &info.Tables[i]
+		}
+		return nil
+	}
+
+	rows, err := db.pool.Query(ctx, `
+		SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		LEFT JOIN pg_attrdef d ON d.adrelid = c.oid AND d.adnum = a.attnum
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname, a.attnum`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var col ColumnInfo
+		if err := rows.Scan(&name, &col.Name, &col.Type, &col.Nullable, &col.Default); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read columns: %w", err)
+		}
+		if _, ok := tables[name]; !ok {
+			tables[name] = len(info.Tables)
+			info.Tables = append(info.Tables, TableInfo{Name: name})
+		}
+		t := table(name)
+		t.Columns = append(t.Columns, col)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	rows, err = db.pool.Query(ctx, `
+		SELECT t.relname, i.relname, ix.indisunique, ix.indisprimary, ix.indisvalid, am.amname,
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid), ''), pg_get_indexdef(ix.indexrelid),
+			ARRAY(SELECT COALESCE(a.attname, '')
+				FROM unnest(ix.indkey[0:ix.indnkeyatts - 1]) WITH ORDINALITY AS k(attnum, ord)
+				LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+				ORDER BY k.ord)
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = i.relam
+		WHERE n.nspname = $1
+		ORDER BY t.relname, i.relname`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var idx IndexInfo
+		if err := rows.Scan(&name, &idx.Name, &idx.Unique, &idx.Primary, &idx.Valid, &idx.Method,
+			&idx.Predicate, &idx.Definition, &idx.Columns); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read indexes: %w", err)
+		}
+		if t := table(name); t != nil {
+			t.Indexes = append(t.Indexes, idx)
+			if idx.Primary {
+				t.PrimaryKey = idx.Columns
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	rows, err = db.pool.Query(ctx, `
+		SELECT cl.relname, c.conname, rt.relname, c.confdeltype::text, c.confupdtype::text,
+			ARRAY(SELECT a.attname FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum ORDER BY k.ord),
+			ARRAY(SELECT a.attname FROM unnest(c.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum ORDER BY k.ord)
+		FROM pg_constraint c
+		JOIN pg_class cl ON cl.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = cl.relnamespace
+		JOIN pg_class rt ON rt.oid = c.confrelid
+		WHERE n.nspname = $1 AND c.contype = 'f'
+		ORDER BY cl.relname, c.conname`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	for rows.Next() {
+		var name, onDelete, onUpdate string
+		var fk ForeignKeyInfo
+		if err := rows.Scan(&name, &fk.Name, &fk.RefTable, &onDelete, &onUpdate, &fk.Columns, &fk.RefColumns); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+		}
+		fk.OnDelete, fk.OnUpdate = foreignKeyAction(onDelete), foreignKeyAction(onUpdate)
+		if t := table(name); t != nil {
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+
+	return info, nil
+}
+
+// foreignKeyAction maps pg_constraint's action codes to the values of the
+// on_delete and on_update tags
+func foreignKeyAction(code string) string {
+	switch code {
+	case "c":
+		return "cascade"
+	case "n":
+		return "set_null"
+	case "d":
+		return "set_default"
+	case "r":
+		return "restrict"
+	}
+	return "no_action"
+}
+
+// Drift compares entities with the schema and describes each table or
+// column an entity maps that the schema lacks, each column whose nullability
+// differs from its not_null tag, and each NOT NULL column without a default
+// that inserts of the entity would leave out. The result is empty when the
+// schema fits the entities.
+func (s *SchemaInfo) Drift(entities ...*Entity) []string {
+	var problems []string
+	for _, entity := range entities {
+		t := s.Table(entity.TableName)
+		if t == nil {
+			problems = append(problems, fmt.Sprintf("table %s is missing", entity.TableName))
+			continue
+		}
+		mapped := make(map[string]bool, len(entity.Fields))
+		for _, field := range entity.Fields {
+			if field.Ignored {
+				continue
+			}
+			mapped[field.DBName] = true
+			col := t.Column(field.DBName)
+			switch {
+			case col == nil:
+				problems = append(problems, fmt.Sprintf("column %s.%s is missing", t.Name, field.DBName))
+			case field.NotNull && col.Nullable && !field.PrimaryKey:
+				problems = append(problems, fmt.Sprintf("column %s.%s is nullable, %s.%s is not_null",
+					t.Name, col.Name, entity.Type.Name(), field.Name))
+			}
+		}
+		for _, col := range t.Columns {
+			if !mapped[col.Name] && !col.Nullable && col.Default == "" {
+				problems = append(problems, fmt.Sprintf("column %s.%s is NOT NULL without a default and not mapped by %s",
+					t.Name, col.Name, entity.Type.Name()))
+			}
+		}
+	}
+	return problems
+}
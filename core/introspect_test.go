@@ -0,0 +1,59 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaInfoLookup(t *testing.T) {
+	schema := &SchemaInfo{Name: "public", Tables: []TableInfo{
+		{Name: "orders", Indexes: []IndexInfo{{Name: "orders_pkey", Primary: true}}},
+		{Name: "users", Columns: []ColumnInfo{{Name: "id"}, {Name: "email"}}},
+	}}
+
+	if schema.Table("users") == nil || schema.Table("orders") == nil {
+		t.Fatal("Expected both tables to be found")
+	}
+	if schema.Table("missing") != nil {
+		t.Error("Expected no table for an unknown name")
+	}
+	if col := schema.Table("users").Column("email"); col == nil || col.Name != "email" {
+		t.Errorf("Expected the email column, got %+v", col)
+	}
+	if idx := schema.Table("orders").Index("orders_pkey"); idx == nil || !idx.Primary {
+		t.Errorf("Expected the primary key index, got %+v", idx)
+	}
+}
+
+func TestSchemaInfoDrift(t *testing.T) {
+	entity, err := EntityMetadata(TestUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var columns []ColumnInfo
+	for _, field := range entity.Fields {
+		if !field.Ignored {
+			columns = append(columns, ColumnInfo{Name: field.DBName, Nullable: !field.NotNull})
+		}
+	}
+	schema := &SchemaInfo{Tables: []TableInfo{{Name: entity.TableName, Columns: columns}}}
+	if problems := schema.Drift(entity); len(problems) != 0 {
+		t.Errorf("Expected no drift, got %v", problems)
+	}
+
+	table := &schema.Tables[0]
+	missing := table.Columns[len(table.Columns)-1].Name
+	table.Columns = append(table.Columns[:len(table.Columns)-1], ColumnInfo{Name: "tenant_id"})
+	want := []string{
+		"column " + entity.TableName + "." + missing + " is missing",
+		"column " + entity.TableName + ".tenant_id is NOT NULL without a default and not mapped by TestUser",
+	}
+	if problems := schema.Drift(entity); !reflect.DeepEqual(problems, want) {
+		t.Errorf("Expected %v, got %v", want, problems)
+	}
+
+	if problems := (&SchemaInfo{}).Drift(entity); len(problems) != 1 {
+		t.Errorf("Expected a missing table, got %v", problems)
+	}
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Iterator yields query results one entity at a time without loading them all
+// into memory. Callers must Close the iterator; the underlying connection is
+// held until then.
+//
+//	it, err := repo.FindAllStream(ctx)
+//	if err != nil { ... }
+//	defer it.Close()
+//	for it.Next() {
+//		user := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] interface {
+	// Next advances to the next entity, returning false when done or on error
+	Next() bool
+
+	// Value returns the current entity
+	Value() *T
+
+	// Err returns the first error encountered during iteration
+	Err() error
+
+	// Close releases the underlying rows; it is safe to call more than once
+	Close()
+}
+
+// rowsIterator implements Iterator over open pgx rows
+type rowsIterator[T any] struct {
+	ctx     context.Context
+	rows    pgx.Rows
+	scan    func(pgx.Row, *T) error
+	current *T
+	err     error
+}
+
+func (it *rowsIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		it.rows.Close()
+		return false
+	}
+	if !it.rows.Next() {
+		it.err = it.rows.Err()
+		return false
+	}
+
+	entity := new(T)
+	if err := it.scan(it.rows, entity); err != nil {
+		it.err = err
+		it.rows.Close()
+		return false
+	}
+	it.current = entity
+	return true
+}
+
+func (it *rowsIterator[T]) Value() *T {
+	return it.current
+}
+
+func (it *rowsIterator[T]) Err() error {
+	return it.err
+}
+
+func (it *rowsIterator[T]) Close() {
+	it.rows.Close()
+}
+
+// FindAllStream returns an iterator over all entities
+func (r *BaseRepository[T, ID]) FindAllStream(ctx context.Context) (Iterator[T], error) {
+	return r.FindAllWithSpecStream(ctx, nil)
+}
+
+// FindAllWithSpecStream returns an iterator over entities matching the specification
+func (r *BaseRepository[T, ID]) FindAllWithSpecStream(ctx context.Context, spec Specification[T]) (Iterator[T], error) {
+	rows, err := r.queryWithSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rowsIterator[T]{
+		ctx:  ctx,
+		rows: rows,
+		scan: r.scanRow,
+	}, nil
+}
+
+// ForEach calls fn for each entity matching the specification (nil for all),
+// streaming rows instead of loading them into memory. Iteration stops at the
+// first error returned by fn or when ctx is cancelled.
+func (r *BaseRepository[T, ID]) ForEach(ctx context.Context, spec Specification[T], fn func(entity *T) error) error {
+	it, err := r.FindAllWithSpecStream(ctx, spec)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// FindMapBy finds the entities matching the specification (nil for all) and
+// returns them keyed by keyFn. The map is built as the rows stream in, so
+// no intermediate slice is held. A later row replaces an earlier one with
+// the same key.
+//
+//	byEmail, err := core.FindMapBy(ctx, userRepo, spec, func(u *User) string { return u.Email })
+func FindMapBy[T any, ID comparable, K comparable](ctx context.Context, repo *BaseRepository[T, ID], spec Specification[T], keyFn func(*T) K) (map[K]*T, error) {
+	index := make(map[K]*T)
+	err := repo.ForEach(ctx, spec, func(entity *T) error {
+		index[keyFn(entity)] = entity
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// FindGroupedBy finds the entities matching the specification (nil for all)
+// and groups them by keyFn as the rows stream in. Each group keeps the
+// order of the rows.
+//
+//	byStatus, err := core.FindGroupedBy(ctx, orderRepo, nil, func(o *Order) string { return o.Status })
+func FindGroupedBy[T any, ID comparable, K comparable](ctx context.Context, repo *BaseRepository[T, ID], spec Specification[T], keyFn func(*T) K) (map[K][]*T, error) {
+	groups := make(map[K][]*T)
+	err := repo.ForEach(ctx, spec, func(entity *T) error {
+		key := keyFn(entity)
+		groups[key] = append(groups[key], entity)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
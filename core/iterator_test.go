@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRows is an in-memory pgx.Rows yielding one int64 column per row
+type fakeRows struct {
+	values []int64
+	pos    int
+	closed bool
+}
+
+func (f *fakeRows) Close()                                       { f.closed = true }
+func (f *fakeRows) Err() error                                   { return nil }
+func (f *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (f *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (f *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (f *fakeRows) RawValues() [][]byte                          { return nil }
+func (f *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (f *fakeRows) Next() bool {
+	if f.closed || f.pos >= len(f.values) {
+		return false
+	}
+	f.pos++
+	return true
+}
+
+func (f *fakeRows) Scan(dest ...any) error {
+	*(dest[0].(*int64)) = f.values[f.pos-1]
+	return nil
+}
+
+func scanID(row pgx.Row, user *TestUser) error {
+	return row.Scan(&user.ID)
+}
+
+func TestRowsIterator(t *testing.T) {
+	t.Run("should yield rows one at a time", func(t *testing.T) {
+		rows := &fakeRows{values: []int64{1, 2, 3}}
+		it := &rowsIterator[TestUser]{ctx: context.Background(), rows: rows, scan: scanID}
+		defer it.Close()
+
+		var ids []int64
+		for it.Next() {
+			ids = append(ids, it.Value().ID)
+		}
+
+		if it.Err() != nil {
+			t.Fatalf("Unexpected error: %v", it.Err())
+		}
+		if len(ids) != 3 || ids[2] != 3 {
+			t.Errorf("Expected [1 2 3], got %v", ids)
+		}
+	})
+
+	t.Run("should stop when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rows := &fakeRows{values: []int64{1, 2, 3}}
+		it := &rowsIterator[TestUser]{ctx: ctx, rows: rows, scan: scanID}
+
+		it.Next()
+		cancel()
+
+		if it.Next() {
+			t.Error("Expected iteration to stop after cancellation")
+		}
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", it.Err())
+		}
+		if !rows.closed {
+			t.Error("Expected rows to be closed after cancellation")
+		}
+	})
+}
+
+func TestFindMapBy(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+	byEmail := func(u *TestUser) string { return u.Email }
+
+	if _, err := FindMapBy(ctx, repo, Equal[TestUser]("age", 30), byEmail); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+	if _, err := FindGroupedBy(ctx, repo, nil, byEmail); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+
+	statements := capture.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(statements))
+	}
+	if want := "SELECT * FROM test_user WHERE age = $1"; statements[0].SQL != want {
+		t.Errorf("Expected %q, got %q", want, statements[0].SQL)
+	}
+	if want := "SELECT * FROM test_user"; statements[1].SQL != want {
+		t.Errorf("Expected %q, got %q", want, statements[1].SQL)
+	}
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isJSONColumn reports whether a field is a json or jsonb column holding a
+// struct, map or slice that is marshaled automatically. String and []byte
+// fields hold raw JSON and, like types with their own Valuer or Scanner, are
+// passed through unchanged.
+func isJSONColumn(f *Field) bool {
+	switch strings.ToLower(f.ExplicitType) {
+	case "json", "jsonb":
+	default:
+		return false
+	}
+
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if hasCustomCodec(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// columnValue returns the field's value as a query argument. Nil pointers
+// are passed as an untyped nil so that they are always written as NULL.
+func (f *Field) columnValue(entity reflect.Value) interface{} {
+	value := f.valueOf(entity)
+	if isNilPointer(value) {
+		return nil
+	}
+	if f.JSON {
+		return jsonValue{v: value.Interface()}
+	}
+	return driverArg(value)
+}
+
+// scanTarget returns the destination for scanning the field's column
+func (f *Field) scanTarget(entity reflect.Value) interface{} {
+	if f.JSON {
+		return jsonScanner{dest: f.valueOf(entity)}
+	}
+	return f.valueOf(entity).Addr().Interface()
+}
+
+// jsonValue marshals a value to JSON when it is sent as a query argument.
+// Nil maps, slices and pointers are written as SQL NULL.
+type jsonValue struct {
+	v interface{}
+}
+
+// Value implements driver.Valuer
+func (j jsonValue) Value() (driver.Value, error) {
+	rv := reflect.ValueOf(j.v)
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	data, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON column: %w", err)
+	}
+	return string(data), nil
+}
+
+// MarshalJSON implements json.Marshaler so wrapped values nest in JSON payloads
+func (j jsonValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.v)
+}
+
+// String renders the JSON text, so debug output shows the argument as sent
+func (j jsonValue) String() string {
+	data, err := json.Marshal(j.v)
+	if err != nil {
+		return fmt.Sprintf("%v", j.v)
+	}
+	return string(data)
+}
+
+// jsonScanner unmarshals a JSON column into a struct field, leaving the
+// field zero for NULL
+type jsonScanner struct {
+	dest reflect.Value
+}
+
+// Scan implements sql.Scanner
+func (j jsonScanner) Scan(src interface{}) error {
+	j.dest.Set(reflect.Zero(j.dest.Type()))
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON field %s", src, j.dest.Type())
+	}
+
+	if err := json.Unmarshal(data, j.dest.Addr().Interface()); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON column: %w", err)
+	}
+	return nil
+}
+
+// JSONBContains creates a specification for field @> value, where value is
+// marshaled to JSON (e.g. map[string]interface{}{"plan": "pro"})
+func JSONBContains[T any](field string, value interface{}) Specification[T] {
+	return Where[T](fmt.Sprintf("%s @> $1::jsonb", field), jsonValue{v: value})
+}
+
+// JSONBHasKey creates a specification for a top-level key in a jsonb field
+func JSONBHasKey[T any](field string, key string) Specification[T] {
+	return Where[T](fmt.Sprintf("jsonb_exists(%s, $1)", field), key)
+}
+
+// JSONBPathExists creates a specification matching rows where the SQL/JSON
+// path returns any item (e.g. `$.tags[*] ? (@ == "go")`)
+func JSONBPathExists[T any](field string, path string) Specification[T] {
+	return Where[T](fmt.Sprintf("jsonb_path_exists(%s, $1::jsonpath)", field), path)
+}
+
+// JSONBPath creates a specification matching rows where the SQL/JSON path
+// returns any item, with vars bound to the path's $name variables so filter
+// values never have to be spliced into the path text
+// (e.g. `$.tags[*] ? (@ == $tag)` with map[string]interface{}{"tag": "go"})
+func JSONBPath[T any](field string, path string, vars map[string]interface{}) Specification[T] {
+	if len(vars) == 0 {
+		return JSONBPathExists[T](field, path)
+	}
+	return Where[T](fmt.Sprintf("jsonb_path_exists(%s, $1::jsonpath, $2::jsonb)", field), path, jsonValue{v: vars})
+}
+
+// JSONBContainedBy creates a specification for field <@ value, where value is
+// marshaled to JSON
+func JSONBContainedBy[T any](field string, value interface{}) Specification[T] {
+	return Where[T](fmt.Sprintf("%s <@ $1::jsonb", field), jsonValue{v: value})
+}
+
+// JSONBHasAnyKey creates a specification matching a jsonb field holding any
+// of the top-level keys
+func JSONBHasAnyKey[T any](field string, keys ...string) Specification[T] {
+	return Where[T](fmt.Sprintf("jsonb_exists_any(%s, $1::text[])", field), keys)
+}
+
+// JSONBHasAllKeys creates a specification matching a jsonb field holding
+// every one of the top-level keys
+func JSONBHasAllKeys[T any](field string, keys ...string) Specification[T] {
+	return Where[T](fmt.Sprintf("jsonb_exists_all(%s, $1::text[])", field), keys)
+}
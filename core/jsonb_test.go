@@ -0,0 +1,168 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Preferences is stored as a JSON document
+type Preferences struct {
+	Theme  string `json:"theme"`
+	Alerts bool   `json:"alerts"`
+}
+
+// TestProfile has JSONB columns
+type TestProfile struct {
+	ID       int64             `db:"id" jet:"primary_key,auto_increment"`
+	Prefs    Preferences       `db:"prefs" jet:"type:jsonb"`
+	Labels   map[string]string `db:"labels" jet:"type:jsonb"`
+	Tags     []string          `db:"tags" jet:"type:jsonb"`
+	Raw      json.RawMessage   `db:"raw" jet:"type:jsonb"`
+	Nickname string            `db:"nickname"`
+}
+
+func TestJSONBColumns(t *testing.T) {
+	repo, err := NewBaseRepository[TestProfile, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should detect JSON columns", func(t *testing.T) {
+		expected := map[string]bool{"prefs": true, "labels": true, "tags": true, "raw": false, "nickname": false}
+		for _, fieldMeta := range repo.entity.Fields {
+			if want, ok := expected[fieldMeta.DBName]; ok && fieldMeta.JSON != want {
+				t.Errorf("Expected JSON=%v for %s", want, fieldMeta.DBName)
+			}
+		}
+	})
+
+	t.Run("should marshal JSON columns on insert", func(t *testing.T) {
+		profile := &TestProfile{
+			Prefs: Preferences{Theme: "dark", Alerts: true},
+			Tags:  []string{"go", "sql"},
+			Raw:   json.RawMessage(`{"a":1}`),
+		}
+		_, args := repo.insertStatement(profile)
+
+		expected := []interface{}{`{"theme":"dark","alerts":true}`, nil, `["go","sql"]`}
+		for i, want := range expected {
+			got, err := args[i].(jsonValue).Value()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		}
+		if _, ok := args[3].(json.RawMessage); !ok {
+			t.Errorf("Expected raw JSON to pass through, got %T", args[3])
+		}
+	})
+
+	t.Run("should marshal JSON columns in partial updates", func(t *testing.T) {
+		_, args, err := repo.updateFieldsStatement(1, map[string]interface{}{"labels": map[string]string{"k": "v"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, _ := args[0].(jsonValue).Value(); got != `{"k":"v"}` {
+			t.Errorf("Expected marshaled labels, got %v", got)
+		}
+	})
+
+	t.Run("should unmarshal JSON columns on scan", func(t *testing.T) {
+		profile := &TestProfile{Tags: []string{"stale"}}
+		v := reflect.ValueOf(profile).Elem()
+		row := fakeRow{
+			int64(1),
+			[]byte(`{"theme":"light","alerts":false}`),
+			`{"k":"v"}`,
+			nil,
+			json.RawMessage(`{}`),
+			"neo",
+		}
+		if err := row.Scan(targetsOf(repo.entity.Fields, v)...); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+
+		if profile.Prefs.Theme != "light" {
+			t.Errorf("Expected theme 'light', got '%s'", profile.Prefs.Theme)
+		}
+		if profile.Labels["k"] != "v" {
+			t.Errorf("Expected labels to be unmarshaled, got %v", profile.Labels)
+		}
+		if profile.Tags != nil {
+			t.Errorf("Expected NULL to reset tags, got %v", profile.Tags)
+		}
+	})
+}
+
+func targetsOf(fields []Field, v reflect.Value) []interface{} {
+	targets := make([]interface{}, len(fields))
+	for i := range fields {
+		targets[i] = fields[i].scanTarget(v)
+	}
+	return targets
+}
+
+func TestJSONBSpecifications(t *testing.T) {
+	t.Run("JSONBContains", func(t *testing.T) {
+		sql, args := JSONBContains[TestProfile]("prefs", map[string]interface{}{"theme": "dark"}).ToSQL()
+		if sql != "prefs @> $1::jsonb" {
+			t.Errorf("Expected 'prefs @> $1::jsonb', got '%s'", sql)
+		}
+		if got, _ := args[0].(jsonValue).Value(); got != `{"theme":"dark"}` {
+			t.Errorf("Expected marshaled argument, got %v", got)
+		}
+		if inlined := InlineArgs(sql, args); inlined != `prefs @> '{"theme":"dark"}'::jsonb` {
+			t.Errorf("Unexpected inlined SQL: %s", inlined)
+		}
+	})
+
+	t.Run("JSONBHasKey", func(t *testing.T) {
+		sql, args := JSONBHasKey[TestProfile]("labels", "team").ToSQL()
+		if sql != "jsonb_exists(labels, $1)" || args[0] != "team" {
+			t.Errorf("Unexpected SQL: %s %v", sql, args)
+		}
+	})
+
+	t.Run("JSONBPathExists", func(t *testing.T) {
+		sql, args := JSONBPathExists[TestProfile]("tags", `$[*] ? (@ == "go")`).ToSQL()
+		if sql != "jsonb_path_exists(tags, $1::jsonpath)" || args[0] != `$[*] ? (@ == "go")` {
+			t.Errorf("Unexpected SQL: %s %v", sql, args)
+		}
+	})
+
+	t.Run("JSONBPath", func(t *testing.T) {
+		sql, args := JSONBPath[TestProfile]("tags", `$[*] ? (@ == $tag)`, map[string]interface{}{"tag": "go"}).ToSQL()
+		if sql != "jsonb_path_exists(tags, $1::jsonpath, $2::jsonb)" || len(args) != 2 {
+			t.Fatalf("Unexpected SQL: %s %v", sql, args)
+		}
+		if got, _ := args[1].(jsonValue).Value(); got != `{"tag":"go"}` {
+			t.Errorf("Expected marshaled vars, got %v", got)
+		}
+
+		sql, _ = JSONBPath[TestProfile]("tags", `$[*]`, nil).ToSQL()
+		if sql != "jsonb_path_exists(tags, $1::jsonpath)" {
+			t.Errorf("Expected the two-argument form without vars, got '%s'", sql)
+		}
+	})
+
+	t.Run("JSONBContainedBy", func(t *testing.T) {
+		sql, _ := JSONBContainedBy[TestProfile]("labels", map[string]string{"team": "core"}).ToSQL()
+		if sql != "labels <@ $1::jsonb" {
+			t.Errorf("Expected 'labels <@ $1::jsonb', got '%s'", sql)
+		}
+	})
+
+	t.Run("JSONBHasAnyKey and JSONBHasAllKeys", func(t *testing.T) {
+		sql, args := JSONBHasAnyKey[TestProfile]("labels", "team", "owner").ToSQL()
+		if sql != "jsonb_exists_any(labels, $1::text[])" || len(args[0].([]string)) != 2 {
+			t.Errorf("Unexpected SQL: %s %v", sql, args)
+		}
+		sql, _ = JSONBHasAllKeys[TestProfile]("labels", "team").ToSQL()
+		if sql != "jsonb_exists_all(labels, $1::text[])" {
+			t.Errorf("Unexpected SQL: %s", sql)
+		}
+	})
+}
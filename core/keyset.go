@@ -0,0 +1,318 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Cursor positions a keyset page. Sort lists the ordering columns; the primary
+// key is appended as a tie-breaker when missing. Token is the NextCursor of the
+// previous page, or empty for the first page.
+type Cursor struct {
+	Sort  Sort
+	Token string
+}
+
+// After returns a cursor for the page following the given token with the same sort
+func (c Cursor) After(token string) Cursor {
+	return Cursor{Sort: c.Sort, Token: token}
+}
+
+// KeysetPage represents a page of results from keyset pagination
+type KeysetPage[T any] struct {
+	Content    []*T   // Page content
+	Size       int    // Requested page size
+	NextCursor string // Token for the next page, empty when HasNext is false
+	HasNext    bool   // Whether more rows follow this page
+	Sort       Sort   // Effective sort, including the primary key tie-breaker
+}
+
+// cursorToken is the decoded form of an opaque cursor token
+type cursorToken struct {
+	Keys   []string          `json:"k"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// FindAllKeyset finds a page of entities after the cursor position.
+// Unlike FindAllPaged it does not use OFFSET, so deep pages stay fast.
+func (r *BaseRepository[T, ID]) FindAllKeyset(ctx context.Context, cursor Cursor, size int) (*KeysetPage[T], error) {
+	return r.FindAllKeysetWithSpec(ctx, nil, cursor, size)
+}
+
+// FindAllKeysetWithSpec finds a page of entities matching the specification after the cursor position
+func (r *BaseRepository[T, ID]) FindAllKeysetWithSpec(ctx context.Context, spec Specification[T], cursor Cursor, size int) (*KeysetPage[T], error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: page size must be positive", ErrInvalidInput)
+	}
+
+	orders, fields, err := r.keysetOrders(cursor.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Token != "" {
+		after, err := r.keysetCondition(orders, fields, cursor.Token)
+		if err != nil {
+			return nil, err
+		}
+		spec = And(spec, after)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	query, args := r.keysetQuery(spec, orders, size+1)
+	content, err := r.queryEntities(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &KeysetPage[T]{
+		Size: size,
+		Sort: Sort{Orders: orders},
+	}
+	if len(content) > size {
+		content = content[:size]
+		page.HasNext = true
+		page.NextCursor, err = r.encodeCursor(orders, fields, content[size-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	page.Content = content
+
+	return page, nil
+}
+
+// keysetOrders validates the sort and appends the primary key as a tie-breaker
+func (r *BaseRepository[T, ID]) keysetOrders(sort Sort) ([]Order, []*Field, error) {
+	orders := make([]Order, 0, len(sort.Orders)+1)
+	fields := make([]*Field, 0, len(sort.Orders)+1)
+	hasPK := false
+
+	for _, order := range sort.Orders {
+		fieldMeta := r.lookupField(order.Field)
+		if fieldMeta == nil || fieldMeta.Ignored {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnknownField, order.Field)
+		}
+		if fieldMeta.PrimaryKey {
+			hasPK = true
+		}
+		orders = append(orders, Order{Field: fieldMeta.DBName, Direction: order.Direction})
+		fields = append(fields, fieldMeta)
+	}
+
+	if !hasPK {
+		direction := Asc
+		if len(orders) > 0 {
+			direction = orders[len(orders)-1].Direction
+		}
+		orders = append(orders, Order{Field: r.pkField, Direction: direction})
+		fields = append(fields, r.entity.PrimaryKey)
+	}
+
+	return orders, fields, nil
+}
+
+// keysetQuery builds the SELECT for a keyset page
+func (r *BaseRepository[T, ID]) keysetQuery(spec Specification[T], orders []Order, limit int) (string, []interface{}) {
+	var whereClause string
+	var args []interface{}
+	if spec != nil {
+		whereClause, args = spec.ToSQL()
+	}
+
+	orderClauses := make([]string, len(orders))
+	for i, order := range orders {
+		direction := "ASC"
+		if order.Direction == Desc {
+			direction = "DESC"
+		}
+		orderClauses[i] = fmt.Sprintf("%s %s", order.Field, direction)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT %d%s",
+		r.selectList(),
+		r.tableName,
+		r.whereClause(whereClause),
+		strings.Join(orderClauses, ", "),
+		limit,
+		r.lockClause(),
+	)
+
+	return query, args
+}
+
+// keysetCondition decodes the token and builds the "rows after this position" condition.
+// Uniform directions use a row comparison so an index on the sort columns can be used.
+func (r *BaseRepository[T, ID]) keysetCondition(orders []Order, fields []*Field, token string) (Specification[T], error) {
+	values, err := decodeCursor(orders, fields, token)
+	if err != nil {
+		return nil, err
+	}
+
+	uniform := true
+	for _, order := range orders {
+		if order.Direction != orders[0].Direction {
+			uniform = false
+			break
+		}
+	}
+
+	columns := make([]string, len(orders))
+	for i, order := range orders {
+		columns[i] = order.Field
+	}
+
+	if uniform {
+		if orders[0].Direction == Desc {
+			return TupleLessThan[T](strings.Join(columns, ", "), values...)
+		}
+		return TupleGreaterThan[T](strings.Join(columns, ", "), values...)
+	}
+
+	// Mixed directions: (a > x) OR (a = x AND b < y) OR ...
+	branches := make([]Specification[T], len(orders))
+	for i, order := range orders {
+		conditions := make([]Specification[T], 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, Equal[T](columns[j], values[j]))
+		}
+		if order.Direction == Desc {
+			conditions = append(conditions, LessThan[T](columns[i], values[i]))
+		} else {
+			conditions = append(conditions, GreaterThan[T](columns[i], values[i]))
+		}
+		branches[i] = And(conditions...)
+	}
+	return Or(branches...), nil
+}
+
+// encodeCursor builds an opaque token from the sort column values of the last row
+func (r *BaseRepository[T, ID]) encodeCursor(orders []Order, fields []*Field, last *T) (string, error) {
+	v := reflect.ValueOf(last).Elem()
+	token := cursorToken{
+		Keys:   make([]string, len(orders)),
+		Values: make([]json.RawMessage, len(orders)),
+	}
+
+	for i, fieldMeta := range fields {
+		raw, err := json.Marshal(fieldMeta.valueOf(v).Interface())
+		if err != nil {
+			return "", fmt.Errorf("encode cursor: %w", err)
+		}
+		token.Keys[i] = orders[i].Field
+		token.Values[i] = raw
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes a token into values typed like the sort fields
+func decodeCursor(orders []Order, fields []*Field, token string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var decoded cursorToken
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(decoded.Keys) != len(orders) || len(decoded.Values) != len(orders) {
+		return nil, fmt.Errorf("%w: sort does not match cursor", ErrInvalidCursor)
+	}
+
+	values := make([]interface{}, len(orders))
+	for i, order := range orders {
+		if decoded.Keys[i] != order.Field {
+			return nil, fmt.Errorf("%w: sort does not match cursor", ErrInvalidCursor)
+		}
+		value := reflect.New(fields[i].Type)
+		if err := json.Unmarshal(decoded.Values[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		values[i] = driverArg(value.Elem())
+	}
+
+	return values, nil
+}
+
+// ForEachBatch walks every entity matching the specification (nil for all) in
+// keyset batches of batchSize, ordered by cursor.Sort (the primary key by
+// default). Unlike ForEach it holds no connection between batches and never
+// uses OFFSET, so it suits long maintenance jobs such as backfills and
+// re-encryption.
+//
+// fn receives each batch with a checkpoint token positioned after its last
+// entity. Persist the checkpoint once the batch is processed; passing it as
+// cursor.Token resumes the walk after that batch, and the final checkpoint
+// picks up rows added later. Iteration stops at the first error returned by
+// fn or when ctx is cancelled.
+func (r *BaseRepository[T, ID]) ForEachBatch(ctx context.Context, spec Specification[T], cursor Cursor, batchSize int, fn func(batch []*T, checkpoint string) error) error {
+	return r.forEachBatch(ctx, cursor, batchSize, func(c Cursor) (*KeysetPage[T], error) {
+		return r.FindAllKeysetWithSpec(ctx, spec, c, batchSize)
+	}, fn)
+}
+
+// ForEachKeyset calls fn for each entity matching the specification, fetching
+// them in keyset batches of batchSize as ForEachBatch does. Use ForEachBatch
+// when the walk must be resumable.
+func (r *BaseRepository[T, ID]) ForEachKeyset(ctx context.Context, spec Specification[T], cursor Cursor, batchSize int, fn func(entity *T) error) error {
+	return r.ForEachBatch(ctx, spec, cursor, batchSize, func(batch []*T, _ string) error {
+		for _, entity := range batch {
+			if err := fn(entity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forEachBatch drives a keyset walk over the pages returned by fetch
+func (r *BaseRepository[T, ID]) forEachBatch(ctx context.Context, cursor Cursor, batchSize int, fetch func(Cursor) (*KeysetPage[T], error), fn func(batch []*T, checkpoint string) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("%w: batch size must be positive", ErrInvalidInput)
+	}
+	orders, fields, err := r.keysetOrders(cursor.Sort)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+		if len(page.Content) == 0 {
+			return nil
+		}
+
+		// The last page has no NextCursor; its checkpoint is built the same way
+		checkpoint := page.NextCursor
+		if !page.HasNext {
+			checkpoint, err = r.encodeCursor(orders, fields, page.Content[len(page.Content)-1])
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(page.Content, checkpoint); err != nil {
+			return err
+		}
+		if !page.HasNext {
+			return nil
+		}
+		cursor = cursor.After(checkpoint)
+	}
+}
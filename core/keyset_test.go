@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBaseRepository_Keyset(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	last := &TestUser{ID: 42, Age: 30, CreatedAt: created}
+
+	t.Run("should append primary key tie-breaker", func(t *testing.T) {
+		orders, _, err := repo.keysetOrders(Sort{Orders: []Order{{Field: "CreatedAt", Direction: Desc}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		query, _ := repo.keysetQuery(nil, orders, 11)
+		expected := "SELECT * FROM test_user ORDER BY created_at DESC, id DESC LIMIT 11"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+	})
+
+	t.Run("should reject unknown sort fields", func(t *testing.T) {
+		_, _, err := repo.keysetOrders(Sort{Orders: []Order{{Field: "name; DROP TABLE x"}}})
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+
+	t.Run("should round-trip cursor with row comparison", func(t *testing.T) {
+		orders, fields, _ := repo.keysetOrders(Sort{Orders: []Order{{Field: "created_at", Direction: Desc}}})
+		token, err := repo.encodeCursor(orders, fields, last)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		spec, err := repo.keysetCondition(orders, fields, token)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		where, args := spec.ToSQL()
+
+		if where != "(created_at, id) < ($1, $2)" {
+			t.Errorf("Expected '(created_at, id) < ($1, $2)', got '%s'", where)
+		}
+		if !args[0].(time.Time).Equal(created) || args[1] != int64(42) {
+			t.Errorf("Expected typed cursor values, got %v", args)
+		}
+	})
+
+	t.Run("should expand mixed directions", func(t *testing.T) {
+		orders, fields, _ := repo.keysetOrders(Sort{Orders: []Order{{Field: "age", Direction: Desc}, {Field: "id", Direction: Asc}}})
+		token, _ := repo.encodeCursor(orders, fields, last)
+
+		spec, err := repo.keysetCondition(orders, fields, token)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		where, args := spec.ToSQL()
+
+		expected := "(age < $1) OR ((age = $2) AND (id > $3))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 3 {
+			t.Errorf("Expected 3 args, got %d", len(args))
+		}
+	})
+
+	t.Run("should reject cursor for a different sort", func(t *testing.T) {
+		orders, fields, _ := repo.keysetOrders(Sort{Orders: []Order{{Field: "age"}}})
+		token, _ := repo.encodeCursor(orders, fields, last)
+
+		other, otherFields, _ := repo.keysetOrders(Sort{Orders: []Order{{Field: "created_at"}}})
+		if _, err := repo.keysetCondition(other, otherFields, token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+		if _, err := repo.keysetCondition(orders, fields, "not-a-cursor!"); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
+
+func TestBaseRepository_ForEachBatch(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	orders, fields, _ := repo.keysetOrders(Sort{})
+
+	// fetch serves users 1..7 by id after the cursor position, as the database would
+	fetched := 0
+	fetch := func(c Cursor) (*KeysetPage[TestUser], error) {
+		fetched++
+		after := int64(0)
+		if c.Token != "" {
+			values, err := decodeCursor(orders, fields, c.Token)
+			if err != nil {
+				return nil, err
+			}
+			after = values[0].(int64)
+		}
+		page := &KeysetPage[TestUser]{Size: 3}
+		for id := after + 1; id <= 7 && len(page.Content) < 3; id++ {
+			page.Content = append(page.Content, &TestUser{ID: id})
+		}
+		if last := page.Content; len(last) == 3 && last[2].ID < 7 {
+			page.HasNext = true
+			page.NextCursor, _ = repo.encodeCursor(orders, fields, last[2])
+		}
+		return page, nil
+	}
+
+	t.Run("should visit every entity in batches", func(t *testing.T) {
+		var sizes []int
+		var ids []int64
+		var checkpoints []string
+		err := repo.forEachBatch(context.Background(), Cursor{}, 3, fetch, func(batch []*TestUser, checkpoint string) error {
+			sizes = append(sizes, len(batch))
+			for _, user := range batch {
+				ids = append(ids, user.ID)
+			}
+			checkpoints = append(checkpoints, checkpoint)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(sizes) != 3 || sizes[0] != 3 || sizes[2] != 1 {
+			t.Errorf("Expected batches of 3, 3 and 1, got %v", sizes)
+		}
+		if len(ids) != 7 || ids[6] != 7 {
+			t.Errorf("Expected ids 1..7, got %v", ids)
+		}
+
+		// The final checkpoint resumes after the last entity
+		values, err := decodeCursor(orders, fields, checkpoints[2])
+		if err != nil || values[0] != int64(7) {
+			t.Errorf("Expected final checkpoint at id 7, got %v (%v)", values, err)
+		}
+	})
+
+	t.Run("should resume from a checkpoint", func(t *testing.T) {
+		var checkpoint string
+		stop := errors.New("stop")
+		err := repo.forEachBatch(context.Background(), Cursor{}, 3, fetch, func(batch []*TestUser, c string) error {
+			if batch[0].ID > 3 {
+				return stop
+			}
+			checkpoint = c
+			return nil
+		})
+		if !errors.Is(err, stop) {
+			t.Fatalf("Expected the callback error, got %v", err)
+		}
+
+		var ids []int64
+		err = repo.forEachBatch(context.Background(), Cursor{Token: checkpoint}, 3, fetch, func(batch []*TestUser, _ string) error {
+			for _, user := range batch {
+				ids = append(ids, user.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(ids) != 4 || ids[0] != 4 {
+			t.Errorf("Expected ids 4..7 after resuming, got %v", ids)
+		}
+	})
+
+	t.Run("should stop when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		fetched = 0
+		err := repo.forEachBatch(ctx, Cursor{}, 3, fetch, func([]*TestUser, string) error {
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) || fetched != 1 {
+			t.Errorf("Expected cancellation after one batch, got %v after %d fetches", err, fetched)
+		}
+	})
+
+	t.Run("should reject a non-positive batch size", func(t *testing.T) {
+		err := repo.ForEachBatch(context.Background(), nil, Cursor{}, 0, func([]*TestUser, string) error { return nil })
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
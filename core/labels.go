@@ -0,0 +1,56 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxApplicationNameLen is the longest application_name PostgreSQL keeps
+// (NAMEDATALEN - 1); longer names are truncated by the server
+const maxApplicationNameLen = 63
+
+// applicationName returns the application_name reported to PostgreSQL and
+// shown in pg_stat_activity: Config.ApplicationName when set, otherwise
+// "service/version" from ServiceName and ServiceVersion, falling back to the
+// executable name.
+func (c Config) applicationName() string {
+	name := c.ApplicationName
+	if name == "" && c.ServiceName != "" {
+		name = c.ServiceName
+		if c.ServiceVersion != "" {
+			name += "/" + c.ServiceVersion
+		}
+	}
+	if name == "" && len(os.Args) > 0 {
+		name = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+
+	// Truncate on a rune boundary, as the server counts bytes
+	for len(name) > maxApplicationNameLen {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// Labels returns the labels identifying the database's connection pool in
+// metrics: Config.PoolLabels plus application_name, database and, when set,
+// service and version. Configured labels take precedence.
+func (db *Database) Labels() map[string]string {
+	labels := map[string]string{
+		"application_name": db.config.applicationName(),
+		"database":         db.config.Database,
+	}
+	if db.config.ServiceName != "" {
+		labels["service"] = db.config.ServiceName
+	}
+	if db.config.ServiceVersion != "" {
+		labels["version"] = db.config.ServiceVersion
+	}
+	for k, v := range db.config.PoolLabels {
+		labels[k] = v
+	}
+	return labels
+}
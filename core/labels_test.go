@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ApplicationName(t *testing.T) {
+	t.Run("explicit name wins", func(t *testing.T) {
+		c := Config{ApplicationName: "reporting", ServiceName: "billing"}
+		if got := c.applicationName(); got != "reporting" {
+			t.Errorf("Expected 'reporting', got '%s'", got)
+		}
+	})
+
+	t.Run("service and version", func(t *testing.T) {
+		c := Config{ServiceName: "billing", ServiceVersion: "v1.4.2"}
+		if got := c.applicationName(); got != "billing/v1.4.2" {
+			t.Errorf("Expected 'billing/v1.4.2', got '%s'", got)
+		}
+		c.ServiceVersion = ""
+		if got := c.applicationName(); got != "billing" {
+			t.Errorf("Expected 'billing', got '%s'", got)
+		}
+	})
+
+	t.Run("falls back to executable name", func(t *testing.T) {
+		if got := (Config{}).applicationName(); got == "" || strings.Contains(got, "/") {
+			t.Errorf("Expected executable base name, got '%s'", got)
+		}
+	})
+
+	t.Run("truncates to the server limit", func(t *testing.T) {
+		c := Config{ServiceName: strings.Repeat("é", 40)}
+		got := c.applicationName()
+		if len(got) != 62 || !strings.HasPrefix(c.ServiceName, got) {
+			t.Errorf("Expected 31 whole runes, got '%s' (%d bytes)", got, len(got))
+		}
+	})
+}
+
+func TestDatabase_Labels(t *testing.T) {
+	db := &Database{config: Config{
+		Database:       "app",
+		ServiceName:    "billing",
+		ServiceVersion: "v1.4.2",
+		PoolLabels:     map[string]string{"role": "replica", "database": "app_ro"},
+	}}
+
+	labels := db.Labels()
+	expected := map[string]string{
+		"application_name": "billing/v1.4.2",
+		"database":         "app_ro",
+		"service":          "billing",
+		"version":          "v1.4.2",
+		"role":             "replica",
+	}
+	if len(labels) != len(expected) {
+		t.Errorf("Expected %d labels, got %v", len(expected), labels)
+	}
+	for k, v := range expected {
+		if labels[k] != v {
+			t.Errorf("Expected label %s='%s', got '%s'", k, v, labels[k])
+		}
+	}
+
+	var c Config
+	WithService("api", "v2")(&c)
+	WithPoolLabels(map[string]string{"zone": "eu"})(&c)
+	if c.ServiceName != "api" || c.ServiceVersion != "v2" || c.PoolLabels["zone"] != "eu" {
+		t.Errorf("Expected options to be applied, got %+v", c)
+	}
+}
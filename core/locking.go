@@ -0,0 +1,77 @@
+package core
+
+import "context"
+
+// LockMode selects the row-level lock taken by SELECT queries
+type LockMode int
+
+const (
+	// LockNone takes no row locks
+	LockNone LockMode = iota
+	// LockForUpdate locks rows against concurrent updates and deletes
+	LockForUpdate
+	// LockForUpdateSkipLocked locks rows, skipping rows already locked (work queues)
+	LockForUpdateSkipLocked
+	// LockForUpdateNoWait locks rows, failing immediately if any is already locked
+	LockForUpdateNoWait
+	// LockForNoKeyUpdate locks rows but allows concurrent foreign key checks
+	LockForNoKeyUpdate
+	// LockForShare locks rows against concurrent updates while allowing other readers to share
+	LockForShare
+	// LockForShareSkipLocked takes share locks, skipping rows already locked
+	LockForShareSkipLocked
+)
+
+// SQL returns the locking clause for the mode, e.g. "FOR UPDATE SKIP LOCKED"
+func (m LockMode) SQL() string {
+	switch m {
+	case LockForUpdate:
+		return "FOR UPDATE"
+	case LockForUpdateSkipLocked:
+		return "FOR UPDATE SKIP LOCKED"
+	case LockForUpdateNoWait:
+		return "FOR UPDATE NOWAIT"
+	case LockForNoKeyUpdate:
+		return "FOR NO KEY UPDATE"
+	case LockForShare:
+		return "FOR SHARE"
+	case LockForShareSkipLocked:
+		return "FOR SHARE SKIP LOCKED"
+	default:
+		return ""
+	}
+}
+
+// WithLock returns a repository whose finders lock the rows they return.
+// Locks are held until the transaction ends, so use it with WithTx;
+// outside a transaction the lock is released as soon as the query completes.
+func (r *BaseRepository[T, ID]) WithLock(mode LockMode) *BaseRepository[T, ID] {
+	repo := *r
+	repo.lockMode = mode
+	return &repo
+}
+
+// FindByIDForUpdate finds an entity by ID and locks its row until the transaction ends
+func (r *BaseRepository[T, ID]) FindByIDForUpdate(ctx context.Context, id ID) (*T, error) {
+	if r.tx == nil {
+		return nil, ErrTransactionRequired
+	}
+	return r.WithLock(LockForUpdate).FindByID(ctx, id)
+}
+
+// FindOneForUpdate finds a single entity matching the specification and locks its row
+// until the transaction ends
+func (r *BaseRepository[T, ID]) FindOneForUpdate(ctx context.Context, spec Specification[T]) (*T, error) {
+	if r.tx == nil {
+		return nil, ErrTransactionRequired
+	}
+	return r.WithLock(LockForUpdate).FindOne(ctx, spec)
+}
+
+// lockClause returns the locking clause to append to SELECT queries
+func (r *BaseRepository[T, ID]) lockClause() string {
+	if sql := r.lockMode.SQL(); sql != "" {
+		return " " + sql
+	}
+	return ""
+}
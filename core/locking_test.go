@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLockMode(t *testing.T) {
+	tests := []struct {
+		mode     LockMode
+		expected string
+	}{
+		{LockNone, ""},
+		{LockForUpdate, "FOR UPDATE"},
+		{LockForUpdateSkipLocked, "FOR UPDATE SKIP LOCKED"},
+		{LockForUpdateNoWait, "FOR UPDATE NOWAIT"},
+		{LockForNoKeyUpdate, "FOR NO KEY UPDATE"},
+		{LockForShare, "FOR SHARE"},
+		{LockForShareSkipLocked, "FOR SHARE SKIP LOCKED"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.SQL(); got != tt.expected {
+			t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+		}
+	}
+}
+
+func TestBaseRepository_WithLock(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should not change the original repository", func(t *testing.T) {
+		locked := repo.WithLock(LockForUpdateSkipLocked)
+		if locked.lockClause() != " FOR UPDATE SKIP LOCKED" {
+			t.Errorf("Expected ' FOR UPDATE SKIP LOCKED', got '%s'", locked.lockClause())
+		}
+		if repo.lockClause() != "" {
+			t.Errorf("Expected no lock on original, got '%s'", repo.lockClause())
+		}
+	})
+
+	t.Run("should require a transaction", func(t *testing.T) {
+		if _, err := repo.FindByIDForUpdate(context.Background(), 1); !errors.Is(err, ErrTransactionRequired) {
+			t.Errorf("Expected ErrTransactionRequired, got %v", err)
+		}
+		if _, err := repo.FindOneForUpdate(context.Background(), Equal[TestUser]("id", 1)); !errors.Is(err, ErrTransactionRequired) {
+			t.Errorf("Expected ErrTransactionRequired, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// memoKey is the context key for the request-scoped memo store
+type memoKey struct{}
+
+// memoStore holds memoized read results grouped by entity type
+type memoStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]interface{}
+}
+
+// WithMemo returns a context that memoizes reads made through MemoizedRepository.
+// Identical reads within the context return the first result until a write
+// through the same repository type or ClearMemo invalidates them.
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey{}, &memoStore{
+		entries: make(map[string]map[string]interface{}),
+	})
+}
+
+// ClearMemo discards all memoized results in the context
+func ClearMemo(ctx context.Context) {
+	if store, ok := ctx.Value(memoKey{}).(*memoStore); ok {
+		store.mu.Lock()
+		store.entries = make(map[string]map[string]interface{})
+		store.mu.Unlock()
+	}
+}
+
+// MemoMiddleware scopes repository read memoization to each HTTP request
+func MemoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := WithMemo(req.Context())
+		defer ClearMemo(ctx)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (s *memoStore) get(group, key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.entries[group][key]
+	return value, ok
+}
+
+func (s *memoStore) set(group, key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries[group] == nil {
+		s.entries[group] = make(map[string]interface{})
+	}
+	s.entries[group][key] = value
+}
+
+func (s *memoStore) invalidate(group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, group)
+}
+
+// MemoizedRepository deduplicates identical reads within a memo context (see WithMemo).
+// Without a memo context it behaves exactly like the wrapped repository.
+// Memoized entities are shared between callers and must not be mutated.
+type MemoizedRepository[T any, ID comparable] struct {
+	Repository[T, ID]
+	group string
+}
+
+// NewMemoizedRepository wraps a repository with per-context memoization
+func NewMemoizedRepository[T any, ID comparable](repo Repository[T, ID]) *MemoizedRepository[T, ID] {
+	return &MemoizedRepository[T, ID]{
+		Repository: repo,
+		group:      fmt.Sprintf("%T", new(T)),
+	}
+}
+
+// memoize returns the memoized result for key or loads and stores it
+func memoize[R any](ctx context.Context, group, key string, load func() (R, error)) (R, error) {
+	store, ok := ctx.Value(memoKey{}).(*memoStore)
+	if !ok {
+		return load()
+	}
+
+	if cached, ok := store.get(group, key); ok {
+		return cached.(R), nil
+	}
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	store.set(group, key, result)
+	return result, nil
+}
+
+// specKey builds a memo key from a specification's SQL and arguments
+func specKey[T any](kind string, spec Specification[T]) string {
+	if spec == nil {
+		return kind + ":all"
+	}
+	sql, args := spec.ToSQL()
+	return fmt.Sprintf("%s:%s:%#v", kind, sql, args)
+}
+
+// invalidate drops memoized results for this entity type after a write
+func (m *MemoizedRepository[T, ID]) invalidate(ctx context.Context) {
+	if store, ok := ctx.Value(memoKey{}).(*memoStore); ok {
+		store.invalidate(m.group)
+	}
+}
+
+// WithTx implements Repository.WithTx. Writes through the transaction
+// repository invalidate the same memoized reads as writes through m.
+func (m *MemoizedRepository[T, ID]) WithTx(tx *Tx) Repository[T, ID] {
+	return &MemoizedRepository[T, ID]{
+		Repository: m.Repository.WithTx(tx),
+		group:      m.group,
+	}
+}
+
+// FindByID implements Repository.FindByID with memoization
+func (m *MemoizedRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
+	return memoize(ctx, m.group, fmt.Sprintf("id:%#v", id), func() (*T, error) {
+		return m.Repository.FindByID(ctx, id)
+	})
+}
+
+// FindAll implements Repository.FindAll with memoization
+func (m *MemoizedRepository[T, ID]) FindAll(ctx context.Context) ([]*T, error) {
+	return memoize(ctx, m.group, "all", func() ([]*T, error) {
+		return m.Repository.FindAll(ctx)
+	})
+}
+
+// Count implements Repository.Count with memoization
+func (m *MemoizedRepository[T, ID]) Count(ctx context.Context) (int64, error) {
+	return memoize(ctx, m.group, "count", func() (int64, error) {
+		return m.Repository.Count(ctx)
+	})
+}
+
+// FindOne implements Repository.FindOne with memoization
+func (m *MemoizedRepository[T, ID]) FindOne(ctx context.Context, spec Specification[T]) (*T, error) {
+	return memoize(ctx, m.group, specKey("one", spec), func() (*T, error) {
+		return m.Repository.FindOne(ctx, spec)
+	})
+}
+
+// FindAllWithSpec implements Repository.FindAllWithSpec with memoization
+func (m *MemoizedRepository[T, ID]) FindAllWithSpec(ctx context.Context, spec Specification[T]) ([]*T, error) {
+	return memoize(ctx, m.group, specKey("find", spec), func() ([]*T, error) {
+		return m.Repository.FindAllWithSpec(ctx, spec)
+	})
+}
+
+// CountWithSpec implements Repository.CountWithSpec with memoization
+func (m *MemoizedRepository[T, ID]) CountWithSpec(ctx context.Context, spec Specification[T]) (int64, error) {
+	return memoize(ctx, m.group, specKey("count", spec), func() (int64, error) {
+		return m.Repository.CountWithSpec(ctx, spec)
+	})
+}
+
+// ExistsWithSpec implements Repository.ExistsWithSpec with memoization
+func (m *MemoizedRepository[T, ID]) ExistsWithSpec(ctx context.Context, spec Specification[T]) (bool, error) {
+	return memoize(ctx, m.group, specKey("exists", spec), func() (bool, error) {
+		return m.Repository.ExistsWithSpec(ctx, spec)
+	})
+}
+
+// Save implements Repository.Save and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) Save(ctx context.Context, entity *T) (*T, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.Save(ctx, entity)
+}
+
+// SaveAll implements Repository.SaveAll and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) SaveAll(ctx context.Context, entities []*T) ([]*T, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.SaveAll(ctx, entities)
+}
+
+// SaveBatch implements Repository.SaveBatch and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) SaveBatch(ctx context.Context, entities []*T, batchSize int) error {
+	defer m.invalidate(ctx)
+	return m.Repository.SaveBatch(ctx, entities, batchSize)
+}
+
+// Update implements Repository.Update and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) Update(ctx context.Context, entity *T) (*T, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.Update(ctx, entity)
+}
+
+// UpdateAll implements Repository.UpdateAll and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) UpdateAll(ctx context.Context, entities []*T) ([]*T, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.UpdateAll(ctx, entities)
+}
+
+// Delete implements Repository.Delete and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) Delete(ctx context.Context, entity *T) error {
+	defer m.invalidate(ctx)
+	return m.Repository.Delete(ctx, entity)
+}
+
+// DeleteByID implements Repository.DeleteByID and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) DeleteByID(ctx context.Context, id ID) error {
+	defer m.invalidate(ctx)
+	return m.Repository.DeleteByID(ctx, id)
+}
+
+// DeleteAll implements Repository.DeleteAll and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) DeleteAll(ctx context.Context, entities []*T) error {
+	defer m.invalidate(ctx)
+	return m.Repository.DeleteAll(ctx, entities)
+}
+
+// DeleteAllByIDs implements Repository.DeleteAllByIDs and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) DeleteAllByIDs(ctx context.Context, ids []ID) error {
+	defer m.invalidate(ctx)
+	return m.Repository.DeleteAllByIDs(ctx, ids)
+}
+
+// DeleteWithSpec implements Repository.DeleteWithSpec and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) DeleteWithSpec(ctx context.Context, spec Specification[T]) (int64, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.DeleteWithSpec(ctx, spec)
+}
+
+// Exec implements Repository.Exec and invalidates memoized reads
+func (m *MemoizedRepository[T, ID]) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	defer m.invalidate(ctx)
+	return m.Repository.Exec(ctx, query, args...)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// countingRepository counts FindByID calls for memoization tests
+type countingRepository struct {
+	Repository[TestUser, int64]
+	finds int
+}
+
+func (c *countingRepository) FindByID(ctx context.Context, id int64) (*TestUser, error) {
+	c.finds++
+	return &TestUser{ID: id}, nil
+}
+
+func (c *countingRepository) Save(ctx context.Context, entity *TestUser) (*TestUser, error) {
+	return entity, nil
+}
+
+func (c *countingRepository) WithTx(tx *Tx) Repository[TestUser, int64] {
+	return c
+}
+
+func TestMemoizedRepository(t *testing.T) {
+	t.Run("should deduplicate reads within a memo context", func(t *testing.T) {
+		inner := &countingRepository{}
+		repo := NewMemoizedRepository[TestUser, int64](inner)
+		ctx := WithMemo(context.Background())
+
+		first, _ := repo.FindByID(ctx, 1)
+		second, _ := repo.FindByID(ctx, 1)
+		repo.FindByID(ctx, 2)
+
+		if inner.finds != 2 {
+			t.Errorf("Expected 2 database reads, got %d", inner.finds)
+		}
+		if first != second {
+			t.Error("Expected identical reads to return the memoized entity")
+		}
+	})
+
+	t.Run("should not memoize without a memo context", func(t *testing.T) {
+		inner := &countingRepository{}
+		repo := NewMemoizedRepository[TestUser, int64](inner)
+
+		repo.FindByID(context.Background(), 1)
+		repo.FindByID(context.Background(), 1)
+
+		if inner.finds != 2 {
+			t.Errorf("Expected 2 database reads, got %d", inner.finds)
+		}
+	})
+
+	t.Run("should invalidate on write", func(t *testing.T) {
+		inner := &countingRepository{}
+		repo := NewMemoizedRepository[TestUser, int64](inner)
+		ctx := WithMemo(context.Background())
+
+		repo.FindByID(ctx, 1)
+		repo.Save(ctx, &TestUser{ID: 1})
+		repo.FindByID(ctx, 1)
+
+		if inner.finds != 2 {
+			t.Errorf("Expected 2 database reads, got %d", inner.finds)
+		}
+	})
+
+	t.Run("should invalidate on write in a transaction", func(t *testing.T) {
+		inner := &countingRepository{}
+		repo := NewMemoizedRepository[TestUser, int64](inner)
+		ctx := WithMemo(context.Background())
+
+		repo.FindByID(ctx, 1)
+		repo.WithTx(&Tx{}).Save(ctx, &TestUser{ID: 1})
+		repo.FindByID(ctx, 1)
+
+		if inner.finds != 2 {
+			t.Errorf("Expected 2 database reads, got %d", inner.finds)
+		}
+	})
+
+	t.Run("should scope memo to a request in middleware", func(t *testing.T) {
+		inner := &countingRepository{}
+		repo := NewMemoizedRepository[TestUser, int64](inner)
+		handler := MemoMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			repo.FindByID(req.Context(), 1)
+			repo.FindByID(req.Context(), 1)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if inner.finds != 2 {
+			t.Errorf("Expected one database read per request, got %d", inner.finds)
+		}
+	})
+}
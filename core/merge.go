@@ -0,0 +1,351 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// mergeMinVersion is the first PostgreSQL release with MERGE
+	mergeMinVersion = 150000
+
+	// mergeBySourceMinVersion is the first release with WHEN NOT MATCHED BY SOURCE
+	mergeBySourceMinVersion = 170000
+)
+
+// MergeBuilder builds MERGE INTO ... USING statements for reconcile operations:
+// update matched rows, insert unmatched rows and delete stale rows in one statement.
+// WHEN clauses are evaluated in the order they are added, and their conditions
+// are raw SQL without arguments.
+type MergeBuilder struct {
+	target      string
+	targetAlias string
+	source      string
+	sourceAlias string
+	sourceArgs  []interface{}
+	on          string
+	onArgs      []interface{}
+	columns     []string          // source columns, known when built by MergeFrom
+	fixed       []string          // key, join and auto timestamp columns, excluded from default updates
+	defaults    map[string]string // column defaults of source columns some rows leave unset
+	autoNow     []string          // columns refreshed with now on update
+	now         string            // SQL for the current time
+	clauses     []mergeClause
+}
+
+// mergeClause is a single WHEN [NOT] MATCHED clause
+type mergeClause struct {
+	match     string // MATCHED, NOT MATCHED or NOT MATCHED BY SOURCE
+	condition string
+	action    string // UPDATE, INSERT, DELETE or DO NOTHING
+	columns   []string
+}
+
+// MergeInto starts a MERGE statement targeting table under the given alias
+func MergeInto(table, alias string) *MergeBuilder {
+	return &MergeBuilder{target: table, targetAlias: alias}
+}
+
+// Using sets a table as the merge source
+func (m *MergeBuilder) Using(table, alias string) *MergeBuilder {
+	m.source = table
+	m.sourceAlias = alias
+	m.sourceArgs = nil
+	return m
+}
+
+// UsingQuery sets a subquery as the merge source
+func (m *MergeBuilder) UsingQuery(query SubqueryBuilder, alias string) *MergeBuilder {
+	sql, args := query.Build()
+	m.source = "(" + sql + ")"
+	m.sourceAlias = alias
+	m.sourceArgs = args
+	return m
+}
+
+// On sets the join condition between target and source
+func (m *MergeBuilder) On(condition string, args ...interface{}) *MergeBuilder {
+	m.on = condition
+	m.onArgs = args
+	return m
+}
+
+// WhenMatchedUpdate copies the given columns from the source into matched rows.
+// With no columns, every source column except the join keys is updated (MergeFrom only).
+func (m *MergeBuilder) WhenMatchedUpdate(condition string, columns ...string) *MergeBuilder {
+	return m.when("MATCHED", condition, "UPDATE", columns)
+}
+
+// WhenMatchedDelete deletes matched rows
+func (m *MergeBuilder) WhenMatchedDelete(condition string) *MergeBuilder {
+	return m.when("MATCHED", condition, "DELETE", nil)
+}
+
+// WhenMatchedDoNothing leaves matched rows unchanged
+func (m *MergeBuilder) WhenMatchedDoNothing(condition string) *MergeBuilder {
+	return m.when("MATCHED", condition, "DO NOTHING", nil)
+}
+
+// WhenNotMatchedInsert inserts source rows without a match.
+// With no columns, every source column is inserted (MergeFrom only).
+func (m *MergeBuilder) WhenNotMatchedInsert(condition string, columns ...string) *MergeBuilder {
+	return m.when("NOT MATCHED", condition, "INSERT", columns)
+}
+
+// WhenNotMatchedBySourceDelete deletes target rows missing from the source.
+// Requires PostgreSQL 17 or later.
+func (m *MergeBuilder) WhenNotMatchedBySourceDelete(condition string) *MergeBuilder {
+	return m.when("NOT MATCHED BY SOURCE", condition, "DELETE", nil)
+}
+
+func (m *MergeBuilder) when(match, condition, action string, columns []string) *MergeBuilder {
+	m.clauses = append(m.clauses, mergeClause{
+		match:     match,
+		condition: condition,
+		action:    action,
+		columns:   columns,
+	})
+	return m
+}
+
+// MinServerVersion returns the lowest server_version_num able to run the statement
+func (m *MergeBuilder) MinServerVersion() int {
+	for _, clause := range m.clauses {
+		if clause.match == "NOT MATCHED BY SOURCE" {
+			return mergeBySourceMinVersion
+		}
+	}
+	return mergeMinVersion
+}
+
+// Validate checks that the statement is complete
+func (m *MergeBuilder) Validate() error {
+	if m.target == "" || m.source == "" || m.on == "" {
+		return fmt.Errorf("%w: MERGE requires a target, a source and an ON condition", ErrInvalidInput)
+	}
+	if len(m.clauses) == 0 {
+		return fmt.Errorf("%w: MERGE requires at least one WHEN clause", ErrInvalidInput)
+	}
+	for _, clause := range m.clauses {
+		if (clause.action == "UPDATE" || clause.action == "INSERT") && len(m.clauseColumns(clause)) == 0 {
+			return fmt.Errorf("%w: MERGE %s requires columns", ErrInvalidInput, clause.action)
+		}
+	}
+	return nil
+}
+
+// Build renders the MERGE statement and its arguments.
+// Source arguments come first, followed by the ON condition arguments.
+func (m *MergeBuilder) Build() (string, []interface{}) {
+	var sb strings.Builder
+	args := append([]interface{}{}, m.sourceArgs...)
+
+	sb.WriteString("MERGE INTO ")
+	sb.WriteString(m.target)
+	if m.targetAlias != "" {
+		sb.WriteString(" AS ")
+		sb.WriteString(m.targetAlias)
+	}
+
+	sb.WriteString(" USING ")
+	sb.WriteString(m.source)
+	if m.sourceAlias != "" {
+		sb.WriteString(" AS ")
+		sb.WriteString(m.sourceAlias)
+	}
+
+	sb.WriteString(" ON ")
+	sb.WriteString(RenumberPlaceholders(m.on, len(args)+1))
+	args = append(args, m.onArgs...)
+
+	for _, clause := range m.clauses {
+		sb.WriteString(" WHEN ")
+		sb.WriteString(clause.match)
+		if clause.condition != "" {
+			sb.WriteString(" AND ")
+			sb.WriteString(clause.condition)
+		}
+		sb.WriteString(" THEN ")
+		sb.WriteString(m.clauseAction(clause))
+	}
+
+	return sb.String(), args
+}
+
+// clauseAction renders the THEN part of a WHEN clause
+func (m *MergeBuilder) clauseAction(clause mergeClause) string {
+	columns := m.clauseColumns(clause)
+
+	switch clause.action {
+	case "UPDATE":
+		sets := make([]string, 0, len(columns)+len(m.autoNow))
+		for _, col := range columns {
+			sets = append(sets, fmt.Sprintf("%s = %s", col, m.sourceColumn(col)))
+		}
+		now := m.now
+		if now == "" {
+			now = "NOW()"
+		}
+		for _, col := range m.autoNow {
+			sets = append(sets, fmt.Sprintf("%s = %s", col, now))
+		}
+		return "UPDATE SET " + strings.Join(sets, ", ")
+	case "INSERT":
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = m.sourceColumn(col)
+		}
+		return fmt.Sprintf("INSERT (%s) VALUES (%s)", strings.Join(columns, ", "), strings.Join(values, ", "))
+	default:
+		return clause.action
+	}
+}
+
+// clauseColumns returns the explicit columns of a clause or the MergeFrom defaults
+func (m *MergeBuilder) clauseColumns(clause mergeClause) []string {
+	if len(clause.columns) > 0 {
+		return clause.columns
+	}
+
+	switch clause.action {
+	case "INSERT":
+		return m.columns
+	case "UPDATE":
+		fixed := make(map[string]bool, len(m.fixed))
+		for _, col := range m.fixed {
+			fixed[col] = true
+		}
+		columns := make([]string, 0, len(m.columns))
+		for _, col := range m.columns {
+			if !fixed[col] {
+				columns = append(columns, col)
+			}
+		}
+		return columns
+	}
+	return nil
+}
+
+// sourceColumn qualifies col with the source alias. Columns some source rows
+// leave to their default fall back to it, as they are NULL in those rows.
+func (m *MergeBuilder) sourceColumn(col string) string {
+	qualified := col
+	if m.sourceAlias != "" {
+		qualified = m.sourceAlias + "." + col
+	}
+	if def, ok := m.defaults[col]; ok {
+		return fmt.Sprintf("COALESCE(%s, %s)", qualified, def)
+	}
+	return qualified
+}
+
+// MergeFrom starts a MERGE into the repository table using entities as the source,
+// matched on the given key columns. The target is aliased "t" and the source "s".
+// Entities are sent as a single JSONB parameter and expanded with
+// jsonb_populate_recordset, so source columns keep the table's column types.
+func (r *BaseRepository[T, ID]) MergeFrom(entities []*T, keys ...string) (*MergeBuilder, error) {
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("%w: no entities to merge", ErrInvalidInput)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: merge keys are required", ErrInvalidInput)
+	}
+	if err := r.validateColumns(keys); err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if err := r.validateEnums(entity); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unset uuid:db keys and nil pointers with a default tag are left out of
+	// their records, so they are NULL in the source and the insert falls back
+	// to the column default
+	columns, rows, unset := r.insertRows(entities)
+	records := make([]map[string]interface{}, len(entities))
+	defaults := make(map[string]string)
+	for i := range entities {
+		record := make(map[string]interface{}, len(columns))
+		for j, col := range columns {
+			if unset[i][j] {
+				defaults[col] = r.columnDefault(col)
+				continue
+			}
+			record[col] = rows[i][j]
+		}
+		records[i] = record
+	}
+
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merge source: %w", err)
+	}
+
+	on := make([]string, len(keys))
+	for i, key := range keys {
+		on[i] = fmt.Sprintf("t.%s = s.%s", key, key)
+	}
+
+	// Auto timestamps are in the source when a clock is configured; matched
+	// rows keep their key and creation time and refresh auto_now columns
+	var autoNow []string
+	fixed := append([]string{r.pkField}, keys...)
+	for _, fieldMeta := range r.entity.Fields {
+		if fieldMeta.AutoNow {
+			autoNow = append(autoNow, fieldMeta.DBName)
+		}
+		if fieldMeta.AutoNow || fieldMeta.AutoNowAdd {
+			fixed = append(fixed, fieldMeta.DBName)
+		}
+	}
+
+	m := MergeInto(r.tableName, "t")
+	m.source = fmt.Sprintf("jsonb_populate_recordset(NULL::%s, $1::jsonb)", r.tableName)
+	m.sourceAlias = "s"
+	m.sourceArgs = []interface{}{string(payload)}
+	m.on = strings.Join(on, " AND ")
+	m.columns = columns
+	m.fixed = fixed
+	m.defaults = defaults
+	m.autoNow = autoNow
+	m.now = r.nowSQL()
+
+	return m, nil
+}
+
+// Merge executes a MERGE statement and returns the number of affected rows.
+// It returns ErrMergeUnsupported when the server is older than the statement requires.
+func (r *BaseRepository[T, ID]) Merge(ctx context.Context, m *MergeBuilder) (int64, error) {
+	if err := m.Validate(); err != nil {
+		return 0, err
+	}
+
+	version, err := r.db.ServerVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if required := m.MinServerVersion(); version < required {
+		return 0, fmt.Errorf("%w: server version %d, requires %d", ErrMergeUnsupported, version, required)
+	}
+
+	query, args := m.Build()
+	return r.execStatement(ctx, query, args...)
+}
+
+// columnDefault returns the SQL of a column's default, as the schema
+// generator declares it
+func (r *BaseRepository[T, ID]) columnDefault(col string) string {
+	fieldMeta := r.lookupField(col)
+	switch {
+	case fieldMeta == nil:
+		return "NULL"
+	case fieldMeta.Default != "":
+		return fieldMeta.Default
+	case fieldMeta.UUID == UUIDDatabase:
+		return "gen_random_uuid()"
+	}
+	return "NULL"
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMergeBuilder(t *testing.T) {
+	t.Run("should build reconcile statement", func(t *testing.T) {
+		query, args := MergeInto("users", "t").
+			UsingQuery(stubSubquery{"SELECT * FROM staging WHERE batch = $1", []interface{}{7}}, "s").
+			On("t.email = s.email AND t.tenant = $1", 3).
+			WhenMatchedDelete("s.deleted").
+			WhenMatchedUpdate("", "username", "age").
+			WhenNotMatchedInsert("", "email", "username").
+			Build()
+
+		expected := "MERGE INTO users AS t USING (SELECT * FROM staging WHERE batch = $1) AS s" +
+			" ON t.email = s.email AND t.tenant = $2" +
+			" WHEN MATCHED AND s.deleted THEN DELETE" +
+			" WHEN MATCHED THEN UPDATE SET username = s.username, age = s.age" +
+			" WHEN NOT MATCHED THEN INSERT (email, username) VALUES (s.email, s.username)"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 2 || args[0] != 7 || args[1] != 3 {
+			t.Errorf("Expected args [7 3], got %v", args)
+		}
+	})
+
+	t.Run("should require newer server for stale deletes", func(t *testing.T) {
+		m := MergeInto("users", "t").Using("staging", "s").On("t.id = s.id").WhenMatchedDelete("")
+		if got := m.MinServerVersion(); got != 150000 {
+			t.Errorf("Expected 150000, got %d", got)
+		}
+
+		m.WhenNotMatchedBySourceDelete("")
+		if got := m.MinServerVersion(); got != 170000 {
+			t.Errorf("Expected 170000, got %d", got)
+		}
+	})
+
+	t.Run("should validate incomplete statements", func(t *testing.T) {
+		if err := MergeInto("users", "t").Using("staging", "s").On("t.id = s.id").Validate(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput without WHEN clauses, got %v", err)
+		}
+
+		m := MergeInto("users", "t").Using("staging", "s").On("t.id = s.id").WhenMatchedUpdate("")
+		if err := m.Validate(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for UPDATE without columns, got %v", err)
+		}
+	})
+}
+
+func TestBaseRepository_MergeFrom(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	users := []*TestUser{{Email: "a@example.com", Username: "a", Age: 30}}
+
+	m, err := repo.MergeFrom(users, "email")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, args := m.WhenMatchedUpdate("").WhenNotMatchedInsert("").Build()
+
+	expected := "MERGE INTO test_user AS t USING jsonb_populate_recordset(NULL::test_user, $1::jsonb) AS s" +
+		" ON t.email = s.email" +
+		" WHEN MATCHED THEN UPDATE SET username = s.username, age = s.age, updated_at = NOW()" +
+		" WHEN NOT MATCHED THEN INSERT (email, username, age) VALUES (s.email, s.username, s.age)"
+	if query != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, query)
+	}
+
+	payload := `[{"age":30,"email":"a@example.com","username":"a"}]`
+	if len(args) != 1 || args[0] != payload {
+		t.Errorf("Expected payload %s, got %v", payload, args)
+	}
+
+	if _, err := repo.MergeFrom(users, "nickname"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected ErrUnknownField, got %v", err)
+	}
+
+	t.Run("should fall back to column defaults in mixed batches", func(t *testing.T) {
+		coupons, err := NewBaseRepository[TestCoupon, uuid.UUID](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		id := uuid.MustParse("00000000-0000-4000-8000-000000000007")
+		uses := int64(3)
+		m, err := coupons.MergeFrom([]*TestCoupon{{Code: "A"}, {ID: id, Code: "B", UsesLeft: &uses}}, "code")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		query, args := m.WhenMatchedUpdate("").WhenNotMatchedInsert("").Build()
+
+		expected := "MERGE INTO test_coupon AS t USING jsonb_populate_recordset(NULL::test_coupon, $1::jsonb) AS s" +
+			" ON t.code = s.code" +
+			" WHEN MATCHED THEN UPDATE SET uses_left = COALESCE(s.uses_left, 10)" +
+			" WHEN NOT MATCHED THEN INSERT (id, code, uses_left)" +
+			" VALUES (COALESCE(s.id, gen_random_uuid()), s.code, COALESCE(s.uses_left, 10))"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+
+		payload := `[{"code":"A"},{"code":"B","id":"00000000-0000-4000-8000-000000000007","uses_left":3}]`
+		if len(args) != 1 || args[0] != payload {
+			t.Errorf("Expected payload %s, got %v", payload, args)
+		}
+	})
+
+	t.Run("should keep auto timestamps out of the update under a clock", func(t *testing.T) {
+		m, err := repo.WithTestMode().MergeFrom(users, "email")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		query, _ := m.WhenMatchedUpdate("").WhenNotMatchedInsert("").Build()
+
+		expected := "MERGE INTO test_user AS t USING jsonb_populate_recordset(NULL::test_user, $1::jsonb) AS s" +
+			" ON t.email = s.email" +
+			" WHEN MATCHED THEN UPDATE SET username = s.username, age = s.age, updated_at = '2000-01-01T00:00:00Z'::timestamptz" +
+			" WHEN NOT MATCHED THEN INSERT (id, email, username, age, created_at, updated_at)" +
+			" VALUES (s.id, s.email, s.username, s.age, s.created_at, s.updated_at)"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/satishbabariya/jetorm/migration"
+)
+
+// migrateOnStart applies the pending migrations in config.MigrateOnStart.
+// The advisory lock lets several instances start at once while only one
+// applies each migration.
+func (db *Database) migrateOnStart(ctx context.Context) error {
+	sqlDB := stdlib.OpenDBFromPool(db.pool)
+	defer sqlDB.Close()
+
+	runner := migration.NewRunnerFS(sqlDB, db.config.MigrateOnStart)
+	if db.config.MigrationTable != "" {
+		runner.SetTableName(db.config.MigrationTable)
+	}
+	runner.SetEventSink(migrationLogger{logger: db.logger})
+
+	return runner.UpLocked(ctx, migration.DefaultLockKey)
+}
+
+// migrationLogger reports migration progress to the database logger
+type migrationLogger struct {
+	logger Logger
+}
+
+func (l migrationLogger) OnMigrationStart(ctx context.Context, event migration.MigrationEvent) {
+	l.logger.Debug("applying migration", "version", event.Version, "name", event.Name)
+}
+
+func (l migrationLogger) OnMigrationApplied(ctx context.Context, event migration.MigrationEvent) {
+	l.logger.Info("migration applied", "version", event.Version, "name", event.Name, "duration", event.Duration)
+}
+
+func (l migrationLogger) OnMigrationFailed(ctx context.Context, event migration.MigrationEvent, err error) {
+	l.logger.Error("migration failed", "version", event.Version, "name", event.Name, "duration", event.Duration, "error", err)
+}
+
+func (l migrationLogger) OnMigrationWarning(ctx context.Context, event migration.MigrationEvent, message string) {
+	l.logger.Warn("migration warning", "version", event.Version, "name", event.Name, "warning", message)
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// TestConnect_MigrateOnStart applies embedded migrations from several
+// concurrent connections. Set JETORM_TEST_DATABASE_URL to run it.
+func TestConnect_MigrateOnStart(t *testing.T) {
+	url := os.Getenv("JETORM_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("JETORM_TEST_DATABASE_URL not set")
+	}
+
+	migrations := fstest.MapFS{
+		"20240101000000_create_widgets.up.sql":   {Data: []byte("CREATE TABLE jetorm_test_widgets (id BIGSERIAL PRIMARY KEY)")},
+		"20240101000000_create_widgets.down.sql": {Data: []byte("DROP TABLE jetorm_test_widgets")},
+	}
+	withMigrations := func(c *Config) {
+		c.MigrateOnStart = migrations
+		c.MigrationTable = "jetorm_test_migrations"
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := ConnectURL(url, withMigrations)
+			if err != nil {
+				errs <- err
+				return
+			}
+			db.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed to connect with migrations: %v", err)
+	}
+
+	db, err := ConnectURL(url)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	defer db.Pool().Exec(ctx, "DROP TABLE IF EXISTS jetorm_test_widgets, jetorm_test_migrations")
+
+	var applied int
+	if err := db.Pool().QueryRow(ctx, "SELECT COUNT(*) FROM jetorm_test_migrations").Scan(&applied); err != nil {
+		t.Fatalf("Failed to read migration table: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Expected 1 applied migration, got %d", applied)
+	}
+	if _, err := db.Pool().Exec(ctx, "SELECT 1 FROM jetorm_test_widgets"); err != nil {
+		t.Errorf("Expected migrated table: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BindNamed rewrites the :name placeholders of sql to positional $n
+// placeholders and returns the matching arguments. params is a map with
+// string keys or a struct (or pointer to one), whose fields are matched by
+// their db tag or Go name; fields of embedded structs are included. A name
+// used several times binds a single argument. Casts (::type) and text inside
+// literals, quoted identifiers, comments and dollar-quoted strings are left
+// untouched. Names without a value fail with ErrInvalidInput.
+func BindNamed(sql string, params interface{}) (string, []interface{}, error) {
+	lookup, err := namedLookup(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []interface{}
+	positions := make(map[string]int)
+	var missing []string
+	bound := walkSQL(sql, func(b *strings.Builder, i int) int {
+		if sql[i] != ':' || i+1 >= len(sql) || !isIdentStart(sql[i+1]) || (i > 0 && sql[i-1] == ':') {
+			return i
+		}
+		j := i + 1
+		for j < len(sql) && isIdentByte(sql[j]) && sql[j] != '$' {
+			j++
+		}
+		name := sql[i+1 : j]
+		n, ok := positions[name]
+		if !ok {
+			value, found := lookup(name)
+			if !found {
+				missing = append(missing, name)
+			}
+			args = append(args, value)
+			n = len(args)
+			positions[name] = n
+		}
+		b.WriteString("$" + strconv.Itoa(n))
+		return j
+	})
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("%w: no value for named parameters :%s", ErrInvalidInput, strings.Join(missing, ", :"))
+	}
+	return bound, args, nil
+}
+
+// QueryNamed executes a raw SQL query with :name placeholders, see BindNamed
+func (r *BaseRepository[T, ID]) QueryNamed(ctx context.Context, query string, params interface{}) ([]*T, error) {
+	bound, args, err := BindNamed(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return r.Query(ctx, bound, args...)
+}
+
+// QueryOneNamed executes a raw SQL query with :name placeholders and returns
+// a single result
+func (r *BaseRepository[T, ID]) QueryOneNamed(ctx context.Context, query string, params interface{}) (*T, error) {
+	bound, args, err := BindNamed(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return r.QueryOne(ctx, bound, args...)
+}
+
+// ExecNamed executes a raw SQL statement with :name placeholders and returns
+// the number of rows affected
+func (r *BaseRepository[T, ID]) ExecNamed(ctx context.Context, query string, params interface{}) (int64, error) {
+	bound, args, err := BindNamed(query, params)
+	if err != nil {
+		return 0, err
+	}
+	return r.Exec(ctx, bound, args...)
+}
+
+// namedLookup returns a function resolving parameter names against params
+func namedLookup(params interface{}) (func(name string) (interface{}, bool), error) {
+	if params == nil {
+		return func(string) (interface{}, bool) { return nil, false }, nil
+	}
+	if m, ok := params.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			value, ok := m[name]
+			return value, ok
+		}, nil
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("%w: named parameters are a nil %s", ErrInvalidInput, v.Type())
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return func(name string) (interface{}, bool) {
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			return value.Interface(), true
+		}, nil
+	case v.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Value)
+		collectNamedFields(v, fields)
+		return func(name string) (interface{}, bool) {
+			field, ok := fields[name]
+			if !ok {
+				return nil, false
+			}
+			return field.Interface(), true
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: named parameters must be a map or struct, got %s", ErrInvalidInput, v.Type())
+}
+
+// collectNamedFields indexes the exported fields of a struct by db tag and
+// Go name. Fields of the outer struct win over those of embedded structs.
+func collectNamedFields(v reflect.Value, fields map[string]reflect.Value) {
+	t := v.Type()
+	var embedded []reflect.Value
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				embedded = append(embedded, fv)
+				continue
+			}
+		}
+		if tag != "" {
+			fields[tag] = v.Field(i)
+		}
+		if _, ok := fields[field.Name]; !ok {
+			fields[field.Name] = v.Field(i)
+		}
+	}
+	for _, ev := range embedded {
+		inner := make(map[string]reflect.Value)
+		collectNamedFields(ev, inner)
+		for name, fv := range inner {
+			if _, ok := fields[name]; !ok {
+				fields[name] = fv
+			}
+		}
+	}
+}
+
+// isIdentStart reports whether b can start an unquoted SQL identifier
+func isIdentStart(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	t.Run("should bind a map and reuse positions for repeated names", func(t *testing.T) {
+		sql, args, err := BindNamed(
+			"SELECT * FROM users WHERE (email = :email OR backup_email = :email) AND age >= :min_age",
+			map[string]interface{}{"email": "a@example.com", "min_age": 18},
+		)
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+		expected := "SELECT * FROM users WHERE (email = $1 OR backup_email = $1) AND age >= $2"
+		if sql != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, sql)
+		}
+		if len(args) != 2 || args[0] != "a@example.com" || args[1] != 18 {
+			t.Errorf("Expected [a@example.com 18], got %v", args)
+		}
+	})
+
+	t.Run("should leave casts, literals and comments alone", func(t *testing.T) {
+		sql, args, err := BindNamed(
+			"SELECT ':skip', \"a:b\", created_at::date /* :note */ FROM t WHERE id = :id::bigint -- :tail",
+			map[string]interface{}{"id": 7},
+		)
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+		expected := "SELECT ':skip', \"a:b\", created_at::date /* :note */ FROM t WHERE id = $1::bigint -- :tail"
+		if sql != expected || len(args) != 1 {
+			t.Errorf("Expected '%s', got '%s' %v", expected, sql, args)
+		}
+	})
+
+	t.Run("should bind struct fields by db tag or name", func(t *testing.T) {
+		type Audit struct {
+			Actor string `db:"actor"`
+		}
+		type Filter struct {
+			Audit
+			Email  string `db:"email"`
+			MinAge int
+			Secret string `db:"-"`
+		}
+
+		sql, args, err := BindNamed("email = :email AND age >= :MinAge AND actor = :actor",
+			&Filter{Audit: Audit{Actor: "ops"}, Email: "a@example.com", MinAge: 21})
+		if err != nil {
+			t.Fatalf("Failed to bind: %v", err)
+		}
+		if sql != "email = $1 AND age >= $2 AND actor = $3" {
+			t.Errorf("Unexpected SQL '%s'", sql)
+		}
+		if len(args) != 3 || args[0] != "a@example.com" || args[1] != 21 || args[2] != "ops" {
+			t.Errorf("Expected [a@example.com 21 ops], got %v", args)
+		}
+
+		if _, _, err := BindNamed("secret = :Secret", Filter{}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected db:\"-\" fields to be skipped, got %v", err)
+		}
+	})
+
+	t.Run("should report every missing name", func(t *testing.T) {
+		_, _, err := BindNamed("a = :a AND b = :b AND c = :c", map[string]interface{}{"b": 1})
+		if !errors.Is(err, ErrInvalidInput) || !contains(err.Error(), ":a, :c") {
+			t.Errorf("Expected missing :a and :c, got %v", err)
+		}
+		if _, _, err := BindNamed("a = :a", 42); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for unsupported params, got %v", err)
+		}
+	})
+
+	t.Run("should run bound statements through the raw methods", func(t *testing.T) {
+		repo, err := NewBaseRepository[TestUser, int64](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		ctx, capture := (&Database{}).DryRun(context.Background())
+
+		if _, err := repo.ExecNamed(ctx, "UPDATE test_user SET age = :age WHERE id = :id", map[string]interface{}{"id": 1, "age": 30}); err != nil {
+			t.Fatalf("Failed to exec: %v", err)
+		}
+		statements := capture.Statements()
+		if len(statements) != 1 || statements[0].SQL != "UPDATE test_user SET age = $1 WHERE id = $2" {
+			t.Fatalf("Unexpected statements %v", statements)
+		}
+		if statements[0].Args[0] != 30 || statements[0].Args[1] != 1 {
+			t.Errorf("Expected [30 1], got %v", statements[0].Args)
+		}
+	})
+}
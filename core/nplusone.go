@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// nPlusOneSuggestion is logged with every N+1 warning
+const nPlusOneSuggestion = "load the rows at once with FindAllByIDs, FindMapByIDs or EagerLoad"
+
+// nPlusOneStackDepth is the number of caller frames a warning reports
+const nPlusOneStackDepth = 8
+
+// NPlusOneWarning describes a single-row query repeated within one context,
+// typically a FindByID made for each element of a list
+type NPlusOneWarning struct {
+	SQL   string // The repeated statement
+	Count int    // Times it ran in the context when the warning was raised
+	Stack string // Callers of the query that crossed the threshold, outside jetorm
+}
+
+// NPlusOneReporter receives N+1 warnings instead of the database's logger
+type NPlusOneReporter func(ctx context.Context, warning NPlusOneWarning)
+
+// nPlusOneKey is the context key for the N+1 detection state
+type nPlusOneKey struct{}
+
+// nPlusOneState counts the single-row queries of one context
+type nPlusOneState struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// WithNPlusOneDetection returns a context in which repositories of a
+// Database configured with NPlusOneThreshold count their single-row SELECTs.
+// When the same statement runs NPlusOneThreshold times within the context,
+// one warning is logged for it with the caller's stack. Scope the context
+// to a request, as NPlusOneMiddleware does; detection is meant for
+// development, as it costs a stack trace per warning and a lock per query.
+func WithNPlusOneDetection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nPlusOneKey{}, &nPlusOneState{counts: make(map[string]int)})
+}
+
+// NPlusOneMiddleware scopes N+1 detection to each HTTP request
+func NPlusOneMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(WithNPlusOneDetection(req.Context())))
+	})
+}
+
+// count records a run of sql and reports whether it reached the threshold
+func (s *nPlusOneState) count(sql string, threshold int) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[sql]++
+	return s.counts[sql], s.counts[sql] == threshold
+}
+
+// nPlusOneQuerier counts the single-row SELECTs run in a detection context
+// and warns about those repeated Config.NPlusOneThreshold times
+type nPlusOneQuerier struct {
+	querier
+	db *Database
+}
+
+// QueryRow runs sql, warning when the context has run it too often
+func (n nPlusOneQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if state, ok := ctx.Value(nPlusOneKey{}).(*nPlusOneState); ok && firstKeyword(sql) == "SELECT" {
+		if count, reached := state.count(sql, n.db.config.NPlusOneThreshold); reached {
+			n.db.reportNPlusOne(ctx, NPlusOneWarning{SQL: sql, Count: count, Stack: callerStack()})
+		}
+	}
+	return n.querier.QueryRow(ctx, sql, args...)
+}
+
+// reportNPlusOne passes a warning to Config.NPlusOneReporter, or logs it
+func (db *Database) reportNPlusOne(ctx context.Context, warning NPlusOneWarning) {
+	if db.config.NPlusOneReporter != nil {
+		db.config.NPlusOneReporter(ctx, warning)
+		return
+	}
+	db.logger.Warn("possible N+1 query", "query", warning.SQL, "count", warning.Count,
+		"suggestion", nPlusOneSuggestion, "stack", warning.Stack)
+}
+
+// callerStack formats the first frames of the current goroutine's stack
+// outside jetorm's core package
+func callerStack() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	var sb strings.Builder
+	for depth := 0; depth < nPlusOneStackDepth; {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, "github.com/satishbabariya/jetorm/core.") &&
+			!strings.HasSuffix(frame.File, "_test.go")
+		if !internal && frame.Function != "" {
+			fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			depth++
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNPlusOneQuerier(t *testing.T) {
+	var warnings []NPlusOneWarning
+	db := &Database{config: Config{
+		NPlusOneThreshold: 3,
+		NPlusOneReporter: func(ctx context.Context, w NPlusOneWarning) {
+			warnings = append(warnings, w)
+		},
+	}}
+	_, capture := db.DryRun(context.Background())
+	q := nPlusOneQuerier{querier: capture, db: db}
+	findByID := "SELECT * FROM test_user WHERE id = $1"
+
+	t.Run("should warn once when a statement reaches the threshold", func(t *testing.T) {
+		warnings = nil
+		ctx := WithNPlusOneDetection(context.Background())
+		for i := 0; i < 5; i++ {
+			q.QueryRow(ctx, findByID, i)
+		}
+		q.QueryRow(ctx, "SELECT COUNT(*) FROM test_user")
+		if len(warnings) != 1 {
+			t.Fatalf("Expected 1 warning, got %d", len(warnings))
+		}
+		if warnings[0].SQL != findByID || warnings[0].Count != 3 {
+			t.Errorf("Unexpected warning %+v", warnings[0])
+		}
+		if !strings.Contains(warnings[0].Stack, "TestNPlusOneQuerier") {
+			t.Errorf("Expected the stack to name the caller, got %q", warnings[0].Stack)
+		}
+	})
+
+	t.Run("should not count outside a detection context", func(t *testing.T) {
+		warnings = nil
+		for i := 0; i < 5; i++ {
+			q.QueryRow(context.Background(), findByID, i)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("Expected no warnings, got %d", len(warnings))
+		}
+	})
+
+	t.Run("should count each request separately", func(t *testing.T) {
+		warnings = nil
+		handler := NPlusOneMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			q.QueryRow(req.Context(), findByID, 1)
+			q.QueryRow(req.Context(), findByID, 2)
+		}))
+		for i := 0; i < 2; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}
+		if len(warnings) != 0 {
+			t.Errorf("Expected no warnings, got %d", len(warnings))
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// isNilPointer reports whether v is a nil pointer, which is written as SQL NULL
+func isNilPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// scansThroughPointer reports whether a field holds a plain value, such as a
+// string, number, bool or time.Time, that cannot represent NULL. Such fields
+// are scanned through a pointer so that NULL leaves the zero value instead of
+// failing the scan. Pointers, slices, maps and sql.Null* style types handle
+// NULL themselves, and not_null columns never return it.
+func (f *Field) scansThroughPointer() bool {
+	if f.JSON || f.PrimaryKey || f.NotNull {
+		return false
+	}
+	switch f.Type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return false
+	}
+	return !hasCustomCodec(f.Type)
+}
+
+// nullTarget is a pointer scanned in place of a plain value field
+type nullTarget struct {
+	ptr   reflect.Value // **T passed to the driver
+	field reflect.Value
+}
+
+// assign stores the scanned value, or the zero value for NULL, in the field
+func (n nullTarget) assign() {
+	if scanned := n.ptr.Elem(); scanned.IsNil() {
+		n.field.SetZero()
+	} else {
+		n.field.Set(scanned.Elem())
+	}
+}
+
+// rowTargets holds the destinations for scanning one row into an entity
+type rowTargets struct {
+	dest  []interface{}
+	nulls []nullTarget
+}
+
+// add appends the scan destination for a field of entity
+func (t *rowTargets) add(f *Field, entity reflect.Value) {
+	if !f.scansThroughPointer() {
+		t.dest = append(t.dest, f.scanTarget(entity))
+		return
+	}
+	ptr := reflect.New(reflect.PointerTo(f.Type))
+	t.nulls = append(t.nulls, nullTarget{ptr: ptr, field: f.valueOf(entity)})
+	t.dest = append(t.dest, ptr.Interface())
+}
+
+// scan scans row and copies values scanned through pointers into the entity
+func (t *rowTargets) scan(row pgx.Row) error {
+	if err := row.Scan(t.dest...); err != nil {
+		return err
+	}
+	for _, n := range t.nulls {
+		n.assign()
+	}
+	return nil
+}
+
+// columnIndex maps the names of the result columns of rows to their positions
+func columnIndex(rows pgx.Rows) map[string]int {
+	descriptions := rows.FieldDescriptions()
+	index := make(map[string]int, len(descriptions))
+	for i, d := range descriptions {
+		index[d.Name] = i
+	}
+	return index
+}
+
+// namedFields are fields of a struct value scanned from the result columns
+// named prefix followed by their column name
+type namedFields struct {
+	fields []Field
+	v      reflect.Value
+	prefix string
+}
+
+// scanByName scans the current row into fields matched to result columns by
+// name. Fields whose column was not read keep their value.
+func scanByName(rows pgx.Rows, index map[string]int, parts ...namedFields) error {
+	targets := rowTargets{}
+	var positions []int
+	for _, part := range parts {
+		for i := range part.fields {
+			f := &part.fields[i]
+			position, ok := index[part.prefix+f.DBName]
+			if f.Ignored || !ok {
+				continue
+			}
+			targets.add(f, part.v)
+			positions = append(positions, position)
+		}
+	}
+
+	dest := make([]interface{}, len(rows.FieldDescriptions()))
+	for i, position := range positions {
+		dest[position] = targets.dest[i]
+	}
+	targets.dest = dest
+	return targets.scan(rows)
+}
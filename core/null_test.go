@@ -0,0 +1,108 @@
+package core
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestContact has nullable columns held in pointers, sql.Null* types and
+// plain values
+type TestContact struct {
+	ID        int64          `db:"id" jet:"primary_key,auto_increment"`
+	Name      string         `db:"name" jet:"not_null"`
+	Nickname  *string        `db:"nickname"`
+	Phone     sql.NullString `db:"phone"`
+	Age       int            `db:"age"`
+	Score     *int64         `db:"score" jet:"default:0"`
+	LastSeen  time.Time      `db:"last_seen"`
+	DeletedBy *int64         `db:"deleted_by"`
+}
+
+func TestBaseRepository_NullHandling(t *testing.T) {
+	repo, err := NewBaseRepository[TestContact, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should write nil pointers and invalid sql.Null values as NULL", func(t *testing.T) {
+		query, args := repo.updateStatement(&TestContact{ID: 1, Name: "Ada"})
+		if !strings.Contains(query, "nickname = $2") || !strings.Contains(query, "score = $5") {
+			t.Fatalf("Unexpected query '%s'", query)
+		}
+		if args[1] != nil {
+			t.Errorf("Expected nil nickname, got %#v", args[1])
+		}
+		if phone, ok := args[2].(sql.NullString); !ok || phone.Valid {
+			t.Errorf("Expected invalid sql.NullString, got %#v", args[2])
+		}
+		if args[4] != nil || args[6] != nil {
+			t.Errorf("Expected nil score and deleted_by, got %#v and %#v", args[4], args[6])
+		}
+	})
+
+	t.Run("should pass set pointers to the driver", func(t *testing.T) {
+		nickname := "ada"
+		_, args := repo.updateStatement(&TestContact{ID: 1, Nickname: &nickname})
+		if p, ok := args[1].(*string); !ok || *p != "ada" {
+			t.Errorf("Expected *string 'ada', got %#v", args[1])
+		}
+	})
+
+	t.Run("should leave unset columns with a default to the database", func(t *testing.T) {
+		query, args := repo.insertStatement(&TestContact{Name: "Ada"})
+		if strings.Contains(query[:strings.Index(query, "VALUES")], "score") {
+			t.Errorf("Expected score to be omitted, got '%s'", query)
+		}
+		if len(args) != 6 {
+			t.Errorf("Expected 6 args, got %d", len(args))
+		}
+
+		score := int64(5)
+		query, _ = repo.insertStatement(&TestContact{Name: "Ada", Score: &score})
+		if !strings.Contains(query, "score") {
+			t.Errorf("Expected score to be inserted, got '%s'", query)
+		}
+	})
+
+	t.Run("should scan NULL into pointers, sql.Null and plain fields", func(t *testing.T) {
+		contact := &TestContact{Age: 99, LastSeen: time.Now(), Phone: sql.NullString{String: "x", Valid: true}}
+		row := fakeRow{int64(3), "Ada", nil, nil, nil, int64(7), nil, nil}
+		if err := repo.scanRow(row, contact); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+
+		if contact.Nickname != nil || contact.DeletedBy != nil {
+			t.Errorf("Expected nil pointers, got %v and %v", contact.Nickname, contact.DeletedBy)
+		}
+		if contact.Phone.Valid {
+			t.Errorf("Expected invalid phone, got %+v", contact.Phone)
+		}
+		if contact.Age != 0 || !contact.LastSeen.IsZero() {
+			t.Errorf("Expected zero values for NULL, got %d and %v", contact.Age, contact.LastSeen)
+		}
+		if contact.Score == nil || *contact.Score != 7 {
+			t.Errorf("Expected score 7, got %v", contact.Score)
+		}
+	})
+
+	t.Run("should scan values into plain fields", func(t *testing.T) {
+		seen := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		contact := &TestContact{}
+		row := fakeRow{int64(3), "Ada", "ada", "555-0100", 36, nil, seen, int64(1)}
+		if err := repo.scanRow(row, contact); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+
+		if contact.Age != 36 || !contact.LastSeen.Equal(seen) {
+			t.Errorf("Expected age 36 and last seen %v, got %d and %v", seen, contact.Age, contact.LastSeen)
+		}
+		if !contact.Phone.Valid || contact.Phone.String != "555-0100" {
+			t.Errorf("Expected phone '555-0100', got %+v", contact.Phone)
+		}
+		if contact.Nickname == nil || *contact.Nickname != "ada" {
+			t.Errorf("Expected nickname 'ada', got %v", contact.Nickname)
+		}
+	})
+}
@@ -13,6 +13,7 @@ type QueryCache struct {
 	mu     sync.RWMutex
 	ttl    time.Duration
 	maxSize int
+	clock  Clock
 }
 
 // CacheEntry represents a cached query result
@@ -29,9 +30,17 @@ func NewQueryCache(ttl time.Duration, maxSize int) *QueryCache {
 		cache:   make(map[string]*CacheEntry),
 		ttl:     ttl,
 		maxSize: maxSize,
+		clock:   SystemClock,
 	}
 }
 
+// SetClock sets the clock entries expire by, e.g. a FrozenClock in tests
+func (qc *QueryCache) SetClock(clock Clock) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	qc.clock = clock
+}
+
 // Get retrieves a value from cache
 func (qc *QueryCache) Get(key string) (interface{}, bool) {
 	qc.mu.RLock()
@@ -43,13 +52,14 @@ func (qc *QueryCache) Get(key string) (interface{}, bool) {
 	}
 
 	// Check expiration
-	if time.Now().After(entry.ExpiresAt) {
+	now := qc.clock.Now()
+	if now.After(entry.ExpiresAt) {
 		return nil, false
 	}
 
 	// Update access info
 	entry.AccessCount++
-	entry.LastAccess = time.Now()
+	entry.LastAccess = now
 
 	return entry.Data, true
 }
@@ -64,11 +74,12 @@ func (qc *QueryCache) Set(key string, value interface{}) {
 		qc.evictLRU()
 	}
 
+	now := qc.clock.Now()
 	qc.cache[key] = &CacheEntry{
 		Data:      value,
-		ExpiresAt: time.Now().Add(qc.ttl),
+		ExpiresAt: now.Add(qc.ttl),
 		AccessCount: 1,
-		LastAccess: time.Now(),
+		LastAccess: now,
 	}
 }
 
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// pageLimit returns the LIMIT for a page query. CountNone fetches one extra row
+// to learn whether another page follows without counting.
+func (p Pageable) pageLimit() int {
+	if p.CountMode == CountNone {
+		return p.Size + 1
+	}
+	return p.Size
+}
+
+// newPage assembles a Page from the fetched rows, computing the total as
+// requested by pageable.CountMode
+func (r *BaseRepository[T, ID]) newPage(ctx context.Context, pageable Pageable, spec Specification[T], content []*T) (*Page[T], error) {
+	totalElements := int64(-1)
+	totalPages := -1
+	last := true
+
+	switch pageable.CountMode {
+	case CountNone:
+		if pageable.Size > 0 && len(content) > pageable.Size {
+			content = content[:pageable.Size]
+			last = false
+		}
+	default:
+		var err error
+		if pageable.CountMode == CountEstimated {
+			totalElements, err = r.estimatePageTotal(ctx, pageable, spec, len(content))
+		} else {
+			totalElements, err = r.CountWithSpec(ctx, spec)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		totalPages = 0
+		if pageable.Size > 0 {
+			totalPages = int((totalElements + int64(pageable.Size) - 1) / int64(pageable.Size))
+		}
+		last = pageable.Page >= totalPages-1 || totalPages == 0
+	}
+
+	numberOfElements := len(content)
+
+	return &Page[T]{
+		Content:          content,
+		Pageable:         pageable,
+		TotalElements:    totalElements,
+		TotalPages:       totalPages,
+		Size:             pageable.Size,
+		Number:           pageable.Page,
+		NumberOfElements: numberOfElements,
+		First:            pageable.Page == 0,
+		Last:             last,
+		Empty:            numberOfElements == 0,
+		Sort:             pageable.Sort,
+	}, nil
+}
+
+// estimatePageTotal estimates the total from planner statistics. A short page
+// ends the result set, so its total is exact; otherwise the estimate is raised
+// to at least the rows already seen.
+func (r *BaseRepository[T, ID]) estimatePageTotal(ctx context.Context, pageable Pageable, spec Specification[T], fetched int) (int64, error) {
+	seen := int64(fetched)
+	if pageable.Size > 0 {
+		seen += int64(pageable.Page * pageable.Size)
+		if fetched < pageable.Size && (fetched > 0 || pageable.Page == 0) {
+			return seen, nil
+		}
+	}
+
+	estimate, err := r.EstimateCount(ctx, spec)
+	if err != nil {
+		return 0, err
+	}
+	if estimate < seen {
+		estimate = seen
+	}
+	return estimate, nil
+}
+
+// EstimateCount estimates the number of entities matching the specification
+// without scanning the table. Unfiltered counts use pg_class.reltuples; filtered
+// counts use the planner's row estimate. Tables that have never been analyzed
+// fall back to an exact count.
+func (r *BaseRepository[T, ID]) EstimateCount(ctx context.Context, spec Specification[T]) (int64, error) {
+	var whereClause string
+	var args []interface{}
+	if spec != nil {
+		whereClause, args = spec.ToSQL()
+	}
+	where := r.whereClause(whereClause)
+
+	var query string
+	if where == "" {
+		query = "SELECT reltuples::bigint FROM pg_class WHERE oid = $1::regclass"
+		args = []interface{}{r.tableName}
+	} else {
+		query = fmt.Sprintf("EXPLAIN (FORMAT JSON) SELECT 1 FROM %s%s", r.tableName, where)
+	}
+
+	r.logQuery(query, args)
+
+	var row pgx.Row
+	if r.tx != nil {
+		row = r.txConn(ctx).QueryRow(ctx, query, args...)
+	} else {
+		row = r.poolConn(ctx).QueryRow(ctx, query, args...)
+	}
+
+	var estimate int64
+	if where == "" {
+		if err := row.Scan(&estimate); err != nil {
+			return 0, err
+		}
+	} else {
+		var plan string
+		if err := row.Scan(&plan); err != nil {
+			return 0, err
+		}
+		var err error
+		if estimate, err = parsePlanRows(plan); err != nil {
+			return 0, err
+		}
+	}
+
+	if estimate < 0 {
+		// reltuples is -1 until the table is first vacuumed or analyzed
+		return r.CountWithSpec(ctx, spec)
+	}
+	return estimate, nil
+}
+
+// parsePlanRows reads the top-level "Plan Rows" of an EXPLAIN (FORMAT JSON) result
+func parsePlanRows(plan string) (int64, error) {
+	var plans []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("failed to parse query plan: empty result")
+	}
+	return int64(plans[0].Plan.PlanRows), nil
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPageable_CountMode(t *testing.T) {
+	t.Run("should keep count mode across pages", func(t *testing.T) {
+		pageable := PageRequest(1, 10).WithCountMode(CountEstimated)
+
+		if pageable.Next().CountMode != CountEstimated || pageable.Previous().CountMode != CountEstimated {
+			t.Errorf("Expected CountEstimated to carry over to adjacent pages")
+		}
+		if pageable.pageLimit() != 10 {
+			t.Errorf("Expected limit 10, got %d", pageable.pageLimit())
+		}
+		if limit := pageable.WithCountMode(CountNone).pageLimit(); limit != 11 {
+			t.Errorf("Expected limit 11 with CountNone, got %d", limit)
+		}
+	})
+}
+
+func TestBaseRepository_NewPage(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+
+	rows := func(n int) []*TestUser {
+		content := make([]*TestUser, n)
+		for i := range content {
+			content[i] = &TestUser{ID: int64(i + 1)}
+		}
+		return content
+	}
+
+	t.Run("should detect next page without counting", func(t *testing.T) {
+		pageable := PageRequest(0, 10).WithCountMode(CountNone)
+
+		page, err := repo.newPage(ctx, pageable, nil, rows(11))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if page.NumberOfElements != 10 || page.Last {
+			t.Errorf("Expected 10 elements and more pages, got %d (last=%v)", page.NumberOfElements, page.Last)
+		}
+		if page.TotalElements != -1 || page.TotalPages != -1 {
+			t.Errorf("Expected unknown totals, got %d/%d", page.TotalElements, page.TotalPages)
+		}
+
+		page, _ = repo.newPage(ctx, pageable, nil, rows(4))
+		if !page.Last {
+			t.Errorf("Expected last page")
+		}
+	})
+
+	t.Run("should derive exact total from a short estimated page", func(t *testing.T) {
+		pageable := PageRequest(2, 10).WithCountMode(CountEstimated)
+
+		page, err := repo.newPage(ctx, pageable, nil, rows(4))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if page.TotalElements != 24 || page.TotalPages != 3 || !page.Last {
+			t.Errorf("Expected 24 elements on 3 pages, got %d/%d (last=%v)", page.TotalElements, page.TotalPages, page.Last)
+		}
+	})
+}
+
+func TestParsePlanRows(t *testing.T) {
+	plan := `[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1234, "Plan Width": 4}}]`
+
+	rows, err := parsePlanRows(plan)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rows != 1234 {
+		t.Errorf("Expected 1234, got %d", rows)
+	}
+
+	if _, err := parsePlanRows("[]"); err == nil {
+		t.Errorf("Expected error for empty plan")
+	}
+}
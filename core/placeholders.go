@@ -0,0 +1,160 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RenumberPlaceholders shifts $n placeholders in a $1-based SQL fragment so
+// they start at startNum, for embedding the fragment after other arguments.
+// Placeholder-like text inside string literals, quoted identifiers, comments
+// and dollar-quoted strings is left untouched.
+func RenumberPlaceholders(sql string, startNum int) string {
+	if startNum == 1 {
+		// No renumbering needed
+		return sql
+	}
+
+	offset := startNum - 1
+	return rewritePlaceholders(sql, func(n int) string {
+		return "$" + strconv.Itoa(n+offset)
+	})
+}
+
+// MaxPlaceholder returns the highest $n placeholder referenced by sql, or 0
+func MaxPlaceholder(sql string) int {
+	max := 0
+	rewritePlaceholders(sql, func(n int) string {
+		if n > max {
+			max = n
+		}
+		return ""
+	})
+	return max
+}
+
+// RewritePlaceholders replaces every $n placeholder in sql with fn(n),
+// leaving placeholder-like text inside string literals, quoted identifiers,
+// comments and dollar-quoted strings untouched. Translators to other query
+// builders use it to rename placeholders.
+func RewritePlaceholders(sql string, fn func(n int) string) string {
+	return rewritePlaceholders(sql, fn)
+}
+
+// rewritePlaceholders replaces every $n placeholder in sql with fn(n),
+// skipping literals, quoted identifiers, comments and dollar-quoted strings
+func rewritePlaceholders(sql string, fn func(n int) string) string {
+	return walkSQL(sql, func(b *strings.Builder, i int) int {
+		if sql[i] != '$' || (i > 0 && isIdentByte(sql[i-1])) {
+			return i
+		}
+		j := i + 1
+		for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			return i
+		}
+		n, _ := strconv.Atoi(sql[i+1 : j])
+		b.WriteString(fn(n))
+		return j
+	})
+}
+
+// walkSQL copies sql, passing every position outside string literals, quoted
+// identifiers, comments and dollar-quoted strings to code. code either writes
+// a replacement and returns the index past what it consumed, or returns i to
+// copy the byte unchanged.
+func walkSQL(sql string, code func(b *strings.Builder, i int) int) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		end := i
+		switch {
+		case c == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !isIdentByte(sql[i-2]))
+			end = skipQuoted(sql, i, '\'', escapes)
+		case c == '"':
+			end = skipQuoted(sql, i, '"', false)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end = strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end = strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i + 4
+			}
+		case c == '$' && (i == 0 || !isIdentByte(sql[i-1])):
+			if tag := dollarTag(sql[i:]); tag != "" {
+				end = strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					end = len(sql)
+				} else {
+					end += i + 2*len(tag)
+				}
+			}
+		}
+		if end > i {
+			b.WriteString(sql[i:end])
+			i = end
+			continue
+		}
+
+		if next := code(&b, i); next > i {
+			i = next
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+
+	return b.String()
+}
+
+// skipQuoted returns the index just past the quoted section starting at start.
+// A doubled quote character is an escaped quote; backslash escapes are honored
+// for E-prefixed (escape) strings.
+func skipQuoted(sql string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// dollarTag returns the opening tag ($$ or $tag$) if s starts with one
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isIdentByte(s[i]) || (i == 1 && s[i] >= '0' && s[i] <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentByte reports whether b can appear inside an unquoted SQL identifier
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' ||
+		(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
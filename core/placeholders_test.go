@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenumberPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		start    int
+		expected string
+	}{
+		{"no shift", "a = $1 AND b = $2", 1, "a = $1 AND b = $2"},
+		{"shift", "a = $1 AND b = $2", 3, "a = $3 AND b = $4"},
+		{"multi-digit", "a = $9 OR b = $10", 11, "a = $19 OR b = $20"},
+		{"string literal", "a = $1 AND b = 'cost $1'", 2, "a = $2 AND b = 'cost $1'"},
+		{"escaped quote", "a = 'it''s $1' AND b = $1", 2, "a = 'it''s $1' AND b = $2"},
+		{"escape string", `a = E'\'$1' AND b = $1`, 2, `a = E'\'$1' AND b = $2`},
+		{"quoted identifier", `"col$1" = $1`, 5, `"col$1" = $5`},
+		{"identifier with dollar", "col$1 = $1", 5, "col$1 = $5"},
+		{"line comment", "a = $1 -- $1\nAND b = $2", 2, "a = $2 -- $1\nAND b = $3"},
+		{"block comment", "a = /* $1 */ $1", 2, "a = /* $1 */ $2"},
+		{"dollar quoted", "a = $$ $1 $$ AND b = $1", 2, "a = $$ $1 $$ AND b = $2"},
+		{"tagged dollar quoted", "a = $x$ $1 $x$ AND b = $1", 2, "a = $x$ $1 $x$ AND b = $2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenumberPlaceholders(tt.sql, tt.start); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaxPlaceholder(t *testing.T) {
+	if got := MaxPlaceholder("a = $2 AND b IN ($1, $12) AND c = '$99'"); got != 12 {
+		t.Errorf("Expected 12, got %d", got)
+	}
+	if got := MaxPlaceholder("a IS NULL"); got != 0 {
+		t.Errorf("Expected 0, got %d", got)
+	}
+}
+
+// randomSpec builds a random And/Or/Not tree whose leaves come from leaf
+func randomSpec(rng *rand.Rand, depth int, leaf func() Specification[TestUser]) Specification[TestUser] {
+	if depth == 0 || rng.Intn(4) == 0 {
+		return leaf()
+	}
+	switch rng.Intn(5) {
+	case 0:
+		return Not(randomSpec(rng, depth-1, leaf))
+	case 1, 2:
+		return randomSpec(rng, depth-1, leaf).And(randomSpec(rng, depth-1, leaf))
+	default:
+		return randomSpec(rng, depth-1, leaf).Or(randomSpec(rng, depth-1, leaf))
+	}
+}
+
+var leafCallRegex = regexp.MustCompile(`f(\d+)\(([^)]*)\)`)
+
+func TestSpecification_RenumberProperty(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for iter := 0; iter < 500; iter++ {
+		// Each leaf f<id>($1, ..., $k) binds the values "<id>:<position>";
+		// some leaves use a Specification implementation other than baseSpecification
+		nextID := 0
+		leaf := func() Specification[TestUser] {
+			nextID++
+			k := 1 + rng.Intn(3)
+			placeholders := make([]string, k)
+			args := make([]interface{}, k)
+			for i := range placeholders {
+				placeholders[i] = fmt.Sprintf("$%d", i+1)
+				args[i] = fmt.Sprintf("%d:%d", nextID, i)
+			}
+			sql := fmt.Sprintf("f%d(%s)", nextID, strings.Join(placeholders, ", "))
+			if rng.Intn(3) == 0 {
+				return rawSpec{sql, args}
+			}
+			return Where[TestUser](sql, args...)
+		}
+
+		spec := randomSpec(rng, 5, leaf)
+		sql, args := spec.ToSQL()
+
+		if max := MaxPlaceholder(sql); max != len(args) {
+			t.Fatalf("Expected highest placeholder $%d, got $%d in %s", len(args), max, sql)
+		}
+
+		seen := make(map[int]bool)
+		for _, call := range leafCallRegex.FindAllStringSubmatch(sql, -1) {
+			for pos, p := range strings.Split(call[2], ", ") {
+				n, err := strconv.Atoi(strings.TrimPrefix(p, "$"))
+				if err != nil {
+					t.Fatalf("Malformed placeholder %q in %s", p, sql)
+				}
+				if seen[n] {
+					t.Fatalf("Placeholder $%d bound twice in %s", n, sql)
+				}
+				seen[n] = true
+
+				expected := fmt.Sprintf("%s:%d", call[1], pos)
+				if args[n-1] != expected {
+					t.Fatalf("Expected $%d to bind %s, got %v in %s", n, expected, args[n-1], sql)
+				}
+			}
+		}
+		if len(seen) != len(args) {
+			t.Fatalf("Expected %d placeholders, found %d in %s", len(args), len(seen), sql)
+		}
+	}
+}
+
+// TestSpecification_RenumberDatabase evaluates random specification trees in
+// PostgreSQL and compares the result with the expected truth value.
+// Set JETORM_TEST_DATABASE_URL to run it.
+func TestSpecification_RenumberDatabase(t *testing.T) {
+	url := os.Getenv("JETORM_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("JETORM_TEST_DATABASE_URL not set")
+	}
+
+	db, err := ConnectURL(url)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+
+	for iter := 0; iter < 200; iter++ {
+		// Leaves are $1::int + $2::int = $3::int with a known truth value
+		truth := make(map[string]bool)
+		nextID := 0
+		leaf := func() Specification[TestUser] {
+			nextID++
+			a, b := rng.Intn(100), rng.Intn(100)
+			c := a + b
+			if rng.Intn(2) == 0 {
+				c++
+			}
+			id := fmt.Sprintf("leaf%d", nextID)
+			truth[id] = a+b == c
+			return Where[TestUser](fmt.Sprintf("'%s' <> '' AND $1::int + $2::int = $3::int", id), a, b, c)
+		}
+
+		spec := randomSpec(rng, 5, leaf)
+		expected := evaluateSpec(spec, truth)
+		sql, args := spec.ToSQL()
+
+		var got bool
+		if err := db.Pool().QueryRow(ctx, "SELECT "+sql, args...).Scan(&got); err != nil {
+			t.Fatalf("Query failed for %s: %v", sql, err)
+		}
+		if got != expected {
+			t.Fatalf("Expected %v, got %v for %s %v", expected, got, sql, args)
+		}
+	}
+}
+
+// evaluateSpec computes the truth value of a tree built by randomSpec
+func evaluateSpec(spec Specification[TestUser], truth map[string]bool) bool {
+	base := spec.(*baseSpecification[TestUser])
+	switch base.operator {
+	case "AND":
+		return evaluateSpec(base.left, truth) && evaluateSpec(base.right, truth)
+	case "OR":
+		return evaluateSpec(base.left, truth) || evaluateSpec(base.right, truth)
+	case "NOT":
+		return !evaluateSpec(base.left, truth)
+	}
+	id := strings.Split(base.whereClause, "'")[1]
+	return truth[id]
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// projectionAliasSeparator joins the prefix and the column name in the
+// aliases of projection columns, e.g. customer__email
+const projectionAliasSeparator = "__"
+
+// projections caches the parsed projection types
+var projections sync.Map // reflect.Type -> *projection
+
+// projection is a view-model struct that embeds entities, such as
+//
+//	type InvoiceView struct {
+//		Invoice
+//		*Customer                  // nil when the LEFT JOIN finds no customer
+//		LineCount int `db:"line_count"`
+//	}
+//
+// Each embedded entity's columns are selected as <prefix>.<column> AS
+// <prefix>__<column>, where the prefix is its table name or the alias in a
+// jet:"alias:name" tag on the embedded field. Other fields are read from the
+// column of their own name.
+type projection struct {
+	entities []projectionEntity
+	fields   []Field
+}
+
+// projectionEntity is an entity embedded in a projection
+type projectionEntity struct {
+	entity  *Entity
+	index   []int  // Index of the embedded field in the projection
+	pointer bool   // Embedded as a pointer, left nil when its primary key is NULL
+	prefix  string // Table name or alias qualifying its columns
+}
+
+// projectionFor returns the cached projection of struct type t
+func projectionFor(t reflect.Type) (*projection, error) {
+	if cached, ok := projections.Load(t); ok {
+		return cached.(*projection), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, ErrInvalidEntity
+	}
+
+	p := &projection{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		entityType, pointer := field.Type, false
+		if entityType.Kind() == reflect.Ptr {
+			entityType, pointer = entityType.Elem(), true
+		}
+		if field.Anonymous && entityType.Kind() == reflect.Struct && !hasCustomCodec(entityType) {
+			entity, err := entityMetadataFor(entityType)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", entityType, err)
+			}
+			prefix := entity.TableName
+			for _, tag := range parseTag(field.Tag.Get("jet")) {
+				if tag.Key == "alias" && tag.Value != "" {
+					prefix = tag.Value
+				}
+			}
+			p.entities = append(p.entities, projectionEntity{entity: entity, index: field.Index, pointer: pointer, prefix: prefix})
+			continue
+		}
+
+		f := parseFieldTags(field)
+		if !f.Ignored {
+			f.StructIndex = field.Index
+			p.fields = append(p.fields, f)
+		}
+	}
+	if len(p.entities) == 0 {
+		return nil, fmt.Errorf("%w: %s embeds no entities", ErrInvalidEntity, t)
+	}
+
+	actual, _ := projections.LoadOrStore(t, p)
+	return actual.(*projection), nil
+}
+
+// columns returns the aliased select expressions of the embedded entities
+func (p *projection) columns() []string {
+	var columns []string
+	for _, e := range p.entities {
+		for _, column := range e.entity.columns() {
+			columns = append(columns, fmt.Sprintf("%s.%s AS %s%s%s", e.prefix, column, e.prefix, projectionAliasSeparator, column))
+		}
+	}
+	return columns
+}
+
+// present reports which embedded entities the current row holds. Entities
+// embedded by value are always present; pointers need a non-NULL primary key.
+func (p *projection) present(rows pgx.Rows, index map[string]int) ([]bool, error) {
+	present := make([]bool, len(p.entities))
+	dest := make([]interface{}, len(rows.FieldDescriptions()))
+	probes := make(map[int]reflect.Value)
+	for i, e := range p.entities {
+		present[i] = true
+		if !e.pointer {
+			continue
+		}
+		position, ok := index[e.prefix+projectionAliasSeparator+e.entity.PrimaryKey.DBName]
+		if !ok {
+			continue
+		}
+		probe := reflect.New(reflect.PointerTo(e.entity.PrimaryKey.Type))
+		dest[position] = probe.Interface()
+		probes[i] = probe
+	}
+	if len(probes) == 0 {
+		return present, nil
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	for i, probe := range probes {
+		present[i] = !probe.Elem().IsNil()
+	}
+	return present, nil
+}
+
+// ProjectionColumns returns the select list of projection struct P: the
+// columns of each embedded entity, qualified by its table name (or the alias
+// of a jet:"alias:name" tag) and aliased <prefix>__<column> so that
+// QueryProjection can tell them apart. Expressions for P's other fields are
+// added by the caller.
+//
+//	columns, err := core.ProjectionColumns[InvoiceView]()
+//	query := "SELECT " + strings.Join(columns, ", ") + ", count(l.id) AS line_count FROM invoice ..."
+func ProjectionColumns[P any]() ([]string, error) {
+	p, err := projectionFor(reflect.TypeOf((*P)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return p.columns(), nil
+}
+
+// QueryProjection runs a raw query, typically a join selecting
+// ProjectionColumns, and scans each row into a projection struct P.
+// Columns are matched by name; fields whose column was not selected keep
+// their zero value.
+func QueryProjection[P any](ctx context.Context, db *Database, query string, args ...interface{}) ([]*P, error) {
+	p, err := projectionFor(reflect.TypeOf((*P)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRawSQL(ctx, db, query); err != nil {
+		return nil, err
+	}
+	if db.config.LogSQL {
+		db.logger.Debug("executing query", "query", query, "args", args)
+	}
+
+	rows, err := db.readConn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := columnIndex(rows)
+	results := make([]*P, 0)
+	for rows.Next() {
+		present, err := p.present(rows, index)
+		if err != nil {
+			return nil, err
+		}
+
+		result := new(P)
+		v := reflect.ValueOf(result).Elem()
+		parts := []namedFields{{fields: p.fields, v: v}}
+		for i, e := range p.entities {
+			if !present[i] {
+				continue
+			}
+			embedded := v.FieldByIndex(e.index)
+			if e.pointer {
+				embedded.Set(reflect.New(e.entity.Type))
+				embedded = embedded.Elem()
+			}
+			parts = append(parts, namedFields{fields: e.entity.Fields, v: embedded, prefix: e.prefix + projectionAliasSeparator})
+		}
+		if err := scanByName(rows, index, parts...); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// dtos caches the fields of the structs scanned by QueryInto
+var dtos sync.Map // reflect.Type -> []Field
+
+// dtoFields returns the fields of plain struct t, parsed like an entity's
+// but without requiring a primary key
+func dtoFields(t reflect.Type) ([]Field, error) {
+	if cached, ok := dtos.Load(t); ok {
+		return cached.([]Field), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, ErrInvalidEntity
+	}
+	meta, err := EntityMetadata(reflect.New(t).Elem().Interface())
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := dtos.LoadOrStore(t, meta.Fields)
+	return actual.([]Field), nil
+}
+
+// QueryInto runs a raw query in the repository's transaction, if any, and
+// scans each row into a plain struct P, such as a DTO holding a subset of the
+// entity's columns. Columns are matched to P's fields by name; fields whose
+// column was not selected keep their zero value.
+//
+//	summaries, err := core.QueryInto[UserSummary](ctx, repo, "SELECT email, full_name FROM users WHERE status = $1", status)
+func QueryInto[P any, T any, ID comparable](ctx context.Context, r *BaseRepository[T, ID], query string, args ...interface{}) ([]*P, error) {
+	fields, err := dtoFields(reflect.TypeOf((*P)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkRawSQL(ctx, query); err != nil {
+		return nil, err
+	}
+	r.logQuery(query, args)
+
+	var rows pgx.Rows
+	if r.tx != nil {
+		rows, err = r.txConn(ctx).Query(ctx, query, args...)
+	} else {
+		rows, err = r.poolConn(ctx).Query(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := columnIndex(rows)
+	results := make([]*P, 0)
+	for rows.Next() {
+		result := new(P)
+		if err := scanByName(rows, index, namedFields{fields: fields, v: reflect.ValueOf(result).Elem()}); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryOneInto is QueryInto for a single row; it returns ErrNotFound when
+// the query returns no rows
+func QueryOneInto[P any, T any, ID comparable](ctx context.Context, r *BaseRepository[T, ID], query string, args ...interface{}) (*P, error) {
+	results, err := QueryInto[P](ctx, r, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+	return results[0], nil
+}
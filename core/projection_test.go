@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestOrder and TestCustomer are joined into projections
+type TestOrder struct {
+	ID         int64 `db:"id" jet:"primary_key,auto_increment"`
+	CustomerID int64 `db:"customer_id"`
+	Total      int64 `db:"total"`
+}
+
+type TestCustomer struct {
+	ID   int64  `db:"id" jet:"primary_key,auto_increment"`
+	Name string `db:"name"`
+}
+
+type testOrderView struct {
+	TestOrder     `jet:"alias:o"`
+	*TestCustomer     // nil when the order has no customer
+	Items         int `db:"items"`
+}
+
+func TestProjectionColumns(t *testing.T) {
+	columns, err := ProjectionColumns[testOrderView]()
+	if err != nil {
+		t.Fatalf("Failed to get columns: %v", err)
+	}
+	want := "o.id AS o__id, o.customer_id AS o__customer_id, o.total AS o__total, " +
+		"test_customer.id AS test_customer__id, test_customer.name AS test_customer__name"
+	if got := strings.Join(columns, ", "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	p, err := projectionFor(reflect.TypeOf(testOrderView{}))
+	if err != nil {
+		t.Fatalf("Failed to parse projection: %v", err)
+	}
+	if len(p.fields) != 1 || p.fields[0].DBName != "items" {
+		t.Errorf("Expected the items field to be read by name, got %+v", p.fields)
+	}
+	if p.entities[0].pointer || !p.entities[1].pointer {
+		t.Errorf("Expected only the customer to be optional")
+	}
+
+	if _, err := ProjectionColumns[TestOrder](); !errors.Is(err, ErrInvalidEntity) {
+		t.Errorf("Expected ErrInvalidEntity for a struct embedding no entities, got %v", err)
+	}
+	if _, err := ProjectionColumns[struct {
+		TestOrder
+		TestNoKey
+	}](); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("Expected ErrNoPrimaryKey for an embedded struct without a key, got %v", err)
+	}
+}
+
+type TestNoKey struct {
+	Name string `db:"name"`
+}
+
+func TestQueryProjection(t *testing.T) {
+	db := &Database{}
+	ctx, capture := db.DryRun(context.Background())
+
+	query := "SELECT o.id AS o__id FROM test_order o"
+	if _, err := QueryProjection[testOrderView](ctx, db, query); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+	if statements := capture.Statements(); len(statements) != 1 || statements[0].SQL != query {
+		t.Errorf("Expected the query to be recorded, got %v", statements)
+	}
+}
+
+// testUserSummary is a DTO holding a subset of TestUser's columns
+type testUserSummary struct {
+	Email    string `db:"email"`
+	Username string
+}
+
+func TestQueryInto(t *testing.T) {
+	fields, err := dtoFields(reflect.TypeOf(testUserSummary{}))
+	if err != nil {
+		t.Fatalf("Failed to parse DTO: %v", err)
+	}
+	if len(fields) != 2 || fields[0].DBName != "email" || fields[1].DBName != "username" {
+		t.Errorf("Unexpected DTO fields: %+v", fields)
+	}
+
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx, capture := (&Database{}).DryRun(context.Background())
+
+	query := "SELECT email, username FROM test_user WHERE age > $1"
+	if _, err := QueryInto[testUserSummary](ctx, repo, query, 30); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+	if _, err := QueryOneInto[testUserSummary](ctx, repo, query, 30); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+	statements := capture.Statements()
+	if len(statements) != 2 || statements[0].SQL != query || statements[0].Args[0] != 30 {
+		t.Errorf("Unexpected statements: %+v", statements)
+	}
+
+	if _, err := QueryInto[int](ctx, repo, query); !errors.Is(err, ErrInvalidEntity) {
+		t.Errorf("Expected ErrInvalidEntity, got %v", err)
+	}
+}
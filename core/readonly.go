@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// readOnlyQuerier rejects statements that write with ErrReadOnly before they
+// reach the database. It guards repositories of a Database configured with
+// Config.ReadOnly; the server additionally runs its sessions read-only.
+type readOnlyQuerier struct {
+	q querier
+}
+
+// guard wraps q in a read-only guard when the database is read-only, and in
+// a comment tagger when it is configured with QueryComments, and in an N+1
+// detector when it is configured with NPlusOneThreshold
+func (r *BaseRepository[T, ID]) guard(q querier) querier {
+	if r.db != nil && r.db.config.QueryComments {
+		q = commentQuerier{q: q}
+	}
+	if r.db != nil && r.db.config.ReadOnly {
+		q = readOnlyQuerier{q: q}
+	}
+	if r.db != nil && r.db.config.NPlusOneThreshold > 0 {
+		q = nPlusOneQuerier{querier: q, db: r.db}
+	}
+	return q
+}
+
+func readOnlyError(sql string) error {
+	return fmt.Errorf("%w: %s", ErrReadOnly, firstKeyword(sql))
+}
+
+// Exec runs sql if it only reads
+func (g readOnlyQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !isReadOnlyStatement(sql) {
+		return pgconn.CommandTag{}, readOnlyError(sql)
+	}
+	return g.q.Exec(ctx, sql, args...)
+}
+
+// Query runs sql if it only reads
+func (g readOnlyQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if !isReadOnlyStatement(sql) {
+		return nil, readOnlyError(sql)
+	}
+	return g.q.Query(ctx, sql, args...)
+}
+
+// QueryRow runs sql if it only reads; otherwise scanning the row fails
+func (g readOnlyQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if !isReadOnlyStatement(sql) {
+		return errRows{err: readOnlyError(sql)}
+	}
+	return g.q.QueryRow(ctx, sql, args...)
+}
+
+// SendBatch sends b if every queued statement only reads
+func (g readOnlyQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, query := range b.QueuedQueries {
+		if !isReadOnlyStatement(query.SQL) {
+			return errBatchResults{err: readOnlyError(query.SQL)}
+		}
+	}
+	return g.q.SendBatch(ctx, b)
+}
+
+// CopyFrom always fails: COPY FROM writes rows
+func (g readOnlyQuerier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, fmt.Errorf("%w: COPY", ErrReadOnly)
+}
+
+// errBatchResults are the results of a batch that was not sent
+type errBatchResults struct {
+	err error
+}
+
+func (r errBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, r.err }
+func (r errBatchResults) Query() (pgx.Rows, error)         { return nil, r.err }
+func (r errBatchResults) QueryRow() pgx.Row                { return errRows{err: r.err} }
+func (r errBatchResults) Close() error                     { return r.err }
+
+// writeKeywords are the statements that modify data inside a WITH or EXPLAIN ANALYZE
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "TRUNCATE": true,
+}
+
+// isReadOnlyStatement reports whether sql only reads. SELECT, SHOW, VALUES
+// and TABLE read; WITH and EXPLAIN read unless a data-modifying statement
+// appears among their keywords. The check is conservative: a string literal
+// containing such a word also counts as a write.
+func isReadOnlyStatement(sql string) bool {
+	switch firstKeyword(sql) {
+	case "SELECT", "SHOW", "VALUES", "TABLE":
+		return true
+	case "WITH":
+		return !containsWriteKeyword(sql)
+	case "EXPLAIN":
+		// Plain EXPLAIN only plans the statement; ANALYZE executes it
+		return !containsKeyword(sql, "ANALYZE") || !containsWriteKeyword(sql)
+	}
+	return false
+}
+
+// firstKeyword returns the first word of sql in upper case, skipping
+// whitespace, comments and opening parentheses
+func firstKeyword(sql string) string {
+	for {
+		sql = strings.TrimLeftFunc(sql, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			if i := strings.IndexByte(sql, '\n'); i >= 0 {
+				sql = sql[i+1:]
+				continue
+			}
+			return ""
+		case strings.HasPrefix(sql, "/*"):
+			if i := strings.Index(sql, "*/"); i >= 0 {
+				sql = sql[i+2:]
+				continue
+			}
+			return ""
+		}
+		end := strings.IndexFunc(sql, func(r rune) bool { return !unicode.IsLetter(r) })
+		if end < 0 {
+			end = len(sql)
+		}
+		return strings.ToUpper(sql[:end])
+	}
+}
+
+func containsWriteKeyword(sql string) bool {
+	for _, word := range sqlWords(sql) {
+		if writeKeywords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKeyword(sql, keyword string) bool {
+	for _, word := range sqlWords(sql) {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlWords splits sql into upper-cased words
+func sqlWords(sql string) []string {
+	return strings.FieldsFunc(strings.ToUpper(sql), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	})
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIsReadOnlyStatement(t *testing.T) {
+	tests := []struct {
+		sql      string
+		readOnly bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  select count(*) from users", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"-- report\nSELECT 1", true},
+		{"/* report */ SHOW server_version", true},
+		{"VALUES (1), (2)", true},
+		{"WITH recent AS (SELECT * FROM users) SELECT * FROM recent", true},
+		{"WITH gone AS (DELETE FROM users RETURNING id) SELECT * FROM gone", false},
+		{"EXPLAIN DELETE FROM users", true},
+		{"EXPLAIN ANALYZE SELECT * FROM users", true},
+		{"EXPLAIN (ANALYZE) DELETE FROM users", false},
+		{"INSERT INTO users (email) VALUES ($1)", false},
+		{"UPDATE users SET age = 1", false},
+		{"DELETE FROM users", false},
+		{"TRUNCATE users", false},
+		{"CREATE INDEX idx ON users (email)", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isReadOnlyStatement(tt.sql); got != tt.readOnly {
+			t.Errorf("isReadOnlyStatement(%q) = %v, expected %v", tt.sql, got, tt.readOnly)
+		}
+	}
+}
+
+func TestBaseRepository_ReadOnly(t *testing.T) {
+	db := &Database{config: Config{ReadOnly: true}}
+	repo, err := NewBaseRepository[TestUser, int64](db)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("should reject writes", func(t *testing.T) {
+		if _, err := repo.Save(ctx, &TestUser{Email: "a@example.com"}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly from Save, got %v", err)
+		}
+		if err := repo.DeleteByID(ctx, 1); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly from DeleteByID, got %v", err)
+		}
+		if _, err := repo.Exec(ctx, "UPDATE test_user SET age = 0"); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly from Exec, got %v", err)
+		}
+		if _, err := repo.BulkInsert(ctx, []*TestUser{{Email: "a@example.com"}}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly from BulkInsert, got %v", err)
+		}
+		if _, err := repo.SaveAll(ctx, []*TestUser{{Email: "a@example.com"}, {Email: "b@example.com"}}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly from SaveAll, got %v", err)
+		}
+	})
+
+	t.Run("should still record writes in dry-run mode", func(t *testing.T) {
+		dryCtx, capture := db.DryRun(ctx)
+		if err := repo.DeleteByID(dryCtx, 1); err != nil {
+			t.Errorf("Expected dry-run delete to succeed, got %v", err)
+		}
+		if len(capture.Statements()) != 1 {
+			t.Errorf("Expected 1 statement, got %d", len(capture.Statements()))
+		}
+	})
+}
+
+func TestConnect_ReadOnlyMigrateOnStart(t *testing.T) {
+	config := DefaultConfig()
+	config.ReadOnly = true
+	config.MigrateOnStart = fstest.MapFS{}
+	if _, err := Connect(config); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// entityRegistry caches validated entity metadata per struct type. Metadata
+// depends only on the type and its tags, so it is computed once per process
+// and shared, read-only, by every repository of that type.
+var entityRegistry sync.Map // reflect.Type -> *registeredEntity
+
+// registeredEntity is a registry entry; misconfigured types cache their error
+type registeredEntity struct {
+	entity *Entity
+	err    error
+}
+
+// RegisterEntity validates the metadata of T and caches it, so that tag
+// mistakes such as a missing primary key or a malformed enum are reported at
+// startup rather than when the first repository is created. Registering a type
+// again returns the cached result.
+func RegisterEntity[T any]() (*Entity, error) {
+	return entityMetadataFor(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// RegisterEntities registers the types of the given entity values, e.g.
+// RegisterEntities(User{}, &Order{}), and reports every misconfigured type
+func RegisterEntities(entities ...interface{}) error {
+	var errs []error
+	for _, entity := range entities {
+		t := reflect.TypeOf(entity)
+		if t == nil {
+			errs = append(errs, ErrInvalidEntity)
+			continue
+		}
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if _, err := entityMetadataFor(t); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisteredEntities returns the valid registered entities, sorted by table name
+func RegisteredEntities() []*Entity {
+	var entities []*Entity
+	entityRegistry.Range(func(_, value any) bool {
+		if entry := value.(*registeredEntity); entry.err == nil {
+			entities = append(entities, entry.entity)
+		}
+		return true
+	})
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].TableName < entities[j].TableName
+	})
+	return entities
+}
+
+// entityMetadataFor returns the cached metadata of t, computing and validating
+// it on first use
+func entityMetadataFor(t reflect.Type) (*Entity, error) {
+	if cached, ok := entityRegistry.Load(t); ok {
+		entry := cached.(*registeredEntity)
+		return entry.entity, entry.err
+	}
+
+	entry := &registeredEntity{}
+	entry.entity, entry.err = EntityMetadata(reflect.New(t).Elem().Interface())
+	if entry.err == nil {
+		entry.err = validateEntity(entry.entity)
+	}
+	if entry.err != nil {
+		entry.entity = nil
+	}
+
+	actual, _ := entityRegistry.LoadOrStore(t, entry)
+	entry = actual.(*registeredEntity)
+	return entry.entity, entry.err
+}
+
+// validateEntity checks the tags a repository relies on
+func validateEntity(entity *Entity) error {
+	if entity.PrimaryKey == nil {
+		return ErrNoPrimaryKey
+	}
+	for i := range entity.Fields {
+		if err := validateUUIDField(&entity.Fields[i]); err != nil {
+			return err
+		}
+		if err := validateEnumField(&entity.Fields[i]); err != nil {
+			return err
+		}
+	}
+	return validateDiscriminator(entity)
+}
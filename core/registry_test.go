@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterEntity(t *testing.T) {
+	t.Run("should cache metadata per type", func(t *testing.T) {
+		first, err := RegisterEntity[TestUser]()
+		if err != nil {
+			t.Fatalf("Failed to register entity: %v", err)
+		}
+		second, _ := RegisterEntity[TestUser]()
+		if first != second {
+			t.Error("Expected the cached metadata to be returned")
+		}
+
+		repo, err := NewBaseRepository[TestUser, int64](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		if repo.entity != first {
+			t.Error("Expected repositories to share the registered metadata")
+		}
+	})
+
+	t.Run("should report misconfigured entities", func(t *testing.T) {
+		type noKey struct {
+			Name string `db:"name"`
+		}
+		if _, err := RegisterEntity[noKey](); !errors.Is(err, ErrNoPrimaryKey) {
+			t.Errorf("Expected ErrNoPrimaryKey, got %v", err)
+		}
+		if _, err := NewBaseRepository[noKey, int64](nil); !errors.Is(err, ErrNoPrimaryKey) {
+			t.Errorf("Expected the cached error from NewBaseRepository, got %v", err)
+		}
+	})
+
+	t.Run("should register several entities and join errors", func(t *testing.T) {
+		type badEnum struct {
+			ID     int64 `db:"id" jet:"primary_key"`
+			Status int   `db:"status" jet:"enum:status_enum(a,b)"`
+		}
+		type noKey struct {
+			Name string `db:"name"`
+		}
+
+		err := RegisterEntities(TestUser{}, &TestTicket{}, badEnum{}, noKey{}, 42)
+		if !errors.Is(err, ErrInvalidEnum) || !errors.Is(err, ErrNoPrimaryKey) || !errors.Is(err, ErrInvalidEntity) {
+			t.Errorf("Expected every error to be reported, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "badEnum") {
+			t.Errorf("Expected the type name in the error, got %v", err)
+		}
+
+		found := false
+		for _, entity := range RegisteredEntities() {
+			if entity.TableName == "test_ticket" {
+				found = true
+			}
+			if entity.TableName == "bad_enum" {
+				t.Error("Expected misconfigured entities not to be listed")
+			}
+		}
+		if !found {
+			t.Error("Expected test_ticket to be registered")
+		}
+	})
+}
@@ -44,11 +44,28 @@ type Repository[T any, ID comparable] interface {
 
 // Pageable represents pagination and sorting request
 type Pageable struct {
-	Page int  // Zero-based page number
-	Size int  // Page size
-	Sort Sort // Sort specification
+	Page      int       // Zero-based page number
+	Size      int       // Page size
+	Sort      Sort      // Sort specification
+	CountMode CountMode // How the total element count is computed
+
+	// SortableFields, when set, lists the fields a sort may name; any other
+	// field is rejected with ErrUnknownField
+	SortableFields []string
 }
 
+// CountMode controls how paged finders compute Page.TotalElements
+type CountMode int
+
+const (
+	// CountExact runs a COUNT(*) query (default)
+	CountExact CountMode = iota
+	// CountEstimated uses planner statistics instead of COUNT(*)
+	CountEstimated
+	// CountNone skips the count; TotalElements and TotalPages are -1
+	CountNone
+)
+
 // Sort represents sort specification
 type Sort struct {
 	Orders []Order
@@ -72,8 +89,8 @@ const (
 type Page[T any] struct {
 	Content          []*T     // Page content
 	Pageable         Pageable // Pageable that produced this page
-	TotalElements    int64    // Total elements across all pages
-	TotalPages       int      // Total number of pages
+	TotalElements    int64    // Total elements across all pages, -1 with CountNone
+	TotalPages       int      // Total number of pages, -1 with CountNone
 	Size             int      // Page size
 	Number           int      // Current page number (zero-based)
 	NumberOfElements int      // Elements in current page
@@ -85,11 +102,8 @@ type Page[T any] struct {
 
 // Next returns the next Pageable
 func (p Pageable) Next() Pageable {
-	return Pageable{
-		Page: p.Page + 1,
-		Size: p.Size,
-		Sort: p.Sort,
-	}
+	p.Page = p.Page + 1
+	return p
 }
 
 // Previous returns the previous Pageable
@@ -97,20 +111,27 @@ func (p Pageable) Previous() Pageable {
 	if p.Page <= 0 {
 		return p.First()
 	}
-	return Pageable{
-		Page: p.Page - 1,
-		Size: p.Size,
-		Sort: p.Sort,
-	}
+	p.Page--
+	return p
 }
 
 // First returns the first Pageable
 func (p Pageable) First() Pageable {
-	return Pageable{
-		Page: 0,
-		Size: p.Size,
-		Sort: p.Sort,
-	}
+	p.Page = 0
+	return p
+}
+
+// WithCountMode returns a copy of the Pageable using the given count mode
+func (p Pageable) WithCountMode(mode CountMode) Pageable {
+	p.CountMode = mode
+	return p
+}
+
+// WithSortable returns a copy of the Pageable that only allows sorting by
+// the given fields
+func (p Pageable) WithSortable(fields ...string) Pageable {
+	p.SortableFields = fields
+	return p
 }
 
 // PageRequest creates a Pageable with the given page, size and sort orders
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/satishbabariya/jetorm/hooks"
+)
+
+// WithActor returns a context identifying the user or service performing
+// the operation. Audit hooks record it as created/updated by, SQL logging
+// and query comments include it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return hooks.WithActor(ctx, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor
+func ActorFromContext(ctx context.Context) (string, bool) {
+	return hooks.ActorFromContext(ctx)
+}
+
+// WithRequestID returns a context carrying the ID of the request being
+// served, for correlating logs and queries
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return hooks.WithRequestID(ctx, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return hooks.RequestIDFromContext(ctx)
+}
+
+// queryComment renders the actor and request ID of ctx as a SQL comment in
+// the sqlcommenter format, e.g. /*actor='42',request_id='abc'*/. Values are
+// URL-encoded, so they cannot close the comment. It returns "" when ctx
+// carries neither.
+func queryComment(ctx context.Context) string {
+	var pairs []string
+	if actor, ok := ActorFromContext(ctx); ok && actor != "" {
+		pairs = append(pairs, "actor='"+url.QueryEscape(actor)+"'")
+	}
+	if requestID, ok := RequestIDFromContext(ctx); ok && requestID != "" {
+		pairs = append(pairs, "request_id='"+url.QueryEscape(requestID)+"'")
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// withQueryComment appends the comment of ctx to sql
+func withQueryComment(ctx context.Context, sql string) string {
+	if comment := queryComment(ctx); comment != "" {
+		return sql + " " + comment
+	}
+	return sql
+}
+
+// commentQuerier tags every statement with the actor and request ID of its
+// context when Config.QueryComments is set, so they show up in
+// pg_stat_activity and the server log
+type commentQuerier struct {
+	q querier
+}
+
+// Exec runs sql with the context's comment appended
+func (c commentQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return c.q.Exec(ctx, withQueryComment(ctx, sql), args...)
+}
+
+// Query runs sql with the context's comment appended
+func (c commentQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return c.q.Query(ctx, withQueryComment(ctx, sql), args...)
+}
+
+// QueryRow runs sql with the context's comment appended
+func (c commentQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return c.q.QueryRow(ctx, withQueryComment(ctx, sql), args...)
+}
+
+// SendBatch appends the context's comment to every queued statement
+func (c commentQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if comment := queryComment(ctx); comment != "" {
+		for _, queued := range b.QueuedQueries {
+			queued.SQL += " " + comment
+		}
+	}
+	return c.q.SendBatch(ctx, b)
+}
+
+// CopyFrom copies rows unchanged; COPY takes no comment
+func (c commentQuerier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return c.q.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
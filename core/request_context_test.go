@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestRequestContext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should round-trip actor and request ID", func(t *testing.T) {
+		if _, ok := ActorFromContext(ctx); ok {
+			t.Error("Expected no actor on a bare context")
+		}
+		ctx := WithRequestID(WithActor(ctx, "user-42"), "req-1")
+		if actor, ok := ActorFromContext(ctx); !ok || actor != "user-42" {
+			t.Errorf("Expected actor user-42, got %q (%v)", actor, ok)
+		}
+		if requestID, ok := RequestIDFromContext(ctx); !ok || requestID != "req-1" {
+			t.Errorf("Expected request ID req-1, got %q (%v)", requestID, ok)
+		}
+	})
+
+	t.Run("should honor the legacy user_id key", func(t *testing.T) {
+		ctx := context.WithValue(ctx, "user_id", "legacy") //nolint:staticcheck // the key audit hooks used to read
+		if actor, ok := ActorFromContext(ctx); !ok || actor != "legacy" {
+			t.Errorf("Expected actor legacy, got %q (%v)", actor, ok)
+		}
+	})
+
+	t.Run("should render escaped query comments", func(t *testing.T) {
+		if comment := queryComment(ctx); comment != "" {
+			t.Errorf("Expected no comment, got %q", comment)
+		}
+		ctx := WithRequestID(WithActor(ctx, "o'brien */ DROP"), "req 1")
+		expected := "/*actor='o%27brien+%2A%2F+DROP',request_id='req+1'*/"
+		if comment := queryComment(ctx); comment != expected {
+			t.Errorf("Expected %s, got %s", expected, comment)
+		}
+	})
+}
+
+func TestCommentQuerier(t *testing.T) {
+	ctx, capture := (&Database{}).DryRun(context.Background())
+	q := commentQuerier{q: capture}
+
+	if _, err := q.Exec(ctx, "UPDATE test_user SET age = 1"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	ctx = WithRequestID(WithActor(ctx, "42"), "abc")
+	if _, err := q.Exec(ctx, "UPDATE test_user SET age = 2"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	batch := &pgx.Batch{}
+	batch.Queue("DELETE FROM test_user WHERE id = $1", 1)
+	q.SendBatch(ctx, batch).Close()
+
+	expected := []string{
+		"UPDATE test_user SET age = 1",
+		"UPDATE test_user SET age = 2 /*actor='42',request_id='abc'*/",
+		"DELETE FROM test_user WHERE id = $1 /*actor='42',request_id='abc'*/",
+	}
+	statements := capture.Statements()
+	if len(statements) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(statements))
+	}
+	for i, statement := range statements {
+		if statement.SQL != expected[i] {
+			t.Errorf("Statement %d: expected %q, got %q", i, expected[i], statement.SQL)
+		}
+	}
+}
+
+func TestBaseRepository_QueryComments(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](&Database{config: Config{QueryComments: true, ReadOnly: true}})
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	q := repo.guard(nil)
+	guard, ok := q.(readOnlyQuerier)
+	if !ok {
+		t.Fatalf("Expected the read-only guard outermost, got %T", q)
+	}
+	if _, ok := guard.q.(commentQuerier); !ok {
+		t.Errorf("Expected the comment querier inside the read-only guard, got %T", guard.q)
+	}
+}
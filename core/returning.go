@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReturningGenerated returns a repository whose saves only return the primary key
+// and database-generated columns (auto_increment, auto_now_add, auto_now and columns
+// with a default) instead of RETURNING *. The other fields of saved entities are
+// copied from the input, which avoids reading back wide text or bytea columns.
+func (r *BaseRepository[T, ID]) ReturningGenerated() *BaseRepository[T, ID] {
+	repo := *r
+	repo.returning = r.generatedColumns()
+	return &repo
+}
+
+// SaveReturning saves an entity like Save but only reads back the given columns
+// and the primary key. Keys may be column names or struct field names.
+func (r *BaseRepository[T, ID]) SaveReturning(ctx context.Context, entity *T, columns ...string) (*T, error) {
+	returning := []string{r.pkField}
+	for _, col := range columns {
+		fieldMeta := r.lookupField(col)
+		if fieldMeta == nil || fieldMeta.Ignored {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, col)
+		}
+		if fieldMeta.DBName != r.pkField {
+			returning = append(returning, fieldMeta.DBName)
+		}
+	}
+
+	repo := *r
+	repo.returning = returning
+	return repo.Save(ctx, entity)
+}
+
+// generatedColumns lists the primary key and columns whose values the database assigns
+func (r *BaseRepository[T, ID]) generatedColumns() []string {
+	columns := []string{r.pkField}
+	for _, fieldMeta := range r.entity.Fields {
+		if fieldMeta.PrimaryKey || fieldMeta.Ignored {
+			continue
+		}
+		if fieldMeta.AutoIncrement || fieldMeta.AutoNowAdd || fieldMeta.AutoNow || fieldMeta.Default != "" {
+			columns = append(columns, fieldMeta.DBName)
+		}
+	}
+	return columns
+}
+
+// returningClause returns the RETURNING column list for INSERT and UPDATE statements
+func (r *BaseRepository[T, ID]) returningClause() string {
+	if len(r.returning) == 0 {
+		return r.selectList()
+	}
+	return strings.Join(r.returning, ", ")
+}
+
+// scanReturning scans the RETURNING row of a save. With a limited column list
+// the result starts as a copy of the saved entity and only returned columns
+// are overwritten.
+func (r *BaseRepository[T, ID]) scanReturning(row pgx.Row, entity *T) (*T, error) {
+	result := new(T)
+	if len(r.returning) == 0 {
+		if err := r.scanRow(row, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	*result = *entity
+	v := reflect.ValueOf(result).Elem()
+	targets := rowTargets{dest: make([]interface{}, 0, len(r.returning))}
+	for _, col := range r.returning {
+		for j := range r.entity.Fields {
+			if r.entity.Fields[j].DBName == col {
+				targets.add(&r.entity.Fields[j], v)
+				break
+			}
+		}
+	}
+
+	if err := targets.scan(row); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeRow is a pgx.Row assigning its values to the scan destinations in order
+type fakeRow []interface{}
+
+func (f fakeRow) Scan(dest ...any) error {
+	for i, value := range f {
+		if scanner, ok := dest[i].(sql.Scanner); ok {
+			if err := scanner.Scan(value); err != nil {
+				return err
+			}
+			continue
+		}
+		// Like pgx, NULL zeroes the target and pointer targets are allocated
+		target := reflect.ValueOf(dest[i]).Elem()
+		switch {
+		case value == nil:
+			target.SetZero()
+		case target.Kind() == reflect.Ptr && reflect.TypeOf(value) == target.Type().Elem():
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(reflect.ValueOf(value))
+			target.Set(ptr)
+		default:
+			target.Set(reflect.ValueOf(value))
+		}
+	}
+	return nil
+}
+
+func TestBaseRepository_Returning(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should return all columns by default", func(t *testing.T) {
+		query, _ := repo.insertStatement(&TestUser{Email: "a@example.com"})
+		if !contains(query, "RETURNING *") {
+			t.Errorf("Expected RETURNING *, got '%s'", query)
+		}
+	})
+
+	t.Run("should return generated columns only", func(t *testing.T) {
+		generated := repo.ReturningGenerated()
+
+		query, _ := generated.insertStatement(&TestUser{Email: "a@example.com"})
+		if !contains(query, "RETURNING id, created_at, updated_at") {
+			t.Errorf("Expected generated columns, got '%s'", query)
+		}
+
+		query, _ = generated.updateStatement(&TestUser{ID: 7, Email: "a@example.com"})
+		if !contains(query, "WHERE id = $5 RETURNING id, created_at, updated_at") {
+			t.Errorf("Expected generated columns, got '%s'", query)
+		}
+
+		if !contains(repo.returningClause(), "*") {
+			t.Errorf("Expected original repository to be unchanged")
+		}
+	})
+
+	t.Run("should merge returned columns into input entity", func(t *testing.T) {
+		generated := repo.ReturningGenerated()
+		created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		input := &TestUser{Email: "a@example.com", Username: "a", Age: 30}
+
+		saved, err := generated.scanReturning(fakeRow{int64(9), created, created}, input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if saved.ID != 9 || !saved.CreatedAt.Equal(created) || saved.Email != "a@example.com" || saved.Age != 30 {
+			t.Errorf("Expected merged entity, got %+v", saved)
+		}
+		if input.ID != 0 {
+			t.Errorf("Expected input entity to be unchanged, got ID %d", input.ID)
+		}
+	})
+
+	t.Run("should reject unknown returning columns", func(t *testing.T) {
+		_, err := repo.SaveReturning(context.Background(), &TestUser{}, "nickname")
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+}
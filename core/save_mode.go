@@ -0,0 +1,54 @@
+package core
+
+import "context"
+
+// SaveMode controls whether Save inserts or updates an entity
+type SaveMode int
+
+const (
+	// SaveAuto inserts entities with a zero primary key and updates the rest (default)
+	SaveAuto SaveMode = iota
+	// SaveAlwaysInsert always inserts, for client-assigned or natural keys that may be zero
+	SaveAlwaysInsert
+	// SaveAlwaysUpdate always updates by primary key, even when it is zero
+	SaveAlwaysUpdate
+)
+
+// String returns the configuration name of the save mode
+func (m SaveMode) String() string {
+	switch m {
+	case SaveAlwaysInsert:
+		return "always_insert"
+	case SaveAlwaysUpdate:
+		return "always_update"
+	default:
+		return "auto"
+	}
+}
+
+// WithSaveMode returns a repository whose Save, SaveAll and SaveBatch use the given mode
+func (r *BaseRepository[T, ID]) WithSaveMode(mode SaveMode) *BaseRepository[T, ID] {
+	repo := *r
+	repo.saveMode = mode
+	return &repo
+}
+
+// Insert inserts an entity regardless of its primary key value
+func (r *BaseRepository[T, ID]) Insert(ctx context.Context, entity *T) (*T, error) {
+	if r.tx != nil {
+		return r.insertTx(ctx, entity, r.txConn(ctx))
+	}
+	return r.insert(ctx, entity, r.poolConn(ctx))
+}
+
+// isInsert reports whether Save should insert the entity under the repository's save mode
+func (r *BaseRepository[T, ID]) isInsert(entity *T) bool {
+	switch r.saveMode {
+	case SaveAlwaysInsert:
+		return true
+	case SaveAlwaysUpdate:
+		return false
+	default:
+		return r.isZeroValue(r.getPKValue(entity))
+	}
+}
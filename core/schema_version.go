@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// requiredSchemaVersion is the highest version passed to RequireSchemaVersion
+var requiredSchemaVersion atomic.Int64
+
+// RequireSchemaVersion records that the application expects the database to
+// be migrated to at least version. Repositories generated with a migrations
+// directory call it from init with the latest migration at generation time;
+// the highest version registered wins.
+func RequireSchemaVersion(version int64) {
+	for {
+		current := requiredSchemaVersion.Load()
+		if version <= current || requiredSchemaVersion.CompareAndSwap(current, version) {
+			return
+		}
+	}
+}
+
+// RequiredSchemaVersion returns the highest version registered with
+// RequireSchemaVersion, or 0
+func RequiredSchemaVersion() int64 {
+	return requiredSchemaVersion.Load()
+}
+
+// SchemaVersion returns the highest migration version applied to the
+// database, or 0 when no migration has been applied
+func (db *Database) SchemaVersion(ctx context.Context) (int64, error) {
+	table := db.config.MigrationTable
+	if table == "" {
+		table = "schema_migrations"
+	}
+
+	var version *int64
+	err := db.pool.QueryRow(ctx, fmt.Sprintf("SELECT MAX(version) FROM %s", table)).Scan(&version)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P01" { // undefined_table
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version == nil {
+		return 0, nil
+	}
+	return *version, nil
+}
+
+// VerifySchemaVersion returns ErrSchemaVersionMismatch when the database is
+// older than Config.SchemaVersion or the version required by generated code,
+// catching deployments that ship code before its migrations
+func (db *Database) VerifySchemaVersion(ctx context.Context) error {
+	required := max(db.config.SchemaVersion, RequiredSchemaVersion())
+	if required == 0 {
+		return nil
+	}
+
+	current, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current < required {
+		return fmt.Errorf("%w: database is at version %d, application requires %d", ErrSchemaVersionMismatch, current, required)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequireSchemaVersion(t *testing.T) {
+	previous := RequiredSchemaVersion()
+	defer requiredSchemaVersion.Store(previous)
+
+	requiredSchemaVersion.Store(0)
+	RequireSchemaVersion(20240102000000)
+	RequireSchemaVersion(20240101000000)
+	if got := RequiredSchemaVersion(); got != 20240102000000 {
+		t.Errorf("Expected the highest version to win, got %d", got)
+	}
+
+	requiredSchemaVersion.Store(0)
+	db := &Database{}
+	if err := db.VerifySchemaVersion(context.Background()); err != nil {
+		t.Errorf("Expected no check without a required version, got %v", err)
+	}
+}
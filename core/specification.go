@@ -2,8 +2,9 @@ package core
 
 import (
 	"fmt"
-	"regexp"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Specification represents a composable query criteria
@@ -28,9 +29,25 @@ type baseSpecification[T any] struct {
 	operator    string // "AND", "OR", "NOT"
 	left        Specification[T]
 	right       Specification[T]
+	condition   *fieldCondition // structured form of field comparisons, for JSON
 }
 
-var placeholderRegex = regexp.MustCompile(`\$(\d+)`)
+// fieldCondition records the field and operator of a specification built by
+// a comparison helper such as Equal or In; its values are the specification args
+type fieldCondition struct {
+	field  string
+	op     string        // a key of conditionOps
+	values []interface{} // values when they differ from the args, as for wide In lists
+}
+
+// fieldSpec creates a leaf specification that remembers its field comparison
+func fieldSpec[T any](field, op, whereClause string, args ...interface{}) Specification[T] {
+	return &baseSpecification[T]{
+		whereClause: whereClause,
+		args:        args,
+		condition:   &fieldCondition{field: field, op: op},
+	}
+}
 
 // ToSQL converts the specification to SQL WHERE clause and arguments
 func (s *baseSpecification[T]) ToSQL() (string, []interface{}) {
@@ -39,64 +56,63 @@ func (s *baseSpecification[T]) ToSQL() (string, []interface{}) {
 		return s.whereClause, s.args
 	}
 	
-	// Composite specification
-	var parts []string
-	var allArgs []interface{}
-	
-	var leftArgs []interface{}
-	if s.left != nil {
-		leftSQL, args := s.left.ToSQL()
-		if leftSQL != "" {
-			// Renumber placeholders in left SQL starting from 1
-			leftSQL = renumberPlaceholders(leftSQL, 1)
-			parts = append(parts, fmt.Sprintf("(%s)", leftSQL))
-			leftArgs = args
-			allArgs = append(allArgs, leftArgs...)
+	// Composite specification: placeholders are numbered in a single pass
+	var args []interface{}
+	sql := s.build(&args)
+	if sql == "" {
+		return "", nil
+	}
+	return sql, args
+}
+
+// build renders the specification with its placeholders numbered after the
+// arguments already collected in args, then appends its own arguments.
+// Threading the offset through the whole tree keeps numbering correct at any depth.
+func (s *baseSpecification[T]) build(args *[]interface{}) string {
+	if s.operator == "" {
+		if s.whereClause == "" {
+			return ""
 		}
+		sql := RenumberPlaceholders(s.whereClause, len(*args)+1)
+		*args = append(*args, s.args...)
+		return sql
 	}
 	
-	if s.right != nil {
-		rightSQL, rightArgs := s.right.ToSQL()
-		if rightSQL != "" {
-			// Renumber placeholders in right SQL starting after left args
-			rightSQL = renumberPlaceholders(rightSQL, len(leftArgs)+1)
-			parts = append(parts, fmt.Sprintf("(%s)", rightSQL))
-			allArgs = append(allArgs, rightArgs...)
+	var parts []string
+	for _, child := range []Specification[T]{s.left, s.right} {
+		if child == nil {
+			continue
+		}
+		if sql := buildSpec(child, args); sql != "" {
+			parts = append(parts, fmt.Sprintf("(%s)", sql))
 		}
 	}
 	
 	if len(parts) == 0 {
-		return "", nil
+		return ""
 	}
 	
 	if s.operator == "NOT" {
-		if len(parts) > 0 {
-			return fmt.Sprintf("NOT %s", parts[0]), allArgs
-		}
-		return "", nil
+		return fmt.Sprintf("NOT %s", parts[0])
 	}
 	
-	return strings.Join(parts, fmt.Sprintf(" %s ", s.operator)), allArgs
+	return strings.Join(parts, fmt.Sprintf(" %s ", s.operator))
 }
 
-// renumberPlaceholders renumbers SQL placeholders starting from startNum
-// For example, if sql is "field = $1 AND other = $2" and startNum is 3,
-// it becomes "field = $3 AND other = $4"
-func renumberPlaceholders(sql string, startNum int) string {
-	if startNum == 1 {
-		// No renumbering needed
-		return sql
+// buildSpec renders any Specification into a larger tree, renumbering
+// implementations other than baseSpecification from their $1-based ToSQL output
+func buildSpec[T any](spec Specification[T], args *[]interface{}) string {
+	if base, ok := spec.(*baseSpecification[T]); ok {
+		return base.build(args)
 	}
 	
-	offset := startNum - 1
-	return placeholderRegex.ReplaceAllStringFunc(sql, func(match string) string {
-		// Extract the number from $1, $2, etc.
-		var num int
-		fmt.Sscanf(match, "$%d", &num)
-		// Renumber: if original was $1 and startNum is 3, result is $3
-		// So: newNum = oldNum + (startNum - 1)
-		return fmt.Sprintf("$%d", num+offset)
-	})
+	sql, specArgs := spec.ToSQL()
+	if sql == "" {
+		return ""
+	}
+	sql = RenumberPlaceholders(sql, len(*args)+1)
+	*args = append(*args, specArgs...)
+	return sql
 }
 
 // And combines this specification with another using AND
@@ -135,6 +151,7 @@ func Where[T any](whereClause string, args ...interface{}) Specification[T] {
 
 // And combines multiple specifications using AND
 func And[T any](specs ...Specification[T]) Specification[T] {
+	specs = nonNilSpecs(specs)
 	if len(specs) == 0 {
 		return nil
 	}
@@ -151,6 +168,7 @@ func And[T any](specs ...Specification[T]) Specification[T] {
 
 // Or combines multiple specifications using OR
 func Or[T any](specs ...Specification[T]) Specification[T] {
+	specs = nonNilSpecs(specs)
 	if len(specs) == 0 {
 		return nil
 	}
@@ -165,6 +183,17 @@ func Or[T any](specs ...Specification[T]) Specification[T] {
 	return result
 }
 
+// nonNilSpecs drops nil specifications so optional filters can be passed directly
+func nonNilSpecs[T any](specs []Specification[T]) []Specification[T] {
+	result := make([]Specification[T], 0, len(specs))
+	for _, spec := range specs {
+		if spec != nil {
+			result = append(result, spec)
+		}
+	}
+	return result
+}
+
 // Not negates a specification
 func Not[T any](spec Specification[T]) Specification[T] {
 	if spec == nil {
@@ -177,86 +206,170 @@ func Not[T any](spec Specification[T]) Specification[T] {
 
 // Equal creates a specification for field = value
 func Equal[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s = $1", field), value)
+	return fieldSpec[T](field, "eq", fmt.Sprintf("%s = $1", field), value)
 }
 
 // NotEqual creates a specification for field != value
 func NotEqual[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s != $1", field), value)
+	return fieldSpec[T](field, "ne", fmt.Sprintf("%s != $1", field), value)
 }
 
 // GreaterThan creates a specification for field > value
 func GreaterThan[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s > $1", field), value)
+	return fieldSpec[T](field, "gt", fmt.Sprintf("%s > $1", field), value)
 }
 
 // GreaterThanEqual creates a specification for field >= value
 func GreaterThanEqual[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s >= $1", field), value)
+	return fieldSpec[T](field, "gte", fmt.Sprintf("%s >= $1", field), value)
 }
 
 // LessThan creates a specification for field < value
 func LessThan[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s < $1", field), value)
+	return fieldSpec[T](field, "lt", fmt.Sprintf("%s < $1", field), value)
 }
 
 // LessThanEqual creates a specification for field <= value
 func LessThanEqual[T any](field string, value interface{}) Specification[T] {
-	return Where[T](fmt.Sprintf("%s <= $1", field), value)
+	return fieldSpec[T](field, "lte", fmt.Sprintf("%s <= $1", field), value)
 }
 
 // Like creates a specification for field LIKE pattern
 func Like[T any](field string, pattern string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s LIKE $1", field), pattern)
+	return fieldSpec[T](field, "like", fmt.Sprintf("%s LIKE $1", field), pattern)
 }
 
+// InListLimit is the number of values above which In and NotIn stop
+// binding one placeholder per value. Lists of one Go kind are bound as a
+// single array, field = ANY($1::type[]); mixed lists are split into OR (or,
+// for NotIn, AND) groups of InListLimit placeholders each.
+var InListLimit = 1000
+
 // In creates a specification for field IN (values...)
 func In[T any](field string, values ...interface{}) Specification[T] {
 	if len(values) == 0 {
-		return Where[T]("1 = 0") // Always false
-	}
-	
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		return fieldSpec[T](field, "in", "1 = 0") // Always false
 	}
-	
-	return Where[T](
-		fmt.Sprintf("%s IN (%s)", field, strings.Join(placeholders, ", ")),
-		values...,
-	)
+	return listSpec[T](field, "in", values)
 }
 
 // NotIn creates a specification for field NOT IN (values...)
 func NotIn[T any](field string, values ...interface{}) Specification[T] {
 	if len(values) == 0 {
-		return Where[T]("1 = 1") // Always true
+		return fieldSpec[T](field, "not_in", "1 = 1") // Always true
 	}
-	
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	return listSpec[T](field, "not_in", values)
+}
+
+// listSpec builds In and NotIn, switching to an array parameter or to
+// chunked lists above InListLimit
+func listSpec[T any](field, op string, values []interface{}) Specification[T] {
+	in, quantifier, join := "IN", "= ANY", " OR "
+	if op == "not_in" {
+		in, quantifier, join = "NOT IN", "<> ALL", " AND "
 	}
-	
-	return Where[T](
-		fmt.Sprintf("%s NOT IN (%s)", field, strings.Join(placeholders, ", ")),
-		values...,
-	)
+	if len(values) <= InListLimit || InListLimit <= 0 {
+		return fieldSpec[T](field, op, fmt.Sprintf("%s %s (%s)", field, in, placeholderList(1, len(values))), values...)
+	}
+
+	spec := &baseSpecification[T]{condition: &fieldCondition{field: field, op: op, values: values}}
+	if array, cast, ok := arrayParameter(values); ok {
+		spec.whereClause = fmt.Sprintf("%s %s($1%s)", field, quantifier, cast)
+		spec.args = []interface{}{array}
+		return spec
+	}
+
+	var groups []string
+	for start := 0; start < len(values); start += InListLimit {
+		n := min(InListLimit, len(values)-start)
+		groups = append(groups, fmt.Sprintf("%s %s (%s)", field, in, placeholderList(start+1, n)))
+	}
+	spec.whereClause = "(" + strings.Join(groups, join) + ")"
+	spec.args = values
+	return spec
+}
+
+// placeholderList returns n comma-separated placeholders numbered from first
+func placeholderList(first, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", first+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// arrayParameter converts values of one Go kind into a typed slice bound as
+// a single array parameter, with the cast that names its element type.
+// Strings are left uncast so the server infers the column's own array type,
+// as text, varchar, uuid and enum columns all hold Go strings.
+func arrayParameter(values []interface{}) (interface{}, string, bool) {
+	switch values[0].(type) {
+	case string:
+		if allOf[string](values) {
+			return typedSlice[string](values), "", true
+		}
+	case bool:
+		if allOf[bool](values) {
+			return typedSlice[bool](values), "::boolean[]", true
+		}
+	case float64:
+		if allOf[float64](values) {
+			return typedSlice[float64](values), "::double precision[]", true
+		}
+	case time.Time:
+		if allOf[time.Time](values) {
+			return typedSlice[time.Time](values), "::timestamptz[]", true
+		}
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		ints := make([]int64, len(values))
+		for i, value := range values {
+			v := reflect.ValueOf(value)
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				ints[i] = v.Int()
+			case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+				ints[i] = int64(v.Uint())
+			default:
+				return nil, "", false
+			}
+		}
+		return ints, "::bigint[]", true
+	}
+	return nil, "", false
+}
+
+// allOf reports whether every value has type E
+func allOf[E any](values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(E); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// typedSlice converts values, all of type E, to []E
+func typedSlice[E any](values []interface{}) []E {
+	typed := make([]E, len(values))
+	for i, value := range values {
+		typed[i] = value.(E)
+	}
+	return typed
 }
 
 // IsNull creates a specification for field IS NULL
 func IsNull[T any](field string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s IS NULL", field))
+	return fieldSpec[T](field, "is_null", fmt.Sprintf("%s IS NULL", field))
 }
 
 // IsNotNull creates a specification for field IS NOT NULL
 func IsNotNull[T any](field string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s IS NOT NULL", field))
+	return fieldSpec[T](field, "is_not_null", fmt.Sprintf("%s IS NOT NULL", field))
 }
 
 // Between creates a specification for field BETWEEN min AND max
 func Between[T any](field string, min, max interface{}) Specification[T] {
-	return Where[T](
+	return fieldSpec[T](field, "between",
 		fmt.Sprintf("%s BETWEEN $1 AND $2", field),
 		min, max,
 	)
@@ -264,16 +377,282 @@ func Between[T any](field string, min, max interface{}) Specification[T] {
 
 // Contains creates a specification for field LIKE '%value%'
 func Contains[T any](field string, value string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s LIKE $1", field), "%"+value+"%")
+	return fieldSpec[T](field, "like", fmt.Sprintf("%s LIKE $1", field), "%"+value+"%")
 }
 
 // StartsWith creates a specification for field LIKE 'value%'
 func StartsWith[T any](field string, value string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s LIKE $1", field), value+"%")
+	return fieldSpec[T](field, "like", fmt.Sprintf("%s LIKE $1", field), value+"%")
 }
 
 // EndsWith creates a specification for field LIKE '%value'
 func EndsWith[T any](field string, value string) Specification[T] {
-	return Where[T](fmt.Sprintf("%s LIKE $1", field), "%"+value)
+	return fieldSpec[T](field, "like", fmt.Sprintf("%s LIKE $1", field), "%"+value)
+}
+
+// EqualIgnoreCase creates a specification for LOWER(field) = LOWER(value).
+// An index on the column is not used; the expression index on LOWER(field)
+// generated for jet:"lower_index" is.
+func EqualIgnoreCase[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "ieq", fmt.Sprintf("LOWER(%s) = LOWER($1)", field), value)
+}
+
+// ContainsIgnoreCase creates a specification for field ILIKE '%value%'.
+// Only a trigram index (jet:"trgm_index") serves it.
+func ContainsIgnoreCase[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "ilike", fmt.Sprintf("%s ILIKE $1", field), "%"+value+"%")
+}
+
+// StartsWithIgnoreCase creates a specification for field ILIKE 'value%'
+func StartsWithIgnoreCase[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "ilike", fmt.Sprintf("%s ILIKE $1", field), value+"%")
+}
+
+// EndsWithIgnoreCase creates a specification for field ILIKE '%value'
+func EndsWithIgnoreCase[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "ilike", fmt.Sprintf("%s ILIKE $1", field), "%"+value)
+}
+
+// UnaccentFunction is the function EqualUnaccent and ContainsUnaccent strip
+// accents with. It defaults to unaccent from the unaccent extension, which is
+// not immutable and cannot appear in an index; set it to immutable_unaccent,
+// the wrapper created for jet:"unaccent_index", to let queries use the index.
+var UnaccentFunction = "unaccent"
+
+// EqualUnaccent creates a specification matching field and value ignoring
+// case and accents: LOWER(unaccent(field)) = LOWER(unaccent(value))
+func EqualUnaccent[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "unaccent_eq", fmt.Sprintf("LOWER(%s(%s)) = LOWER(%s($1))", UnaccentFunction, field, UnaccentFunction), value)
+}
+
+// ContainsUnaccent creates a specification for unaccent(field) ILIKE
+// unaccent('%value%'), ignoring case and accents
+func ContainsUnaccent[T any](field string, value string) Specification[T] {
+	return fieldSpec[T](field, "unaccent_ilike", fmt.Sprintf("%s(%s) ILIKE %s($1)", UnaccentFunction, field, UnaccentFunction), "%"+value+"%")
+}
+
+// InTuples creates a specification for (col1, col2) IN (($1, $2), ($3, $4), ...).
+// It fails with ErrInvalidInput if a tuple does not have one value per column.
+func InTuples[T any](columns string, tuples [][]interface{}) (Specification[T], error) {
+	if len(tuples) == 0 {
+		return Where[T]("1 = 0"), nil // Always false
+	}
+
+	columns = tupleColumns(columns)
+	width := tupleWidth(columns)
+	var args []interface{}
+	groups := make([]string, len(tuples))
+	for i, tuple := range tuples {
+		if len(tuple) != width {
+			return nil, fmt.Errorf("%w: tuple %d has %d values for %d columns %s", ErrInvalidInput, i, len(tuple), width, columns)
+		}
+		groups[i] = tuplePlaceholders(len(args)+1, len(tuple))
+		args = append(args, tuple...)
+	}
+
+	return Where[T](
+		fmt.Sprintf("%s IN (%s)", columns, strings.Join(groups, ", ")),
+		args...,
+	), nil
+}
+
+// TupleGreaterThan creates a specification for (col1, col2) > ($1, $2)
+func TupleGreaterThan[T any](columns string, values ...interface{}) (Specification[T], error) {
+	return tupleCompare[T](columns, ">", values)
+}
+
+// TupleGreaterThanEqual creates a specification for (col1, col2) >= ($1, $2)
+func TupleGreaterThanEqual[T any](columns string, values ...interface{}) (Specification[T], error) {
+	return tupleCompare[T](columns, ">=", values)
+}
+
+// TupleLessThan creates a specification for (col1, col2) < ($1, $2)
+func TupleLessThan[T any](columns string, values ...interface{}) (Specification[T], error) {
+	return tupleCompare[T](columns, "<", values)
+}
+
+// TupleLessThanEqual creates a specification for (col1, col2) <= ($1, $2)
+func TupleLessThanEqual[T any](columns string, values ...interface{}) (Specification[T], error) {
+	return tupleCompare[T](columns, "<=", values)
+}
+
+// tupleCompare builds a row-value comparison between columns and values.
+// It fails with ErrInvalidInput unless there is one value per column.
+func tupleCompare[T any](columns string, op string, values []interface{}) (Specification[T], error) {
+	columns = tupleColumns(columns)
+	if width := tupleWidth(columns); len(values) != width {
+		return nil, fmt.Errorf("%w: %d values for %d columns %s", ErrInvalidInput, len(values), width, columns)
+	}
+	return Where[T](
+		fmt.Sprintf("%s %s %s", columns, op, tuplePlaceholders(1, len(values))),
+		values...,
+	), nil
+}
+
+// tupleColumns wraps a column list in parentheses if it is not already
+func tupleColumns(columns string) string {
+	columns = strings.TrimSpace(columns)
+	if strings.HasPrefix(columns, "(") && strings.HasSuffix(columns, ")") {
+		return columns
+	}
+	return "(" + columns + ")"
+}
+
+// tupleWidth counts the columns of a parenthesized column list, skipping
+// commas inside function calls and quoted identifiers
+func tupleWidth(columns string) int {
+	width, depth := 1, 0
+	quoted := false
+	for _, r := range columns[1 : len(columns)-1] {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			width++
+		}
+	}
+	return width
+}
+
+// tuplePlaceholders returns "($start, $start+1, ...)" for n values
+func tuplePlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// SubqueryBuilder is implemented by query builders (e.g. query.QueryBuilder)
+// that can render a subquery with its arguments
+type SubqueryBuilder interface {
+	Build() (string, []interface{})
+}
+
+// ExistsSubquery creates a specification for EXISTS (subquery).
+// The subquery numbers its placeholders from $1 and may reference the outer table.
+func ExistsSubquery[T any](subSQL string, args ...interface{}) Specification[T] {
+	return Where[T](fmt.Sprintf("EXISTS (%s)", subSQL), args...)
+}
+
+// NotExistsSubquery creates a specification for NOT EXISTS (subquery)
+func NotExistsSubquery[T any](subSQL string, args ...interface{}) Specification[T] {
+	return Where[T](fmt.Sprintf("NOT EXISTS (%s)", subSQL), args...)
+}
+
+// ExistsQuery creates an EXISTS specification from a query builder
+func ExistsQuery[T any](builder SubqueryBuilder) Specification[T] {
+	subSQL, args := builder.Build()
+	return ExistsSubquery[T](subSQL, args...)
+}
+
+// NotExistsQuery creates a NOT EXISTS specification from a query builder
+func NotExistsQuery[T any](builder SubqueryBuilder) Specification[T] {
+	subSQL, args := builder.Build()
+	return NotExistsSubquery[T](subSQL, args...)
+}
+
+// ExistsIn creates a specification matching T rows with at least one related
+// U row, where foreignKey is the U column referencing T's primary key and sub
+// filters the related rows (nil for any). For example
+//
+//	ExistsIn[User]("user_id", Equal[Order]("status", "paid"))
+//
+// renders EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id AND
+// (status = $1)). Soft-deleted U rows are ignored. It panics if T or U is not
+// a valid entity.
+func ExistsIn[T, U any](foreignKey string, sub Specification[U]) Specification[T] {
+	subSQL, args := relatedSubquery[T](foreignKey, "1", sub)
+	return ExistsSubquery[T](subSQL, args...)
+}
+
+// NotExistsIn creates a specification matching T rows without a related U row
+// matching sub, the negation of ExistsIn
+func NotExistsIn[T, U any](foreignKey string, sub Specification[U]) Specification[T] {
+	subSQL, args := relatedSubquery[T](foreignKey, "1", sub)
+	return NotExistsSubquery[T](subSQL, args...)
+}
+
+// InSubquery creates a specification for field IN (SELECT column FROM <U
+// table> WHERE sub), e.g. InSubquery[Order]("user_id", "id", Equal[User]("plan", "pro")).
+// Soft-deleted U rows are ignored. It panics if U is not a valid entity.
+func InSubquery[T, U any](field, column string, sub Specification[U]) Specification[T] {
+	subSQL, args := entitySubquery(mustEntity[U](), "", column, "", sub)
+	return Where[T](fmt.Sprintf("%s IN (%s)", field, subSQL), args...)
+}
+
+// NotInSubquery creates a specification for field NOT IN (SELECT column FROM
+// <U table> WHERE sub). The subquery column must not be NULL, as with any NOT IN.
+func NotInSubquery[T, U any](field, column string, sub Specification[U]) Specification[T] {
+	subSQL, args := entitySubquery(mustEntity[U](), "", column, "", sub)
+	return Where[T](fmt.Sprintf("%s NOT IN (%s)", field, subSQL), args...)
+}
+
+// relatedSubquery selects from U's table the rows whose foreignKey references
+// the enclosing T row
+func relatedSubquery[T, U any](foreignKey, selectExpr string, sub Specification[U]) (string, []interface{}) {
+	outer := mustEntity[T]()
+	inner := mustEntity[U]()
+
+	// A self-referencing subquery needs an alias to reach the outer row
+	alias := ""
+	ref := inner.TableName
+	if inner.TableName == outer.TableName {
+		alias = inner.TableName + "_sub"
+		ref = alias
+	}
+	correlation := fmt.Sprintf("%s.%s = %s.%s", ref, foreignKey, outer.TableName, outer.PrimaryKey.DBName)
+	return entitySubquery(inner, alias, selectExpr, correlation, sub)
+}
+
+// entitySubquery renders SELECT selectExpr FROM the entity's table, filtered
+// by the correlation condition, sub, the entity's soft delete column and, on
+// a shared table, its discriminator.
+// Placeholders are numbered from $1.
+func entitySubquery[U any](inner *Entity, alias, selectExpr, correlation string, sub Specification[U]) (string, []interface{}) {
+	from := inner.TableName
+	qualifier := inner.TableName
+	if alias != "" {
+		from += " AS " + alias
+		qualifier = alias
+	}
+
+	var conditions []string
+	if correlation != "" {
+		conditions = append(conditions, correlation)
+	}
+	var args []interface{}
+	if sub != nil {
+		if subSQL, subArgs := sub.ToSQL(); subSQL != "" {
+			conditions = append(conditions, "("+subSQL+")")
+			args = subArgs
+		}
+	}
+	if inner.SoftDelete != nil {
+		conditions = append(conditions, fmt.Sprintf("%s.%s IS NULL", qualifier, inner.SoftDelete.DBName))
+	}
+	if d := inner.Discriminator; d != nil {
+		conditions = append(conditions, fmt.Sprintf("%s.%s = %s", qualifier, d.Column, quoteString(d.Value)))
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s", selectExpr, from)
+	if len(conditions) > 0 {
+		sql += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return sql, args
 }
 
+// mustEntity returns the registered metadata of T, panicking on
+// misconfigured entities since specifications cannot report errors
+func mustEntity[T any]() *Entity {
+	entity, err := RegisterEntity[T]()
+	if err != nil {
+		panic(fmt.Sprintf("jetorm: %T is not a valid entity: %v", *new(T), err))
+	}
+	return entity
+}
@@ -0,0 +1,185 @@
+package core
+
+import "fmt"
+
+// SpecNodeKind identifies the kind of a SpecNode
+type SpecNodeKind int
+
+const (
+	// SpecCondition compares a field with values
+	SpecCondition SpecNodeKind = iota
+	// SpecAnd matches when every child matches
+	SpecAnd
+	// SpecOr matches when any child matches
+	SpecOr
+	// SpecNot matches when its single child does not
+	SpecNot
+	// SpecRaw is SQL built by Where or other helpers without a structured form
+	SpecRaw
+)
+
+// String returns the kind's name
+func (k SpecNodeKind) String() string {
+	switch k {
+	case SpecCondition:
+		return "condition"
+	case SpecAnd:
+		return "and"
+	case SpecOr:
+		return "or"
+	case SpecNot:
+		return "not"
+	case SpecRaw:
+		return "raw"
+	}
+	return fmt.Sprintf("SpecNodeKind(%d)", int(k))
+}
+
+// SpecNode is a node of a specification's syntax tree. Conditions carry the
+// column, the operator (eq, ne, gt, gte, lt, lte, like, ilike, ieq,
+// unaccent_eq, unaccent_ilike, in, not_in, between, is_null or is_not_null,
+// as in the JSON form) and the values; raw nodes
+// carry SQL with placeholders numbered from $1 and its arguments.
+type SpecNode struct {
+	Kind     SpecNodeKind
+	Children []*SpecNode   // Operands of And and Or, the operand of Not
+	Field    string        // Column of a condition
+	Op       string        // Operator of a condition
+	Values   []interface{} // Values of a condition: none for is_null, two for between
+	SQL      string        // SQL of a raw node
+	Args     []interface{} // Arguments of a raw node
+}
+
+// SpecAST returns the syntax tree of a specification, or nil for a nil or
+// empty one. Chains built by And and Or are flattened into one node, so
+// And(a, b, c) has three children. Specifications other than those built
+// by this package become raw nodes of their ToSQL output.
+func SpecAST[T any](spec Specification[T]) *SpecNode {
+	if spec == nil {
+		return nil
+	}
+	base, ok := spec.(*baseSpecification[T])
+	if !ok {
+		sql, args := spec.ToSQL()
+		if sql == "" {
+			return nil
+		}
+		return &SpecNode{Kind: SpecRaw, SQL: sql, Args: args}
+	}
+
+	switch base.operator {
+	case "AND", "OR":
+		kind := SpecAnd
+		if base.operator == "OR" {
+			kind = SpecOr
+		}
+		var children []*SpecNode
+		for _, child := range []Specification[T]{base.left, base.right} {
+			node := SpecAST(child)
+			switch {
+			case node == nil:
+			case node.Kind == kind:
+				children = append(children, node.Children...)
+			default:
+				children = append(children, node)
+			}
+		}
+		switch len(children) {
+		case 0:
+			return nil
+		case 1:
+			return children[0]
+		}
+		return &SpecNode{Kind: kind, Children: children}
+	case "NOT":
+		child := SpecAST(base.left)
+		if child == nil {
+			return nil
+		}
+		return &SpecNode{Kind: SpecNot, Children: []*SpecNode{child}}
+	}
+
+	if base.condition != nil {
+		values := base.args
+		if base.condition.values != nil {
+			values = base.condition.values
+		}
+		return &SpecNode{Kind: SpecCondition, Field: base.condition.field, Op: base.condition.op, Values: values}
+	}
+	if base.whereClause == "" {
+		return nil
+	}
+	return &SpecNode{Kind: SpecRaw, SQL: base.whereClause, Args: base.args}
+}
+
+// Walk visits the node and its descendants depth-first, skipping the
+// children of nodes for which fn returns false
+func (n *SpecNode) Walk(fn func(*SpecNode) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Fields returns the columns compared by the tree's conditions, in order of
+// first use. Columns referenced by raw nodes are not included.
+func (n *SpecNode) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	n.Walk(func(node *SpecNode) bool {
+		if node.Kind == SpecCondition && !seen[node.Field] {
+			seen[node.Field] = true
+			fields = append(fields, node.Field)
+		}
+		return true
+	})
+	return fields
+}
+
+// SpecVisitor translates a specification tree bottom-up into R, such as a
+// query builder expression or a filter for another backend
+type SpecVisitor[R any] interface {
+	Condition(field, op string, values []interface{}) (R, error)
+	And(children []R) (R, error)
+	Or(children []R) (R, error)
+	Not(child R) (R, error)
+	Raw(sql string, args []interface{}) (R, error)
+}
+
+// VisitSpec translates the tree with v, visiting children before their
+// parent. A nil node yields the zero R.
+func VisitSpec[R any](n *SpecNode, v SpecVisitor[R]) (R, error) {
+	var zero R
+	if n == nil {
+		return zero, nil
+	}
+	switch n.Kind {
+	case SpecCondition:
+		return v.Condition(n.Field, n.Op, n.Values)
+	case SpecRaw:
+		return v.Raw(n.SQL, n.Args)
+	}
+
+	children := make([]R, len(n.Children))
+	for i, child := range n.Children {
+		result, err := VisitSpec(child, v)
+		if err != nil {
+			return zero, err
+		}
+		children[i] = result
+	}
+	switch n.Kind {
+	case SpecAnd:
+		return v.And(children)
+	case SpecOr:
+		return v.Or(children)
+	case SpecNot:
+		if len(children) != 1 {
+			return zero, fmt.Errorf("%w: not takes one operand, got %d", ErrInvalidInput, len(children))
+		}
+		return v.Not(children[0])
+	}
+	return zero, fmt.Errorf("%w: unknown specification node kind %v", ErrInvalidInput, n.Kind)
+}
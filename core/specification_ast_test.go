@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeVisitor renders a tree as nested calls, e.g. and(gt(age,[18]),...)
+type describeVisitor struct{}
+
+func (describeVisitor) Condition(field, op string, values []interface{}) (string, error) {
+	return fmt.Sprintf("%s(%s,%v)", op, field, values), nil
+}
+
+func (describeVisitor) And(children []string) (string, error) {
+	return "and(" + strings.Join(children, ",") + ")", nil
+}
+
+func (describeVisitor) Or(children []string) (string, error) {
+	return "or(" + strings.Join(children, ",") + ")", nil
+}
+
+func (describeVisitor) Not(child string) (string, error) {
+	return "not(" + child + ")", nil
+}
+
+func (describeVisitor) Raw(sql string, args []interface{}) (string, error) {
+	return fmt.Sprintf("raw(%s,%v)", sql, args), nil
+}
+
+// rejectRaw fails on raw nodes, as a backend without SQL would
+type rejectRaw struct{ describeVisitor }
+
+func (rejectRaw) Raw(sql string, args []interface{}) (string, error) {
+	return "", errors.New("raw SQL not supported")
+}
+
+func TestSpecAST(t *testing.T) {
+	t.Run("should flatten chains and keep structure", func(t *testing.T) {
+		spec := And(
+			GreaterThanEqual[TestUser]("age", 18),
+			And(IsNotNull[TestUser]("email"), Or(In[TestUser]("id", 1, 2), Not(Like[TestUser]("username", "a%")))),
+			Where[TestUser]("created_at > NOW() - $1::interval", "1 day"),
+		)
+		got, err := VisitSpec(SpecAST(spec), describeVisitor{})
+		if err != nil {
+			t.Fatalf("VisitSpec failed: %v", err)
+		}
+		expected := "and(gte(age,[18]),is_not_null(email,[]),or(in(id,[1 2]),not(like(username,[a%]))),raw(created_at > NOW() - $1::interval,[1 day]))"
+		if got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("should return nil for nil and empty specifications", func(t *testing.T) {
+		if node := SpecAST[TestUser](nil); node != nil {
+			t.Errorf("Expected nil, got %+v", node)
+		}
+		if node := SpecAST(Where[TestUser]("")); node != nil {
+			t.Errorf("Expected nil, got %+v", node)
+		}
+		if node := SpecAST(Where[TestUser]("").And(Equal[TestUser]("age", 1))); node == nil || node.Kind != SpecCondition {
+			t.Errorf("Expected the remaining condition, got %+v", node)
+		}
+	})
+
+	t.Run("should turn other implementations into raw nodes", func(t *testing.T) {
+		node := SpecAST[TestUser](rawSpec{sql: "age > $1", args: []interface{}{1}})
+		if node == nil || node.Kind != SpecRaw || node.SQL != "age > $1" {
+			t.Errorf("Expected a raw node, got %+v", node)
+		}
+	})
+
+	t.Run("should walk and list fields", func(t *testing.T) {
+		node := SpecAST(And(Equal[TestUser]("age", 1), Not(Equal[TestUser]("email", "x")), Equal[TestUser]("age", 2)))
+		if fields := node.Fields(); !reflect.DeepEqual(fields, []string{"age", "email"}) {
+			t.Errorf("Expected [age email], got %v", fields)
+		}
+		var kinds []string
+		node.Walk(func(n *SpecNode) bool {
+			kinds = append(kinds, n.Kind.String())
+			return n.Kind != SpecNot
+		})
+		if strings.Join(kinds, " ") != "and condition not condition" {
+			t.Errorf("Unexpected walk order %v", kinds)
+		}
+	})
+
+	t.Run("should propagate visitor errors", func(t *testing.T) {
+		_, err := VisitSpec(SpecAST(And(Equal[TestUser]("age", 1), Where[TestUser]("age > 2"))), rejectRaw{})
+		if err == nil || err.Error() != "raw SQL not supported" {
+			t.Errorf("Expected the visitor's error, got %v", err)
+		}
+	})
+}
+
+func TestCacheKeyGenerator_KeyForSpec(t *testing.T) {
+	keys := NewCacheKeyGenerator[TestUser, int64]("user")
+	a, b, c := Equal[TestUser]("age", 1), IsNull[TestUser]("email"), In[TestUser]("id", 1, 2)
+
+	nested, err := keys.KeyForSpec(And(a, And(b, c)))
+	if err != nil {
+		t.Fatalf("KeyForSpec failed: %v", err)
+	}
+	flat, err := keys.KeyForSpec(And(a, b, c))
+	if err != nil {
+		t.Fatalf("KeyForSpec failed: %v", err)
+	}
+	if nested != flat {
+		t.Errorf("Expected equal keys, got %s and %s", nested, flat)
+	}
+	expected := `user:spec:{"and":[{"field":"age","op":"eq","value":1},{"field":"email","op":"is_null"},{"field":"id","op":"in","values":[1,2]}]}`
+	if flat != expected {
+		t.Errorf("Expected %s, got %s", expected, flat)
+	}
+
+	other, _ := keys.KeyForSpec(Or(a, b, c))
+	if other == flat {
+		t.Error("Expected different keys for AND and OR")
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SpecExplanation describes how a specification renders to SQL
+type SpecExplanation struct {
+	SQL     string        // WHERE clause with $n placeholders
+	Args    []interface{} // Bound arguments in placeholder order
+	Inlined string        // WHERE clause with arguments inlined, for logging only
+	Tree    string        // Indented dump of the composite structure
+}
+
+// String returns a human-readable report of the explanation
+func (e SpecExplanation) String() string {
+	return fmt.Sprintf("SQL: %s\nArgs: %v\nInlined: %s\nTree:\n%s", e.SQL, e.Args, e.Inlined, e.Tree)
+}
+
+// ExplainSpec renders a specification for debugging. The inlined SQL quotes
+// arguments as SQL literals but must never be executed; use SQL and Args instead.
+func ExplainSpec[T any](spec Specification[T]) SpecExplanation {
+	if spec == nil {
+		return SpecExplanation{}
+	}
+
+	sql, args := spec.ToSQL()
+
+	var tree strings.Builder
+	dumpSpec(&tree, spec, 0)
+
+	return SpecExplanation{
+		SQL:     sql,
+		Args:    args,
+		Inlined: InlineArgs(sql, args),
+		Tree:    tree.String(),
+	}
+}
+
+// InlineArgs replaces $n placeholders with quoted SQL literals of args.
+// Placeholders without a matching argument are left untouched.
+func InlineArgs(sql string, args []interface{}) string {
+	return rewritePlaceholders(sql, func(n int) string {
+		if n < 1 || n > len(args) {
+			return "$" + strconv.Itoa(n)
+		}
+		return QuoteLiteral(args[n-1])
+	})
+}
+
+// QuoteLiteral formats a value as a PostgreSQL literal
+func QuoteLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", v)
+	case []byte:
+		return "'\\x" + hex.EncodeToString(v) + "'"
+	case time.Time:
+		return quoteString(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return quoteString(v.String())
+	case string:
+		return quoteString(v)
+	default:
+		return quoteString(fmt.Sprintf("%v", v))
+	}
+}
+
+// quoteString quotes s as a standard-conforming SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// dumpSpec writes one line per node of the specification tree
+func dumpSpec[T any](b *strings.Builder, spec Specification[T], depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	base, ok := spec.(*baseSpecification[T])
+	if !ok {
+		sql, args := spec.ToSQL()
+		fmt.Fprintf(b, "%s%T: %s %v\n", indent, spec, sql, args)
+		return
+	}
+
+	if base.operator == "" {
+		fmt.Fprintf(b, "%s%s %v\n", indent, base.whereClause, base.args)
+		return
+	}
+
+	fmt.Fprintf(b, "%s%s\n", indent, base.operator)
+	if base.left != nil {
+		dumpSpec(b, base.left, depth+1)
+	}
+	if base.right != nil {
+		dumpSpec(b, base.right, depth+1)
+	}
+}
@@ -0,0 +1,333 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// conditionOps maps the JSON operators of field conditions to the helper
+// constructors that build them
+var conditionOps = map[string]func(field string, values []interface{}) (sql string, args []interface{}, err error){
+	"eq":             singleValue("="),
+	"ne":             singleValue("!="),
+	"gt":             singleValue(">"),
+	"gte":            singleValue(">="),
+	"lt":             singleValue("<"),
+	"lte":            singleValue("<="),
+	"like":           singleValue("LIKE"),
+	"ilike":          singleValue("ILIKE"),
+	"ieq":            lowerValue,
+	"unaccent_eq":    unaccentValue("LOWER(%[1]s(%[2]s)) = LOWER(%[1]s($1))"),
+	"unaccent_ilike": unaccentValue("%[1]s(%[2]s) ILIKE %[1]s($1)"),
+	"in":             listValues(In[struct{}]),
+	"not_in":         listValues(NotIn[struct{}]),
+	"between":        betweenValues,
+	"is_null":        noValue(IsNull[struct{}]),
+	"is_not_null":    noValue(IsNotNull[struct{}]),
+}
+
+func singleValue(operator string) func(string, []interface{}) (string, []interface{}, error) {
+	return func(field string, values []interface{}) (string, []interface{}, error) {
+		if len(values) != 1 {
+			return "", nil, fmt.Errorf("%w: %s %s needs one value", ErrInvalidInput, field, operator)
+		}
+		return fmt.Sprintf("%s %s $1", field, operator), values, nil
+	}
+}
+
+func lowerValue(field string, values []interface{}) (string, []interface{}, error) {
+	if len(values) != 1 {
+		return "", nil, fmt.Errorf("%w: %s ieq needs one value", ErrInvalidInput, field)
+	}
+	return fmt.Sprintf("LOWER(%s) = LOWER($1)", field), values, nil
+}
+
+// unaccentValue renders format with UnaccentFunction and the field, like
+// EqualUnaccent and ContainsUnaccent; the value is used as it is
+func unaccentValue(format string) func(string, []interface{}) (string, []interface{}, error) {
+	return func(field string, values []interface{}) (string, []interface{}, error) {
+		if len(values) != 1 {
+			return "", nil, fmt.Errorf("%w: %s needs one value", ErrInvalidInput, field)
+		}
+		return fmt.Sprintf(format, UnaccentFunction, field), values, nil
+	}
+}
+
+func listValues(build func(string, ...interface{}) Specification[struct{}]) func(string, []interface{}) (string, []interface{}, error) {
+	return func(field string, values []interface{}) (string, []interface{}, error) {
+		sql, args := build(field, values...).ToSQL()
+		return sql, args, nil
+	}
+}
+
+func betweenValues(field string, values []interface{}) (string, []interface{}, error) {
+	if len(values) != 2 {
+		return "", nil, fmt.Errorf("%w: %s between needs two values", ErrInvalidInput, field)
+	}
+	sql, args := Between[struct{}](field, values[0], values[1]).ToSQL()
+	return sql, args, nil
+}
+
+func noValue(build func(string) Specification[struct{}]) func(string, []interface{}) (string, []interface{}, error) {
+	return func(field string, values []interface{}) (string, []interface{}, error) {
+		if len(values) != 0 {
+			return "", nil, fmt.Errorf("%w: %s takes no value", ErrInvalidInput, field)
+		}
+		sql, _ := build(field).ToSQL()
+		return sql, nil, nil
+	}
+}
+
+// specNode is the JSON form of a specification tree. A node is either a
+// combination (and, or, not), a field condition (field, op and value or
+// values) or raw SQL (sql and args) for specifications built with Where.
+type specNode struct {
+	And    []*specNode       `json:"and,omitempty"`
+	Or     []*specNode       `json:"or,omitempty"`
+	Not    *specNode         `json:"not,omitempty"`
+	Field  string            `json:"field,omitempty"`
+	Op     string            `json:"op,omitempty"`
+	Value  json.RawMessage   `json:"value,omitempty"`
+	Values []json.RawMessage `json:"values,omitempty"`
+	SQL    string            `json:"sql,omitempty"`
+	Args   []json.RawMessage `json:"args,omitempty"`
+}
+
+// MarshalJSON encodes the specification tree, e.g.
+// {"and":[{"field":"age","op":"gte","value":18},{"field":"email","op":"is_not_null"}]}.
+// Specifications built with Where or other raw SQL helpers are encoded as
+// {"sql":...,"args":[...]} and can only be decoded by SpecFromTrustedJSON.
+func (s *baseSpecification[T]) MarshalJSON() ([]byte, error) {
+	node, err := encodeSpecNode(SpecAST[T](s))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(node)
+}
+
+// encodeSpecNode converts a syntax tree node into its JSON form
+func encodeSpecNode(n *SpecNode) (*specNode, error) {
+	if n == nil {
+		return &specNode{}, nil
+	}
+	switch n.Kind {
+	case SpecAnd, SpecOr, SpecNot:
+		children := make([]*specNode, len(n.Children))
+		for i, child := range n.Children {
+			node, err := encodeSpecNode(child)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = node
+		}
+		switch n.Kind {
+		case SpecAnd:
+			return &specNode{And: children}, nil
+		case SpecOr:
+			return &specNode{Or: children}, nil
+		}
+		return &specNode{Not: children[0]}, nil
+	case SpecCondition:
+		node := &specNode{Field: n.Field, Op: n.Op}
+		values, err := marshalValues(n.Values)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case "in", "not_in", "between":
+			node.Values = values
+			if node.Values == nil {
+				node.Values = []json.RawMessage{}
+			}
+		case "is_null", "is_not_null":
+		default:
+			node.Value = values[0]
+		}
+		return node, nil
+	}
+
+	args, err := marshalValues(n.Args)
+	if err != nil {
+		return nil, err
+	}
+	return &specNode{SQL: n.SQL, Args: args}, nil
+}
+
+func marshalValues(values []interface{}) ([]json.RawMessage, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	raw := make([]json.RawMessage, len(values))
+	for i, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode specification argument: %w", err)
+		}
+		raw[i] = data
+	}
+	return raw, nil
+}
+
+// SpecFromJSON decodes a specification encoded by MarshalJSON. Only field
+// conditions and their combinations are accepted: fields must be columns or
+// field names of T, and values are decoded into the field's Go type, so the
+// input may come from API clients. Raw SQL nodes fail with ErrInvalidInput.
+func SpecFromJSON[T any](data []byte) (Specification[T], error) {
+	return specFromJSON[T](data, false)
+}
+
+// SpecFromTrustedJSON decodes a specification like SpecFromJSON but also
+// accepts raw SQL nodes and conditions on columns outside T, for replaying
+// specifications the application stored itself. Never use it on client input.
+func SpecFromTrustedJSON[T any](data []byte) (Specification[T], error) {
+	return specFromJSON[T](data, true)
+}
+
+func specFromJSON[T any](data []byte, trusted bool) (Specification[T], error) {
+	entity, err := RegisterEntity[T]()
+	if err != nil {
+		return nil, err
+	}
+	var node specNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("%w: invalid specification JSON: %v", ErrInvalidInput, err)
+	}
+	d := specDecoder[T]{entity: entity, trusted: trusted}
+	return d.decode(&node)
+}
+
+type specDecoder[T any] struct {
+	entity  *Entity
+	trusted bool
+}
+
+func (d specDecoder[T]) decode(node *specNode) (Specification[T], error) {
+	switch {
+	case node.And != nil || node.Or != nil:
+		children := node.And
+		combine := And[T]
+		if node.Or != nil {
+			children = node.Or
+			combine = Or[T]
+		}
+		specs := make([]Specification[T], 0, len(children))
+		for _, child := range children {
+			if child == nil {
+				continue
+			}
+			spec, err := d.decode(child)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, spec)
+		}
+		return combine(specs...), nil
+	case node.Not != nil:
+		spec, err := d.decode(node.Not)
+		if err != nil || spec == nil {
+			return spec, err
+		}
+		return Not(spec), nil
+	case node.SQL != "":
+		if !d.trusted {
+			return nil, fmt.Errorf("%w: raw SQL specifications require SpecFromTrustedJSON", ErrInvalidInput)
+		}
+		args := make([]interface{}, len(node.Args))
+		for i, raw := range node.Args {
+			value, err := decodeUntyped(raw)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return Where[T](node.SQL, args...), nil
+	case node.Field != "":
+		return d.decodeCondition(node)
+	}
+	return nil, fmt.Errorf("%w: empty specification node", ErrInvalidInput)
+}
+
+func (d specDecoder[T]) decodeCondition(node *specNode) (Specification[T], error) {
+	build, ok := conditionOps[node.Op]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown specification operator %q", ErrInvalidInput, node.Op)
+	}
+
+	column := node.Field
+	var fieldType reflect.Type
+	if f := d.lookup(node.Field); f != nil {
+		column = f.DBName
+		fieldType = f.Type
+	} else if !d.trusted {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, node.Field)
+	}
+
+	raws := node.Values
+	if node.Value != nil {
+		raws = append([]json.RawMessage{node.Value}, raws...)
+	}
+	values := make([]interface{}, len(raws))
+	for i, raw := range raws {
+		valueType := fieldType
+		switch node.Op {
+		case "like", "ilike", "ieq", "unaccent_eq", "unaccent_ilike":
+			valueType = reflect.TypeOf("")
+		}
+		value, err := decodeValue(raw, valueType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value of %s: %v", ErrInvalidInput, node.Field, err)
+		}
+		values[i] = value
+	}
+
+	sql, args, err := build(column, values)
+	if err != nil {
+		return nil, err
+	}
+	return fieldSpec[T](column, node.Op, sql, args...), nil
+}
+
+// lookup finds a stored field of the entity by column or Go name
+func (d specDecoder[T]) lookup(name string) *Field {
+	for i := range d.entity.Fields {
+		f := &d.entity.Fields[i]
+		if !f.Ignored && (f.DBName == name || f.Name == name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes a JSON value into t, or its element type for pointers
+func decodeValue(raw json.RawMessage, t reflect.Type) (interface{}, error) {
+	if t == nil {
+		return decodeUntyped(raw)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	value := reflect.New(t)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
+
+// decodeUntyped decodes a JSON value keeping integers as int64
+func decodeUntyped(raw json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return i, nil
+		}
+		return number.Float64()
+	}
+	return value, nil
+}
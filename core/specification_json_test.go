@@ -0,0 +1,117 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSpecification_JSON(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("should encode field conditions and combinations", func(t *testing.T) {
+		spec := And(
+			GreaterThanEqual[TestUser]("age", 18),
+			IsNotNull[TestUser]("email"),
+			Or(In[TestUser]("username", "ann", "bob"), Not(StartsWith[TestUser]("email", "admin"))),
+		)
+		data, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+
+		expected := `{"and":[{"field":"age","op":"gte","value":18},{"field":"email","op":"is_not_null"},` +
+			`{"or":[{"field":"username","op":"in","values":["ann","bob"]},{"not":{"field":"email","op":"like","value":"admin%"}}]}]}`
+		if string(data) != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, data)
+		}
+	})
+
+	t.Run("should round trip to the same SQL and typed arguments", func(t *testing.T) {
+		spec := And(
+			Between[TestUser]("created_at", created, created.Add(time.Hour)),
+			Equal[TestUser]("Age", 30),
+			NotIn[TestUser]("id", int64(1), int64(2)),
+		)
+		data, _ := json.Marshal(spec)
+
+		decoded, err := SpecFromJSON[TestUser](data)
+		if err != nil {
+			t.Fatalf("Failed to decode: %v", err)
+		}
+		sql, args := decoded.ToSQL()
+		expected := "((created_at BETWEEN $1 AND $2) AND (age = $3)) AND (id NOT IN ($4, $5))"
+		if sql != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, sql)
+		}
+		if args[0] != created || args[2] != 30 || args[3] != int64(1) {
+			t.Errorf("Expected arguments decoded into field types, got %#v", args)
+		}
+	})
+
+	t.Run("should round trip case-insensitive conditions", func(t *testing.T) {
+		spec := Or(EqualIgnoreCase[TestUser]("email", "A@B.com"), ContainsIgnoreCase[TestUser]("username", "jo"))
+		data, _ := json.Marshal(spec)
+		expected := `{"or":[{"field":"email","op":"ieq","value":"A@B.com"},{"field":"username","op":"ilike","value":"%jo%"}]}`
+		if string(data) != expected {
+			t.Errorf("Expected %s, got %s", expected, data)
+		}
+
+		decoded, err := SpecFromJSON[TestUser](data)
+		if err != nil {
+			t.Fatalf("Failed to decode: %v", err)
+		}
+		if sql, _ := decoded.ToSQL(); sql != "(LOWER(email) = LOWER($1)) OR (username ILIKE $2)" {
+			t.Errorf("Unexpected SQL '%s'", sql)
+		}
+	})
+
+	t.Run("should round trip unaccent conditions", func(t *testing.T) {
+		spec := And(EqualUnaccent[TestUser]("username", "José"), ContainsUnaccent[TestUser]("email", "jose"))
+		data, _ := json.Marshal(spec)
+		expected := `{"and":[{"field":"username","op":"unaccent_eq","value":"José"},{"field":"email","op":"unaccent_ilike","value":"%jose%"}]}`
+		if string(data) != expected {
+			t.Errorf("Expected %s, got %s", expected, data)
+		}
+
+		decoded, err := SpecFromJSON[TestUser](data)
+		if err != nil {
+			t.Fatalf("Failed to decode: %v", err)
+		}
+		sql, _ := decoded.ToSQL()
+		if original, _ := spec.ToSQL(); sql != original {
+			t.Errorf("Expected '%s', got '%s'", original, sql)
+		}
+		if _, err := SpecFromJSON[TestUser]([]byte(`{"field":"password","op":"unaccent_eq","value":"x"}`)); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+
+	t.Run("should reject raw SQL and unknown fields from untrusted input", func(t *testing.T) {
+		if _, err := SpecFromJSON[TestUser]([]byte(`{"sql":"1 = 1; DROP TABLE test_user"}`)); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := SpecFromJSON[TestUser]([]byte(`{"field":"age; DROP TABLE test_user","op":"eq","value":1}`)); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+		if _, err := SpecFromJSON[TestUser]([]byte(`{"field":"age","op":"regex","value":1}`)); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for an unknown operator, got %v", err)
+		}
+		if _, err := SpecFromJSON[TestUser]([]byte(`{"field":"age","op":"eq","value":"old"}`)); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for a mistyped value, got %v", err)
+		}
+	})
+
+	t.Run("should replay raw SQL from trusted input", func(t *testing.T) {
+		data, _ := json.Marshal(And(Where[TestUser]("age % $1 = 0", 2), JSONBHasKey[TestUser]("tags", "go")))
+		decoded, err := SpecFromTrustedJSON[TestUser](data)
+		if err != nil {
+			t.Fatalf("Failed to decode: %v", err)
+		}
+		sql, args := decoded.ToSQL()
+		if sql != "(age % $1 = 0) AND (jsonb_exists(tags, $2))" || args[0] != int64(2) || args[1] != "go" {
+			t.Errorf("Unexpected SQL: %s %v", sql, args)
+		}
+	})
+}
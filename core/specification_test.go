@@ -1,6 +1,9 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -151,6 +154,37 @@ func TestSpecification_HelperFunctions(t *testing.T) {
 		}
 	})
 
+	t.Run("IgnoreCase", func(t *testing.T) {
+		tests := []struct {
+			spec  Specification[TestUser]
+			where string
+			arg   string
+		}{
+			{EqualIgnoreCase[TestUser]("email", "John@Example.com"), "LOWER(email) = LOWER($1)", "John@Example.com"},
+			{ContainsIgnoreCase[TestUser]("username", "john"), "username ILIKE $1", "%john%"},
+			{StartsWithIgnoreCase[TestUser]("username", "jo"), "username ILIKE $1", "jo%"},
+			{EndsWithIgnoreCase[TestUser]("email", ".COM"), "email ILIKE $1", "%.COM"},
+			{EqualUnaccent[TestUser]("username", "José"), "LOWER(unaccent(username)) = LOWER(unaccent($1))", "José"},
+			{ContainsUnaccent[TestUser]("username", "jose"), "unaccent(username) ILIKE unaccent($1)", "%jose%"},
+		}
+		for _, tt := range tests {
+			where, args := tt.spec.ToSQL()
+			if where != tt.where || len(args) != 1 || args[0] != tt.arg {
+				t.Errorf("Expected '%s' with %q, got '%s' with %v", tt.where, tt.arg, where, args)
+			}
+		}
+	})
+
+	t.Run("UnaccentFunction", func(t *testing.T) {
+		defer func(name string) { UnaccentFunction = name }(UnaccentFunction)
+		UnaccentFunction = "immutable_unaccent"
+
+		where, _ := EqualUnaccent[TestUser]("username", "José").ToSQL()
+		if where != "LOWER(immutable_unaccent(username)) = LOWER(immutable_unaccent($1))" {
+			t.Errorf("Expected the configured function, got '%s'", where)
+		}
+	})
+
 	t.Run("IsNull", func(t *testing.T) {
 		spec := IsNull[TestUser]("deleted_at")
 		where, args := spec.ToSQL()
@@ -164,6 +198,199 @@ func TestSpecification_HelperFunctions(t *testing.T) {
 	})
 }
 
+func TestSpecification_Tuples(t *testing.T) {
+	t.Run("InTuples", func(t *testing.T) {
+		spec, err := InTuples[TestUser]("(org_id, email)", [][]interface{}{
+			{1, "a@example.com"},
+			{2, "b@example.com"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to build InTuples: %v", err)
+		}
+		where, args := spec.ToSQL()
+
+		expected := "(org_id, email) IN (($1, $2), ($3, $4))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 4 {
+			t.Errorf("Expected 4 args, got %d", len(args))
+		}
+		if args[2] != 2 || args[3] != "b@example.com" {
+			t.Errorf("Expected args in tuple order, got %v", args)
+		}
+	})
+
+	t.Run("InTuples without parentheses", func(t *testing.T) {
+		spec, _ := InTuples[TestUser]("org_id, email", [][]interface{}{{1, "a@example.com"}})
+		where, _ := spec.ToSQL()
+
+		expected := "(org_id, email) IN (($1, $2))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+	})
+
+	t.Run("InTuples empty", func(t *testing.T) {
+		spec, err := InTuples[TestUser]("(org_id, email)", nil)
+		if err != nil {
+			t.Fatalf("Failed to build InTuples: %v", err)
+		}
+		where, args := spec.ToSQL()
+
+		if where != "1 = 0" {
+			t.Errorf("Expected '1 = 0', got '%s'", where)
+		}
+		if len(args) != 0 {
+			t.Errorf("Expected 0 args, got %d", len(args))
+		}
+	})
+
+	t.Run("InTuples with the wrong number of values", func(t *testing.T) {
+		tuples := [][]interface{}{{1, "a@example.com"}, {2}}
+		if _, err := InTuples[TestUser]("(org_id, email)", tuples); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := InTuples[TestUser]("(org_id, LOWER(email))", [][]interface{}{{1, "a", "b"}}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for a function column, got %v", err)
+		}
+		if _, err := InTuples[TestUser]("COALESCE(org_id, 0), email", [][]interface{}{{1, "a"}}); err != nil {
+			t.Errorf("Expected commas inside calls to be skipped, got %v", err)
+		}
+	})
+
+	t.Run("TupleGreaterThan", func(t *testing.T) {
+		spec, err := TupleGreaterThan[TestUser]("(created_at, id)", "2024-01-01", 42)
+		if err != nil {
+			t.Fatalf("Failed to build TupleGreaterThan: %v", err)
+		}
+		where, args := spec.ToSQL()
+
+		expected := "(created_at, id) > ($1, $2)"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 2 {
+			t.Errorf("Expected 2 args, got %d", len(args))
+		}
+	})
+
+	t.Run("TupleLessThan combined", func(t *testing.T) {
+		before, _ := TupleLessThan[TestUser]("(created_at, id)", "2024-01-01", 42)
+		spec := Equal[TestUser]("status", "active").And(before)
+		where, args := spec.ToSQL()
+
+		expected := "(status = $1) AND ((created_at, id) < ($2, $3))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 3 {
+			t.Errorf("Expected 3 args, got %d", len(args))
+		}
+	})
+
+	t.Run("Tuple comparisons with the wrong number of values", func(t *testing.T) {
+		if _, err := TupleGreaterThan[TestUser]("(created_at, id)", "2024-01-01"); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := TupleLessThanEqual[TestUser]("created_at, id", "2024-01-01", 42, 7); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+// stubSubquery is a minimal SubqueryBuilder for tests
+type stubSubquery struct {
+	sql  string
+	args []interface{}
+}
+
+func (s stubSubquery) Build() (string, []interface{}) {
+	return s.sql, s.args
+}
+
+func TestSpecification_Exists(t *testing.T) {
+	t.Run("ExistsSubquery", func(t *testing.T) {
+		spec := ExistsSubquery[TestUser]("SELECT 1 FROM orders o WHERE o.user_id = test_user.id AND o.status = $1", "paid")
+		where, args := spec.ToSQL()
+
+		expected := "EXISTS (SELECT 1 FROM orders o WHERE o.user_id = test_user.id AND o.status = $1)"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 1 || args[0] != "paid" {
+			t.Errorf("Expected ['paid'], got %v", args)
+		}
+	})
+
+	t.Run("ExistsQuery renumbers when combined", func(t *testing.T) {
+		sub := stubSubquery{
+			sql:  "SELECT 1 FROM orders WHERE orders.user_id = test_user.id AND status = $1 AND total > $2",
+			args: []interface{}{"paid", 100},
+		}
+		spec := And(Equal[TestUser]("age", 30), ExistsQuery[TestUser](sub))
+		where, args := spec.ToSQL()
+
+		expected := "(age = $1) AND (EXISTS (SELECT 1 FROM orders WHERE orders.user_id = test_user.id AND status = $2 AND total > $3))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 3 || args[1] != "paid" || args[2] != 100 {
+			t.Errorf("Expected [30 paid 100], got %v", args)
+		}
+	})
+
+	t.Run("NotExistsQuery", func(t *testing.T) {
+		where, _ := NotExistsQuery[TestUser](stubSubquery{sql: "SELECT 1 FROM bans"}).ToSQL()
+
+		if where != "NOT EXISTS (SELECT 1 FROM bans)" {
+			t.Errorf("Expected 'NOT EXISTS (SELECT 1 FROM bans)', got '%s'", where)
+		}
+	})
+
+	t.Run("ExistsIn correlates with the outer entity", func(t *testing.T) {
+		spec := And(
+			Equal[TestUser]("age", 30),
+			ExistsIn[TestUser]("author_id", Equal[TestArticle]("title", "Go")),
+		)
+		where, args := spec.ToSQL()
+
+		expected := "(age = $1) AND (EXISTS (SELECT 1 FROM test_article WHERE test_article.author_id = test_user.id AND (title = $2) AND test_article.deleted_at IS NULL))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 2 || args[1] != "Go" {
+			t.Errorf("Expected [30 Go], got %v", args)
+		}
+	})
+
+	t.Run("NotExistsIn aliases self references", func(t *testing.T) {
+		where, _ := NotExistsIn[TestUser, TestUser]("referrer_id", nil).ToSQL()
+
+		expected := "NOT EXISTS (SELECT 1 FROM test_user AS test_user_sub WHERE test_user_sub.referrer_id = test_user.id)"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+	})
+
+	t.Run("InSubquery", func(t *testing.T) {
+		where, args := InSubquery[TestArticle]("author_id", "id", GreaterThan[TestUser]("age", 18)).ToSQL()
+
+		expected := "author_id IN (SELECT id FROM test_user WHERE (age > $1))"
+		if where != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, where)
+		}
+		if len(args) != 1 || args[0] != 18 {
+			t.Errorf("Expected [18], got %v", args)
+		}
+
+		where, _ = NotInSubquery[TestUser, TestArticle]("id", "author_id", nil).ToSQL()
+		if where != "id NOT IN (SELECT author_id FROM test_article WHERE test_article.deleted_at IS NULL)" {
+			t.Errorf("Unexpected SQL '%s'", where)
+		}
+	})
+}
+
 func TestSpecification_AndOr(t *testing.T) {
 	t.Run("And with multiple specs", func(t *testing.T) {
 		spec1 := Equal[TestUser]("status", "active")
@@ -207,3 +434,238 @@ func contains(s, substr string) bool {
 	return false
 }
 
+
+func TestExplainSpec(t *testing.T) {
+	t.Run("inlines quoted arguments", func(t *testing.T) {
+		spec := And(
+			Equal[TestUser]("username", "o'brien"),
+			Or(GreaterThan[TestUser]("age", 18), IsNull[TestUser]("email")),
+		)
+		explained := ExplainSpec(spec)
+
+		expected := "(username = 'o''brien') AND ((age > 18) OR (email IS NULL))"
+		if explained.Inlined != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, explained.Inlined)
+		}
+		if len(explained.Args) != 2 {
+			t.Errorf("Expected 2 args, got %d", len(explained.Args))
+		}
+	})
+
+	t.Run("dumps tree", func(t *testing.T) {
+		spec := Equal[TestUser]("age", 30).Or(Not(Equal[TestUser]("username", "bob")))
+		tree := ExplainSpec(spec).Tree
+
+		expected := "OR\n  age = $1 [30]\n  NOT\n    username = $1 [bob]\n"
+		if tree != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, tree)
+		}
+	})
+
+	t.Run("nil spec", func(t *testing.T) {
+		if explained := ExplainSpec[TestUser](nil); explained.SQL != "" {
+			t.Errorf("Expected empty explanation, got '%s'", explained.SQL)
+		}
+	})
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "NULL"},
+		{true, "TRUE"},
+		{42, "42"},
+		{3.5, "3.5"},
+		{"it's", "'it''s'"},
+		{[]byte{0xde, 0xad}, "'\\xdead'"},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteLiteral(tt.value); got != tt.expected {
+			t.Errorf("QuoteLiteral(%v): expected '%s', got '%s'", tt.value, tt.expected, got)
+		}
+	}
+}
+
+// rawSpec is a Specification implementation outside baseSpecification
+type rawSpec struct {
+	sql  string
+	args []interface{}
+}
+
+func (s rawSpec) ToSQL() (string, []interface{}) { return s.sql, s.args }
+
+func (s rawSpec) And(other Specification[TestUser]) Specification[TestUser] {
+	return &baseSpecification[TestUser]{operator: "AND", left: s, right: other}
+}
+
+func (s rawSpec) Or(other Specification[TestUser]) Specification[TestUser] {
+	return &baseSpecification[TestUser]{operator: "OR", left: s, right: other}
+}
+
+func (s rawSpec) Not() Specification[TestUser] {
+	return &baseSpecification[TestUser]{operator: "NOT", left: s}
+}
+
+func TestSpecification_DeepNesting(t *testing.T) {
+	a := Equal[TestUser]("a", 1)
+	b := Equal[TestUser]("b", 2)
+	c := Between[TestUser]("c", 3, 4)
+	d := In[TestUser]("d", 5, 6, 7)
+	e := Equal[TestUser]("e", 8)
+
+	tests := []struct {
+		name     string
+		spec     Specification[TestUser]
+		expected string
+	}{
+		{
+			"right-deep methods",
+			a.And(b.Or(c.And(d))),
+			"(a = $1) AND ((b = $2) OR ((c BETWEEN $3 AND $4) AND (d IN ($5, $6, $7))))",
+		},
+		{
+			"left-deep methods",
+			a.Or(b).And(c).Or(d),
+			"(((a = $1) OR (b = $2)) AND (c BETWEEN $3 AND $4)) OR (d IN ($5, $6, $7))",
+		},
+		{
+			"nested free functions",
+			And(Or(a, b), And(c, Or(d, Not(e)))),
+			"((a = $1) OR (b = $2)) AND ((c BETWEEN $3 AND $4) AND ((d IN ($5, $6, $7)) OR (NOT (e = $8))))",
+		},
+		{
+			"composites combined with composites",
+			Or(And(a, c), And(d, And(b, e))),
+			"((a = $1) AND (c BETWEEN $2 AND $3)) OR ((d IN ($4, $5, $6)) AND ((b = $7) AND (e = $8)))",
+		},
+		{
+			"custom implementation inside tree",
+			And(a, Or(rawSpec{"x = $1 OR y = $2", []interface{}{"x", "y"}}, c)),
+			"(a = $1) AND ((x = $2 OR y = $3) OR (c BETWEEN $4 AND $5))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.spec.ToSQL()
+			if where != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, where)
+			}
+			if max := MaxPlaceholder(where); max != len(args) {
+				t.Errorf("Expected %d args for highest placeholder, got %d", max, len(args))
+			}
+		})
+	}
+
+	t.Run("argument order follows placeholders", func(t *testing.T) {
+		_, args := And(Or(a, b), And(c, Or(d, Not(e)))).ToSQL()
+		expected := []interface{}{1, 2, 3, 4, 5, 6, 7, 8}
+		for i := range expected {
+			if args[i] != expected[i] {
+				t.Fatalf("Expected args %v, got %v", expected, args)
+			}
+		}
+	})
+
+	t.Run("nil specs are skipped", func(t *testing.T) {
+		where, args := And(nil, a, nil, Or[TestUser](nil, b)).ToSQL()
+		if where != "(a = $1) AND (b = $2)" {
+			t.Errorf("Expected '(a = $1) AND (b = $2)', got '%s'", where)
+		}
+		if len(args) != 2 {
+			t.Errorf("Expected 2 args, got %d", len(args))
+		}
+		if And[TestUser](nil, nil) != nil {
+			t.Error("Expected nil for all-nil specs")
+		}
+	})
+
+	t.Run("empty branches are dropped", func(t *testing.T) {
+		where, args := And(Where[TestUser](""), a).ToSQL()
+		if where != "(a = $1)" {
+			t.Errorf("Expected '(a = $1)', got '%s'", where)
+		}
+		if len(args) != 1 {
+			t.Errorf("Expected 1 arg, got %d", len(args))
+		}
+	})
+}
+
+func TestSpecification_WideIn(t *testing.T) {
+	const n = 12000
+
+	t.Run("uniform values bind one array", func(t *testing.T) {
+		ids := make([]interface{}, n)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		where, args := And(Equal[TestUser]("age", 30), In[TestUser]("id", ids...)).ToSQL()
+		if where != "(age = $1) AND (id = ANY($2::bigint[]))" {
+			t.Errorf("Expected an array parameter, got '%s'", where)
+		}
+		if len(args) != 2 {
+			t.Fatalf("Expected 2 args, got %d", len(args))
+		}
+		if array, ok := args[1].([]int64); !ok || len(array) != n || array[n-1] != n {
+			t.Errorf("Expected %d int64 values, got %T", n, args[1])
+		}
+
+		names := make([]interface{}, n)
+		for i := range names {
+			names[i] = fmt.Sprintf("user%d", i)
+		}
+		where, args = NotIn[TestUser]("username", names...).ToSQL()
+		if where != "username <> ALL($1)" {
+			t.Errorf("Expected an uncast array parameter, got '%s'", where)
+		}
+		if array, ok := args[0].([]string); !ok || len(array) != n {
+			t.Errorf("Expected %d strings, got %T", n, args[0])
+		}
+	})
+
+	t.Run("mixed values are chunked", func(t *testing.T) {
+		values := make([]interface{}, n)
+		for i := range values {
+			values[i] = i
+			if i%2 == 1 {
+				values[i] = fmt.Sprint(i)
+			}
+		}
+		where, args := In[TestUser]("code", values...).ToSQL()
+		if len(args) != n {
+			t.Errorf("Expected %d args, got %d", n, len(args))
+		}
+		if got := strings.Count(where, " OR "); got != n/InListLimit-1 {
+			t.Errorf("Expected %d OR groups, got %d", n/InListLimit, got+1)
+		}
+		if !strings.HasPrefix(where, "(code IN ($1, $2,") || !strings.HasSuffix(where, fmt.Sprintf("$%d))", n)) {
+			t.Errorf("Expected chunked IN lists numbered $1 to $%d, got '%.40s...%s'", n, where, where[len(where)-20:])
+		}
+
+		where, _ = NotIn[TestUser]("code", values...).ToSQL()
+		if strings.Count(where, " AND ") != n/InListLimit-1 || strings.Contains(where, " OR ") {
+			t.Errorf("Expected NOT IN groups joined by AND, got '%.60s...'", where)
+		}
+	})
+
+	t.Run("the syntax tree keeps the values", func(t *testing.T) {
+		ids := make([]interface{}, n)
+		for i := range ids {
+			ids[i] = int64(i)
+		}
+		node := SpecAST(In[TestUser]("id", ids...))
+		if node.Op != "in" || len(node.Values) != n {
+			t.Errorf("Expected an in condition with %d values, got %s with %d", n, node.Op, len(node.Values))
+		}
+	})
+
+	t.Run("short lists are unchanged", func(t *testing.T) {
+		where, _ := In[TestUser]("id", 1, 2).ToSQL()
+		if where != "id IN ($1, $2)" {
+			t.Errorf("Expected 'id IN ($1, $2)', got '%s'", where)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"github.com/jackc/pgx/v5"
+)
+
+// StatementCacheDisabled turns off prepared statement caching when used as
+// Config.StatementCacheSize, for servers behind a transaction-pooling proxy
+// such as PgBouncer, where a prepared statement may live on another backend
+const StatementCacheDisabled = -1
+
+// configureStatementCache applies StatementCacheSize to a connection config.
+// With a positive size each pooled connection prepares a statement the first
+// time it sees its SQL text and reuses it afterwards, evicting the least
+// recently used of size statements. Zero keeps the pgx default.
+func (c Config) configureStatementCache(conn *pgx.ConnConfig) {
+	switch {
+	case c.StatementCacheSize > 0:
+		conn.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		conn.StatementCacheCapacity = c.StatementCacheSize
+	case c.StatementCacheSize < 0:
+		// Describe and execute each statement with the unnamed statement
+		conn.DefaultQueryExecMode = pgx.QueryExecModeExec
+		conn.StatementCacheCapacity = 0
+		conn.DescriptionCacheCapacity = 0
+	}
+}
+
+// WithStatementCache sets how many prepared statements each connection keeps,
+// or disables the cache with StatementCacheDisabled
+func WithStatementCache(size int) ConfigOption {
+	return func(c *Config) {
+		c.StatementCacheSize = size
+	}
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestConfig_StatementCache(t *testing.T) {
+	connConfig := func(size int) *pgx.ConnConfig {
+		conn, err := pgx.ParseConfig("host=localhost")
+		if err != nil {
+			t.Fatalf("Failed to parse config: %v", err)
+		}
+		Config{StatementCacheSize: size}.configureStatementCache(conn)
+		return conn
+	}
+
+	if conn := connConfig(0); conn.DefaultQueryExecMode != pgx.QueryExecModeCacheStatement || conn.StatementCacheCapacity != 512 {
+		t.Errorf("Expected the pgx default, got %v with %d", conn.DefaultQueryExecMode, conn.StatementCacheCapacity)
+	}
+	if conn := connConfig(64); conn.StatementCacheCapacity != 64 {
+		t.Errorf("Expected a cache of 64 statements, got %d", conn.StatementCacheCapacity)
+	}
+
+	conn := connConfig(StatementCacheDisabled)
+	if conn.DefaultQueryExecMode != pgx.QueryExecModeExec || conn.StatementCacheCapacity != 0 || conn.DescriptionCacheCapacity != 0 {
+		t.Errorf("Expected caching disabled, got %v with %d/%d", conn.DefaultQueryExecMode, conn.StatementCacheCapacity, conn.DescriptionCacheCapacity)
+	}
+}
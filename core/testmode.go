@@ -0,0 +1,115 @@
+package core
+
+import (
+	"encoding/binary"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestModeTime is the time test mode's clock is frozen at
+var TestModeTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// IDGenerator supplies the keys of inserted rows. Config.IDGenerator sets the
+// generator repositories use instead of random UUIDs, gen_random_uuid() and
+// serial sequences; tests make keys deterministic with NewSequentialIDs.
+type IDGenerator interface {
+	// NextID returns the next auto_increment key of the table
+	NextID(table string) int64
+
+	// NewUUID returns the next uuid key of the table
+	NewUUID(table string) uuid.UUID
+}
+
+// SequentialIDs is an IDGenerator numbering the keys of each table 1, 2,
+// 3... UUIDs carry the number in their last bytes, e.g.
+// 00000000-0000-4000-8000-000000000001.
+type SequentialIDs struct {
+	mu   sync.Mutex
+	next map[string]int64
+}
+
+// NewSequentialIDs creates a generator starting every table at 1
+func NewSequentialIDs() *SequentialIDs {
+	return &SequentialIDs{next: make(map[string]int64)}
+}
+
+// NextID returns the table's next number
+func (g *SequentialIDs) NextID(table string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next[table]++
+	return g.next[table]
+}
+
+// NewUUID returns the table's next number as a version 4 UUID
+func (g *SequentialIDs) NewUUID(table string) uuid.UUID {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], uint64(g.NextID(table)))
+	id[6] = 0x40  // Version 4
+	id[8] |= 0x80 // RFC 4122 variant
+	return id
+}
+
+// Reset starts every table at 1 again
+func (g *SequentialIDs) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next = make(map[string]int64)
+}
+
+// WithIDGenerator makes repositories take the keys of inserted rows from
+// ids instead of random UUIDs and the database's defaults and sequences
+func WithIDGenerator(ids IDGenerator) ConfigOption {
+	return func(c *Config) {
+		c.IDGenerator = ids
+	}
+}
+
+// WithTestMode makes repositories deterministic for golden tests: auto
+// timestamps come from a clock frozen at TestModeTime and keys from
+// SequentialIDs, so statements and returned entities are identical across
+// runs. Sequences are bypassed, so the tables should start empty.
+func WithTestMode() ConfigOption {
+	return func(c *Config) {
+		c.Clock = NewFrozenClock(TestModeTime)
+		c.IDGenerator = NewSequentialIDs()
+	}
+}
+
+// WithTestMode returns a repository with its own test mode, as
+// WithTestMode configures for a Database: a clock frozen at TestModeTime and
+// SequentialIDs starting at 1. Each call starts a new sequence.
+func (r *BaseRepository[T, ID]) WithTestMode() *BaseRepository[T, ID] {
+	repo := *r
+	repo.testClock = NewFrozenClock(TestModeTime)
+	repo.testIDs = NewSequentialIDs()
+	return &repo
+}
+
+// idGenerator returns the repository's configured ID generator, or nil when
+// keys are left to random UUIDs and the database
+func (r *BaseRepository[T, ID]) idGenerator() IDGenerator {
+	if r.testIDs != nil {
+		return r.testIDs
+	}
+	if r.db == nil {
+		return nil
+	}
+	return r.db.config.IDGenerator
+}
+
+// generatedKey returns the key the ID generator assigns an unset key field,
+// converted to the field's type
+func (r *BaseRepository[T, ID]) generatedKey(ids IDGenerator, f *Field) interface{} {
+	if f.UUID != "" {
+		id := ids.NewUUID(r.tableName)
+		if f.Type.Kind() == reflect.String {
+			return reflect.ValueOf(id.String()).Convert(f.Type).Interface()
+		}
+		return reflect.ValueOf(id).Convert(f.Type).Interface()
+	}
+	return reflect.ValueOf(ids.NextID(r.tableName)).Convert(f.Type).Interface()
+}
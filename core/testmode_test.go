@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSequentialIDs(t *testing.T) {
+	ids := NewSequentialIDs()
+	if a, b, c := ids.NextID("users"), ids.NextID("users"), ids.NextID("orders"); a != 1 || b != 2 || c != 1 {
+		t.Errorf("Expected 1, 2 and 1, got %d, %d and %d", a, b, c)
+	}
+
+	id := ids.NewUUID("documents")
+	if id.String() != "00000000-0000-4000-8000-000000000001" || id.Version() != 4 || id.Variant() != uuid.RFC4122 {
+		t.Errorf("Expected the first sequential UUID, got %s", id)
+	}
+
+	ids.Reset()
+	if n := ids.NextID("users"); n != 1 {
+		t.Errorf("Expected 1 after Reset, got %d", n)
+	}
+}
+
+func TestBaseRepository_WithTestMode(t *testing.T) {
+	t.Run("should produce identical statements across runs", func(t *testing.T) {
+		run := func() string {
+			repo, err := NewBaseRepository[TestUser, int64](nil)
+			if err != nil {
+				t.Fatalf("Failed to create repository: %v", err)
+			}
+			repo = repo.WithTestMode()
+			ctx, capture := (&Database{}).DryRun(context.Background())
+			repo.Save(ctx, &TestUser{Email: "a@example.com", Username: "a"})
+			repo.Save(ctx, &TestUser{Email: "b@example.com", Username: "b"})
+			return capture.String()
+		}
+
+		expected := "INSERT INTO test_user (id, email, username, age, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING * " +
+			"[$1=1 $2=a@example.com $3=a $4=0 $5=2000-01-01 00:00:00 +0000 UTC $6=2000-01-01 00:00:00 +0000 UTC]\n" +
+			"INSERT INTO test_user (id, email, username, age, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING * " +
+			"[$1=2 $2=b@example.com $3=b $4=0 $5=2000-01-01 00:00:00 +0000 UTC $6=2000-01-01 00:00:00 +0000 UTC]\n"
+		for i := 0; i < 2; i++ {
+			if got := run(); got != expected {
+				t.Errorf("Run %d: expected\n%s\ngot\n%s", i, expected, got)
+			}
+		}
+	})
+
+	t.Run("should keep assigned keys", func(t *testing.T) {
+		repo, _ := NewBaseRepository[TestUser, int64](nil)
+		_, args := repo.WithTestMode().WithSaveMode(SaveAlwaysInsert).insertStatement(&TestUser{ID: 42})
+		if args[0] != int64(42) {
+			t.Errorf("Expected 42, got %v", args[0])
+		}
+	})
+
+	t.Run("should generate UUID keys", func(t *testing.T) {
+		docs, _ := NewBaseRepository[TestDocument, string](nil)
+		_, args := docs.WithTestMode().insertStatement(&TestDocument{Title: "hello"})
+		if args[0] != "00000000-0000-4000-8000-000000000001" {
+			t.Errorf("Expected the first sequential UUID, got %v", args[0])
+		}
+
+		tokens, _ := NewBaseRepository[TestToken, uuid.UUID](nil)
+		query, args := tokens.WithTestMode().insertStatement(&TestToken{Value: "secret"})
+		if query != "INSERT INTO test_token (id, value) VALUES ($1, $2) RETURNING *" {
+			t.Errorf("Expected the key to be bound, got '%s'", query)
+		}
+		if id, ok := args[0].(uuid.UUID); !ok || id.String() != "00000000-0000-4000-8000-000000000001" {
+			t.Errorf("Expected the first sequential UUID, got %v", args[0])
+		}
+	})
+
+	t.Run("should be configurable on the database", func(t *testing.T) {
+		var config Config
+		WithTestMode()(&config)
+		db := &Database{config: config}
+		repo, _ := NewBaseRepository[TestUser, int64](db)
+		_, args := repo.insertStatement(&TestUser{})
+		if args[0] != int64(1) || args[4] != TestModeTime {
+			t.Errorf("Expected deterministic key and timestamps, got %v", args)
+		}
+	})
+}
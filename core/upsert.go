@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxQueryParams is the PostgreSQL limit on bind parameters per statement
+const maxQueryParams = 65535
+
+// ConflictClause describes an ON CONFLICT strategy for upserts
+type ConflictClause struct {
+	columns    []string
+	doNothing  bool
+	setColumns []string
+}
+
+// OnConflict starts a conflict clause targeting the given unique columns
+func OnConflict(columns ...string) *ConflictClause {
+	return &ConflictClause{columns: columns}
+}
+
+// DoUpdate overwrites the given columns with the proposed row on conflict.
+// With no columns, every inserted column except the primary key, the conflict
+// target and auto timestamps is updated.
+func (c *ConflictClause) DoUpdate(setColumns ...string) *ConflictClause {
+	c.doNothing = false
+	c.setColumns = setColumns
+	return c
+}
+
+// DoNothing skips conflicting rows; they are not included in the returned entities
+func (c *ConflictClause) DoNothing() *ConflictClause {
+	c.doNothing = true
+	c.setColumns = nil
+	return c
+}
+
+// build renders the ON CONFLICT clause for the inserted columns.
+// autoNow columns are refreshed with now when rows are updated.
+func (c *ConflictClause) build(insertColumns, autoNow []string, now string) string {
+	target := ""
+	if len(c.columns) > 0 {
+		target = " (" + strings.Join(c.columns, ", ") + ")"
+	}
+
+	if c.doNothing {
+		return "ON CONFLICT" + target + " DO NOTHING"
+	}
+
+	setColumns := c.setColumns
+	if len(setColumns) == 0 {
+		conflict := make(map[string]bool, len(c.columns))
+		for _, col := range c.columns {
+			conflict[col] = true
+		}
+		for _, col := range insertColumns {
+			if !conflict[col] {
+				setColumns = append(setColumns, col)
+			}
+		}
+	}
+
+	sets := make([]string, 0, len(setColumns)+len(autoNow))
+	for _, col := range setColumns {
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+	}
+	for _, col := range autoNow {
+		sets = append(sets, fmt.Sprintf("%s = %s", col, now))
+	}
+
+	if len(sets) == 0 {
+		return "ON CONFLICT" + target + " DO NOTHING"
+	}
+	return "ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// UpsertAll inserts entities with multi-row INSERT ... ON CONFLICT statements,
+// one per chunk sized to stay under the bind parameter limit. It returns the
+// inserted or updated rows; rows skipped by DoNothing are not returned.
+func (r *BaseRepository[T, ID]) UpsertAll(ctx context.Context, entities []*T, conflict *ConflictClause) ([]*T, error) {
+	if len(entities) == 0 {
+		return []*T{}, nil
+	}
+	if conflict == nil {
+		return nil, fmt.Errorf("%w: conflict clause is required", ErrInvalidInput)
+	}
+	if err := r.validateColumns(conflict.columns); err != nil {
+		return nil, err
+	}
+	if err := r.validateColumns(conflict.setColumns); err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if err := r.validateEnums(entity); err != nil {
+			return nil, err
+		}
+	}
+
+	columns, _, _ := r.insertRows(entities)
+	chunkSize := len(entities)
+	if len(columns) > 0 && len(columns)*chunkSize > maxQueryParams {
+		chunkSize = maxQueryParams / len(columns)
+	}
+
+	results := make([]*T, 0, len(entities))
+	for start := 0; start < len(entities); start += chunkSize {
+		end := start + chunkSize
+		if end > len(entities) {
+			end = len(entities)
+		}
+
+		query, args := r.upsertStatement(entities[start:end], conflict)
+		r.logQuery(query, args)
+
+		var rows pgx.Rows
+		var err error
+		if r.tx != nil {
+			rows, err = r.txConn(ctx).Query(ctx, query, args...)
+		} else {
+			rows, err = r.poolConn(ctx).Query(ctx, query, args...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed at offset %d: %w", start, err)
+		}
+
+		saved, err := r.scanRows(rows)
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed at offset %d: %w", start, err)
+		}
+		results = append(results, saved...)
+	}
+
+	return results, nil
+}
+
+// upsertStatement builds a multi-row INSERT ... ON CONFLICT ... RETURNING statement
+func (r *BaseRepository[T, ID]) upsertStatement(entities []*T, conflict *ConflictClause) (string, []interface{}) {
+	// Every row lists the same columns; a row leaving one to its default
+	// writes DEFAULT in its place
+	columns, values, defaults := r.insertRows(entities)
+	var args []interface{}
+	rows := make([]string, len(entities))
+	for i := range entities {
+		tuple := make([]string, len(columns))
+		for j := range columns {
+			if defaults[i][j] {
+				tuple[j] = "DEFAULT"
+				continue
+			}
+			args = append(args, values[i][j])
+			tuple[j] = fmt.Sprintf("$%d", len(args))
+		}
+		rows[i] = "(" + strings.Join(tuple, ", ") + ")"
+	}
+
+	// Auto timestamps are inserted when a clock is configured; conflicting
+	// rows keep their key and creation time and refresh auto_now columns
+	var autoNow []string
+	fixed := map[string]bool{r.pkField: true}
+	for _, fieldMeta := range r.entity.Fields {
+		if fieldMeta.AutoNow {
+			autoNow = append(autoNow, fieldMeta.DBName)
+		}
+		if fieldMeta.AutoNow || fieldMeta.AutoNowAdd {
+			fixed[fieldMeta.DBName] = true
+		}
+	}
+	updatable := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if !fixed[col] {
+			updatable = append(updatable, col)
+		}
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s %s RETURNING %s",
+		r.tableName,
+		strings.Join(columns, ", "),
+		strings.Join(rows, ", "),
+		conflict.build(updatable, autoNow, r.nowSQL()),
+		r.selectList(),
+	)
+
+	return query, args
+}
+
+// validateColumns checks that every name is a column of the entity
+func (r *BaseRepository[T, ID]) validateColumns(columns []string) error {
+	for _, col := range columns {
+		if fieldMeta := r.lookupField(col); fieldMeta == nil || fieldMeta.DBName != col {
+			return fmt.Errorf("%w: %s", ErrUnknownField, col)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBaseRepository_UpsertStatement(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	users := []*TestUser{
+		{Email: "a@example.com", Username: "a", Age: 30},
+		{Email: "b@example.com", Username: "b", Age: 40},
+	}
+
+	t.Run("should update all non-conflict columns by default", func(t *testing.T) {
+		query, args := repo.upsertStatement(users, OnConflict("email").DoUpdate())
+
+		expected := "INSERT INTO test_user (email, username, age) VALUES ($1, $2, $3), ($4, $5, $6) " +
+			"ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, age = EXCLUDED.age, updated_at = NOW() RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 6 || args[3] != "b@example.com" {
+			t.Errorf("Expected args in row order, got %v", args)
+		}
+	})
+
+	t.Run("should update selected columns", func(t *testing.T) {
+		query, _ := repo.upsertStatement(users[:1], OnConflict("email").DoUpdate("age"))
+
+		if !contains(query, "ON CONFLICT (email) DO UPDATE SET age = EXCLUDED.age, updated_at = NOW()") {
+			t.Errorf("Unexpected conflict clause: %s", query)
+		}
+	})
+
+	t.Run("should skip conflicts", func(t *testing.T) {
+		query, _ := repo.upsertStatement(users[:1], OnConflict("email", "username").DoNothing())
+
+		if !contains(query, "ON CONFLICT (email, username) DO NOTHING RETURNING *") {
+			t.Errorf("Unexpected conflict clause: %s", query)
+		}
+	})
+
+	t.Run("should align rows leaving columns to their defaults", func(t *testing.T) {
+		coupons, err := NewBaseRepository[TestCoupon, uuid.UUID](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		id := uuid.MustParse("00000000-0000-4000-8000-000000000007")
+		uses := int64(3)
+		query, args := coupons.upsertStatement([]*TestCoupon{
+			{Code: "A"},
+			{ID: id, Code: "B", UsesLeft: &uses},
+			{Code: "C"},
+		}, OnConflict("code").DoUpdate("uses_left"))
+
+		expected := "INSERT INTO test_coupon (id, code, uses_left) VALUES (DEFAULT, $1, DEFAULT), ($2, $3, $4), (DEFAULT, $5, DEFAULT) " +
+			"ON CONFLICT (code) DO UPDATE SET uses_left = EXCLUDED.uses_left RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+		if len(args) != 5 || args[0] != "A" || args[1] != id || args[4] != "C" {
+			t.Errorf("Expected args in row order, got %v", args)
+		}
+	})
+
+	t.Run("should keep generated keys of conflicting rows", func(t *testing.T) {
+		docs, err := NewBaseRepository[TestDocument, string](nil)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		query, _ := docs.WithTestMode().upsertStatement([]*TestDocument{{Title: "a"}}, OnConflict("title").DoUpdate())
+
+		expected := "INSERT INTO test_document (id, title) VALUES ($1, $2) ON CONFLICT (title) DO NOTHING RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+
+		query, _ = repo.WithTestMode().upsertStatement(users[:1], OnConflict("email").DoUpdate())
+		expected = "INSERT INTO test_user (id, email, username, age, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) " +
+			"ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, age = EXCLUDED.age, " +
+			"updated_at = '2000-01-01T00:00:00Z'::timestamptz RETURNING *"
+		if query != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, query)
+		}
+	})
+
+	t.Run("should reject unknown columns", func(t *testing.T) {
+		_, err := repo.UpsertAll(context.Background(), users, OnConflict("nope").DoNothing())
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("Expected ErrUnknownField, got %v", err)
+		}
+	})
+}
@@ -108,12 +108,14 @@ func ExtractID[T any, ID comparable](entity *T) (ID, error) {
 		entityValue = entityValue.Elem()
 	}
 
-	// Find primary key field
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
+	// Find primary key field, including fields promoted from embedded structs
+	for _, field := range reflect.VisibleFields(entityType) {
 		jetTag := field.Tag.Get("jet")
 		if strings.Contains(jetTag, "primary_key") {
-			fieldValue := entityValue.Field(i)
+			fieldValue, err := entityValue.FieldByIndexErr(field.Index)
+			if err != nil {
+				continue
+			}
 			if fieldValue.CanInterface() {
 				if id, ok := fieldValue.Interface().(ID); ok {
 					return id, nil
@@ -137,12 +139,14 @@ func SetID[T any, ID comparable](entity *T, id ID) error {
 		entityValue = entityValue.Elem()
 	}
 
-	// Find primary key field
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
+	// Find primary key field, including fields promoted from embedded structs
+	for _, field := range reflect.VisibleFields(entityType) {
 		jetTag := field.Tag.Get("jet")
 		if strings.Contains(jetTag, "primary_key") {
-			fieldValue := entityValue.Field(i)
+			fieldValue, err := entityValue.FieldByIndexErr(field.Index)
+			if err != nil {
+				continue
+			}
 			if fieldValue.CanSet() {
 				idValue := reflect.ValueOf(id)
 				if idValue.Type().AssignableTo(fieldValue.Type()) {
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// UUID primary key strategies, set with jet:"primary_key,uuid" (v4),
+// jet:"primary_key,uuid:v7" or jet:"primary_key,uuid:db"
+const (
+	UUIDv4       = "v4" // Random UUID generated by the client
+	UUIDv7       = "v7" // Time-ordered UUID generated by the client
+	UUIDDatabase = "db" // Generated by the column default, gen_random_uuid()
+)
+
+// validateUUIDField checks that a uuid field uses a known strategy and a
+// string or [16]byte type such as uuid.UUID
+func validateUUIDField(f *Field) error {
+	switch f.UUID {
+	case "", UUIDv4, UUIDv7, UUIDDatabase:
+	default:
+		return fmt.Errorf("%w: unknown strategy %q for %s", ErrUnsupportedUUID, f.UUID, f.Name)
+	}
+	if f.UUID == "" || isUUIDType(f.Type) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s must be a string or [16]byte, got %s", ErrUnsupportedUUID, f.Name, f.Type)
+}
+
+func isUUIDType(t reflect.Type) bool {
+	if t.Kind() == reflect.String {
+		return true
+	}
+	return t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+}
+
+// newUUID generates a client-side UUID converted to the field's type
+func (f *Field) newUUID() interface{} {
+	var id uuid.UUID
+	if f.UUID == UUIDv7 {
+		id = uuid.Must(uuid.NewV7())
+	} else {
+		id = uuid.New()
+	}
+
+	if f.Type.Kind() == reflect.String {
+		return reflect.ValueOf(id.String()).Convert(f.Type).Interface()
+	}
+	return reflect.ValueOf(id).Convert(f.Type).Interface()
+}
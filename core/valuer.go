@@ -0,0 +1,28 @@
+package core
+
+import (
+	"reflect"
+)
+
+// implementsValuer reports whether t or *t implements driver.Valuer
+func implementsValuer(t reflect.Type) bool {
+	return t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType)
+}
+
+// hasCustomCodec reports whether values of t convert themselves to and from
+// column values through driver.Valuer or sql.Scanner, such as money types,
+// encrypted strings or pgtype values. Such fields are passed to the driver
+// unchanged rather than being flattened or marshaled to JSON.
+func hasCustomCodec(t reflect.Type) bool {
+	return implementsValuer(t) || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// driverArg returns a field value as a query argument. When only the pointer
+// type implements driver.Valuer, the field's address is passed so that the
+// driver calls Value instead of encoding the bare struct.
+func driverArg(v reflect.Value) interface{} {
+	if v.CanAddr() && !v.Type().Implements(valuerType) && reflect.PointerTo(v.Type()).Implements(valuerType) {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Money is stored as "<cents> <currency>" and implements both interfaces on
+// the pointer, as types that mutate themselves usually do
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+func (m *Money) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d %s", m.Cents, m.Currency), nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unexpected money value %T", src)
+	}
+	_, err := fmt.Sscanf(s, "%d %s", &m.Cents, &m.Currency)
+	return err
+}
+
+// Secret is an encrypted column; rot13 stands in for real encryption
+type Secret string
+
+func (s Secret) Value() (driver.Value, error) {
+	return rot13(string(s)), nil
+}
+
+func (s *Secret) Scan(src interface{}) error {
+	*s = Secret(rot13(src.(string)))
+	return nil
+}
+
+func rot13(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 'a' + (r-'a'+13)%26
+		case r >= 'A' && r <= 'Z':
+			return 'A' + (r-'A'+13)%26
+		}
+		return r
+	}, s)
+}
+
+// TestInvoice has columns with custom Valuer and Scanner implementations
+type TestInvoice struct {
+	ID      int64          `db:"id" jet:"primary_key,auto_increment"`
+	Total   Money          `db:"total"`
+	Token   Secret         `db:"token"`
+	Price   Money          `db:"price" jet:"type:jsonb"`
+	Note    sql.NullString `db:"note"`
+	Balance *Money         `db:"balance"`
+}
+
+func TestBaseRepository_CustomValuers(t *testing.T) {
+	repo, err := NewBaseRepository[TestInvoice, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should not treat custom types as JSON", func(t *testing.T) {
+		for _, fieldMeta := range repo.entity.Fields {
+			if fieldMeta.JSON {
+				t.Errorf("Expected %s not to be marshaled as JSON", fieldMeta.DBName)
+			}
+		}
+	})
+
+	t.Run("should pass values through their Valuer on insert", func(t *testing.T) {
+		invoice := &TestInvoice{
+			Total: Money{Cents: 1250, Currency: "EUR"},
+			Token: "hello",
+			Price: Money{Cents: 99, Currency: "USD"},
+			Note:  sql.NullString{String: "paid", Valid: true},
+		}
+		_, args := repo.insertStatement(invoice)
+
+		expected := []driver.Value{"1250 EUR", "uryyb", "99 USD", "paid", nil}
+		for i, want := range expected {
+			if i == len(expected)-1 {
+				// A nil pointer is written as NULL without calling its Valuer
+				if args[i] != nil {
+					t.Errorf("Expected nil, got %#v", args[i])
+				}
+				continue
+			}
+			valuer, ok := args[i].(driver.Valuer)
+			if !ok {
+				t.Fatalf("Expected argument %d to implement driver.Valuer, got %T", i, args[i])
+			}
+			got, err := valuer.Value()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		}
+	})
+
+	t.Run("should pass the key through its Valuer on update", func(t *testing.T) {
+		_, args := repo.updateStatement(&TestInvoice{ID: 7, Total: Money{Cents: 1, Currency: "EUR"}})
+		if got, _ := args[0].(driver.Valuer).Value(); got != "1 EUR" {
+			t.Errorf("Expected '1 EUR', got %v", got)
+		}
+		if args[len(args)-1] != int64(7) {
+			t.Errorf("Expected key 7, got %v", args[len(args)-1])
+		}
+	})
+
+	t.Run("should scan values through their Scanner", func(t *testing.T) {
+		invoice := &TestInvoice{}
+		v := reflect.ValueOf(invoice).Elem()
+		// Pointers to Scanners are allocated by pgx; fakeRow only assigns them
+		row := fakeRow{int64(1), "500 GBP", "frperg", "7 USD", "due", &Money{Cents: 20, Currency: "EUR"}}
+		if err := row.Scan(targetsOf(repo.entity.Fields, v)...); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+
+		if invoice.Total != (Money{Cents: 500, Currency: "GBP"}) {
+			t.Errorf("Expected 500 GBP, got %+v", invoice.Total)
+		}
+		if invoice.Token != "secret" {
+			t.Errorf("Expected 'secret', got '%s'", invoice.Token)
+		}
+		if invoice.Price != (Money{Cents: 7, Currency: "USD"}) {
+			t.Errorf("Expected 7 USD, got %+v", invoice.Price)
+		}
+		if !invoice.Note.Valid || invoice.Note.String != "due" {
+			t.Errorf("Expected note 'due', got %+v", invoice.Note)
+		}
+		if invoice.Balance == nil || invoice.Balance.Cents != 20 {
+			t.Errorf("Expected balance 20 EUR, got %+v", invoice.Balance)
+		}
+	})
+}
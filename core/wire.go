@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WirePage is the JSON envelope of a Page shared between services. Its
+// layout matches docs/schemas/jetorm.proto and docs/schemas/jetorm.schema.json.
+type WirePage[T any] struct {
+	Content          []*T        `json:"content"`
+	Page             int         `json:"page"`
+	Size             int         `json:"size"`
+	TotalElements    int64       `json:"total_elements"` // -1 when not counted
+	TotalPages       int         `json:"total_pages"`    // -1 when not counted
+	NumberOfElements int         `json:"number_of_elements"`
+	First            bool        `json:"first"`
+	Last             bool        `json:"last"`
+	Empty            bool        `json:"empty"`
+	Sort             []WireOrder `json:"sort,omitempty"`
+}
+
+// WireOrder is the JSON form of an Order
+type WireOrder struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"` // asc or desc
+}
+
+// WireError is the JSON envelope of an error shared between services
+type WireError struct {
+	Code    ErrorCode              `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// WireSort returns the wire form of a sort
+func WireSort(sort Sort) []WireOrder {
+	if len(sort.Orders) == 0 {
+		return nil
+	}
+	orders := make([]WireOrder, len(sort.Orders))
+	for i, order := range sort.Orders {
+		orders[i] = WireOrder{Field: order.Field, Direction: "asc"}
+		if order.Direction == Desc {
+			orders[i].Direction = "desc"
+		}
+	}
+	return orders
+}
+
+// SortFromWire returns the sort of wire orders; directions are case
+// insensitive and default to asc
+func SortFromWire(orders []WireOrder) (Sort, error) {
+	var sort Sort
+	for _, order := range orders {
+		if order.Field == "" {
+			return Sort{}, fmt.Errorf("%w: sort order without a field", ErrInvalidInput)
+		}
+		switch strings.ToLower(order.Direction) {
+		case "", "asc":
+			sort.Orders = append(sort.Orders, Order{Field: order.Field, Direction: Asc})
+		case "desc":
+			sort.Orders = append(sort.Orders, Order{Field: order.Field, Direction: Desc})
+		default:
+			return Sort{}, fmt.Errorf("%w: sort direction %q of %s", ErrInvalidInput, order.Direction, order.Field)
+		}
+	}
+	return sort, nil
+}
+
+// ToWire returns the wire envelope of the page
+func (p *Page[T]) ToWire() WirePage[T] {
+	content := p.Content
+	if content == nil {
+		content = []*T{}
+	}
+	return WirePage[T]{
+		Content:          content,
+		Page:             p.Number,
+		Size:             p.Size,
+		TotalElements:    p.TotalElements,
+		TotalPages:       p.TotalPages,
+		NumberOfElements: p.NumberOfElements,
+		First:            p.First,
+		Last:             p.Last,
+		Empty:            p.Empty,
+		Sort:             WireSort(p.Sort),
+	}
+}
+
+// PageFromWire rebuilds a Page from its wire envelope, including the
+// Pageable that produced it
+func PageFromWire[T any](w WirePage[T]) (*Page[T], error) {
+	sort, err := SortFromWire(w.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return &Page[T]{
+		Content:          w.Content,
+		Pageable:         Pageable{Page: w.Page, Size: w.Size, Sort: sort},
+		TotalElements:    w.TotalElements,
+		TotalPages:       w.TotalPages,
+		Size:             w.Size,
+		Number:           w.Page,
+		NumberOfElements: w.NumberOfElements,
+		First:            w.First,
+		Last:             w.Last,
+		Empty:            w.Empty,
+		Sort:             sort,
+	}, nil
+}
+
+// EncodePage encodes a page as a WirePage JSON document
+func EncodePage[T any](page *Page[T]) ([]byte, error) {
+	return json.Marshal(page.ToWire())
+}
+
+// DecodePage decodes a WirePage JSON document
+func DecodePage[T any](data []byte) (*Page[T], error) {
+	var w WirePage[T]
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+	return PageFromWire(w)
+}
+
+// ErrorCodeOf classifies an error: the code of a CodedError in its chain, or
+// the code of the jetorm sentinel it wraps, or ErrorCodeInternal
+func ErrorCodeOf(err error) ErrorCode {
+	var coded *CodedError
+	switch {
+	case errors.As(err, &coded):
+		return coded.Code
+	case IsNotFound(err):
+		return ErrorCodeNotFound
+	case IsDuplicate(err):
+		return ErrorCodeDuplicate
+	case IsValidationError(err):
+		return ErrorCodeValidation
+	case errors.Is(err, ErrQueryTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeTimeout
+	case IsTransactionError(err):
+		return ErrorCodeTransaction
+	case errors.Is(err, ErrQueryFailed), errors.Is(err, ErrQueryInvalid), errors.Is(err, ErrUnknownField):
+		return ErrorCodeQuery
+	case errors.Is(err, ErrDatabaseConnection), errors.Is(err, ErrConnectionFailed),
+		errors.Is(err, ErrDatabaseQuery), errors.Is(err, ErrDatabaseExec):
+		return ErrorCodeDatabase
+	}
+	return ErrorCodeInternal
+}
+
+// ToWireError returns the wire envelope of an error. The message is the
+// CodedError's message when there is one, otherwise the error text; the
+// context of an ErrorWithContext becomes the details.
+func ToWireError(err error) WireError {
+	w := WireError{Code: ErrorCodeOf(err), Message: err.Error()}
+	var coded *CodedError
+	if errors.As(err, &coded) && coded.Message != "" {
+		w.Message = coded.Message
+	}
+	var withContext *ErrorWithContext
+	if errors.As(err, &withContext) && len(withContext.Context) > 0 {
+		w.Details = withContext.Context
+	}
+	return w
+}
+
+// wireSentinels are the errors decoded wire errors wrap, so that errors.Is
+// and the Is* helpers work on errors received from another service
+var wireSentinels = map[ErrorCode]error{
+	ErrorCodeNotFound:    ErrNotFound,
+	ErrorCodeDuplicate:   ErrEntityDuplicate,
+	ErrorCodeValidation:  ErrValidationFailed,
+	ErrorCodeDatabase:    ErrDatabaseQuery,
+	ErrorCodeTransaction: ErrTransactionFailed,
+	ErrorCodeQuery:       ErrQueryFailed,
+	ErrorCodeTimeout:     ErrQueryTimeout,
+}
+
+// FromWireError returns the error of a wire envelope: a CodedError wrapping
+// the sentinel of its code, or ErrorWithContext carrying its details
+func FromWireError(w WireError) error {
+	var err error
+	if cause, ok := wireSentinels[w.Code]; ok {
+		err = NewCodedError(w.Code, w.Message, cause)
+	} else {
+		err = NewCodedError(w.Code, "", errors.New(w.Message))
+	}
+	if len(w.Details) > 0 {
+		err = &ErrorWithContext{Err: err, Context: w.Details}
+	}
+	return err
+}
+
+// EncodeError encodes an error as a WireError JSON document
+func EncodeError(err error) ([]byte, error) {
+	return json.Marshal(ToWireError(err))
+}
+
+// DecodeError decodes a WireError JSON document into an error
+func DecodeError(data []byte) (error, error) {
+	var w WireError
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to decode error: %w", err)
+	}
+	return FromWireError(w), nil
+}
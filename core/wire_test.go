@@ -0,0 +1,97 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPageWireRoundTrip(t *testing.T) {
+	page := &Page[TestUser]{
+		Content:          []*TestUser{{ID: 1, Email: "a@example.com"}},
+		Pageable:         PageRequest(2, 10, Order{Field: "email", Direction: Desc}),
+		TotalElements:    21,
+		TotalPages:       3,
+		Size:             10,
+		Number:           2,
+		NumberOfElements: 1,
+		Last:             true,
+		Sort:             Sort{Orders: []Order{{Field: "email", Direction: Desc}}},
+	}
+
+	data, err := EncodePage(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["total_elements"] != float64(21) || doc["page"] != float64(2) {
+		t.Errorf("Expected snake_case page fields, got %s", data)
+	}
+	if sort := doc["sort"].([]interface{}); sort[0].(map[string]interface{})["direction"] != "desc" {
+		t.Errorf("Expected a named direction, got %s", data)
+	}
+
+	decoded, err := DecodePage[TestUser](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Content[0].Email != "a@example.com" || decoded.Number != 2 || !decoded.Last {
+		t.Errorf("Expected the page back, got %+v", decoded)
+	}
+	if !reflect.DeepEqual(decoded.Sort, page.Sort) || decoded.Pageable.Page != 2 || decoded.Pageable.Size != 10 {
+		t.Errorf("Expected the sort and pageable back, got %+v", decoded)
+	}
+
+	empty, err := EncodePage(&Page[TestUser]{Empty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(empty) || string(empty[:13]) != `{"content":[]` {
+		t.Errorf("Expected empty content as an array, got %s", empty)
+	}
+
+	if _, err := SortFromWire([]WireOrder{{Field: "email", Direction: "sideways"}}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown direction, got %v", err)
+	}
+}
+
+func TestErrorWireRoundTrip(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+		is   error
+	}{
+		{WrapError(ErrNotFound, "user 7"), ErrorCodeNotFound, ErrNotFound},
+		{NewCodedError(ErrorCodeDuplicate, "email taken", ErrEntityDuplicate), ErrorCodeDuplicate, ErrEntityDuplicate},
+		{WithContext(ErrValidationFailed, "bad user", map[string]interface{}{"field": "email"}), ErrorCodeValidation, ErrValidationFailed},
+		{errors.New("boom"), ErrorCodeInternal, nil},
+	}
+	for _, tt := range tests {
+		data, err := EncodeError(tt.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeError(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ErrorCodeOf(decoded); got != tt.code {
+			t.Errorf("%v: expected code %s, got %s", tt.err, tt.code, got)
+		}
+		if tt.is != nil && !errors.Is(decoded, tt.is) {
+			t.Errorf("%v: expected the decoded error to wrap %v, got %v", tt.err, tt.is, decoded)
+		}
+	}
+
+	w := ToWireError(WithContext(ErrValidationFailed, "bad user", map[string]interface{}{"field": "email"}))
+	if w.Details["field"] != "email" {
+		t.Errorf("Expected the error context as details, got %+v", w)
+	}
+	if w := ToWireError(NewCodedError(ErrorCodeDuplicate, "email taken", ErrEntityDuplicate)); w.Message != "email taken" {
+		t.Errorf("Expected the coded message, got %q", w.Message)
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// FindAllWithQuery runs a query builder, such as a query.QueryBuilder with
+// joins, CTEs or GROUP BY, and scans the rows into entities. Result columns
+// are matched to entity fields by name, so the builder may select a subset
+// of the columns or extra ones, which are ignored; when a join returns a
+// column name twice the first occurrence is used. The query runs as built:
+// soft-delete scoping and the lock mode are not applied.
+func (r *BaseRepository[T, ID]) FindAllWithQuery(ctx context.Context, builder SubqueryBuilder) ([]*T, error) {
+	rows, err := r.queryBuilder(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*T, 0)
+	targets := r.columnTargets(rows.FieldDescriptions())
+	for rows.Next() {
+		entity := new(T)
+		if err := targets.scan(rows, entity); err != nil {
+			return nil, err
+		}
+		results = append(results, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindOneWithQuery runs a query builder like FindAllWithQuery and returns
+// the first row, or ErrNotFound
+func (r *BaseRepository[T, ID]) FindOneWithQuery(ctx context.Context, builder SubqueryBuilder) (*T, error) {
+	rows, err := r.queryBuilder(ctx, builder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFound
+	}
+	entity := new(T)
+	if err := r.columnTargets(rows.FieldDescriptions()).scan(rows, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// queryBuilder builds and runs the statement of a query builder
+func (r *BaseRepository[T, ID]) queryBuilder(ctx context.Context, builder SubqueryBuilder) (pgx.Rows, error) {
+	query, args := builder.Build()
+	r.logQuery(query, args)
+
+	if r.tx != nil {
+		return r.txConn(ctx).Query(ctx, query, args...)
+	}
+	return r.poolConn(ctx).Query(ctx, query, args...)
+}
+
+// columnScanner scans result columns into the entity fields they name
+type columnScanner struct {
+	fields []*Field // per result column; nil columns are discarded
+}
+
+// columnTargets maps result columns to entity fields by name
+func (r *BaseRepository[T, ID]) columnTargets(columns []pgconn.FieldDescription) columnScanner {
+	seen := make(map[*Field]bool, len(columns))
+	fields := make([]*Field, len(columns))
+	for i, column := range columns {
+		if f := r.lookupField(column.Name); f != nil && !seen[f] {
+			fields[i] = f
+			seen[f] = true
+		}
+	}
+	return columnScanner{fields: fields}
+}
+
+// scan scans the current row into dest
+func (s columnScanner) scan(row pgx.Row, dest interface{}) error {
+	v := reflect.ValueOf(dest).Elem()
+	targets := rowTargets{dest: make([]interface{}, 0, len(s.fields))}
+	for _, f := range s.fields {
+		if f == nil {
+			targets.dest = append(targets.dest, new(interface{}))
+			continue
+		}
+		targets.add(f, v)
+	}
+	return targets.scan(row)
+}
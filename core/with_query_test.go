@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// valuesRow is a pgx.Row that assigns fixed values to the scan destinations
+type valuesRow []interface{}
+
+func (v valuesRow) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		target := reflect.ValueOf(d).Elem()
+		value := reflect.ValueOf(v[i])
+		if target.Kind() == reflect.Ptr && value.Type().AssignableTo(target.Type().Elem()) {
+			ptr := reflect.New(value.Type())
+			ptr.Elem().Set(value)
+			value = ptr
+		}
+		if !value.Type().AssignableTo(target.Type()) {
+			return fmt.Errorf("cannot scan %T into %T", v[i], d)
+		}
+		target.Set(value)
+	}
+	return nil
+}
+
+func TestBaseRepository_FindWithQuery(t *testing.T) {
+	repo, err := NewBaseRepository[TestUser, int64](nil)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	t.Run("should scan columns by name and skip unknown or repeated ones", func(t *testing.T) {
+		columns := []pgconn.FieldDescription{{Name: "email"}, {Name: "order_count"}, {Name: "id"}, {Name: "id"}, {Name: "age"}}
+		targets := repo.columnTargets(columns)
+
+		user := new(TestUser)
+		if err := targets.scan(valuesRow{"a@example.com", int64(3), int64(7), int64(99), 30}, user); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		if user.Email != "a@example.com" || user.ID != 7 || user.Age != 30 || user.Username != "" {
+			t.Errorf("Unexpected entity %+v", user)
+		}
+	})
+
+	t.Run("should run the built query", func(t *testing.T) {
+		ctx, capture := (&Database{}).DryRun(context.Background())
+		builder := stubSubquery{
+			sql:  "SELECT test_user.* FROM test_user INNER JOIN orders ON orders.user_id = test_user.id WHERE orders.status = $1",
+			args: []interface{}{"paid"},
+		}
+
+		if _, err := repo.FindAllWithQuery(ctx, builder); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+		if _, err := repo.FindOneWithQuery(ctx, builder); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Expected ErrDryRun, got %v", err)
+		}
+
+		statements := capture.Statements()
+		if len(statements) != 2 || statements[0].SQL != builder.sql || statements[0].Args[0] != "paid" {
+			t.Errorf("Expected the builder's statement, got %v", statements)
+		}
+	})
+}
@@ -1,21 +1,31 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 )
 
 // QueryMethod represents a parsed query method
 type QueryMethod struct {
 	Name           string
 	Operation      Operation
+	Distinct       bool
 	Fields         []FieldCondition
+	Selected       []string     // Fields a finder selects, e.g. Email and Name in FindEmailAndNameByStatus
+	Projection     string       // DTO type a finder scans the selected columns into, if not the entity
+	Columns        []string     // Select list of a projection finder
+	Assignments    []Assignment // Columns an update method sets
 	SortFields     []SortField
 	Limit          int
 	ReturnType     ReturnType
 	Parameters     []Parameter
+	Pageable       string // Name of the core.Pageable parameter of a paged finder
+	Sort           string // Name of the core.Sort parameter of a sorted finder
 	GeneratedSQL   string
 }
 
@@ -27,6 +37,7 @@ const (
 	OpCount
 	OpExists
 	OpDelete
+	OpUpdate
 )
 
 // ReturnType represents the return type of a method
@@ -38,13 +49,15 @@ const (
 	ReturnInt64
 	ReturnBool
 	ReturnError
+	ReturnPage
 )
 
 // FieldCondition represents a condition on a field
 type FieldCondition struct {
-	FieldName string
-	Operator  Operator
-	AndOr     string // "AND" or "OR"
+	FieldName  string
+	Operator   Operator
+	AndOr      string // "AND" or "OR"
+	IgnoreCase bool   // Compare lower-cased values
 }
 
 // Operator represents a comparison operator
@@ -70,8 +83,18 @@ const (
 	OpIgnoreCase
 	OpTrue
 	OpFalse
+	OpAfter
+	OpBefore
+	OpInDay
 )
 
+// Assignment is a column set by an update method, e.g. Status in
+// UpdateStatusByID or Active in SetActiveFalseByID
+type Assignment struct {
+	FieldName string
+	Value     string // "true", "false" or "nil" for a constant, "" for a parameter
+}
+
 // SortField represents a sort field
 type SortField struct {
 	FieldName string
@@ -86,8 +109,7 @@ type Parameter struct {
 
 // Analyzer analyzes method names and generates query methods
 type Analyzer struct {
-	entityType reflect.Type
-	fields     map[string]reflect.StructField
+	fields map[string]string // Go field name -> field type
 }
 
 // NewAnalyzer creates a new analyzer for an entity type
@@ -99,93 +121,141 @@ func NewAnalyzer(entityType reflect.Type) (*Analyzer, error) {
 		return nil, fmt.Errorf("entity type must be a struct")
 	}
 
-	fields := make(map[string]reflect.StructField)
+	fields := make(map[string]string)
 	for i := 0; i < entityType.NumField(); i++ {
 		field := entityType.Field(i)
-		fields[field.Name] = field
+		fields[field.Name] = field.Type.String()
 	}
 
-	return &Analyzer{
-		entityType: entityType,
-		fields:     fields,
-	}, nil
+	return &Analyzer{fields: fields}, nil
 }
 
-// AnalyzeMethod analyzes a method name and returns a QueryMethod
+// NewAnalyzerForType creates an analyzer for an entity loaded by a TypeLoader
+func NewAnalyzerForType(info *EntityTypeInfo) *Analyzer {
+	fields := make(map[string]string, len(info.Fields))
+	for _, field := range info.Fields {
+		fields[field.Name] = field.TypeName
+	}
+	return &Analyzer{fields: fields}
+}
+
+// NewAnalyzerForFields creates an analyzer for an entity parsed from source
+// by ParseEntityFields
+func NewAnalyzerForFields(info *EntityFields) *Analyzer {
+	fields := make(map[string]string, len(info.Fields))
+	for _, field := range info.Fields {
+		fields[field.Name] = field.Type
+	}
+	return &Analyzer{fields: fields}
+}
+
+// AnalyzeMethod analyzes a method name and returns a QueryMethod. The name
+// is tokenized on the entity's field names, so fields containing keywords
+// such as Order or Brand are read whole.
 func (a *Analyzer) AnalyzeMethod(methodName string) (*QueryMethod, error) {
+	method, err := a.analyzeMethod(methodName)
+	var nameErr *MethodNameError
+	if errors.As(err, &nameErr) {
+		nameErr.Method = methodName
+	}
+	return method, err
+}
+
+// analyzeMethod is AnalyzeMethod without the method name in its errors
+func (a *Analyzer) analyzeMethod(methodName string) (*QueryMethod, error) {
 	method := &QueryMethod{
 		Name: methodName,
 	}
 
 	// Determine operation type
-	if strings.HasPrefix(methodName, "Find") {
-		method.Operation = OpFind
-	} else if strings.HasPrefix(methodName, "Count") {
-		method.Operation = OpCount
-	} else if strings.HasPrefix(methodName, "Exists") {
-		method.Operation = OpExists
-	} else if strings.HasPrefix(methodName, "Delete") {
-		method.Operation = OpDelete
-	} else {
-		return nil, fmt.Errorf("unsupported method prefix: %s", methodName)
-	}
-
-	// Parse method name
 	remaining := methodName
-	var err error
+	for _, prefix := range []struct {
+		name      string
+		operation Operation
+	}{
+		{"Find", OpFind}, {"Count", OpCount}, {"Exists", OpExists}, {"Delete", OpDelete}, {"Update", OpUpdate}, {"Set", OpUpdate},
+	} {
+		if strings.HasPrefix(remaining, prefix.name) {
+			method.Operation = prefix.operation
+			remaining = strings.TrimPrefix(remaining, prefix.name)
+			break
+		}
+	}
+	if remaining == methodName {
+		return nil, &MethodNameError{Token: camelWords(methodName)[0], Reason: "unsupported method prefix",
+			Suggestions: closest(camelWords(methodName)[0], []string{"Find", "Count", "Exists", "Delete", "Update", "Set"})}
+	}
 
-	// Check for First/TopN
-	if strings.HasPrefix(remaining, "FindFirst") {
-		method.Limit = 1
-		remaining = strings.TrimPrefix(remaining, "FindFirst")
-	} else if strings.HasPrefix(remaining, "FindTop") {
-		// Extract number from FindTop{N}
-		re := regexp.MustCompile(`^FindTop(\d+)`)
-		matches := re.FindStringSubmatch(remaining)
-		if len(matches) > 1 {
-			fmt.Sscanf(matches[1], "%d", &method.Limit)
-			remaining = strings.TrimPrefix(remaining, matches[0])
-		}
-	} else if strings.HasPrefix(remaining, "Find") {
-		remaining = strings.TrimPrefix(remaining, "Find")
-	} else if strings.HasPrefix(remaining, "Count") {
-		remaining = strings.TrimPrefix(remaining, "Count")
-	} else if strings.HasPrefix(remaining, "Exists") {
-		remaining = strings.TrimPrefix(remaining, "Exists")
-	} else if strings.HasPrefix(remaining, "Delete") {
-		remaining = strings.TrimPrefix(remaining, "Delete")
-	}
-
-	// Parse "By" conditions
-	if strings.HasPrefix(remaining, "By") {
-		remaining = strings.TrimPrefix(remaining, "By")
-		// Check if there's an OrderBy clause before parsing conditions
-		orderByPos := strings.Index(remaining, "OrderBy")
-		if orderByPos > 0 {
-			// Parse conditions up to OrderBy
-			conditionsPart := remaining[:orderByPos]
-			_, err = a.parseConditions(conditionsPart, method)
+	var err error
+	switch method.Operation {
+	case OpFind, OpCount:
+		// Rows are distinct by their primary key, so Distinct only changes
+		// the SQL of finders
+		if strings.HasPrefix(remaining, "Distinct") {
+			method.Distinct = true
+			remaining = strings.TrimPrefix(remaining, "Distinct")
+		}
+		if method.Operation == OpFind {
+			remaining, err = parseLimit(remaining, method)
 			if err != nil {
 				return nil, err
 			}
-			remaining = remaining[orderByPos:]
-		} else {
-			remaining, err = a.parseConditions(remaining, method)
-			if err != nil {
-				return nil, err
+			if remaining != "" && !strings.HasPrefix(remaining, "By") && !strings.HasPrefix(remaining, "OrderBy") {
+				remaining, err = a.parseSelected(remaining, method)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	case OpUpdate:
+		remaining, err = a.parseAssignments(remaining, method)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse "By" conditions. FindTopByOrderBy... has none, unless the
+	// entity has an Order field the rest of the name can compare.
+	if strings.HasPrefix(remaining, "By") {
+		rest, err := a.parseConditions(strings.TrimPrefix(remaining, "By"), method)
+		switch {
+		case err == nil:
+			remaining = rest
+		case strings.HasPrefix(remaining, "ByOrderBy"):
+			method.Fields = nil
+			remaining = strings.TrimPrefix(remaining, "By")
+		default:
+			return nil, err
+		}
+	}
+
+	// AllIgnoreCase applies to every string condition that can ignore case
+	if len(method.Fields) > 0 && strings.HasPrefix(remaining, "AllIgnoreCase") {
+		remaining = strings.TrimPrefix(remaining, "AllIgnoreCase")
+		for i := range method.Fields {
+			if a.isString(method.Fields[i].FieldName) && ignoresCase(method.Fields[i].Operator) {
+				method.Fields[i].IgnoreCase = true
 			}
 		}
 	}
 
 	// Parse OrderBy clause
 	if strings.HasPrefix(remaining, "OrderBy") {
-		remaining = strings.TrimPrefix(remaining, "OrderBy")
-		remaining, err = a.parseOrderBy(remaining, method)
+		remaining, err = a.parseOrderBy(strings.TrimPrefix(remaining, "OrderBy"), method)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if remaining != "" {
+		token := leadingToken(remaining)
+		return nil, &MethodNameError{Token: token, Reason: "unsupported keyword",
+			Suggestions: closest(token, append(a.fieldNames(), "And", "Or", "OrderBy", "AllIgnoreCase"))}
+	}
+	if method.Operation == OpUpdate && (len(method.Fields) == 0 || len(method.SortFields) > 0) {
+		return nil, fmt.Errorf("update methods must end in By conditions and cannot be ordered: %s", methodName)
+	}
+
 	// Determine return type based on operation
 	switch method.Operation {
 	case OpFind:
@@ -198,7 +268,7 @@ func (a *Analyzer) AnalyzeMethod(methodName string) (*QueryMethod, error) {
 		method.ReturnType = ReturnInt64
 	case OpExists:
 		method.ReturnType = ReturnBool
-	case OpDelete:
+	case OpDelete, OpUpdate:
 		method.ReturnType = ReturnInt64
 	}
 
@@ -208,133 +278,441 @@ func (a *Analyzer) AnalyzeMethod(methodName string) (*QueryMethod, error) {
 	return method, nil
 }
 
-// parseConditions parses field conditions from method name
+// MethodNameError reports a derived method name the Analyzer cannot read,
+// with the offending part of the name and the nearest valid spellings
+type MethodNameError struct {
+	Method      string
+	Token       string   // Part of the name that could not be read
+	Reason      string   // e.g. "unknown field"
+	Suggestions []string // Nearest fields or keywords, closest first
+}
+
+// Error implements error
+func (e *MethodNameError) Error() string {
+	msg := fmt.Sprintf("%s: %s %q", e.Method, e.Reason, e.Token)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+// fieldNames returns the entity's field names, sorted
+func (a *Analyzer) fieldNames() []string {
+	names := make([]string, 0, len(a.fields))
+	for name := range a.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// leadingToken returns the camel-case words s starts with, up to the first
+// word that starts a keyword, And, Or or OrderBy, e.g. Emial for
+// EmialContainingAndName
+func leadingToken(s string) string {
+	words := camelWords(s)
+	token := words[0]
+	for _, word := range words[1:] {
+		rest := s[len(token):]
+		if startsKeyword(rest) {
+			break
+		}
+		token += word
+	}
+	return token
+}
+
+// startsKeyword reports whether s starts with a condition keyword or a
+// connective that ends on a word boundary
+func startsKeyword(s string) bool {
+	for _, keyword := range append([]string{"And", "Or", "OrderBy", "AllIgnoreCase", "IgnoreCase"}, keywordsByLength...) {
+		if keyword == "" || !strings.HasPrefix(s, keyword) {
+			continue
+		}
+		if rest := s[len(keyword):]; rest == "" || unicode.IsUpper(rune(rest[0])) || unicode.IsDigit(rune(rest[0])) {
+			return true
+		}
+	}
+	return false
+}
+
+// camelWords splits s before each upper-case letter; s is never split into
+// no words
+func camelWords(s string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if unicode.IsUpper(rune(s[i])) && !unicode.IsUpper(rune(s[i-1])) {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	return append(words, s[start:])
+}
+
+// closest returns up to three candidates within a small edit distance of
+// token, closest first
+func closest(token string, candidates []string) []string {
+	limit := max(2, len(token)/3)
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if d := editDistance(strings.ToLower(token), strings.ToLower(candidate)); d <= limit {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	var names []string
+	for _, m := range matches {
+		if len(names) == 3 {
+			break
+		}
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// parseLimit parses the First, FirstN, Top or TopN of a finder, and the All
+// of FindAllBy, which changes nothing
+func parseLimit(remaining string, method *QueryMethod) (string, error) {
+	matches := limitPattern.FindStringSubmatch(remaining)
+	if matches == nil {
+		if rest := strings.TrimPrefix(remaining, "All"); strings.HasPrefix(rest, "By") || strings.HasPrefix(rest, "OrderBy") {
+			return rest, nil
+		}
+		return remaining, nil
+	}
+
+	method.Limit = 1
+	if matches[2] != "" {
+		fmt.Sscanf(matches[2], "%d", &method.Limit)
+		if method.Limit <= 0 {
+			return remaining, fmt.Errorf("%s must select at least one row: %s", matches[0], method.Name)
+		}
+	}
+	return strings.TrimPrefix(remaining, matches[0]), nil
+}
+
+// limitPattern matches First, Top and their row counts
+var limitPattern = regexp.MustCompile(`^(First|Top)(\d*)`)
+
+// conditionKeywords maps the keywords that can follow a field name in a
+// condition to their operators. Is forms and the Equals, Contains,
+// StartsWith and EndsWith aliases read like the short ones.
+var conditionKeywords = map[string]Operator{
+	"": OpEqual, "Is": OpEqual, "Equals": OpEqual,
+	"Not": OpNotEqual, "IsNot": OpNotEqual,
+	"GreaterThan": OpGreaterThan, "IsGreaterThan": OpGreaterThan,
+	"GreaterThanEqual": OpGreaterThanEqual, "IsGreaterThanEqual": OpGreaterThanEqual,
+	"LessThan": OpLessThan, "IsLessThan": OpLessThan,
+	"LessThanEqual": OpLessThanEqual, "IsLessThanEqual": OpLessThanEqual,
+	"After": OpAfter, "IsAfter": OpAfter,
+	"Before": OpBefore, "IsBefore": OpBefore,
+	"InDay": OpInDay, "IsInDay": OpInDay,
+	"Like": OpLike, "IsLike": OpLike,
+	"NotLike": OpNotLike, "IsNotLike": OpNotLike,
+	"In": OpIn, "IsIn": OpIn,
+	"NotIn": OpNotIn, "IsNotIn": OpNotIn,
+	"Null": OpIsNull, "IsNull": OpIsNull,
+	"NotNull": OpIsNotNull, "IsNotNull": OpIsNotNull,
+	"Between": OpBetween, "IsBetween": OpBetween,
+	"Containing": OpContaining, "IsContaining": OpContaining, "Contains": OpContaining,
+	"StartingWith": OpStartingWith, "IsStartingWith": OpStartingWith, "StartsWith": OpStartingWith,
+	"EndingWith": OpEndingWith, "IsEndingWith": OpEndingWith, "EndsWith": OpEndingWith,
+	"True": OpTrue, "IsTrue": OpTrue,
+	"False": OpFalse, "IsFalse": OpFalse,
+}
+
+// keywordsByLength lists conditionKeywords longest first, so that
+// GreaterThanEqual is tried before GreaterThan
+var keywordsByLength = func() []string {
+	keywords := make([]string, 0, len(conditionKeywords))
+	for keyword := range conditionKeywords {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if len(keywords[i]) != len(keywords[j]) {
+			return len(keywords[i]) > len(keywords[j])
+		}
+		return keywords[i] < keywords[j]
+	})
+	return keywords
+}()
+
+// ignoresCase reports whether IgnoreCase can be applied to an operator
+func ignoresCase(operator Operator) bool {
+	switch operator {
+	case OpEqual, OpNotEqual, OpLike, OpNotLike, OpContaining, OpStartingWith, OpEndingWith:
+		return true
+	}
+	return false
+}
+
+// fieldsAt returns the entity fields s starts with, longest first
+func (a *Analyzer) fieldsAt(s string) []string {
+	var fields []string
+	for name := range a.fields {
+		if strings.HasPrefix(s, name) {
+			fields = append(fields, name)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return len(fields[i]) > len(fields[j]) })
+	return fields
+}
+
+// isString reports whether a field holds a string
+func (a *Analyzer) isString(field string) bool {
+	return a.fields[field] == "string" || a.fields[field] == "*string"
+}
+
+// conditionEnd reports whether a condition can end before s: at the end of
+// the name, before another condition, or before AllIgnoreCase or OrderBy
+func (a *Analyzer) conditionEnd(s string) bool {
+	switch {
+	case s == "", strings.HasPrefix(s, "OrderBy"), strings.HasPrefix(s, "AllIgnoreCase"):
+		return true
+	case strings.HasPrefix(s, "And"):
+		return len(a.fieldsAt(s[len("And"):])) > 0
+	case strings.HasPrefix(s, "Or"):
+		return len(a.fieldsAt(s[len("Or"):])) > 0
+	}
+	return false
+}
+
+// parseAssignments parses the columns an update method sets, up to the By
+// that starts its conditions. Each By in the name is tried in turn, so field
+// names containing By (such as UpdatedBy) can be set too.
+func (a *Analyzer) parseAssignments(remaining string, method *QueryMethod) (string, error) {
+	for start := 0; ; {
+		pos := strings.Index(remaining[start:], "By")
+		if pos < 0 {
+			return remaining, fmt.Errorf("update methods must name the columns to set before By: %s", method.Name)
+		}
+		pos += start
+		if assignments, ok := a.assignments(remaining[:pos]); ok {
+			method.Assignments = assignments
+			return remaining[pos:], nil
+		}
+		start = pos + len("By")
+	}
+}
+
+// assignments splits the part of an update method's name before By into
+// the fields it sets, reporting false when it does not name entity fields
+func (a *Analyzer) assignments(part string) ([]Assignment, bool) {
+	var assignments []Assignment
+	for part != "" || len(assignments) == 0 {
+		if len(assignments) > 0 {
+			if !strings.HasPrefix(part, "And") {
+				return nil, false
+			}
+			part = strings.TrimPrefix(part, "And")
+		}
+		assignment, rest, ok := a.assignment(part)
+		if !ok {
+			return nil, false
+		}
+		assignments = append(assignments, assignment)
+		part = rest
+	}
+	return assignments, true
+}
+
+// assignment parses the field an update method sets at the start of part,
+// with an optional True, False or Null constant
+func (a *Analyzer) assignment(part string) (Assignment, string, bool) {
+	constants := []struct{ suffix, value string }{{"True", "true"}, {"False", "false"}, {"Null", "nil"}, {"", ""}}
+	for _, field := range a.fieldsAt(part) {
+		rest := part[len(field):]
+		for _, constant := range constants {
+			after := strings.TrimPrefix(rest, constant.suffix)
+			if !strings.HasPrefix(rest, constant.suffix) {
+				continue
+			}
+			if after == "" || strings.HasPrefix(after, "And") && len(a.fieldsAt(after[len("And"):])) > 0 {
+				return Assignment{FieldName: field, Value: constant.value}, after, true
+			}
+		}
+	}
+	return Assignment{}, part, false
+}
+
+// parseSelected parses the fields a finder selects before its By conditions,
+// joined by And
+func (a *Analyzer) parseSelected(remaining string, method *QueryMethod) (string, error) {
+	for start := 0; ; {
+		pos := strings.Index(remaining[start:], "By")
+		if pos < 0 {
+			return remaining, fmt.Errorf("could not parse %q in %s", remaining, method.Name)
+		}
+		pos += start
+		if selected, ok := a.selected(remaining[:pos]); ok {
+			method.Selected = selected
+			return remaining[pos:], nil
+		}
+		start = pos + len("By")
+	}
+}
+
+// selected splits the part of a finder's name before By into the entity
+// fields it names, reporting false when it does not name entity fields
+func (a *Analyzer) selected(part string) ([]string, bool) {
+	if part == "" {
+		return nil, false
+	}
+	for _, field := range a.fieldsAt(part) {
+		rest := part[len(field):]
+		if rest == "" {
+			return []string{field}, true
+		}
+		if !strings.HasPrefix(rest, "And") {
+			continue
+		}
+		if fields, ok := a.selected(rest[len("And"):]); ok {
+			return append([]string{field}, fields...), true
+		}
+	}
+	return nil, false
+}
+
+// parseConditions parses field conditions from method name, up to an
+// OrderBy or AllIgnoreCase
 func (a *Analyzer) parseConditions(remaining string, method *QueryMethod) (string, error) {
-	firstField := true
-	for remaining != "" {
-		// Check for And/Or (but not on first field)
+	for first := true; first || remaining != ""; first = false {
+		// Conditions after the first are joined by And or Or
 		andOr := ""
-		if !firstField {
-			if strings.HasPrefix(remaining, "And") {
+		if !first {
+			switch {
+			case strings.HasPrefix(remaining, "And") && len(a.fieldsAt(remaining[len("And"):])) > 0:
 				andOr = "AND"
 				remaining = strings.TrimPrefix(remaining, "And")
-			} else if strings.HasPrefix(remaining, "Or") {
+			case strings.HasPrefix(remaining, "Or") && len(a.fieldsAt(remaining[len("Or"):])) > 0:
 				andOr = "OR"
 				remaining = strings.TrimPrefix(remaining, "Or")
-			} else if strings.HasPrefix(remaining, "OrderBy") {
-				// Stop parsing conditions, OrderBy follows
-				break
+			default:
+				return remaining, nil
 			}
 		}
 
-		// Parse field name and operator
-		fieldName, operator, consumed, err := a.parseFieldCondition(remaining)
+		condition, rest, err := a.parseFieldCondition(remaining)
 		if err != nil {
 			return remaining, err
 		}
-
-		// Validate field exists
-		if _, exists := a.fields[fieldName]; !exists {
-			return remaining, fmt.Errorf("field %s not found in entity", fieldName)
-		}
-
-		method.Fields = append(method.Fields, FieldCondition{
-			FieldName: fieldName,
-			Operator:  operator,
-			AndOr:     andOr,
-		})
-
-		remaining = remaining[consumed:]
-		firstField = false
+		condition.AndOr = andOr
+		method.Fields = append(method.Fields, condition)
+		remaining = rest
 	}
 
 	return remaining, nil
 }
 
-// parseFieldCondition parses a single field condition
-// It stops at "And", "Or", or "OrderBy" to allow proper parsing of multiple conditions
-func (a *Analyzer) parseFieldCondition(remaining string) (fieldName string, operator Operator, consumed int, err error) {
-	// Find where the field condition ends (at And, Or, or OrderBy)
-	endPos := len(remaining)
-	if andPos := strings.Index(remaining, "And"); andPos > 0 && andPos < endPos {
-		endPos = andPos
-	}
-	if orPos := strings.Index(remaining, "Or"); orPos > 0 && orPos < endPos {
-		endPos = orPos
-	}
-	if orderByPos := strings.Index(remaining, "OrderBy"); orderByPos > 0 && orderByPos < endPos {
-		endPos = orderByPos
+// parseFieldCondition parses the condition at the start of remaining: an
+// entity field, a keyword and an optional IgnoreCase. Longer fields and
+// keywords are tried first, and a match only counts where a condition can
+// end, so Order is read as a field rather than as Or.
+func (a *Analyzer) parseFieldCondition(remaining string) (FieldCondition, string, error) {
+	fields := a.fieldsAt(remaining)
+	if len(fields) == 0 {
+		token := leadingToken(remaining)
+		return FieldCondition{}, remaining, &MethodNameError{Token: token, Reason: "unknown field",
+			Suggestions: closest(token, a.fieldNames())}
 	}
 
-	// Extract the field condition part
-	fieldPart := remaining[:endPos]
+	for _, field := range fields {
+		rest := remaining[len(field):]
+		for _, keyword := range keywordsByLength {
+			if !strings.HasPrefix(rest, keyword) {
+				continue
+			}
+			after := rest[len(keyword):]
+			ignoreCase := false
+			for _, suffix := range []string{"IgnoreCase", "IgnoringCase"} {
+				if strings.HasPrefix(after, suffix) && a.conditionEnd(after[len(suffix):]) {
+					ignoreCase = true
+					after = after[len(suffix):]
+					break
+				}
+			}
+			if !a.conditionEnd(after) {
+				continue
+			}
 
-	// Try to match field name with various operators (in order of specificity)
-	patterns := []struct {
-		pattern  *regexp.Regexp
-		operator Operator
-	}{
-		{regexp.MustCompile(`^(\w+)GreaterThanEqual$`), OpGreaterThanEqual},
-		{regexp.MustCompile(`^(\w+)LessThanEqual$`), OpLessThanEqual},
-		{regexp.MustCompile(`^(\w+)GreaterThan$`), OpGreaterThan},
-		{regexp.MustCompile(`^(\w+)LessThan$`), OpLessThan},
-		{regexp.MustCompile(`^(\w+)Containing$`), OpContaining},
-		{regexp.MustCompile(`^(\w+)StartingWith$`), OpStartingWith},
-		{regexp.MustCompile(`^(\w+)EndingWith$`), OpEndingWith},
-		{regexp.MustCompile(`^(\w+)NotLike$`), OpNotLike},
-		{regexp.MustCompile(`^(\w+)Like$`), OpLike},
-		{regexp.MustCompile(`^(\w+)NotIn$`), OpNotIn},
-		{regexp.MustCompile(`^(\w+)In$`), OpIn},
-		{regexp.MustCompile(`^(\w+)IsNotNull$`), OpIsNotNull},
-		{regexp.MustCompile(`^(\w+)IsNull$`), OpIsNull},
-		{regexp.MustCompile(`^(\w+)Between$`), OpBetween},
-		{regexp.MustCompile(`^(\w+)IgnoreCase$`), OpIgnoreCase},
-		{regexp.MustCompile(`^(\w+)True$`), OpTrue},
-		{regexp.MustCompile(`^(\w+)False$`), OpFalse},
-		{regexp.MustCompile(`^(\w+)$`), OpEqual}, // Default to equal
-	}
-
-	for _, p := range patterns {
-		matches := p.pattern.FindStringSubmatch(fieldPart)
-		if len(matches) > 1 {
-			fieldName = matches[1]
-			operator = p.operator
-			consumed = len(fieldPart)
-			return
-		}
-	}
-
-	return "", OpEqual, 0, fmt.Errorf("could not parse field condition from: %s", fieldPart)
-}
-
-// parseOrderBy parses OrderBy clause
-func (a *Analyzer) parseOrderBy(remaining string, method *QueryMethod) (string, error) {
-	// Parse field name
-	re := regexp.MustCompile(`^(\w+)(Asc|Desc)`)
-	matches := re.FindStringSubmatch(remaining)
-	if len(matches) < 3 {
-		return remaining, fmt.Errorf("invalid OrderBy format: %s", remaining)
+			condition := FieldCondition{FieldName: field, Operator: conditionKeywords[keyword], IgnoreCase: ignoreCase}
+			if ignoreCase && !ignoresCase(condition.Operator) {
+				return condition, after, fmt.Errorf("IgnoreCase cannot be applied to %s%s", field, keyword)
+			}
+			return condition, after, nil
+		}
 	}
 
-	fieldName := matches[1]
-	direction := strings.ToUpper(matches[2])
+	token := leadingToken(remaining[len(fields[0]):])
+	return FieldCondition{}, remaining, &MethodNameError{Token: token, Reason: "unsupported keyword after " + fields[0],
+		Suggestions: closest(token, keywordsByLength)}
+}
 
-	// Validate field exists
-	if _, exists := a.fields[fieldName]; !exists {
-		return remaining, fmt.Errorf("field %s not found in entity", fieldName)
+// parseOrderBy parses the fields of an OrderBy clause, each with an
+// optional Asc or Desc (Asc by default)
+func (a *Analyzer) parseOrderBy(remaining string, method *QueryMethod) (string, error) {
+	if remaining == "" {
+		return remaining, fmt.Errorf("invalid OrderBy format: %s", method.Name)
 	}
 
-	method.SortFields = append(method.SortFields, SortField{
-		FieldName: fieldName,
-		Direction: direction,
-	})
-
-	remaining = remaining[len(matches[0]):]
-
-	// Check for additional sort fields
-	if remaining != "" && !strings.HasPrefix(remaining, "And") && !strings.HasPrefix(remaining, "Or") {
-		// Try to parse another OrderBy field
-		return a.parseOrderBy(remaining, method)
+	for remaining != "" {
+		matched := false
+		for _, field := range a.fieldsAt(remaining) {
+			rest := remaining[len(field):]
+			for _, direction := range []string{"Asc", "Desc", ""} {
+				after := strings.TrimPrefix(rest, direction)
+				if !strings.HasPrefix(rest, direction) || after != "" && len(a.fieldsAt(after)) == 0 {
+					continue
+				}
+				if direction == "" {
+					direction = "Asc"
+				}
+				method.SortFields = append(method.SortFields, SortField{
+					FieldName: field,
+					Direction: strings.ToUpper(direction),
+				})
+				remaining, matched = after, true
+				break
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			token := leadingToken(remaining)
+			return remaining, &MethodNameError{Token: token, Reason: "unknown OrderBy field",
+				Suggestions: closest(strings.TrimSuffix(strings.TrimSuffix(token, "Desc"), "Asc"), a.fieldNames())}
+		}
 	}
 
 	return remaining, nil
@@ -346,8 +724,7 @@ func (a *Analyzer) generateParameters(method *QueryMethod) []Parameter {
 	paramIndex := 1
 
 	for _, field := range method.Fields {
-		fieldType := a.fields[field.FieldName].Type
-		typeStr := fieldType.String()
+		typeStr := a.fields[field.FieldName]
 
 		switch field.Operator {
 		case OpBetween:
@@ -376,6 +753,21 @@ func (a *Analyzer) generateParameters(method *QueryMethod) []Parameter {
 		paramIndex++
 	}
 
+	// Update methods take the values they set after those they filter by
+	for _, assignment := range method.Assignments {
+		if assignment.Value != "" {
+			continue
+		}
+		name := strings.ToLower(assignment.FieldName)
+		for _, param := range params {
+			if param.Name == name {
+				name = "new" + assignment.FieldName
+				break
+			}
+		}
+		params = append(params, Parameter{Name: name, Type: a.fields[assignment.FieldName]})
+	}
+
 	return params
 }
 
@@ -386,14 +778,19 @@ func (m *QueryMethod) ToSQL(tableName string, fieldToColumn func(string) string)
 
 	for i, field := range m.Fields {
 		columnName := fieldToColumn(field.FieldName)
+		placeholder := fmt.Sprintf("$%d", paramIndex)
+		if field.IgnoreCase {
+			columnName = "LOWER(" + columnName + ")"
+			placeholder = "LOWER(" + placeholder + ")"
+		}
 		var condition string
 
 		switch field.Operator {
 		case OpEqual:
-			condition = fmt.Sprintf("%s = $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s = %s", columnName, placeholder)
 			paramIndex++
 		case OpNotEqual:
-			condition = fmt.Sprintf("%s != $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s != %s", columnName, placeholder)
 			paramIndex++
 		case OpGreaterThan:
 			condition = fmt.Sprintf("%s > $%d", columnName, paramIndex)
@@ -408,10 +805,10 @@ func (m *QueryMethod) ToSQL(tableName string, fieldToColumn func(string) string)
 			condition = fmt.Sprintf("%s <= $%d", columnName, paramIndex)
 			paramIndex++
 		case OpLike:
-			condition = fmt.Sprintf("%s LIKE $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s LIKE %s", columnName, placeholder)
 			paramIndex++
 		case OpNotLike:
-			condition = fmt.Sprintf("%s NOT LIKE $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s NOT LIKE %s", columnName, placeholder)
 			paramIndex++
 		case OpIn:
 			// For IN, we need to handle slice parameter - use PostgreSQL ANY
@@ -428,17 +825,29 @@ func (m *QueryMethod) ToSQL(tableName string, fieldToColumn func(string) string)
 			condition = fmt.Sprintf("%s BETWEEN $%d AND $%d", columnName, paramIndex, paramIndex+1)
 			paramIndex += 2
 		case OpContaining:
-			condition = fmt.Sprintf("%s LIKE $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s LIKE %s", columnName, placeholder)
 			paramIndex++
 		case OpStartingWith:
-			condition = fmt.Sprintf("%s LIKE $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s LIKE %s", columnName, placeholder)
 			paramIndex++
 		case OpEndingWith:
-			condition = fmt.Sprintf("%s LIKE $%d", columnName, paramIndex)
+			condition = fmt.Sprintf("%s LIKE %s", columnName, placeholder)
 			paramIndex++
 		case OpIgnoreCase:
 			condition = fmt.Sprintf("LOWER(%s) = LOWER($%d)", columnName, paramIndex)
 			paramIndex++
+		case OpAfter:
+			condition = fmt.Sprintf("%s > $%d", columnName, paramIndex)
+			paramIndex++
+		case OpBefore:
+			condition = fmt.Sprintf("%s < $%d", columnName, paramIndex)
+			paramIndex++
+		case OpInDay:
+			// Compare against the day's bounds rather than truncating the
+			// column, so an index on it can still be used
+			condition = fmt.Sprintf("(%s >= date_trunc('day', $%d::timestamptz) AND %s < date_trunc('day', $%d::timestamptz) + INTERVAL '1 day')",
+				columnName, paramIndex, columnName, paramIndex)
+			paramIndex++
 		case OpTrue:
 			condition = fmt.Sprintf("%s = true", columnName)
 		case OpFalse:
@@ -455,6 +864,9 @@ func (m *QueryMethod) ToSQL(tableName string, fieldToColumn func(string) string)
 
 	// Build full query
 	query := fmt.Sprintf("SELECT * FROM %s", tableName)
+	if m.Distinct {
+		query = fmt.Sprintf("SELECT DISTINCT * FROM %s", tableName)
+	}
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
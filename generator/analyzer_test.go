@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -225,3 +226,236 @@ func contains(s, substr string) bool {
 		   contains(s[1:], substr))))
 }
 
+
+func TestAnalyzer_TimeOperators(t *testing.T) {
+	analyzer, err := NewAnalyzer(reflect.TypeOf(TestUser{}))
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+	fieldToColumn := func(fieldName string) string { return fieldName }
+
+	tests := []struct {
+		methodName string
+		expected   string
+	}{
+		{"FindByCreatedAtAfter", "SELECT * FROM users WHERE CreatedAt > $1"},
+		{"FindByCreatedAtBefore", "SELECT * FROM users WHERE CreatedAt < $1"},
+		{"FindByCreatedAtAfterAndCreatedAtBefore", "SELECT * FROM users WHERE CreatedAt > $1 AND CreatedAt < $2"},
+		{"FindByStatusAndCreatedAtInDay", "SELECT * FROM users WHERE Status = $1 AND (CreatedAt >= date_trunc('day', $2::timestamptz) AND CreatedAt < date_trunc('day', $2::timestamptz) + INTERVAL '1 day')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.methodName, func(t *testing.T) {
+			method, err := analyzer.AnalyzeMethod(tt.methodName)
+			if err != nil {
+				t.Fatalf("Failed to analyze: %v", err)
+			}
+			if sql := method.ToSQL("users", fieldToColumn); sql != tt.expected {
+				t.Errorf("Expected SQL '%s', got '%s'", tt.expected, sql)
+			}
+		})
+	}
+
+	method, _ := analyzer.AnalyzeMethod("FindByCreatedAtInDay")
+	params := analyzer.generateParameters(method)
+	if len(params) != 1 || params[0].Name != "createdat" || params[0].Type != "string" {
+		t.Errorf("Expected a single createdat parameter, got %+v", params)
+	}
+}
+
+func TestAnalyzer_UpdateMethods(t *testing.T) {
+	analyzer, err := NewAnalyzer(reflect.TypeOf(TestUser{}))
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+
+	tests := []struct {
+		methodName  string
+		assignments []Assignment
+		params      []Parameter
+	}{
+		{"UpdateStatusByID", []Assignment{{FieldName: "Status"}},
+			[]Parameter{{Name: "id", Type: "int64"}, {Name: "status", Type: "string"}}},
+		{"SetIsActiveFalseByCreatedAtBefore", []Assignment{{FieldName: "IsActive", Value: "false"}},
+			[]Parameter{{Name: "createdat", Type: "string"}}},
+		{"UpdateStatusAndAgeByEmail", []Assignment{{FieldName: "Status"}, {FieldName: "Age"}},
+			[]Parameter{{Name: "email", Type: "string"}, {Name: "status", Type: "string"}, {Name: "age", Type: "int"}}},
+		{"UpdateStatusByStatus", []Assignment{{FieldName: "Status"}},
+			[]Parameter{{Name: "status", Type: "string"}, {Name: "newStatus", Type: "string"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.methodName, func(t *testing.T) {
+			method, err := analyzer.AnalyzeMethod(tt.methodName)
+			if err != nil {
+				t.Fatalf("Failed to analyze: %v", err)
+			}
+			if method.Operation != OpUpdate || method.ReturnType != ReturnInt64 {
+				t.Errorf("Expected an update returning int64, got %v, %v", method.Operation, method.ReturnType)
+			}
+			if !reflect.DeepEqual(method.Assignments, tt.assignments) {
+				t.Errorf("Expected assignments %+v, got %+v", tt.assignments, method.Assignments)
+			}
+			if !reflect.DeepEqual(method.Parameters, tt.params) {
+				t.Errorf("Expected parameters %+v, got %+v", tt.params, method.Parameters)
+			}
+		})
+	}
+
+	for _, methodName := range []string{"UpdateStatus", "UpdateByID", "UpdateNameByID", "UpdateStatusByIDOrderByAgeAsc"} {
+		if _, err := analyzer.AnalyzeMethod(methodName); err == nil {
+			t.Errorf("Expected %s to be rejected", methodName)
+		}
+	}
+}
+
+func TestAnalyzer_SelectedFields(t *testing.T) {
+	analyzer, err := NewAnalyzer(reflect.TypeOf(TestUser{}))
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+
+	tests := []struct {
+		methodName string
+		selected   []string
+		limit      int
+	}{
+		{"FindEmailByStatus", []string{"Email"}, 0},
+		{"FindEmailAndStatusByAgeGreaterThan", []string{"Email", "Status"}, 0},
+		{"FindFirstEmailByStatusOrderByAgeDesc", []string{"Email"}, 1},
+		{"FindByStatus", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.methodName, func(t *testing.T) {
+			method, err := analyzer.AnalyzeMethod(tt.methodName)
+			if err != nil {
+				t.Fatalf("Failed to analyze: %v", err)
+			}
+			if !reflect.DeepEqual(method.Selected, tt.selected) || method.Limit != tt.limit {
+				t.Errorf("Expected %v limited to %d, got %v limited to %d", tt.selected, tt.limit, method.Selected, method.Limit)
+			}
+		})
+	}
+
+	for _, methodName := range []string{"FindEmailAndByStatus", "FindNicknameByStatus", "FindEmail"} {
+		if _, err := analyzer.AnalyzeMethod(methodName); err == nil {
+			t.Errorf("Expected %s to be rejected", methodName)
+		}
+	}
+}
+
+// TestProduct has fields containing the keywords Or, And and In
+type TestProduct struct {
+	ID          int64
+	Brand       string
+	Order       int
+	OrderNumber string
+	Origin      string
+	Name        string
+	Index       int
+	Price       float64
+}
+
+func TestAnalyzer_Keywords(t *testing.T) {
+	analyzer, err := NewAnalyzer(reflect.TypeOf(TestProduct{}))
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+	fieldToColumn := func(fieldName string) string { return fieldName }
+
+	tests := []struct {
+		methodName string
+		expected   string
+	}{
+		{"FindByBrand", "SELECT * FROM products WHERE Brand = $1"},
+		{"FindByBrandOrOrigin", "SELECT * FROM products WHERE Brand = $1 OR Origin = $2"},
+		{"FindByOrderNumberAndOrder", "SELECT * FROM products WHERE OrderNumber = $1 AND Order = $2"},
+		{"FindByOriginOrderByOrderDesc", "SELECT * FROM products WHERE Origin = $1 ORDER BY Order DESC"},
+		{"FindByIndexIn", "SELECT * FROM products WHERE Index = ANY($1)"},
+		{"FindByBrandNot", "SELECT * FROM products WHERE Brand != $1"},
+		{"FindByBrandIsNotNull", "SELECT * FROM products WHERE Brand IS NOT NULL"},
+		{"FindByPriceIsGreaterThanEqual", "SELECT * FROM products WHERE Price >= $1"},
+		{"FindByNameContainsIgnoreCase", "SELECT * FROM products WHERE LOWER(Name) LIKE LOWER($1)"},
+		{"FindByNameStartingWithIgnoreCaseAndPriceLessThan", "SELECT * FROM products WHERE LOWER(Name) LIKE LOWER($1) AND Price < $2"},
+		{"FindByBrandAndNameNotAllIgnoreCase", "SELECT * FROM products WHERE LOWER(Brand) = LOWER($1) AND LOWER(Name) != LOWER($2)"},
+		{"FindByBrandAndPriceAllIgnoreCase", "SELECT * FROM products WHERE LOWER(Brand) = LOWER($1) AND Price = $2"},
+		{"FindDistinctByBrand", "SELECT DISTINCT * FROM products WHERE Brand = $1"},
+		{"FindAllByOrigin", "SELECT * FROM products WHERE Origin = $1"},
+		{"FindTop3ByBrandOrderByPriceDescName", "SELECT * FROM products WHERE Brand = $1 ORDER BY Price DESC, Name ASC LIMIT 3"},
+		{"FindFirst10OrderByOrder", "SELECT * FROM products ORDER BY Order ASC LIMIT 10"},
+		{"FindTopByOrderByPriceAsc", "SELECT * FROM products ORDER BY Price ASC LIMIT 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.methodName, func(t *testing.T) {
+			method, err := analyzer.AnalyzeMethod(tt.methodName)
+			if err != nil {
+				t.Fatalf("Failed to analyze: %v", err)
+			}
+			if sql := method.ToSQL("products", fieldToColumn); sql != tt.expected {
+				t.Errorf("Expected SQL '%s', got '%s'", tt.expected, sql)
+			}
+		})
+	}
+
+	for _, methodName := range []string{
+		"FindByBrandOr",                    // dangling Or
+		"FindByColour",                     // no such field
+		"FindByPriceGreaterThanIgnoreCase", // IgnoreCase on a number
+		"FindByBrandSomething",             // unparsed rest
+		"FindTop0ByBrand",
+		"FindByBrandOrderBy",
+	} {
+		if _, err := analyzer.AnalyzeMethod(methodName); err == nil {
+			t.Errorf("Expected %s to be rejected", methodName)
+		}
+	}
+
+	method, _ := analyzer.AnalyzeMethod("FindTop1ByBrand")
+	if method.ReturnType != ReturnSingle {
+		t.Errorf("Expected Top1 to return a single entity")
+	}
+}
+
+func TestAnalyzer_MethodNameErrors(t *testing.T) {
+	analyzer, err := NewAnalyzer(reflect.TypeOf(TestUser{}))
+	if err != nil {
+		t.Fatalf("Failed to create analyzer: %v", err)
+	}
+
+	tests := []struct {
+		methodName  string
+		token       string
+		reason      string
+		suggestions []string
+	}{
+		{"FindByEmial", "Emial", "unknown field", []string{"Email"}},
+		{"FindByEmialContainingAndAge", "Emial", "unknown field", []string{"Email"}},
+		{"FindByAgeGreaterThn", "GreaterThn", "unsupported keyword after Age", []string{"GreaterThan", "IsGreaterThan"}},
+		{"FindByEmailOrderByUsrnameDesc", "UsrnameDesc", "unknown OrderBy field", []string{"Username"}},
+		{"FetchByEmail", "Fetch", "unsupported method prefix", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.methodName, func(t *testing.T) {
+			_, err := analyzer.AnalyzeMethod(tt.methodName)
+			var nameErr *MethodNameError
+			if !errors.As(err, &nameErr) {
+				t.Fatalf("Expected a MethodNameError, got %v", err)
+			}
+			if nameErr.Method != tt.methodName || nameErr.Token != tt.token || nameErr.Reason != tt.reason {
+				t.Errorf("Expected %s, %q, %s, got %s, %q, %s", tt.methodName, tt.token, tt.reason,
+					nameErr.Method, nameErr.Token, nameErr.Reason)
+			}
+			if !reflect.DeepEqual(nameErr.Suggestions, tt.suggestions) {
+				t.Errorf("Expected suggestions %v, got %v", tt.suggestions, nameErr.Suggestions)
+			}
+		})
+	}
+
+	_, err = analyzer.AnalyzeMethod("FindByEmial")
+	if want := `FindByEmial: unknown field "Emial" (did you mean Email?)`; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/satishbabariya/jetorm/generator"
 )
 
 // Command represents a CLI command
@@ -23,6 +31,21 @@ var commands = []Command{
 		Description: "Generate repository code",
 		Execute:     cmdGenerate,
 	},
+	{
+		Name:        "scan",
+		Description: "Generate the marked interfaces of package directories",
+		Execute:     cmdScan,
+	},
+	{
+		Name:        "mapper",
+		Description: "Generate entity/model mapping functions",
+		Execute:     cmdMapper,
+	},
+	{
+		Name:        "introspect",
+		Description: "Generate entity structs from an existing database",
+		Execute:     cmdIntrospect,
+	},
 	{
 		Name:        "validate",
 		Description: "Validate configuration",
@@ -40,6 +63,19 @@ func cmdInit(args []string) error {
 	return initConfigFile(configPath)
 }
 
+// cmdScan generates every marked repository interface of the package
+// directories given, or of the current one
+func cmdScan(args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	files, err := generator.GeneratePackage(args...)
+	for _, file := range files {
+		fmt.Printf("Successfully generated: %s\n", file)
+	}
+	return err
+}
+
 // cmdGenerate generates code
 func cmdGenerate(args []string) error {
 	cfg, err := parseConfig()
@@ -47,9 +83,66 @@ func cmdGenerate(args []string) error {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Generate code (implementation from main.go)
-	// This is a simplified version
-	fmt.Printf("Generating code for %s...\n", cfg.EntityType)
+	files, err := generator.Generate(cfg)
+	for _, file := range files {
+		fmt.Printf("Successfully generated: %s\n", file)
+	}
+	return err
+}
+
+// cmdMapper generates mapping functions between an entity and an API model
+func cmdMapper(args []string) error {
+	fs := flag.NewFlagSet("mapper", flag.ContinueOnError)
+	cfg := generator.MapperConfig{}
+	fs.StringVar(&cfg.Dir, "dir", ".", "Package directory declaring both structs")
+	fs.StringVar(&cfg.Entity, "entity", "", "Entity struct name")
+	fs.StringVar(&cfg.Model, "model", "", "API model struct name")
+	fs.StringVar(&cfg.OutputFile, "output", "", "Output file path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	file, err := generator.GenerateMappers(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Successfully generated: %s\n", file)
+	return nil
+}
+
+// cmdIntrospect generates entity structs from the tables of a database
+func cmdIntrospect(args []string) error {
+	fs := flag.NewFlagSet("introspect", flag.ContinueOnError)
+	cfg := generator.IntrospectConfig{}
+	var databaseURL, tables string
+	fs.StringVar(&databaseURL, "db", os.Getenv("DATABASE_URL"), "Database URL (default $DATABASE_URL)")
+	fs.StringVar(&cfg.Schema, "schema", "public", "Schema to read")
+	fs.StringVar(&tables, "tables", "", "Comma-separated tables to generate (default all)")
+	fs.StringVar(&cfg.Package, "package", "", "Package name (default the output directory's name)")
+	fs.StringVar(&cfg.OutputFile, "output", "entities.go", "Output file path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if databaseURL == "" {
+		return fmt.Errorf("database URL is required: use -db or DATABASE_URL")
+	}
+	if tables != "" {
+		for _, table := range strings.Split(tables, ",") {
+			cfg.Tables = append(cfg.Tables, strings.TrimSpace(table))
+		}
+	}
+
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	file, err := generator.GenerateEntities(context.Background(), db, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Successfully generated: %s\n", file)
 	return nil
 }
 
@@ -71,6 +164,8 @@ func cmdValidate(args []string) error {
 // printUsage prints command usage
 func printUsage() {
 	fmt.Println("Usage: jetorm-gen [command] [options]")
+	fmt.Println("\nWithout arguments, generates every interface of the current package")
+	fmt.Println("marked //jetorm:repository, for use with //go:generate jetorm-gen.")
 	fmt.Println("\nCommands:")
 	for _, cmd := range commands {
 		fmt.Printf("  %-15s %s\n", cmd.Name, cmd.Description)
@@ -83,6 +178,21 @@ func printUsage() {
 	fmt.Println("  -output string     Output file path")
 	fmt.Println("  -comments          Generate documentation comments")
 	fmt.Println("  -tests             Generate test files")
+	fmt.Println("  -save-mode string  Save mode: auto, always_insert or always_update")
+	fmt.Println("  -migrations string Migrations directory whose latest version the code requires")
+	fmt.Println("  -mocks             Generate a mock of the repository interface")
+	fmt.Println("  -jet-tables string Directory of a Jet table package to write the entity's table to")
+	fmt.Println("\nMapper options (jetorm-gen mapper):")
+	fmt.Println("  -dir string        Package directory declaring both structs")
+	fmt.Println("  -entity string     Entity struct name")
+	fmt.Println("  -model string      API model struct name")
+	fmt.Println("  -output string     Output file path")
+	fmt.Println("\nIntrospect options (jetorm-gen introspect):")
+	fmt.Println("  -db string         Database URL (default $DATABASE_URL)")
+	fmt.Println("  -schema string     Schema to read (default public)")
+	fmt.Println("  -tables string     Comma-separated tables to generate (default all)")
+	fmt.Println("  -package string    Package name (default the output directory's name)")
+	fmt.Println("  -output string     Output file path (default entities.go)")
 }
 
 // executeCommand executes a command
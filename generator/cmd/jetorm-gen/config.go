@@ -18,6 +18,10 @@ func parseConfig() (*generator.Config, error) {
 		interfaceName = flag.String("interface", "", "Repository interface name")
 		generateComments = flag.Bool("comments", true, "Generate documentation comments")
 		generateTests = flag.Bool("tests", false, "Generate test files")
+		generateMocks = flag.Bool("mocks", false, "Generate a mock of the repository interface")
+		jetTablesDir = flag.String("jet-tables", "", "Directory of a Jet table package to write the entity's table to")
+		saveMode     = flag.String("save-mode", "", "Save mode: auto, always_insert or always_update")
+		migrationsDir = flag.String("migrations", "", "Migrations directory whose latest version the code requires")
 	)
 	flag.Parse()
 
@@ -50,9 +54,19 @@ func parseConfig() (*generator.Config, error) {
 	if *interfaceName != "" {
 		cfg.InterfaceName = *interfaceName
 	}
+	if *saveMode != "" {
+		cfg.SaveMode = *saveMode
+	}
+	if *migrationsDir != "" {
+		cfg.MigrationsDir = *migrationsDir
+	}
+	if *jetTablesDir != "" {
+		cfg.JetTablesDir = *jetTablesDir
+	}
 	if flag.NFlag() > 0 {
 		cfg.GenerateComments = *generateComments
 		cfg.GenerateTests = *generateTests
+		cfg.GenerateMocks = *generateMocks
 	}
 
 	// Validate configuration
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"go/format"
 	"reflect"
+	"regexp"
 	"strings"
 	"text/template"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
 // CodeGenerator generates repository implementation code
@@ -14,6 +17,22 @@ type CodeGenerator struct {
 	entityType reflect.Type
 	tableName  string
 	fieldToColumn map[string]string
+	softDelete string   // soft-delete column finders exclude, if any
+	discriminator string   // condition selecting the entity's rows of a shared table, if any
+	columns    []string // columns finders read when the table is shared, * otherwise
+	queries    []string // SQL of the generated methods, in generation order
+	repositoryName string // receiver type of the methods, <Entity>Repository when empty
+	loader     *TypeLoader // resolves the DTOs finders return, if any
+}
+
+// SetRepositoryName sets the name of the struct the generated methods belong to
+func (g *CodeGenerator) SetRepositoryName(name string) {
+	g.repositoryName = name
+}
+
+// SetTypeLoader sets the loader resolving the DTO types finders return
+func (g *CodeGenerator) SetTypeLoader(loader *TypeLoader) {
+	g.loader = loader
 }
 
 // NewCodeGenerator creates a new code generator
@@ -51,6 +70,233 @@ func NewCodeGenerator(entityType reflect.Type) (*CodeGenerator, error) {
 	}, nil
 }
 
+// NewCodeGeneratorForType creates a code generator for an entity loaded by a
+// TypeLoader
+func NewCodeGeneratorForType(info *EntityTypeInfo) *CodeGenerator {
+	fieldToColumn := make(map[string]string, len(info.Fields))
+	softDelete := ""
+	var columns []string
+	for _, field := range info.Fields {
+		fieldToColumn[field.Name] = field.DBName
+		if _, ok := field.Tags["soft_delete"]; ok {
+			softDelete = field.DBName
+		}
+		columns = append(columns, field.DBName)
+	}
+	g := &CodeGenerator{
+		analyzer:      NewAnalyzerForType(info),
+		tableName:     info.TableName,
+		fieldToColumn: fieldToColumn,
+		softDelete:    softDelete,
+	}
+	if d := info.Discriminator; d != nil {
+		g.discriminator = fmt.Sprintf("%s = '%s'", d.Column, strings.ReplaceAll(d.Value, "'", "''"))
+		g.columns = columns
+	}
+	return g
+}
+
+// GenerateInterfaceMethod generates the implementation of a query method
+// declared on a repository interface. The method name is analyzed as usual;
+// the interface supplies the parameter names and the return type, which must
+// fit the operation: a pointer or slice of entity pointers for Find, int64
+// for Count, Delete and Update (or just error for Delete and Update) and
+// bool for Exists. A Find
+// method whose last parameter is a core.Pageable returns a *core.Page, and
+// one whose last parameter is a core.Sort is ordered by it. A Find method
+// returning a pointer or slice of pointers to another struct of the package
+// selects only the columns of that DTO's fields, or the fields its name
+// lists, as in FindEmailAndNameByStatus.
+func (g *CodeGenerator) GenerateInterfaceMethod(info MethodInfo, entityName string) (string, error) {
+	method, err := g.analyzer.AnalyzeMethod(info.Name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(info.Parameters) == 0 || info.Parameters[0].Type != "context.Context" {
+		return "", fmt.Errorf("%s: first parameter must be context.Context", info.Name)
+	}
+	params := info.Parameters[1:]
+	if n := len(params); n > 0 && (params[n-1].Type == "core.Pageable" || params[n-1].Type == "core.Sort") {
+		name := params[n-1].Name
+		if name == "" || name == "_" {
+			return "", fmt.Errorf("%s: parameter %d must be named", info.Name, n+1)
+		}
+		if params[n-1].Type == "core.Sort" {
+			method.Sort = name
+		} else {
+			method.Pageable = name
+		}
+		params = params[:n-1]
+	}
+	if len(params) != len(method.Parameters) {
+		expected := make([]string, len(method.Parameters))
+		for i, p := range method.Parameters {
+			expected[i] = p.Name + " " + p.Type
+		}
+		return "", fmt.Errorf("%s: expected %d parameters after ctx (%s), got %d",
+			info.Name, len(method.Parameters), strings.Join(expected, ", "), len(params))
+	}
+	for i, p := range params {
+		if p.Name == "" || p.Name == "_" {
+			return "", fmt.Errorf("%s: parameter %d must be named", info.Name, i+2)
+		}
+		method.Parameters[i] = Parameter{Name: p.Name, Type: p.Type}
+	}
+
+	method.ReturnType, err = interfaceReturnType(method, info.Returns, entityName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", info.Name, err)
+	}
+	if method.Projection != "" {
+		if method.Columns, err = g.projectionColumns(method); err != nil {
+			return "", fmt.Errorf("%s: %w", info.Name, err)
+		}
+	}
+
+	return g.GenerateMethod(method, entityName, "")
+}
+
+// interfaceReturnType maps the declared results of a method to the return
+// type of its operation
+func interfaceReturnType(method *QueryMethod, returns []ReturnInfo, entityName string) (ReturnType, error) {
+	results := make([]string, len(returns))
+	for i, ret := range returns {
+		results[i] = ret.Type
+	}
+	declared := strings.Join(results, ", ")
+
+	page := "*core.Page[" + entityName + "], error"
+	switch {
+	case method.Sort != "" && method.Operation != OpFind:
+		return 0, fmt.Errorf("only find methods take a core.Sort")
+	case method.Pageable != "" && method.Operation != OpFind:
+		return 0, fmt.Errorf("only find methods take a core.Pageable")
+	case method.Pageable != "" && method.Limit > 0:
+		return 0, fmt.Errorf("First and Top cannot be combined with a core.Pageable")
+	case method.Pageable != "" && declared != page:
+		return 0, fmt.Errorf("paged find methods must return (%s), not (%s)", page, declared)
+	case method.Pageable == "" && declared == page:
+		return 0, fmt.Errorf("find methods returning a page need a core.Pageable parameter")
+	}
+
+	switch method.Operation {
+	case OpFind:
+		if m := projectionReturn.FindStringSubmatch(declared); m != nil && m[2] != entityName {
+			if method.Pageable != "" || method.Sort != "" {
+				return 0, fmt.Errorf("find methods returning %s cannot take a core.Pageable or core.Sort", m[2])
+			}
+			method.Projection = m[2]
+			if m[1] == "*" {
+				return ReturnSingle, nil
+			}
+			return ReturnSlice, nil
+		}
+		if len(method.Selected) > 0 {
+			return 0, fmt.Errorf("find methods selecting %s must return a DTO, not (%s)", strings.Join(method.Selected, ", "), declared)
+		}
+		switch declared {
+		case "*" + entityName + ", error":
+			return ReturnSingle, nil
+		case "[]*" + entityName + ", error":
+			return ReturnSlice, nil
+		case page:
+			return ReturnPage, nil
+		}
+		return 0, fmt.Errorf("find methods must return (*%s, error), ([]*%s, error), (%s) or a DTO, not (%s)", entityName, entityName, page, declared)
+	case OpCount:
+		if declared == "int64, error" {
+			return ReturnInt64, nil
+		}
+		return 0, fmt.Errorf("count methods must return (int64, error), not (%s)", declared)
+	case OpExists:
+		if declared == "bool, error" {
+			return ReturnBool, nil
+		}
+		return 0, fmt.Errorf("exists methods must return (bool, error), not (%s)", declared)
+	case OpUpdate:
+		switch declared {
+		case "int64, error":
+			return ReturnInt64, nil
+		case "error":
+			return ReturnError, nil
+		}
+		return 0, fmt.Errorf("update methods must return (int64, error) or error, not (%s)", declared)
+	default:
+		switch declared {
+		case "int64, error":
+			return ReturnInt64, nil
+		case "error":
+			return ReturnError, nil
+		}
+		return 0, fmt.Errorf("delete methods must return (int64, error) or error, not (%s)", declared)
+	}
+}
+
+// projectionReturn matches the results of a finder returning a DTO of the
+// package: its pointer or slice of pointers and the type name
+var projectionReturn = regexp.MustCompile(`^(\*|\[\]\*)([A-Za-z_]\w*), error$`)
+
+// projectionColumns returns the select list of a finder returning a DTO.
+// Each DTO field reads the entity column of its own column name, or that of
+// the entity field of the same name, aliased to its column name. A finder
+// naming the fields it selects must name exactly the DTO's fields.
+func (g *CodeGenerator) projectionColumns(method *QueryMethod) ([]string, error) {
+	if g.loader == nil {
+		return nil, fmt.Errorf("DTO %s cannot be resolved", method.Projection)
+	}
+	dto, err := g.loader.LoadEntityType(method.Projection)
+	if err != nil {
+		return nil, err
+	}
+
+	columnToField := make(map[string]string, len(g.fieldToColumn))
+	for field, column := range g.fieldToColumn {
+		columnToField[column] = field
+	}
+	var read []string // Entity field each DTO field reads, in order
+	expressions := make(map[string]string, len(dto.Fields))
+	for _, f := range dto.Fields {
+		field, ok := columnToField[f.DBName]
+		expression := f.DBName
+		if !ok {
+			column, found := g.fieldToColumn[f.Name]
+			if !found {
+				return nil, fmt.Errorf("field %s of %s matches no column of the entity", f.Name, method.Projection)
+			}
+			field, expression = f.Name, column+" AS "+f.DBName
+		}
+		if _, dup := expressions[field]; dup {
+			return nil, fmt.Errorf("fields of %s read the column of %s twice", method.Projection, field)
+		}
+		read = append(read, field)
+		expressions[field] = expression
+	}
+	if len(read) == 0 {
+		return nil, fmt.Errorf("DTO %s has no fields", method.Projection)
+	}
+
+	selected := method.Selected
+	if len(selected) == 0 {
+		selected = read
+	}
+	var columns []string
+	for _, field := range selected {
+		expression, ok := expressions[field]
+		if !ok {
+			return nil, fmt.Errorf("%s has no field for the selected %s", method.Projection, field)
+		}
+		columns = append(columns, expression)
+		delete(expressions, field)
+	}
+	for _, field := range read {
+		if _, ok := expressions[field]; ok {
+			return nil, fmt.Errorf("%s reads %s, which is not selected", method.Projection, field)
+		}
+	}
+	return columns, nil
+}
+
 // GenerateMethod generates code for a single query method
 func (g *CodeGenerator) GenerateMethod(method *QueryMethod, entityName string, idType string) (string, error) {
 	tmpl := `func (r *{{.RepositoryName}}) {{.MethodName}}(ctx context.Context{{.Params}}) {{.Returns}} {
@@ -63,30 +309,47 @@ func (g *CodeGenerator) GenerateMethod(method *QueryMethod, entityName string, i
 	for _, param := range method.Parameters {
 		params = append(params, fmt.Sprintf("%s %s", param.Name, param.Type))
 	}
+	if method.Pageable != "" {
+		params = append(params, method.Pageable+" core.Pageable")
+	}
+	if method.Sort != "" {
+		params = append(params, method.Sort+" core.Sort")
+	}
 	paramsStr := ""
 	if len(params) > 0 {
 		paramsStr = ", " + strings.Join(params, ", ")
 	}
 
 	// Build returns string
+	resultName := entityName
+	if method.Projection != "" {
+		resultName = method.Projection
+	}
 	var returns []string
 	switch method.ReturnType {
 	case ReturnSingle:
-		returns = []string{fmt.Sprintf("*%s", entityName), "error"}
+		returns = []string{fmt.Sprintf("*%s", resultName), "error"}
 	case ReturnSlice:
-		returns = []string{fmt.Sprintf("[]*%s", entityName), "error"}
+		returns = []string{fmt.Sprintf("[]*%s", resultName), "error"}
 	case ReturnInt64:
 		returns = []string{"int64", "error"}
 	case ReturnBool:
 		returns = []string{"bool", "error"}
+	case ReturnError:
+		returns = []string{"error"}
+	case ReturnPage:
+		returns = []string{fmt.Sprintf("*core.Page[%s]", entityName), "error"}
+	}
+	returnsStr := strings.Join(returns, ", ")
+	if len(returns) > 1 {
+		returnsStr = "(" + returnsStr + ")"
 	}
-	returnsStr := "(" + strings.Join(returns, ", ") + ")"
 
 	// Generate method body
 	body := g.generateMethodBody(method, entityName)
 
 	data := map[string]interface{}{
-		"RepositoryName": fmt.Sprintf("%sRepository", entityName),
+		"RepositoryName": g.receiverName(entityName),
 		"MethodName":     method.Name,
 		"Params":         paramsStr,
 		"Returns":        returnsStr,
@@ -112,6 +375,25 @@ func (g *CodeGenerator) GenerateMethod(method *QueryMethod, entityName string, i
 	return string(formatted), nil
 }
 
+// scoped adds the conditions the repository applies to every read, for the
+// soft delete column and the discriminator of a shared table, to a WHERE clause
+func (g *CodeGenerator) scoped(where string) string {
+	var conditions []string
+	if g.softDelete != "" {
+		conditions = append(conditions, g.softDelete+" IS NULL")
+	}
+	if g.discriminator != "" {
+		conditions = append(conditions, g.discriminator)
+	}
+	switch {
+	case len(conditions) == 0:
+		return where
+	case where != "":
+		return fmt.Sprintf("(%s) AND %s", where, strings.Join(conditions, " AND "))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
 // generateMethodBody generates the body of a query method
 func (g *CodeGenerator) generateMethodBody(method *QueryMethod, entityName string) string {
 	var body strings.Builder
@@ -138,9 +420,20 @@ func (g *CodeGenerator) generateMethodBody(method *QueryMethod, entityName strin
 	var query string
 	switch method.Operation {
 	case OpFind:
-		query = fmt.Sprintf("SELECT * FROM %s", g.tableName)
-		if wherePart != "" {
-			query += " WHERE " + wherePart
+		// Scope finders like the repository does; specifications are scoped at runtime
+		selectList := "*"
+		switch {
+		case len(method.Columns) > 0:
+			selectList = strings.Join(method.Columns, ", ")
+		case len(g.columns) > 0:
+			selectList = strings.Join(g.columns, ", ")
+		}
+		if method.Distinct {
+			selectList = "DISTINCT " + selectList
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s", selectList, g.tableName)
+		if where := g.scoped(wherePart); where != "" {
+			query += " WHERE " + where
 		}
 		if len(method.SortFields) > 0 {
 			orderClauses := make([]string, len(method.SortFields))
@@ -168,134 +461,185 @@ func (g *CodeGenerator) generateMethodBody(method *QueryMethod, entityName strin
 		if wherePart != "" {
 			query += " WHERE " + wherePart
 		}
-	}
-
-	// Build args list for logging and query execution
-	argsList := make([]string, 0)
-	for _, field := range method.Fields {
-		switch field.Operator {
-		case OpBetween:
-			argsList = append(argsList, fmt.Sprintf("min%s", field.FieldName))
-			argsList = append(argsList, fmt.Sprintf("max%s", field.FieldName))
-		case OpIn, OpNotIn:
-			argsList = append(argsList, fmt.Sprintf("%ss", strings.ToLower(field.FieldName)))
-		case OpIsNull, OpIsNotNull, OpTrue, OpFalse:
-			// No arguments
-		case OpContaining:
-			paramName := strings.ToLower(field.FieldName)
-			argsList = append(argsList, fmt.Sprintf(`fmt.Sprintf("%%s%%", %s)`, paramName))
-		case OpStartingWith:
-			paramName := strings.ToLower(field.FieldName)
-			argsList = append(argsList, fmt.Sprintf(`fmt.Sprintf("%%s%%", %s)`, paramName))
-		case OpEndingWith:
-			paramName := strings.ToLower(field.FieldName)
-			argsList = append(argsList, fmt.Sprintf(`fmt.Sprintf("%%s%%", %s)`, paramName))
-		default:
-			argsList = append(argsList, strings.ToLower(field.FieldName))
+	case OpUpdate:
+		// The values are bound ahead of the conditions
+		sets := make([]string, len(method.Assignments))
+		for i, assignment := range method.Assignments {
+			sets[i] = fmt.Sprintf("%s = $%d", g.fieldToColumn[assignment.FieldName], i+1)
 		}
+		query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", g.tableName, strings.Join(sets, ", "),
+			core.RenumberPlaceholders(wherePart, len(sets)+1))
 	}
 
-	argsStr := ""
-	if len(argsList) > 0 {
-		argsStr = ", " + strings.Join(argsList, ", ")
+	args := ""
+	if argsList := queryArgs(method); len(argsList) > 0 {
+		args = ", " + strings.Join(argsList, ", ")
 	}
 
-	// Generate body based on operation and return type
+	// Finders run the SQL as written, so it is allowlisted; counts, existence
+	// checks, deletes and updates go through specifications, which apply
+	// soft-delete scoping
+	spec := "nil"
+	if wherePart != "" {
+		spec = fmt.Sprintf("core.Where[%s](%q%s)", entityName, wherePart, args)
+	}
 	switch method.Operation {
 	case OpFind:
-		if method.ReturnType == ReturnSingle {
-			body.WriteString(fmt.Sprintf(`query := %q
-	r.logQuery(query, []interface{}{%s})
-
-	var row pgx.Row
-	if r.tx != nil {
-		row = r.tx.tx.QueryRow(ctx, query%s)
-	} else {
-		row = r.db.pool.QueryRow(ctx, query%s)
-	}
-
-	result := new(%s)
-	if err := r.scanRow(row, result); err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, ErrNotFound
+		if method.Pageable != "" {
+			fmt.Fprintf(&body, "// %s, paged by %s\n\t", query, method.Pageable)
+			g.writePagedFind(&body, method, entityName, spec)
+			break
+		}
+		if method.Sort != "" {
+			fmt.Fprintf(&body, "// %s, sorted by %s\n\t", query, method.Sort)
+			g.writeSortedFind(&body, method, spec)
+			break
+		}
+		g.queries = append(g.queries, query)
+		switch {
+		case method.Projection != "" && method.ReturnType == ReturnSingle:
+			fmt.Fprintf(&body, "return core.QueryOneInto[%s](ctx, r.BaseRepository, %q%s)", method.Projection, query, args)
+		case method.Projection != "":
+			fmt.Fprintf(&body, "return core.QueryInto[%s](ctx, r.BaseRepository, %q%s)", method.Projection, query, args)
+		case method.ReturnType == ReturnSingle:
+			fmt.Fprintf(&body, "return r.QueryOne(ctx, %q%s)", query, args)
+		default:
+			fmt.Fprintf(&body, "return r.Query(ctx, %q%s)", query, args)
+		}
+	case OpCount:
+		fmt.Fprintf(&body, "// %s\n\treturn r.CountWithSpec(ctx, %s)", query, spec)
+	case OpExists:
+		fmt.Fprintf(&body, "// %s\n\treturn r.ExistsWithSpec(ctx, %s)", query, spec)
+	case OpDelete:
+		if method.ReturnType == ReturnError {
+			fmt.Fprintf(&body, "// %s\n\t_, err := r.DeleteWithSpec(ctx, %s)\n\treturn err", query, spec)
+		} else {
+			fmt.Fprintf(&body, "// %s\n\treturn r.DeleteWithSpec(ctx, %s)", query, spec)
+		}
+	case OpUpdate:
+		fields := g.assignedFields(method)
+		if method.ReturnType == ReturnError {
+			fmt.Fprintf(&body, "// %s\n\t_, err := r.UpdateWithSpec(ctx, %s, %s)\n\treturn err", query, fields, spec)
+		} else {
+			fmt.Fprintf(&body, "// %s\n\treturn r.UpdateWithSpec(ctx, %s, %s)", query, fields, spec)
 		}
-		return nil, err
 	}
 
-	return result, nil`, query, strings.Join(argsList, ", "), argsStr, argsStr, entityName))
-		} else {
-			body.WriteString(fmt.Sprintf(`query := %q
-	r.logQuery(query, []interface{}{%s})
+	return body.String()
+}
 
-	var rows pgx.Rows
-	var err error
-	if r.tx != nil {
-		rows, err = r.tx.tx.Query(ctx, query%s)
-	} else {
-		rows, err = r.db.pool.Query(ctx, query%s)
+// writePagedFind writes the body of a finder taking a core.Pageable. Pages
+// are read through a specification, so the pageable supplies the ORDER BY,
+// LIMIT and OFFSET and the total is counted as its CountMode asks. The order
+// in the method name applies when the pageable has no sort.
+func (g *CodeGenerator) writePagedFind(body *strings.Builder, method *QueryMethod, entityName, spec string) {
+	pageable := method.Pageable
+	if order := g.nameOrder(method); order != "" {
+		fmt.Fprintf(body, "if len(%s.Sort.Orders) == 0 {\n\t\t%s.Sort = %s\n\t}\n\t", pageable, pageable, order)
 	}
+	fmt.Fprintf(body, "return r.FindAllPagedWithSpec(ctx, %s, %s)", spec, pageable)
+}
 
-	if err != nil {
-		return nil, err
+// writeSortedFind writes the body of a finder taking a core.Sort. The rows
+// are read as one uncounted page, so the sort is checked against the entity's
+// fields and appended as ORDER BY at runtime. The order in the method name
+// applies when the sort is empty.
+func (g *CodeGenerator) writeSortedFind(body *strings.Builder, method *QueryMethod, spec string) {
+	sort := method.Sort
+	if order := g.nameOrder(method); order != "" {
+		fmt.Fprintf(body, "if len(%s.Orders) == 0 {\n\t\t%s = %s\n\t}\n\t", sort, sort, order)
 	}
-	defer rows.Close()
 
-	return r.scanRows(rows)`, query, strings.Join(argsList, ", "), argsStr, argsStr))
-		}
-	case OpCount:
-		body.WriteString(fmt.Sprintf(`query := %q
-	r.logQuery(query, []interface{}{%s})
-
-	var count int64
-	var err error
-	if r.tx != nil {
-		err = r.tx.tx.QueryRow(ctx, query%s).Scan(&count)
+	size := ""
+	switch {
+	case method.ReturnType == ReturnSingle:
+		size = "Size: 1, "
+	case method.Limit > 0:
+		size = fmt.Sprintf("Size: %d, ", method.Limit)
+	}
+	fmt.Fprintf(body, "page, err := r.FindAllPagedWithSpec(ctx, %s, core.Pageable{%sSort: %s, CountMode: core.CountNone})\n", spec, size, sort)
+	body.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	if method.ReturnType == ReturnSingle {
+		body.WriteString("\tif len(page.Content) == 0 {\n\t\treturn nil, core.ErrNotFound\n\t}\n\treturn page.Content[0], nil")
 	} else {
-		err = r.db.pool.QueryRow(ctx, query%s).Scan(&count)
+		body.WriteString("\treturn page.Content, nil")
 	}
+}
 
-	if err != nil {
-		return 0, err
+// nameOrder returns a core.Sort literal of the OrderBy in a method's name,
+// or "" when it has none
+func (g *CodeGenerator) nameOrder(method *QueryMethod) string {
+	if len(method.SortFields) == 0 {
+		return ""
 	}
-
-	return count, nil`, query, strings.Join(argsList, ", "), argsStr, argsStr))
-	case OpExists:
-		body.WriteString(fmt.Sprintf(`query := %q
-	r.logQuery(query, []interface{}{%s})
-
-	var exists bool
-	var err error
-	if r.tx != nil {
-		err = r.tx.tx.QueryRow(ctx, query%s).Scan(&exists)
-	} else {
-		err = r.db.pool.QueryRow(ctx, query%s).Scan(&exists)
+	orders := make([]string, len(method.SortFields))
+	for i, sf := range method.SortFields {
+		direction := "core.Asc"
+		if sf.Direction == "DESC" {
+			direction = "core.Desc"
+		}
+		orders[i] = fmt.Sprintf("{Field: %q, Direction: %s}", g.fieldToColumn[sf.FieldName], direction)
 	}
+	return fmt.Sprintf("core.Sort{Orders: []core.Order{%s}}", strings.Join(orders, ", "))
+}
 
-	if err != nil {
-		return false, err
+// assignedFields returns a map literal of the columns an update method sets,
+// taking the parameters that follow those of its conditions
+func (g *CodeGenerator) assignedFields(method *QueryMethod) string {
+	next := len(method.Parameters)
+	for _, assignment := range method.Assignments {
+		if assignment.Value == "" {
+			next--
+		}
 	}
 
-	return exists, nil`, query, strings.Join(argsList, ", "), argsStr, argsStr))
-	case OpDelete:
-		body.WriteString(fmt.Sprintf(`query := %q
-	r.logQuery(query, []interface{}{%s})
-
-	var result pgconn.CommandTag
-	var err error
-	if r.tx != nil {
-		result, err = r.tx.tx.Exec(ctx, query%s)
-	} else {
-		result, err = r.db.pool.Exec(ctx, query%s)
+	entries := make([]string, len(method.Assignments))
+	for i, assignment := range method.Assignments {
+		value := assignment.Value
+		if value == "" {
+			value = method.Parameters[next].Name
+			next++
+		}
+		entries[i] = fmt.Sprintf("%q: %s", g.fieldToColumn[assignment.FieldName], value)
 	}
+	return fmt.Sprintf("map[string]interface{}{%s}", strings.Join(entries, ", "))
+}
 
-	if err != nil {
-		return 0, err
+// queryArgs lists the expressions bound to the placeholders of a method's
+// conditions, taking the method parameters in order
+func queryArgs(method *QueryMethod) []string {
+	var args []string
+	next := 0
+	param := func() string {
+		name := method.Parameters[next].Name
+		next++
+		return name
 	}
-
-	return result.RowsAffected(), nil`, query, strings.Join(argsList, ", "), argsStr, argsStr))
+	for _, field := range method.Fields {
+		switch field.Operator {
+		case OpIsNull, OpIsNotNull, OpTrue, OpFalse:
+			// No arguments
+		case OpBetween:
+			args = append(args, param(), param())
+		case OpContaining:
+			args = append(args, `"%" + `+param()+` + "%"`)
+		case OpStartingWith:
+			args = append(args, param()+` + "%"`)
+		case OpEndingWith:
+			args = append(args, `"%" + `+param())
+		default:
+			args = append(args, param())
+		}
 	}
+	return args
+}
 
-	return body.String()
+// receiverName returns the struct the generated methods are declared on
+func (g *CodeGenerator) receiverName(entityName string) string {
+	if g.repositoryName != "" {
+		return g.repositoryName
+	}
+	return entityName + "Repository"
 }
 
 // toSnakeCase converts a string to snake_case
@@ -314,3 +658,26 @@ func toSnakeCase(s string) string {
 	return result.String()
 }
 
+
+// AllowlistCode emits an init function registering the fingerprints of the
+// queries generated so far with core.AllowQueries, so that they keep running
+// when Config.RestrictRawSQL is set. It returns "" when no query was generated.
+func (g *CodeGenerator) AllowlistCode() string {
+	if len(g.queries) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	buf.WriteString("func init() {\n\tcore.AllowQueries(\n")
+	seen := make(map[string]bool)
+	for _, query := range g.queries {
+		fingerprint := core.QueryFingerprint(query)
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+		fmt.Fprintf(&buf, "\t\t%q, // %s\n", fingerprint, strings.Join(strings.Fields(query), " "))
+	}
+	buf.WriteString("\t)\n}\n")
+	return buf.String()
+}
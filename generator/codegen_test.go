@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
 func TestCodeGenerator_GenerateMethod(t *testing.T) {
@@ -75,5 +77,26 @@ func TestCodeGenerator_GenerateMethod(t *testing.T) {
 			t.Error("Generated code should have correct return type for Count")
 		}
 	})
+
+	t.Run("allowlist the generated queries", func(t *testing.T) {
+		analyzer, _ := NewAnalyzer(entityType)
+		method, err := analyzer.AnalyzeMethod("FindByEmail")
+		if err != nil {
+			t.Fatalf("Failed to analyze method: %v", err)
+		}
+		gen, _ := NewCodeGenerator(entityType)
+		if gen.AllowlistCode() != "" {
+			t.Error("Expected no allowlist before generating methods")
+		}
+		if _, err := gen.GenerateMethod(method, "User", "int64"); err != nil {
+			t.Fatalf("Failed to generate method: %v", err)
+		}
+
+		code := gen.AllowlistCode()
+		fingerprint := core.QueryFingerprint(gen.queries[0])
+		if !strings.Contains(code, "core.AllowQueries(") || !strings.Contains(code, `"`+fingerprint+`", // SELECT * FROM`) {
+			t.Errorf("Expected the query fingerprint to be allowlisted, got:\n%s", code)
+		}
+	})
 }
 
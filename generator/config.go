@@ -2,6 +2,7 @@ package generator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,25 +11,40 @@ import (
 // Config represents the configuration for code generation
 type Config struct {
 	// Entity configuration
-	EntityType    string `json:"entity_type"`
-	EntityPackage string `json:"entity_package,omitempty"`
+	EntityType    string `json:"entity_type" yaml:"entity_type"`
+	EntityPackage string `json:"entity_package,omitempty" yaml:"entity_package,omitempty"`
 	
 	// Interface configuration
-	InterfaceName string `json:"interface_name"`
+	InterfaceName string `json:"interface_name" yaml:"interface_name"`
 	
 	// Output configuration
-	OutputFile    string `json:"output_file"`
-	OutputPackage string `json:"output_package,omitempty"`
+	OutputFile    string `json:"output_file" yaml:"output_file"`
+	RepositoryName string `json:"repository_name,omitempty" yaml:"repository_name,omitempty"` // Generated struct, default <Entity>Repository
+	OutputPackage string `json:"output_package,omitempty" yaml:"output_package,omitempty"`
 	
 	// Input configuration
-	InputFile string `json:"input_file"`
+	InputFile string `json:"input_file" yaml:"input_file"`
 	
 	// Generation options
-	GenerateComments bool `json:"generate_comments,omitempty"`
-	GenerateTests    bool `json:"generate_tests,omitempty"`
+	GenerateComments bool `json:"generate_comments,omitempty" yaml:"generate_comments,omitempty"`
+	GenerateTests    bool `json:"generate_tests,omitempty" yaml:"generate_tests,omitempty"`
+	GenerateMocks    bool `json:"generate_mocks,omitempty" yaml:"generate_mocks,omitempty"` // Mock<Interface> in <output>_mock.go
 	
-	// ID type (if not auto-detected)
-	IDType string `json:"id_type,omitempty"`
+	// Directory of a Jet table package to write the entity's table to, e.g. ./table
+	JetTablesDir string `json:"jet_tables_dir,omitempty" yaml:"jet_tables_dir,omitempty"`
+	
+	// ID type; detected from the entity's primary_key field when empty
+	IDType string `json:"id_type,omitempty" yaml:"id_type,omitempty"`
+	
+	// Save mode: auto (default), always_insert or always_update
+	SaveMode string `json:"save_mode,omitempty" yaml:"save_mode,omitempty"`
+	
+	// Migrations directory whose latest version the generated code requires
+	MigrationsDir string `json:"migrations_dir,omitempty" yaml:"migrations_dir,omitempty"`
+	
+	// Repositories to generate in one pass instead of the single one above;
+	// see Expand for the settings they inherit
+	Repositories []Config `json:"repositories,omitempty" yaml:"repositories,omitempty"`
 }
 
 // LoadConfig loads configuration from a file
@@ -64,8 +80,52 @@ func (c *Config) SaveConfig(configPath string) error {
 	return nil
 }
 
+// Expand returns the config of each repository to generate: c itself, or
+// one per entry of Repositories. Entries take the entity package, input file,
+// output package, ID type, save mode, migrations directory and Jet table
+// directory from c where they leave them empty, and the comment, test and
+// mock options always.
+func (c *Config) Expand() []*Config {
+	if len(c.Repositories) == 0 {
+		return []*Config{c}
+	}
+
+	configs := make([]*Config, len(c.Repositories))
+	for i := range c.Repositories {
+		repo := c.Repositories[i]
+		for target, value := range map[*string]string{
+			&repo.EntityPackage: c.EntityPackage,
+			&repo.InputFile:     c.InputFile,
+			&repo.OutputPackage: c.OutputPackage,
+			&repo.IDType:        c.IDType,
+			&repo.SaveMode:      c.SaveMode,
+			&repo.MigrationsDir: c.MigrationsDir,
+			&repo.JetTablesDir:  c.JetTablesDir,
+		} {
+			if *target == "" {
+				*target = value
+			}
+		}
+		repo.GenerateComments = c.GenerateComments
+		repo.GenerateTests = c.GenerateTests
+		repo.GenerateMocks = c.GenerateMocks
+		repo.Repositories = nil
+		configs[i] = &repo
+	}
+	return configs
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
+	if len(c.Repositories) > 0 {
+		var errs []error
+		for _, repo := range c.Expand() {
+			if err := repo.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", repo.InterfaceName, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
 	if c.EntityType == "" {
 		return fmt.Errorf("entity_type is required")
 	}
@@ -78,15 +138,39 @@ func (c *Config) Validate() error {
 	if c.InputFile == "" {
 		return fmt.Errorf("input_file is required")
 	}
+	if _, ok := saveModes[c.SaveMode]; !ok {
+		return fmt.Errorf("save_mode must be auto, always_insert or always_update, got %q", c.SaveMode)
+	}
 	return nil
 }
 
+// saveModes maps save_mode values to core.SaveMode constants
+var saveModes = map[string]string{
+	"":              "",
+	"auto":          "",
+	"always_insert": "core.SaveAlwaysInsert",
+	"always_update": "core.SaveAlwaysUpdate",
+}
+
+// SaveModeExpr returns the core.SaveMode constant for the configured save mode,
+// or an empty string when the default (auto) applies
+func (c *Config) SaveModeExpr() string {
+	return saveModes[c.SaveMode]
+}
+
+// RepositoryStructName returns the name of the generated repository struct
+func (c *Config) RepositoryStructName() string {
+	if c.RepositoryName != "" {
+		return c.RepositoryName
+	}
+	return c.EntityType + "Repository"
+}
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		GenerateComments: true,
 		GenerateTests:    false,
-		IDType:          "int64",
 	}
 }
 
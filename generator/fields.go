@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EntityField is a column of an entity struct parsed from source
+type EntityField struct {
+	Name   string // Go field name
+	Column string // Column name, from the db tag or the snake_cased field name
+	Type   string // Go type expression, e.g. "*time.Time"
+}
+
+// EntityFields lists an entity's columns and the imports their types need
+type EntityFields struct {
+	Entity     string
+	Fields     []EntityField
+	Imports    map[string]string // import path -> name used in the field types
+	PrimaryKey string            // Type of the primary_key field, "" if none
+}
+
+// ParseEntityFields finds the struct named entityName among the non-test Go
+// files in dir and lists its columns the way core.EntityMetadata does:
+// db:"-" fields are skipped and embedded structs declared in the same package
+// are flattened. It returns nil when the struct is not declared in dir.
+func ParseEntityFields(dir, entityName string) (*EntityFields, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	// Index the package's struct types with the file declaring them
+	structs := make(map[string]declaredStruct)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = declaredStruct{st: st, file: file}
+					}
+				}
+			}
+		}
+	}
+
+	if _, ok := structs[entityName]; !ok {
+		return nil, nil
+	}
+
+	result := &EntityFields{Entity: entityName, Imports: make(map[string]string)}
+	if err := result.collect(fset, structs, entityName, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type declaredStruct struct {
+	st   *ast.StructType
+	file *ast.File
+}
+
+// collect appends the columns of the named struct, flattening embedded structs
+func (ef *EntityFields) collect(fset *token.FileSet, structs map[string]declaredStruct, name string, visiting map[string]bool) error {
+	decl := structs[name]
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	for _, field := range decl.st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		dbTag := tag.Get("db")
+		if dbTag == "-" {
+			continue
+		}
+
+		// Embedded structs of this package without a db tag are flattened
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok && dbTag == "" {
+				if _, ok := structs[ident.Name]; ok && !visiting[ident.Name] {
+					if err := ef.collect(fset, structs, ident.Name, visiting); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		typeExpr, err := ef.typeString(fset, field.Type, decl.file)
+		if err != nil {
+			return err
+		}
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			column := dbTag
+			if column == "" {
+				column = toSnakeCase(ident.Name)
+			}
+			ef.Fields = append(ef.Fields, EntityField{Name: ident.Name, Column: column, Type: typeExpr})
+		}
+		if _, ok := parseTags(tag.Get("jet"))["primary_key"]; ok && ef.PrimaryKey == "" {
+			ef.PrimaryKey = typeExpr
+		}
+	}
+	return nil
+}
+
+// typeString prints a field type and records the imports it refers to
+func (ef *EntityFields) typeString(fset *token.FileSet, expr ast.Expr, file *ast.File) (string, error) {
+	var err error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			imported, found := resolveImport(file, pkg.Name)
+			if !found {
+				err = fmt.Errorf("unknown package %s in type of %s field", pkg.Name, ef.Entity)
+				return false
+			}
+			ef.Imports[imported] = pkg.Name
+		}
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, expr); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// resolveImport resolves a package name used in file to its import path
+func resolveImport(file *ast.File, name string) (string, bool) {
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return importPath, true
+			}
+			continue
+		}
+		if defaultImportName(importPath) == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// defaultImportName guesses the package name of an import path without
+// loading it: the last element, skipping a /vN suffix, a .vN suffix as used by
+// gopkg.in, and a go- prefix
+func defaultImportName(importPath string) string {
+	name := path.Base(importPath)
+	if isMajorVersion(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// importSpecs returns the import lines the field types need, standard library
+// packages first, skipping paths already imported
+func (ef *EntityFields) importSpecs(existing ...string) (std, thirdParty []string) {
+	skip := make(map[string]bool, len(existing))
+	for _, imported := range existing {
+		skip[imported] = true
+	}
+	for importPath, name := range ef.Imports {
+		if skip[importPath] {
+			continue
+		}
+		spec := strconv.Quote(importPath)
+		if defaultImportName(importPath) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			thirdParty = append(thirdParty, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(thirdParty)
+	return std, thirdParty
+}
+
+// generateFieldsCode emits the <Entity>Fields variable of typed columns
+func generateFieldsCode(ef *EntityFields) string {
+	if ef == nil || len(ef.Fields) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// %sFields holds typed column references for %s specifications, e.g.\n", ef.Entity, ef.Entity)
+	fmt.Fprintf(&buf, "// %sFields.%s.Eq(value).\n", ef.Entity, ef.Fields[0].Name)
+	fmt.Fprintf(&buf, "var %sFields = struct {\n", ef.Entity)
+	for _, f := range ef.Fields {
+		fmt.Fprintf(&buf, "\t%s core.Column[%s, %s]\n", f.Name, ef.Entity, f.Type)
+	}
+	buf.WriteString("}{\n")
+	for _, f := range ef.Fields {
+		fmt.Fprintf(&buf, "\t%s: core.NewColumn[%s, %s](%q),\n", f.Name, ef.Entity, f.Type, f.Column)
+	}
+	buf.WriteString("}\n")
+
+	// Align the struct like gofmt would
+	const header = "package p\n\n"
+	formatted, err := format.Source([]byte(header + buf.String()))
+	if err != nil {
+		return buf.String()
+	}
+	return strings.TrimPrefix(string(formatted), header)
+}
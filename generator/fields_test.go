@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fieldsEntitySource = `package models
+
+import (
+	"context"
+	"time"
+
+	guuid "github.com/google/uuid"
+)
+
+type Audit struct {
+	CreatedAt time.Time ` + "`db:\"created_at\"`" + `
+	CreatedBy *string
+}
+
+type Account struct {
+	ID       int64       ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email    string      ` + "`db:\"email_address\"`" + `
+	PublicID guuid.UUID  ` + "`db:\"public_id\"`" + `
+	Tags     []string    ` + "`db:\"tags\" jet:\"type:jsonb\"`" + `
+	Secret   string      ` + "`db:\"-\"`" + `
+	internal int
+	Audit
+}
+
+type AccountRepository interface {
+	FindByEmail(ctx context.Context, email string) (*Account, error)
+}
+`
+
+func TestParseEntityFields(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "account.go"), []byte(fieldsEntitySource), 0644); err != nil {
+		t.Fatalf("Failed to write entity file: %v", err)
+	}
+
+	fields, err := ParseEntityFields(dir, "Account")
+	if err != nil {
+		t.Fatalf("Failed to parse fields: %v", err)
+	}
+
+	expected := []EntityField{
+		{Name: "ID", Column: "id", Type: "int64"},
+		{Name: "Email", Column: "email_address", Type: "string"},
+		{Name: "PublicID", Column: "public_id", Type: "guuid.UUID"},
+		{Name: "Tags", Column: "tags", Type: "[]string"},
+		{Name: "CreatedAt", Column: "created_at", Type: "time.Time"},
+		{Name: "CreatedBy", Column: "created_by", Type: "*string"},
+	}
+	if len(fields.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %+v", len(expected), fields.Fields)
+	}
+	for i, want := range expected {
+		if fields.Fields[i] != want {
+			t.Errorf("Expected %+v, got %+v", want, fields.Fields[i])
+		}
+	}
+
+	std, thirdParty := fields.importSpecs()
+	if len(std) != 1 || std[0] != `"time"` {
+		t.Errorf("Expected time import, got %v", std)
+	}
+	if len(thirdParty) != 1 || thirdParty[0] != `guuid "github.com/google/uuid"` {
+		t.Errorf("Expected aliased uuid import, got %v", thirdParty)
+	}
+
+	if missing, err := ParseEntityFields(dir, "Missing"); err != nil || missing != nil {
+		t.Errorf("Expected nil for an undeclared entity, got %+v, %v", missing, err)
+	}
+}
+
+func TestGenerate_FieldConstants(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "account.go")
+	if err := os.WriteFile(input, []byte(fieldsEntitySource), 0644); err != nil {
+		t.Fatalf("Failed to write entity file: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "Account"
+	cfg.InterfaceName = "AccountRepository"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "account_repository_gen.go")
+
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+
+	for _, want := range []string{
+		"var AccountFields = struct {",
+		"Email     core.Column[Account, string]",
+		`PublicID:  core.NewColumn[Account, guuid.UUID]("public_id"),`,
+		`CreatedAt: core.NewColumn[Account, time.Time]("created_at"),`,
+		"\t\"time\"\n",
+		"\tguuid \"github.com/google/uuid\"\n",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "Secret") || strings.Contains(code, "internal") {
+		t.Errorf("Expected ignored and unexported fields to be skipped, got:\n%s", code)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Errorf("Generated code has syntax errors: %v\nCode:\n%s", err, code)
+	}
+}
@@ -0,0 +1,466 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/satishbabariya/jetorm/migration"
+)
+
+// Generate parses the repository interface described by cfg and writes the
+// generated repository, plus a test file when cfg.GenerateTests is set, a
+// mock of the interface when cfg.GenerateMocks is set and the entity's Jet
+// table when cfg.JetTablesDir is set.
+// It returns the paths of the files written. A config listing Repositories
+// generates each of them, as GenerateAll does.
+func Generate(cfg *Config) ([]string, error) {
+	if len(cfg.Repositories) > 0 {
+		return GenerateAll(cfg.Expand())
+	}
+	return generate(cfg, make(typeLoaders))
+}
+
+// GenerateAll generates the repository of every config in one pass. Packages
+// are type-checked once however many repositories they declare, and every
+// config is generated even when others fail; the returned error joins the
+// failures, each prefixed with its interface name.
+func GenerateAll(configs []*Config) ([]string, error) {
+	return generateAll(configs, make(typeLoaders))
+}
+
+// generateAll is GenerateAll with a cache of type-checked packages
+func generateAll(configs []*Config, loaders typeLoaders) ([]string, error) {
+	var files []string
+	var errs []error
+	for _, cfg := range configs {
+		written, err := generate(cfg, loaders)
+		files = append(files, written...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cfg.InterfaceName, err))
+		}
+	}
+	return files, errors.Join(errs...)
+}
+
+// typeLoaders caches the type-checked package of each input directory
+type typeLoaders map[string]*TypeLoader
+
+// load returns the type loader of dir, type-checking it on first use
+func (l typeLoaders) load(dir string) (*TypeLoader, error) {
+	dir = filepath.Clean(dir)
+	if loader, ok := l[dir]; ok {
+		return loader, nil
+	}
+	loader, err := NewTypeLoader(dir)
+	if err != nil {
+		return nil, err
+	}
+	l[dir] = loader
+	return loader, nil
+}
+
+// generate writes the repository of a single config
+func generate(cfg *Config, loaders typeLoaders) ([]string, error) {
+	// Get package name
+	pkgName := cfg.OutputPackage
+	if pkgName == "" {
+		pkgName = extractPackageName(cfg.InputFile)
+	}
+
+	// Parse interface to extract methods
+	interfaceInfo, err := NewParser().ParseInterface(cfg.InputFile, cfg.InterfaceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse interface: %w", err)
+	}
+	if interfaceInfo == nil {
+		return nil, fmt.Errorf("interface %s not found in %s", cfg.InterfaceName, cfg.InputFile)
+	}
+
+	// Typed field constants are emitted when the entity is declared next to the interface
+	fields, err := ParseEntityFields(filepath.Dir(cfg.InputFile), cfg.EntityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entity fields: %w", err)
+	}
+
+	// Query methods get real bodies when the entity type resolves
+	loader, err := loaders.load(filepath.Dir(cfg.InputFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity type: %w", err)
+	}
+	entity, err := loader.LoadEntityType(cfg.EntityType)
+	if err != nil {
+		entity = nil // declared elsewhere: fall back to stubs
+	}
+
+	// Stamp the latest migration so Connect can verify the database is not older
+	var schemaVersion int64
+	if cfg.MigrationsDir != "" {
+		schemaVersion, err = migration.LatestVersion(os.DirFS(cfg.MigrationsDir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations: %w", err)
+		}
+	}
+
+	// Plugins may adjust the config before anything is generated
+	customMethods := interfaceInfo.FindCustomMethods()
+	plugins := &PluginContext{Config: cfg, PackageName: pkgName, EntityName: cfg.EntityType,
+		RepositoryName: cfg.RepositoryStructName(), Methods: customMethods}
+	if err := plugins.preGenerate(); err != nil {
+		return nil, err
+	}
+
+	// Generate repository code
+	code, err := generateRepositoryCode(pkgName, cfg.EntityType, customMethods, fields, entity, loader, schemaVersion, cfg, plugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code: %w", err)
+	}
+	if code, err = plugins.postGenerate(code); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cfg.OutputFile, []byte(code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write output file: %w", err)
+	}
+	files := []string{cfg.OutputFile}
+
+	// Generate tests if requested
+	if cfg.GenerateTests {
+		testCode, err := generateTestCode(pkgName, cfg.EntityType, customMethods, cfg)
+		if err != nil {
+			return files, fmt.Errorf("failed to generate tests: %w", err)
+		}
+		testFile := strings.TrimSuffix(cfg.OutputFile, ".go") + "_test.go"
+		if err := os.WriteFile(testFile, []byte(testCode), 0644); err != nil {
+			return files, fmt.Errorf("failed to write test file: %w", err)
+		}
+		files = append(files, testFile)
+	}
+
+	// Generate a mock of the interface if requested
+	if cfg.GenerateMocks {
+		mockCode, err := generateMockCode(pkgName, cfg.InterfaceName, loader)
+		if err != nil {
+			return files, fmt.Errorf("failed to generate mock: %w", err)
+		}
+		mockFile := MockFile(cfg.OutputFile)
+		if err := os.WriteFile(mockFile, []byte(mockCode), 0644); err != nil {
+			return files, fmt.Errorf("failed to write mock file: %w", err)
+		}
+		files = append(files, mockFile)
+	}
+
+	// Generate the entity's Jet table if requested
+	if cfg.JetTablesDir != "" {
+		if entity == nil {
+			return files, fmt.Errorf("failed to generate Jet table: entity %s does not resolve", cfg.EntityType)
+		}
+		tableFile, err := writeJetTable(cfg.JetTablesDir, entity)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, tableFile)
+	}
+
+	return files, nil
+}
+
+// extractPackageName extracts package name from a Go file
+func extractPackageName(filePath string) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	if err != nil {
+		return "main"
+	}
+	return f.Name.Name
+}
+
+// generateRepositoryCode generates the complete repository implementation
+func generateRepositoryCode(pkgName, entityName string, customMethods []MethodInfo, fields *EntityFields, entity *EntityTypeInfo, loader *TypeLoader, schemaVersion int64, cfg *Config, plugins *PluginContext) (string, error) {
+	var buf strings.Builder
+
+	// Generate the query methods first, as they decide the imports
+	repoName := cfg.RepositoryStructName()
+	methods, err := generateQueryMethods(customMethods, entityName, repoName, entity, fields, loader, plugins)
+	if err != nil {
+		return "", err
+	}
+
+	// Write package declaration
+	buf.WriteString(fmt.Sprintf("package %s\n\n", pkgName))
+
+	// Write imports, adding the packages of the field types
+	var std []string
+	if methods != "" {
+		std = append(std, `"context"`)
+	}
+	thirdParty := []string{`"github.com/satishbabariya/jetorm/core"`}
+	if fields != nil {
+		fieldStd, fieldThirdParty := fields.importSpecs("context", "github.com/satishbabariya/jetorm/core")
+		std = append(std, fieldStd...)
+		thirdParty = append(thirdParty, fieldThirdParty...)
+	}
+	pluginStd, pluginThirdParty := plugins.importSpecs()
+	std = appendMissing(std, pluginStd...)
+	thirdParty = appendMissing(thirdParty, pluginThirdParty...)
+	sort.Strings(std)
+	sort.Strings(thirdParty)
+	buf.WriteString("import (\n")
+	for _, spec := range std {
+		buf.WriteString("\t" + spec + "\n")
+	}
+	if len(std) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, spec := range thirdParty {
+		buf.WriteString("\t" + spec + "\n")
+	}
+	buf.WriteString(")\n")
+
+	idType := repositoryIDType(cfg, entity, fields)
+
+	// Apply the configured save mode in the constructor
+	saveMode := ""
+	if expr := cfg.SaveModeExpr(); expr != "" {
+		saveMode = fmt.Sprintf("\tbaseRepo = baseRepo.WithSaveMode(%s)\n", expr)
+	}
+
+	// Add comments if requested
+	if cfg.GenerateComments {
+		buf.WriteString(fmt.Sprintf(`
+// %s is the generated repository implementation for %s entities.
+// This code is auto-generated by jetorm-gen. Do not edit manually.
+type %s struct {
+	*core.BaseRepository[%s, %s]
+}
+
+// New%s creates a new %s repository instance.
+func New%s(db *core.Database) (*%s, error) {
+	baseRepo, err := core.NewBaseRepository[%s, %s](db)
+	if err != nil {
+		return nil, err
+	}
+%s	return &%s{
+		BaseRepository: baseRepo,
+	}, nil
+}
+`, repoName, entityName, repoName, entityName, idType, repoName, repoName, repoName, repoName, entityName, idType, saveMode, repoName))
+	} else {
+		buf.WriteString(fmt.Sprintf(`
+type %s struct {
+	*core.BaseRepository[%s, %s]
+}
+
+func New%s(db *core.Database) (*%s, error) {
+	baseRepo, err := core.NewBaseRepository[%s, %s](db)
+	if err != nil {
+		return nil, err
+	}
+%s	return &%s{
+		BaseRepository: baseRepo,
+	}, nil
+}
+`, repoName, entityName, idType, repoName, repoName, entityName, idType, saveMode, repoName))
+	}
+
+	// Schema version the generated code was built against
+	if schemaVersion > 0 {
+		buf.WriteString(fmt.Sprintf(`
+// %sSchemaVersion is the latest migration when this file was generated.
+const %sSchemaVersion int64 = %d
+
+func init() {
+	core.RequireSchemaVersion(%sSchemaVersion)
+}
+`, repoName, repoName, schemaVersion, repoName))
+	}
+
+	// Typed column references for specifications
+	if fieldsCode := generateFieldsCode(fields); fieldsCode != "" {
+		buf.WriteString("\n")
+		buf.WriteString(fieldsCode)
+	}
+
+	buf.WriteString(methods)
+
+	return buf.String(), nil
+}
+
+// repositoryIDType returns the ID type argument of the generated
+// BaseRepository: the configured IDType, or the type of the entity's
+// primary_key field, such as string, uuid.UUID or a key struct, or int64
+func repositoryIDType(cfg *Config, entity *EntityTypeInfo, fields *EntityFields) string {
+	switch {
+	case cfg.IDType != "":
+		return cfg.IDType
+	case fields != nil && fields.PrimaryKey != "":
+		return fields.PrimaryKey // As written, with its import recorded
+	case entity != nil && entity.PrimaryKey != nil:
+		return entity.GetIDType()
+	}
+	return "int64"
+}
+
+// generateQueryMethods implements the derived query methods of the interface.
+// Without the entity type only stubs can be written, after checking their
+// names against fields when the entity's declaration was parsed. The loader
+// resolves the DTOs finders return, and plugins rewrite each method's body.
+// Errors are reported for every failing method, each at its position.
+func generateQueryMethods(customMethods []MethodInfo, entityName, repoName string, entity *EntityTypeInfo, fields *EntityFields, loader *TypeLoader, plugins *PluginContext) (string, error) {
+	var buf strings.Builder
+	gen := &CodeGenerator{}
+	if entity != nil {
+		gen = NewCodeGeneratorForType(entity)
+	}
+	gen.SetRepositoryName(repoName)
+	gen.SetTypeLoader(loader)
+
+	// Without the entity type, names are still checked against the fields
+	// declared next to the interface
+	var analyzer *Analyzer
+	if entity == nil && fields != nil {
+		analyzer = NewAnalyzerForFields(fields)
+	}
+
+	// Every method is checked, so one run reports all bad names
+	var errs []error
+	for _, methodInfo := range customMethods {
+		var methodCode string
+		switch {
+		case methodInfo.Query != "":
+			// Annotated methods need no entity metadata
+			code, err := gen.GenerateAnnotatedMethod(methodInfo, entityName)
+			if err != nil {
+				errs = append(errs, methodError(methodInfo, err))
+				continue
+			}
+			methodCode = fmt.Sprintf("// %s runs its %s\n%s", methodInfo.Name, QueryAnnotation, strings.TrimSuffix(code, "\n"))
+		case !IsQueryMethod(methodInfo.Name):
+			continue
+		case entity == nil:
+			if analyzer != nil {
+				if _, err := analyzer.AnalyzeMethod(methodInfo.Name); err != nil {
+					errs = append(errs, methodError(methodInfo, err))
+					continue
+				}
+			}
+			methodCode = generateMethodStub(methodInfo, repoName)
+		default:
+			code, err := gen.GenerateInterfaceMethod(methodInfo, entityName)
+			if err != nil {
+				errs = append(errs, methodError(methodInfo, err))
+				continue
+			}
+			methodCode = fmt.Sprintf("// %s implements the query method\n%s", methodInfo.Name, strings.TrimSuffix(code, "\n"))
+		}
+		methodCode, err := plugins.methodBody(methodInfo, methodCode)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		buf.WriteString("\n")
+		buf.WriteString(methodCode)
+		buf.WriteString("\n")
+	}
+
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	// Keep the generated queries running when raw SQL is restricted
+	if allowlist := gen.AllowlistCode(); allowlist != "" {
+		buf.WriteString("\n")
+		buf.WriteString(allowlist)
+	}
+
+	return buf.String(), nil
+}
+
+// methodError prefixes the error of a method with its file:line:col and,
+// unless the error already starts with it, its name
+func methodError(info MethodInfo, err error) error {
+	if !strings.HasPrefix(err.Error(), info.Name+":") {
+		err = fmt.Errorf("%s: %w", info.Name, err)
+	}
+	if info.Pos.IsValid() {
+		err = fmt.Errorf("%s: %w", info.Pos, err)
+	}
+	return err
+}
+
+// appendMissing appends the specs not already in specs
+func appendMissing(specs []string, more ...string) []string {
+	for _, spec := range more {
+		if !slices.Contains(specs, spec) {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// generateTestCode generates test code for the repository
+func generateTestCode(pkgName, entityName string, customMethods []MethodInfo, cfg *Config) (string, error) {
+	var buf strings.Builder
+
+	buf.WriteString(fmt.Sprintf("package %s\n\n", pkgName))
+	buf.WriteString(`import (
+	"context"
+	"testing"
+)
+`)
+
+	repoName := cfg.RepositoryStructName()
+	buf.WriteString(fmt.Sprintf(`
+func Test%s(t *testing.T) {
+	// TODO: Implement tests for %s
+	t.Skip("Tests not yet implemented")
+}
+`, repoName, repoName))
+
+	return buf.String(), nil
+}
+
+// generateMethodStub generates a method stub for a query method
+func generateMethodStub(methodInfo MethodInfo, repoName string) string {
+	var buf strings.Builder
+
+	// Build parameter list
+	var params []string
+	for _, param := range methodInfo.Parameters {
+		if param.Name != "" {
+			params = append(params, fmt.Sprintf("%s %s", param.Name, param.Type))
+		} else {
+			params = append(params, param.Type)
+		}
+	}
+	paramsStr := strings.Join(params, ", ")
+
+	// Build return list
+	var returns []string
+	for _, ret := range methodInfo.Returns {
+		returns = append(returns, ret.Type)
+	}
+	returnsStr := strings.Join(returns, ", ")
+	if len(returns) > 1 {
+		returnsStr = "(" + returnsStr + ")"
+	}
+
+	// Generate method signature
+	buf.WriteString(fmt.Sprintf("// %s implements the query method\n", methodInfo.Name))
+	buf.WriteString(fmt.Sprintf("func (r *%s) %s(ctx context.Context", repoName, methodInfo.Name))
+	if paramsStr != "" {
+		buf.WriteString(", " + paramsStr)
+	}
+	buf.WriteString(fmt.Sprintf(") %s {\n", returnsStr))
+	buf.WriteString("\t// TODO: Implement query method\n")
+	buf.WriteString("\t// This method should be generated using jetorm-gen with full type information\n")
+	buf.WriteString("\tpanic(\"not implemented\")\n")
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
@@ -90,6 +90,9 @@ func TestIntegration_MethodAnalysis(t *testing.T) {
 		{"CountByStatus", true},
 		{"DeleteByEmail", true},
 		{"ExistsByUsername", true},
+		{"UpdateStatusByID", true},
+		{"SetActiveFalseByLastLoginBefore", true},
+		{"UpdateAll", false},
 		{"FindFirstByStatus", true},
 		{"FindByStatusOrderByCreatedAtDesc", true},
 		{"InvalidMethod", false},
@@ -148,6 +151,948 @@ func TestIntegration_ConfigFile(t *testing.T) {
 	}
 }
 
+// TestIntegration_GenerateAll tests generating several repositories from one config
+func TestIntegration_GenerateAll(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "models.go")
+	source := `package models
+
+import "context"
+
+type User struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email string ` + "`db:\"email\"`" + `
+}
+
+type Post struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Title string ` + "`db:\"title\"`" + `
+}
+
+type UserQueries interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+}
+
+type PostQueries interface {
+	FindByTitle(ctx context.Context, title string) ([]*Post, error)
+}
+
+type BrokenQueries interface {
+	FindByNickname(ctx context.Context, nickname string) (*User, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.InputFile = input
+	cfg.SaveMode = "always_insert"
+	cfg.Repositories = []Config{
+		{EntityType: "User", InterfaceName: "UserQueries", OutputFile: filepath.Join(dir, "user_gen.go")},
+		{EntityType: "User", InterfaceName: "BrokenQueries", OutputFile: filepath.Join(dir, "broken_gen.go"), RepositoryName: "BrokenRepository"},
+		{EntityType: "Post", InterfaceName: "PostQueries", OutputFile: filepath.Join(dir, "post_gen.go")},
+	}
+
+	configs := cfg.Expand()
+	if len(configs) != 3 || configs[2].InputFile != input || configs[2].SaveMode != "always_insert" || !configs[2].GenerateComments {
+		t.Fatalf("Expected entries to inherit the shared settings, got %+v", configs[2])
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config validation failed: %v", err)
+	}
+
+	// The failing repository is reported without stopping the others
+	files, err := Generate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "BrokenQueries: ") {
+		t.Errorf("Expected an error naming BrokenQueries, got %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 files, got %v", files)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "post_gen.go")); err != nil || !strings.Contains(string(data), "func (r *PostRepository) FindByTitle") {
+		t.Errorf("Expected the post repository, got %s (%v)", data, err)
+	}
+
+	cfg.Repositories[0].InterfaceName = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation to check every repository")
+	}
+}
+
+// TestIntegration_SaveMode tests that the configured save mode reaches the generated constructor
+func TestIntegration_SaveMode(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserRepository"
+	cfg.InputFile = "user.go"
+	cfg.OutputFile = "user_repository_gen.go"
+
+	cfg.SaveMode = "sometimes"
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Expected validation error for unknown save mode")
+	}
+
+	cfg.SaveMode = "always_insert"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config validation failed: %v", err)
+	}
+
+	code, err := GenerateRepositoryCode(TemplateData{
+		PackageName:    "models",
+		EntityName:     "User",
+		RepositoryName: "UserRepository",
+		IDType:         "string",
+		SaveMode:       cfg.SaveModeExpr(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	if !strings.Contains(code, "baseRepo = baseRepo.WithSaveMode(core.SaveAlwaysInsert)") {
+		t.Errorf("Expected constructor to apply save mode, got:\n%s", code)
+	}
+
+	cfg.SaveMode = "auto"
+	if expr := cfg.SaveModeExpr(); expr != "" {
+		t.Errorf("Expected no save mode for auto, got %s", expr)
+	}
+}
+
+func TestIntegration_QueryMethodBodies(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import (
+	"context"
+	"time"
+)
+
+type Audit struct {
+	CreatedAt time.Time  ` + "`db:\"created_at\"`" + `
+	DeletedAt *time.Time ` + "`db:\"deleted_at\" jet:\"soft_delete\"`" + `
+}
+
+type User struct {
+	ID       int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email    string ` + "`db:\"email_address\"`" + `
+	Age      int
+	Password string ` + "`db:\"-\"`" + `
+	Audit
+}
+
+type UserQueries interface {
+	FindByEmail(ctx context.Context, address string) (*User, error)
+	FindByAgeBetweenOrderByCreatedAtDesc(ctx context.Context, lo, hi int) ([]*User, error)
+	CountByCreatedAtAfter(ctx context.Context, since time.Time) (int64, error)
+	DeleteByEmailIn(ctx context.Context, emails []string) error
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	loader, err := NewTypeLoader(dir)
+	if err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	entity, err := loader.LoadEntityType("User")
+	if err != nil {
+		t.Fatalf("Failed to load entity: %v", err)
+	}
+	var columns []string
+	for _, f := range entity.Fields {
+		columns = append(columns, f.DBName+" "+f.TypeName)
+	}
+	if got := strings.Join(columns, ", "); got != "id int64, email_address string, age int, created_at time.Time, deleted_at *time.Time" {
+		t.Errorf("Unexpected columns %s", got)
+	}
+	if entity.GetIDType() != "int64" {
+		t.Errorf("Expected int64 ID, got %s", entity.GetIDType())
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		`return r.QueryOne(ctx, "SELECT * FROM user WHERE (email_address = $1) AND deleted_at IS NULL", address)`,
+		`return r.Query(ctx, "SELECT * FROM user WHERE (age BETWEEN $1 AND $2) AND deleted_at IS NULL ORDER BY created_at DESC", lo, hi)`,
+		`return r.CountWithSpec(ctx, core.Where[User]("created_at > $1", since))`,
+		`_, err := r.DeleteWithSpec(ctx, core.Where[User]("email_address = ANY($1)", emails))`,
+		"core.AllowQueries(",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "panic(") || strings.Contains(code, "pgconn") {
+		t.Errorf("Expected no stubs or unused imports, got:\n%s", code)
+	}
+
+	t.Run("rejects signatures that do not fit the method name", func(t *testing.T) {
+		gen := NewCodeGeneratorForType(entity)
+		for _, method := range []MethodInfo{
+			{Name: "FindByEmail", Parameters: []ParameterInfo{{Name: "ctx", Type: "context.Context"}}, Returns: []ReturnInfo{{Type: "*User"}, {Type: "error"}}},
+			{Name: "CountByAge", Parameters: []ParameterInfo{{Name: "ctx", Type: "context.Context"}, {Name: "age", Type: "int"}}, Returns: []ReturnInfo{{Type: "int"}, {Type: "error"}}},
+		} {
+			if _, err := gen.GenerateInterfaceMethod(method, "User"); err == nil {
+				t.Errorf("Expected an error for %s", method.Name)
+			}
+		}
+	})
+}
+
+func TestIntegration_PagedQueryMethods(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import (
+	"context"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type User struct {
+	ID     int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Name   string ` + "`db:\"name\"`" + `
+	Status string ` + "`db:\"status\"`" + `
+}
+
+type UserQueries interface {
+	FindByStatus(ctx context.Context, status string, pageable core.Pageable) (*core.Page[User], error)
+	FindByStatusOrderByNameDesc(ctx context.Context, status string, page core.Pageable) (*core.Page[User], error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"func (r *UserRepository) FindByStatus(ctx context.Context, status string, pageable core.Pageable) (*core.Page[User], error) {",
+		`return r.FindAllPagedWithSpec(ctx, core.Where[User]("status = $1", status), pageable)`,
+		`page.Sort = core.Sort{Orders: []core.Order{{Field: "name", Direction: core.Desc}}}`,
+		`return r.FindAllPagedWithSpec(ctx, core.Where[User]("status = $1", status), page)`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+
+	t.Run("rejects mismatched signatures", func(t *testing.T) {
+		gen := &CodeGenerator{analyzer: &Analyzer{fields: map[string]string{"Status": "string"}}}
+		ctxParam := ParameterInfo{Name: "ctx", Type: "context.Context"}
+		pageParam := ParameterInfo{Name: "pageable", Type: "core.Pageable"}
+		status := ParameterInfo{Name: "status", Type: "string"}
+		page := []ReturnInfo{{Type: "*core.Page[User]"}, {Type: "error"}}
+		for _, method := range []MethodInfo{
+			{Name: "FindByStatus", Parameters: []ParameterInfo{ctxParam, status, pageParam}, Returns: []ReturnInfo{{Type: "[]*User"}, {Type: "error"}}},
+			{Name: "FindByStatus", Parameters: []ParameterInfo{ctxParam, status}, Returns: page},
+			{Name: "CountByStatus", Parameters: []ParameterInfo{ctxParam, status, pageParam}, Returns: []ReturnInfo{{Type: "int64"}, {Type: "error"}}},
+			{Name: "FindTop3ByStatus", Parameters: []ParameterInfo{ctxParam, status, pageParam}, Returns: page},
+		} {
+			if _, err := gen.GenerateInterfaceMethod(method, "User"); err == nil {
+				t.Errorf("Expected an error for %s %v -> %v", method.Name, method.Parameters, method.Returns)
+			}
+		}
+	})
+}
+
+func TestIntegration_SortedQueryMethods(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import (
+	"context"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type User struct {
+	ID     int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Name   string ` + "`db:\"name\"`" + `
+	Active bool   ` + "`db:\"active\"`" + `
+}
+
+type UserQueries interface {
+	FindByActiveTrue(ctx context.Context, sort core.Sort) ([]*User, error)
+	FindTop5ByNameOrderByIDAsc(ctx context.Context, name string, sort core.Sort) ([]*User, error)
+	FindFirstByName(ctx context.Context, name string, sort core.Sort) (*User, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"func (r *UserRepository) FindByActiveTrue(ctx context.Context, sort core.Sort) ([]*User, error) {",
+		`page, err := r.FindAllPagedWithSpec(ctx, core.Where[User]("active = true"), core.Pageable{Sort: sort, CountMode: core.CountNone})`,
+		`sort = core.Sort{Orders: []core.Order{{Field: "id", Direction: core.Asc}}}`,
+		`page, err := r.FindAllPagedWithSpec(ctx, core.Where[User]("name = $1", name), core.Pageable{Size: 5, Sort: sort, CountMode: core.CountNone})`,
+		`page, err := r.FindAllPagedWithSpec(ctx, core.Where[User]("name = $1", name), core.Pageable{Size: 1, Sort: sort, CountMode: core.CountNone})`,
+		"return nil, core.ErrNotFound",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestIntegration_UpdateQueryMethods(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import (
+	"context"
+	"time"
+)
+
+type User struct {
+	ID        int64     ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Status    string    ` + "`db:\"status\"`" + `
+	Active    bool      ` + "`db:\"active\"`" + `
+	LastLogin time.Time ` + "`db:\"last_login\"`" + `
+}
+
+type UserQueries interface {
+	UpdateStatusByID(ctx context.Context, id int64, status string) (int64, error)
+	SetActiveFalseByLastLoginBefore(ctx context.Context, t time.Time) error
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"// UPDATE user SET status = $1 WHERE id = $2",
+		`return r.UpdateWithSpec(ctx, map[string]interface{}{"status": status}, core.Where[User]("id = $1", id))`,
+		"// UPDATE user SET active = $1 WHERE last_login < $2",
+		`_, err := r.UpdateWithSpec(ctx, map[string]interface{}{"active": false}, core.Where[User]("last_login < $1", t))`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestIntegration_MethodNameDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import "context"
+
+type User struct {
+	ID       int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email    string ` + "`db:\"email\"`" + `
+	Username string ` + "`db:\"username\"`" + `
+}
+
+type UserQueries interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByEmial(ctx context.Context, email string) (*User, error)
+	CountByUsernameLikee(ctx context.Context, username string) (int64, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	_, err := Generate(cfg)
+	if err == nil {
+		t.Fatal("Expected Generate to fail on unknown fields and keywords")
+	}
+	for _, want := range []string{
+		input + `:13:2: FindByEmial: unknown field "Emial" (did you mean Email?)`,
+		input + `:14:2: CountByUsernameLikee: unsupported keyword after Username "Likee" (did you mean Like`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to contain %q, got:\n%v", want, err)
+		}
+	}
+	if _, statErr := os.Stat(cfg.OutputFile); !os.IsNotExist(statErr) {
+		t.Errorf("Expected no output file, got %v", statErr)
+	}
+}
+
+func TestIntegration_ProjectionQueryMethods(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import "context"
+
+type User struct {
+	ID     int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email  string ` + "`db:\"email\"`" + `
+	Name   string ` + "`db:\"full_name\"`" + `
+	Status string ` + "`db:\"status\"`" + `
+}
+
+type UserSummary struct {
+	Email string
+	Name  string ` + "`db:\"full_name\"`" + `
+}
+
+type UserContact struct {
+	Email string
+	Name  string
+}
+
+type UserQueries interface {
+	FindEmailAndNameByStatus(ctx context.Context, status string) ([]*UserSummary, error)
+	FindFirstByEmail(ctx context.Context, email string) (*UserContact, error)
+	// jetorm:query SELECT email, full_name FROM users WHERE status = :status
+	FindSummaries(ctx context.Context, status string) ([]*UserSummary, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		`return core.QueryInto[UserSummary](ctx, r.BaseRepository, "SELECT email, full_name FROM user WHERE status = $1", status)`,
+		`return core.QueryOneInto[UserContact](ctx, r.BaseRepository, "SELECT email, full_name AS name FROM user WHERE email = $1 LIMIT 1", email)`,
+		`return core.QueryInto[UserSummary](ctx, r.BaseRepository, "SELECT email, full_name FROM users WHERE status = $1", status)`,
+		"// SELECT email, full_name FROM user WHERE status = $1",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+
+	// The selected fields must match the DTO's
+	for _, method := range []string{
+		"FindEmailByStatus(ctx context.Context, status string) ([]*UserSummary, error)",
+		"FindEmailAndNameByStatus(ctx context.Context, status string) ([]*User, error)",
+	} {
+		bad := strings.Replace(source, "FindEmailAndNameByStatus(ctx context.Context, status string) ([]*UserSummary, error)", method, 1)
+		if err := os.WriteFile(input, []byte(bad), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+		if _, err := Generate(cfg); err == nil {
+			t.Errorf("Expected %s to be rejected", method)
+		}
+	}
+}
+
+func TestIntegration_GenerateMocks(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import "context"
+
+type User struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email string ` + "`db:\"email\"`" + `
+}
+
+type UserQueries interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	CountByEmail(ctx context.Context, email string) (int64, error)
+	Touch(context.Context, ...int64) error
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	cfg.GenerateMocks = true
+	files, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	mockFile := filepath.Join(dir, "user_repository_gen_mock.go")
+	if len(files) != 2 || files[1] != mockFile {
+		t.Fatalf("Expected the repository and %s, got %v", mockFile, files)
+	}
+	data, err := os.ReadFile(mockFile)
+	if err != nil {
+		t.Fatalf("Failed to read mock: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated mock has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		`jetormtesting "github.com/satishbabariya/jetorm/testing"`,
+		"type MockUserQueries struct {\n\tjetormtesting.Recorder",
+		"func(ctx context.Context, email string) (*User, error)\n",
+		"var _ UserQueries = (*MockUserQueries)(nil)",
+		"m.Recorder.Record(\"FindByEmail\", email)\n\tif m.FindByEmailFunc != nil {\n\t\treturn m.FindByEmailFunc(ctx, email)\n\t}\n\treturn nil, core.ErrNotFound",
+		"\treturn 0, nil\n}",
+		"func (m *MockUserQueries) Touch(arg0 context.Context, arg1 ...int64) error {\n\tm.Recorder.Record(\"Touch\", arg1)",
+		"return m.TouchFunc(arg0, arg1...)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected mock to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestIntegration_GenerateJetTables(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "order_item.go")
+	source := `package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type OrderItem struct {
+	ID        int64        ` + "`db:\"id\" jet:\"primary_key,auto_increment\"`" + `
+	OrderID   string       ` + "`db:\"order_id\" jet:\"uuid\"`" + `
+	Quantity  int          ` + "`db:\"quantity\" jet:\"default:1\"`" + `
+	Price     float64      ` + "`db:\"price\" jet:\"type:numeric(10,2)\"`" + `
+	Note      sql.NullString ` + "`db:\"note\"`" + `
+	Payload   []byte       ` + "`db:\"payload\"`" + `
+	ShippedAt *time.Time   ` + "`db:\"shipped_at\" jet:\"type:timestamptz\"`" + `
+	CreatedAt time.Time    ` + "`db:\"created_at\" jet:\"auto_now_add\"`" + `
+}
+
+type OrderItemRepository interface {
+	FindByOrderID(ctx context.Context, orderID string) ([]*OrderItem, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "OrderItem"
+	cfg.InterfaceName = "OrderItemRepository"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "order_item_repository_gen.go")
+	cfg.JetTablesDir = filepath.Join(dir, "table")
+	files, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	tableFile := filepath.Join(dir, "table", "order_item.go")
+	if len(files) != 2 || files[1] != tableFile {
+		t.Fatalf("Expected the repository and %s, got %v", tableFile, files)
+	}
+	data, err := os.ReadFile(tableFile)
+	if err != nil {
+		t.Fatalf("Failed to read Jet table: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated Jet table has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"package table",
+		`var OrderItem = newOrderItemTable("", "order_item", "")`,
+		"type orderItemTable struct {\n\tpostgres.Table",
+		"ID        postgres.ColumnInteger",
+		"OrderID   postgres.ColumnString",
+		"Price     postgres.ColumnFloat",
+		"Note      postgres.ColumnString",
+		"Payload   postgres.ColumnBytea",
+		"ShippedAt postgres.ColumnTimestampz",
+		"CreatedAt postgres.ColumnTimestamp",
+		`QuantityColumn  = postgres.IntegerColumn("quantity")`,
+		"mutableColumns  = postgres.ColumnList{OrderIDColumn, QuantityColumn, PriceColumn, NoteColumn, PayloadColumn, ShippedAtColumn, CreatedAtColumn}",
+		"defaultColumns  = postgres.ColumnList{IDColumn, QuantityColumn}",
+		"EXCLUDED:       newOrderItemTableImpl(\"\", \"excluded\", \"\")",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected Jet table to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestIntegration_Discriminator(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "vehicle.go")
+	source := `package models
+
+import "context"
+
+type Vehicle struct {
+	ID   int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Kind string ` + "`db:\"kind\"`" + `
+	Name string ` + "`db:\"name\"`" + `
+}
+
+type Truck struct {
+	Vehicle ` + "`jet:\"discriminator:kind=lorry\"`" + `
+	Payload int ` + "`db:\"payload\"`" + `
+}
+
+type TruckQueries interface {
+	FindByName(ctx context.Context, name string) ([]*Truck, error)
+	CountByPayloadGreaterThan(ctx context.Context, payload int) (int64, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	loader, err := NewTypeLoader(dir)
+	if err != nil {
+		t.Fatalf("Failed to load package: %v", err)
+	}
+	entity, err := loader.LoadEntityType("Truck")
+	if err != nil {
+		t.Fatalf("Failed to load entity: %v", err)
+	}
+	if entity.TableName != "vehicle" || entity.Discriminator == nil || entity.Discriminator.Value != "lorry" {
+		t.Errorf("Expected the vehicle table discriminated by kind = lorry, got %s %+v", entity.TableName, entity.Discriminator)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "Truck"
+	cfg.InterfaceName = "TruckQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "truck_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	for _, want := range []string{
+		`return r.Query(ctx, "SELECT id, kind, name, payload FROM vehicle WHERE (name = $1) AND kind = 'lorry'", name)`,
+		`return r.CountWithSpec(ctx, core.Where[Truck]("payload > $1", payload))`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestIntegration_ScanPackage(t *testing.T) {
+	dir := t.TempDir()
+	source := `package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type User struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email string ` + "`db:\"email\"`" + `
+}
+
+type Order struct {
+	ID     uuid.UUID ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Status string    ` + "`db:\"status\"`" + `
+}
+
+//jetorm:repository
+type UserRepository interface {
+	core.Repository[User, int64]
+	FindByEmail(ctx context.Context, email string) (*User, error)
+}
+
+// OrderStore is found by the marker's entity option.
+//
+//jetorm:repository entity=Order id=uuid.UUID output=orders_gen.go mocks=true
+type OrderStoreRepository interface {
+	CountByStatus(ctx context.Context, status string) (int64, error)
+}
+
+// Unmarked interfaces are left alone
+type AuditRepository interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	configs, err := ScanPackage(dir)
+	if err != nil {
+		t.Fatalf("ScanPackage failed: %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("Expected 2 marked interfaces, got %d", len(configs))
+	}
+
+	order, user := configs[0], configs[1]
+	if order.EntityType != "Order" || order.IDType != "uuid.UUID" || order.OutputFile != filepath.Join(dir, "orders_gen.go") {
+		t.Errorf("Unexpected order config %+v", order)
+	}
+	if !order.GenerateMocks || user.GenerateMocks {
+		t.Errorf("Expected a mock for the order repository only")
+	}
+	if order.RepositoryStructName() != "OrderRepository" {
+		t.Errorf("Expected OrderRepository, got %s", order.RepositoryStructName())
+	}
+	if user.EntityType != "User" || user.IDType != "int64" || user.OutputFile != filepath.Join(dir, "user_repository_gen.go") {
+		t.Errorf("Unexpected user config %+v", user)
+	}
+	// The struct may not take the interface's name
+	if user.RepositoryStructName() != "UserRepositoryImpl" {
+		t.Errorf("Expected UserRepositoryImpl, got %s", user.RepositoryStructName())
+	}
+
+	files, err := GeneratePackage(dir)
+	if err != nil {
+		t.Fatalf("GeneratePackage failed: %v", err)
+	}
+	if len(files) != 3 || files[1] != filepath.Join(dir, "orders_gen_mock.go") {
+		t.Fatalf("Expected 3 files, got %v", files)
+	}
+	data, err := os.ReadFile(user.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	for _, want := range []string{
+		"type UserRepositoryImpl struct",
+		"*core.BaseRepository[User, int64]",
+		"func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*User, error)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, data)
+		}
+	}
+
+	t.Run("rejects unknown marker options", func(t *testing.T) {
+		bad := t.TempDir()
+		os.WriteFile(filepath.Join(bad, "x.go"), []byte("package x\n\n//jetorm:repository table=users\ntype UserRepository interface{}\n"), 0644)
+		if _, err := ScanPackage(bad); err == nil {
+			t.Error("Expected an error for an unknown option")
+		}
+	})
+}
+
+func TestIntegration_QueryAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import (
+	"context"
+	"time"
+)
+
+type User struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email string ` + "`db:\"email\"`" + `
+}
+
+type UserQueries interface {
+	// RecentByDomain finds the users of a domain who signed up lately.
+	//
+	// jetorm:query SELECT * FROM users
+	//   WHERE email LIKE '%@' || :domain AND created_at > :since::timestamptz
+	//   ORDER BY created_at DESC
+	RecentByDomain(ctx context.Context, domain string, since time.Time) ([]*User, error)
+
+	// jetorm:query SELECT * FROM users WHERE lower(email) = lower(:email)
+	FindByEmail(ctx context.Context, email string) (*User, error)
+
+	// jetorm:query UPDATE users SET email = :email WHERE id = :id
+	ChangeEmail(ctx context.Context, id int64, email string) (int64, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	info, err := NewParser().ParseInterface(input, "UserQueries")
+	if err != nil {
+		t.Fatalf("Failed to parse interface: %v", err)
+	}
+	if got := info.Methods[0].Query; got != "SELECT * FROM users\nWHERE email LIKE '%@' || :domain AND created_at > :since::timestamptz\nORDER BY created_at DESC" {
+		t.Errorf("Unexpected annotation %q", got)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		`return r.Query(ctx, "SELECT * FROM users\nWHERE email LIKE '%@' || $1 AND created_at > $2::timestamptz\nORDER BY created_at DESC", domain, since)`,
+		`return r.QueryOne(ctx, "SELECT * FROM users WHERE lower(email) = lower($1)", email)`,
+		`return r.Exec(ctx, "UPDATE users SET email = $1 WHERE id = $2", email, id)`,
+		"// SELECT * FROM users WHERE email LIKE '%@' || $1 AND created_at > $2::timestamptz ORDER BY created_at DESC",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+
+	t.Run("rejects unbound and unused parameters", func(t *testing.T) {
+		gen := &CodeGenerator{}
+		ctxParam := ParameterInfo{Name: "ctx", Type: "context.Context"}
+		for _, method := range []MethodInfo{
+			{Name: "Unbound", Query: "SELECT * FROM users WHERE email = :address", Parameters: []ParameterInfo{ctxParam, {Name: "email", Type: "string"}}, Returns: []ReturnInfo{{Type: "*User"}, {Type: "error"}}},
+			{Name: "Unused", Query: "SELECT * FROM users", Parameters: []ParameterInfo{ctxParam, {Name: "email", Type: "string"}}, Returns: []ReturnInfo{{Type: "[]*User"}, {Type: "error"}}},
+			{Name: "BadReturn", Query: "SELECT count(*) FROM users", Parameters: []ParameterInfo{ctxParam}, Returns: []ReturnInfo{{Type: "int"}, {Type: "error"}}},
+		} {
+			if _, err := gen.GenerateAnnotatedMethod(method, "User"); err == nil {
+				t.Errorf("Expected an error for %s", method.Name)
+			}
+		}
+	})
+}
+
+func TestIntegration_SchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	migrationsDir := filepath.Join(dir, "migrations")
+	os.MkdirAll(migrationsDir, 0755)
+	for _, name := range []string{"20240101000000_create_users.up.sql", "20240315093000_add_email.up.sql"} {
+		if err := os.WriteFile(filepath.Join(migrationsDir, name), []byte("SELECT 1;"), 0644); err != nil {
+			t.Fatalf("Failed to write migration: %v", err)
+		}
+	}
+	input := filepath.Join(dir, "user.go")
+	source := "package models\n\ntype User struct {\n\tID int64\n}\n\ntype UserRepository interface{}\n"
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserRepository"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	cfg.MigrationsDir = migrationsDir
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	for _, want := range []string{
+		"const UserRepositorySchemaVersion int64 = 20240315093000",
+		"core.RequireSchemaVersion(UserRepositorySchemaVersion)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+
+	templated, err := GenerateRepositoryCode(TemplateData{
+		PackageName:    "models",
+		EntityName:     "User",
+		RepositoryName: "UserRepository",
+		IDType:         "int64",
+		SchemaVersion:  20240315093000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	if !strings.Contains(templated, "core.RequireSchemaVersion(UserRepositorySchemaVersion)") {
+		t.Errorf("Expected the template to stamp the schema version, got:\n%s", templated)
+	}
+}
+
 // TestIntegration_GeneratedCodeStructure tests the structure of generated code
 func TestIntegration_GeneratedCodeStructure(t *testing.T) {
 	// This test verifies that generated code has the expected structure
@@ -358,3 +1303,172 @@ func generateRepositoryCodeForTest(pkgName, entityName string, customMethods []M
 	return buf.String(), nil
 }
 
+
+// tracingPlugin wraps every generated method in a tracing span
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string { return "tracing" }
+
+func (tracingPlugin) PreGenerate(ctx *PluginContext) error {
+	ctx.AddImport("go.opentelemetry.io/otel")
+	return nil
+}
+
+func (tracingPlugin) MethodBody(ctx *PluginContext, method MethodInfo, body string) (string, error) {
+	return "\tctx, span := otel.Tracer(\"" + ctx.RepositoryName + "\").Start(ctx, \"" + method.Name + "\")\n" +
+		"\tdefer span.End()\n" + body, nil
+}
+
+func (tracingPlugin) PostGenerate(ctx *PluginContext, code string) (string, error) {
+	return strings.Replace(code, "package ", "// Traced.\n\npackage ", 1), nil
+}
+
+// TestIntegration_Plugins tests that registered plugins rewrite generated
+// repositories
+func TestIntegration_Plugins(t *testing.T) {
+	RegisterPlugin(tracingPlugin{})
+	defer func() {
+		pluginsMu.Lock()
+		plugins = nil
+		pluginsMu.Unlock()
+	}()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "user.go")
+	source := `package models
+
+import "context"
+
+type User struct {
+	ID    int64  ` + "`db:\"id\" jet:\"primary_key\"`" + `
+	Email string ` + "`db:\"email\"`" + `
+}
+
+type UserQueries interface {
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	CountByEmail(ctx context.Context, email string) (int64, error)
+}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.EntityType = "User"
+	cfg.InterfaceName = "UserQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "user_repository_gen.go")
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, err := os.ReadFile(cfg.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"// Traced.\n\npackage models",
+		`"go.opentelemetry.io/otel"`,
+		`ctx, span := otel.Tracer("UserRepository").Start(ctx, "FindByEmail")`,
+		`ctx, span := otel.Tracer("UserRepository").Start(ctx, "CountByEmail")`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+	if n := strings.Count(code, "defer span.End()"); n != 2 {
+		t.Errorf("Expected 2 spans, got %d:\n%s", n, code)
+	}
+}
+
+// TestIntegration_InferredIDType tests that the repository's ID type follows
+// the entity's primary key
+func TestIntegration_InferredIDType(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "models.go")
+	source := `package models
+
+import "github.com/google/uuid"
+
+type Account struct {
+	Handle string ` + "`db:\"handle\" jet:\"primary_key\"`" + `
+	Name   string ` + "`db:\"name\"`" + `
+}
+
+type Order struct {
+	ID    uuid.UUID ` + "`db:\"id\" jet:\"primary_key,uuid\"`" + `
+	Total int64     ` + "`db:\"total\"`" + `
+}
+
+type LineKey struct {
+	OrderID int64
+	Line    int
+}
+
+type OrderLine struct {
+	Key LineKey ` + "`db:\"key\" jet:\"primary_key\"`" + `
+	SKU string  ` + "`db:\"sku\"`" + `
+}
+
+type AccountQueries interface{}
+type OrderQueries interface{}
+type OrderLineQueries interface{}
+`
+	if err := os.WriteFile(input, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	for _, tc := range []struct {
+		entity, idType string
+	}{
+		{"Account", "string"},
+		{"Order", "uuid.UUID"},
+		{"OrderLine", "LineKey"},
+	} {
+		cfg := DefaultConfig()
+		cfg.EntityType = tc.entity
+		cfg.InterfaceName = tc.entity + "Queries"
+		cfg.InputFile = input
+		cfg.OutputFile = filepath.Join(dir, toSnakeCase(tc.entity)+"_repository_gen.go")
+		if _, err := Generate(cfg); err != nil {
+			t.Fatalf("Generate %s failed: %v", tc.entity, err)
+		}
+		data, err := os.ReadFile(cfg.OutputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		code := string(data)
+		if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+			t.Fatalf("Generated code has syntax errors: %v\n%s", err, code)
+		}
+		for _, want := range []string{
+			"*core.BaseRepository[" + tc.entity + ", " + tc.idType + "]",
+			"core.NewBaseRepository[" + tc.entity + ", " + tc.idType + "](db)",
+		} {
+			if !strings.Contains(code, want) {
+				t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+			}
+		}
+		if tc.idType == "uuid.UUID" && !strings.Contains(code, `"github.com/google/uuid"`) {
+			t.Errorf("Expected the uuid import, got:\n%s", code)
+		}
+	}
+
+	// A configured ID type wins
+	cfg := DefaultConfig()
+	cfg.EntityType = "Account"
+	cfg.InterfaceName = "AccountQueries"
+	cfg.InputFile = input
+	cfg.OutputFile = filepath.Join(dir, "account_repository_gen.go")
+	cfg.IDType = "AccountHandle"
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	data, _ := os.ReadFile(cfg.OutputFile)
+	if !strings.Contains(string(data), "core.NewBaseRepository[Account, AccountHandle](db)") {
+		t.Errorf("Expected the configured ID type, got:\n%s", data)
+	}
+}
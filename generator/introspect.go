@@ -0,0 +1,588 @@
+package generator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableSchema is a table read from a database by IntrospectSchema
+type TableSchema struct {
+	Name    string
+	Columns []ColumnSchema
+}
+
+// ColumnSchema is a column of a TableSchema with the constraints and
+// indexes jet tags can express
+type ColumnSchema struct {
+	Name       string
+	DataType   string   // information_schema data_type, e.g. "character varying"
+	UDTName    string   // Underlying type name, e.g. "varchar", "_int8" or an enum's name
+	Nullable   bool     // NULL allowed
+	Default    string   // Default expression, "" if none
+	MaxLength  int      // Length of varchar and char columns
+	Precision  int      // Precision of numeric columns
+	Scale      int      // Scale of numeric columns
+	EnumValues []string // Labels of enum columns, in declaration order
+
+	PrimaryKey     bool
+	Unique         bool   // Single-column UNIQUE constraint
+	Index          string // Single-column index name
+	UniqueIndex    string // Single-column unique index name
+	CompositeIndex string // Multi-column index name
+	CompositeOrder int    // Position in CompositeIndex, from 1
+	ForeignKey     string // Referenced table.column of a single-column foreign key
+	OnDelete       string // Foreign key action: cascade, set_null, set_default or restrict
+	OnUpdate       string
+}
+
+// IntrospectConfig configures the generation of entities from a database
+type IntrospectConfig struct {
+	Schema     string   `json:"schema" yaml:"schema"`                               // Default public
+	Tables     []string `json:"tables,omitempty" yaml:"tables,omitempty"`           // Default every table except schema_migrations
+	Package    string   `json:"package,omitempty" yaml:"package,omitempty"`         // Default the output directory's name
+	OutputFile string   `json:"output_file,omitempty" yaml:"output_file,omitempty"` // Default entities.go
+}
+
+// GenerateEntities writes a Go entity struct for each table of the schema
+// and returns the path written. It is meant to adopt jetorm on an existing
+// database: the file is a starting point to edit, and is not regenerated.
+//
+// Structs are named after their tables, as jetorm derives table names from
+// struct names. Fields carry db tags and jet tags for primary keys, serial
+// and UUID keys, NOT NULL, defaults, unique constraints, indexes, single
+// column foreign keys with their actions, enums, and SQL types Go types do
+// not imply. Nullable columns become pointers. created_at, updated_at and
+// deleted_at timestamps become auto_now_add, auto_now and soft_delete.
+func GenerateEntities(ctx context.Context, db *sql.DB, cfg IntrospectConfig) (string, error) {
+	if cfg.Schema == "" {
+		cfg.Schema = "public"
+	}
+	if cfg.OutputFile == "" {
+		cfg.OutputFile = "entities.go"
+	}
+	if cfg.Package == "" {
+		cfg.Package = defaultPackageName(cfg.OutputFile)
+	}
+
+	tables, err := IntrospectSchema(ctx, db, cfg.Schema, cfg.Tables...)
+	if err != nil {
+		return "", err
+	}
+	if len(tables) == 0 {
+		return "", fmt.Errorf("no tables found in schema %s", cfg.Schema)
+	}
+
+	code, err := generateEntityCode(cfg.Package, cfg.Schema, tables)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(cfg.OutputFile, []byte(code), 0644); err != nil {
+		return "", fmt.Errorf("failed to write output file: %w", err)
+	}
+	return cfg.OutputFile, nil
+}
+
+// defaultPackageName returns the name of the directory of output, if it is
+// an identifier, or "models"
+func defaultPackageName(output string) string {
+	dir, err := filepath.Abs(filepath.Dir(output))
+	if err != nil {
+		return "models"
+	}
+	name := strings.ReplaceAll(filepath.Base(dir), "-", "_")
+	if !token.IsIdentifier(name) || token.IsKeyword(name) {
+		return "models"
+	}
+	return name
+}
+
+// IntrospectSchema reads the base tables of a PostgreSQL schema, or the named
+// ones, with their columns, constraints and plain column indexes. Without
+// names it skips the schema_migrations table.
+func IntrospectSchema(ctx context.Context, db *sql.DB, schema string, tables ...string) ([]TableSchema, error) {
+	wanted := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		wanted[table] = true
+	}
+	include := func(table string) bool {
+		if len(wanted) == 0 {
+			return table != "schema_migrations"
+		}
+		return wanted[table]
+	}
+
+	enums, err := introspectEnums(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.table_name, c.column_name, c.data_type, c.udt_name, c.is_nullable = 'YES',
+			COALESCE(c.column_default, ''), COALESCE(c.character_maximum_length, 0),
+			COALESCE(c.numeric_precision, 0), COALESCE(c.numeric_scale, 0)
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = $1 AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	var result []TableSchema
+	for rows.Next() {
+		var table string
+		var col ColumnSchema
+		if err := rows.Scan(&table, &col.Name, &col.DataType, &col.UDTName, &col.Nullable,
+			&col.Default, &col.MaxLength, &col.Precision, &col.Scale); err != nil {
+			return nil, fmt.Errorf("failed to read columns: %w", err)
+		}
+		if !include(table) {
+			continue
+		}
+		if col.DataType == "USER-DEFINED" {
+			col.EnumValues = enums[col.UDTName]
+		}
+		if len(result) == 0 || result[len(result)-1].Name != table {
+			result = append(result, TableSchema{Name: table})
+		}
+		t := &result[len(result)-1]
+		t.Columns = append(t.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	index := make(map[string]map[string]*ColumnSchema, len(result))
+	for i := range result {
+		index[result[i].Name] = make(map[string]*ColumnSchema, len(result[i].Columns))
+		for j := range result[i].Columns {
+			index[result[i].Name][result[i].Columns[j].Name] = &result[i].Columns[j]
+		}
+	}
+	column := func(table, name string) *ColumnSchema {
+		return index[table][name]
+	}
+
+	if err := introspectConstraints(ctx, db, schema, column); err != nil {
+		return nil, err
+	}
+	if err := introspectIndexes(ctx, db, schema, column); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// introspectEnums returns the labels of every enum type by type name
+func introspectEnums(ctx context.Context, db *sql.DB) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.typname, e.enumlabel
+		FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		ORDER BY t.typname, e.enumsortorder`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read enum types: %w", err)
+	}
+	defer rows.Close()
+
+	enums := make(map[string][]string)
+	for rows.Next() {
+		var name, label string
+		if err := rows.Scan(&name, &label); err != nil {
+			return nil, fmt.Errorf("failed to read enum types: %w", err)
+		}
+		enums[name] = append(enums[name], label)
+	}
+	return enums, rows.Err()
+}
+
+// introspectConstraints records primary keys, single-column unique
+// constraints and single-column foreign keys on the columns
+func introspectConstraints(ctx context.Context, db *sql.DB, schema string, column func(table, name string) *ColumnSchema) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT cl.relname, a.attname, c.contype::text, cardinality(c.conkey),
+			COALESCE(rt.relname, ''), COALESCE(ra.attname, ''),
+			c.confdeltype::text, c.confupdtype::text
+		FROM pg_constraint c
+		JOIN pg_class cl ON cl.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = cl.relnamespace
+		CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		LEFT JOIN pg_class rt ON rt.oid = c.confrelid
+		LEFT JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = c.confkey[k.ord]
+		WHERE n.nspname = $1 AND c.contype IN ('p', 'u', 'f')
+		ORDER BY cl.relname, c.conname, k.ord`, schema)
+	if err != nil {
+		return fmt.Errorf("failed to read constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, name, kind, refTable, refColumn, onDelete, onUpdate string
+		var columns int
+		if err := rows.Scan(&table, &name, &kind, &columns, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return fmt.Errorf("failed to read constraints: %w", err)
+		}
+		col := column(table, name)
+		if col == nil {
+			continue
+		}
+		switch {
+		case kind == "p":
+			col.PrimaryKey = true
+		case kind == "u" && columns == 1:
+			col.Unique = true
+		case kind == "f" && columns == 1:
+			col.ForeignKey = refTable + "." + refColumn
+			col.OnDelete = foreignKeyAction(onDelete)
+			col.OnUpdate = foreignKeyAction(onUpdate)
+		}
+	}
+	return rows.Err()
+}
+
+// foreignKeyAction maps pg_constraint's action codes to jet tag values; the
+// default, no action, maps to ""
+func foreignKeyAction(code string) string {
+	switch code {
+	case "c":
+		return "cascade"
+	case "n":
+		return "set_null"
+	case "d":
+		return "set_default"
+	case "r":
+		return "restrict"
+	}
+	return ""
+}
+
+// introspectIndexes records the indexes over plain columns that do not back
+// a constraint; expression and partial indexes have no jet tag
+func introspectIndexes(ctx context.Context, db *sql.DB, schema string, column func(table, name string) *ColumnSchema) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.relname, i.relname, ix.indisunique, a.attname, k.ord::int, ix.indnatts::int
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1 AND ix.indexprs IS NULL AND ix.indpred IS NULL
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = ix.indexrelid)
+		ORDER BY t.relname, i.relname, k.ord`, schema)
+	if err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, index, name string
+		var unique bool
+		var order, columns int
+		if err := rows.Scan(&table, &index, &unique, &name, &order, &columns); err != nil {
+			return fmt.Errorf("failed to read indexes: %w", err)
+		}
+		col := column(table, name)
+		switch {
+		case col == nil:
+		case columns > 1:
+			col.CompositeIndex, col.CompositeOrder = index, order
+		case unique:
+			col.UniqueIndex = index
+		default:
+			col.Index = index
+		}
+	}
+	return rows.Err()
+}
+
+// columnType is the Go type of a column and the SQL type the schema
+// generator derives from it
+type columnType struct {
+	goType  string
+	imports []string
+	derived string // SQL type of goType without a type tag, "" if none matches
+}
+
+// columnTypes maps udt names to Go types
+var columnTypes = map[string]columnType{
+	"int8":        {goType: "int64", derived: "int8"},
+	"int4":        {goType: "int32"},
+	"int2":        {goType: "int16"},
+	"float8":      {goType: "float64", derived: "float8"},
+	"float4":      {goType: "float32", derived: "float4"},
+	"numeric":     {goType: "float64"},
+	"bool":        {goType: "bool", derived: "bool"},
+	"text":        {goType: "string", derived: "text"},
+	"varchar":     {goType: "string"},
+	"bpchar":      {goType: "string"},
+	"bytea":       {goType: "[]byte", derived: "bytea"},
+	"uuid":        {goType: "string"},
+	"timestamp":   {goType: "time.Time", imports: []string{"time"}, derived: "timestamp"},
+	"timestamptz": {goType: "time.Time", imports: []string{"time"}},
+	"date":        {goType: "time.Time", imports: []string{"time"}},
+	"time":        {goType: "time.Time", imports: []string{"time"}},
+	"interval":    {goType: "pgtype.Interval", imports: []string{"github.com/jackc/pgx/v5/pgtype"}, derived: "interval"},
+	"json":        {goType: "json.RawMessage", imports: []string{"encoding/json"}},
+	"jsonb":       {goType: "json.RawMessage", imports: []string{"encoding/json"}},
+	"inet":        {goType: "netip.Addr", imports: []string{"net/netip"}, derived: "inet"},
+	"cidr":        {goType: "netip.Prefix", imports: []string{"net/netip"}, derived: "cidr"},
+}
+
+// arrayTypes maps udt names of array columns to Go element types
+var arrayTypes = map[string]string{
+	"_int8": "int64", "_int4": "int32", "_int2": "int16",
+	"_float8": "float64", "_float4": "float32",
+	"_text": "string", "_varchar": "string", "_bool": "bool", "_uuid": "string",
+}
+
+// goField returns the Go type of a column, the imports it needs and the
+// type tag value it needs, if any
+func goField(col ColumnSchema) (string, []string, string) {
+	if col.DataType == "USER-DEFINED" && len(col.EnumValues) > 0 {
+		return nullable("string", col.Nullable), nil, ""
+	}
+	if elem, ok := arrayTypes[col.UDTName]; ok {
+		return "[]" + elem, nil, sqlTypeName(strings.TrimPrefix(col.UDTName, "_")) + "[]"
+	}
+
+	ct, ok := columnTypes[col.UDTName]
+	if !ok {
+		// Types pgx scans as text, such as money, xml or citext
+		if col.DataType == "USER-DEFINED" {
+			return nullable("string", col.Nullable), nil, col.UDTName
+		}
+		return nullable("string", col.Nullable), nil, col.DataType
+	}
+
+	typeTag := ""
+	switch {
+	case col.UDTName == "varchar" && col.MaxLength > 0:
+		// Expressed by the size tag
+	case col.UDTName == "bpchar" && col.MaxLength > 0:
+		typeTag = fmt.Sprintf("char(%d)", col.MaxLength)
+	case col.UDTName == "numeric" && col.Precision > 0:
+		typeTag = fmt.Sprintf("numeric(%d,%d)", col.Precision, col.Scale)
+	case col.UDTName == "uuid" && isUUIDDefault(col.Default):
+		// Expressed by the uuid tag
+	case ct.derived == "":
+		typeTag = sqlTypeName(col.UDTName)
+	}
+
+	goType := ct.goType
+	if !strings.HasPrefix(goType, "[]") && goType != "json.RawMessage" {
+		goType = nullable(goType, col.Nullable)
+	}
+	return goType, ct.imports, typeTag
+}
+
+// sqlTypeName returns the SQL name of a udt name, e.g. integer for int4
+func sqlTypeName(udtName string) string {
+	switch udtName {
+	case "int2":
+		return "smallint"
+	case "int4":
+		return "integer"
+	case "int8":
+		return "bigint"
+	case "float4":
+		return "real"
+	case "float8":
+		return "double precision"
+	case "bool":
+		return "boolean"
+	}
+	return udtName
+}
+
+func nullable(goType string, isNullable bool) string {
+	if isNullable {
+		return "*" + goType
+	}
+	return goType
+}
+
+var (
+	serialDefault = regexp.MustCompile(`^nextval\(.*\)$`)
+	uuidDefault   = regexp.MustCompile(`^(gen_random_uuid|uuid_generate_v4)\(\)$`)
+	nowDefault    = regexp.MustCompile(`^(now\(\)|CURRENT_TIMESTAMP)$`)
+	// castLiteral matches a quoted literal with a type cast, e.g. 'a'::text
+	castLiteral = regexp.MustCompile(`^('(?:[^']|'')*')::[\w ."]+(\[\])?$`)
+)
+
+func isUUIDDefault(expr string) bool {
+	return uuidDefault.MatchString(expr)
+}
+
+// entityField returns the Go type of a column, the imports it needs and its
+// jet tag options
+func entityField(col ColumnSchema) (string, []string, []string) {
+	goType, imports, typeTag := goField(col)
+	isTime := strings.HasSuffix(goType, "time.Time")
+	isInteger := strings.HasPrefix(strings.TrimPrefix(goType, "*"), "int")
+	def := col.Default
+
+	var opts []string
+	if col.PrimaryKey {
+		opts = append(opts, "primary_key")
+	}
+	switch {
+	case isInteger && serialDefault.MatchString(def):
+		opts = append(opts, "auto_increment")
+		def = ""
+	case col.UDTName == "uuid" && isUUIDDefault(def):
+		opts = append(opts, "uuid:db")
+		def = ""
+	case isTime && col.Name == "created_at" && (def == "" || nowDefault.MatchString(def)):
+		opts = append(opts, "auto_now_add")
+		def = ""
+	case isTime && col.Name == "updated_at" && (def == "" || nowDefault.MatchString(def)):
+		opts = append(opts, "auto_now")
+		def = ""
+	case isTime && col.Name == "deleted_at" && col.Nullable && def == "":
+		opts = append(opts, "soft_delete")
+	}
+	if typeTag != "" {
+		opts = append(opts, "type:"+typeTag)
+	}
+	if col.UDTName == "varchar" && col.MaxLength > 0 {
+		opts = append(opts, "size:"+strconv.Itoa(col.MaxLength))
+	}
+	if len(col.EnumValues) > 0 {
+		opts = append(opts, fmt.Sprintf("enum:%s(%s)", col.UDTName, strings.Join(col.EnumValues, ",")))
+	}
+	if !col.Nullable && !col.PrimaryKey {
+		opts = append(opts, "not_null")
+	}
+	if col.Unique && !col.PrimaryKey {
+		opts = append(opts, "unique")
+	}
+	if def != "" && !strings.Contains(def, "`") {
+		if m := castLiteral.FindStringSubmatch(def); m != nil && m[2] == "" {
+			def = m[1]
+		}
+		opts = append(opts, "default:"+def)
+	}
+	if col.Index != "" {
+		opts = append(opts, "index:"+col.Index)
+	}
+	if col.UniqueIndex != "" {
+		opts = append(opts, "unique_index:"+col.UniqueIndex)
+	}
+	if col.CompositeIndex != "" {
+		opts = append(opts, fmt.Sprintf("composite_index:%s:%d", col.CompositeIndex, col.CompositeOrder))
+	}
+	if col.ForeignKey != "" {
+		opts = append(opts, "foreign_key:"+col.ForeignKey)
+		if col.OnDelete != "" {
+			opts = append(opts, "on_delete:"+col.OnDelete)
+		}
+		if col.OnUpdate != "" {
+			opts = append(opts, "on_update:"+col.OnUpdate)
+		}
+	}
+	return goType, imports, opts
+}
+
+// goInitialisms are the column name parts exported in upper case
+var goInitialisms = map[string]bool{
+	"id": true, "uuid": true, "url": true, "uri": true, "ip": true, "http": true,
+	"api": true, "json": true, "sql": true, "html": true, "sku": true,
+}
+
+// exportedName returns the Go field name of a column, e.g. UserID for user_id
+func exportedName(column string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(column, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		if goInitialisms[strings.ToLower(part)] {
+			sb.WriteString(strings.ToUpper(part))
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := sb.String()
+	if name == "" || !token.IsIdentifier(name) || name[0] < 'A' || name[0] > 'Z' {
+		name = "X" + name
+	}
+	return name
+}
+
+// entityName returns the struct name jetorm maps to table, e.g. OrderItems
+// for order_items
+func entityName(table string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(table, "_") {
+		if part != "" {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
+
+// generateEntityCode generates the entity structs of the tables
+func generateEntityCode(pkgName, schema string, tables []TableSchema) (string, error) {
+	imports := make(map[string]bool)
+	var body strings.Builder
+	for _, table := range tables {
+		name := entityName(table.Name)
+		fmt.Fprintf(&body, "\n// %s is a row of the %s table\n", name, table.Name)
+		if !token.IsIdentifier(name) || toSnakeCase(name) != table.Name {
+			fmt.Fprintf(&body, "//\n// jetorm maps %s to table %s; rename the struct or the table.\n", name, toSnakeCase(name))
+		}
+		fmt.Fprintf(&body, "type %s struct {\n", name)
+		for _, col := range table.Columns {
+			goType, colImports, opts := entityField(col)
+			for _, path := range colImports {
+				imports[path] = true
+			}
+			tag := "db:" + strconv.Quote(col.Name)
+			if len(opts) > 0 {
+				tag += " jet:" + strconv.Quote(strings.Join(opts, ","))
+			}
+			fmt.Fprintf(&body, "\t%s %s `%s`\n", exportedName(col.Name), goType, tag)
+		}
+		body.WriteString("}\n")
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// Generated by jetorm-gen introspect from schema %s; edit as needed.\n\npackage %s\n", schema, pkgName)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		// Standard library first
+		sort.Slice(paths, func(i, j int) bool {
+			si, sj := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+			if si != sj {
+				return si
+			}
+			return paths[i] < paths[j]
+		})
+		buf.WriteString("\nimport (\n")
+		for i, path := range paths {
+			if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(path, ".") {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n")
+	}
+	buf.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(buf.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format entities: %w", err)
+	}
+	return string(formatted), nil
+}
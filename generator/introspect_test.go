@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateEntityCode(t *testing.T) {
+	tables := []TableSchema{
+		{Name: "customers", Columns: []ColumnSchema{
+			{Name: "id", DataType: "uuid", UDTName: "uuid", Default: "gen_random_uuid()", PrimaryKey: true},
+			{Name: "email", DataType: "character varying", UDTName: "varchar", MaxLength: 255, Unique: true},
+			{Name: "api_key", DataType: "text", UDTName: "text", Nullable: true, UniqueIndex: "customers_api_key_idx"},
+		}},
+		{Name: "order_items", Columns: []ColumnSchema{
+			{Name: "id", DataType: "bigint", UDTName: "int8", Default: "nextval('order_items_id_seq'::regclass)", PrimaryKey: true},
+			{Name: "customer_id", DataType: "uuid", UDTName: "uuid", ForeignKey: "customers.id", OnDelete: "cascade", CompositeIndex: "order_items_customer_sku_idx", CompositeOrder: 1},
+			{Name: "sku", DataType: "text", UDTName: "text", CompositeIndex: "order_items_customer_sku_idx", CompositeOrder: 2},
+			{Name: "quantity", DataType: "integer", UDTName: "int4", Default: "1"},
+			{Name: "price", DataType: "numeric", UDTName: "numeric", Precision: 10, Scale: 2, Nullable: true},
+			{Name: "status", DataType: "USER-DEFINED", UDTName: "item_status", Default: "'open'::item_status", EnumValues: []string{"open", "shipped"}},
+			{Name: "tags", DataType: "ARRAY", UDTName: "_text", Nullable: true},
+			{Name: "attributes", DataType: "jsonb", UDTName: "jsonb", Default: "'{}'::jsonb"},
+			{Name: "note", DataType: "character varying", UDTName: "varchar", MaxLength: 255, Nullable: true, Default: "'n/a'::character varying", Index: "order_items_note_idx"},
+			{Name: "created_at", DataType: "timestamp with time zone", UDTName: "timestamptz", Default: "now()"},
+			{Name: "updated_at", DataType: "timestamp without time zone", UDTName: "timestamp"},
+			{Name: "deleted_at", DataType: "timestamp without time zone", UDTName: "timestamp", Nullable: true},
+		}},
+	}
+
+	code, err := generateEntityCode("models", "public", tables)
+	if err != nil {
+		t.Fatalf("generateEntityCode failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"package models",
+		"\"encoding/json\"\n\t\"time\"\n)",
+		"type Customers struct {",
+		"ID     string  `db:\"id\" jet:\"primary_key,uuid:db\"`",
+		"Email  string  `db:\"email\" jet:\"size:255,not_null,unique\"`",
+		"APIKey *string `db:\"api_key\" jet:\"unique_index:customers_api_key_idx\"`",
+		"type OrderItems struct {",
+		"`db:\"id\" jet:\"primary_key,auto_increment\"`",
+		"CustomerID string",
+		"`db:\"customer_id\" jet:\"type:uuid,not_null,composite_index:order_items_customer_sku_idx:1,foreign_key:customers.id,on_delete:cascade\"`",
+		"`db:\"quantity\" jet:\"type:integer,not_null,default:1\"`",
+		"Price      *float64",
+		"`db:\"price\" jet:\"type:numeric(10,2)\"`",
+		"`db:\"status\" jet:\"enum:item_status(open,shipped),not_null,default:'open'\"`",
+		"Tags       []string",
+		"`db:\"tags\" jet:\"type:text[]\"`",
+		"Attributes json.RawMessage",
+		"`db:\"attributes\" jet:\"type:jsonb,not_null,default:'{}'\"`",
+		"`db:\"note\" jet:\"size:255,default:'n/a',index:order_items_note_idx\"`",
+		"CreatedAt  time.Time",
+		"`db:\"created_at\" jet:\"auto_now_add,type:timestamptz,not_null\"`",
+		"`db:\"updated_at\" jet:\"auto_now,not_null\"`",
+		"DeletedAt  *time.Time",
+		"`db:\"deleted_at\" jet:\"soft_delete\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestEntityName(t *testing.T) {
+	for table, want := range map[string]string{
+		"users":       "Users",
+		"order_items": "OrderItems",
+		"user2fa":     "User2fa",
+	} {
+		if got := entityName(table); got != want {
+			t.Errorf("entityName(%q) = %q, want %q", table, got, want)
+		}
+		if got := toSnakeCase(entityName(table)); got != table {
+			t.Errorf("toSnakeCase(entityName(%q)) = %q", table, got)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JetTableFile returns the path of the Jet table generated for a table
+func JetTableFile(dir, table string) string {
+	return filepath.Join(dir, table+".go")
+}
+
+// jetTablesPackage returns the package name of a Jet table directory: its
+// base name, as with Jet's own "table" package
+func jetTablesPackage(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "table"
+	}
+	name := strings.ReplaceAll(filepath.Base(abs), "-", "_")
+	if !token.IsIdentifier(name) || token.IsKeyword(name) {
+		return "table"
+	}
+	return name
+}
+
+// jetSQLTypes maps SQL types, as given to jet:"type:...", to Jet column types
+var jetSQLTypes = map[string]string{
+	"bool": "Bool", "boolean": "Bool",
+	"smallint": "Integer", "integer": "Integer", "int": "Integer", "bigint": "Integer",
+	"int2": "Integer", "int4": "Integer", "int8": "Integer",
+	"smallserial": "Integer", "serial": "Integer", "bigserial": "Integer",
+	"real": "Float", "double precision": "Float", "float4": "Float", "float8": "Float",
+	"numeric": "Float", "decimal": "Float",
+	"date":                        "Date",
+	"time":                        "Time",
+	"time without time zone":      "Time",
+	"timetz":                      "Timez",
+	"time with time zone":         "Timez",
+	"timestamp":                   "Timestamp",
+	"timestamp without time zone": "Timestamp",
+	"timestamptz":                 "Timestampz",
+	"timestamp with time zone":    "Timestampz",
+	"interval":                    "Interval",
+	"bytea":                       "Bytea",
+}
+
+// jetNamedTypes maps nullable and driver types to Jet column types
+var jetNamedTypes = map[string]string{
+	"time.Time":                                  "Timestamp",
+	"database/sql.NullString":                    "String",
+	"database/sql.NullBool":                      "Bool",
+	"database/sql.NullByte":                      "Integer",
+	"database/sql.NullInt16":                     "Integer",
+	"database/sql.NullInt32":                     "Integer",
+	"database/sql.NullInt64":                     "Integer",
+	"database/sql.NullFloat64":                   "Float",
+	"database/sql.NullTime":                      "Timestamp",
+	"github.com/jackc/pgx/v5/pgtype.Bool":        "Bool",
+	"github.com/jackc/pgx/v5/pgtype.Int2":        "Integer",
+	"github.com/jackc/pgx/v5/pgtype.Int4":        "Integer",
+	"github.com/jackc/pgx/v5/pgtype.Int8":        "Integer",
+	"github.com/jackc/pgx/v5/pgtype.Float4":      "Float",
+	"github.com/jackc/pgx/v5/pgtype.Float8":      "Float",
+	"github.com/jackc/pgx/v5/pgtype.Numeric":     "Float",
+	"github.com/jackc/pgx/v5/pgtype.Date":        "Date",
+	"github.com/jackc/pgx/v5/pgtype.Time":        "Time",
+	"github.com/jackc/pgx/v5/pgtype.Timestamp":   "Timestamp",
+	"github.com/jackc/pgx/v5/pgtype.Timestamptz": "Timestampz",
+	"github.com/jackc/pgx/v5/pgtype.Interval":    "Interval",
+}
+
+// jetColumnType returns the Jet column type of a field: Bool, Integer,
+// Float, String, Date, Time, Timez, Timestamp, Timestampz, Interval or Bytea
+func jetColumnType(field FieldInfo) string {
+	if sqlType, ok := field.Tags["type"]; ok {
+		sqlType = strings.ToLower(strings.TrimSpace(sqlType))
+		if i := strings.Index(sqlType, "("); i >= 0 {
+			sqlType = strings.TrimSpace(sqlType[:i])
+		}
+		if columnType, ok := jetSQLTypes[sqlType]; ok {
+			return columnType
+		}
+		return "String"
+	}
+	if _, ok := field.Tags["enum"]; ok {
+		return "String"
+	}
+	if _, ok := field.Tags["uuid"]; ok {
+		return "String"
+	}
+
+	t := field.Type
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+		if columnType, ok := jetNamedTypes[named.Obj().Pkg().Path()+"."+named.Obj().Name()]; ok {
+			return columnType
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "Bool"
+		case u.Info()&types.IsInteger != 0:
+			return "Integer"
+		case u.Info()&types.IsFloat != 0:
+			return "Float"
+		}
+	case *types.Slice:
+		if basic, ok := u.Elem().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return "Bytea"
+		}
+	}
+	// Strings, UUIDs and JSON columns
+	return "String"
+}
+
+// hasDatabaseDefault reports whether the database fills the field's column
+// when an INSERT omits it
+func hasDatabaseDefault(field FieldInfo) bool {
+	_, hasDefault := field.Tags["default"]
+	return field.IsAutoInc || hasDefault || field.Tags["uuid"] == "db"
+}
+
+// generateJetTableCode generates the Jet table of an entity, in the form
+// Jet's generator emits for a database table: a <Table> variable of type
+// *<Table>Table with a column field per entity column, AllColumns,
+// MutableColumns (all but the primary key) and DefaultColumns (those the
+// database fills), AS, FromSchema, WithPrefix and WithSuffix, and EXCLUDED
+// for ON CONFLICT updates. The table is not schema-qualified.
+func generateJetTableCode(pkgName string, entity *EntityTypeInfo) (string, error) {
+	if len(entity.Fields) == 0 {
+		return "", fmt.Errorf("entity %s has no columns", entity.Name)
+	}
+
+	typeName := entityName(entity.TableName) + "Table"
+	implName := strings.ToLower(typeName[:1]) + typeName[1:]
+
+	var fields, vars, assigns strings.Builder
+	var all, mutable, defaults []string
+	for _, field := range entity.Fields {
+		name := exportedName(field.DBName)
+		columnType := jetColumnType(field)
+		fmt.Fprintf(&fields, "\t%s postgres.Column%s\n", name, columnType)
+		fmt.Fprintf(&vars, "\t\t%sColumn = postgres.%sColumn(%q)\n", name, columnType, field.DBName)
+		fmt.Fprintf(&assigns, "\t\t%s: %sColumn,\n", name, name)
+
+		all = append(all, name+"Column")
+		if !field.IsPrimaryKey {
+			mutable = append(mutable, name+"Column")
+		}
+		if hasDatabaseDefault(field) {
+			defaults = append(defaults, name+"Column")
+		}
+	}
+
+	code := fmt.Sprintf(`// Code generated by jetorm-gen from %[1]s. DO NOT EDIT.
+
+package %[2]s
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+var %[3]s = new%[4]s("", %[5]q, "")
+
+type %[6]s struct {
+	postgres.Table
+
+	// Columns
+%[7]s
+	AllColumns     postgres.ColumnList
+	MutableColumns postgres.ColumnList
+	DefaultColumns postgres.ColumnList
+}
+
+type %[4]s struct {
+	%[6]s
+
+	EXCLUDED %[6]s
+}
+
+// AS creates new %[4]s with assigned alias
+func (a %[4]s) AS(alias string) *%[4]s {
+	return new%[4]s(a.SchemaName(), a.TableName(), alias)
+}
+
+// FromSchema creates new %[4]s with assigned schema name
+func (a %[4]s) FromSchema(schemaName string) *%[4]s {
+	return new%[4]s(schemaName, a.TableName(), a.Alias())
+}
+
+// WithPrefix creates new %[4]s with assigned table prefix
+func (a %[4]s) WithPrefix(prefix string) *%[4]s {
+	return new%[4]s(a.SchemaName(), prefix+a.TableName(), a.TableName())
+}
+
+// WithSuffix creates new %[4]s with assigned table suffix
+func (a %[4]s) WithSuffix(suffix string) *%[4]s {
+	return new%[4]s(a.SchemaName(), a.TableName()+suffix, a.TableName())
+}
+
+func new%[4]s(schemaName, tableName, alias string) *%[4]s {
+	return &%[4]s{
+		%[6]s: new%[4]sImpl(schemaName, tableName, alias),
+		EXCLUDED: new%[4]sImpl("", "excluded", ""),
+	}
+}
+
+func new%[4]sImpl(schemaName, tableName, alias string) %[6]s {
+	var (
+%[8]s		allColumns     = postgres.ColumnList{%[9]s}
+		mutableColumns = postgres.ColumnList{%[10]s}
+		defaultColumns = postgres.ColumnList{%[11]s}
+	)
+
+	return %[6]s{
+		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
+
+		// Columns
+%[12]s
+		AllColumns:     allColumns,
+		MutableColumns: mutableColumns,
+		DefaultColumns: defaultColumns,
+	}
+}
+`, entity.Name, pkgName, entityName(entity.TableName), typeName, entity.TableName, implName,
+		fields.String(), vars.String(), strings.Join(all, ", "), strings.Join(mutable, ", "),
+		strings.Join(defaults, ", "), assigns.String())
+
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return "", fmt.Errorf("failed to format Jet table: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// writeJetTable writes the Jet table of an entity to dir and returns its path
+func writeJetTable(dir string, entity *EntityTypeInfo) (string, error) {
+	code, err := generateJetTableCode(jetTablesPackage(dir), entity)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create Jet table directory: %w", err)
+	}
+	path := JetTableFile(dir, entity.TableName)
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Jet table: %w", err)
+	}
+	return path, nil
+}
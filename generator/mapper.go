@@ -0,0 +1,509 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MapperConfig configures the generation of mapping functions between an
+// entity and an API model declared in the same package
+type MapperConfig struct {
+	Dir        string `json:"dir" yaml:"dir"`                                     // Package directory declaring both structs
+	Entity     string `json:"entity" yaml:"entity"`                               // Entity struct name
+	Model      string `json:"model" yaml:"model"`                                 // API model struct name
+	OutputFile string `json:"output_file,omitempty" yaml:"output_file,omitempty"` // Default <entity>_<model>_mapper_gen.go in Dir
+}
+
+// Validate validates the mapper configuration
+func (c *MapperConfig) Validate() error {
+	if c.Entity == "" {
+		return fmt.Errorf("entity is required")
+	}
+	if c.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if c.Entity == c.Model {
+		return fmt.Errorf("entity and model must be different structs")
+	}
+	return nil
+}
+
+// outputFile returns the configured output path or the default one
+func (c *MapperConfig) outputFile() string {
+	if c.OutputFile != "" {
+		return c.OutputFile
+	}
+	name := fmt.Sprintf("%s_%s_mapper_gen.go", toSnakeCase(c.Entity), toSnakeCase(c.Model))
+	return filepath.Join(c.Dir, name)
+}
+
+// GenerateMappers writes <Entity>To<Model> and <Model>To<Entity> functions,
+// plus ...Slice variants for []*T, and returns the path written.
+//
+// Model fields are matched to entity fields of the same name, or to the
+// entity field named by a map:"Name" tag on the model field; map:"-" skips a
+// field. Values and pointers of the same type convert into each other (a nil
+// pointer leaves the zero value), as do sql.Null* types and their value or
+// pointer types. Fields whose types are other structs of the package, or
+// pointers and slices of them, are mapped with generated functions for that
+// pair too, so nested relations convert recursively. Target fields without a
+// matching source are listed in a comment of the function.
+func GenerateMappers(cfg MapperConfig) (string, error) {
+	if err := cfg.Validate(); err != nil {
+		return "", err
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	pkg, err := parsePackageStructs(cfg.Dir)
+	if err != nil {
+		return "", err
+	}
+	code, err := generateMapperCode(pkg, cfg.Entity, cfg.Model)
+	if err != nil {
+		return "", err
+	}
+	output := cfg.outputFile()
+	if err := os.WriteFile(output, []byte(code), 0644); err != nil {
+		return "", fmt.Errorf("failed to write output file: %w", err)
+	}
+	return output, nil
+}
+
+// packageStructs indexes the struct types of a package's non-test files
+type packageStructs struct {
+	name    string
+	fset    *token.FileSet
+	structs map[string]declaredStruct
+}
+
+// parsePackageStructs parses the non-test Go files in dir
+func parsePackageStructs(dir string) (*packageStructs, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	result := &packageStructs{fset: fset, structs: make(map[string]declaredStruct)}
+	for name, pkg := range pkgs {
+		result.name = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						result.structs[ts.Name.Name] = declaredStruct{st: st, file: file}
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// typeRef is a type expression with the file it appears in, which resolves
+// the package names it uses
+type typeRef struct {
+	expr ast.Expr
+	file *ast.File
+}
+
+// mapperField is an exported field of a struct, including promoted fields of
+// embedded structs declared in the package
+type mapperField struct {
+	name string
+	typ  typeRef
+	tag  reflect.StructTag
+}
+
+// fields lists the exported fields of the named struct. Direct fields shadow
+// promoted ones, as in Go.
+func (p *packageStructs) fields(name string) []mapperField {
+	var result []mapperField
+	seen := make(map[string]bool)
+	level := []string{name}
+	visited := map[string]bool{name: true}
+	for len(level) > 0 {
+		var next []string
+		for _, structName := range level {
+			decl := p.structs[structName]
+			for _, field := range decl.st.Fields.List {
+				tag := reflect.StructTag("")
+				if field.Tag != nil {
+					if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+						tag = reflect.StructTag(unquoted)
+					}
+				}
+				if len(field.Names) == 0 {
+					if ident, ok := field.Type.(*ast.Ident); ok {
+						if _, ok := p.structs[ident.Name]; ok && !visited[ident.Name] {
+							visited[ident.Name] = true
+							next = append(next, ident.Name)
+						}
+					}
+					continue
+				}
+				for _, ident := range field.Names {
+					if !ident.IsExported() || seen[ident.Name] {
+						continue
+					}
+					seen[ident.Name] = true
+					result = append(result, mapperField{name: ident.Name, typ: typeRef{expr: field.Type, file: decl.file}, tag: tag})
+				}
+			}
+		}
+		level = next
+	}
+	return result
+}
+
+// mapperPair is an entity struct and the model struct it maps to
+type mapperPair struct {
+	entity, model string
+}
+
+// sqlNullTypes maps database/sql null types to their value type and field
+var sqlNullTypes = map[string][2]string{
+	"NullString":  {"string", "String"},
+	"NullBool":    {"bool", "Bool"},
+	"NullByte":    {"byte", "Byte"},
+	"NullInt16":   {"int16", "Int16"},
+	"NullInt32":   {"int32", "Int32"},
+	"NullInt64":   {"int64", "Int64"},
+	"NullFloat64": {"float64", "Float64"},
+	"NullTime":    {"time.Time", "Time"},
+}
+
+type mapperGen struct {
+	pkg     *packageStructs
+	buf     strings.Builder
+	imports map[string]string // import path -> name used
+	queue   []mapperPair
+	queued  map[mapperPair]bool
+	vars    int
+}
+
+// generateMapperCode emits the mapping functions of the pair and of every
+// nested pair reachable from it
+func generateMapperCode(pkg *packageStructs, entity, model string) (string, error) {
+	for _, name := range []string{entity, model} {
+		if _, ok := pkg.structs[name]; !ok {
+			return "", fmt.Errorf("struct %s not found in package %s", name, pkg.name)
+		}
+	}
+
+	g := &mapperGen{pkg: pkg, imports: make(map[string]string), queued: make(map[mapperPair]bool)}
+	g.enqueue(mapperPair{entity: entity, model: model})
+	for i := 0; i < len(g.queue); i++ {
+		pair := g.queue[i]
+		g.writeFunc(pair.entity, pair.model, true)
+		g.writeFunc(pair.model, pair.entity, false)
+		g.writeSliceFunc(pair.entity, pair.model)
+		g.writeSliceFunc(pair.model, pair.entity)
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by jetorm-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n", pkg.name)
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for importPath := range g.imports {
+			paths = append(paths, importPath)
+		}
+		sort.Strings(paths)
+		out.WriteString("\nimport (\n")
+		for _, importPath := range paths {
+			spec := strconv.Quote(importPath)
+			if name := g.imports[importPath]; defaultImportName(importPath) != name {
+				spec = name + " " + spec
+			}
+			fmt.Fprintf(&out, "\t%s\n", spec)
+		}
+		out.WriteString(")\n")
+	}
+	out.WriteString(g.buf.String())
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated mappers: %w", err)
+	}
+	return string(formatted), nil
+}
+
+func (g *mapperGen) enqueue(pair mapperPair) {
+	if !g.queued[pair] {
+		g.queued[pair] = true
+		g.queue = append(g.queue, pair)
+	}
+}
+
+func funcName(src, dst string) string {
+	return src + "To" + dst
+}
+
+// writeFunc emits the function mapping *src to *dst. forward is true when
+// src is the pair's entity.
+func (g *mapperGen) writeFunc(src, dst string, forward bool) {
+	name := funcName(src, dst)
+	fmt.Fprintf(&g.buf, "\n// %s maps *%s to *%s, returning nil for nil.\n", name, src, dst)
+
+	// Pair each target field with its source through the model's map tags
+	srcFields := make(map[string]mapperField)
+	for _, f := range g.pkg.fields(src) {
+		srcFields[g.matchName(f, !forward)] = f
+	}
+
+	var body strings.Builder
+	var unmapped []string
+	for _, f := range g.pkg.fields(dst) {
+		key := g.matchName(f, forward)
+		if key == "-" {
+			continue
+		}
+		from, ok := srcFields[key]
+		if !ok {
+			unmapped = append(unmapped, f.name)
+			continue
+		}
+		if !g.assign(&body, "\t", "dst."+f.name, "src."+from.name, f.typ, from.typ, forward) {
+			unmapped = append(unmapped, fmt.Sprintf("%s (%s from %s)", f.name, g.typeString(f.typ), g.typeString(from.typ)))
+		}
+	}
+
+	if len(unmapped) > 0 {
+		fmt.Fprintf(&g.buf, "//\n// Not mapped: %s.\n", strings.Join(unmapped, ", "))
+	}
+	fmt.Fprintf(&g.buf, "func %s(src *%s) *%s {\n\tif src == nil {\n\t\treturn nil\n\t}\n\tdst := &%s{}\n", name, src, dst, dst)
+	g.buf.WriteString(body.String())
+	g.buf.WriteString("\treturn dst\n}\n")
+}
+
+// writeSliceFunc emits the function mapping []*src to []*dst
+func (g *mapperGen) writeSliceFunc(src, dst string) {
+	name := funcName(src, dst)
+	fmt.Fprintf(&g.buf, `
+// %sSlice maps []*%s to []*%s element-wise, returning nil for nil.
+func %sSlice(src []*%s) []*%s {
+	if src == nil {
+		return nil
+	}
+	dst := make([]*%s, len(src))
+	for i, v := range src {
+		dst[i] = %s(v)
+	}
+	return dst
+}
+`, name, src, dst, name, src, dst, dst, name)
+}
+
+// matchName returns the name a field is matched by: for model fields the
+// map tag when present, otherwise the field name
+func (g *mapperGen) matchName(f mapperField, isModel bool) string {
+	if isModel {
+		if name, ok := f.tag.Lookup("map"); ok && name != "" {
+			return name
+		}
+	}
+	return f.name
+}
+
+func (g *mapperGen) tmp(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+// typeString prints a type and records the imports it needs
+func (g *mapperGen) typeString(t typeRef) string {
+	ast.Inspect(t.expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			if importPath, found := resolveImport(t.file, pkg.Name); found {
+				g.imports[importPath] = pkg.Name
+			}
+		}
+		return false
+	})
+	var buf bytes.Buffer
+	format.Node(&buf, g.pkg.fset, t.expr)
+	return buf.String()
+}
+
+// printed returns a type's source without recording imports
+func (g *mapperGen) printed(t typeRef) string {
+	var buf bytes.Buffer
+	format.Node(&buf, g.pkg.fset, t.expr)
+	return buf.String()
+}
+
+// structName returns the name of a package struct type, or ""
+func (g *mapperGen) structName(t typeRef) string {
+	if ident, ok := t.expr.(*ast.Ident); ok {
+		if _, ok := g.pkg.structs[ident.Name]; ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// sqlNull returns the value type and field of a database/sql null type
+func (g *mapperGen) sqlNull(t typeRef) (valueType, field string, ok bool) {
+	sel, isSel := t.expr.(*ast.SelectorExpr)
+	if !isSel {
+		return "", "", false
+	}
+	pkg, isIdent := sel.X.(*ast.Ident)
+	if !isIdent {
+		return "", "", false
+	}
+	if importPath, found := resolveImport(t.file, pkg.Name); !found || importPath != "database/sql" {
+		return "", "", false
+	}
+	info, ok := sqlNullTypes[sel.Sel.Name]
+	return info[0], info[1], ok
+}
+
+func elem(t typeRef) (typeRef, bool) {
+	if star, ok := t.expr.(*ast.StarExpr); ok {
+		return typeRef{expr: star.X, file: t.file}, true
+	}
+	return t, false
+}
+
+func sliceElem(t typeRef) (typeRef, bool) {
+	if array, ok := t.expr.(*ast.ArrayType); ok && array.Len == nil {
+		return typeRef{expr: array.Elt, file: t.file}, true
+	}
+	return t, false
+}
+
+// assign writes statements setting dst from src, converting between the
+// types, and reports whether it could. src and dst are addressable.
+func (g *mapperGen) assign(w *strings.Builder, indent, dst, src string, dt, st typeRef, forward bool) bool {
+	dtName, stName := g.printed(dt), g.printed(st)
+
+	// Identical types copy directly
+	if dtName == stName {
+		fmt.Fprintf(w, "%s%s = %s\n", indent, dst, src)
+		return true
+	}
+
+	// Nested structs of another pair map through that pair's functions
+	dElem, dPtr := elem(dt)
+	sElem, sPtr := elem(st)
+	if d, s := g.structName(dElem), g.structName(sElem); d != "" && s != "" {
+		if forward {
+			g.enqueue(mapperPair{entity: s, model: d})
+		} else {
+			g.enqueue(mapperPair{entity: d, model: s})
+		}
+		call := funcName(s, d)
+		switch {
+		case dPtr && sPtr:
+			fmt.Fprintf(w, "%s%s = %s(%s)\n", indent, dst, call, src)
+		case dPtr:
+			fmt.Fprintf(w, "%s%s = %s(&%s)\n", indent, dst, call, src)
+		case sPtr:
+			fmt.Fprintf(w, "%sif %s != nil {\n%s\t%s = *%s(%s)\n%s}\n", indent, src, indent, dst, call, src, indent)
+		default:
+			fmt.Fprintf(w, "%s%s = *%s(&%s)\n", indent, dst, call, src)
+		}
+		return true
+	}
+
+	// Slices convert element by element
+	if dEl, ok := sliceElem(dt); ok {
+		sEl, ok := sliceElem(st)
+		if !ok {
+			return false
+		}
+		var inner strings.Builder
+		i := g.tmp("i")
+		if !g.assign(&inner, indent+"\t\t", fmt.Sprintf("%s[%s]", dst, i), fmt.Sprintf("%s[%s]", src, i), dEl, sEl, forward) {
+			return false
+		}
+		fmt.Fprintf(w, "%sif %s != nil {\n", indent, src)
+		fmt.Fprintf(w, "%s\t%s = make(%s, len(%s))\n", indent, dst, g.typeString(dt), src)
+		fmt.Fprintf(w, "%s\tfor %s := range %s {\n%s%s\t}\n%s}\n", indent, i, src, inner.String(), indent, indent)
+		return true
+	}
+
+	// sql.Null* types convert to and from their value and pointer types
+	if valueType, field, ok := g.sqlNull(dt); ok {
+		nullType := g.typeString(dt)
+		switch {
+		case stName == valueType:
+			fmt.Fprintf(w, "%s%s = %s{%s: %s, Valid: true}\n", indent, dst, nullType, field, src)
+			return true
+		case sPtr && g.printed(sElem) == valueType:
+			fmt.Fprintf(w, "%sif %s != nil {\n%s\t%s = %s{%s: *%s, Valid: true}\n%s}\n", indent, src, indent, dst, nullType, field, src, indent)
+			return true
+		}
+		return false
+	}
+	if valueType, field, ok := g.sqlNull(st); ok {
+		switch {
+		case dtName == valueType:
+			fmt.Fprintf(w, "%sif %s.Valid {\n%s\t%s = %s.%s\n%s}\n", indent, src, indent, dst, src, field, indent)
+			return true
+		case dPtr && g.printed(dElem) == valueType:
+			v := g.tmp("v")
+			fmt.Fprintf(w, "%sif %s.Valid {\n%s\t%s := %s.%s\n%s\t%s = &%s\n%s}\n", indent, src, indent, v, src, field, indent, dst, v, indent)
+			return true
+		}
+		return false
+	}
+
+	// Pointers and values of convertible types
+	switch {
+	case dPtr && sPtr:
+		var inner strings.Builder
+		v := g.tmp("v")
+		if !g.assign(&inner, indent+"\t", v, "*"+src, dElem, sElem, forward) {
+			return false
+		}
+		fmt.Fprintf(w, "%sif %s != nil {\n%s\tvar %s %s\n%s%s\t%s = &%s\n%s}\n",
+			indent, src, indent, v, g.typeString(dElem), inner.String(), indent, dst, v, indent)
+		return true
+	case dPtr:
+		v := g.tmp("v")
+		if g.printed(dElem) == stName {
+			fmt.Fprintf(w, "%s{\n%s\t%s := %s\n%s\t%s = &%s\n%s}\n", indent, indent, v, src, indent, dst, v, indent)
+			return true
+		}
+		var inner strings.Builder
+		if !g.assign(&inner, indent+"\t", v, src, dElem, st, forward) {
+			return false
+		}
+		fmt.Fprintf(w, "%s{\n%s\tvar %s %s\n%s%s\t%s = &%s\n%s}\n",
+			indent, indent, v, g.typeString(dElem), inner.String(), indent, dst, v, indent)
+		return true
+	case sPtr:
+		var inner strings.Builder
+		if !g.assign(&inner, indent+"\t", dst, "*"+src, dt, sElem, forward) {
+			return false
+		}
+		fmt.Fprintf(w, "%sif %s != nil {\n%s%s}\n", indent, src, inner.String(), indent)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mapperSource = `package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Base struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type User struct {
+	Base
+	Email     string
+	Nickname  sql.NullString
+	Age       *int
+	Home      *Address
+	Addresses []Address
+	Password  string
+}
+
+type AddressDTO struct {
+	Street string
+	Town   string ` + "`map:\"City\"`" + `
+}
+
+type UserResponse struct {
+	ID        int64
+	EmailAddr string ` + "`map:\"Email\"`" + `
+	Nickname  *string
+	Age       int
+	Home      AddressDTO
+	Addresses []*AddressDTO
+	Internal  string ` + "`map:\"-\"`" + `
+	Extra     bool
+}
+`
+
+func TestGenerateMappers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(mapperSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GenerateMappers(MapperConfig{Dir: dir, Entity: "User", Model: "UserResponse"})
+	if err != nil {
+		t.Fatalf("GenerateMappers failed: %v", err)
+	}
+	if filepath.Base(output) != "user_user_response_mapper_gen.go" {
+		t.Errorf("Unexpected output file %s", output)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	if _, err := parser.ParseFile(token.NewFileSet(), output, data, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, code)
+	}
+
+	for _, expected := range []string{
+		"func UserToUserResponse(src *User) *UserResponse {",
+		"func UserResponseToUser(src *UserResponse) *User {",
+		"func UserToUserResponseSlice(src []*User) []*UserResponse {",
+		"func AddressToAddressDTO(src *Address) *AddressDTO {",
+		"func AddressDTOToAddress(src *AddressDTO) *Address {",
+		"dst.ID = src.ID",
+		"dst.EmailAddr = src.Email",
+		"dst.Email = src.EmailAddr",
+		"dst.Town = src.City",
+		"\tif src.Nickname.Valid {\n\t\tv1 := src.Nickname.String\n\t\tdst.Nickname = &v1\n\t}",
+		"dst.Nickname = sql.NullString{String: *src.Nickname, Valid: true}",
+		"\tif src.Age != nil {\n\t\tdst.Age = *src.Age\n\t}",
+		"\tif src.Home != nil {\n\t\tdst.Home = *AddressToAddressDTO(src.Home)\n\t}",
+		"dst.Home = AddressDTOToAddress(&src.Home)",
+		"dst.Addresses[i2] = AddressToAddressDTO(&src.Addresses[i2])",
+		"// Not mapped: Extra.",
+		`"database/sql"`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected generated code to contain %q\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "Internal") || strings.Contains(code, "dst.Password") {
+		t.Errorf("Expected skipped and unmatched fields to be left out\n%s", code)
+	}
+	if strings.Contains(code, `"time"`) {
+		t.Errorf("Expected no unused imports\n%s", code)
+	}
+}
+
+func TestGenerateMappers_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(mapperSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateMappers(MapperConfig{Dir: dir, Entity: "User"}); err == nil {
+		t.Error("Expected an error without a model")
+	}
+	if _, err := GenerateMappers(MapperConfig{Dir: dir, Entity: "User", Model: "Missing"}); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Expected a missing struct error, got %v", err)
+	}
+}
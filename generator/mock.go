@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// mockImportPath is the package of the Recorder generated mocks embed
+const mockImportPath = "github.com/satishbabariya/jetorm/testing"
+
+// MockName returns the name of the mock generated for an interface
+func MockName(interfaceName string) string {
+	return "Mock" + interfaceName
+}
+
+// MockFile returns the path of the mock generated next to a repository file
+func MockFile(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".go") + "_mock.go"
+}
+
+// generateMockCode generates an in-memory implementation of the interface for
+// unit tests. Every method of the interface, including those of embedded
+// interfaces such as core.Repository, records its call in an embedded
+// Recorder and returns what its <Method>Func field returns. Without one it
+// returns zero values, except that finders of a single pointer return
+// core.ErrNotFound and results the interface itself satisfies, such as
+// WithTx's repository, return the mock.
+func generateMockCode(pkgName, interfaceName string, loader *TypeLoader) (string, error) {
+	iface, err := loader.LoadInterface(interfaceName)
+	if err != nil {
+		return "", err
+	}
+	self := loader.pkg.Scope().Lookup(interfaceName).Type()
+
+	imports := map[string]string{mockImportPath: "jetormtesting"}
+	qualifier := func(pkg *types.Package) string {
+		if pkg == loader.pkg {
+			return ""
+		}
+		if _, ok := imports[pkg.Path()]; !ok {
+			imports[pkg.Path()] = pkg.Name()
+		}
+		return imports[pkg.Path()]
+	}
+
+	mock := MockName(interfaceName)
+	var fields, methods strings.Builder
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		sig := method.Type().(*types.Signature)
+		name := method.Name()
+
+		fmt.Fprintf(&fields, "\t%sFunc %s\n", name, types.TypeString(sig, qualifier))
+
+		// Parameters need names to be passed on
+		var params, args, recorded []string
+		for j := 0; j < sig.Params().Len(); j++ {
+			param := sig.Params().At(j)
+			paramName := param.Name()
+			if paramName == "" || paramName == "_" || paramName == "m" {
+				paramName = fmt.Sprintf("arg%d", j)
+			}
+			paramType := types.TypeString(param.Type(), qualifier)
+			arg := paramName
+			if sig.Variadic() && j == sig.Params().Len()-1 {
+				paramType = "..." + types.TypeString(param.Type().(*types.Slice).Elem(), qualifier)
+				arg += "..."
+			}
+			params = append(params, paramName+" "+paramType)
+			args = append(args, arg)
+			if j > 0 || !isContext(param.Type()) {
+				recorded = append(recorded, paramName)
+			}
+		}
+
+		var results, defaults []string
+		for j := 0; j < sig.Results().Len(); j++ {
+			result := sig.Results().At(j).Type()
+			results = append(results, types.TypeString(result, qualifier))
+			defaults = append(defaults, mockDefault(result, self, qualifier))
+		}
+		if n := sig.Results().Len(); n == 2 && isError(sig.Results().At(1).Type()) &&
+			isPointer(sig.Results().At(0).Type()) && (strings.HasPrefix(name, "Find") || name == "QueryOne") {
+			defaults[1] = "core.ErrNotFound"
+			qualifier(types.NewPackage("github.com/satishbabariya/jetorm/core", "core"))
+		}
+		returns := strings.Join(results, ", ")
+		if len(results) > 1 {
+			returns = "(" + returns + ")"
+		}
+
+		record := fmt.Sprintf("%q", name)
+		if len(recorded) > 0 {
+			record += ", " + strings.Join(recorded, ", ")
+		}
+		call := fmt.Sprintf("m.%sFunc(%s)", name, strings.Join(args, ", "))
+		fmt.Fprintf(&methods, "\n// %s records the call and runs %sFunc\nfunc (m *%s) %s(%s) %s {\n\tm.Recorder.Record(%s)\n",
+			name, name, mock, name, strings.Join(params, ", "), returns, record)
+		if len(results) == 0 {
+			fmt.Fprintf(&methods, "\tif m.%sFunc != nil {\n\t\t%s\n\t}\n}\n", name, call)
+			continue
+		}
+		fmt.Fprintf(&methods, "\tif m.%sFunc != nil {\n\t\treturn %s\n\t}\n\treturn %s\n}\n", name, call, strings.Join(defaults, ", "))
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// Code generated by jetorm-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	for i, path := range paths {
+		if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(path, ".") {
+			buf.WriteString("\n")
+		}
+		if name := imports[path]; name != path[strings.LastIndex(path, "/")+1:] {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+	}
+	fmt.Fprintf(&buf, `)
+
+// %s is an in-memory %s for unit tests.
+// Each method records its call and returns what its Func field returns.
+type %s struct {
+	jetormtesting.Recorder
+
+%s}
+
+var _ %s = (*%s)(nil)
+%s`, mock, interfaceName, mock, fields.String(), interfaceName, mock, methods.String())
+
+	formatted, err := format.Source([]byte(buf.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format mock: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// mockDefault returns the value a mock method returns for a result of type t
+// when its Func field is not set
+func mockDefault(t, self types.Type, qualifier types.Qualifier) string {
+	if types.IsInterface(t) && !isError(t) && types.AssignableTo(self, t) {
+		return "m"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+		return "nil"
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, qualifier) + "{}"
+	}
+	return "nil"
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isPointer(t types.Type) bool {
+	_, ok := t.(*types.Pointer)
+	return ok
+}
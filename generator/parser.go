@@ -5,6 +5,7 @@ import (
 	"go/parser"
 	"go/token"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -20,6 +21,8 @@ type MethodInfo struct {
 	Name       string
 	Parameters []ParameterInfo
 	Returns    []ReturnInfo
+	Query      string         // SQL of a jetorm:query comment, if any
+	Pos        token.Position // Position of the method name in its file
 }
 
 // ParameterInfo represents a method parameter
@@ -94,6 +97,8 @@ func (p *Parser) extractInterface(name string, it *ast.InterfaceType) *Interface
 				Name:       method.Names[0].Name,
 				Parameters: p.extractParameters(fn.Params),
 				Returns:    p.extractReturns(fn.Results),
+				Query:      queryAnnotation(method.Doc),
+				Pos:        p.fset.Position(method.Names[0].Pos()),
 			}
 			info.Methods = append(info.Methods, methodInfo)
 		}
@@ -167,6 +172,14 @@ func (p *Parser) typeToString(expr ast.Expr) string {
 		return "func(...)" // Simplified
 	case *ast.Ellipsis:
 		return "..." + p.typeToString(x.Elt)
+	case *ast.IndexExpr:
+		return p.typeToString(x.X) + "[" + p.typeToString(x.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(x.Indices))
+		for i, index := range x.Indices {
+			args[i] = p.typeToString(index)
+		}
+		return p.typeToString(x.X) + "[" + strings.Join(args, ", ") + "]"
 	default:
 		return "unknown"
 	}
@@ -176,12 +189,15 @@ func (p *Parser) typeToString(expr ast.Expr) string {
 func (info *InterfaceInfo) FindCustomMethods() []MethodInfo {
 	baseMethods := map[string]bool{
 		"Save":           true,
+		"Insert":         true,
 		"SaveAll":        true,
 		"Update":         true,
 		"UpdateAll":      true,
 		"FindByID":       true,
 		"FindAll":        true,
 		"FindAllByIDs":   true,
+		"FindAllByIDsOrdered": true,
+		"FindMapByIDs":   true,
 		"Delete":         true,
 		"DeleteByID":     true,
 		"DeleteAll":      true,
@@ -200,6 +216,7 @@ func (info *InterfaceInfo) FindCustomMethods() []MethodInfo {
 		"CountWithSpec":  true,
 		"ExistsWithSpec": true,
 		"DeleteWithSpec": true,
+		"UpdateWithSpec": true,
 	}
 
 	var customMethods []MethodInfo
@@ -212,14 +229,22 @@ func (info *InterfaceInfo) FindCustomMethods() []MethodInfo {
 	return customMethods
 }
 
+// updateMethodPattern matches derived update methods such as
+// UpdateStatusByID and SetActiveFalseByLastLoginBefore
+var updateMethodPattern = regexp.MustCompile(`^(Update|Set)[A-Z]\w*By[A-Z]`)
+
+// selectMethodPattern matches finders naming the fields they select, such as
+// FindEmailAndNameByStatus
+var selectMethodPattern = regexp.MustCompile(`^Find[A-Z]\w*By[A-Z]`)
+
 // IsQueryMethod checks if a method name follows the query method naming convention
 func IsQueryMethod(methodName string) bool {
 	queryPrefixes := []string{
-		"FindBy", "FindFirstBy", "FindTop",
+		"FindBy", "FindAllBy", "FindFirst", "FindTop",
 		"CountBy", "CountDistinctBy",
 		"ExistsBy",
 		"DeleteBy",
-		"FindDistinctBy",
+		"FindDistinct",
 	}
 
 	for _, prefix := range queryPrefixes {
@@ -228,6 +253,6 @@ func IsQueryMethod(methodName string) bool {
 		}
 	}
 
-	return false
+	return updateMethodPattern.MatchString(methodName) || selectMethodPattern.MatchString(methodName)
 }
 
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GeneratorPlugin customizes every generated repository, e.g. to add
+// tracing spans or metrics to its methods. Plugins registered with
+// RegisterPlugin run in registration order; an error from any hook fails the
+// repository's generation.
+type GeneratorPlugin interface {
+	// Name identifies the plugin in errors
+	Name() string
+
+	// PreGenerate runs before the repository is generated. It may adjust
+	// ctx.Config and add imports.
+	PreGenerate(ctx *PluginContext) error
+
+	// MethodBody returns the body of a generated method, the statements
+	// between its braces, each indented by one tab
+	MethodBody(ctx *PluginContext, method MethodInfo, body string) (string, error)
+
+	// PostGenerate returns the complete file before it is written
+	PostGenerate(ctx *PluginContext, code string) (string, error)
+}
+
+// PluginContext describes the repository being generated to plugins
+type PluginContext struct {
+	Config         *Config
+	PackageName    string
+	EntityName     string
+	RepositoryName string
+	Methods        []MethodInfo // Methods of the interface outside core.Repository
+
+	imports []string // Import specs added by plugins
+}
+
+// AddImport adds an import to the generated file, e.g.
+// AddImport("go.opentelemetry.io/otel"), or with a name,
+// AddImport("go.opentelemetry.io/otel/trace", "oteltrace")
+func (c *PluginContext) AddImport(path string, name ...string) {
+	spec := strconv.Quote(path)
+	if len(name) > 0 && name[0] != "" {
+		spec = name[0] + " " + spec
+	}
+	for _, existing := range c.imports {
+		if existing == spec {
+			return
+		}
+	}
+	c.imports = append(c.imports, spec)
+}
+
+// importSpecs returns the imports added by plugins, split into standard
+// library and third-party specs
+func (c *PluginContext) importSpecs() (std, thirdParty []string) {
+	for _, spec := range c.imports {
+		path := spec[strings.Index(spec, `"`)+1:]
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			thirdParty = append(thirdParty, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	return std, thirdParty
+}
+
+var (
+	pluginsMu sync.Mutex
+	plugins   []GeneratorPlugin
+)
+
+// RegisterPlugin adds a plugin to every later generation, including
+// GeneratePackage. Teams register their plugins in a small main that wraps
+// the generator, e.g. in a tools/jetorm-gen command run by go:generate.
+func RegisterPlugin(plugin GeneratorPlugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	plugins = append(plugins, plugin)
+}
+
+// registeredPlugins returns the registered plugins
+func registeredPlugins() []GeneratorPlugin {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	return append([]GeneratorPlugin(nil), plugins...)
+}
+
+// preGenerate runs the PreGenerate hooks
+func (c *PluginContext) preGenerate() error {
+	for _, plugin := range registeredPlugins() {
+		if err := plugin.PreGenerate(c); err != nil {
+			return fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
+// methodBody runs the MethodBody hooks on the body of a generated method:
+// the lines between its signature and its closing brace
+func (c *PluginContext) methodBody(method MethodInfo, code string) (string, error) {
+	registered := registeredPlugins()
+	if len(registered) == 0 {
+		return code, nil
+	}
+	sig := 0
+	if !strings.HasPrefix(code, "func ") {
+		sig = strings.Index(code, "\nfunc ") + 1
+		if sig == 0 {
+			return code, nil
+		}
+	}
+	brace := strings.Index(code[sig:], "{\n")
+	bodyEnd := strings.LastIndex(code, "}")
+	if brace < 0 || bodyEnd < sig+brace {
+		return code, nil
+	}
+	bodyStart := sig + brace + len("{\n")
+
+	body := code[bodyStart:bodyEnd]
+	for _, plugin := range registered {
+		var err error
+		body, err = plugin.MethodBody(c, method, body)
+		if err != nil {
+			return "", fmt.Errorf("plugin %s: %s: %w", plugin.Name(), method.Name, err)
+		}
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return code[:bodyStart] + body + code[bodyEnd:], nil
+}
+
+// postGenerate runs the PostGenerate hooks
+func (c *PluginContext) postGenerate(code string) (string, error) {
+	for _, plugin := range registeredPlugins() {
+		var err error
+		code, err = plugin.PostGenerate(c, code)
+		if err != nil {
+			return "", fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+	}
+	return code, nil
+}
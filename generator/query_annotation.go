@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"strings"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+// QueryAnnotation starts a comment above an interface method giving the SQL
+// the method runs, for queries a derived method name cannot express. The SQL
+// may continue on the following comment lines up to a blank one, and binds
+// the method's parameters by name:
+//
+//	// jetorm:query SELECT * FROM users
+//	//   WHERE email = :email AND created_at > :since
+//	FindRecent(ctx context.Context, email string, since time.Time) ([]*User, error)
+const QueryAnnotation = "jetorm:query"
+
+// queryAnnotation returns the SQL of a QueryAnnotation in a method's doc
+// comment, or "" when it has none
+func queryAnnotation(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, comment := range doc.List {
+		text, ok := strings.CutPrefix(comment.Text, "//")
+		if !ok {
+			continue // Block comments are not annotations
+		}
+		text = strings.TrimSpace(text)
+		if lines == nil {
+			if rest, ok := strings.CutPrefix(text, QueryAnnotation); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+				lines = append(lines, strings.TrimSpace(rest))
+			}
+			continue
+		}
+		if text == "" {
+			break
+		}
+		lines = append(lines, text)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// GenerateAnnotatedMethod implements an interface method from its
+// QueryAnnotation. The :name placeholders are bound to the method's
+// parameters and rewritten to positional ones, so the generated code runs
+// the SQL as written. The results select how it runs: *Entity and []*Entity
+// scan rows with QueryOne and Query, pointers to another struct of the
+// package scan them into that DTO with core.QueryOneInto and core.QueryInto,
+// while int64 (rows affected) and plain error execute it with Exec.
+func (g *CodeGenerator) GenerateAnnotatedMethod(info MethodInfo, entityName string) (string, error) {
+	if info.Query == "" {
+		return "", fmt.Errorf("%s: no %s comment", info.Name, QueryAnnotation)
+	}
+	if len(info.Parameters) == 0 || info.Parameters[0].Type != "context.Context" {
+		return "", fmt.Errorf("%s: first parameter must be context.Context", info.Name)
+	}
+
+	// Bind each placeholder to the parameter of that name
+	params := make(map[string]interface{}, len(info.Parameters)-1)
+	var signature string
+	for i, p := range info.Parameters[1:] {
+		if p.Name == "" || p.Name == "_" {
+			return "", fmt.Errorf("%s: parameter %d must be named", info.Name, i+2)
+		}
+		params[p.Name] = p.Name
+		signature += ", " + p.Name + " " + p.Type
+	}
+	query, bound, err := core.BindNamed(info.Query, params)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", info.Name, err)
+	}
+	used := make(map[interface{}]bool, len(bound))
+	for _, name := range bound {
+		used[name] = true
+	}
+	for _, p := range info.Parameters[1:] {
+		if !used[p.Name] {
+			return "", fmt.Errorf("%s: parameter %s is not used by the query", info.Name, p.Name)
+		}
+	}
+	var args string
+	for _, name := range bound {
+		args += ", " + name.(string)
+	}
+
+	results := make([]string, len(info.Returns))
+	for i, ret := range info.Returns {
+		results[i] = ret.Type
+	}
+	returns := strings.Join(results, ", ")
+
+	var body string
+	switch returns {
+	case "*" + entityName + ", error":
+		body = fmt.Sprintf("return r.QueryOne(ctx, %q%s)", query, args)
+	case "[]*" + entityName + ", error":
+		body = fmt.Sprintf("return r.Query(ctx, %q%s)", query, args)
+	case "int64, error":
+		body = fmt.Sprintf("return r.Exec(ctx, %q%s)", query, args)
+	case "error":
+		body = fmt.Sprintf("_, err := r.Exec(ctx, %q%s)\n\treturn err", query, args)
+	default:
+		m := projectionReturn.FindStringSubmatch(returns)
+		switch {
+		case m == nil:
+			return "", fmt.Errorf("%s: annotated methods must return (*%s, error), ([]*%s, error), a DTO, (int64, error) or error, not (%s)",
+				info.Name, entityName, entityName, returns)
+		case m[1] == "*":
+			body = fmt.Sprintf("return core.QueryOneInto[%s](ctx, r.BaseRepository, %q%s)", m[2], query, args)
+		default:
+			body = fmt.Sprintf("return core.QueryInto[%s](ctx, r.BaseRepository, %q%s)", m[2], query, args)
+		}
+	}
+	if len(results) > 1 {
+		returns = "(" + returns + ")"
+	}
+	g.queries = append(g.queries, query)
+
+	code := fmt.Sprintf("func (r *%s) %s(ctx context.Context%s) %s {\n\t%s\n}\n",
+		g.receiverName(entityName), info.Name, signature, returns, body)
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return code, nil
+	}
+	return string(formatted), nil
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RepositoryMarker marks a repository interface for ScanPackage. It goes in
+// the interface's doc comment, optionally followed by key=value options:
+//
+//	//jetorm:repository entity=User id=int64
+//	type UserRepository interface { ... }
+//
+// The options are entity, id, save_mode, output, name (the generated
+// struct), mocks=true, which also generates Mock<Interface>, and
+// jet_tables=<dir>, which also writes the entity's Jet table to dir. Paths
+// are relative to the package. Without entity the interface name minus
+// "Repository" is used.
+const RepositoryMarker = "//jetorm:repository"
+
+// markerOptions lists the options RepositoryMarker accepts
+var markerOptions = map[string]bool{"entity": true, "id": true, "save_mode": true, "output": true, "name": true, "mocks": true, "jet_tables": true}
+
+// ScanPackage returns a generation config for each interface of the package
+// in dir that is named *Repository and carries RepositoryMarker. The ID type
+// comes from the marker, from an embedded core.Repository[Entity, ID], or
+// from the entity's primary key, in that order. Each interface is written to
+// its own <interface>_gen.go file in dir.
+func ScanPackage(dir string) ([]*Config, error) {
+	return scanPackage(dir, make(typeLoaders))
+}
+
+// scanPackage is ScanPackage with a cache of type-checked packages
+func scanPackage(dir string, loaders typeLoaders) ([]*Config, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	var configs []*Config
+	for pkgName, pkg := range pkgs {
+		if strings.HasSuffix(pkgName, "_test") {
+			continue
+		}
+		for path, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					it, ok := ts.Type.(*ast.InterfaceType)
+					if !ok || !strings.HasSuffix(ts.Name.Name, "Repository") {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					options, ok, err := parseMarker(doc)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", fset.Position(ts.Pos()), err)
+					}
+					if !ok {
+						continue
+					}
+					cfg, err := scannedConfig(dir, path, ts.Name.Name, it, options, loaders)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", fset.Position(ts.Pos()), err)
+					}
+					configs = append(configs, cfg)
+				}
+			}
+		}
+	}
+
+	// Map iteration order is random; generate in a stable order
+	sort.Slice(configs, func(i, j int) bool { return configs[i].InterfaceName < configs[j].InterfaceName })
+	return configs, nil
+}
+
+// GeneratePackage generates a repository for every interface ScanPackage
+// finds in the package directories, in one pass like GenerateAll, and
+// returns the paths of the files written
+func GeneratePackage(dirs ...string) ([]string, error) {
+	loaders := make(typeLoaders)
+	var configs []*Config
+	var errs []error
+	for _, dir := range dirs {
+		found, err := scanPackage(dir, loaders)
+		if err == nil && len(found) == 0 {
+			err = fmt.Errorf("no interfaces marked %s in %s", RepositoryMarker, dir)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		configs = append(configs, found...)
+	}
+
+	files, err := generateAll(configs, loaders)
+	return files, errors.Join(append(errs, err)...)
+}
+
+// parseMarker finds RepositoryMarker in a doc comment and returns its options
+func parseMarker(doc *ast.CommentGroup) (map[string]string, bool, error) {
+	if doc == nil {
+		return nil, false, nil
+	}
+	for _, comment := range doc.List {
+		rest, ok := strings.CutPrefix(comment.Text, RepositoryMarker)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		options := make(map[string]string)
+		for _, option := range strings.Fields(rest) {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok || value == "" || !markerOptions[key] {
+				return nil, false, fmt.Errorf("invalid %s option %q", RepositoryMarker, option)
+			}
+			options[key] = value
+		}
+		return options, true, nil
+	}
+	return nil, false, nil
+}
+
+// scannedConfig builds the generation config of a marked interface
+func scannedConfig(dir, path, interfaceName string, it *ast.InterfaceType, options map[string]string, loaders typeLoaders) (*Config, error) {
+	cfg := DefaultConfig()
+	cfg.InputFile = path
+	cfg.InterfaceName = interfaceName
+	cfg.SaveMode = options["save_mode"]
+	if mocks, ok := options["mocks"]; ok {
+		generate, err := strconv.ParseBool(mocks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s option mocks=%s", RepositoryMarker, mocks)
+		}
+		cfg.GenerateMocks = generate
+	}
+
+	cfg.EntityType = options["entity"]
+	if cfg.EntityType == "" {
+		cfg.EntityType = strings.TrimSuffix(interfaceName, "Repository")
+	}
+	if cfg.EntityType == "" {
+		return nil, fmt.Errorf("%s needs entity=<Type>", RepositoryMarker)
+	}
+
+	// The generated struct cannot share the interface's name
+	cfg.RepositoryName = options["name"]
+	if cfg.RepositoryName == "" && cfg.RepositoryStructName() == interfaceName {
+		cfg.RepositoryName = interfaceName + "Impl"
+	}
+
+	cfg.IDType = options["id"]
+	if cfg.IDType == "" {
+		cfg.IDType = embeddedIDType(it, cfg.EntityType)
+	}
+	if cfg.IDType == "" {
+		if loader, err := loaders.load(dir); err == nil {
+			if entity, err := loader.LoadEntityType(cfg.EntityType); err == nil {
+				cfg.IDType = entity.GetIDType()
+			}
+		}
+	}
+	if cfg.IDType == "" {
+		cfg.IDType = "int64"
+	}
+
+	cfg.OutputFile = options["output"]
+	if cfg.OutputFile == "" {
+		cfg.OutputFile = toSnakeCase(interfaceName) + "_gen.go"
+	}
+	if !filepath.IsAbs(cfg.OutputFile) {
+		cfg.OutputFile = filepath.Join(dir, cfg.OutputFile)
+	}
+	cfg.JetTablesDir = options["jet_tables"]
+	if cfg.JetTablesDir != "" && !filepath.IsAbs(cfg.JetTablesDir) {
+		cfg.JetTablesDir = filepath.Join(dir, cfg.JetTablesDir)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// embeddedIDType returns the ID type argument of a Repository[Entity, ID]
+// interface embedded in it, or "" when there is none
+func embeddedIDType(it *ast.InterfaceType, entityName string) string {
+	p := NewParser()
+	for _, field := range it.Methods.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		index, ok := field.Type.(*ast.IndexListExpr)
+		if !ok || len(index.Indices) != 2 {
+			continue
+		}
+		name := p.typeToString(index.X)
+		if name != "Repository" && !strings.HasSuffix(name, ".Repository") {
+			continue
+		}
+		if p.typeToString(index.Indices[0]) == entityName {
+			return p.typeToString(index.Indices[1])
+		}
+	}
+	return ""
+}
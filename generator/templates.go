@@ -6,9 +6,10 @@ import (
 	"text/template"
 )
 
-// Templates for code generation
-
-var repositoryTemplate = `
+// RepositoryTemplate is the text/template GenerateRepositoryCode renders a
+// repository's struct and constructor with, from a TemplateData. Assign a
+// custom template to change the generated code.
+var RepositoryTemplate = `
 package {{.PackageName}}
 
 import (
@@ -32,13 +33,27 @@ func New{{.RepositoryName}}(db *core.Database) (*{{.RepositoryName}}, error) {
 	if err != nil {
 		return nil, err
 	}
+{{- if .SaveMode}}
+	baseRepo = baseRepo.WithSaveMode({{.SaveMode}})
+{{- end}}
 	return &{{.RepositoryName}}{
 		BaseRepository: baseRepo,
 	}, nil
 }
+{{- if .SchemaVersion}}
+
+// {{.RepositoryName}}SchemaVersion is the latest migration when this file was generated.
+const {{.RepositoryName}}SchemaVersion int64 = {{.SchemaVersion}}
+
+func init() {
+	core.RequireSchemaVersion({{.RepositoryName}}SchemaVersion)
+}
+{{- end}}
 `
 
-var methodTemplate = `
+// MethodTemplate is the text/template GenerateRepositoryCode renders each
+// of TemplateData.Methods with, from a MethodTemplateData
+var MethodTemplate = `
 // {{.MethodName}} implements the query method
 func (r *{{.RepositoryName}}) {{.MethodName}}(ctx context.Context{{.Parameters}}) {{.ReturnType}} {
 	{{.Body}}
@@ -51,6 +66,8 @@ type TemplateData struct {
 	EntityName     string
 	RepositoryName string
 	IDType         string
+	SaveMode       string // core.SaveMode constant, empty for the default
+	SchemaVersion  int64  // Latest migration version the code requires, 0 for none
 	Methods        []MethodTemplateData
 }
 
@@ -64,7 +81,7 @@ type MethodTemplateData struct {
 
 // GenerateRepositoryCode generates repository code using templates
 func GenerateRepositoryCode(data TemplateData) (string, error) {
-	tmpl, err := template.New("repository").Parse(repositoryTemplate)
+	tmpl, err := template.New("repository").Parse(RepositoryTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse repository template: %w", err)
 	}
@@ -75,7 +92,7 @@ func GenerateRepositoryCode(data TemplateData) (string, error) {
 	}
 
 	// Generate methods
-	methodTmpl, err := template.New("method").Parse(methodTemplate)
+	methodTmpl, err := template.New("method").Parse(MethodTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse method template: %w", err)
 	}
@@ -2,27 +2,187 @@ package generator
 
 import (
 	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
 	"go/types"
+	"os"
 	"reflect"
 	"strings"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
-// TypeLoader loads type information using go/types
+// TypeLoader loads entity types from a package directory with go/types
 type TypeLoader struct {
 	pkg *types.Package
 }
 
-// NewTypeLoader creates a new type loader
-func NewTypeLoader(pkgPath string) (*TypeLoader, error) {
-	// This is a placeholder - full implementation would use go/types
-	// For now, we'll use reflect-based approach
-	return &TypeLoader{}, nil
+// NewTypeLoader type-checks the non-test Go files in dir, resolving imports
+// from source. Type errors are ignored, as the package usually refers to
+// repositories that are not generated yet; only the entity has to resolve.
+func NewTypeLoader(dir string) (*TypeLoader, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	var files []*ast.File
+	var name string
+	for pkgName, pkg := range pkgs {
+		if strings.HasSuffix(pkgName, "_test") {
+			continue
+		}
+		name = pkgName
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(name, fset, files, nil)
+	return &TypeLoader{pkg: pkg}, nil
 }
 
-// LoadEntityType loads entity type information
+// LoadEntityType resolves the named struct and lists its columns the way
+// core.EntityMetadata does: db:"-" and jet:"-" fields are skipped and
+// embedded structs are flattened, including ones from other packages.
 func (tl *TypeLoader) LoadEntityType(typeName string) (*EntityTypeInfo, error) {
-	// Placeholder - would use go/types in full implementation
-	return nil, fmt.Errorf("full type loading not yet implemented")
+	obj, ok := tl.pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", typeName, tl.pkg.Name())
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("entity type %s must be a struct", typeName)
+	}
+
+	info := &EntityTypeInfo{
+		Name:      typeName,
+		Package:   tl.pkg.Path(),
+		TableName: toSnakeCase(typeName),
+	}
+	tl.collectFields(info, st)
+
+	// An embedded base struct tagged discriminator:column[=value] puts the
+	// entity in the base's table, like core.EntityMetadata
+	for i := 0; i < st.NumFields(); i++ {
+		tag := reflect.StructTag(st.Tag(i))
+		if _, ok := flattenedStruct(st.Field(i), tag.Get("db")); !ok {
+			continue
+		}
+		spec, ok := parseTags(tag.Get("jet"))["discriminator"]
+		if !ok {
+			continue
+		}
+		column, value, _ := strings.Cut(spec, "=")
+		if value == "" {
+			value = toSnakeCase(typeName)
+		}
+		info.TableName = toSnakeCase(st.Field(i).Name())
+		info.Discriminator = &core.Discriminator{Table: info.TableName, Column: column, Value: value}
+	}
+	return info, nil
+}
+
+// LoadInterface resolves the named interface. Its method set includes the
+// methods of embedded interfaces, which must resolve too.
+func (tl *TypeLoader) LoadInterface(typeName string) (*types.Interface, error) {
+	obj, ok := tl.pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", typeName, tl.pkg.Name())
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an interface", typeName)
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		if embedded := iface.EmbeddedType(i); embedded == types.Typ[types.Invalid] {
+			return nil, fmt.Errorf("%s embeds an interface that does not resolve", typeName)
+		}
+	}
+	return iface, nil
+}
+
+// collectFields appends the columns of st, flattening embedded structs
+func (tl *TypeLoader) collectFields(info *EntityTypeInfo, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		dbTag, jetTag := tag.Get("db"), tag.Get("jet")
+		if !field.Exported() || dbTag == "-" || jetTag == "-" {
+			continue
+		}
+		if embedded, ok := flattenedStruct(field, dbTag); ok {
+			tl.collectFields(info, embedded)
+			continue
+		}
+
+		fieldInfo := FieldInfo{
+			Name:     field.Name(),
+			DBName:   dbTag,
+			Type:     field.Type(),
+			TypeName: types.TypeString(field.Type(), tl.qualifier),
+			Tags:     parseTags(jetTag),
+		}
+		if fieldInfo.DBName == "" {
+			fieldInfo.DBName = toSnakeCase(field.Name())
+		}
+		if _, ok := fieldInfo.Tags["primary_key"]; ok {
+			fieldInfo.IsPrimaryKey = true
+		}
+		if _, ok := fieldInfo.Tags["auto_increment"]; ok {
+			fieldInfo.IsAutoInc = true
+		}
+
+		info.Fields = append(info.Fields, fieldInfo)
+		if fieldInfo.IsPrimaryKey {
+			info.PrimaryKey = &info.Fields[len(info.Fields)-1]
+		}
+	}
+}
+
+// qualifier writes types as the entity's package refers to them: its own
+// types unqualified and imported ones by package name, e.g. uuid.UUID
+func (tl *TypeLoader) qualifier(pkg *types.Package) string {
+	if pkg == tl.pkg {
+		return ""
+	}
+	return pkg.Name()
+}
+
+// flattenedStruct returns the struct of an embedded field whose columns are
+// flattened into the entity. Like core, embedded structs with a db tag, with
+// Value or Scan methods, or without exported fields (such as time.Time) map
+// to one column.
+func flattenedStruct(field *types.Var, dbTag string) (*types.Struct, bool) {
+	if !field.Embedded() || dbTag != "" {
+		return nil, false
+	}
+	st, ok := field.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, false
+	}
+	methods := types.NewMethodSet(types.NewPointer(field.Type()))
+	if methods.Lookup(nil, "Value") != nil || methods.Lookup(nil, "Scan") != nil {
+		return nil, false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Exported() {
+			return st, true
+		}
+	}
+	return nil, false
 }
 
 // EntityTypeInfo contains information about an entity type
@@ -32,6 +192,7 @@ type EntityTypeInfo struct {
 	Fields     []FieldInfo
 	PrimaryKey *FieldInfo
 	TableName  string
+	Discriminator *core.Discriminator // Set when the table is shared with other entity types
 }
 
 // FieldInfo contains information about a struct field
@@ -39,6 +200,7 @@ type FieldInfo struct {
 	Name         string
 	DBName       string
 	Type         types.Type
+	TypeName     string // Type as written in the entity's package, e.g. "*time.Time"
 	IsPrimaryKey bool
 	IsAutoInc    bool
 	Tags         map[string]string
@@ -47,6 +209,9 @@ type FieldInfo struct {
 // GetIDType returns the ID type for an entity
 func (eti *EntityTypeInfo) GetIDType() string {
 	if eti.PrimaryKey != nil {
+		if eti.PrimaryKey.TypeName != "" {
+			return eti.PrimaryKey.TypeName
+		}
 		return eti.PrimaryKey.Type.String()
 	}
 	return "int64" // Default
@@ -0,0 +1,40 @@
+package hooks
+
+import "context"
+
+// actorKey and requestIDKey are the context keys shared by hooks, audit
+// fields, logging and query comments. core re-exports the helpers.
+type (
+	actorKey     struct{}
+	requestIDKey struct{}
+)
+
+// legacyUserIDKey is the string key audit hooks read before WithActor existed
+const legacyUserIDKey = "user_id"
+
+// WithActor returns a context identifying the user or service performing
+// the operation
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor. Contexts carrying a
+// "user_id" string value, as read by earlier audit hooks, are still honored.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor, true
+	}
+	actor, ok := ctx.Value(legacyUserIDKey).(string)
+	return actor, ok
+}
+
+// WithRequestID returns a context carrying the ID of the request being served
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
@@ -183,8 +183,8 @@ func AuditHook[T Auditable]() HookFunc[T] {
 		auditable := any(*entity).(Auditable)
 		auditable.SetUpdatedAt(now)
 		
-		// Try to get user ID from context
-		if userID, ok := ctx.Value("user_id").(string); ok {
+		// Try to get the actor from context
+		if userID, ok := ActorFromContext(ctx); ok {
 			auditable.SetUpdatedBy(userID)
 		}
 		
@@ -201,7 +201,7 @@ func CreateAuditHook[T Auditable]() HookFunc[T] {
 		auditable.SetCreatedAt(now)
 		auditable.SetUpdatedAt(now)
 		
-		if userID, ok := ctx.Value("user_id").(string); ok {
+		if userID, ok := ActorFromContext(ctx); ok {
 			auditable.SetCreatedBy(userID)
 			auditable.SetUpdatedBy(userID)
 		}
@@ -0,0 +1,161 @@
+// Package schema maps entity fields to the PostgreSQL column types the
+// schema generator declares, for core and migration alike.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// wellKnownColumnTypes maps nullable and driver types, which implement
+// sql.Scanner and driver.Valuer themselves, to their PostgreSQL column types
+var wellKnownColumnTypes = map[string]string{
+	"sql.NullString":      "TEXT",
+	"sql.NullBool":        "BOOLEAN",
+	"sql.NullByte":        "SMALLINT",
+	"sql.NullInt16":       "SMALLINT",
+	"sql.NullInt32":       "INTEGER",
+	"sql.NullInt64":       "BIGINT",
+	"sql.NullFloat64":     "DOUBLE PRECISION",
+	"sql.NullTime":        "TIMESTAMP",
+	"pgtype.Text":         "TEXT",
+	"pgtype.Bool":         "BOOLEAN",
+	"pgtype.Int2":         "SMALLINT",
+	"pgtype.Int4":         "INTEGER",
+	"pgtype.Int8":         "BIGINT",
+	"pgtype.Float4":       "REAL",
+	"pgtype.Float8":       "DOUBLE PRECISION",
+	"pgtype.Numeric":      "NUMERIC",
+	"pgtype.Date":         "DATE",
+	"pgtype.Time":         "TIME",
+	"pgtype.Timestamp":    "TIMESTAMP",
+	"pgtype.Timestamptz":  "TIMESTAMPTZ",
+	"pgtype.Interval":     "INTERVAL",
+	"pgtype.UUID":         "UUID",
+	"netip.Addr":          "INET",
+	"netip.Prefix":        "CIDR",
+	"decimal.Decimal":     "NUMERIC",
+	"decimal.NullDecimal": "NUMERIC",
+}
+
+// ColumnType returns the PostgreSQL type of a field of goType with the given
+// jet tag, as the schema generator declares its column
+func ColumnType(goType reflect.Type, jetTag string) string {
+	// Check for explicit type in jet tag
+	if explicitType := TagValue(jetTag, "type"); explicitType != "" {
+		return explicitType
+	}
+
+	// PostgreSQL enum types
+	if name, _, ok := ParseEnum(TagValue(jetTag, "enum")); ok {
+		return name
+	}
+
+	// UUID keys, generated by the client or by the database
+	if HasTagFlag(jetTag, "uuid") {
+		return "UUID"
+	}
+
+	// Nullable and driver types
+	baseType := goType
+	if baseType.Kind() == reflect.Ptr {
+		baseType = baseType.Elem()
+	}
+	if columnType, ok := wellKnownColumnTypes[baseType.String()]; ok {
+		return columnType
+	}
+
+	// Map Go types to PostgreSQL types
+	switch baseType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "DOUBLE PRECISION"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.String:
+		if size := TagValue(jetTag, "size"); size != "" {
+			return fmt.Sprintf("VARCHAR(%s)", size)
+		}
+		return "TEXT"
+	case reflect.Slice, reflect.Array:
+		if baseType.Elem().Kind() == reflect.Uint8 {
+			return "BYTEA"
+		}
+		return "TEXT" // JSON array
+	case reflect.Struct:
+		if baseType.String() == "time.Time" {
+			return "TIMESTAMP"
+		}
+		return "TEXT" // JSON object
+	default:
+		return "TEXT"
+	}
+}
+
+// ParseEnum parses the value of an enum tag, name(value1,value2)
+func ParseEnum(value string) (name string, values []string, ok bool) {
+	open := strings.Index(value, "(")
+	if open <= 0 || !strings.HasSuffix(value, ")") {
+		return "", nil, false
+	}
+
+	for _, v := range strings.Split(value[open+1:len(value)-1], ",") {
+		if v = strings.Trim(strings.TrimSpace(v), "'"); v != "" {
+			values = append(values, v)
+		}
+	}
+	return strings.TrimSpace(value[:open]), values, len(values) > 0
+}
+
+// HasTagFlag reports whether a tag contains key, with or without a value
+func HasTagFlag(tag, key string) bool {
+	for _, part := range SplitTag(tag) {
+		if part == key || strings.HasPrefix(part, key+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// TagValue extracts the value of key from a tag
+func TagValue(tag, key string) string {
+	for _, part := range SplitTag(tag) {
+		if strings.HasPrefix(part, key+":") {
+			return strings.TrimPrefix(part, key+":")
+		}
+	}
+	return ""
+}
+
+// SplitTag splits a jet tag on commas outside quotes and parentheses, so that
+// values like type:decimal(10,2) and enum:status(active,banned) stay whole
+func SplitTag(tag string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuote := false
+	depth := 0
+
+	for _, r := range tag {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '(' && !inQuote:
+			depth++
+		case r == ')' && !inQuote:
+			depth--
+		case r == ',' && !inQuote && depth == 0:
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	parts = append(parts, strings.TrimSpace(current.String()))
+	return parts
+}
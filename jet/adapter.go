@@ -7,6 +7,7 @@ import (
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/go-jet/jet/v2/qrm"
 	"github.com/satishbabariya/jetorm/core"
+	"github.com/satishbabariya/jetorm/jet/jetspec"
 )
 
 // SpecificationAdapter adapts core.Specification to Jet SQL
@@ -23,14 +24,7 @@ func NewSpecificationAdapter(table postgres.Table) *SpecificationAdapter {
 
 // ToJet converts a core.Specification to Jet SQL BoolExpression
 func (sa *SpecificationAdapter) ToJet(spec core.Specification[interface{}]) (postgres.BoolExpression, error) {
-	if spec == nil {
-		return postgres.Bool(true), nil
-	}
-
-	// This is a simplified adapter
-	// Full implementation would parse the specification tree and convert to Jet expressions
-	// For now, return a placeholder
-	return postgres.Bool(true), fmt.Errorf("specification conversion not yet implemented")
+	return jetspec.SpecificationToJet(spec, sa.table)
 }
 
 // RepositoryAdapter adapts Jet SQL to work with JetORM repositories
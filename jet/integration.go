@@ -167,18 +167,6 @@ func (qb *QueryBuilder) Delete() postgres.DeleteStatement {
 	return postgres.DELETE(qb.table)
 }
 
-// SpecificationToJet converts a core.Specification to Jet SQL WHERE clause
-// This is a placeholder - full implementation would parse the specification tree
-func SpecificationToJet[T any](spec core.Specification[T], table postgres.Table) (postgres.BoolExpression, error) {
-	if spec == nil {
-		return postgres.Bool(true), nil
-	}
-
-	// This is a simplified conversion
-	// Full implementation would parse the specification tree and convert to Jet expressions
-	return postgres.Bool(true), fmt.Errorf("specification conversion not yet implemented")
-}
-
 // JetToSpecification converts a Jet SQL WHERE clause to core.Specification
 // This is a placeholder - full implementation would convert Jet expressions to specifications
 func JetToSpecification[T any](expr postgres.BoolExpression) core.Specification[T] {
@@ -0,0 +1,149 @@
+// Package jetspec translates jetorm specifications into go-jet expressions,
+// so a core.Specification can filter a Jet statement.
+package jetspec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/satishbabariya/jetorm/core"
+)
+
+// SpecificationToJet converts a core.Specification to a Jet SQL boolean
+// expression by translating its syntax tree (see core.SpecAST). Conditions
+// become raw expressions over the condition's column with the values bound
+// as arguments; raw specifications keep their SQL. A nil specification
+// converts to TRUE. The table is accepted for symmetry with the other
+// adapters; columns are not qualified.
+func SpecificationToJet[T any](spec core.Specification[T], table postgres.Table) (postgres.BoolExpression, error) {
+	node := core.SpecAST(spec)
+	if node == nil {
+		return postgres.Bool(true), nil
+	}
+	return core.VisitSpec[postgres.BoolExpression](node, jetVisitor{})
+}
+
+// jetVisitor translates specification nodes into Jet expressions
+type jetVisitor struct{}
+
+// Condition renders a field comparison with its values as named arguments
+func (jetVisitor) Condition(field, op string, values []interface{}) (postgres.BoolExpression, error) {
+	args := postgres.RawArgs{}
+	arg := func(i int) string {
+		name := "#arg" + strconv.Itoa(i+1) + "#"
+		args[name] = values[i]
+		return name
+	}
+	single := func(operator string) (postgres.BoolExpression, error) {
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: %s %s needs one value", core.ErrInvalidInput, field, op)
+		}
+		return postgres.RawBool(fmt.Sprintf("%s %s %s", field, operator, arg(0)), args), nil
+	}
+
+	switch op {
+	case "eq":
+		return single("=")
+	case "ne":
+		return single("!=")
+	case "gt":
+		return single(">")
+	case "gte":
+		return single(">=")
+	case "lt":
+		return single("<")
+	case "lte":
+		return single("<=")
+	case "like":
+		return single("LIKE")
+	case "ilike":
+		return single("ILIKE")
+	case "ieq":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: %s %s needs one value", core.ErrInvalidInput, field, op)
+		}
+		return postgres.RawBool(fmt.Sprintf("LOWER(%s) = LOWER(%s)", field, arg(0)), args), nil
+	case "unaccent_eq", "unaccent_ilike":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: %s %s needs one value", core.ErrInvalidInput, field, op)
+		}
+		format := "LOWER(%[1]s(%[2]s)) = LOWER(%[1]s(%[3]s))"
+		if op == "unaccent_ilike" {
+			format = "%[1]s(%[2]s) ILIKE %[1]s(%[3]s)"
+		}
+		return postgres.RawBool(fmt.Sprintf(format, core.UnaccentFunction, field, arg(0)), args), nil
+	case "in", "not_in":
+		if len(values) == 0 {
+			// Matches nothing for IN and everything for NOT IN, like core.In
+			return postgres.Bool(op == "not_in"), nil
+		}
+		names := make([]string, len(values))
+		for i := range values {
+			names[i] = arg(i)
+		}
+		operator := "IN"
+		if op == "not_in" {
+			operator = "NOT IN"
+		}
+		return postgres.RawBool(fmt.Sprintf("%s %s (%s)", field, operator, strings.Join(names, ", ")), args), nil
+	case "between":
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%w: %s between needs two values", core.ErrInvalidInput, field)
+		}
+		return postgres.RawBool(fmt.Sprintf("%s BETWEEN %s AND %s", field, arg(0), arg(1)), args), nil
+	case "is_null":
+		return postgres.RawBool(field + " IS NULL"), nil
+	case "is_not_null":
+		return postgres.RawBool(field + " IS NOT NULL"), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported specification operator %q", core.ErrInvalidInput, op)
+}
+
+// And joins the children with AND
+func (jetVisitor) And(children []postgres.BoolExpression) (postgres.BoolExpression, error) {
+	result := children[0]
+	for _, child := range children[1:] {
+		result = result.AND(child)
+	}
+	return result, nil
+}
+
+// Or joins the children with OR
+func (jetVisitor) Or(children []postgres.BoolExpression) (postgres.BoolExpression, error) {
+	result := children[0]
+	for _, child := range children[1:] {
+		result = result.OR(child)
+	}
+	return result, nil
+}
+
+// Not negates the child
+func (jetVisitor) Not(child postgres.BoolExpression) (postgres.BoolExpression, error) {
+	return postgres.NOT(child), nil
+}
+
+// Raw keeps the SQL, turning its $n placeholders into named arguments.
+// Placeholder-like text inside literals, quoted identifiers, comments and
+// dollar-quoted strings is not an argument and is kept as it is.
+func (jetVisitor) Raw(sql string, args []interface{}) (postgres.BoolExpression, error) {
+	if len(args) == 0 {
+		return postgres.RawBool(sql), nil
+	}
+	named := postgres.RawArgs{}
+	var missing int
+	sql = core.RewritePlaceholders(sql, func(n int) string {
+		if n < 1 || n > len(args) {
+			missing = n
+			return "$" + strconv.Itoa(n)
+		}
+		name := "#arg" + strconv.Itoa(n) + "#"
+		named[name] = args[n-1]
+		return name
+	})
+	if missing != 0 {
+		return nil, fmt.Errorf("%w: $%d has no argument", core.ErrInvalidInput, missing)
+	}
+	return postgres.RawBool(sql, named), nil
+}
@@ -0,0 +1,85 @@
+package jetspec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type testUser struct {
+	ID    int64  `db:"id" jet:"primary_key"`
+	Email string `db:"email"`
+	Age   int    `db:"age"`
+}
+
+// whereSQL renders the expression as a statement's WHERE clause
+func whereSQL(t *testing.T, spec core.Specification[testUser]) (string, []interface{}) {
+	t.Helper()
+	expr, err := SpecificationToJet(spec, nil)
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	query, args := postgres.SELECT(postgres.Raw("1")).WHERE(expr).Sql()
+	_, where, _ := strings.Cut(query, "WHERE ")
+	return strings.Join(strings.Fields(where), " "), args
+}
+
+func TestSpecificationToJet(t *testing.T) {
+	t.Run("should translate conditions with bound values", func(t *testing.T) {
+		where, args := whereSQL(t, core.And(
+			core.Equal[testUser]("email", "a@example.com"),
+			core.Or(core.GreaterThan[testUser]("age", 18), core.In[testUser]("id", 1, 2)),
+			core.Not(core.IsNull[testUser]("email")),
+		))
+		expected := "((email = $1) AND ((age > $2) OR (id IN ($3, $4)))) AND (NOT (email IS NULL));"
+		if where != expected {
+			t.Errorf("Expected %q, got %q", expected, where)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"a@example.com", 18, 1, 2}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should translate unaccent conditions", func(t *testing.T) {
+		where, args := whereSQL(t, core.Or(
+			core.EqualUnaccent[testUser]("email", "José"),
+			core.ContainsUnaccent[testUser]("email", "jose"),
+		))
+		expected := "(LOWER(unaccent(email)) = LOWER(unaccent($1))) OR (unaccent(email) ILIKE unaccent($2));"
+		if where != expected {
+			t.Errorf("Expected %q, got %q", expected, where)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"José", "%jose%"}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should translate a nil specification to TRUE", func(t *testing.T) {
+		where, args := whereSQL(t, nil)
+		if where != "$1::boolean;" || !reflect.DeepEqual(args, []interface{}{true}) {
+			t.Errorf("Expected TRUE, got %q %v", where, args)
+		}
+	})
+
+	t.Run("should keep placeholders inside literals in raw SQL", func(t *testing.T) {
+		where, args := whereSQL(t, core.Where[testUser]("email = $2 AND note <> '$1' AND age > $1 AND body = $$ $1 $$", 18, "a@example.com"))
+		expected := "email = $1 AND note <> '$1' AND age > $2 AND body = $$ $1 $$;"
+		if where != expected {
+			t.Errorf("Expected %q, got %q", expected, where)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"a@example.com", 18}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should reject placeholders without arguments", func(t *testing.T) {
+		_, err := SpecificationToJet(core.Where[testUser]("age > $2", 18), nil)
+		if !errors.Is(err, core.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/satishbabariya/jetorm/hooks"
 )
 
 // SQLLogger logs SQL queries and their execution details
@@ -38,6 +40,7 @@ func (sl *SQLLogger) LogQuery(ctx context.Context, query string, args []interfac
 	if len(args) > 0 {
 		attrs = append(attrs, slog.Any("args", args))
 	}
+	attrs = append(attrs, contextAttrs(ctx)...)
 	
 	if sl.logSlow && duration > sl.slowThreshold {
 		sl.logger.Warn("Slow query detected", slog.Group("sql", attrs...))
@@ -48,18 +51,36 @@ func (sl *SQLLogger) LogQuery(ctx context.Context, query string, args []interfac
 
 // LogError logs a SQL error
 func (sl *SQLLogger) LogError(ctx context.Context, query string, err error) {
-	sl.logger.Error("SQL query error",
+	attrs := []any{
 		slog.String("query", query),
 		slog.String("error", err.Error()),
-	)
+	}
+	sl.logger.Error("SQL query error", append(attrs, contextAttrs(ctx)...)...)
 }
 
 // LogTransaction logs transaction events
 func (sl *SQLLogger) LogTransaction(ctx context.Context, event string, txID string) {
-	sl.logger.Info("Transaction event",
+	attrs := []any{
 		slog.String("event", event),
 		slog.String("tx_id", txID),
-	)
+	}
+	sl.logger.Info("Transaction event", append(attrs, contextAttrs(ctx)...)...)
+}
+
+// contextAttrs returns the actor and request ID set on ctx with
+// hooks.WithActor and hooks.WithRequestID (or their core equivalents)
+func contextAttrs(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []any
+	if actor, ok := hooks.ActorFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("actor", actor))
+	}
+	if requestID, ok := hooks.RequestIDFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	return attrs
 }
 
 // FormatQuery formats a query with arguments for logging
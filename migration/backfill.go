@@ -0,0 +1,158 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnBackfill adds a NOT NULL column to a table that already holds rows
+// without locking it for long. It is written as three migrations:
+//
+//  1. add the column as nullable and set its default, so new rows get it;
+//  2. fill existing rows in batches, committing after each batch;
+//  3. add a NOT VALID check, validate it, and promote it to NOT NULL.
+//
+// Steps 2 and 3 run outside a transaction and every step can be run again
+// after a failure.
+type ColumnBackfill struct {
+	Table     string
+	Column    string
+	Type      string // SQL type of the column, e.g. "TEXT"
+	Default   string // SQL expression new rows get, e.g. "'active'"
+	Value     string // SQL expression existing rows get, Default when empty; may refer to other columns
+	Key       string // Unique column batches are taken in order of, default "id"
+	BatchSize int    // Rows updated per transaction, default 10000
+}
+
+// validate checks that the backfill names a column and a value for it
+func (b ColumnBackfill) validate() error {
+	if b.Table == "" || b.Column == "" || b.Type == "" {
+		return fmt.Errorf("backfill needs a table, a column and a type")
+	}
+	if b.Default == "" && b.Value == "" {
+		return fmt.Errorf("backfill of %s.%s needs a default or a value", b.Table, b.Column)
+	}
+	return nil
+}
+
+// Migrations returns the three migrations of the backfill, versioned with
+// the timestamps of at and the two seconds after it
+func (b ColumnBackfill) Migrations(at time.Time) ([]Migration, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	key := b.Key
+	if key == "" {
+		key = "id"
+	}
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
+	value := b.Value
+	if value == "" {
+		value = b.Default
+	}
+	name := strings.ReplaceAll(b.Table, ".", "_") + "_" + b.Column
+	constraint := HashTruncate(name+"_not_null", MaxIdentifierLength)
+
+	add := AddColumnIfNotExists(b.Table, b.Column, b.Type)
+	if b.Default != "" {
+		add += fmt.Sprintf("\nALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", b.Table, b.Column, b.Default)
+	}
+
+	// Batches are key ranges, so rows the value leaves NULL cannot loop forever
+	backfill := fmt.Sprintf(`%s
+DO $$
+DECLARE
+    last_key %[2]s.%[3]s%%TYPE;
+    upper_key last_key%%TYPE;
+BEGIN
+    LOOP
+        SELECT max(%[3]s) INTO upper_key FROM (
+            SELECT %[3]s FROM %[2]s WHERE last_key IS NULL OR %[3]s > last_key ORDER BY %[3]s LIMIT %[4]d
+        ) batch;
+        EXIT WHEN upper_key IS NULL;
+        UPDATE %[2]s SET %[5]s = %[6]s
+        WHERE %[5]s IS NULL AND (last_key IS NULL OR %[3]s > last_key) AND %[3]s <= upper_key;
+        last_key := upper_key;
+        COMMIT;
+    END LOOP;
+END $$;`, NoTransactionDirective, b.Table, key, batchSize, b.Column, value)
+
+	// Validating the check scans the table without blocking writes, and lets
+	// SET NOT NULL skip its own scan
+	promote := fmt.Sprintf(`%s
+DO $$
+BEGIN
+    IF NOT (SELECT attnotnull FROM pg_attribute WHERE attrelid = to_regclass(%[2]s) AND attname = %[3]s) THEN
+        IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass(%[2]s) AND conname = %[4]s) THEN
+            ALTER TABLE %[5]s ADD CONSTRAINT %[6]s CHECK (%[7]s IS NOT NULL) NOT VALID;
+        END IF;
+        COMMIT;
+        ALTER TABLE %[5]s VALIDATE CONSTRAINT %[6]s;
+        COMMIT;
+        ALTER TABLE %[5]s ALTER COLUMN %[7]s SET NOT NULL;
+        ALTER TABLE %[5]s DROP CONSTRAINT %[6]s;
+    END IF;
+END $$;`, NoTransactionDirective, quoteLiteral(b.Table), quoteLiteral(b.Column), quoteLiteral(constraint),
+		b.Table, constraint, b.Column)
+
+	return []Migration{
+		{
+			Version: backfillVersion(at, 0),
+			Name:    "add_" + name,
+			UpSQL:   add,
+			DownSQL: DropColumnIfExists(b.Table, b.Column),
+		},
+		{
+			Version: backfillVersion(at, 1),
+			Name:    "backfill_" + name,
+			UpSQL:   backfill,
+			DownSQL: "-- The backfilled values are dropped with the column by the add migration",
+		},
+		{
+			Version: backfillVersion(at, 2),
+			Name:    "require_" + name,
+			UpSQL:   promote,
+			DownSQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;\n%s",
+				b.Table, b.Column, DropConstraintIfExists(b.Table, constraint)),
+		},
+	}, nil
+}
+
+// backfillVersion returns the timestamp version of step seconds after at
+func backfillVersion(at time.Time, step int) int64 {
+	version, _ := strconv.ParseInt(at.Add(time.Duration(step)*time.Second).Format("20060102150405"), 10, 64)
+	return version
+}
+
+// GenerateBackfillMigrations writes the migration files of a ColumnBackfill
+// to migrationsDir and returns their paths
+func (g *Generator) GenerateBackfillMigrations(backfill ColumnBackfill, migrationsDir string) ([]string, error) {
+	migrations, err := backfill.Migrations(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	var files []string
+	for _, m := range migrations {
+		for _, file := range []struct{ direction, body string }{{"up", m.UpSQL}, {"down", m.DownSQL}} {
+			path := filepath.Join(migrationsDir, fmt.Sprintf("%d_%s.%s.sql", m.Version, m.Name, file.direction))
+			content := fmt.Sprintf("-- Backfill %s.%s: %s\n-- Generated: %s\n\n%s\n",
+				backfill.Table, backfill.Column, m.Name, time.Now().Format(time.RFC3339), file.body)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return files, fmt.Errorf("failed to write %s migration: %w", file.direction, err)
+			}
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/satishbabariya/jetorm/migration"
@@ -50,25 +51,60 @@ var migrationCommands = []Command{
 	},
 }
 
+// Rollback safety flags, see Runner.SetForce and Runner.SetBackupDir
+var (
+	rollbackForce     bool
+	rollbackBackupDir string
+)
+
+// newRunner creates a runner that renders migration progress to stdout
+func newRunner(db *sql.DB, migrationsDir string) *migration.Runner {
+	runner := migration.NewRunner(db, migrationsDir)
+	runner.SetEventSink(migration.NewWriterEventSink(os.Stdout))
+	runner.SetAppVersion(os.Getenv("JETORM_APP_VERSION"))
+	runner.SetForce(rollbackForce)
+	runner.SetBackupDir(rollbackBackupDir)
+	return runner
+}
+
+// statusLine describes a migration's status with its audit details
+func statusLine(status migration.MigrationStatus) string {
+	line := status.Status
+	if status.AppliedAt == nil {
+		return line
+	}
+	line += fmt.Sprintf(" (%s", status.AppliedAt.Format("2006-01-02 15:04:05"))
+	if status.AppliedBy != "" {
+		line += fmt.Sprintf(" in %s by %s", status.Duration, status.AppliedBy)
+		if status.Hostname != "" {
+			line += "@" + status.Hostname
+		}
+	}
+	if status.AppVersion != "" {
+		line += ", version " + status.AppVersion
+	}
+	return line + ")"
+}
+
 // cmdCreate creates a new migration
 func cmdCreate(ctx context.Context, db *sql.DB, migrationsDir string, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("migration name is required")
 	}
 
-	runner := migration.NewRunner(nil, migrationsDir)
+	runner := newRunner(nil, migrationsDir)
 	return runner.CreateMigration(args[0])
 }
 
 // cmdUp applies migrations
 func cmdUp(ctx context.Context, db *sql.DB, migrationsDir string, args []string) error {
-	runner := migration.NewRunner(db, migrationsDir)
+	runner := newRunner(db, migrationsDir)
 	return runner.Up(ctx)
 }
 
 // cmdDown rolls back last migration
 func cmdDown(ctx context.Context, db *sql.DB, migrationsDir string, args []string) error {
-	runner := migration.NewRunner(db, migrationsDir)
+	runner := newRunner(db, migrationsDir)
 	return runner.Down(ctx)
 }
 
@@ -83,13 +119,13 @@ func cmdDownTo(ctx context.Context, db *sql.DB, migrationsDir string, args []str
 		return fmt.Errorf("invalid version: %w", err)
 	}
 
-	runner := migration.NewRunner(db, migrationsDir)
+	runner := newRunner(db, migrationsDir)
 	return runner.DownTo(ctx, version)
 }
 
 // cmdStatus shows migration status
 func cmdStatus(ctx context.Context, db *sql.DB, migrationsDir string, args []string) error {
-	runner := migration.NewRunner(db, migrationsDir)
+	runner := newRunner(db, migrationsDir)
 	statuses, err := runner.Status(ctx)
 	if err != nil {
 		return err
@@ -98,11 +134,7 @@ func cmdStatus(ctx context.Context, db *sql.DB, migrationsDir string, args []str
 	fmt.Println("Migration Status:")
 	fmt.Println("=================")
 	for _, status := range statuses {
-		statusStr := status.Status
-		if status.AppliedAt != nil {
-			statusStr += fmt.Sprintf(" (%s)", status.AppliedAt.Format("2006-01-02 15:04:05"))
-		}
-		fmt.Printf("%d - %s: %s\n", status.Version, status.Name, statusStr)
+		fmt.Printf("%d - %s: %s\n", status.Version, status.Name, statusLine(status))
 	}
 
 	return nil
@@ -110,7 +142,7 @@ func cmdStatus(ctx context.Context, db *sql.DB, migrationsDir string, args []str
 
 // cmdValidate validates migrations
 func cmdValidate(ctx context.Context, db *sql.DB, migrationsDir string, args []string) error {
-	runner := migration.NewRunner(db, migrationsDir)
+	runner := newRunner(db, migrationsDir)
 	return runner.ValidateMigrations(ctx)
 }
 
@@ -126,6 +158,8 @@ func printMigrationUsage() {
 	fmt.Println("  -dir string       Migrations directory (default: ./migrations)")
 	fmt.Println("  -to int64         Target version for down-to command")
 	fmt.Println("  -name string      Migration name for create command")
+	fmt.Println("  -force            Roll back migrations that drop tables or columns")
+	fmt.Println("  -backup-dir string  Back up tables destroyed by a rollback to this directory")
 }
 
 // executeMigrationCommand executes a migration command
@@ -8,7 +8,6 @@ import (
 	"os"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/satishbabariya/jetorm/migration"
 )
 
 func main() {
@@ -25,6 +24,8 @@ func main() {
 		targetVersion = flag.Int64("to", 0, "Target version for down-to command")
 		migrationName = flag.String("name", "", "Migration name for create command")
 	)
+	flag.BoolVar(&rollbackForce, "force", false, "Roll back migrations that drop tables or columns without a backup")
+	flag.StringVar(&rollbackBackupDir, "backup-dir", "", "Back up tables that a rollback destroys data of to this directory")
 	flag.Parse()
 
 	if *command == "" {
@@ -71,7 +72,7 @@ func main() {
 		}
 		
 		// Create migration files (no DB needed)
-		runner := migration.NewRunner(nil, *migrationsDir)
+		runner := newRunner(nil, *migrationsDir)
 		if err := runner.CreateMigration(*migrationName); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating migration: %v\n", err)
 			os.Exit(1)
@@ -86,7 +87,7 @@ func main() {
 		}
 		defer db.Close()
 
-		runner := migration.NewRunner(db, *migrationsDir)
+		runner := newRunner(db, *migrationsDir)
 		if err := runner.Up(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Error applying migrations: %v\n", err)
 			os.Exit(1)
@@ -101,7 +102,7 @@ func main() {
 		}
 		defer db.Close()
 
-		runner := migration.NewRunner(db, *migrationsDir)
+		runner := newRunner(db, *migrationsDir)
 		if err := runner.Down(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Error rolling back migration: %v\n", err)
 			os.Exit(1)
@@ -121,7 +122,7 @@ func main() {
 		}
 		defer db.Close()
 
-		runner := migration.NewRunner(db, *migrationsDir)
+		runner := newRunner(db, *migrationsDir)
 		if err := runner.DownTo(ctx, *targetVersion); err != nil {
 			fmt.Fprintf(os.Stderr, "Error rolling back migrations: %v\n", err)
 			os.Exit(1)
@@ -136,7 +137,7 @@ func main() {
 		}
 		defer db.Close()
 
-		runner := migration.NewRunner(db, *migrationsDir)
+		runner := newRunner(db, *migrationsDir)
 		statuses, err := runner.Status(ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting migration status: %v\n", err)
@@ -146,11 +147,7 @@ func main() {
 		fmt.Println("Migration Status:")
 		fmt.Println("=================")
 		for _, status := range statuses {
-			statusStr := status.Status
-			if status.AppliedAt != nil {
-				statusStr += fmt.Sprintf(" (%s)", status.AppliedAt.Format("2006-01-02 15:04:05"))
-			}
-			fmt.Printf("%d - %s: %s\n", status.Version, status.Name, statusStr)
+			fmt.Printf("%d - %s: %s\n", status.Version, status.Name, statusLine(status))
 		}
 
 	case "validate":
@@ -161,7 +158,7 @@ func main() {
 		}
 		defer db.Close()
 
-		runner := migration.NewRunner(db, *migrationsDir)
+		runner := newRunner(db, *migrationsDir)
 		if err := runner.ValidateMigrations(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
 			os.Exit(1)
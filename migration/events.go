@@ -0,0 +1,130 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Direction is the direction a migration runs in
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// MigrationEvent describes a migration as it is applied or rolled back
+type MigrationEvent struct {
+	Version   int64
+	Name      string
+	Direction Direction
+	Duration  time.Duration // Time spent running the migration, zero on start
+}
+
+// EventSink receives migration progress from a Runner. Implementations can
+// render progress in a CLI or forward it to an application's logger or metrics.
+type EventSink interface {
+	OnMigrationStart(ctx context.Context, event MigrationEvent)
+	OnMigrationApplied(ctx context.Context, event MigrationEvent)
+	OnMigrationFailed(ctx context.Context, event MigrationEvent, err error)
+	OnMigrationWarning(ctx context.Context, event MigrationEvent, message string)
+}
+
+// NopEventSink discards all migration events
+type NopEventSink struct{}
+
+func (NopEventSink) OnMigrationStart(context.Context, MigrationEvent)           {}
+func (NopEventSink) OnMigrationApplied(context.Context, MigrationEvent)         {}
+func (NopEventSink) OnMigrationFailed(context.Context, MigrationEvent, error)   {}
+func (NopEventSink) OnMigrationWarning(context.Context, MigrationEvent, string) {}
+
+// WriterEventSink renders migration progress as plain text lines
+type WriterEventSink struct {
+	w io.Writer
+}
+
+// NewWriterEventSink creates an event sink writing progress lines to w
+func NewWriterEventSink(w io.Writer) *WriterEventSink {
+	return &WriterEventSink{w: w}
+}
+
+// OnMigrationStart implements EventSink
+func (s *WriterEventSink) OnMigrationStart(ctx context.Context, event MigrationEvent) {
+	fmt.Fprintf(s.w, "%s %d (%s)...\n", runningVerb(event.Direction), event.Version, event.Name)
+}
+
+// OnMigrationApplied implements EventSink
+func (s *WriterEventSink) OnMigrationApplied(ctx context.Context, event MigrationEvent) {
+	fmt.Fprintf(s.w, "%s %d (%s) in %s\n", doneVerb(event.Direction), event.Version, event.Name, event.Duration.Round(time.Millisecond))
+}
+
+// OnMigrationFailed implements EventSink
+func (s *WriterEventSink) OnMigrationFailed(ctx context.Context, event MigrationEvent, err error) {
+	fmt.Fprintf(s.w, "Failed %d (%s) after %s: %v\n", event.Version, event.Name, event.Duration.Round(time.Millisecond), err)
+}
+
+// OnMigrationWarning implements EventSink
+func (s *WriterEventSink) OnMigrationWarning(ctx context.Context, event MigrationEvent, message string) {
+	fmt.Fprintf(s.w, "Warning: migration %d (%s) %s\n", event.Version, event.Name, message)
+}
+
+func runningVerb(direction Direction) string {
+	if direction == DirectionDown {
+		return "Rolling back"
+	}
+	return "Applying"
+}
+
+func doneVerb(direction Direction) string {
+	if direction == DirectionDown {
+		return "Rolled back"
+	}
+	return "Applied"
+}
+
+// LogEventSink logs migration events with structured attributes
+type LogEventSink struct {
+	logger *slog.Logger
+}
+
+// NewLogEventSink creates an event sink logging to logger
+func NewLogEventSink(logger *slog.Logger) *LogEventSink {
+	return &LogEventSink{logger: logger}
+}
+
+// OnMigrationStart implements EventSink
+func (s *LogEventSink) OnMigrationStart(ctx context.Context, event MigrationEvent) {
+	s.logger.InfoContext(ctx, "Migration started", eventAttrs(event)...)
+}
+
+// OnMigrationApplied implements EventSink
+func (s *LogEventSink) OnMigrationApplied(ctx context.Context, event MigrationEvent) {
+	attrs := append(eventAttrs(event), slog.Duration("duration", event.Duration))
+	s.logger.InfoContext(ctx, "Migration applied", attrs...)
+}
+
+// OnMigrationFailed implements EventSink
+func (s *LogEventSink) OnMigrationFailed(ctx context.Context, event MigrationEvent, err error) {
+	attrs := append(eventAttrs(event),
+		slog.Duration("duration", event.Duration),
+		slog.String("error", err.Error()),
+	)
+	s.logger.ErrorContext(ctx, "Migration failed", attrs...)
+}
+
+// OnMigrationWarning implements EventSink
+func (s *LogEventSink) OnMigrationWarning(ctx context.Context, event MigrationEvent, message string) {
+	attrs := append(eventAttrs(event), slog.String("warning", message))
+	s.logger.WarnContext(ctx, "Migration warning", attrs...)
+}
+
+func eventAttrs(event MigrationEvent) []any {
+	return []any{
+		slog.Int64("version", event.Version),
+		slog.String("name", event.Name),
+		slog.String("direction", string(event.Direction)),
+	}
+}
@@ -12,13 +12,23 @@ import (
 // Generator generates migration files from entity definitions
 type Generator struct {
 	schemaGen *SchemaGenerator
+	namer     *Namer // Names indexes and constraints, shared with schemaGen
 }
 
 // NewGenerator creates a new migration generator
 func NewGenerator() *Generator {
-	return &Generator{
+	g := &Generator{
 		schemaGen: NewSchemaGenerator(),
 	}
+	g.SetNamer(NewNamer())
+	return g
+}
+
+// SetNamer sets the namer of generated index and constraint names. Names are
+// checked for collisions across every migration the generator writes.
+func (g *Generator) SetNamer(namer *Namer) {
+	g.namer = namer
+	g.schemaGen.SetNamer(namer)
 }
 
 // GenerateCreateTableMigration generates a CREATE TABLE migration from an entity type
@@ -33,8 +43,11 @@ func (g *Generator) GenerateCreateTableMigration(entityType reflect.Type, tableN
 		return fmt.Errorf("failed to generate CREATE TABLE: %w", err)
 	}
 
-	// Generate DROP TABLE SQL for down migration
+	// Generate DROP TABLE SQL for down migration, then drop the table's enum types
 	dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)
+	for _, enum := range g.schemaGen.enumTypes(entityType) {
+		dropSQL += fmt.Sprintf("\nDROP TYPE IF EXISTS %s;", enum.name)
+	}
 
 	// Create migration files
 	version := time.Now().Format("20060102150405")
@@ -99,6 +112,11 @@ func (g *Generator) GenerateAlterTableMigration(tableName string, alterSQL strin
 
 // GenerateIndexMigration generates a CREATE INDEX migration
 func (g *Generator) GenerateIndexMigration(tableName string, indexName string, columns []string, unique bool, migrationsDir string) error {
+	indexName, err := g.namer.Explicit(indexName)
+	if err != nil {
+		return err
+	}
+
 	version := time.Now().Format("20060102150405")
 	sanitizedName := strings.ToLower(strings.ReplaceAll(indexName, " ", "_"))
 	
@@ -139,8 +157,12 @@ func (g *Generator) GenerateIndexMigration(tableName string, indexName string, c
 
 // GenerateForeignKeyMigration generates a FOREIGN KEY migration
 func (g *Generator) GenerateForeignKeyMigration(tableName string, columnName string, refTable string, refColumn string, onDelete string, onUpdate string, migrationsDir string) error {
+	fkName, err := g.namer.Name(fmt.Sprintf("fk_%s_%s", tableName, columnName))
+	if err != nil {
+		return err
+	}
+
 	version := time.Now().Format("20060102150405")
-	fkName := fmt.Sprintf("fk_%s_%s", tableName, columnName)
 	sanitizedName := strings.ToLower(strings.ReplaceAll(fkName, " ", "_"))
 	
 	upFileName := fmt.Sprintf("%s_add_foreign_key_%s.up.sql", version, sanitizedName)
@@ -0,0 +1,130 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Guarded DDL helpers return statements that succeed whether or not they
+// have run before, so a migration that failed half-way can simply be run
+// again. Table names may be schema-qualified. Names are written as given;
+// they are meant for migration authors, not user input.
+
+// NoTransactionDirective marks an up or down migration file whose SQL must
+// run outside a transaction, such as CREATE INDEX CONCURRENTLY. Such a file
+// should hold a single statement.
+const NoTransactionDirective = "-- jetorm:no-transaction"
+
+// AddColumnIfNotExists adds a column unless the table already has it
+func AddColumnIfNotExists(table, column, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;", table, column, definition)
+}
+
+// DropColumnIfExists drops a column if the table has it
+func DropColumnIfExists(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, column)
+}
+
+// CreateIndexConcurrentlyIfNotExists builds an index without blocking writes
+// unless it already exists. It cannot run in a transaction: put it alone in a
+// migration file starting with NoTransactionDirective. A failed concurrent
+// build leaves an invalid index behind that IF NOT EXISTS would skip; see
+// DropInvalidIndex.
+func CreateIndexConcurrentlyIfNotExists(index, table string, unique bool, columns ...string) string {
+	uniqueClause := ""
+	if unique {
+		uniqueClause = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s);",
+		uniqueClause, index, table, strings.Join(columns, ", "))
+}
+
+// DropInvalidIndex drops an index left invalid by a failed concurrent build,
+// and does nothing when the index is missing or valid. Run it before retrying
+// CreateIndexConcurrentlyIfNotExists.
+func DropInvalidIndex(index string) string {
+	return guardedBlock(
+		fmt.Sprintf("EXISTS (SELECT 1 FROM pg_index WHERE indexrelid = to_regclass(%s) AND NOT indisvalid)", quoteLiteral(index)),
+		fmt.Sprintf("DROP INDEX %s", index),
+	)
+}
+
+// DropIndexConcurrentlyIfExists drops an index without blocking writes. Like
+// CreateIndexConcurrentlyIfNotExists it needs NoTransactionDirective.
+func DropIndexConcurrentlyIfExists(index string) string {
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s;", index)
+}
+
+// RenameColumnIfExists renames a column when the old name exists and the new
+// one does not. When the rename already happened it does nothing, so the
+// migration can be run again, and when neither column exists it fails.
+func RenameColumnIfExists(table, from, to string) string {
+	return guardedBlock(
+		fmt.Sprintf("%s AND NOT %s", columnExists(table, from), columnExists(table, to)),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, from, to),
+	) + "\n" + guardedBlock(
+		"NOT "+columnExists(table, to),
+		fmt.Sprintf("RAISE EXCEPTION 'column %%.%% does not exist', %s, %s", quoteLiteral(table), quoteLiteral(to)),
+	)
+}
+
+// RenameTableIfExists renames a table when the old name exists and the new one
+// does not, and does nothing when the rename already happened
+func RenameTableIfExists(from, to string) string {
+	return guardedBlock(
+		fmt.Sprintf("to_regclass(%s) IS NOT NULL AND to_regclass(%s) IS NULL", quoteLiteral(from), quoteLiteral(to)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", from, to),
+	)
+}
+
+// AddConstraintIfNotExists adds a named constraint unless the table already
+// has one of that name, as ADD CONSTRAINT has no IF NOT EXISTS
+func AddConstraintIfNotExists(table, constraint, definition string) string {
+	return guardedBlock(
+		fmt.Sprintf("NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass(%s) AND conname = %s)",
+			quoteLiteral(table), quoteLiteral(constraint)),
+		fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", table, constraint, definition),
+	)
+}
+
+// DropConstraintIfExists drops a named constraint if the table has it
+func DropConstraintIfExists(table, constraint string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", table, constraint)
+}
+
+// CreateEnumTypeIfNotExists creates an enum type unless it already exists, as
+// CREATE TYPE has no IF NOT EXISTS
+func CreateEnumTypeIfNotExists(name string, values ...string) string {
+	return enumType{name: name, values: values}.createSQL()
+}
+
+// guardedBlock runs statement in a DO block when condition holds
+func guardedBlock(condition, statement string) string {
+	return fmt.Sprintf("DO $$\nBEGIN\n    IF %s THEN\n        %s;\n    END IF;\nEND $$;", condition, statement)
+}
+
+// columnExists is a condition that holds when the table has the column
+func columnExists(table, column string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = to_regclass(%s) AND attname = %s AND NOT attisdropped)",
+		quoteLiteral(table), quoteLiteral(column))
+}
+
+// quoteLiteral quotes s as a SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// noTransaction reports whether migration SQL starts with NoTransactionDirective,
+// ignoring other leading comment lines
+func noTransaction(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line == NoTransactionDirective {
+			return true
+		}
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return false
+}
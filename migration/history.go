@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// auditColumns are the columns of the migrations table that record who applied
+// each migration, from where and how long it took. They are added to tables
+// created by earlier versions, whose rows keep NULL values.
+var auditColumns = []struct{ name, definition string }{
+	{"duration_ms", "BIGINT"},
+	{"app_version", "VARCHAR(255)"},
+	{"applied_by", "VARCHAR(255)"},
+	{"hostname", "VARCHAR(255)"},
+}
+
+// execer is satisfied by *sql.DB, *sql.Conn and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SetAppVersion sets the application version recorded with each migration
+// applied from now on, such as a release tag or commit hash
+func (m *Migrator) SetAppVersion(version string) {
+	m.appVersion = version
+}
+
+// SetAppliedBy sets the user recorded with each migration applied from now
+// on. By default it is the operating system user, or the database user when
+// that is unknown.
+func (m *Migrator) SetAppliedBy(name string) {
+	m.appliedBy = name
+}
+
+// upgradeTable adds the audit columns missing from the migrations table
+func (m *Migrator) upgradeTable(ctx context.Context) error {
+	if m.upgraded {
+		return nil
+	}
+	clauses := make([]string, len(auditColumns))
+	for i, column := range auditColumns {
+		clauses[i] = fmt.Sprintf("ADD COLUMN IF NOT EXISTS %s %s", column.name, column.definition)
+	}
+	query := fmt.Sprintf("ALTER TABLE %s %s", m.tableName, strings.Join(clauses, ", "))
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to upgrade %s: %w", m.tableName, err)
+	}
+	m.upgraded = true
+	return nil
+}
+
+// record inserts the migrations table row for an applied migration
+func (m *Migrator) record(ctx context.Context, db execer, migration Migration, duration time.Duration) error {
+	query := fmt.Sprintf(`INSERT INTO %s (version, name, applied_at, duration_ms, app_version, applied_by, hostname)
+		VALUES ($1, $2, NOW(), $3, NULLIF($4, ''), COALESCE(NULLIF($5, ''), current_user), NULLIF($6, ''))`, m.tableName)
+
+	appliedBy := m.appliedBy
+	if appliedBy == "" {
+		appliedBy = osUser()
+	}
+	hostname, _ := os.Hostname()
+
+	_, err := db.ExecContext(ctx, query, migration.Version, migration.Name,
+		duration.Milliseconds(), m.appVersion, appliedBy, hostname)
+	return err
+}
+
+// osUser returns the name of the user running the process, or "" if unknown
+func osUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// scanApplied reads a migrations table row selected by GetAppliedMigrations
+func scanApplied(rows *sql.Rows) (Migration, error) {
+	var (
+		migration  Migration
+		durationMs sql.NullInt64
+		appVersion sql.NullString
+		appliedBy  sql.NullString
+		hostname   sql.NullString
+	)
+	err := rows.Scan(&migration.Version, &migration.Name, &migration.AppliedAt,
+		&durationMs, &appVersion, &appliedBy, &hostname)
+	migration.Duration = time.Duration(durationMs.Int64) * time.Millisecond
+	migration.AppVersion = appVersion.String
+	migration.AppliedBy = appliedBy.String
+	migration.Hostname = hostname.String
+	return migration, err
+}
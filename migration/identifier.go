@@ -0,0 +1,116 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength is the longest identifier PostgreSQL keeps with the
+// default NAMEDATALEN of 64. Longer names are silently truncated, so two
+// generated names sharing their first 63 bytes would refer to one object.
+const MaxIdentifierLength = 63
+
+// identifierHashLength is the number of hex digits of the hash that
+// HashTruncate appends
+const identifierHashLength = 8
+
+// ErrIdentifierCollision is returned when two different names shorten to
+// the same identifier
+var ErrIdentifierCollision = errors.New("migration: identifier collision")
+
+// ErrIdentifierTooLong is returned for an explicitly given name longer than
+// the identifier limit, which is never shortened
+var ErrIdentifierTooLong = errors.New("migration: identifier too long")
+
+// HashTruncate shortens name to at most maxLength bytes. Longer names keep
+// as much of their start as fits and end in _ and the first 8 hex digits of
+// the name's SHA-256, so the result is the same on every run and differs
+// between names that share a prefix.
+//
+//	HashTruncate("idx_customer_subscription_invoices_billing_address_line_two_lower", 63)
+//	// idx_customer_subscription_invoices_billing_address_lin_dcc25786
+func HashTruncate(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:])[:identifierHashLength]
+
+	keep := maxLength - len(suffix)
+	if keep < 0 {
+		return suffix[1 : 1+min(maxLength, identifierHashLength)]
+	}
+	// Do not split a multi-byte character
+	for keep > 0 && !utf8.RuneStart(name[keep]) {
+		keep--
+	}
+	return name[:keep] + suffix
+}
+
+// Namer gives generated identifiers, such as index and constraint names,
+// their final form. Names longer than the limit are shortened by the
+// truncation function, and every name handed out is remembered so that
+// two different names never end up as the same identifier.
+type Namer struct {
+	maxLength int
+	truncate  func(name string, maxLength int) string
+	names     map[string]string // Identifier -> name it was made from
+}
+
+// NewNamer creates a namer for MaxIdentifierLength shortening with
+// HashTruncate
+func NewNamer() *Namer {
+	return &Namer{
+		maxLength: MaxIdentifierLength,
+		truncate:  HashTruncate,
+		names:     make(map[string]string),
+	}
+}
+
+// SetMaxLength sets the identifier limit, for servers built with a
+// different NAMEDATALEN or to leave room for suffixes
+func (n *Namer) SetMaxLength(maxLength int) {
+	if maxLength > 0 {
+		n.maxLength = maxLength
+	}
+}
+
+// SetTruncateFunc sets how names longer than the limit are shortened. The
+// function must be deterministic and return at most maxLength bytes.
+func (n *Namer) SetTruncateFunc(truncate func(name string, maxLength int) string) {
+	if truncate != nil {
+		n.truncate = truncate
+	}
+}
+
+// Name returns the identifier for a generated name, shortened if needed. It
+// fails with ErrIdentifierCollision when another name was given the same
+// identifier.
+func (n *Namer) Name(name string) (string, error) {
+	identifier := n.truncate(name, n.maxLength)
+	if len(identifier) > n.maxLength {
+		return "", fmt.Errorf("%w: %q shortened to %q, still longer than %d bytes", ErrIdentifierTooLong, name, identifier, n.maxLength)
+	}
+	return identifier, n.claim(identifier, name)
+}
+
+// Explicit checks a name given by the user, which is used as written: it
+// must fit the limit and not collide with another identifier
+func (n *Namer) Explicit(name string) (string, error) {
+	if len(name) > n.maxLength {
+		return "", fmt.Errorf("%w: %q is %d bytes, the limit is %d", ErrIdentifierTooLong, name, len(name), n.maxLength)
+	}
+	return name, n.claim(name, name)
+}
+
+// claim records that identifier stands for name
+func (n *Namer) claim(identifier, name string) error {
+	if previous, ok := n.names[identifier]; ok && previous != name {
+		return fmt.Errorf("%w: %q and %q are both named %s", ErrIdentifierCollision, previous, name, identifier)
+	}
+	n.names[identifier] = name
+	return nil
+}
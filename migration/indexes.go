@@ -0,0 +1,277 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// IndexSpec describes an index for an IndexBuilder to create
+type IndexSpec struct {
+	Name    string
+	Table   string
+	Columns []string
+	Unique  bool
+}
+
+// createSQL returns the statement building the index
+func (s IndexSpec) createSQL() string {
+	return CreateIndexConcurrentlyIfNotExists(s.Name, s.Table, s.Unique, s.Columns...)
+}
+
+// validate checks that the spec names an index, a table and columns
+func (s IndexSpec) validate() error {
+	if s.Name == "" || s.Table == "" || len(s.Columns) == 0 {
+		return fmt.Errorf("index %q needs a name, a table and columns", s.Name)
+	}
+	return nil
+}
+
+// IndexState is the state of an index reported by an IndexBuilder
+type IndexState string
+
+const (
+	IndexStarted  IndexState = "started"
+	IndexBuilding IndexState = "building"
+	IndexRetrying IndexState = "retrying"
+	IndexDone     IndexState = "done"
+	IndexFailed   IndexState = "failed"
+)
+
+// IndexProgress reports the progress of one index. While building, Phase and
+// the counters come from pg_stat_progress_create_index.
+type IndexProgress struct {
+	Index       string
+	State       IndexState
+	Attempt     int // 1 for the first build
+	Phase       string
+	BlocksDone  int64
+	BlocksTotal int64
+	TuplesDone  int64
+	TuplesTotal int64
+	Elapsed     time.Duration
+	Err         error // Set when State is IndexRetrying or IndexFailed
+}
+
+// IndexBuilder creates indexes with CREATE INDEX CONCURRENTLY, several at a
+// time, so that large tables stay writable while they are indexed. Each build
+// runs on its own connection outside a transaction. A build that fails leaves
+// an invalid index behind; the builder drops it and tries again.
+type IndexBuilder struct {
+	db           *sql.DB
+	parallelism  int
+	retries      int
+	pollInterval time.Duration
+	progress     func(IndexProgress)
+}
+
+// NewIndexBuilder creates an index builder that runs two builds at a time,
+// retries a failed build once and polls progress every two seconds
+func NewIndexBuilder(db *sql.DB) *IndexBuilder {
+	return &IndexBuilder{
+		db:           db,
+		parallelism:  2,
+		retries:      1,
+		pollInterval: 2 * time.Second,
+		progress:     func(IndexProgress) {},
+	}
+}
+
+// SetParallelism sets how many indexes are built at the same time. Each
+// build uses two connections: one building and one polling its progress.
+func (b *IndexBuilder) SetParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	b.parallelism = n
+}
+
+// SetRetries sets how many times a failed build is retried
+func (b *IndexBuilder) SetRetries(n int) {
+	b.retries = n
+}
+
+// SetPollInterval sets how often the progress of running builds is polled
+func (b *IndexBuilder) SetPollInterval(d time.Duration) {
+	b.pollInterval = d
+}
+
+// SetProgressFunc sets the function receiving progress reports. It is
+// called from several goroutines at once.
+func (b *IndexBuilder) SetProgressFunc(fn func(IndexProgress)) {
+	if fn == nil {
+		fn = func(IndexProgress) {}
+	}
+	b.progress = fn
+}
+
+// Build creates the indexes that do not exist yet. Every index is attempted
+// even when others fail; the returned error joins the failures.
+func (b *IndexBuilder) Build(ctx context.Context, specs ...IndexSpec) error {
+	for _, spec := range specs {
+		if err := spec.validate(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	slots := make(chan struct{}, b.parallelism)
+	for _, spec := range specs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+
+		wg.Add(1)
+		go func(spec IndexSpec) {
+			defer func() { <-slots; wg.Done() }()
+			if err := b.build(ctx, spec); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("index %s: %w", spec.Name, err))
+				mu.Unlock()
+			}
+		}(spec)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// build creates one index, retrying failed attempts
+func (b *IndexBuilder) build(ctx context.Context, spec IndexSpec) error {
+	start := time.Now()
+	report := IndexProgress{Index: spec.Name}
+
+	var err error
+	for attempt := 1; attempt <= b.retries+1; attempt++ {
+		report.Attempt = attempt
+		report.State = IndexStarted
+		report.Elapsed = time.Since(start)
+		b.progress(report)
+
+		if err = b.attempt(ctx, spec, start, report); err == nil {
+			report.State = IndexDone
+			report.Elapsed = time.Since(start)
+			b.progress(report)
+			return nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if attempt <= b.retries {
+			report.State = IndexRetrying
+			report.Err = err
+			b.progress(report)
+			report.Err = nil
+		}
+	}
+
+	report.State = IndexFailed
+	report.Err = err
+	report.Elapsed = time.Since(start)
+	b.progress(report)
+	return err
+}
+
+// attempt drops an invalid leftover of the index, builds it while polling its
+// progress, and checks that the result is valid
+func (b *IndexBuilder) attempt(ctx context.Context, spec IndexSpec, start time.Time, report IndexProgress) error {
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, DropInvalidIndex(spec.Name)); err != nil {
+		return fmt.Errorf("failed to drop invalid index: %w", err)
+	}
+
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		b.poll(ctx, pid, start, report, done)
+	}()
+	_, err = conn.ExecContext(ctx, spec.createSQL())
+	close(done)
+	<-polled
+	if err != nil {
+		return err
+	}
+
+	var valid bool
+	query := "SELECT indisvalid FROM pg_index WHERE indexrelid = to_regclass($1)"
+	if err := conn.QueryRowContext(ctx, query, spec.Name).Scan(&valid); err != nil {
+		return fmt.Errorf("failed to check index: %w", err)
+	}
+	if !valid {
+		return fmt.Errorf("index is invalid after the build")
+	}
+	return nil
+}
+
+// poll reports the progress of the build running in backend pid until done
+func (b *IndexBuilder) poll(ctx context.Context, pid int, start time.Time, report IndexProgress, done <-chan struct{}) {
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+
+	query := `SELECT phase, blocks_done, blocks_total, tuples_done, tuples_total
+		FROM pg_stat_progress_create_index WHERE pid = $1`
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := b.db.QueryRowContext(ctx, query, pid).Scan(&report.Phase,
+			&report.BlocksDone, &report.BlocksTotal, &report.TuplesDone, &report.TuplesTotal)
+		if err != nil {
+			continue // Not started yet, already finished, or not visible
+		}
+		report.State = IndexBuilding
+		report.Elapsed = time.Since(start)
+		b.progress(report)
+	}
+}
+
+// NewIndexProgressWriter returns a progress function for SetProgressFunc that
+// writes one line per report to w
+func NewIndexProgressWriter(w io.Writer) func(IndexProgress) {
+	var mu sync.Mutex
+	return func(p IndexProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch p.State {
+		case IndexBuilding:
+			percent := 0.0
+			if p.BlocksTotal > 0 {
+				percent = float64(p.BlocksDone) / float64(p.BlocksTotal) * 100
+			}
+			fmt.Fprintf(w, "Index %s: %s, %.0f%% of blocks (%s)\n", p.Index, p.Phase, percent, p.Elapsed.Round(time.Second))
+		case IndexRetrying, IndexFailed:
+			fmt.Fprintf(w, "Index %s %s after attempt %d: %v\n", p.Index, p.State, p.Attempt, p.Err)
+		case IndexDone:
+			fmt.Fprintf(w, "Index %s done (%s)\n", p.Index, p.Elapsed.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(w, "Index %s started (attempt %d)\n", p.Index, p.Attempt)
+		}
+	}
+}
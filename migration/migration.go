@@ -14,12 +14,22 @@ type Migration struct {
 	UpSQL     string
 	DownSQL   string
 	AppliedAt *time.Time
+
+	// Recorded when the migration is applied; empty for migrations applied
+	// before the migrations table had these columns
+	Duration   time.Duration
+	AppVersion string
+	AppliedBy  string
+	Hostname   string
 }
 
 // Migrator manages database migrations
 type Migrator struct {
-	db        *sql.DB
-	tableName string
+	db         *sql.DB
+	tableName  string
+	appVersion string
+	appliedBy  string
+	upgraded   bool
 }
 
 // NewMigrator creates a new migrator instance
@@ -45,8 +55,10 @@ func (m *Migrator) Initialize(ctx context.Context) error {
 		)
 	`, m.tableName)
 
-	_, err := m.db.ExecContext(ctx, query)
-	return err
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	return m.upgradeTable(ctx)
 }
 
 // GetAppliedMigrations returns a list of applied migrations
@@ -55,7 +67,7 @@ func (m *Migrator) GetAppliedMigrations(ctx context.Context) ([]Migration, error
 		return nil, err
 	}
 
-	query := fmt.Sprintf("SELECT version, name, applied_at FROM %s ORDER BY version", m.tableName)
+	query := fmt.Sprintf("SELECT version, name, applied_at, duration_ms, app_version, applied_by, hostname FROM %s ORDER BY version", m.tableName)
 	rows, err := m.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -64,8 +76,8 @@ func (m *Migrator) GetAppliedMigrations(ctx context.Context) ([]Migration, error
 
 	var migrations []Migration
 	for rows.Next() {
-		var m Migration
-		if err := rows.Scan(&m.Version, &m.Name, &m.AppliedAt); err != nil {
+		m, err := scanApplied(rows)
+		if err != nil {
 			return nil, err
 		}
 		migrations = append(migrations, m)
@@ -105,6 +117,18 @@ func (m *Migrator) Apply(ctx context.Context, migration Migration) error {
 		return fmt.Errorf("migration %d (%s) already applied", migration.Version, migration.Name)
 	}
 
+	// Statements such as CREATE INDEX CONCURRENTLY cannot run in a transaction
+	if noTransaction(migration.UpSQL) {
+		start := time.Now()
+		if _, err := m.db.ExecContext(ctx, migration.UpSQL); err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		if err := m.record(ctx, m.db, migration, time.Since(start)); err != nil {
+			return fmt.Errorf("failed to record migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	}
+
 	// Begin transaction
 	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -113,13 +137,13 @@ func (m *Migrator) Apply(ctx context.Context, migration Migration) error {
 	defer tx.Rollback()
 
 	// Execute up migration
+	start := time.Now()
 	if _, err := tx.ExecContext(ctx, migration.UpSQL); err != nil {
 		return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
 	}
 
 	// Record migration
-	recordQuery := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, NOW())", m.tableName)
-	if _, err := tx.ExecContext(ctx, recordQuery, migration.Version, migration.Name); err != nil {
+	if err := m.record(ctx, tx, migration, time.Since(start)); err != nil {
 		return fmt.Errorf("failed to record migration %d (%s): %w", migration.Version, migration.Name, err)
 	}
 
@@ -141,6 +165,18 @@ func (m *Migrator) Rollback(ctx context.Context, migration Migration) error {
 		return fmt.Errorf("migration %d (%s) not applied", migration.Version, migration.Name)
 	}
 
+	recordQuery := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.tableName)
+
+	if noTransaction(migration.DownSQL) {
+		if _, err := m.db.ExecContext(ctx, migration.DownSQL); err != nil {
+			return fmt.Errorf("failed to rollback migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		if _, err := m.db.ExecContext(ctx, recordQuery, migration.Version); err != nil {
+			return fmt.Errorf("failed to remove migration record %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	}
+
 	// Begin transaction
 	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -156,7 +192,6 @@ func (m *Migrator) Rollback(ctx context.Context, migration Migration) error {
 	}
 
 	// Remove migration record
-	recordQuery := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.tableName)
 	if _, err := tx.ExecContext(ctx, recordQuery, migration.Version); err != nil {
 		return fmt.Errorf("failed to remove migration record %d (%s): %w", migration.Version, migration.Name, err)
 	}
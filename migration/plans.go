@@ -0,0 +1,301 @@
+package migration
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PlanQuery is a named query whose plan is guarded against regressions
+type PlanQuery struct {
+	Name string
+	SQL  string
+}
+
+// manifestName matches the line that starts a query of a manifest
+var manifestName = regexp.MustCompile(`^--\s*name:\s*([A-Za-z0-9_-]+)\s*$`)
+
+// ParseQueryManifest reads a SQL manifest: queries, each following a
+// "-- name: <Name>" line, with $n placeholders for their parameters.
+// Other comments and blank lines are ignored; names must be unique.
+//
+//	-- name: FindActiveUsers
+//	SELECT * FROM users WHERE status = $1 ORDER BY created_at DESC LIMIT 20;
+func ParseQueryManifest(r io.Reader) ([]PlanQuery, error) {
+	var queries []PlanQuery
+	var body strings.Builder
+	seen := make(map[string]bool)
+	flush := func() error {
+		if len(queries) == 0 {
+			if strings.TrimSpace(body.String()) != "" {
+				return fmt.Errorf("manifest has SQL before the first -- name: line")
+			}
+			return nil
+		}
+		q := &queries[len(queries)-1]
+		q.SQL = strings.TrimSuffix(strings.TrimSpace(body.String()), ";")
+		if q.SQL == "" {
+			return fmt.Errorf("query %s has no SQL", q.Name)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := manifestName.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if seen[m[1]] {
+				return nil, fmt.Errorf("query %s is declared twice", m[1])
+			}
+			seen[m[1]] = true
+			queries = append(queries, PlanQuery{Name: m[1]})
+			body.Reset()
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		body.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// LoadQueryManifest reads the SQL manifest at path, see ParseQueryManifest
+func LoadQueryManifest(path string) ([]PlanQuery, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+	return ParseQueryManifest(f)
+}
+
+// PlanOptions configures how plans are obtained
+type PlanOptions struct {
+	// DisableSeqScan plans with enable_seqscan off, so that a sparse CI
+	// database still reveals which indexes a query can use
+	DisableSeqScan bool
+}
+
+// ExplainShape returns the shape of the plan PostgreSQL chooses for query:
+// one line per node, indented by depth, with the relation and index it
+// reads, but no costs or row estimates. Queries with $n placeholders are
+// planned with GENERIC_PLAN, which requires PostgreSQL 16.
+func ExplainShape(ctx context.Context, db *sql.DB, query string, opts PlanOptions) (string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if opts.DisableSeqScan {
+		if _, err := conn.ExecContext(ctx, "SET enable_seqscan = off"); err != nil {
+			return "", fmt.Errorf("failed to disable sequential scans: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "RESET enable_seqscan")
+	}
+
+	explain := "EXPLAIN (FORMAT JSON) "
+	if placeholder.MatchString(query) {
+		explain = "EXPLAIN (FORMAT JSON, GENERIC_PLAN) "
+	}
+	var out []byte
+	if err := conn.QueryRowContext(ctx, explain+query).Scan(&out); err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+	return planShape(out)
+}
+
+// placeholder matches a positional parameter
+var placeholder = regexp.MustCompile(`\$[0-9]+`)
+
+// planNode is the part of an EXPLAIN (FORMAT JSON) node a shape keeps
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// planShape formats an EXPLAIN (FORMAT JSON) result as a shape
+func planShape(explain []byte) (string, error) {
+	var result []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(explain, &result); err != nil {
+		return "", fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("empty plan")
+	}
+
+	var sb strings.Builder
+	var write func(node planNode, depth int)
+	write = func(node planNode, depth int) {
+		sb.WriteString(strings.Repeat("  ", depth) + node.NodeType)
+		if node.IndexName != "" {
+			sb.WriteString(" using " + node.IndexName)
+		}
+		if node.RelationName != "" {
+			sb.WriteString(" on " + node.RelationName)
+		}
+		sb.WriteString("\n")
+		for _, child := range node.Plans {
+			write(child, depth+1)
+		}
+	}
+	write(result[0].Plan, 0)
+	return sb.String(), nil
+}
+
+// PlanRegression is a relation a query read through an index in its golden
+// plan but now scans sequentially
+type PlanRegression struct {
+	Query    string // Manifest name of the query
+	Relation string
+}
+
+func (r PlanRegression) String() string {
+	return fmt.Sprintf("%s: sequential scan on %s, which the golden plan read through an index", r.Query, r.Relation)
+}
+
+// PlanReport is the result of CheckPlans
+type PlanReport struct {
+	Regressions []PlanRegression
+	Changed     []string // Queries whose shape changed without a regression
+	Missing     []string // Queries without a golden file
+}
+
+// ComparePlanShapes returns the relations a query read through an index in
+// the golden shape and reads only with a sequential scan in the current one
+func ComparePlanShapes(golden, current string) []string {
+	before, after := relationAccess(golden), relationAccess(current)
+	var regressed []string
+	for relation, access := range before {
+		if access.index && after[relation].seq && !after[relation].index {
+			regressed = append(regressed, relation)
+		}
+	}
+	sort.Strings(regressed)
+	return regressed
+}
+
+// access records how a plan reads a relation
+type access struct {
+	seq, index bool
+}
+
+// relationAccess returns how each relation of a shape is read
+func relationAccess(shape string) map[string]access {
+	result := make(map[string]access)
+	for _, line := range strings.Split(shape, "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.LastIndex(line, " on ")
+		if i < 0 {
+			continue
+		}
+		relation, node := line[i+len(" on "):], line[:i]
+		a := result[relation]
+		switch {
+		case strings.HasPrefix(node, "Seq Scan"):
+			a.seq = true
+		case strings.HasPrefix(node, "Index") || strings.HasPrefix(node, "Bitmap Heap Scan"):
+			// Index Scan, Index Only Scan, and the heap scan over Bitmap Index Scans
+			a.index = true
+		}
+		result[relation] = a
+	}
+	return result
+}
+
+// goldenFile returns the path of a query's golden plan
+func goldenFile(dir, name string) string {
+	return filepath.Join(dir, name+".plan")
+}
+
+// goldenHeader is written before a shape to show the query it belongs to
+func goldenHeader(q PlanQuery) string {
+	return "-- " + strings.Join(strings.Fields(q.SQL), " ") + "\n"
+}
+
+// RecordPlans writes the shape of each query's plan to <dir>/<name>.plan and
+// returns the paths written. Commit the files to review plan changes
+// together with the migrations that cause them.
+func RecordPlans(ctx context.Context, db *sql.DB, queries []PlanQuery, dir string, opts PlanOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create golden directory: %w", err)
+	}
+	var files []string
+	for _, q := range queries {
+		shape, err := ExplainShape(ctx, db, q.SQL, opts)
+		if err != nil {
+			return files, fmt.Errorf("query %s: %w", q.Name, err)
+		}
+		path := goldenFile(dir, q.Name)
+		if err := os.WriteFile(path, []byte(goldenHeader(q)+shape), 0644); err != nil {
+			return files, fmt.Errorf("failed to write golden plan: %w", err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// CheckPlans compares the current plan of each query with its golden file.
+// A regression is a relation read through an index in the golden plan that
+// is now only scanned sequentially; other shape changes are reported as
+// changed, and may be recorded again once reviewed.
+func CheckPlans(ctx context.Context, db *sql.DB, queries []PlanQuery, dir string, opts PlanOptions) (*PlanReport, error) {
+	report := &PlanReport{}
+	for _, q := range queries {
+		golden, err := os.ReadFile(goldenFile(dir, q.Name))
+		if os.IsNotExist(err) {
+			report.Missing = append(report.Missing, q.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read golden plan: %w", err)
+		}
+		shape, err := ExplainShape(ctx, db, q.SQL, opts)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", q.Name, err)
+		}
+
+		goldenShape := withoutComments(string(golden))
+		regressed := ComparePlanShapes(goldenShape, shape)
+		for _, relation := range regressed {
+			report.Regressions = append(report.Regressions, PlanRegression{Query: q.Name, Relation: relation})
+		}
+		if len(regressed) == 0 && goldenShape != shape {
+			report.Changed = append(report.Changed, q.Name)
+		}
+	}
+	return report, nil
+}
+
+// withoutComments drops the comment lines of a golden file
+func withoutComments(golden string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(golden, "\n") {
+		if !strings.HasPrefix(line, "--") {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQueryManifest(t *testing.T) {
+	manifest := `-- Critical queries of the user service
+
+-- name: FindActiveUsers
+SELECT * FROM users
+WHERE status = $1;
+
+-- name: CountOrders
+-- Orders per user
+SELECT user_id, COUNT(*) FROM orders GROUP BY user_id
+`
+	queries, err := ParseQueryManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("ParseQueryManifest failed: %v", err)
+	}
+	want := []PlanQuery{
+		{Name: "FindActiveUsers", SQL: "SELECT * FROM users\nWHERE status = $1"},
+		{Name: "CountOrders", SQL: "SELECT user_id, COUNT(*) FROM orders GROUP BY user_id"},
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("Expected %q, got %q", want, queries)
+	}
+
+	for _, invalid := range []string{
+		"SELECT 1;\n-- name: One\nSELECT 1",
+		"-- name: One\nSELECT 1\n-- name: One\nSELECT 2",
+		"-- name: Empty\n-- name: One\nSELECT 1",
+	} {
+		if _, err := ParseQueryManifest(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestPlanShape(t *testing.T) {
+	explain := `[{"Plan": {"Node Type": "Limit", "Total Cost": 8.3, "Plans": [
+		{"Node Type": "Nested Loop", "Plans": [
+			{"Node Type": "Index Scan", "Index Name": "users_email_idx", "Relation Name": "users", "Alias": "u"},
+			{"Node Type": "Bitmap Heap Scan", "Relation Name": "orders", "Plans": [
+				{"Node Type": "Bitmap Index Scan", "Index Name": "orders_user_id_idx"}
+			]}
+		]}
+	]}}]`
+	shape, err := planShape([]byte(explain))
+	if err != nil {
+		t.Fatalf("planShape failed: %v", err)
+	}
+	want := "Limit\n" +
+		"  Nested Loop\n" +
+		"    Index Scan using users_email_idx on users\n" +
+		"    Bitmap Heap Scan on orders\n" +
+		"      Bitmap Index Scan using orders_user_id_idx\n"
+	if shape != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, shape)
+	}
+}
+
+func TestComparePlanShapes(t *testing.T) {
+	golden := "Nested Loop\n" +
+		"  Index Scan using users_email_idx on users\n" +
+		"  Bitmap Heap Scan on orders\n" +
+		"    Bitmap Index Scan using orders_user_id_idx\n" +
+		"  Seq Scan on countries\n"
+
+	tests := []struct {
+		name    string
+		current string
+		want    []string
+	}{
+		{"unchanged", golden, nil},
+		{
+			"index replaced by sequential scans",
+			"Hash Join\n  Seq Scan on users\n  Seq Scan on orders\n  Seq Scan on countries\n",
+			[]string{"orders", "users"},
+		},
+		{
+			"different index",
+			"Nested Loop\n  Index Only Scan using users_pkey on users\n  Index Scan using orders_user_id_idx on orders\n  Index Scan using countries_pkey on countries\n",
+			nil,
+		},
+		{
+			"relation still read through an index elsewhere",
+			"Nested Loop\n  Seq Scan on users\n  Index Scan using users_pkey on users\n  Bitmap Heap Scan on orders\n  Seq Scan on countries\n",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComparePlanShapes(golden, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
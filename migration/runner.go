@@ -17,6 +17,22 @@ import (
 type Runner struct {
 	migrator *Migrator
 	migrationsDir string
+	fsys     fs.FS // Migration source; migrationsDir on disk when nil
+	events   EventSink
+
+	force     bool   // Roll back destructive down migrations without a backup
+	backupDir string // Where destroyed tables are copied before rolling back
+}
+
+// DefaultLockKey is the advisory lock key UpLocked uses to serialize runners
+const DefaultLockKey int64 = 7245018421
+
+// NewRunnerFS creates a migration runner reading migrations from the root of fsys,
+// such as an embed.FS, instead of a directory on disk
+func NewRunnerFS(db *sql.DB, fsys fs.FS) *Runner {
+	runner := NewRunner(db, "")
+	runner.fsys = fsys
+	return runner
 }
 
 // NewRunner creates a new migration runner
@@ -24,7 +40,61 @@ func NewRunner(db *sql.DB, migrationsDir string) *Runner {
 	return &Runner{
 		migrator:      NewMigrator(db),
 		migrationsDir: migrationsDir,
+		events:        NopEventSink{},
+	}
+}
+
+// SetTableName sets the name of the migrations tracking table
+func (r *Runner) SetTableName(name string) {
+	r.migrator.SetTableName(name)
+}
+
+// SetAppVersion sets the application version recorded with applied migrations
+func (r *Runner) SetAppVersion(version string) {
+	r.migrator.SetAppVersion(version)
+}
+
+// SetAppliedBy sets the user recorded with applied migrations
+func (r *Runner) SetAppliedBy(name string) {
+	r.migrator.SetAppliedBy(name)
+}
+
+// SetEventSink sets the sink receiving migration progress events
+func (r *Runner) SetEventSink(sink EventSink) {
+	if sink == nil {
+		sink = NopEventSink{}
 	}
+	r.events = sink
+}
+
+// eventSink returns the configured sink, tolerating runners built without NewRunner
+func (r *Runner) eventSink() EventSink {
+	if r.events == nil {
+		return NopEventSink{}
+	}
+	return r.events
+}
+
+// run executes a migration step and reports its progress and duration
+func (r *Runner) run(ctx context.Context, migration Migration, direction Direction, step func(context.Context, Migration) error) error {
+	events := r.eventSink()
+	event := MigrationEvent{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		Direction: direction,
+	}
+	events.OnMigrationStart(ctx, event)
+
+	start := time.Now()
+	err := step(ctx, migration)
+	event.Duration = time.Since(start)
+
+	if err != nil {
+		events.OnMigrationFailed(ctx, event, err)
+		return err
+	}
+	events.OnMigrationApplied(ctx, event)
+	return nil
 }
 
 // LoadMigrations loads migrations from the migrations directory
@@ -38,7 +108,12 @@ func (r *Runner) LoadMigrations(ctx context.Context) ([]Migration, error) {
 
 	var migrations []Migration
 
-	err := filepath.WalkDir(r.migrationsDir, func(path string, d fs.DirEntry, err error) error {
+	fsys := r.fsys
+	if fsys == nil {
+		fsys = os.DirFS(r.migrationsDir)
+	}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -54,7 +129,7 @@ func (r *Runner) LoadMigrations(ctx context.Context) ([]Migration, error) {
 		}
 
 		// Parse migration file name
-		migration, err := r.parseMigrationFile(path, baseName)
+		migration, err := r.parseMigrationFile(fsys, path, baseName)
 		if err != nil {
 			return err
 		}
@@ -95,7 +170,7 @@ func (r *Runner) LoadMigrations(ctx context.Context) ([]Migration, error) {
 }
 
 // parseMigrationFile parses a migration file and returns a Migration
-func (r *Runner) parseMigrationFile(path, fileName string) (*Migration, error) {
+func (r *Runner) parseMigrationFile(fsys fs.FS, path, fileName string) (*Migration, error) {
 	// Parse file name: YYYYMMDDHHMMSS_name.up.sql or YYYYMMDDHHMMSS_name.down.sql
 	parts := strings.Split(fileName, "_")
 	if len(parts) < 2 {
@@ -120,7 +195,7 @@ func (r *Runner) parseMigrationFile(path, fileName string) (*Migration, error) {
 	direction := nameParts[1] // "up" or "down"
 
 	// Read file content
-	content, err := os.ReadFile(path)
+	content, err := fs.ReadFile(fsys, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migration file %s: %w", path, err)
 	}
@@ -165,7 +240,7 @@ func (r *Runner) Up(ctx context.Context) error {
 			return fmt.Errorf("migration %d (%s) has no up SQL", migration.Version, migration.Name)
 		}
 
-		if err := r.migrator.Apply(ctx, migration); err != nil {
+		if err := r.run(ctx, migration, DirectionUp, r.migrator.Apply); err != nil {
 			return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
 		}
 	}
@@ -173,6 +248,23 @@ func (r *Runner) Up(ctx context.Context) error {
 	return nil
 }
 
+// UpLocked applies all pending migrations while holding a PostgreSQL advisory
+// lock, so concurrent instances of a service starting together apply them once
+func (r *Runner) UpLocked(ctx context.Context, lockKey int64) error {
+	conn, err := r.migrator.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	return r.Up(ctx)
+}
+
 // Down rolls back the last migration
 func (r *Runner) Down(ctx context.Context) error {
 	appliedMigrations, err := r.migrator.GetAppliedMigrations(ctx)
@@ -210,7 +302,14 @@ func (r *Runner) Down(ctx context.Context) error {
 		return fmt.Errorf("migration %d (%s) has no down SQL", migration.Version, migration.Name)
 	}
 
-	return r.migrator.Rollback(ctx, *migration)
+	if err := r.checkDataLoss([]*Migration{migration}); err != nil {
+		return err
+	}
+	if err := r.backup(ctx, *migration); err != nil {
+		return err
+	}
+
+	return r.run(ctx, *migration, DirectionDown, r.migrator.Rollback)
 }
 
 // DownTo rolls back migrations to a specific version
@@ -235,6 +334,8 @@ func (r *Runner) DownTo(ctx context.Context, targetVersion int64) error {
 		migrationMap[migrations[i].Version] = &migrations[i]
 	}
 
+	// Check every migration before rolling any back
+	var rollbacks []*Migration
 	for _, applied := range appliedMigrations {
 		if applied.Version <= targetVersion {
 			break
@@ -248,8 +349,17 @@ func (r *Runner) DownTo(ctx context.Context, targetVersion int64) error {
 		if migration.DownSQL == "" {
 			return fmt.Errorf("migration %d (%s) has no down SQL", migration.Version, migration.Name)
 		}
+		rollbacks = append(rollbacks, migration)
+	}
+	if err := r.checkDataLoss(rollbacks); err != nil {
+		return err
+	}
 
-		if err := r.migrator.Rollback(ctx, *migration); err != nil {
+	for _, migration := range rollbacks {
+		if err := r.backup(ctx, *migration); err != nil {
+			return err
+		}
+		if err := r.run(ctx, *migration, DirectionDown, r.migrator.Rollback); err != nil {
 			return fmt.Errorf("failed to rollback migration %d (%s): %w", migration.Version, migration.Name, err)
 		}
 	}
@@ -269,10 +379,10 @@ func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
 		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	appliedVersions := make(map[int64]time.Time)
+	appliedVersions := make(map[int64]Migration)
 	for _, m := range appliedMigrations {
 		if m.AppliedAt != nil {
-			appliedVersions[m.Version] = *m.AppliedAt
+			appliedVersions[m.Version] = m
 		}
 	}
 
@@ -284,9 +394,13 @@ func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
 			Status:  "pending",
 		}
 
-		if appliedAt, ok := appliedVersions[migration.Version]; ok {
+		if applied, ok := appliedVersions[migration.Version]; ok {
 			status.Status = "applied"
-			status.AppliedAt = &appliedAt
+			status.AppliedAt = applied.AppliedAt
+			status.Duration = applied.Duration
+			status.AppVersion = applied.AppVersion
+			status.AppliedBy = applied.AppliedBy
+			status.Hostname = applied.Hostname
 		}
 
 		statuses = append(statuses, status)
@@ -301,10 +415,27 @@ type MigrationStatus struct {
 	Name      string
 	Status    string // "applied" or "pending"
 	AppliedAt *time.Time
+
+	// Audit details of an applied migration, see Migration
+	Duration   time.Duration
+	AppVersion string
+	AppliedBy  string
+	Hostname   string
 }
 
 // CreateMigration creates a new migration file pair
 func (r *Runner) CreateMigration(name string) error {
+	return r.CreateMigrationFromTemplate(name, TemplateBlank)
+}
+
+// CreateMigrationFromTemplate creates a new migration file pair whose bodies
+// are filled in from the given template
+func (r *Runner) CreateMigrationFromTemplate(name string, template Template) error {
+	up, down, err := template.render(name)
+	if err != nil {
+		return err
+	}
+	
 	// Generate timestamp-based version
 	version := time.Now().Format("20060102150405")
 	
@@ -325,13 +456,13 @@ func (r *Runner) CreateMigration(name string) error {
 	}
 	
 	// Create up file
-	upContent := fmt.Sprintf("-- Migration: %s\n-- Version: %s\n-- Up migration\n\n", name, version)
+	upContent := fmt.Sprintf("-- Migration: %s\n-- Version: %s\n-- Up migration\n\n%s", name, version, up)
 	if err := os.WriteFile(upPath, []byte(upContent), 0644); err != nil {
 		return fmt.Errorf("failed to create up migration file: %w", err)
 	}
 	
 	// Create down file
-	downContent := fmt.Sprintf("-- Migration: %s\n-- Version: %s\n-- Down migration\n\n", name, version)
+	downContent := fmt.Sprintf("-- Migration: %s\n-- Version: %s\n-- Down migration\n\n%s", name, version, down)
 	if err := os.WriteFile(downPath, []byte(downContent), 0644); err != nil {
 		return fmt.Errorf("failed to create down migration file: %w", err)
 	}
@@ -353,7 +484,11 @@ func (r *Runner) ValidateMigrations(ctx context.Context) error {
 		// Down SQL is optional but recommended
 		if migration.DownSQL == "" {
 			// Warning, not error
-			fmt.Printf("Warning: migration %d (%s) is missing down SQL\n", migration.Version, migration.Name)
+			r.eventSink().OnMigrationWarning(ctx, MigrationEvent{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Direction: DirectionDown,
+			}, "is missing down SQL")
 		}
 	}
 
@@ -374,3 +509,16 @@ func Version(fileName string) (int64, error) {
 	return strconv.ParseInt(parts[0], 10, 64)
 }
 
+
+// LatestVersion returns the highest migration version found in the root of
+// fsys, or 0 when it holds no migrations
+func LatestVersion(fsys fs.FS) (int64, error) {
+	migrations, err := NewRunnerFS(nil, fsys).LoadMigrations(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
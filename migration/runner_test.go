@@ -1,13 +1,22 @@
 package migration
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestRunner_LoadMigrations(t *testing.T) {
@@ -49,6 +58,31 @@ func TestRunner_LoadMigrations(t *testing.T) {
 	}
 }
 
+func TestRunner_LoadMigrationsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240102000000_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"20240101000000_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id BIGINT);")},
+		"20240101000000_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"README.md":                            {Data: []byte("not a migration")},
+	}
+
+	runner := &Runner{fsys: fsys}
+	migrations, err := runner.LoadMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Name != "create_users" || migrations[0].DownSQL == "" {
+		t.Errorf("Expected create_users with down SQL first, got %+v", migrations[0])
+	}
+	if migrations[1].Name != "add_email" {
+		t.Errorf("Expected add_email second, got '%s'", migrations[1].Name)
+	}
+}
+
 func TestRunner_CreateMigration(t *testing.T) {
 	tmpDir := t.TempDir()
 	migrationsDir := filepath.Join(tmpDir, "migrations")
@@ -88,6 +122,154 @@ func TestRunner_CreateMigration(t *testing.T) {
 	}
 }
 
+func TestRunner_CreateMigrationFromTemplate(t *testing.T) {
+	tests := []struct {
+		template Template
+		up       string
+		down     string
+	}{
+		{TemplateTable, "CREATE TABLE table_name", "DROP TABLE IF EXISTS table_name"},
+		{TemplateIndex, "CREATE INDEX idx_add_email_index ON", "DROP INDEX IF EXISTS idx_add_email_index"},
+		{TemplateData, "UPDATE table_name", "Revert the data changes"},
+		{TemplateColumn, "ADD COLUMN IF NOT EXISTS column_name TEXT", "DROP COLUMN IF EXISTS column_name"},
+		{TemplateConcurrentIndex, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_add_email_index", "DROP INDEX CONCURRENTLY IF EXISTS idx_add_email_index"},
+		{TemplateRename, "RENAME COLUMN old_name TO new_name", "RENAME COLUMN new_name TO old_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.template), func(t *testing.T) {
+			migrationsDir := t.TempDir()
+			runner := NewRunner(nil, migrationsDir)
+			if err := runner.CreateMigrationFromTemplate("add_email_index", tt.template); err != nil {
+				t.Fatalf("Failed to create migration: %v", err)
+			}
+
+			migrations, err := runner.LoadMigrations(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to load migrations: %v", err)
+			}
+			if len(migrations) != 1 {
+				t.Fatalf("Expected 1 migration, got %d", len(migrations))
+			}
+			if !strings.Contains(migrations[0].UpSQL, tt.up) {
+				t.Errorf("Expected up SQL to contain '%s', got '%s'", tt.up, migrations[0].UpSQL)
+			}
+			if !strings.Contains(migrations[0].DownSQL, tt.down) {
+				t.Errorf("Expected down SQL to contain '%s', got '%s'", tt.down, migrations[0].DownSQL)
+			}
+		})
+	}
+
+	if _, err := ParseTemplate("view"); err == nil {
+		t.Error("Expected error for unknown template")
+	}
+}
+
+func TestGuardedDDL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"add column", AddColumnIfNotExists("users", "nickname", "TEXT"), "ALTER TABLE users ADD COLUMN IF NOT EXISTS nickname TEXT;"},
+		{"unique index", CreateIndexConcurrentlyIfNotExists("idx_users_email", "users", true, "email"), "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email ON users (email);"},
+		{"rename column", RenameColumnIfExists("app.users", "name", "full_name"), "IF EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = to_regclass('app.users') AND attname = 'name' AND NOT attisdropped) AND NOT EXISTS"},
+		{"rename fallback", RenameColumnIfExists("users", "name", "full_name"), "RAISE EXCEPTION 'column %.% does not exist', 'users', 'full_name';"},
+		{"rename table", RenameTableIfExists("people", "users"), "IF to_regclass('people') IS NOT NULL AND to_regclass('users') IS NULL THEN\n        ALTER TABLE people RENAME TO users;"},
+		{"constraint", AddConstraintIfNotExists("users", "users_age_check", "CHECK (age >= 0)"), "conname = 'users_age_check') THEN\n        ALTER TABLE users ADD CONSTRAINT users_age_check CHECK (age >= 0);"},
+		{"invalid index", DropInvalidIndex("idx_users_email"), "indexrelid = to_regclass('idx_users_email') AND NOT indisvalid"},
+		{"enum", CreateEnumTypeIfNotExists("mood", "happy", "sad"), "CREATE TYPE mood AS ENUM ('happy', 'sad');"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.sql, tt.want) {
+				t.Errorf("Expected SQL to contain '%s', got '%s'", tt.want, tt.sql)
+			}
+		})
+	}
+
+	t.Run("no-transaction directive", func(t *testing.T) {
+		if !noTransaction("-- Migration: idx\n" + NoTransactionDirective + "\nCREATE INDEX CONCURRENTLY i ON t (c);") {
+			t.Error("Expected the directive after header comments to be found")
+		}
+		if noTransaction("CREATE TABLE t (id INT);\n" + NoTransactionDirective) {
+			t.Error("Expected the directive after a statement to be ignored")
+		}
+	})
+}
+
+func TestDestructiveStatements(t *testing.T) {
+	down := `-- Migration: rollback
+ALTER TABLE users ALTER COLUMN email DROP DEFAULT, DROP CONSTRAINT users_age_check;
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS nickname, DROP age;
+DROP INDEX idx_users_email;
+DROP TABLE IF EXISTS posts, comments CASCADE;
+TRUNCATE audit_log RESTART IDENTITY;`
+
+	got := DestructiveStatements(down)
+	want := []string{
+		"drops column users.nickname",
+		"drops column users.age",
+		"drops data of table posts",
+		"drops data of table comments",
+		"drops data of table audit_log",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d destructive statements, got %v", len(want), got)
+	}
+	for i, loss := range got {
+		if loss.String() != want[i] {
+			t.Errorf("Expected '%s', got '%s'", want[i], loss)
+		}
+	}
+
+	runner := NewRunner(nil, "")
+	migrations := []*Migration{{Version: 2, Name: "add_posts", DownSQL: down}}
+	if err := runner.checkDataLoss(migrations); !errors.Is(err, ErrDataLoss) {
+		t.Errorf("Expected ErrDataLoss, got %v", err)
+	}
+	if err := runner.checkDataLoss([]*Migration{{Version: 1, DownSQL: "DROP INDEX idx_users_email;"}}); err != nil {
+		t.Errorf("Expected no data loss, got %v", err)
+	}
+
+	runner.SetForce(true)
+	if err := runner.checkDataLoss(migrations); err != nil {
+		t.Errorf("Expected a forced rollback to pass, got %v", err)
+	}
+	runner.SetForce(false)
+	runner.SetBackupDir(t.TempDir())
+	if err := runner.checkDataLoss(migrations); err != nil {
+		t.Errorf("Expected a backed up rollback to pass, got %v", err)
+	}
+}
+
+func TestIndexBuilder(t *testing.T) {
+	spec := IndexSpec{Name: "idx_orders_customer", Table: "orders", Columns: []string{"customer_id", "created_at"}}
+	if got := spec.createSQL(); got != "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_customer ON orders (customer_id, created_at);" {
+		t.Errorf("Unexpected SQL %s", got)
+	}
+
+	// Specs are checked before any build starts
+	builder := NewIndexBuilder(nil)
+	if err := builder.Build(context.Background(), spec, IndexSpec{Name: "idx_empty", Table: "orders"}); err == nil {
+		t.Error("Expected an error for an index without columns")
+	}
+
+	var buf bytes.Buffer
+	write := NewIndexProgressWriter(&buf)
+	write(IndexProgress{Index: "idx_a", State: IndexBuilding, Phase: "building index: scanning table", BlocksDone: 25, BlocksTotal: 100, Elapsed: 3 * time.Second})
+	write(IndexProgress{Index: "idx_a", State: IndexRetrying, Attempt: 1, Err: errors.New("deadlock detected")})
+	for _, want := range []string{
+		"Index idx_a: building index: scanning table, 25% of blocks (3s)",
+		"Index idx_a retrying after attempt 1: deadlock detected",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected '%s' in:\n%s", want, buf.String())
+		}
+	}
+}
+
 func TestRunner_ValidateMigrations(t *testing.T) {
 	tmpDir := t.TempDir()
 	migrationsDir := filepath.Join(tmpDir, "migrations")
@@ -136,6 +318,193 @@ func TestSchemaGenerator_GenerateCreateTable(t *testing.T) {
 	}
 }
 
+func TestSchemaGenerator_ExpressionIndexes(t *testing.T) {
+	type TestCustomer struct {
+		ID    int64  `db:"id" jet:"primary_key"`
+		Email string `db:"email" jet:"not_null,lower_index"`
+		Name  string `db:"name" jet:"trgm_index:idx_customer_name_search,unaccent_index"`
+	}
+
+	sg := NewSchemaGenerator()
+	ddl, err := sg.GenerateCreateTable(reflect.TypeOf(TestCustomer{}), "customers")
+	if err != nil {
+		t.Fatalf("Failed to generate CREATE TABLE: %v", err)
+	}
+
+	if !strings.Contains(ddl, "email TEXT NOT NULL,") || !strings.Contains(ddl, "name TEXT,") {
+		t.Errorf("Index tags should not change column definitions, got %s", ddl)
+	}
+	expected := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm;",
+		"CREATE EXTENSION IF NOT EXISTS unaccent;",
+		"CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text",
+		"CREATE INDEX IF NOT EXISTS idx_customers_email_lower ON customers (LOWER(email));",
+		"CREATE INDEX IF NOT EXISTS idx_customer_name_search ON customers USING gin (name gin_trgm_ops);",
+		"CREATE INDEX IF NOT EXISTS idx_customers_name_unaccent ON customers (LOWER(immutable_unaccent(name)));",
+	}
+	last := strings.Index(ddl, ");")
+	for _, statement := range expected {
+		at := strings.Index(ddl, statement)
+		if at < 0 {
+			t.Errorf("Expected %q, got %s", statement, ddl)
+			continue
+		}
+		if at < last {
+			t.Errorf("Expected %q after the CREATE TABLE statement", statement)
+		}
+		last = at
+	}
+}
+
+func TestNamer(t *testing.T) {
+	long := "idx_customer_subscription_invoices_billing_address_line_two_lower"
+	short := HashTruncate(long, MaxIdentifierLength)
+	if len(short) != MaxIdentifierLength || !strings.HasPrefix(short, long[:50]) {
+		t.Errorf("Expected a %d byte name keeping the prefix, got %q", MaxIdentifierLength, short)
+	}
+	if other := HashTruncate(long+"_x", MaxIdentifierLength); other == short {
+		t.Errorf("Expected names sharing a prefix to differ, both got %q", short)
+	}
+	if HashTruncate(long, MaxIdentifierLength) != short {
+		t.Error("Expected truncation to be deterministic")
+	}
+
+	namer := NewNamer()
+	if name, err := namer.Name("idx_users_email_lower"); err != nil || name != "idx_users_email_lower" {
+		t.Errorf("Expected short names to be kept, got %q, %v", name, err)
+	}
+	if name, err := namer.Name(long); err != nil || name != short {
+		t.Errorf("Expected %q, got %q, %v", short, name, err)
+	}
+	if _, err := namer.Name(long); err != nil {
+		t.Errorf("Expected a name to be given out again, got %v", err)
+	}
+	if _, err := namer.Explicit(short); !errors.Is(err, ErrIdentifierCollision) {
+		t.Errorf("Expected ErrIdentifierCollision, got %v", err)
+	}
+	if _, err := namer.Explicit(long); !errors.Is(err, ErrIdentifierTooLong) {
+		t.Errorf("Expected ErrIdentifierTooLong, got %v", err)
+	}
+
+	namer = NewNamer()
+	namer.SetMaxLength(20)
+	namer.SetTruncateFunc(func(name string, maxLength int) string { return name[:maxLength] })
+	if _, err := namer.Name("fk_orders_customer_id"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := namer.Name("fk_orders_customer_id_2"); !errors.Is(err, ErrIdentifierCollision) {
+		t.Errorf("Expected ErrIdentifierCollision for a custom truncation, got %v", err)
+	}
+}
+
+func TestSchemaGenerator_LongIndexNames(t *testing.T) {
+	type TestInvoice struct {
+		ID      int64  `db:"id" jet:"primary_key"`
+		Address string `db:"billing_address_line_two" jet:"lower_index"`
+	}
+
+	table := "customer_subscription_invoices"
+	ddl, err := NewSchemaGenerator().GenerateCreateTable(reflect.TypeOf(TestInvoice{}), table)
+	if err != nil {
+		t.Fatalf("Failed to generate CREATE TABLE: %v", err)
+	}
+	index := HashTruncate("idx_customer_subscription_invoices_billing_address_line_two_lower", MaxIdentifierLength)
+	if !strings.Contains(ddl, "CREATE INDEX IF NOT EXISTS "+index+" ON "+table) {
+		t.Errorf("Expected the index to be named %s, got %s", index, ddl)
+	}
+}
+
+func TestSchemaGenerator_UUIDColumns(t *testing.T) {
+	type TestSession struct {
+		ID    string `db:"id" jet:"primary_key,uuid:db"`
+		Token string `db:"token" jet:"uuid"`
+	}
+
+	sg := NewSchemaGenerator()
+	sql, err := sg.GenerateCreateTable(reflect.TypeOf(TestSession{}), "sessions")
+	if err != nil {
+		t.Fatalf("Failed to generate CREATE TABLE: %v", err)
+	}
+
+	if !strings.Contains(sql, "id UUID DEFAULT gen_random_uuid()") {
+		t.Errorf("Expected database-generated UUID key, got %s", sql)
+	}
+	if !strings.Contains(sql, "token UUID,") {
+		t.Errorf("Expected UUID column without default, got %s", sql)
+	}
+}
+
+func TestSchemaGenerator_WellKnownTypes(t *testing.T) {
+	type TestAccount struct {
+		ID      int64          `db:"id" jet:"primary_key"`
+		Name    sql.NullString `db:"name"`
+		Visits  sql.NullInt32  `db:"visits"`
+		Balance pgtype.Numeric `db:"balance"`
+		SeenAt  *sql.NullTime  `db:"seen_at"`
+		Address netip.Addr     `db:"address"`
+		Score   *int64         `db:"score"`
+		Rating  *float64       `db:"rating"`
+		Avatar  *[]byte        `db:"avatar"`
+		Expires *time.Time     `db:"expires"`
+	}
+
+	sg := NewSchemaGenerator()
+	ddl, err := sg.GenerateCreateTable(reflect.TypeOf(TestAccount{}), "accounts")
+	if err != nil {
+		t.Fatalf("Failed to generate CREATE TABLE: %v", err)
+	}
+
+	for _, column := range []string{"name TEXT", "visits INTEGER", "balance NUMERIC", "seen_at TIMESTAMP", "address INET",
+		"score BIGINT", "rating DOUBLE PRECISION", "avatar BYTEA", "expires TIMESTAMP"} {
+		if !strings.Contains(ddl, column) {
+			t.Errorf("Expected column '%s', got %s", column, ddl)
+		}
+	}
+}
+
+func TestSchemaGenerator_EnumColumns(t *testing.T) {
+	type TestTicket struct {
+		ID       int64   `db:"id" jet:"primary_key"`
+		Status   string  `db:"status" jet:"enum:ticket_status(open,closed,wont_fix),not_null"`
+		Previous *string `db:"previous" jet:"enum:ticket_status(open,closed,wont_fix)"`
+		Price    float64 `db:"price" jet:"type:decimal(10,2),not_null"`
+	}
+
+	sg := NewSchemaGenerator()
+	ddl, err := sg.GenerateCreateTable(reflect.TypeOf(TestTicket{}), "tickets")
+	if err != nil {
+		t.Fatalf("Failed to generate CREATE TABLE: %v", err)
+	}
+
+	if strings.Count(ddl, "CREATE TYPE") != 1 {
+		t.Errorf("Expected the enum type to be created once, got %s", ddl)
+	}
+	if !strings.Contains(ddl, "CREATE TYPE ticket_status AS ENUM ('open', 'closed', 'wont_fix');") {
+		t.Errorf("Expected CREATE TYPE statement, got %s", ddl)
+	}
+	if strings.Index(ddl, "CREATE TYPE") > strings.Index(ddl, "CREATE TABLE") {
+		t.Errorf("Expected the type to be created before the table, got %s", ddl)
+	}
+	for _, column := range []string{"status ticket_status NOT NULL", "previous ticket_status", "price decimal(10,2) NOT NULL"} {
+		if !strings.Contains(ddl, column) {
+			t.Errorf("Expected column '%s', got %s", column, ddl)
+		}
+	}
+
+	migrationsDir := t.TempDir()
+	if err := NewGenerator().GenerateCreateTableMigration(reflect.TypeOf(TestTicket{}), "tickets", migrationsDir); err != nil {
+		t.Fatalf("Failed to generate migration: %v", err)
+	}
+	downFiles, _ := filepath.Glob(filepath.Join(migrationsDir, "*.down.sql"))
+	if len(downFiles) != 1 {
+		t.Fatalf("Expected 1 down migration, got %d", len(downFiles))
+	}
+	down, _ := os.ReadFile(downFiles[0])
+	if !strings.Contains(string(down), "DROP TABLE IF EXISTS tickets;\nDROP TYPE IF EXISTS ticket_status;") {
+		t.Errorf("Expected the enum type to be dropped after the table, got %s", down)
+	}
+}
+
 func TestGenerator_GenerateCreateTableMigration(t *testing.T) {
 	type TestUser struct {
 		ID    int64  `db:"id" jet:"primary_key"`
@@ -204,6 +573,59 @@ func TestGenerator_GenerateForeignKeyMigration(t *testing.T) {
 	}
 }
 
+func TestGenerator_GenerateBackfillMigrations(t *testing.T) {
+	backfill := ColumnBackfill{Table: "users", Column: "status", Type: "TEXT", Default: "'active'", BatchSize: 500}
+	at := time.Date(2024, 3, 1, 12, 0, 59, 0, time.UTC)
+	migrations, err := backfill.Migrations(at)
+	if err != nil {
+		t.Fatalf("Failed to build migrations: %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migrations))
+	}
+
+	add, fill, require := migrations[0], migrations[1], migrations[2]
+	if add.Version != 20240301120059 || fill.Version != 20240301120100 || require.Version != 20240301120101 {
+		t.Errorf("Unexpected versions %d, %d, %d", add.Version, fill.Version, require.Version)
+	}
+	if add.Name != "add_users_status" || noTransaction(add.UpSQL) {
+		t.Errorf("Expected a transactional add_users_status, got %s", add.Name)
+	}
+	if !strings.Contains(add.UpSQL, "ALTER TABLE users ALTER COLUMN status SET DEFAULT 'active';") {
+		t.Errorf("Expected the default to be set, got:\n%s", add.UpSQL)
+	}
+	for _, want := range []string{"last_key users.id%TYPE;", "LIMIT 500", "UPDATE users SET status = 'active'", "COMMIT;"} {
+		if !strings.Contains(fill.UpSQL, want) {
+			t.Errorf("Expected backfill to contain '%s', got:\n%s", want, fill.UpSQL)
+		}
+	}
+	for _, want := range []string{"CHECK (status IS NOT NULL) NOT VALID", "VALIDATE CONSTRAINT users_status_not_null", "SET NOT NULL"} {
+		if !strings.Contains(require.UpSQL, want) {
+			t.Errorf("Expected promotion to contain '%s', got:\n%s", want, require.UpSQL)
+		}
+	}
+	if !noTransaction(fill.UpSQL) || !noTransaction(require.UpSQL) {
+		t.Error("Expected the backfill and promotion to run outside a transaction")
+	}
+
+	if _, err := (ColumnBackfill{Table: "users", Column: "status", Type: "TEXT"}).Migrations(at); err == nil {
+		t.Error("Expected an error without a default or value")
+	}
+
+	migrationsDir := filepath.Join(t.TempDir(), "migrations")
+	files, err := NewGenerator().GenerateBackfillMigrations(backfill, migrationsDir)
+	if err != nil {
+		t.Fatalf("Failed to generate backfill migrations: %v", err)
+	}
+	if len(files) != 6 {
+		t.Errorf("Expected 6 files, got %d", len(files))
+	}
+	loaded, err := NewRunner(nil, migrationsDir).LoadMigrations(context.Background())
+	if err != nil || len(loaded) != 3 || !noTransaction(loaded[1].UpSQL) {
+		t.Errorf("Expected the files to load as 3 migrations, got %d (%v)", len(loaded), err)
+	}
+}
+
 func TestValidator_ValidateSQL(t *testing.T) {
 	validator := NewValidator(nil)
 
@@ -261,3 +683,123 @@ func TestValidator_ValidateMigrationOrder(t *testing.T) {
 	}
 }
 
+
+// recordingSink records migration events for assertions
+type recordingSink struct {
+	events []string
+}
+
+func (s *recordingSink) OnMigrationStart(ctx context.Context, event MigrationEvent) {
+	s.events = append(s.events, fmt.Sprintf("start %s %d", event.Direction, event.Version))
+}
+
+func (s *recordingSink) OnMigrationApplied(ctx context.Context, event MigrationEvent) {
+	s.events = append(s.events, fmt.Sprintf("applied %s %d", event.Direction, event.Version))
+}
+
+func (s *recordingSink) OnMigrationFailed(ctx context.Context, event MigrationEvent, err error) {
+	s.events = append(s.events, fmt.Sprintf("failed %s %d: %v", event.Direction, event.Version, err))
+}
+
+func (s *recordingSink) OnMigrationWarning(ctx context.Context, event MigrationEvent, message string) {
+	s.events = append(s.events, fmt.Sprintf("warning %d: %s", event.Version, message))
+}
+
+func TestRunner_Events(t *testing.T) {
+	ctx := context.Background()
+	migration := Migration{Version: 1, Name: "create_users"}
+
+	t.Run("should report applied migrations", func(t *testing.T) {
+		sink := &recordingSink{}
+		runner := NewRunner(nil, "")
+		runner.SetEventSink(sink)
+
+		err := runner.run(ctx, migration, DirectionUp, func(context.Context, Migration) error { return nil })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []string{"start up 1", "applied up 1"}
+		if !reflect.DeepEqual(sink.events, expected) {
+			t.Errorf("Expected %v, got %v", expected, sink.events)
+		}
+	})
+
+	t.Run("should report failed migrations", func(t *testing.T) {
+		sink := &recordingSink{}
+		runner := NewRunner(nil, "")
+		runner.SetEventSink(sink)
+
+		failure := errors.New("syntax error")
+		err := runner.run(ctx, migration, DirectionDown, func(context.Context, Migration) error { return failure })
+		if !errors.Is(err, failure) {
+			t.Fatalf("Expected step error, got %v", err)
+		}
+		expected := []string{"start down 1", "failed down 1: syntax error"}
+		if !reflect.DeepEqual(sink.events, expected) {
+			t.Errorf("Expected %v, got %v", expected, sink.events)
+		}
+	})
+
+	t.Run("should report missing down SQL as a warning", func(t *testing.T) {
+		migrationsDir := t.TempDir()
+		os.WriteFile(filepath.Join(migrationsDir, "20240101000000_test.up.sql"), []byte("SELECT 1;"), 0644)
+
+		sink := &recordingSink{}
+		runner := NewRunner(nil, migrationsDir)
+		runner.SetEventSink(sink)
+
+		if err := runner.ValidateMigrations(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []string{"warning 20240101000000: is missing down SQL"}
+		if !reflect.DeepEqual(sink.events, expected) {
+			t.Errorf("Expected %v, got %v", expected, sink.events)
+		}
+	})
+}
+
+func TestEventSinks(t *testing.T) {
+	ctx := context.Background()
+	event := MigrationEvent{Version: 1, Name: "create_users", Direction: DirectionUp, Duration: 1500 * time.Microsecond}
+
+	t.Run("writer sink should render progress", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewWriterEventSink(&buf)
+		sink.OnMigrationStart(ctx, event)
+		sink.OnMigrationApplied(ctx, event)
+
+		expected := "Applying 1 (create_users)...\nApplied 1 (create_users) in 2ms\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("log sink should log structured attributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewLogEventSink(slog.New(slog.NewTextHandler(&buf, nil)))
+		sink.OnMigrationFailed(ctx, event, errors.New("boom"))
+
+		for _, attr := range []string{"level=ERROR", "version=1", "name=create_users", "direction=up", "duration=1.5ms", "error=boom"} {
+			if !strings.Contains(buf.String(), attr) {
+				t.Errorf("Expected log to contain '%s', got '%s'", attr, buf.String())
+			}
+		}
+	})
+}
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion(fstest.MapFS{
+		"20240102000000_add_email.up.sql":    {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"20240101000000_create_users.up.sql": {Data: []byte("CREATE TABLE users (id BIGINT);")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to read latest version: %v", err)
+	}
+	if version != 20240102000000 {
+		t.Errorf("Expected 20240102000000, got %d", version)
+	}
+
+	if version, err := LatestVersion(fstest.MapFS{}); err != nil || version != 0 {
+		t.Errorf("Expected 0 for no migrations, got %d, %v", version, err)
+	}
+}
@@ -0,0 +1,181 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ErrDataLoss is returned by Down and DownTo when a down migration drops
+// tables or columns and the runner is neither forced nor backing them up
+var ErrDataLoss = errors.New("rollback would destroy data")
+
+// DataLoss is a statement of a migration that destroys stored data
+type DataLoss struct {
+	Statement string
+	Table     string
+	Column    string // Empty when the statement drops or empties the whole table
+}
+
+func (d DataLoss) String() string {
+	if d.Column != "" {
+		return fmt.Sprintf("drops column %s.%s", d.Table, d.Column)
+	}
+	return fmt.Sprintf("drops data of table %s", d.Table)
+}
+
+var (
+	sqlLineComment = regexp.MustCompile(`--[^\n]*`)
+	dropTable      = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.+?)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	truncateTable  = regexp.MustCompile(`(?is)^TRUNCATE\s+(?:TABLE\s+)?(?:ONLY\s+)?(.+?)(?:\s+(?:RESTART|CONTINUE)\s+IDENTITY)?(?:\s+(?:CASCADE|RESTRICT))?$`)
+	alterTable     = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."]+)\s+(.+)$`)
+	dropColumn     = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?([\w"]+)`)
+)
+
+// DestructiveStatements finds the statements of migration SQL that drop or
+// truncate tables or drop columns. It reads plain DDL only; statements inside
+// DO blocks or functions are not seen.
+func DestructiveStatements(sql string) []DataLoss {
+	var losses []DataLoss
+	for _, statement := range strings.Split(sqlLineComment.ReplaceAllString(sql, ""), ";") {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+
+		if match := dropTable.FindStringSubmatch(statement); match != nil {
+			losses = append(losses, tableLosses(statement, match[1])...)
+		} else if match := truncateTable.FindStringSubmatch(statement); match != nil {
+			losses = append(losses, tableLosses(statement, match[1])...)
+		} else if match := alterTable.FindStringSubmatch(statement); match != nil {
+			for _, action := range strings.Split(match[2], ",") {
+				column := dropColumn.FindStringSubmatch(strings.TrimSpace(action))
+				if column == nil || isDropKeyword(column[1]) {
+					continue
+				}
+				losses = append(losses, DataLoss{Statement: statement, Table: match[1], Column: column[1]})
+			}
+		}
+	}
+	return losses
+}
+
+// tableLosses lists each table of a comma-separated table list
+func tableLosses(statement, tables string) []DataLoss {
+	var losses []DataLoss
+	for _, table := range strings.Split(tables, ",") {
+		losses = append(losses, DataLoss{Statement: statement, Table: strings.TrimSpace(table)})
+	}
+	return losses
+}
+
+// isDropKeyword reports whether the word after DROP in an ALTER TABLE action
+// starts a clause that keeps the data, such as DROP CONSTRAINT
+func isDropKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "CONSTRAINT", "DEFAULT", "NOT", "IDENTITY", "EXPRESSION":
+		return true
+	}
+	return false
+}
+
+// SetForce lets Down and DownTo run down migrations that destroy data
+// without backing it up
+func (r *Runner) SetForce(force bool) {
+	r.force = force
+}
+
+// SetBackupDir makes Down and DownTo copy each table that a down migration
+// drops, truncates or drops columns of into a CSV file in dir before running
+// it. Files are named <version>_<table>.csv.
+func (r *Runner) SetBackupDir(dir string) {
+	r.backupDir = dir
+}
+
+// checkDataLoss refuses to roll back migrations that destroy data, unless
+// the runner is forced or backs the data up
+func (r *Runner) checkDataLoss(migrations []*Migration) error {
+	if r.force || r.backupDir != "" {
+		return nil
+	}
+
+	var descriptions []string
+	for _, migration := range migrations {
+		for _, loss := range DestructiveStatements(migration.DownSQL) {
+			descriptions = append(descriptions, fmt.Sprintf("%d (%s) %s", migration.Version, migration.Name, loss))
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s; force the rollback or back the data up first", ErrDataLoss, strings.Join(descriptions, ", "))
+}
+
+// backup copies the tables whose data migration's down SQL destroys into the
+// backup directory. Tables that do not exist are skipped.
+func (r *Runner) backup(ctx context.Context, migration Migration) error {
+	losses := DestructiveStatements(migration.DownSQL)
+	if r.backupDir == "" || len(losses) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(r.backupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	conn, err := r.migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	copied := make(map[string]bool)
+	for _, loss := range losses {
+		if copied[loss.Table] {
+			continue
+		}
+		copied[loss.Table] = true
+
+		var exists bool
+		if err := conn.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", loss.Table).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		path := filepath.Join(r.backupDir, fmt.Sprintf("%d_%s.csv", migration.Version, strings.ReplaceAll(loss.Table, `"`, "")))
+		if err := copyTable(ctx, conn, loss.Table, path); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", loss.Table, err)
+		}
+	}
+	return nil
+}
+
+// copyTable writes a table's rows to a CSV file with COPY, which needs the
+// pgx database/sql driver
+func copyTable(ctx context.Context, conn *sql.Conn, table, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("backups need the pgx driver, got %T", driverConn)
+		}
+		_, err := pgxConn.Conn().PgConn().CopyTo(ctx, file, fmt.Sprintf("COPY %s TO STDOUT WITH (FORMAT csv, HEADER)", table))
+		return err
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
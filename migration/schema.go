@@ -4,14 +4,25 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/satishbabariya/jetorm/internal/schema"
 )
 
 // SchemaGenerator generates SQL schema from Go struct definitions
-type SchemaGenerator struct{}
+type SchemaGenerator struct {
+	namer *Namer // Names the generated indexes
+}
 
 // NewSchemaGenerator creates a new schema generator
 func NewSchemaGenerator() *SchemaGenerator {
-	return &SchemaGenerator{}
+	return &SchemaGenerator{namer: NewNamer()}
+}
+
+// SetNamer sets the namer of generated index names, to change the
+// identifier limit or truncation, or to share collision checks with
+// another generator
+func (sg *SchemaGenerator) SetNamer(namer *Namer) {
+	sg.namer = namer
 }
 
 // GenerateCreateTable generates a CREATE TABLE statement from a struct type
@@ -25,10 +36,9 @@ func (sg *SchemaGenerator) GenerateCreateTable(entityType reflect.Type, tableNam
 	
 	var columns []string
 	var primaryKeys []string
+	var indexes []expressionIndex
 	
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		
+	for _, field := range columnFields(entityType) {
 		// Skip unexported fields
 		if !field.IsExported() {
 			continue
@@ -42,6 +52,11 @@ func (sg *SchemaGenerator) GenerateCreateTable(entityType reflect.Type, tableNam
 		jetTag := field.Tag.Get("jet")
 		columnDef := sg.generateColumnDefinition(field, dbTag, jetTag)
 		columns = append(columns, columnDef)
+		columnIndexes, err := sg.expressionIndexes(tableName, dbTag, jetTag)
+		if err != nil {
+			return "", err
+		}
+		indexes = append(indexes, columnIndexes...)
 		
 		// Check for primary key
 		if strings.Contains(jetTag, "primary_key") {
@@ -53,7 +68,13 @@ func (sg *SchemaGenerator) GenerateCreateTable(entityType reflect.Type, tableNam
 		return "", fmt.Errorf("no columns found for table %s", tableName)
 	}
 	
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", tableName)
+	// Enum types must exist before the table that uses them
+	query := ""
+	for _, enum := range sg.enumTypes(entityType) {
+		query += enum.createSQL() + "\n\n"
+	}
+	
+	query += fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", tableName)
 	query += strings.Join(columns, ",\n")
 	
 	if len(primaryKeys) > 0 {
@@ -61,10 +82,144 @@ func (sg *SchemaGenerator) GenerateCreateTable(entityType reflect.Type, tableNam
 	}
 	
 	query += "\n);"
+	query += expressionIndexSQL(indexes)
 	
 	return query, nil
 }
 
+// expressionIndex is an index over an expression of a column, declared with
+// jet:"lower_index", jet:"trgm_index" or jet:"unaccent_index" (optionally
+// followed by :name) to serve the case- and accent-insensitive specifications
+type expressionIndex struct {
+	kind string // lower, trgm or unaccent
+	sql  string
+}
+
+// expressionIndexes returns the expression indexes declared on a column.
+// Generated names are shortened to the identifier limit; names given in the
+// tag must fit it.
+func (sg *SchemaGenerator) expressionIndexes(tableName, column, jetTag string) ([]expressionIndex, error) {
+	if sg.namer == nil {
+		sg.namer = NewNamer()
+	}
+	name := func(key, suffix string) (string, error) {
+		if value := sg.extractTagValue(jetTag, key); value != "" {
+			return sg.namer.Explicit(value)
+		}
+		return sg.namer.Name(fmt.Sprintf("idx_%s_%s_%s", tableName, column, suffix))
+	}
+
+	var indexes []expressionIndex
+	for _, def := range []struct {
+		kind, format string
+	}{
+		{"lower", "-- Serves core.EqualIgnoreCase on %[1]s\nCREATE INDEX IF NOT EXISTS %[2]s ON %[3]s (LOWER(%[1]s));"},
+		{"trgm", "-- Serves LIKE and ILIKE patterns on %[1]s, including core.ContainsIgnoreCase\nCREATE INDEX IF NOT EXISTS %[2]s ON %[3]s USING gin (%[1]s gin_trgm_ops);"},
+		{"unaccent", "-- Serves core.EqualUnaccent on %[1]s when core.UnaccentFunction is \"immutable_unaccent\"\nCREATE INDEX IF NOT EXISTS %[2]s ON %[3]s (LOWER(immutable_unaccent(%[1]s)));"},
+	} {
+		key := def.kind + "_index"
+		if !sg.hasTagFlag(jetTag, key) {
+			continue
+		}
+		index, err := name(key, def.kind)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", tableName, column, err)
+		}
+		indexes = append(indexes, expressionIndex{kind: def.kind, sql: fmt.Sprintf(def.format, column, index, tableName)})
+	}
+	return indexes, nil
+}
+
+// expressionIndexSQL renders the indexes after the extensions and functions
+// they depend on
+func expressionIndexSQL(indexes []expressionIndex) string {
+	if len(indexes) == 0 {
+		return ""
+	}
+	needs := make(map[string]bool)
+	for _, index := range indexes {
+		needs[index.kind] = true
+	}
+
+	var parts []string
+	if needs["trgm"] {
+		parts = append(parts, "CREATE EXTENSION IF NOT EXISTS pg_trgm;")
+	}
+	if needs["unaccent"] {
+		// unaccent() is only STABLE; the wrapper pins the dictionary so it can
+		// be declared IMMUTABLE and used in indexes
+		parts = append(parts, "CREATE EXTENSION IF NOT EXISTS unaccent;\n"+
+			"CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text\n"+
+			"LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT\n"+
+			"AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;")
+	}
+	for _, index := range indexes {
+		parts = append(parts, index.sql)
+	}
+	return "\n\n" + strings.Join(parts, "\n\n")
+}
+
+// enumType is a PostgreSQL enum declared with jet:"enum:name(value1,value2)"
+type enumType struct {
+	name   string
+	values []string
+}
+
+// parseEnumType parses the value of an enum tag
+func parseEnumType(value string) (enumType, bool) {
+	name, values, ok := schema.ParseEnum(value)
+	return enumType{name: name, values: values}, ok
+}
+
+// createSQL creates the type unless it already exists, as CREATE TYPE has no IF NOT EXISTS
+func (e enumType) createSQL() string {
+	quoted := make([]string, len(e.values))
+	for i, v := range e.values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return fmt.Sprintf(
+		"DO $$ BEGIN\n    CREATE TYPE %s AS ENUM (%s);\nEXCEPTION\n    WHEN duplicate_object THEN NULL;\nEND $$;",
+		e.name, strings.Join(quoted, ", "),
+	)
+}
+
+// enumTypes lists the enum types used by an entity's columns, once each
+func (sg *SchemaGenerator) enumTypes(entityType reflect.Type) []enumType {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	
+	var enums []enumType
+	seen := make(map[string]bool)
+	for _, field := range columnFields(entityType) {
+		if !field.IsExported() || field.Tag.Get("db") == "" || field.Tag.Get("db") == "-" {
+			continue
+		}
+		enum, ok := parseEnumType(sg.extractTagValue(field.Tag.Get("jet"), "enum"))
+		if ok && !seen[enum.name] {
+			seen[enum.name] = true
+			enums = append(enums, enum)
+		}
+	}
+	return enums
+}
+
+// columnFields lists the struct fields of an entity, flattening embedded
+// structs without a db tag so shared fields like timestamps become columns
+func columnFields(entityType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < entityType.NumField(); i++ {
+		field := entityType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct &&
+			field.Tag.Get("db") == "" && field.Tag.Get("jet") != "-" {
+			fields = append(fields, columnFields(field.Type)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
 // generateColumnDefinition generates a column definition from field metadata
 func (sg *SchemaGenerator) generateColumnDefinition(field reflect.StructField, dbName, jetTag string) string {
 	var parts []string
@@ -88,59 +243,35 @@ func (sg *SchemaGenerator) generateColumnDefinition(field reflect.StructField, d
 	// Default value
 	if defaultVal := sg.extractTagValue(jetTag, "default"); defaultVal != "" {
 		parts = append(parts, fmt.Sprintf("DEFAULT %s", defaultVal))
+	} else if sg.extractTagValue(jetTag, "uuid") == "db" {
+		parts = append(parts, "DEFAULT gen_random_uuid()")
 	}
 	
 	return strings.Join(parts, " ")
 }
 
+// ColumnType returns the PostgreSQL type the schema generator uses for a
+// field of goType with the given jet tag
+func ColumnType(goType reflect.Type, jetTag string) string {
+	return schema.ColumnType(goType, jetTag)
+}
+
 // getColumnType maps Go types to PostgreSQL column types
 func (sg *SchemaGenerator) getColumnType(goType reflect.Type, jetTag string) string {
-	// Check for explicit type in jet tag
-	if explicitType := sg.extractTagValue(jetTag, "type"); explicitType != "" {
-		return explicitType
-	}
-	
-	// Map Go types to PostgreSQL types
-	switch goType.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return "BIGINT"
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return "BIGINT"
-	case reflect.Float32:
-		return "REAL"
-	case reflect.Float64:
-		return "DOUBLE PRECISION"
-	case reflect.Bool:
-		return "BOOLEAN"
-	case reflect.String:
-		if size := sg.extractTagValue(jetTag, "size"); size != "" {
-			return fmt.Sprintf("VARCHAR(%s)", size)
-		}
-		return "TEXT"
-	case reflect.Slice, reflect.Array:
-		if goType.Elem().Kind() == reflect.Uint8 {
-			return "BYTEA"
-		}
-		return "TEXT" // JSON array
-	case reflect.Struct:
-		if goType.String() == "time.Time" {
-			return "TIMESTAMP"
-		}
-		return "TEXT" // JSON object
-	default:
-		return "TEXT"
-	}
+	return schema.ColumnType(goType, jetTag)
+}
+
+// hasTagFlag reports whether a tag contains key, with or without a value
+func (sg *SchemaGenerator) hasTagFlag(tag, key string) bool {
+	return schema.HasTagFlag(tag, key)
 }
 
 // extractTagValue extracts a value from a tag string
 func (sg *SchemaGenerator) extractTagValue(tag, key string) string {
-	parts := strings.Split(tag, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if strings.HasPrefix(part, key+":") {
-			return strings.TrimPrefix(part, key+":")
-		}
-	}
-	return ""
+	return schema.TagValue(tag, key)
 }
 
+// splitTag splits a jet tag on commas outside quotes and parentheses
+func splitTag(tag string) []string {
+	return schema.SplitTag(tag)
+}
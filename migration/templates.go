@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template selects the skeleton written into new migration files
+type Template string
+
+const (
+	// TemplateBlank writes only the migration header
+	TemplateBlank Template = ""
+	// TemplateTable writes a CREATE TABLE / DROP TABLE pair
+	TemplateTable Template = "table"
+	// TemplateIndex writes a CREATE INDEX / DROP INDEX pair
+	TemplateIndex Template = "index"
+	// TemplateData writes a data migration skeleton run in a transaction
+	TemplateData Template = "data"
+	// TemplateColumn writes a re-runnable ADD COLUMN / DROP COLUMN pair
+	TemplateColumn Template = "column"
+	// TemplateConcurrentIndex writes a CREATE INDEX CONCURRENTLY pair run
+	// outside a transaction
+	TemplateConcurrentIndex Template = "concurrent_index"
+	// TemplateRename writes a re-runnable column rename and its reverse
+	TemplateRename Template = "rename"
+)
+
+// Templates lists the named templates accepted by CreateMigrationFromTemplate
+var Templates = []Template{TemplateTable, TemplateIndex, TemplateData, TemplateColumn, TemplateConcurrentIndex, TemplateRename}
+
+// ParseTemplate converts a template name to a Template
+func ParseTemplate(name string) (Template, error) {
+	switch t := Template(strings.ToLower(strings.TrimSpace(name))); t {
+	case TemplateBlank, TemplateTable, TemplateIndex, TemplateData, TemplateColumn, TemplateConcurrentIndex, TemplateRename:
+		return t, nil
+	case "blank":
+		return TemplateBlank, nil
+	default:
+		return "", fmt.Errorf("unknown migration template %q: expected table, index, data, column, concurrent_index or rename", name)
+	}
+}
+
+// render returns the up and down bodies of the template
+func (t Template) render(name string) (string, string, error) {
+	switch t {
+	case TemplateBlank:
+		return "", "", nil
+	case TemplateTable:
+		return `CREATE TABLE table_name (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+`, "DROP TABLE IF EXISTS table_name;\n", nil
+	case TemplateIndex:
+		index := "idx_" + strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+		return fmt.Sprintf("CREATE INDEX %s ON table_name (column_name);\n", index),
+			fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", index), nil
+	case TemplateData:
+		return "-- Write idempotent data changes here, e.g.\n-- UPDATE table_name SET column_name = 'value' WHERE column_name IS NULL;\n",
+			"-- Revert the data changes here\n", nil
+	case TemplateColumn:
+		return AddColumnIfNotExists("table_name", "column_name", "TEXT") + "\n",
+			DropColumnIfExists("table_name", "column_name") + "\n", nil
+	case TemplateConcurrentIndex:
+		index := "idx_" + strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+		return NoTransactionDirective + "\n" + CreateIndexConcurrentlyIfNotExists(index, "table_name", false, "column_name") + "\n",
+			NoTransactionDirective + "\n" + DropIndexConcurrentlyIfExists(index) + "\n", nil
+	case TemplateRename:
+		return RenameColumnIfExists("table_name", "old_name", "new_name") + "\n",
+			RenameColumnIfExists("table_name", "new_name", "old_name") + "\n", nil
+	default:
+		return "", "", fmt.Errorf("unknown migration template %q", string(t))
+	}
+}
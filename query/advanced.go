@@ -12,31 +12,24 @@ type AdvancedQueryBuilder struct {
 	subqueries []*AdvancedSubquery
 	unions     []*UnionQuery
 	ctes       []*CTE
-	window     *WindowFunction
 }
 
 // AdvancedSubquery represents a subquery in advanced builder
 type AdvancedSubquery struct {
 	Alias   string
-	Builder *QueryBuilder
+	Builder SQLBuilder
 }
 
 // UnionQuery represents a UNION query
 type UnionQuery struct {
-	Type   string // UNION, UNION ALL, INTERSECT, EXCEPT
-	Builder *QueryBuilder
+	Type    string // UNION, UNION ALL, INTERSECT, EXCEPT
+	Builder SQLBuilder
 }
 
 // CTE represents a Common Table Expression
 type CTE struct {
 	Name    string
-	Builder *QueryBuilder
-}
-
-// WindowFunction represents a window function
-type WindowFunction struct {
-	Function string
-	Over     string
+	Builder SQLBuilder
 }
 
 // NewAdvancedQueryBuilder creates a new advanced query builder
@@ -50,7 +43,7 @@ func NewAdvancedQueryBuilder(tableName string) *AdvancedQueryBuilder {
 }
 
 // WithCTE adds a Common Table Expression
-func (aqb *AdvancedQueryBuilder) WithCTE(name string, builder *QueryBuilder) *AdvancedQueryBuilder {
+func (aqb *AdvancedQueryBuilder) WithCTE(name string, builder SQLBuilder) *AdvancedQueryBuilder {
 	aqb.ctes = append(aqb.ctes, &CTE{
 		Name:    name,
 		Builder: builder,
@@ -58,8 +51,9 @@ func (aqb *AdvancedQueryBuilder) WithCTE(name string, builder *QueryBuilder) *Ad
 	return aqb
 }
 
-// Subquery adds a subquery
-func (aqb *AdvancedQueryBuilder) Subquery(alias string, builder *QueryBuilder) *AdvancedQueryBuilder {
+// Subquery adds a scalar subquery to the selected columns, replacing the
+// default *
+func (aqb *AdvancedQueryBuilder) Subquery(alias string, builder SQLBuilder) *AdvancedQueryBuilder {
 	aqb.subqueries = append(aqb.subqueries, &AdvancedSubquery{
 		Alias:   alias,
 		Builder: builder,
@@ -68,7 +62,7 @@ func (aqb *AdvancedQueryBuilder) Subquery(alias string, builder *QueryBuilder) *
 }
 
 // Union adds a UNION query
-func (aqb *AdvancedQueryBuilder) Union(builder *QueryBuilder) *AdvancedQueryBuilder {
+func (aqb *AdvancedQueryBuilder) Union(builder SQLBuilder) *AdvancedQueryBuilder {
 	aqb.unions = append(aqb.unions, &UnionQuery{
 		Type:    "UNION",
 		Builder: builder,
@@ -77,7 +71,7 @@ func (aqb *AdvancedQueryBuilder) Union(builder *QueryBuilder) *AdvancedQueryBuil
 }
 
 // UnionAll adds a UNION ALL query
-func (aqb *AdvancedQueryBuilder) UnionAll(builder *QueryBuilder) *AdvancedQueryBuilder {
+func (aqb *AdvancedQueryBuilder) UnionAll(builder SQLBuilder) *AdvancedQueryBuilder {
 	aqb.unions = append(aqb.unions, &UnionQuery{
 		Type:    "UNION ALL",
 		Builder: builder,
@@ -85,60 +79,64 @@ func (aqb *AdvancedQueryBuilder) UnionAll(builder *QueryBuilder) *AdvancedQueryB
 	return aqb
 }
 
-// Window adds a window function
-func (aqb *AdvancedQueryBuilder) Window(function, over string) *AdvancedQueryBuilder {
-	aqb.window = &WindowFunction{
-		Function: function,
-		Over:     over,
-	}
+// Window adds a window function to the selected columns, e.g.
+// Window(RowNumber().Over(Over().PartitionBy("dept").OrderBy("salary", "DESC")).As("rank"))
+func (aqb *AdvancedQueryBuilder) Window(fn *WindowFunction) *AdvancedQueryBuilder {
+	aqb.selectCols = append(aqb.selectCols, fn.String())
 	return aqb
 }
 
-// BuildAdvanced builds the advanced query
+// BuildAdvanced builds the advanced query. Every part is built with its own
+// $1-based arguments and renumbered in the order it appears: CTEs, the main
+// query with its subqueries, then the unions.
 func (aqb *AdvancedQueryBuilder) BuildAdvanced() (string, []interface{}) {
+	p := NewParams()
 	var parts []string
-	var args []interface{}
 
 	// Build CTEs
 	if len(aqb.ctes) > 0 {
 		cteParts := make([]string, 0, len(aqb.ctes))
 		for _, cte := range aqb.ctes {
-			cteQuery, cteArgs := cte.Builder.Build()
-			cteParts = append(cteParts, fmt.Sprintf("%s AS (%s)", cte.Name, cteQuery))
-			args = append(args, cteArgs...)
+			cteParts = append(cteParts, fmt.Sprintf("%s AS (%s)", cte.Name, p.Build(cte.Builder)))
 		}
 		parts = append(parts, "WITH "+strings.Join(cteParts, ", "))
 	}
 
-	// Build main query
-	mainQuery, mainArgs := aqb.QueryBuilder.Build()
-	parts = append(parts, mainQuery)
-	args = append(args, mainArgs...)
+	// Build main query with its subqueries in the select list
+	main := aqb.QueryBuilder.clone()
+	for _, subquery := range aqb.subqueries {
+		sql, args := subquery.Builder.Build()
+		main.SelectAggregate(Aggregate{SQL: "(" + sql + ")", Args: args}, subquery.Alias)
+	}
+	parts = append(parts, p.Build(main))
 
 	// Build unions
 	for _, union := range aqb.unions {
-		unionQuery, unionArgs := union.Builder.Build()
-		parts = append(parts, union.Type, unionQuery)
-		args = append(args, unionArgs...)
+		parts = append(parts, union.Type, p.Build(union.Builder))
 	}
 
-	return strings.Join(parts, " "), args
+	return strings.Join(parts, " "), p.Args()
+}
+
+// Build builds the advanced query, so it can be nested in other builders
+func (aqb *AdvancedQueryBuilder) Build() (string, []interface{}) {
+	return aqb.BuildAdvanced()
 }
 
 // QueryComposer provides fluent query composition
 type QueryComposer struct {
-	queries []*QueryBuilder
+	queries []SQLBuilder
 }
 
 // NewQueryComposer creates a new query composer
 func NewQueryComposer() *QueryComposer {
 	return &QueryComposer{
-		queries: make([]*QueryBuilder, 0),
+		queries: make([]SQLBuilder, 0),
 	}
 }
 
 // AddQuery adds a query to the composition
-func (qc *QueryComposer) AddQuery(builder *QueryBuilder) *QueryComposer {
+func (qc *QueryComposer) AddQuery(builder SQLBuilder) *QueryComposer {
 	qc.queries = append(qc.queries, builder)
 	return qc
 }
@@ -149,19 +147,17 @@ func (qc *QueryComposer) Compose(operator string) (string, []interface{}) {
 		return "", nil
 	}
 
+	p := NewParams()
 	var parts []string
-	var args []interface{}
 
 	for i, query := range qc.queries {
 		if i > 0 {
 			parts = append(parts, operator)
 		}
-		queryStr, queryArgs := query.Build()
-		parts = append(parts, "("+queryStr+")")
-		args = append(args, queryArgs...)
+		parts = append(parts, "("+p.Build(query)+")")
 	}
 
-	return strings.Join(parts, " "), args
+	return strings.Join(parts, " "), p.Args()
 }
 
 // ConditionalBuilder provides conditional query building
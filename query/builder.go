@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
 // QueryBuilder builds SQL queries dynamically
 type QueryBuilder struct {
 	tableName string
 	selectCols []string
+	selectArgs []interface{}
+	joins []joinFragment
 	whereClauses []string
 	whereArgs []interface{}
 	orderBy []string
@@ -40,6 +44,29 @@ func (qb *QueryBuilder) Select(cols ...string) *QueryBuilder {
 	return qb
 }
 
+// joinFragment is a rendered JOIN clause with its $1-based arguments
+type joinFragment struct {
+	sql  string
+	args []interface{}
+}
+
+// SelectAggregate adds an aggregate expression, such as PercentileCont, to
+// the selected columns, replacing the default *. Its arguments are bound
+// before those of the WHERE and HAVING clauses, whose placeholders are
+// renumbered to follow them.
+func (qb *QueryBuilder) SelectAggregate(agg Aggregate, alias string) *QueryBuilder {
+	if len(qb.selectCols) == 1 && qb.selectCols[0] == "*" {
+		qb.selectCols = nil
+	}
+	expr := core.RenumberPlaceholders(agg.SQL, len(qb.selectArgs)+1)
+	if alias != "" {
+		expr += " AS " + alias
+	}
+	qb.selectCols = append(qb.selectCols, expr)
+	qb.selectArgs = append(qb.selectArgs, agg.Args...)
+	return qb
+}
+
 // Where adds a WHERE clause
 func (qb *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
 	qb.whereClauses = append(qb.whereClauses, condition)
@@ -72,12 +99,29 @@ func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuild
 	return qb
 }
 
-// OrderBy adds an ORDER BY clause
+// OrderBy adds an ORDER BY clause. The column is written as given; the
+// direction must be ASC or DESC, optionally followed by NULLS FIRST or
+// NULLS LAST, and anything else sorts ascending. Use core.ValidIdentifier
+// on column names taken from user input.
 func (qb *QueryBuilder) OrderBy(column string, direction string) *QueryBuilder {
-	qb.orderBy = append(qb.orderBy, fmt.Sprintf("%s %s", column, direction))
+	qb.orderBy = append(qb.orderBy, column+" "+sortDirection(direction))
 	return qb
 }
 
+// sortDirection normalizes an ORDER BY direction, falling back to ASC for
+// anything that is not a direction keyword
+func sortDirection(direction string) string {
+	switch d := strings.ToUpper(strings.Join(strings.Fields(direction), " ")); d {
+	case "ASC", "DESC",
+		"ASC NULLS FIRST", "ASC NULLS LAST",
+		"DESC NULLS FIRST", "DESC NULLS LAST":
+		return d
+	case "NULLS FIRST", "NULLS LAST":
+		return "ASC " + d
+	}
+	return "ASC"
+}
+
 // Limit sets the LIMIT clause
 func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
 	qb.limitVal = &limit
@@ -103,50 +147,76 @@ func (qb *QueryBuilder) Having(condition string, args ...interface{}) *QueryBuil
 	return qb
 }
 
-// Build builds the SQL query string
+// clone returns a copy of the builder that can be modified independently
+func (qb *QueryBuilder) clone() *QueryBuilder {
+	c := *qb
+	c.selectCols = append([]string(nil), qb.selectCols...)
+	c.selectArgs = append([]interface{}(nil), qb.selectArgs...)
+	c.joins = append([]joinFragment(nil), qb.joins...)
+	c.whereClauses = append([]string(nil), qb.whereClauses...)
+	c.whereArgs = append([]interface{}(nil), qb.whereArgs...)
+	c.orderBy = append([]string(nil), qb.orderBy...)
+	c.groupBy = append([]string(nil), qb.groupBy...)
+	c.havingClauses = append([]string(nil), qb.havingClauses...)
+	c.havingArgs = append([]interface{}(nil), qb.havingArgs...)
+	return &c
+}
+
+// Build builds the SQL query string. Arguments are numbered in the order
+// their clauses appear: selected expressions, joins, then WHERE and HAVING.
 func (qb *QueryBuilder) Build() (string, []interface{}) {
-	var parts []string
+	p := NewParams()
 	
 	// SELECT
-	parts = append(parts, "SELECT", strings.Join(qb.selectCols, ", "))
+	parts := []string{"SELECT", p.Fragment(strings.Join(qb.selectCols, ", "), qb.selectArgs)}
 	
 	// FROM
 	parts = append(parts, "FROM", qb.tableName)
 	
+	// JOIN
+	for _, join := range qb.joins {
+		parts = append(parts, p.Fragment(join.sql, join.args))
+	}
+	
+	// WHERE through OFFSET, numbered across the WHERE and HAVING arguments
+	var rest []string
+	
 	// WHERE
 	if len(qb.whereClauses) > 0 {
-		parts = append(parts, "WHERE", strings.Join(qb.whereClauses, " AND "))
+		rest = append(rest, "WHERE", strings.Join(qb.whereClauses, " AND "))
 	}
 	
 	// GROUP BY
 	if len(qb.groupBy) > 0 {
-		parts = append(parts, "GROUP BY", strings.Join(qb.groupBy, ", "))
+		rest = append(rest, "GROUP BY", strings.Join(qb.groupBy, ", "))
 	}
 	
 	// HAVING
 	if len(qb.havingClauses) > 0 {
-		parts = append(parts, "HAVING", strings.Join(qb.havingClauses, " AND "))
+		rest = append(rest, "HAVING", strings.Join(qb.havingClauses, " AND "))
 	}
 	
 	// ORDER BY
 	if len(qb.orderBy) > 0 {
-		parts = append(parts, "ORDER BY", strings.Join(qb.orderBy, ", "))
+		rest = append(rest, "ORDER BY", strings.Join(qb.orderBy, ", "))
 	}
 	
 	// LIMIT
 	if qb.limitVal != nil {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", *qb.limitVal))
+		rest = append(rest, fmt.Sprintf("LIMIT %d", *qb.limitVal))
 	}
 	
 	// OFFSET
 	if qb.offsetVal != nil {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", *qb.offsetVal))
+		rest = append(rest, fmt.Sprintf("OFFSET %d", *qb.offsetVal))
 	}
 	
-	query := strings.Join(parts, " ")
-	args := append(qb.whereArgs, qb.havingArgs...)
+	if len(rest) > 0 {
+		restArgs := append(append([]interface{}(nil), qb.whereArgs...), qb.havingArgs...)
+		parts = append(parts, p.Fragment(strings.Join(rest, " "), restArgs))
+	}
 	
-	return query, args
+	return strings.Join(parts, " "), p.Args()
 }
 
 // BuildCount builds a COUNT query
@@ -2,6 +2,8 @@ package query
 
 import (
 	"testing"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
 func TestQueryBuilder_Basic(t *testing.T) {
@@ -83,6 +85,50 @@ func TestComposableQuery_WithSpecification(t *testing.T) {
 	}
 }
 
+func TestComposableQuery_HavingSpecification(t *testing.T) {
+	cq := NewComposableQuery[string]("orders")
+	cq.Select("customer_id", "COUNT(*)")
+	cq.WhereEqual("status", "paid")
+	cq.WithSpecification(core.GreaterThan[string]("total", 100))
+	cq.GroupBy("customer_id")
+	cq.HavingSpecification(core.And(
+		core.GreaterThan[string]("COUNT(*)", 5),
+		core.LessThan[string]("SUM(total)", 10000),
+	))
+
+	query, args := cq.Build()
+
+	expected := "SELECT customer_id, COUNT(*) FROM orders WHERE status = $1 AND total > $2 GROUP BY customer_id HAVING (COUNT(*) > $3) AND (SUM(total) < $4)"
+	if query != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, query)
+	}
+	if len(args) != 4 || args[0] != "paid" || args[1] != 100 || args[2] != 5 || args[3] != 10000 {
+		t.Errorf("Expected [paid 100 5 10000], got %v", args)
+	}
+
+	// Building again must not duplicate the specification clauses
+	again, againArgs := cq.Build()
+	if again != query || len(againArgs) != len(args) {
+		t.Errorf("Expected repeated Build to be stable, got '%s'", again)
+	}
+}
+
+func TestComposableQuery_WhereSpecification(t *testing.T) {
+	cq := NewComposableQuery[string]("users")
+	cq.WhereEqual("status", "active")
+	cq.WhereSpecification(core.Between[string]("age", 18, 65))
+
+	query, args := cq.Build()
+
+	expected := "SELECT * FROM users WHERE status = $1 AND age BETWEEN $2 AND $3"
+	if query != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, query)
+	}
+	if len(args) != 3 {
+		t.Errorf("Expected 3 args, got %d", len(args))
+	}
+}
+
 func TestConditionBuilder_Basic(t *testing.T) {
 	cb := NewConditionBuilder()
 	cb.Equal("status", "active")
@@ -172,3 +218,136 @@ func contains(s, substr string) bool {
 		   contains(s[1:], substr))))
 }
 
+
+func TestAdvancedQueryBuilder_Renumbering(t *testing.T) {
+	cte := NewQueryBuilder("orders").Select("user_id").WhereEqual("status", "paid")
+	other := NewQueryBuilder("archived_users").Select("id").WhereEqual("region", "eu")
+
+	aqb := NewAdvancedQueryBuilder("users")
+	aqb.Select("id")
+	aqb.WhereEqual("active", true)
+	aqb.WithCTE("paid", cte)
+	aqb.Union(other)
+
+	query, args := aqb.BuildAdvanced()
+
+	expected := "WITH paid AS (SELECT user_id FROM orders WHERE status = $1) SELECT id FROM users WHERE active = $2 UNION SELECT id FROM archived_users WHERE region = $3"
+	if query != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, query)
+	}
+	if len(args) != 3 || args[0] != "paid" || args[1] != true || args[2] != "eu" {
+		t.Errorf("Expected [paid true eu], got %v", args)
+	}
+}
+
+func TestJoinQuery_Renumbering(t *testing.T) {
+	jq := NewJoinQuery[string]("users")
+	jq.InnerJoin("orders", "orders.user_id = users.id AND orders.status = $1", "paid")
+	jq.WhereEqual("users.active", true)
+
+	query, args := jq.Build()
+
+	if !contains(query, "orders.status = $1") || !contains(query, "users.active = $2") {
+		t.Errorf("Expected join args before where args, got '%s'", query)
+	}
+	if len(args) != 2 || args[0] != "paid" || args[1] != true {
+		t.Errorf("Expected [paid true], got %v", args)
+	}
+}
+
+func TestQueryBuilder_SelectAggregate(t *testing.T) {
+	qb := NewQueryBuilder("requests").
+		Select("route").
+		SelectAggregate(PercentileCont("latency_ms", 0.5), "p50").
+		SelectAggregate(PercentileDisc("latency_ms", 0.99), "p99").
+		SelectAggregate(Stddev("latency_ms"), "").
+		WhereEqual("status", 200).
+		GroupBy("route").
+		Having("COUNT(*) > $2", 10)
+
+	query, args := qb.Build()
+
+	expected := "SELECT route, percentile_cont($1) WITHIN GROUP (ORDER BY latency_ms) AS p50, " +
+		"percentile_disc($2) WITHIN GROUP (ORDER BY latency_ms) AS p99, stddev_samp(latency_ms) " +
+		"FROM requests WHERE status = $3 GROUP BY route HAVING COUNT(*) > $4"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if len(args) != 4 || args[0] != 0.5 || args[1] != 0.99 || args[2] != 200 || args[3] != 10 {
+		t.Errorf("Unexpected args %v", args)
+	}
+
+	query, _ = NewQueryBuilder("products").SelectAggregate(Mode("category"), "top").Build()
+	if query != "SELECT mode() WITHIN GROUP (ORDER BY category) AS top FROM products" {
+		t.Errorf("Unexpected query %s", query)
+	}
+}
+
+func TestJoinQuery_Lateral(t *testing.T) {
+	latest := NewQueryBuilder("orders").
+		Select("orders.id", "orders.total").
+		Where("orders.user_id = users.id AND orders.status = $1", "paid").
+		OrderBy("orders.created_at", "DESC").
+		Limit(3)
+
+	jq := NewJoinQuery[string]("users")
+	jq.InnerJoin("profiles", "profiles.user_id = users.id AND profiles.kind = $1", "public")
+	jq.CrossJoinLateral(latest, "recent")
+	jq.LeftJoinLateral(NewQueryBuilder("logins").Select("MAX(at) AS last_login").Where("logins.user_id = users.id"), "l")
+	jq.WhereEqual("users.active", true)
+
+	query, args := jq.Build()
+
+	expected := "SELECT * FROM users INNER JOIN profiles ON profiles.user_id = users.id AND profiles.kind = $1 " +
+		"CROSS JOIN LATERAL (SELECT orders.id, orders.total FROM orders WHERE orders.user_id = users.id AND orders.status = $2 " +
+		"ORDER BY orders.created_at DESC LIMIT 3) AS recent " +
+		"LEFT JOIN LATERAL (SELECT MAX(at) AS last_login FROM logins WHERE logins.user_id = users.id) AS l ON TRUE " +
+		"WHERE users.active = $3"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if len(args) != 3 || args[0] != "public" || args[1] != "paid" || args[2] != true {
+		t.Errorf("Expected [public paid true], got %v", args)
+	}
+}
+
+func TestQueryBuilder_OrderByDirection(t *testing.T) {
+	qb := NewQueryBuilder("users")
+	qb.OrderBy("created_at", "desc nulls last")
+	qb.OrderBy("id", "ASC; DROP TABLE users")
+
+	query, _ := qb.Build()
+	if !contains(query, "ORDER BY created_at DESC NULLS LAST, id ASC") {
+		t.Errorf("Unexpected ORDER BY in '%s'", query)
+	}
+}
+
+type ProjectionInvoice struct {
+	ID    int64 `db:"id" jet:"primary_key"`
+	Total int64 `db:"total"`
+}
+
+type ProjectionClient struct {
+	ID   int64  `db:"id" jet:"primary_key"`
+	Name string `db:"name"`
+}
+
+type projectionInvoiceView struct {
+	ProjectionInvoice `jet:"alias:i"`
+	*ProjectionClient
+}
+
+func TestNewProjectionQuery(t *testing.T) {
+	jq, err := NewProjectionQuery[projectionInvoiceView]("projection_invoice i")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	jq.LeftJoin("projection_client", "projection_client.id = i.client_id")
+
+	query, _ := jq.Build()
+	want := "SELECT i.id AS i__id, i.total AS i__total, projection_client.id AS projection_client__id, " +
+		"projection_client.name AS projection_client__name FROM projection_invoice i LEFT JOIN projection_client"
+	if len(query) < len(want) || query[:len(want)] != want {
+		t.Errorf("Expected query to start with %q, got %q", want, query)
+	}
+}
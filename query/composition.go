@@ -2,7 +2,6 @@ package query
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/satishbabariya/jetorm/core"
 )
@@ -11,6 +10,7 @@ import (
 type ComposableQuery[T any] struct {
 	builder     *QueryBuilder
 	spec        core.Specification[T]
+	havingSpec  core.Specification[T]
 	tableName   string
 	entityType  string
 }
@@ -52,6 +52,7 @@ func (cq *ComposableQuery[T]) WhereSpecification(spec core.Specification[T]) *Co
 	if spec != nil {
 		whereClause, args := spec.ToSQL()
 		if whereClause != "" {
+			whereClause = core.RenumberPlaceholders(whereClause, len(cq.builder.whereArgs)+1)
 			cq.builder.Where(whereClause, args...)
 		}
 	}
@@ -88,38 +89,56 @@ func (cq *ComposableQuery[T]) Having(condition string, args ...interface{}) *Com
 	return cq
 }
 
-// Build builds the final SQL query
-func (cq *ComposableQuery[T]) Build() (string, []interface{}) {
-	// Apply specification if set
+// HavingSpecification sets a specification for the HAVING clause.
+// Its placeholders are renumbered at build time to follow the WHERE arguments.
+func (cq *ComposableQuery[T]) HavingSpecification(spec core.Specification[T]) *ComposableQuery[T] {
+	cq.havingSpec = spec
+	return cq
+}
+
+// prepare returns a copy of the builder with the WHERE and HAVING
+// specifications applied, so Build can be called repeatedly
+func (cq *ComposableQuery[T]) prepare() *QueryBuilder {
+	qb := cq.builder.clone()
+	
 	if cq.spec != nil {
 		whereClause, args := cq.spec.ToSQL()
 		if whereClause != "" {
-			cq.builder.Where(whereClause, args...)
+			qb.Where(core.RenumberPlaceholders(whereClause, len(qb.whereArgs)+1), args...)
 		}
 	}
-	return cq.builder.Build()
+	
+	// HAVING arguments are bound after all WHERE arguments
+	if cq.havingSpec != nil {
+		havingClause, args := cq.havingSpec.ToSQL()
+		if havingClause != "" {
+			start := len(qb.whereArgs) + len(qb.havingArgs) + 1
+			qb.Having(core.RenumberPlaceholders(havingClause, start), args...)
+		}
+	}
+	
+	return qb
+}
+
+// Build builds the final SQL query
+func (cq *ComposableQuery[T]) Build() (string, []interface{}) {
+	return cq.prepare().Build()
 }
 
 // BuildCount builds a COUNT query
 func (cq *ComposableQuery[T]) BuildCount() (string, []interface{}) {
-	// Apply specification if set
-	if cq.spec != nil {
-		whereClause, args := cq.spec.ToSQL()
-		if whereClause != "" {
-			cq.builder.Where(whereClause, args...)
-		}
-	}
-	return cq.builder.BuildCount()
+	return cq.prepare().BuildCount()
 }
 
 // Join represents a JOIN clause
 type Join struct {
-	Type      string // "INNER", "LEFT", "RIGHT", "FULL"
-	Table     string
-	Condition string
+	Type      string // "INNER", "LEFT", "RIGHT", "FULL", "CROSS"
+	Table     string // Table, or LATERAL (subquery) AS alias
+	Condition string // Empty for CROSS joins
 	Args      []interface{}
 }
 
+
 // JoinQuery represents a query with joins
 type JoinQuery[T any] struct {
 	*ComposableQuery[T]
@@ -184,35 +203,47 @@ func (jq *JoinQuery[T]) FullJoin(table, condition string, args ...interface{}) *
 	return jq
 }
 
-// Build builds the query with joins
+// CrossJoinLateral joins each row with the rows of a subquery that may
+// reference the preceding tables, e.g. the latest orders of each user
+func (jq *JoinQuery[T]) CrossJoinLateral(subquery SQLBuilder, alias string) *JoinQuery[T] {
+	query, args := subquery.Build()
+	jq.joins = append(jq.joins, Join{
+		Type:  "CROSS",
+		Table: fmt.Sprintf("LATERAL (%s) AS %s", query, alias),
+		Args:  args,
+	})
+	return jq
+}
+
+// LeftJoinLateral is CrossJoinLateral keeping rows for which the subquery
+// returns nothing, with NULL subquery columns
+func (jq *JoinQuery[T]) LeftJoinLateral(subquery SQLBuilder, alias string) *JoinQuery[T] {
+	query, args := subquery.Build()
+	jq.joins = append(jq.joins, Join{
+		Type:      "LEFT",
+		Table:     fmt.Sprintf("LATERAL (%s) AS %s", query, alias),
+		Condition: "TRUE",
+		Args:      args,
+	})
+	return jq
+}
+
+// Build builds the query with joins. Join arguments are numbered after the
+// selected expressions and before the WHERE arguments.
 func (jq *JoinQuery[T]) Build() (string, []interface{}) {
-	query, args := jq.ComposableQuery.Build()
-	
-	// Insert JOIN clauses after FROM
-	if len(jq.joins) > 0 {
-		fromIndex := strings.Index(query, "FROM")
-		if fromIndex > 0 {
-			beforeFrom := query[:fromIndex+4]
-			afterFrom := query[fromIndex+4:]
-			
-			var joinClauses []string
-			joinArgs := make([]interface{}, 0)
-			
-			for _, join := range jq.joins {
-				joinType := join.Type
-				if joinType == "FULL" {
-					joinType = "FULL OUTER"
-				}
-				joinClauses = append(joinClauses, fmt.Sprintf("%s JOIN %s ON %s", joinType, join.Table, join.Condition))
-				joinArgs = append(joinArgs, join.Args...)
-			}
-			
-			query = beforeFrom + " " + strings.Join(joinClauses, " ") + " " + afterFrom
-			args = append(joinArgs, args...)
+	qb := jq.prepare()
+	for _, join := range jq.joins {
+		joinType := join.Type
+		if joinType == "FULL" {
+			joinType = "FULL OUTER"
 		}
+		clause := fmt.Sprintf("%s JOIN %s", joinType, join.Table)
+		if join.Condition != "" {
+			clause += " ON " + join.Condition
+		}
+		qb.joins = append(qb.joins, joinFragment{sql: clause, args: join.Args})
 	}
-	
-	return query, args
+	return qb.Build()
 }
 
 // Subquery represents a subquery
@@ -246,42 +277,15 @@ func (sq *SubqueryQuery[T]) WithSubquery(query string, args []interface{}, alias
 	return sq
 }
 
-// Build builds the query with subqueries
+// Build builds the query with subqueries added to the selected columns,
+// replacing the default *. Subquery arguments are numbered before the
+// WHERE arguments.
 func (sq *SubqueryQuery[T]) Build() (string, []interface{}) {
-	query, args := sq.ComposableQuery.Build()
-	
-	// Add subqueries to SELECT clause
-	if len(sq.subqueries) > 0 {
-		selectIndex := strings.Index(query, "SELECT")
-		if selectIndex >= 0 {
-			afterSelect := query[selectIndex+6:]
-			fromIndex := strings.Index(afterSelect, "FROM")
-			
-			if fromIndex > 0 {
-				beforeFrom := query[:selectIndex+6] + afterSelect[:fromIndex]
-				afterFrom := afterSelect[fromIndex:]
-				
-				var subqueryClauses []string
-				subqueryArgs := make([]interface{}, 0)
-				
-				for _, subq := range sq.subqueries {
-					subqueryClauses = append(subqueryClauses, fmt.Sprintf("(%s) AS %s", subq.Query, subq.Alias))
-					subqueryArgs = append(subqueryArgs, subq.Args...)
-				}
-				
-				existingCols := strings.TrimSpace(beforeFrom[selectIndex+6:])
-				if existingCols == "" || existingCols == "*" {
-					query = query[:selectIndex+6] + strings.Join(subqueryClauses, ", ") + " " + afterFrom
-				} else {
-					query = beforeFrom + ", " + strings.Join(subqueryClauses, ", ") + " " + afterFrom
-				}
-				
-				args = append(subqueryArgs, args...)
-			}
-		}
+	qb := sq.prepare()
+	for _, subq := range sq.subqueries {
+		qb.SelectAggregate(Aggregate{SQL: "(" + subq.Query + ")", Args: subq.Args}, subq.Alias)
 	}
-	
-	return query, args
+	return qb.Build()
 }
 
 // DynamicQuery allows building queries dynamically based on conditions
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/satishbabariya/jetorm/core"
 )
 
 // ConditionBuilder helps build WHERE conditions
@@ -148,10 +150,10 @@ func (cb *ConditionBuilder) NotExists(subquery string, args ...interface{}) *Con
 	return cb
 }
 
-// And combines conditions with AND
+// And combines conditions with AND, renumbering the other builder's
+// placeholders to follow this one's
 func (cb *ConditionBuilder) And(other *ConditionBuilder) *ConditionBuilder {
-	cb.conditions = append(cb.conditions, other.conditions...)
-	cb.args = append(cb.args, other.args...)
+	mergeConditions(cb, other)
 	return cb
 }
 
@@ -159,7 +161,7 @@ func (cb *ConditionBuilder) And(other *ConditionBuilder) *ConditionBuilder {
 func (cb *ConditionBuilder) Or(other *ConditionBuilder) *ConditionBuilder {
 	if len(cb.conditions) > 0 && len(other.conditions) > 0 {
 		left := "(" + strings.Join(cb.conditions, " AND ") + ")"
-		right := "(" + strings.Join(other.conditions, " AND ") + ")"
+		right := "(" + core.RenumberPlaceholders(strings.Join(other.conditions, " AND "), len(cb.args)+1) + ")"
 		cb.conditions = []string{left + " OR " + right}
 		cb.args = append(cb.args, other.args...)
 	}
@@ -176,8 +178,7 @@ func (cb *ConditionBuilder) Build() (string, []interface{}) {
 
 // DateRange creates a condition for date range queries
 func DateRange(column string, start, end time.Time) *ConditionBuilder {
-	cb := NewConditionBuilder()
-	return cb.GreaterThanEqual(column, start).And(cb.LessThanEqual(column, end))
+	return NewConditionBuilder().GreaterThanEqual(column, start).LessThanEqual(column, end)
 }
 
 // TextSearch creates a condition for full-text search (PostgreSQL)
@@ -215,3 +216,37 @@ func ArrayOverlaps(column string, values []interface{}) *ConditionBuilder {
 	return cb
 }
 
+
+// Aggregate is an aggregate expression with its arguments, numbered from $1,
+// for QueryBuilder.SelectAggregate
+type Aggregate struct {
+	SQL  string
+	Args []interface{}
+}
+
+// PercentileCont interpolates the percentile (0 to 1) of a numeric column,
+// e.g. PercentileCont("latency_ms", 0.95) for p95
+func PercentileCont(column string, fraction float64) Aggregate {
+	return Aggregate{SQL: fmt.Sprintf("percentile_cont($1) WITHIN GROUP (ORDER BY %s)", column), Args: []interface{}{fraction}}
+}
+
+// PercentileDisc returns the first value of a column whose position in the
+// ordering reaches the percentile (0 to 1)
+func PercentileDisc(column string, fraction float64) Aggregate {
+	return Aggregate{SQL: fmt.Sprintf("percentile_disc($1) WITHIN GROUP (ORDER BY %s)", column), Args: []interface{}{fraction}}
+}
+
+// Stddev is the sample standard deviation of a numeric column
+func Stddev(column string) Aggregate {
+	return Aggregate{SQL: fmt.Sprintf("stddev_samp(%s)", column)}
+}
+
+// StddevPop is the population standard deviation of a numeric column
+func StddevPop(column string) Aggregate {
+	return Aggregate{SQL: fmt.Sprintf("stddev_pop(%s)", column)}
+}
+
+// Mode is the most frequent value of a column, the smallest one on ties
+func Mode(column string) Aggregate {
+	return Aggregate{SQL: fmt.Sprintf("mode() WITHIN GROUP (ORDER BY %s)", column)}
+}
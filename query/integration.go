@@ -112,6 +112,10 @@ func (rq *RepositoryQuery[T, ID]) Paginate(ctx context.Context, pageable core.Pa
 		pageable = core.PageRequest(0, 20)
 	}
 	
+	if err := pageable.ValidateSort(); err != nil {
+		return nil, err
+	}
+	
 	// Calculate offset
 	offset := pageable.Page * pageable.Size
 	
@@ -235,3 +239,24 @@ func WithGroupBy(columns ...string) QueryOption {
 //     WithLimit(10),
 // )
 
+
+// NewProjectionQuery starts a join query on table selecting the columns of
+// the entities embedded in projection struct P (see core.ProjectionColumns).
+// Join the other entities' tables under their table names or aliases and run
+// it with FindProjection.
+func NewProjectionQuery[P any](table string) (*JoinQuery[P], error) {
+	columns, err := core.ProjectionColumns[P]()
+	if err != nil {
+		return nil, err
+	}
+	jq := NewJoinQuery[P](table)
+	jq.ComposableQuery.Select(columns...)
+	return jq, nil
+}
+
+// FindProjection runs a built query and scans its rows into projection
+// struct P with core.QueryProjection
+func FindProjection[P any](ctx context.Context, db *core.Database, q SQLBuilder) ([]*P, error) {
+	query, args := q.Build()
+	return core.QueryProjection[P](ctx, db, query, args...)
+}
@@ -0,0 +1,408 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+// InsertBuilder builds INSERT statements
+type InsertBuilder struct {
+	tableName string
+	columns   []string
+	rows      [][]interface{}
+	conflict  *insertConflict
+	returning []string
+}
+
+// insertConflict is the ON CONFLICT clause of an insert
+type insertConflict struct {
+	columns     []string
+	constraint  string
+	doNothing   bool
+	assignments []assignment
+	where       *ConditionBuilder
+}
+
+// NewInsertBuilder creates a new insert builder
+func NewInsertBuilder(tableName string) *InsertBuilder {
+	return &InsertBuilder{tableName: tableName}
+}
+
+// Columns sets the columns to insert
+func (ib *InsertBuilder) Columns(cols ...string) *InsertBuilder {
+	ib.columns = cols
+	return ib
+}
+
+// Values adds a row of values, one per column
+func (ib *InsertBuilder) Values(values ...interface{}) *InsertBuilder {
+	ib.rows = append(ib.rows, values)
+	return ib
+}
+
+// Set adds a column and its value to a single-row insert
+func (ib *InsertBuilder) Set(column string, value interface{}) *InsertBuilder {
+	ib.columns = append(ib.columns, column)
+	if len(ib.rows) == 0 {
+		ib.rows = append(ib.rows, nil)
+	}
+	ib.rows[0] = append(ib.rows[0], value)
+	return ib
+}
+
+// SetMap adds the columns and values of a map, in column order
+func (ib *InsertBuilder) SetMap(values map[string]interface{}) *InsertBuilder {
+	for _, column := range sortedKeys(values) {
+		ib.Set(column, values[column])
+	}
+	return ib
+}
+
+// OnConflict adds an ON CONFLICT clause targeting the given unique columns.
+// Without columns it matches any conflict, which only DoNothing accepts.
+// Follow it with DoNothing or DoUpdateSet.
+func (ib *InsertBuilder) OnConflict(columns ...string) *InsertBuilder {
+	ib.conflict = &insertConflict{columns: columns, where: NewConditionBuilder()}
+	return ib
+}
+
+// OnConflictConstraint adds an ON CONFLICT ON CONSTRAINT clause
+func (ib *InsertBuilder) OnConflictConstraint(name string) *InsertBuilder {
+	ib.conflict = &insertConflict{constraint: name, where: NewConditionBuilder()}
+	return ib
+}
+
+// DoNothing skips conflicting rows
+func (ib *InsertBuilder) DoNothing() *InsertBuilder {
+	c := ib.onConflict()
+	c.doNothing = true
+	c.assignments = nil
+	return ib
+}
+
+// DoUpdateSet overwrites the given columns with the proposed row
+// (column = EXCLUDED.column) on conflict. With no columns, every inserted
+// column except the conflict target is updated, as with core.OnConflict.
+func (ib *InsertBuilder) DoUpdateSet(columns ...string) *InsertBuilder {
+	c := ib.onConflict()
+	c.doNothing = false
+	if len(columns) == 0 {
+		target := make(map[string]bool, len(c.columns))
+		for _, column := range c.columns {
+			target[column] = true
+		}
+		for _, column := range ib.columns {
+			if !target[column] {
+				columns = append(columns, column)
+			}
+		}
+	}
+	for _, column := range columns {
+		c.assignments = append(c.assignments, assignment{column: column, expr: "EXCLUDED." + column})
+	}
+	return ib
+}
+
+// DoUpdateSetValue assigns a value to a column on conflict
+func (ib *InsertBuilder) DoUpdateSetValue(column string, value interface{}) *InsertBuilder {
+	c := ib.onConflict()
+	c.doNothing = false
+	c.assignments = append(c.assignments, assignment{column: column, args: []interface{}{value}})
+	return ib
+}
+
+// DoUpdateSetExpr assigns an SQL expression to a column on conflict, such as
+// "users.logins + 1". Its placeholders are numbered from $1.
+func (ib *InsertBuilder) DoUpdateSetExpr(column, expr string, args ...interface{}) *InsertBuilder {
+	c := ib.onConflict()
+	c.doNothing = false
+	c.assignments = append(c.assignments, assignment{column: column, expr: expr, args: args})
+	return ib
+}
+
+// DoUpdateWhere limits the conflict update to existing rows matching the
+// conditions, e.g. to skip rows with a newer version
+func (ib *InsertBuilder) DoUpdateWhere(cb *ConditionBuilder) *InsertBuilder {
+	mergeConditions(ib.onConflict().where, cb)
+	return ib
+}
+
+// onConflict returns the conflict clause, starting an untargeted one
+func (ib *InsertBuilder) onConflict() *insertConflict {
+	if ib.conflict == nil {
+		ib.OnConflict()
+	}
+	return ib.conflict
+}
+
+// Returning sets the RETURNING columns
+func (ib *InsertBuilder) Returning(cols ...string) *InsertBuilder {
+	ib.returning = cols
+	return ib
+}
+
+// Build builds the INSERT statement. Without rows it inserts DEFAULT VALUES.
+func (ib *InsertBuilder) Build() (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+
+	parts = append(parts, "INSERT INTO", ib.tableName)
+	if len(ib.columns) > 0 {
+		parts = append(parts, "("+strings.Join(ib.columns, ", ")+")")
+	}
+
+	if len(ib.rows) == 0 {
+		parts = append(parts, "DEFAULT VALUES")
+	} else {
+		rows := make([]string, len(ib.rows))
+		for i, row := range ib.rows {
+			placeholders := make([]string, len(row))
+			for j, value := range row {
+				args = append(args, value)
+				placeholders[j] = fmt.Sprintf("$%d", len(args))
+			}
+			rows[i] = "(" + strings.Join(placeholders, ", ") + ")"
+		}
+		parts = append(parts, "VALUES", strings.Join(rows, ", "))
+	}
+
+	if c := ib.conflict; c != nil {
+		parts = append(parts, "ON CONFLICT")
+		if c.constraint != "" {
+			parts = append(parts, "ON CONSTRAINT", c.constraint)
+		} else if len(c.columns) > 0 {
+			parts = append(parts, "("+strings.Join(c.columns, ", ")+")")
+		}
+
+		if c.doNothing || len(c.assignments) == 0 {
+			parts = append(parts, "DO NOTHING")
+		} else {
+			var sets []string
+			sets, args = buildAssignments(c.assignments, args)
+			parts = append(parts, "DO UPDATE SET", strings.Join(sets, ", "))
+			if where, whereArgs := c.where.Build(); where != "" {
+				parts = append(parts, "WHERE", core.RenumberPlaceholders(where, len(args)+1))
+				args = append(args, whereArgs...)
+			}
+		}
+	}
+
+	if len(ib.returning) > 0 {
+		parts = append(parts, "RETURNING", strings.Join(ib.returning, ", "))
+	}
+
+	return strings.Join(parts, " "), args
+}
+
+// Execute executes the statement and returns the affected rows
+func (ib *InsertBuilder) Execute(ctx context.Context, executor Executor) (Result, error) {
+	query, args := ib.Build()
+	return executor.Exec(ctx, query, args...)
+}
+
+// ExecuteReturning executes the statement and returns the RETURNING rows
+func (ib *InsertBuilder) ExecuteReturning(ctx context.Context, executor Executor) (Rows, error) {
+	query, args := ib.Build()
+	return executor.Query(ctx, query, args...)
+}
+
+// assignment is a SET item of an UPDATE, either a value or an expression
+type assignment struct {
+	column string
+	expr   string // $1-based expression, empty for a plain value
+	args   []interface{}
+}
+
+// UpdateBuilder builds UPDATE statements
+type UpdateBuilder struct {
+	tableName   string
+	assignments []assignment
+	where       *ConditionBuilder
+	returning   []string
+}
+
+// NewUpdateBuilder creates a new update builder
+func NewUpdateBuilder(tableName string) *UpdateBuilder {
+	return &UpdateBuilder{tableName: tableName, where: NewConditionBuilder()}
+}
+
+// Set assigns a value to a column
+func (ub *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	ub.assignments = append(ub.assignments, assignment{column: column, args: []interface{}{value}})
+	return ub
+}
+
+// SetMap assigns the values of a map, in column order
+func (ub *UpdateBuilder) SetMap(values map[string]interface{}) *UpdateBuilder {
+	for _, column := range sortedKeys(values) {
+		ub.Set(column, values[column])
+	}
+	return ub
+}
+
+// SetExpr assigns an SQL expression to a column, such as "version + 1" or
+// "COALESCE($1, name)". Its placeholders are numbered from $1.
+func (ub *UpdateBuilder) SetExpr(column, expr string, args ...interface{}) *UpdateBuilder {
+	ub.assignments = append(ub.assignments, assignment{column: column, expr: expr, args: args})
+	return ub
+}
+
+// Where adds a WHERE condition whose placeholders are numbered after the
+// arguments of the previous conditions, as with QueryBuilder.Where
+func (ub *UpdateBuilder) Where(condition string, args ...interface{}) *UpdateBuilder {
+	ub.where.conditions = append(ub.where.conditions, condition)
+	ub.where.args = append(ub.where.args, args...)
+	return ub
+}
+
+// WhereEqual adds an equality WHERE condition
+func (ub *UpdateBuilder) WhereEqual(column string, value interface{}) *UpdateBuilder {
+	ub.where.Equal(column, value)
+	return ub
+}
+
+// WhereConditions adds the conditions of a ConditionBuilder
+func (ub *UpdateBuilder) WhereConditions(cb *ConditionBuilder) *UpdateBuilder {
+	mergeConditions(ub.where, cb)
+	return ub
+}
+
+// Returning sets the RETURNING columns
+func (ub *UpdateBuilder) Returning(cols ...string) *UpdateBuilder {
+	ub.returning = cols
+	return ub
+}
+
+// Build builds the UPDATE statement. The SET arguments come first, followed
+// by the WHERE arguments.
+func (ub *UpdateBuilder) Build() (string, []interface{}) {
+	sets, args := buildAssignments(ub.assignments, nil)
+	parts := []string{"UPDATE", ub.tableName, "SET", strings.Join(sets, ", ")}
+	if where, whereArgs := ub.where.Build(); where != "" {
+		parts = append(parts, "WHERE", core.RenumberPlaceholders(where, len(args)+1))
+		args = append(args, whereArgs...)
+	}
+	if len(ub.returning) > 0 {
+		parts = append(parts, "RETURNING", strings.Join(ub.returning, ", "))
+	}
+
+	return strings.Join(parts, " "), args
+}
+
+// Execute executes the statement and returns the affected rows
+func (ub *UpdateBuilder) Execute(ctx context.Context, executor Executor) (Result, error) {
+	query, args := ub.Build()
+	return executor.Exec(ctx, query, args...)
+}
+
+// ExecuteReturning executes the statement and returns the RETURNING rows
+func (ub *UpdateBuilder) ExecuteReturning(ctx context.Context, executor Executor) (Rows, error) {
+	query, args := ub.Build()
+	return executor.Query(ctx, query, args...)
+}
+
+// DeleteBuilder builds DELETE statements
+type DeleteBuilder struct {
+	tableName string
+	where     *ConditionBuilder
+	returning []string
+}
+
+// NewDeleteBuilder creates a new delete builder
+func NewDeleteBuilder(tableName string) *DeleteBuilder {
+	return &DeleteBuilder{tableName: tableName, where: NewConditionBuilder()}
+}
+
+// Where adds a WHERE condition whose placeholders are numbered after the
+// arguments of the previous conditions, as with QueryBuilder.Where
+func (db *DeleteBuilder) Where(condition string, args ...interface{}) *DeleteBuilder {
+	db.where.conditions = append(db.where.conditions, condition)
+	db.where.args = append(db.where.args, args...)
+	return db
+}
+
+// WhereEqual adds an equality WHERE condition
+func (db *DeleteBuilder) WhereEqual(column string, value interface{}) *DeleteBuilder {
+	db.where.Equal(column, value)
+	return db
+}
+
+// WhereConditions adds the conditions of a ConditionBuilder
+func (db *DeleteBuilder) WhereConditions(cb *ConditionBuilder) *DeleteBuilder {
+	mergeConditions(db.where, cb)
+	return db
+}
+
+// Returning sets the RETURNING columns
+func (db *DeleteBuilder) Returning(cols ...string) *DeleteBuilder {
+	db.returning = cols
+	return db
+}
+
+// Build builds the DELETE statement
+func (db *DeleteBuilder) Build() (string, []interface{}) {
+	parts := []string{"DELETE FROM", db.tableName}
+	where, args := db.where.Build()
+	if where != "" {
+		parts = append(parts, "WHERE", where)
+	}
+	if len(db.returning) > 0 {
+		parts = append(parts, "RETURNING", strings.Join(db.returning, ", "))
+	}
+	return strings.Join(parts, " "), args
+}
+
+// Execute executes the statement and returns the affected rows
+func (db *DeleteBuilder) Execute(ctx context.Context, executor Executor) (Result, error) {
+	query, args := db.Build()
+	return executor.Exec(ctx, query, args...)
+}
+
+// ExecuteReturning executes the statement and returns the RETURNING rows
+func (db *DeleteBuilder) ExecuteReturning(ctx context.Context, executor Executor) (Rows, error) {
+	query, args := db.Build()
+	return executor.Query(ctx, query, args...)
+}
+
+// buildAssignments renders SET items, numbering their placeholders after args
+func buildAssignments(assignments []assignment, args []interface{}) ([]string, []interface{}) {
+	sets := make([]string, len(assignments))
+	for i, a := range assignments {
+		if a.expr == "" {
+			args = append(args, a.args...)
+			sets[i] = fmt.Sprintf("%s = $%d", a.column, len(args))
+			continue
+		}
+		sets[i] = fmt.Sprintf("%s = %s", a.column, core.RenumberPlaceholders(a.expr, len(args)+1))
+		args = append(args, a.args...)
+	}
+	return sets, args
+}
+
+// mergeConditions appends the conditions of src to dst, numbering their
+// placeholders after the arguments dst already has
+func mergeConditions(dst, src *ConditionBuilder) {
+	if src == nil {
+		return
+	}
+	start := len(dst.args) + 1
+	for _, condition := range src.conditions {
+		dst.conditions = append(dst.conditions, core.RenumberPlaceholders(condition, start))
+	}
+	dst.args = append(dst.args, src.args...)
+}
+
+// sortedKeys returns the keys of a value map in order, so statements built
+// from maps are stable
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInsertBuilder(t *testing.T) {
+	t.Run("should insert several rows with RETURNING", func(t *testing.T) {
+		query, args := NewInsertBuilder("users").
+			Columns("email", "age").
+			Values("a@example.com", 30).
+			Values("b@example.com", 40).
+			Returning("id", "created_at").
+			Build()
+
+		expected := "INSERT INTO users (email, age) VALUES ($1, $2), ($3, $4) RETURNING id, created_at"
+		if query != expected {
+			t.Errorf("Expected %s, got %s", expected, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"a@example.com", 30, "b@example.com", 40}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should build a single row from Set and SetMap", func(t *testing.T) {
+		query, args := NewInsertBuilder("users").
+			Set("email", "a@example.com").
+			SetMap(map[string]interface{}{"username": "a", "age": 30}).
+			Build()
+
+		expected := "INSERT INTO users (email, age, username) VALUES ($1, $2, $3)"
+		if query != expected {
+			t.Errorf("Expected %s, got %s", expected, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"a@example.com", 30, "a"}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should insert default values without rows", func(t *testing.T) {
+		query, args := NewInsertBuilder("events").Returning("id").Build()
+		if query != "INSERT INTO events DEFAULT VALUES RETURNING id" || len(args) != 0 {
+			t.Errorf("Unexpected statement %s %v", query, args)
+		}
+	})
+}
+
+func TestInsertBuilder_OnConflict(t *testing.T) {
+	t.Run("should update the non-target columns by default", func(t *testing.T) {
+		query, args := NewInsertBuilder("users").
+			Columns("email", "username", "age").
+			Values("a@example.com", "a", 30).
+			OnConflict("email").
+			DoUpdateSet().
+			Returning("id").
+			Build()
+
+		expected := "INSERT INTO users (email, username, age) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (email) DO UPDATE SET username = EXCLUDED.username, age = EXCLUDED.age RETURNING id"
+		if query != expected {
+			t.Errorf("Expected %s, got %s", expected, query)
+		}
+		if len(args) != 3 {
+			t.Errorf("Expected 3 args, got %v", args)
+		}
+	})
+
+	t.Run("should number update values and conditions after the rows", func(t *testing.T) {
+		query, args := NewInsertBuilder("counters").
+			Columns("name", "hits", "version").
+			Values("home", 1, 5).
+			OnConflict("name").
+			DoUpdateSetExpr("hits", "counters.hits + $1", 1).
+			DoUpdateSetValue("updated_by", "job").
+			DoUpdateSet("version").
+			DoUpdateWhere(NewConditionBuilder().LessThan("counters.version", 5)).
+			Build()
+
+		expected := "INSERT INTO counters (name, hits, version) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (name) DO UPDATE SET hits = counters.hits + $4, updated_by = $5, version = EXCLUDED.version " +
+			"WHERE counters.version < $6"
+		if query != expected {
+			t.Errorf("Expected %s, got %s", expected, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"home", 1, 5, 1, "job", 5}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("should skip conflicts", func(t *testing.T) {
+		query, _ := NewInsertBuilder("tags").Columns("name").Values("go").OnConflict().DoNothing().Build()
+		if query != "INSERT INTO tags (name) VALUES ($1) ON CONFLICT DO NOTHING" {
+			t.Errorf("Unexpected statement %s", query)
+		}
+		query, _ = NewInsertBuilder("tags").Columns("name").Values("go").OnConflictConstraint("tags_name_key").DoNothing().Build()
+		if query != "INSERT INTO tags (name) VALUES ($1) ON CONFLICT ON CONSTRAINT tags_name_key DO NOTHING" {
+			t.Errorf("Unexpected statement %s", query)
+		}
+	})
+}
+
+func TestUpdateBuilder(t *testing.T) {
+	t.Run("should number WHERE arguments after SET arguments", func(t *testing.T) {
+		query, args := NewUpdateBuilder("users").
+			Set("email", "new@example.com").
+			SetExpr("version", "version + 1").
+			SetExpr("nickname", "COALESCE($1, nickname)", "nick").
+			WhereEqual("id", 7).
+			Where("version = $2", 3).
+			WhereConditions(NewConditionBuilder().GreaterThan("age", 18).IsNull("deleted_at")).
+			Returning("*").
+			Build()
+
+		expected := "UPDATE users SET email = $1, version = version + 1, nickname = COALESCE($2, nickname) " +
+			"WHERE id = $3 AND version = $4 AND age > $5 AND deleted_at IS NULL RETURNING *"
+		if query != expected {
+			t.Errorf("Expected %s, got %s", expected, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"new@example.com", "nick", 7, 3, 18}) {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+}
+
+func TestDeleteBuilder(t *testing.T) {
+	query, args := NewDeleteBuilder("sessions").
+		WhereConditions(NewConditionBuilder().LessThan("expires_at", "2024-01-01")).
+		WhereConditions(NewConditionBuilder().In("user_id", []interface{}{1, 2})).
+		Returning("id").
+		Build()
+
+	expected := "DELETE FROM sessions WHERE expires_at < $1 AND user_id IN ($2, $3) RETURNING id"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"2024-01-01", 1, 2}) {
+		t.Errorf("Unexpected args %v", args)
+	}
+
+	if query, _ := NewDeleteBuilder("sessions").Build(); query != "DELETE FROM sessions" {
+		t.Errorf("Unexpected statement %s", query)
+	}
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"strconv"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+// SQLBuilder builds SQL with $1-based arguments, such as a QueryBuilder
+type SQLBuilder interface {
+	Build() (string, []interface{})
+}
+
+// Params allocates the $n placeholders of a statement assembled from
+// fragments. Every builder writes its own SQL with placeholders numbered
+// from $1; Fragment renumbers a fragment to follow the arguments allocated
+// before it, so fragments combine correctly in any order and nesting.
+type Params struct {
+	args []interface{}
+}
+
+// NewParams creates an empty parameter allocator
+func NewParams() *Params {
+	return &Params{}
+}
+
+// Add binds a value and returns its placeholder
+func (p *Params) Add(value interface{}) string {
+	p.args = append(p.args, value)
+	return "$" + strconv.Itoa(len(p.args))
+}
+
+// Fragment binds the arguments of a $1-based fragment and returns the
+// fragment with its placeholders renumbered to match
+func (p *Params) Fragment(sql string, args []interface{}) string {
+	sql = core.RenumberPlaceholders(sql, len(p.args)+1)
+	p.args = append(p.args, args...)
+	return sql
+}
+
+// Build binds the statement of a builder as a fragment
+func (p *Params) Build(builder SQLBuilder) string {
+	return p.Fragment(builder.Build())
+}
+
+// Args returns the bound arguments in placeholder order
+func (p *Params) Args() []interface{} {
+	return p.args
+}
+
+// Len returns the number of bound arguments
+func (p *Params) Len() int {
+	return len(p.args)
+}
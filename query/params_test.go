@@ -0,0 +1,99 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParams_Fragments(t *testing.T) {
+	p := NewParams()
+	first := p.Add("a")
+	second := p.Fragment("x = $1 AND y = $2", []interface{}{"b", "c"})
+	third := p.Build(NewQueryBuilder("t").WhereEqual("z", "d"))
+
+	if first != "$1" || second != "x = $2 AND y = $3" || third != "SELECT * FROM t WHERE z = $4" {
+		t.Errorf("Unexpected fragments %s, %s, %s", first, second, third)
+	}
+	if p.Len() != 4 || p.Args()[0] != "a" || p.Args()[3] != "d" {
+		t.Errorf("Expected [a b c d], got %v", p.Args())
+	}
+}
+
+func TestAdvancedQueryBuilder_NestedRenumbering(t *testing.T) {
+	// A join with its own arguments used as a CTE
+	paid := NewJoinQuery[string]("orders")
+	paid.Select("orders.user_id")
+	paid.InnerJoin("payments", "payments.order_id = orders.id AND payments.method = $1", "card")
+	paid.WhereEqual("orders.status", "paid")
+
+	// A nested advanced query with a CTE of its own, used as a union
+	archived := NewAdvancedQueryBuilder("archived_users")
+	archived.Select("id")
+	archived.WithCTE("eu", NewQueryBuilder("regions").Select("id").WhereEqual("code", "eu"))
+	archived.Where("region_id IN (SELECT id FROM eu) AND archived_at > $1", 2020)
+
+	aqb := NewAdvancedQueryBuilder("users")
+	aqb.Select("id")
+	aqb.WithCTE("paid", paid)
+	aqb.Subquery("orders", NewQueryBuilder("orders").Select("COUNT(*)").Where("orders.user_id = users.id AND total > $1", 100))
+	aqb.WhereEqual("active", true)
+	aqb.Union(archived)
+
+	query, args := aqb.Build()
+
+	expected := "WITH paid AS (SELECT orders.user_id FROM orders INNER JOIN payments ON payments.order_id = orders.id AND payments.method = $1 WHERE orders.status = $2) " +
+		"SELECT id, (SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id AND total > $3) AS orders FROM users WHERE active = $4 " +
+		"UNION WITH eu AS (SELECT id FROM regions WHERE code = $5) SELECT id FROM archived_users WHERE region_id IN (SELECT id FROM eu) AND archived_at > $6"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	want := []interface{}{"card", "paid", 100, true, "eu", 2020}
+	if len(args) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, args)
+			break
+		}
+	}
+
+	// Building must not change the builder
+	if again, _ := aqb.Build(); again != query {
+		t.Errorf("Expected repeated Build to be stable, got %s", again)
+	}
+}
+
+func TestQueryComposer_Renumbering(t *testing.T) {
+	query, args := NewQueryComposer().
+		AddQuery(NewQueryBuilder("users").Select("id").WhereEqual("role", "admin")).
+		AddQuery(NewQueryBuilder("users").Select("id").WhereEqual("role", "owner")).
+		Compose("INTERSECT")
+
+	expected := "(SELECT id FROM users WHERE role = $1) INTERSECT (SELECT id FROM users WHERE role = $2)"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if len(args) != 2 || args[0] != "admin" || args[1] != "owner" {
+		t.Errorf("Expected [admin owner], got %v", args)
+	}
+}
+
+func TestConditionBuilder_Renumbering(t *testing.T) {
+	left := NewConditionBuilder().Equal("status", "active")
+	where, args := left.And(NewConditionBuilder().GreaterThan("age", 18).LessThan("age", 65)).Build()
+	if where != "status = $1 AND age > $2 AND age < $3" || len(args) != 3 {
+		t.Errorf("Unexpected AND %s %v", where, args)
+	}
+
+	where, args = NewConditionBuilder().Equal("a", 1).Or(NewConditionBuilder().Equal("b", 2)).Build()
+	if where != "(a = $1) OR (b = $2)" || len(args) != 2 {
+		t.Errorf("Unexpected OR %s %v", where, args)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = DateRange("created_at", start, start.AddDate(0, 1, 0)).Build()
+	if where != "created_at >= $1 AND created_at <= $2" || len(args) != 2 {
+		t.Errorf("Unexpected date range %s %v", where, args)
+	}
+}
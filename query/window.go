@@ -0,0 +1,157 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameBound is a boundary of a window frame
+type FrameBound string
+
+// Frame boundaries
+const (
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+	CurrentRow         FrameBound = "CURRENT ROW"
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding is the frame boundary n rows (or values, for RANGE) before the
+// current row
+func Preceding(n int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d PRECEDING", n))
+}
+
+// Following is the frame boundary n rows (or values, for RANGE) after the
+// current row
+func Following(n int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d FOLLOWING", n))
+}
+
+// Frame is a window frame extent, such as Between(Preceding(6), CurrentRow)
+type Frame string
+
+// Between is the frame from start to end
+func Between(start, end FrameBound) Frame {
+	return Frame(fmt.Sprintf("BETWEEN %s AND %s", start, end))
+}
+
+// WindowSpec is the OVER clause of a window function
+type WindowSpec struct {
+	partitionBy []string
+	orderBy     []string
+	frame       string
+}
+
+// Over starts a window specification
+func Over() *WindowSpec {
+	return &WindowSpec{}
+}
+
+// PartitionBy adds PARTITION BY columns
+func (w *WindowSpec) PartitionBy(cols ...string) *WindowSpec {
+	w.partitionBy = append(w.partitionBy, cols...)
+	return w
+}
+
+// OrderBy adds an ORDER BY column and direction
+func (w *WindowSpec) OrderBy(column string, direction string) *WindowSpec {
+	w.orderBy = append(w.orderBy, column+" "+sortDirection(direction))
+	return w
+}
+
+// Rows sets a ROWS frame, e.g. Rows(Between(Preceding(6), CurrentRow)) for a
+// seven-row moving window
+func (w *WindowSpec) Rows(frame Frame) *WindowSpec {
+	w.frame = "ROWS " + string(frame)
+	return w
+}
+
+// Range sets a RANGE frame
+func (w *WindowSpec) Range(frame Frame) *WindowSpec {
+	w.frame = "RANGE " + string(frame)
+	return w
+}
+
+// String renders the parenthesized window specification
+func (w *WindowSpec) String() string {
+	var parts []string
+	if len(w.partitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitionBy, ", "))
+	}
+	if len(w.orderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(w.orderBy, ", "))
+	}
+	if w.frame != "" {
+		parts = append(parts, w.frame)
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// WindowFunction is a function evaluated over a window
+type WindowFunction struct {
+	function string
+	over     *WindowSpec
+	alias    string
+}
+
+// WindowFunc wraps any aggregate or window function call, such as
+// "AVG(price)", for use with Over
+func WindowFunc(call string) *WindowFunction {
+	return &WindowFunction{function: call}
+}
+
+// RowNumber numbers the rows of each partition from 1
+func RowNumber() *WindowFunction {
+	return WindowFunc("ROW_NUMBER()")
+}
+
+// Rank ranks the rows of each partition, with gaps after ties
+func Rank() *WindowFunction {
+	return WindowFunc("RANK()")
+}
+
+// DenseRank ranks the rows of each partition without gaps
+func DenseRank() *WindowFunction {
+	return WindowFunc("DENSE_RANK()")
+}
+
+// Lag is the column's value offset rows before the current row
+func Lag(column string, offset int) *WindowFunction {
+	return WindowFunc(fmt.Sprintf("LAG(%s, %d)", column, offset))
+}
+
+// Lead is the column's value offset rows after the current row
+func Lead(column string, offset int) *WindowFunction {
+	return WindowFunc(fmt.Sprintf("LEAD(%s, %d)", column, offset))
+}
+
+// SumOver sums the column over the window, e.g. a running total when the
+// window is ordered
+func SumOver(column string) *WindowFunction {
+	return WindowFunc(fmt.Sprintf("SUM(%s)", column))
+}
+
+// Over sets the window the function is evaluated over
+func (f *WindowFunction) Over(spec *WindowSpec) *WindowFunction {
+	f.over = spec
+	return f
+}
+
+// As names the result column
+func (f *WindowFunction) As(alias string) *WindowFunction {
+	f.alias = alias
+	return f
+}
+
+// String renders the function call with its OVER clause and alias
+func (f *WindowFunction) String() string {
+	over := f.over
+	if over == nil {
+		over = Over()
+	}
+	expr := f.function + " OVER " + over.String()
+	if f.alias != "" {
+		expr += " AS " + f.alias
+	}
+	return expr
+}
@@ -0,0 +1,61 @@
+package query
+
+import "testing"
+
+func TestWindowFunction(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       *WindowFunction
+		expected string
+	}{
+		{
+			name:     "should partition and order row numbers",
+			fn:       RowNumber().Over(Over().PartitionBy("dept").OrderBy("salary", "DESC")).As("rank_in_dept"),
+			expected: "ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC) AS rank_in_dept",
+		},
+		{
+			name:     "should render an empty window",
+			fn:       Rank(),
+			expected: "RANK() OVER ()",
+		},
+		{
+			name:     "should render lag with an offset",
+			fn:       Lag("price", 1).Over(Over().PartitionBy("sku").OrderBy("day", "ASC")).As("prev_price"),
+			expected: "LAG(price, 1) OVER (PARTITION BY sku ORDER BY day ASC) AS prev_price",
+		},
+		{
+			name:     "should render row frames",
+			fn:       SumOver("amount").Over(Over().OrderBy("day", "ASC").Rows(Between(Preceding(6), CurrentRow))).As("weekly"),
+			expected: "SUM(amount) OVER (ORDER BY day ASC ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS weekly",
+		},
+		{
+			name:     "should render range frames",
+			fn:       WindowFunc("AVG(price)").Over(Over().OrderBy("day", "ASC").Range(Between(UnboundedPreceding, Following(0)))),
+			expected: "AVG(price) OVER (ORDER BY day ASC RANGE BETWEEN UNBOUNDED PRECEDING AND 0 FOLLOWING)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAdvancedQueryBuilder_Window(t *testing.T) {
+	aqb := NewAdvancedQueryBuilder("employees")
+	aqb.Select("name", "dept")
+	aqb.Window(DenseRank().Over(Over().PartitionBy("dept").OrderBy("salary", "DESC")).As("rank"))
+	aqb.WhereEqual("active", true)
+
+	query, args := aqb.BuildAdvanced()
+	expected := "SELECT name, dept, DENSE_RANK() OVER (PARTITION BY dept ORDER BY salary DESC) AS rank FROM employees WHERE active = $1"
+	if query != expected {
+		t.Errorf("Expected %s, got %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got %v", args)
+	}
+}
@@ -3,6 +3,7 @@ package testing
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"github.com/satishbabariya/jetorm/core"
 )
@@ -187,3 +188,54 @@ func (m *MockRepository[T, ID]) WithTx(ctx context.Context, fn func(ctx context.
 	return fn(ctx)
 }
 
+// Call is a method call recorded by a Recorder
+type Call struct {
+	Method string
+	Args   []interface{} // Arguments after the context
+}
+
+// Recorder records the calls made to a mock. Mocks generated with
+// jetorm-gen -mocks embed it, so tests can assert on the calls:
+//
+//	if calls := mock.Calls("FindByEmail"); len(calls) != 1 || calls[0].Args[0] != "a@example.com" {
+//		t.Errorf("unexpected calls: %v", calls)
+//	}
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Record records a call to method
+func (r *Recorder) Record(method string, args ...interface{}) {
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	r.mu.Unlock()
+}
+
+// Calls returns the recorded calls to method in call order, or every
+// recorded call when method is empty
+func (r *Recorder) Calls(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, call := range r.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of recorded calls to method
+func (r *Recorder) CallCount(method string) int {
+	return len(r.Calls(method))
+}
+
+// Reset discards the recorded calls
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.calls = nil
+	r.mu.Unlock()
+}
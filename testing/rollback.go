@@ -0,0 +1,156 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	stdtesting "testing"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+var (
+	// rollbackMu serializes WithRollback, as the registered variables are
+	// shared by every test of the package
+	rollbackMu sync.Mutex
+
+	registryMu   sync.Mutex
+	repositories []interface{} // Pointers registered with RegisterRepositories
+)
+
+// txType is the parameter type of the WithTx methods BindRepositories calls
+var txType = reflect.TypeOf((*core.Tx)(nil))
+
+// RegisterRepositories records variables holding repositories, e.g.
+// RegisterRepositories(&userRepo, &orderRepo) in TestMain, so that
+// WithRollback binds them to its transaction. See BindRepositories for the
+// repositories that can be bound.
+func RegisterRepositories(repos ...interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	repositories = append(repositories, repos...)
+}
+
+// WithRollback runs fn in a transaction that is rolled back when fn returns,
+// so integration tests leave no data behind without truncating tables. The
+// registered repositories are bound to the transaction while fn runs and
+// restored afterwards. Tests using WithRollback run one at a time, even when
+// parallel, as the registered variables are shared.
+//
+// Code under test that begins its own transaction through the Database runs
+// outside the test's transaction and is not rolled back.
+func WithRollback(t stdtesting.TB, db *core.Database, fn func(tx *core.Tx)) {
+	t.Helper()
+
+	rollbackMu.Lock()
+	defer rollbackMu.Unlock()
+
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin test transaction: %v", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			t.Errorf("failed to roll back test transaction: %v", err)
+		}
+	}()
+
+	registryMu.Lock()
+	repos := append([]interface{}(nil), repositories...)
+	registryMu.Unlock()
+
+	restore, err := BindRepositories(tx, repos...)
+	if err != nil {
+		t.Fatalf("failed to bind repositories: %v", err)
+	}
+	defer restore()
+
+	fn(tx)
+}
+
+// BindRepositories replaces the repository held by each variable with one
+// bound to tx, and returns a function that puts the original repositories
+// back. Each argument is a pointer to a variable holding either a repository
+// whose WithTx(*core.Tx) returns the variable's type, such as a
+// *core.BaseRepository or a core.Repository, or a pointer to a struct
+// embedding one, such as a generated repository.
+func BindRepositories(tx *core.Tx, repos ...interface{}) (func(), error) {
+	type binding struct {
+		target   reflect.Value
+		original reflect.Value
+	}
+	var bindings []binding
+	restore := func() {
+		for i := len(bindings) - 1; i >= 0; i-- {
+			bindings[i].target.Set(bindings[i].original)
+		}
+	}
+
+	for _, repo := range repos {
+		ptr := reflect.ValueOf(repo)
+		if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+			restore()
+			return nil, fmt.Errorf("%T is not a pointer to a repository variable", repo)
+		}
+		target := ptr.Elem()
+		bound, ok := bindValue(target, tx)
+		if !ok {
+			restore()
+			return nil, fmt.Errorf("%s cannot be bound to a transaction", target.Type())
+		}
+		original := reflect.New(target.Type()).Elem()
+		original.Set(target)
+		bindings = append(bindings, binding{target: target, original: original})
+		target.Set(bound)
+	}
+	return restore, nil
+}
+
+// bindValue returns v bound to tx, converted to v's type: the result of its
+// WithTx method, or a copy of the struct it points to with its embedded
+// repositories bound
+func bindValue(v reflect.Value, tx *core.Tx) (reflect.Value, bool) {
+	want := v.Type()
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+
+	if method := v.MethodByName("WithTx"); method.IsValid() && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		mt := method.Type()
+		if mt.NumIn() == 1 && mt.In(0) == txType && mt.NumOut() == 1 {
+			result := method.Call([]reflect.Value{reflect.ValueOf(tx)})[0]
+			if result.Kind() == reflect.Interface && !result.IsNil() {
+				result = result.Elem()
+			}
+			if result.IsValid() && result.Type().AssignableTo(want) {
+				return result, true
+			}
+		}
+	}
+
+	// Generated repositories embed the repository WithTx binds
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	copied := reflect.New(v.Elem().Type())
+	copied.Elem().Set(v.Elem())
+	bound := false
+	for i := 0; i < copied.Elem().NumField(); i++ {
+		field := copied.Elem().Field(i)
+		if !copied.Elem().Type().Field(i).Anonymous || !field.CanSet() {
+			continue
+		}
+		if value, ok := bindValue(field, tx); ok {
+			field.Set(value)
+			bound = true
+		}
+	}
+	if !bound || !copied.Type().AssignableTo(want) {
+		return reflect.Value{}, false
+	}
+	return copied, true
+}
@@ -0,0 +1,68 @@
+package testing
+
+import (
+	stdtesting "testing"
+
+	"github.com/satishbabariya/jetorm/core"
+)
+
+type rollbackUser struct {
+	ID int64 `db:"id" jet:"primary_key"`
+}
+
+// StubRepo is exported, as embedded repositories must be to be bound
+type StubRepo struct {
+	tx *core.Tx
+}
+
+func (r *StubRepo) WithTx(tx *core.Tx) *StubRepo {
+	return &StubRepo{tx: tx}
+}
+
+// generatedRepo embeds its repository like generated code does
+type generatedRepo struct {
+	*StubRepo
+	name string
+}
+
+func TestBindRepositories(t *stdtesting.T) {
+	tx := &core.Tx{}
+	plain := &StubRepo{}
+	generated := &generatedRepo{StubRepo: &StubRepo{}, name: "users"}
+	base, err := core.NewBaseRepository[rollbackUser, int64](nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iface core.Repository[rollbackUser, int64] = base
+	originalBase := base
+
+	restore, err := BindRepositories(tx, &plain, &generated, &base, &iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.tx != tx {
+		t.Error("Expected the repository to be bound to the transaction")
+	}
+	if generated.tx != tx || generated.name != "users" {
+		t.Errorf("Expected the embedded repository to be bound in a copy, got %+v", generated)
+	}
+	if base == originalBase || iface == core.Repository[rollbackUser, int64](originalBase) {
+		t.Error("Expected the base repositories to be replaced")
+	}
+
+	restore()
+	if plain.tx != nil || generated.tx != nil || base != originalBase || iface != core.Repository[rollbackUser, int64](originalBase) {
+		t.Error("Expected restore to put the original repositories back")
+	}
+
+	var unbindable string
+	if _, err := BindRepositories(tx, &plain, &unbindable); err == nil {
+		t.Error("Expected an error for a variable holding no repository")
+	}
+	if plain.tx != nil {
+		t.Error("Expected a failed bind to restore the variables bound before it")
+	}
+	if _, err := BindRepositories(tx, plain); err == nil {
+		t.Error("Expected an error for a repository passed by value")
+	}
+}